@@ -0,0 +1,153 @@
+package slackdump
+
+// In this file: the admin settings of the conversations.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"runtime/trace"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// AdminFile is the name of the admin settings file in the root of the
+// archive.
+const AdminFile = "admin.json"
+
+// adminDenied are the API errors, that mean that the token can't call the
+// admin method at all, as opposed to the errors of the particular
+// conversation.
+var adminDenied = map[string]bool{
+	"not_an_admin":           true,
+	"not_an_enterprise":      true,
+	"feature_not_enabled":    true,
+	"missing_scope":          true,
+	"not_allowed_token_type": true,
+	"no_permission":          true,
+}
+
+// Admin returns the admin settings of the conversations channelIDs: the
+// custom retention policy, the conversation preferences and the workspaces
+// the conversation is shared with.  The admin API methods require the admin
+// or owner user token of Enterprise Grid organisation with the
+// admin.conversations:read scope.  The parts that the token can't access are
+// skipped, and the reason is recorded in the Skipped field.
+func (sd *Session) Admin(ctx context.Context, channelIDs []string) (*types.Admin, error) {
+	ctx, task := trace.NewTask(ctx, "Admin")
+	defer task.End()
+
+	adm := &types.Admin{Created: time.Now().UTC()}
+	type adminPart struct {
+		name string
+		fn   func(ac *types.AdminConversation) error
+	}
+	call := func(method string, channelID string, v any) error {
+		return sd.api.call(ctx, method, url.Values{"channel_id": {channelID}}, v)
+	}
+	parts := []adminPart{
+		{"retention", func(ac *types.AdminConversation) error {
+			var resp types.Retention
+			if err := call("admin.conversations.getCustomRetention", ac.ChannelID, &resp); err != nil {
+				return err
+			}
+			ac.Retention = &resp
+			return nil
+		}},
+		{"prefs", func(ac *types.AdminConversation) error {
+			var resp struct {
+				Prefs json.RawMessage `json:"prefs"`
+			}
+			if err := call("admin.conversations.getConversationPrefs", ac.ChannelID, &resp); err != nil {
+				return err
+			}
+			ac.Prefs = resp.Prefs
+			return nil
+		}},
+		{"teams", func(ac *types.AdminConversation) error {
+			var resp struct {
+				TeamIDs []string `json:"team_ids"`
+			}
+			if err := call("admin.conversations.getTeams", ac.ChannelID, &resp); err != nil {
+				return err
+			}
+			ac.TeamIDs = resp.TeamIDs
+			return nil
+		}},
+	}
+
+	l := sd.limiter(network.Tier3)
+	for _, id := range channelIDs {
+		ac := types.AdminConversation{ChannelID: id}
+		for _, p := range parts {
+			if _, skipped := adm.Skipped[p.name]; skipped {
+				continue
+			}
+			if err := sd.proceed(ctx); err != nil {
+				return nil, err
+			}
+			err := network.WithRetry(ctx, l, sd.options.Tier3Retries, func() error { return p.fn(&ac) })
+			if err == nil {
+				continue
+			}
+			var ser slack.SlackErrorResponse
+			if errors.Is(err, errNoAPIClient) || (errors.As(err, &ser) && adminDenied[ser.Err]) {
+				sd.l().Debugf("admin: skipping %s: %s", p.name, err)
+				if adm.Skipped == nil {
+					adm.Skipped = make(map[string]string)
+				}
+				adm.Skipped[p.name] = err.Error()
+				continue
+			}
+			err = network.Classify(id, err)
+			var na *ErrNoAccess
+			if !errors.As(err, &na) {
+				return nil, err
+			}
+			ac.Err = err.Error()
+		}
+		adm.Conversations = append(adm.Conversations, ac)
+	}
+	return adm, nil
+}
+
+// SaveAdmin takes the snapshot of the admin settings of the conversations,
+// identified by links (channel IDs, channel or thread URLs), and writes it
+// into the root of the filesystem fs as AdminFile, if the AdminInfo option is
+// set.  The settings are auxiliary, so the errors, other than the
+// interruption, are logged and do not stop the run.
+func (sd *Session) SaveAdmin(ctx context.Context, fs fsadapter.FS, links []string) error {
+	if !sd.options.AdminInfo || len(links) == 0 {
+		return nil
+	}
+	var (
+		ids  []string
+		seen = make(map[string]bool, len(links))
+	)
+	for _, link := range links {
+		sl, err := structures.ParseLink(link)
+		if err != nil || seen[sl.Channel] {
+			continue
+		}
+		seen[sl.Channel] = true
+		ids = append(ids, sl.Channel)
+	}
+	adm, err := sd.Admin(ctx, ids)
+	if err == nil {
+		err = writeJSON(fs, AdminFile, adm)
+	}
+	if err != nil {
+		if IsInterrupted(ctx, err) {
+			return err
+		}
+		sd.l().Printf("warning: failed to save the admin settings: %s", err)
+	}
+	return nil
+}
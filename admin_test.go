@@ -0,0 +1,121 @@
+package slackdump
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// adminServer returns the server that serves the admin.conversations methods,
+// responses are keyed by the method and the channel ID.
+func adminServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := filepath.Base(r.URL.Path) + " " + r.FormValue("channel_id")
+		resp, ok := responses[key]
+		if !ok {
+			t.Errorf("unexpected request: %s", key)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSession_Admin(t *testing.T) {
+	t.Run("all parts", func(t *testing.T) {
+		srv := adminServer(t, map[string]string{
+			"admin.conversations.getCustomRetention C01":   `{"ok":true,"is_policy_enabled":true,"duration_days":30}`,
+			"admin.conversations.getConversationPrefs C01": `{"ok":true,"prefs":{"who_can_post":{"type":["admin"]}}}`,
+			"admin.conversations.getTeams C01":             `{"ok":true,"team_ids":["T01","T02"]}`,
+			"admin.conversations.getCustomRetention C02":   `{"ok":false,"error":"channel_not_found"}`,
+			"admin.conversations.getConversationPrefs C02": `{"ok":true,"prefs":{}}`,
+			"admin.conversations.getTeams C02":             `{"ok":true,"team_ids":["T01"]}`,
+		})
+		sd := &Session{api: &apiClient{cl: srv.Client(), token: "xoxp-test", baseURL: srv.URL + "/"}, options: DefOptions}
+		got, err := sd.Admin(context.Background(), []string{"C01", "C02"})
+		require.NoError(t, err)
+		require.Len(t, got.Conversations, 2)
+		assert.Empty(t, got.Skipped)
+
+		c01 := got.Conversations[0]
+		assert.Equal(t, &types.Retention{IsPolicyEnabled: true, DurationDays: 30}, c01.Retention)
+		assert.JSONEq(t, `{"who_can_post":{"type":["admin"]}}`, string(c01.Prefs))
+		assert.Equal(t, []string{"T01", "T02"}, c01.TeamIDs)
+		assert.Empty(t, c01.Err)
+
+		c02 := got.Conversations[1]
+		assert.Nil(t, c02.Retention)
+		assert.Contains(t, c02.Err, "channel_not_found")
+		assert.Equal(t, []string{"T01"}, c02.TeamIDs)
+	})
+	t.Run("not an admin", func(t *testing.T) {
+		// the part is requested only once, and skipped for the rest of the
+		// conversations.
+		srv := adminServer(t, map[string]string{
+			"admin.conversations.getCustomRetention C01":   `{"ok":false,"error":"not_an_admin"}`,
+			"admin.conversations.getConversationPrefs C01": `{"ok":false,"error":"missing_scope"}`,
+			"admin.conversations.getTeams C01":             `{"ok":false,"error":"not_an_enterprise"}`,
+		})
+		sd := &Session{api: &apiClient{cl: srv.Client(), token: "xoxp-test", baseURL: srv.URL + "/"}, options: DefOptions}
+		got, err := sd.Admin(context.Background(), []string{"C01", "C02"})
+		require.NoError(t, err)
+		assert.Len(t, got.Conversations, 2)
+		assert.Len(t, got.Skipped, 3)
+		assert.Contains(t, got.Skipped["retention"], "not_an_admin")
+	})
+	t.Run("no api client", func(t *testing.T) {
+		sd := &Session{options: DefOptions}
+		got, err := sd.Admin(context.Background(), []string{"C01"})
+		require.NoError(t, err)
+		assert.Len(t, got.Skipped, 3)
+	})
+}
+
+func TestSession_SaveAdmin(t *testing.T) {
+	srv := adminServer(t, map[string]string{
+		"admin.conversations.getCustomRetention C01":   `{"ok":true,"is_policy_enabled":false}`,
+		"admin.conversations.getConversationPrefs C01": `{"ok":true,"prefs":{}}`,
+		"admin.conversations.getTeams C01":             `{"ok":true,"team_ids":["T01"]}`,
+	})
+	newSession := func(enabled bool) *Session {
+		opts := DefOptions
+		opts.AdminInfo = enabled
+		return &Session{api: &apiClient{cl: srv.Client(), token: "xoxp-test", baseURL: srv.URL + "/"}, options: opts}
+	}
+	t.Run("disabled", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, newSession(false).SaveAdmin(context.Background(), fsadapter.NewDirectory(dir), []string{"C01"}))
+		assert.NoFileExists(t, filepath.Join(dir, AdminFile))
+	})
+	t.Run("links are deduplicated", func(t *testing.T) {
+		dir := t.TempDir()
+		links := []string{"C01", "C01:1577694990.000400", "https://fake.slack.com/archives/C01/p1577694990000400"}
+		require.NoError(t, newSession(true).SaveAdmin(context.Background(), fsadapter.NewDirectory(dir), links))
+		data, err := os.ReadFile(filepath.Join(dir, AdminFile))
+		require.NoError(t, err)
+		var got types.Admin
+		require.NoError(t, json.Unmarshal(data, &got))
+		require.Len(t, got.Conversations, 1)
+		assert.Equal(t, "C01", got.Conversations[0].ChannelID)
+		assert.Equal(t, &types.Retention{}, got.Conversations[0].Retention)
+	})
+	t.Run("interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := newSession(true).SaveAdmin(ctx, fsadapter.NewDirectory(t.TempDir()), []string{"C01"})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,115 @@
+package slackdump
+
+// In this file: the client for the API methods, that are not available in
+// the slack library, i.e. the ones used by the Slack web client.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// errNoAPIClient is returned by the calls of the API methods that are not
+// available in the slack library, if the session was created with
+// NewWithClient.
+var errNoAPIClient = errors.New("not supported by the client of the session")
+
+// auditURL is the base URL of the Audit Logs API.
+const auditURL = "https://api.slack.com/audit/v1/"
+
+// apiClient calls the Slack API methods on the workspace URL.
+type apiClient struct {
+	cl       *http.Client
+	token    string
+	baseURL  string // workspace URL, i.e. https://xxxx.slack.com/
+	auditURL string // Audit Logs API URL, if empty, auditURL is used.
+}
+
+// call calls the API method with the form values, and decodes the response
+// into v.  The API errors are returned as slack.SlackErrorResponse, and the
+// rate limiting as *slack.RateLimitedError, so that they are handled the same
+// way as the errors of the slack library.
+func (c *apiClient) call(ctx context.Context, method string, form url.Values, v any) error {
+	if c == nil {
+		return errNoAPIClient
+	}
+	base := c.baseURL
+	if base == "" {
+		base = slackURL
+	}
+	values := url.Values{"token": {c.token}}
+	for k, vv := range form {
+		values[k] = vv
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(base, "/")+"/api/"+method, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req, method, true, v)
+}
+
+// audit calls the Audit Logs API method, i.e. "logs", with the query values,
+// and decodes the response into v.  The Audit Logs API requires the user
+// token of the org-level app with the auditlogs:read scope.
+func (c *apiClient) audit(ctx context.Context, method string, query url.Values, v any) error {
+	if c == nil {
+		return errNoAPIClient
+	}
+	base := c.auditURL
+	if base == "" {
+		base = auditURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(base, "/")+"/"+method+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	// the successful responses of the Audit Logs API have no "ok" field.
+	return c.do(req, method, false, v)
+}
+
+// do sends the request and decodes the response into v.  If requireOK is
+// set, the response must have the "ok" field set to true.
+func (c *apiClient) do(req *http.Request, method string, requireOK bool, v any) error {
+	resp, err := c.cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retry, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return &slack.RateLimitedError{RetryAfter: time.Duration(retry) * time.Second}
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var sr slack.SlackResponse
+	jsonErr := json.Unmarshal(data, &sr)
+	if jsonErr == nil && sr.Error != "" {
+		return slack.SlackErrorResponse{Err: sr.Error, ResponseMetadata: sr.ResponseMetadata}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return slack.StatusCodeError{Code: resp.StatusCode, Status: resp.Status}
+	}
+	if jsonErr != nil {
+		return fmt.Errorf("%s: %w", method, jsonErr)
+	}
+	if requireOK && !sr.Ok {
+		return slack.SlackErrorResponse{Err: sr.Error, ResponseMetadata: sr.ResponseMetadata}
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package slackdump
+
+// In this file: archiving of the conversations into the directory or ZIP
+// file.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/trace"
+	"time"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// EntityList is the list of the channel IDs or URLs to include and exclude,
+// it is used to select the conversations for the export.
+type EntityList = structures.EntityList
+
+// NewEntityList creates the EntityList from the channel IDs or URLs.  Entities
+// prefixed with "^" are excluded.
+func NewEntityList(entities ...string) (*EntityList, error) {
+	return structures.MakeEntityList(entities)
+}
+
+// Archive dumps the conversations identified by links (channel IDs, channel
+// or thread URLs) into the target directory or ZIP file.  Each conversation
+// is saved as <ID>.json, or <ID>-<ThreadTS>.json for threads, the same way
+// as the slackdump command does, and the files, if the DownloadFiles option is
+// set, into the <ID> directory.  The Manifest is saved as ManifestFile.  If oldest or latest are not zero, only the
+// messages within the window are dumped.
+//
+// Conversations that the user has no access to (see ErrNoAccess) are skipped,
+// and the reason is recorded in the Result.  Result is returned even if
+// Archive fails, it contains the conversations processed before the error.
+// If the context is cancelled, or the deadline budget is spent, the archive
+// is closed with the complete conversations, and the rest are listed by
+// Result.Pending.
+//
+// Archive uses the target as the session filesystem for the duration of the
+// call, so the session must not be used for dumping concurrently.
+func (sd *Session) Archive(ctx context.Context, target string, oldest, latest time.Time, links ...string) (*Result, error) {
+	ctx, task := trace.NewTask(ctx, "Archive")
+	defer task.End()
+
+	res := NewResult()
+	defer res.Finish()
+	if len(links) == 0 {
+		return res, fmt.Errorf("nothing to archive")
+	}
+	fsa, err := fsadapter.New(target)
+	if err != nil {
+		return res, err
+	}
+	err = sd.archive(ctx, fsa, res, oldest, latest, links)
+	if err == nil || IsInterrupted(ctx, err) {
+		if merr := WriteManifest(fsa, sd.Manifest()); merr != nil && err == nil {
+			err = merr
+		}
+	}
+	if err != nil {
+		fsa.Close()
+		return res, err
+	}
+	return res, fsa.Close()
+}
+
+func (sd *Session) archive(ctx context.Context, fsa fsadapter.FS, res *Result, oldest, latest time.Time, links []string) error {
+	prevFS, prevPr := sd.fs, sd.options.Progress
+	sd.SetFS(fsa)
+	sd.options.Progress = res.Reporter(sd.pr())
+	defer func() { sd.fs, sd.options.Progress = prevFS, prevPr }()
+
+	if err := sd.SaveWorkspace(ctx, fsa); err != nil {
+		res.Interrupt(err, links...)
+		return err
+	}
+
+	var dumped []string
+	for i, link := range links {
+		cr := ChannelResult{ID: link}
+		start := time.Now()
+		cnv, err := sd.Dump(ctx, link, oldest, latest)
+		cr.Duration = time.Since(start)
+		if err != nil {
+			if IsInterrupted(ctx, err) {
+				sd.l().Printf("archive interrupted: %s, %d conversation(s) left", err, len(links)-i)
+				res.Interrupt(err, links[i:]...)
+				return err
+			}
+			var na *ErrNoAccess
+			if errors.As(err, &na) || errors.Is(err, ErrSkipListed) {
+				sd.l().Printf("skipping %s: %s", link, err)
+				cr.Skipped = err.Error()
+				res.Add(cr)
+				continue
+			}
+			cr.Err = err
+			res.Add(cr)
+			return fmt.Errorf("%s: %w", link, err)
+		}
+		cr.Name = cnv.Name
+		cr.CountMessages(cnv.Messages)
+		if err := writeConversation(fsa, cnv); err != nil {
+			cr.Err = err
+			res.Add(cr)
+			return fmt.Errorf("%s: %w", link, err)
+		}
+		res.Add(cr)
+		dumped = append(dumped, link)
+	}
+	if err := sd.SaveAdmin(ctx, fsa, dumped); err != nil {
+		return err
+	}
+	return sd.SaveIntegrations(ctx, fsa)
+}
+
+// writeConversation writes the conversation to its JSON file.
+func writeConversation(fs fsadapter.FS, cnv *types.Conversation) error {
+	return writeJSON(fs, cnv.String()+".json", cnv)
+}
+
+// writeJSON writes v as the indented JSON into the file name on fs.
+func writeJSON(fs fsadapter.FS, name string, v any) error {
+	f, err := fs.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,372 @@
+// Package archive provides read access to the data saved by slackdump.  It
+// supports the Slack Export format (as created by the export mode) and the
+// conversation dumps (as created by the dump mode), stored either in a
+// directory, a ZIP file or a tarball.
+package archive
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/structures/files"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Type is the type of the archive.
+type Type uint8
+
+const (
+	TUnknown Type = iota // Unknown
+	TExport              // Export
+	TDump                // Dump
+)
+
+func (t Type) String() string {
+	switch t {
+	case TExport:
+		return "Export"
+	case TDump:
+		return "Dump"
+	default:
+		return "Unknown"
+	}
+}
+
+const (
+	usersFile      = "users.json"
+	teamUsersGlob  = "users-*.json" // users of the other Enterprise Grid teams, export
+	channelsFile   = "channels.json"
+	userGroupsFile = "usergroups.json" // export
+	workspaceFile  = "workspace.json"  // dump, see slackdump.WorkspaceFile
+)
+
+var (
+	// ErrUnknownFormat is returned by Open if the location does not look
+	// like anything slackdump would produce.
+	ErrUnknownFormat = errors.New("unknown archive format")
+	// ErrNotFound is returned if the conversation is not in the archive.
+	ErrNotFound = errors.New("conversation not found")
+)
+
+// Archive is the slackdump archive opened for reading.
+type Archive struct {
+	fsys   fs.FS
+	closer io.Closer
+	name   string
+	typ    Type
+
+	mu       sync.Mutex
+	channels []slack.Channel
+	users    types.Users
+	// userGroups is nil, until the user groups are read.
+	userGroups []slack.UserGroup
+	// dumpIdx is the mapping of channel ID to the list of the conversation
+	// files, only populated for dumps.
+	dumpIdx map[string][]string
+}
+
+// Open opens the archive at location, which can be a directory, a ZIP file
+// or a tarball (.tar, .tar.gz or .tgz), and detects its type.  The packed
+// archives are read without the extraction.
+func Open(location string) (*Archive, error) {
+	fi, err := os.Stat(location)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return New(os.DirFS(location), location)
+	}
+	var fsys interface {
+		fs.FS
+		io.Closer
+	}
+	switch {
+	case strings.EqualFold(filepath.Ext(location), ".zip"):
+		fsys, err = zip.OpenReader(location)
+	case isTar(location):
+		fsys, err = openTar(location)
+	default:
+		return nil, fmt.Errorf("%s: %w", location, ErrUnknownFormat)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ar, err := New(packedRoot(fsys), location)
+	if err != nil {
+		fsys.Close()
+		return nil, err
+	}
+	ar.closer = fsys
+	return ar, nil
+}
+
+// NewPacked returns the archive, packed in the ZIP file or the uncompressed
+// tarball, that is read from r of the given size, i.e. the remote file, read
+// by the ranges.  The format is detected by the extension of the name.
+func NewPacked(r io.ReaderAt, size int64, name string) (*Archive, error) {
+	var (
+		fsys fs.FS
+		err  error
+	)
+	switch lname := strings.ToLower(name); {
+	case strings.HasSuffix(lname, ".zip"):
+		fsys, err = zip.NewReader(r, size)
+	case strings.HasSuffix(lname, ".tar"):
+		fsys, err = newTarFS(io.NewSectionReader(r, 0, size), closerFunc(func() error { return nil }))
+	case isTar(lname):
+		return nil, fmt.Errorf("%s: the compressed tarball can't be read by the ranges, use the ZIP file or the uncompressed tarball", name)
+	default:
+		return nil, fmt.Errorf("%s: %w", name, ErrUnknownFormat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return New(packedRoot(fsys), name)
+}
+
+// packedRoot returns the root of the archive in the packed file fsys: if the
+// archive directory itself was packed, i.e. with "tar czf export.tar.gz
+// export", it is the only directory in the root.
+func packedRoot(fsys fs.FS) fs.FS {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return fsys
+	}
+	sub, err := fs.Sub(fsys, entries[0].Name())
+	if err != nil {
+		return fsys
+	}
+	return sub
+}
+
+// New returns the archive backed by the filesystem fsys.  name is used for
+// display purposes only.
+func New(fsys fs.FS, name string) (*Archive, error) {
+	ar := &Archive{fsys: fsys, name: name}
+	typ, err := ar.detect()
+	if err != nil {
+		return nil, err
+	}
+	ar.typ = typ
+	return ar, nil
+}
+
+func (ar *Archive) String() string {
+	return fmt.Sprintf("<%s archive: %s>", strings.ToLower(ar.typ.String()), ar.name)
+}
+
+// Name returns the name of the archive.
+func (ar *Archive) Name() string {
+	return ar.name
+}
+
+// Type returns the type of the archive.
+func (ar *Archive) Type() Type {
+	return ar.typ
+}
+
+// FS returns the underlying filesystem.
+func (ar *Archive) FS() fs.FS {
+	return ar.fsys
+}
+
+// Close closes the archive.
+func (ar *Archive) Close() error {
+	if ar.closer == nil {
+		return nil
+	}
+	return ar.closer.Close()
+}
+
+// detect detects the archive type.
+func (ar *Archive) detect() (Type, error) {
+	if isFile(ar.fsys, channelsFile) {
+		return TExport, nil
+	}
+	idx, err := ar.indexDumps()
+	if err != nil {
+		return TUnknown, err
+	}
+	if len(idx) == 0 {
+		return TUnknown, fmt.Errorf("%s: %w", ar.name, ErrUnknownFormat)
+	}
+	ar.dumpIdx = idx
+	return TDump, nil
+}
+
+// Channels returns the list of channels in the archive.
+func (ar *Archive) Channels() ([]slack.Channel, error) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	if ar.channels != nil {
+		return ar.channels, nil
+	}
+	var (
+		chans []slack.Channel
+		err   error
+	)
+	switch ar.typ {
+	case TExport:
+		chans, err = ar.exportChannels()
+	case TDump:
+		chans, err = ar.dumpChannels()
+	default:
+		err = ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(chans, func(i, j int) bool {
+		return chans[i].ID < chans[j].ID
+	})
+	ar.channels = chans
+	return chans, nil
+}
+
+// Channel returns the channel with the given ID.
+func (ar *Archive) Channel(id string) (slack.Channel, error) {
+	chans, err := ar.Channels()
+	if err != nil {
+		return slack.Channel{}, err
+	}
+	for _, ch := range chans {
+		if ch.ID == id {
+			return ch, nil
+		}
+	}
+	return slack.Channel{}, fmt.Errorf("%s: %w", id, ErrNotFound)
+}
+
+// Users returns the users from the archive.  For dumps, the users are read
+// from users.json in the archive root if present; otherwise an empty slice is
+// returned.  The exports of the Enterprise Grid organisations have the users
+// of the other teams in the separate files, they are merged into the result.
+func (ar *Archive) Users() (types.Users, error) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	if ar.users != nil {
+		return ar.users, nil
+	}
+	if !isFile(ar.fsys, usersFile) {
+		if ar.typ == TExport {
+			return nil, fmt.Errorf("%s: missing %s", ar.name, usersFile)
+		}
+		ar.users = types.Users{}
+		return ar.users, nil
+	}
+	var uu types.Users
+	if err := unmarshalFile(ar.fsys, usersFile, &uu); err != nil {
+		return nil, err
+	}
+	teamFiles, err := fs.Glob(ar.fsys, teamUsersGlob)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(uu))
+	for _, u := range uu {
+		seen[u.ID] = true
+	}
+	for _, name := range teamFiles {
+		var team types.Users
+		if err := unmarshalFile(ar.fsys, name, &team); err != nil {
+			return nil, err
+		}
+		for _, u := range team {
+			if !seen[u.ID] {
+				seen[u.ID] = true
+				uu = append(uu, u)
+			}
+		}
+	}
+	ar.users = uu
+	return uu, nil
+}
+
+// UserGroups returns the user groups from the archive, they are read from
+// the usergroups.json of the export, or the workspace.json of the dump.  If
+// the archive was created without the user groups, an empty slice is
+// returned.
+func (ar *Archive) UserGroups() ([]slack.UserGroup, error) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	if ar.userGroups != nil {
+		return ar.userGroups, nil
+	}
+	ugs := []slack.UserGroup{}
+	switch {
+	case isFile(ar.fsys, userGroupsFile):
+		if err := unmarshalFile(ar.fsys, userGroupsFile, &ugs); err != nil {
+			return nil, err
+		}
+	case isFile(ar.fsys, workspaceFile):
+		var ws types.Workspace
+		if err := unmarshalFile(ar.fsys, workspaceFile, &ws); err != nil {
+			return nil, err
+		}
+		if ws.UserGroups != nil {
+			ugs = ws.UserGroups
+		}
+	}
+	ar.userGroups = ugs
+	return ugs, nil
+}
+
+// Conversation returns the conversation with the channelID.  Messages are
+// sorted by timestamp and threads are reconstructed, so that replies are
+// attached to their parent messages.  File paths of the downloaded files are
+// relative to the root of the archive.
+func (ar *Archive) Conversation(channelID string) (*types.Conversation, error) {
+	switch ar.typ {
+	case TExport:
+		return ar.exportConversation(channelID)
+	case TDump:
+		return ar.dumpConversation(channelID)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// isFile returns true if name exists in fsys and is a regular file.
+func isFile(fsys fs.FS, name string) bool {
+	fi, err := fs.Stat(fsys, name)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// unmarshalFile decodes the JSON file name into v.
+func unmarshalFile(fsys fs.FS, name string, v any) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// rebaseFiles prefixes the local paths of the files in messages with dir.
+func rebaseFiles(msgs []types.Message, dir string) {
+	for i := range msgs {
+		for j := range msgs[i].Files {
+			f := &msgs[i].Files[j]
+			for _, p := range []*string{&f.URLPrivate, &f.URLPrivateDownload} {
+				if files.IsLocal(*p) {
+					*p = path.Join(dir, *p)
+				}
+			}
+		}
+		rebaseFiles(msgs[i].ThreadReplies, dir)
+	}
+}
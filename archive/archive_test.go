@@ -0,0 +1,269 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testExportFS = fstest.MapFS{
+	"channels.json":   {Data: []byte(`[{"id":"C01","name":"general"},{"id":"C02","name":"random"}]`)},
+	"groups.json":     {Data: []byte(`[{"id":"G01","name":"secret"}]`)},
+	"dms.json":        {Data: []byte(`[{"id":"D01","created":1600000000,"members":["U01","U02"]}]`)},
+	"users.json":      {Data: []byte(`[{"id":"U01","name":"alice"},{"id":"U02","name":"bob"}]`)},
+	"usergroups.json": {Data: []byte(`[{"id":"S01","name":"Everyone","handle":"everyone","users":["U01","U02"]}]`)},
+	"general/2023-01-02.json": {Data: []byte(`[
+		{"type":"message","user":"U02","text":"reply","ts":"1672617600.000200","thread_ts":"1672531200.000100"},
+		{"type":"message","user":"U01","text":"bcast","ts":"1672617600.000300","thread_ts":"1672531200.000100","subtype":"thread_broadcast"}
+	]`)},
+	"general/2023-01-01.json": {Data: []byte(`[
+		{"type":"message","user":"U01","text":"parent","ts":"1672531200.000100","thread_ts":"1672531200.000100","reply_count":2,
+		 "files":[{"id":"F01","name":"a.txt","url_private":"attachments/F01-a.txt","url_private_download":"attachments/F01-a.txt"}]},
+		{"type":"message","user":"U02","text":"hello","ts":"1672531300.000100"}
+	]`)},
+	"general/attachments/F01-a.txt": {Data: []byte("file contents")},
+	"D01/2023-01-01.json":           {Data: []byte(`[{"type":"message","user":"U01","text":"dm","ts":"1672531200.000100"}]`)},
+}
+
+var testDumpFS = fstest.MapFS{
+	"C01.json": {Data: []byte(`{"name":"general","channel_id":"C01","messages":[
+		{"type":"message","user":"U01","text":"one","ts":"1672531200.000100"},
+		{"type":"message","user":"U01","text":"two","ts":"1672531300.000100","files":[{"id":"F01","name":"a.txt","url_private":"C01/F01-a.txt"}]}
+	]}`)},
+	"C01-1672531400.000100.json": {Data: []byte(`{"name":"general","channel_id":"C01","thread_ts":"1672531400.000100","messages":[
+		{"type":"message","user":"U01","text":"parent","ts":"1672531400.000100","thread_ts":"1672531400.000100","reply_count":1},
+		{"type":"message","user":"U02","text":"reply","ts":"1672531500.000100","thread_ts":"1672531400.000100"}
+	]}`)},
+	"unrelated.json": {Data: []byte(`{"foo":"bar"}`)},
+	"workspace.json": {Data: []byte(`{"created":"2023-01-01T00:00:00Z","usergroups":[{"id":"S01","handle":"everyone"}]}`)},
+	"C01/F01-a.txt":  {Data: []byte("file contents")},
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    Type
+		wantErr bool
+	}{
+		{"export", testExportFS, TExport, false},
+		{"dump", testDumpFS, TDump, false},
+		{"empty", fstest.MapFS{"foo.txt": {Data: []byte("bar")}}, TUnknown, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar, err := New(tt.fsys, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, ar.Type())
+		})
+	}
+}
+
+func TestArchive_export(t *testing.T) {
+	ar, err := New(testExportFS, "test")
+	require.NoError(t, err)
+
+	chans, err := ar.Channels()
+	require.NoError(t, err)
+	var ids []string
+	for _, ch := range chans {
+		ids = append(ids, ch.ID)
+	}
+	assert.Equal(t, []string{"C01", "C02", "D01", "G01"}, ids)
+
+	dm, err := ar.Channel("D01")
+	require.NoError(t, err)
+	assert.True(t, dm.IsIM)
+	assert.Equal(t, "U01", dm.User)
+
+	users, err := ar.Users()
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	ugs, err := ar.UserGroups()
+	require.NoError(t, err)
+	require.Len(t, ugs, 1)
+	assert.Equal(t, "everyone", ugs[0].Handle)
+
+	cnv, err := ar.Conversation("C01")
+	require.NoError(t, err)
+	require.Len(t, cnv.Messages, 3)
+	assert.Equal(t, "parent", cnv.Messages[0].Text)
+	assert.Equal(t, "general/attachments/F01-a.txt", cnv.Messages[0].Files[0].URLPrivate)
+	require.Len(t, cnv.Messages[0].ThreadReplies, 2)
+	assert.Equal(t, "reply", cnv.Messages[0].ThreadReplies[0].Text)
+	assert.Equal(t, "hello", cnv.Messages[1].Text)
+	assert.Equal(t, "bcast", cnv.Messages[2].Text)
+
+	empty, err := ar.Conversation("C02")
+	require.NoError(t, err)
+	assert.Empty(t, empty.Messages)
+
+	_, err = ar.Conversation("C99")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestArchive_exportSanitizedDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"channels.json":        {Data: []byte(`[{"id":"C01","name":"aux"}]`)},
+		"_aux/2023-01-01.json": {Data: []byte(`[{"type":"message","user":"U01","text":"hello","ts":"1672531200.000100"}]`)},
+	}
+	ar, err := New(fsys, "test")
+	require.NoError(t, err)
+	cnv, err := ar.Conversation("C01")
+	require.NoError(t, err)
+	require.Len(t, cnv.Messages, 1)
+	assert.Equal(t, "hello", cnv.Messages[0].Text)
+}
+
+func TestArchive_UsersByTeam(t *testing.T) {
+	fsys := fstest.MapFS{
+		"channels.json":    {Data: []byte(`[{"id":"C01","name":"general"}]`)},
+		"users.json":       {Data: []byte(`[{"id":"W01","name":"alice","team_id":"T01"}]`)},
+		"users-T02.json":   {Data: []byte(`[{"id":"W02","name":"bob","team_id":"T02"},{"id":"W01","name":"alice","team_id":"T01"}]`)},
+		"users-T03.json":   {Data: []byte(`[{"id":"W03","name":"carol","team_id":"T03"}]`)},
+		"general/log.json": {Data: []byte(`[]`)},
+	}
+	ar, err := New(fsys, "test")
+	require.NoError(t, err)
+	users, err := ar.Users()
+	require.NoError(t, err)
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Name)
+	}
+	assert.Equal(t, []string{"alice", "bob", "carol"}, names, "users of all teams, without duplicates")
+}
+
+func TestArchive_dump(t *testing.T) {
+	ar, err := New(testDumpFS, "test")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{"C01": {"C01-1672531400.000100.json", "C01.json"}}, ar.DumpFiles())
+
+	chans, err := ar.Channels()
+	require.NoError(t, err)
+	require.Len(t, chans, 1)
+	assert.Equal(t, "general", chans[0].Name)
+
+	users, err := ar.Users()
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	ugs, err := ar.UserGroups()
+	require.NoError(t, err)
+	require.Len(t, ugs, 1)
+	assert.Equal(t, "S01", ugs[0].ID)
+
+	cnv, err := ar.Conversation("C01")
+	require.NoError(t, err)
+	require.Len(t, cnv.Messages, 3)
+	assert.Equal(t, "C01/F01-a.txt", cnv.Messages[1].Files[0].URLPrivate)
+	assert.Equal(t, "parent", cnv.Messages[2].Text)
+	assert.Len(t, cnv.Messages[2].ThreadReplies, 1)
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	zipname := filepath.Join(dir, "export.zip")
+	f, err := os.Create(zipname)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for name, file := range testExportFS {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(file.Data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	ar, err := Open(zipname)
+	require.NoError(t, err)
+	defer ar.Close()
+	assert.Equal(t, TExport, ar.Type())
+	cnv, err := ar.Conversation("D01")
+	require.NoError(t, err)
+	assert.Len(t, cnv.Messages, 1)
+
+	_, err = Open(filepath.Join(dir, "nonexistent"))
+	assert.Error(t, err)
+}
+
+func TestNewPacked(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, file := range testExportFS {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(file.Data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	ar, err := NewPacked(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "s3://bucket/export.zip")
+	require.NoError(t, err)
+	assert.Equal(t, TExport, ar.Type())
+	cnv, err := ar.Conversation("D01")
+	require.NoError(t, err)
+	assert.Len(t, cnv.Messages, 1)
+
+	_, err = NewPacked(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "s3://bucket/export.tar.gz")
+	assert.Error(t, err)
+}
+
+func TestOpen_tar(t *testing.T) {
+	dir := t.TempDir()
+	// writeTar packs the test export into the directory "export" of the
+	// tarball name.
+	writeTar := func(name string, compress bool) {
+		f, err := os.Create(filepath.Join(dir, name))
+		require.NoError(t, err)
+		defer f.Close()
+		var w io.Writer = f
+		if compress {
+			gz := gzip.NewWriter(f)
+			defer gz.Close()
+			w = gz
+		}
+		tw := tar.NewWriter(w)
+		for name, file := range testExportFS {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: "export/" + name, Mode: 0644, Size: int64(len(file.Data))}))
+			_, err := tw.Write(file.Data)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+	}
+	writeTar("export.tar", false)
+	writeTar("export.tar.gz", true)
+
+	for _, name := range []string{"export.tar", "export.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			ar, err := Open(filepath.Join(dir, name))
+			require.NoError(t, err)
+			defer ar.Close()
+			assert.Equal(t, TExport, ar.Type())
+			cnv, err := ar.Conversation("C01")
+			require.NoError(t, err)
+			assert.Len(t, cnv.Messages, 3)
+			data, err := fs.ReadFile(ar.FS(), "general/attachments/F01-a.txt")
+			require.NoError(t, err)
+			assert.Equal(t, "file contents", string(data))
+		})
+	}
+}
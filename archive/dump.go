@@ -0,0 +1,146 @@
+package archive
+
+// In this file: conversation dump reader.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// dumpHeader is used to peek into the conversation file without allocating
+// messages.
+type dumpHeader struct {
+	Name     string `json:"name"`
+	ID       string `json:"channel_id"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+}
+
+// indexDumps scans the root of the filesystem for conversation dumps and
+// returns the mapping of channel ID to the list of files.  Files in the root
+// that do not look like conversations, are silently skipped.
+func (ar *Archive) indexDumps() (map[string][]string, error) {
+	entries, err := fs.ReadDir(ar.fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	idx := make(map[string][]string)
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".json") || e.Name() == usersFile {
+			continue
+		}
+		hdr, err := readDumpHeader(ar.fsys, e.Name())
+		if err != nil || hdr.ID == "" {
+			continue
+		}
+		idx[hdr.ID] = append(idx[hdr.ID], e.Name())
+	}
+	for id := range idx {
+		sort.Strings(idx[id])
+	}
+	return idx, nil
+}
+
+// DumpFiles returns the conversation files of the dump by the channel ID, the
+// file names of each channel are sorted.  It returns nil, if the archive is
+// not a dump.
+func (ar *Archive) DumpFiles() map[string][]string {
+	if ar.dumpIdx == nil {
+		return nil
+	}
+	files := make(map[string][]string, len(ar.dumpIdx))
+	for id, names := range ar.dumpIdx {
+		files[id] = append([]string(nil), names...)
+	}
+	return files
+}
+
+func readDumpHeader(fsys fs.FS, name string) (dumpHeader, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return dumpHeader{}, err
+	}
+	defer f.Close()
+	var hdr dumpHeader
+	if err := json.NewDecoder(f).Decode(&hdr); err != nil {
+		return dumpHeader{}, err
+	}
+	return hdr, nil
+}
+
+// dumpChannels returns the channels from the dump index.
+func (ar *Archive) dumpChannels() ([]slack.Channel, error) {
+	chans := make([]slack.Channel, 0, len(ar.dumpIdx))
+	for id, files := range ar.dumpIdx {
+		var ch slack.Channel
+		ch.ID = id
+		ch.IsIM = strings.HasPrefix(id, "D")
+		for _, name := range files {
+			hdr, err := readDumpHeader(ar.fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			if ch.Name == "" || hdr.ThreadTS == "" {
+				ch.Name = hdr.Name
+			}
+		}
+		chans = append(chans, ch)
+	}
+	return chans, nil
+}
+
+// dumpConversation reads and merges all the files of the same conversation.
+func (ar *Archive) dumpConversation(channelID string) (*types.Conversation, error) {
+	files, ok := ar.dumpIdx[channelID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", channelID, ErrNotFound)
+	}
+	var (
+		result types.Conversation
+		seen   = make(map[string]int)
+	)
+	for _, name := range files {
+		var cnv types.Conversation
+		if err := unmarshalFile(ar.fsys, name, &cnv); err != nil {
+			return nil, err
+		}
+		if result.ID == "" || cnv.ThreadTS == "" {
+			result.ID = cnv.ID
+			result.Name = cnv.Name
+		}
+		if cnv.ThreadTS != "" {
+			// thread dump: the first message is the parent.
+			cnv.Messages = threadAsParent(cnv.Messages)
+		}
+		for _, m := range cnv.Messages {
+			if i, ok := seen[m.Timestamp]; ok {
+				if len(result.Messages[i].ThreadReplies) < len(m.ThreadReplies) {
+					result.Messages[i] = m
+				}
+				continue
+			}
+			seen[m.Timestamp] = len(result.Messages)
+			result.Messages = append(result.Messages, m)
+		}
+	}
+	types.SortMessages(result.Messages)
+	return &result, nil
+}
+
+// threadAsParent converts the flat list of thread messages, as it appears in
+// the thread dump, into the parent message with replies.
+func threadAsParent(msgs []types.Message) []types.Message {
+	if len(msgs) == 0 {
+		return msgs
+	}
+	types.SortMessages(msgs)
+	parent := msgs[0]
+	parent.ThreadReplies = append([]types.Message{}, msgs[1:]...)
+	return []types.Message{parent}
+}
@@ -0,0 +1,176 @@
+package archive
+
+// In this file: Slack Export format reader.
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/sanitize"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// exportDM is the entry of the dms.json.
+type exportDM struct {
+	ID      string   `json:"id"`
+	Created int64    `json:"created"`
+	Members []string `json:"members"`
+}
+
+// exportChannels reads channels from all the index files of the export.
+func (ar *Archive) exportChannels() ([]slack.Channel, error) {
+	var all []slack.Channel
+	for _, idx := range []struct {
+		filename string
+		fn       func(*slack.Channel)
+	}{
+		{channelsFile, func(*slack.Channel) {}},
+		{"groups.json", func(ch *slack.Channel) { ch.IsGroup = true; ch.IsPrivate = true }},
+		{"mpims.json", func(ch *slack.Channel) { ch.IsMpIM = true; ch.IsPrivate = true }},
+	} {
+		if !isFile(ar.fsys, idx.filename) {
+			continue
+		}
+		var chans []slack.Channel
+		if err := unmarshalFile(ar.fsys, idx.filename, &chans); err != nil {
+			return nil, err
+		}
+		for i := range chans {
+			idx.fn(&chans[i])
+		}
+		all = append(all, chans...)
+	}
+	if isFile(ar.fsys, "dms.json") {
+		var dms []exportDM
+		if err := unmarshalFile(ar.fsys, "dms.json", &dms); err != nil {
+			return nil, err
+		}
+		for _, dm := range dms {
+			var ch slack.Channel
+			ch.ID = dm.ID
+			ch.IsIM = true
+			ch.Created = slack.JSONTime(dm.Created)
+			ch.Members = dm.Members
+			if len(dm.Members) > 0 {
+				ch.User = dm.Members[0]
+			}
+			all = append(all, ch)
+		}
+	}
+	return all, nil
+}
+
+// exportDir returns the directory name of the channel in the export.  See
+// export.validName.
+func exportDir(ch slack.Channel) string {
+	if ch.IsIM {
+		return ch.ID
+	}
+	return ch.Name
+}
+
+// ExportDir returns the directory of the channel ch in the export fsys.  If
+// the directory does not exist, but the sanitized one does, i.e. the export
+// was created with the windows-safe filename profile, or repaired with
+// "slackdump tools fixnames", the sanitized name is returned.
+func ExportDir(fsys fs.FS, ch slack.Channel) string {
+	dir := exportDir(ch)
+	if safe := sanitize.WindowsSafe.Name(dir); safe != dir {
+		if _, err := fs.Stat(fsys, dir); errors.Is(err, fs.ErrNotExist) {
+			if _, err := fs.Stat(fsys, safe); err == nil {
+				return safe
+			}
+		}
+	}
+	return dir
+}
+
+// exportConversation reads all daily files of the channel.
+func (ar *Archive) exportConversation(channelID string) (*types.Conversation, error) {
+	ch, err := ar.Channel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	dir := ExportDir(ar.fsys, ch)
+	entries, err := fs.ReadDir(ar.fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// channel without messages.
+			return &types.Conversation{Name: ch.Name, ID: ch.ID}, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var msgs []types.Message
+	for _, name := range names {
+		var day []types.Message
+		if err := unmarshalFile(ar.fsys, path.Join(dir, name), &day); err != nil {
+			return nil, fmt.Errorf("channel %s: %w", channelID, err)
+		}
+		msgs = append(msgs, day...)
+	}
+	// export stores the file references relative to the channel directory.
+	rebaseFiles(msgs, dir)
+
+	return &types.Conversation{
+		Name:     ch.Name,
+		ID:       ch.ID,
+		Messages: BuildThreads(msgs),
+	}, nil
+}
+
+// BuildThreads takes the flat list of messages, as they appear in the Slack
+// Export, and attaches thread replies to their parent messages.  Broadcast
+// replies are kept in both places.  Replies, for which the parent is not
+// present are left on the top level.  Returned messages are sorted by
+// timestamp.
+func BuildThreads(msgs []types.Message) []types.Message {
+	types.SortMessages(msgs)
+	var (
+		top     = make([]types.Message, 0, len(msgs))
+		parents = make(map[string]int, 0)
+		orphans []types.Message
+	)
+	for _, m := range msgs {
+		if isReply(m) {
+			orphans = append(orphans, m)
+			if m.SubType != "thread_broadcast" {
+				continue
+			}
+		}
+		if m.IsThread() && m.ThreadTimestamp == m.Timestamp {
+			parents[m.Timestamp] = len(top)
+		}
+		top = append(top, m)
+	}
+	for _, m := range orphans {
+		idx, ok := parents[m.ThreadTimestamp]
+		if !ok {
+			if m.SubType != "thread_broadcast" {
+				top = append(top, m)
+			}
+			continue
+		}
+		top[idx].ThreadReplies = append(top[idx].ThreadReplies, m)
+	}
+	types.SortMessages(top)
+	return top
+}
+
+// isReply returns true if the message is a thread reply.
+func isReply(m types.Message) bool {
+	return m.IsThread() && m.ThreadTimestamp != m.Timestamp
+}
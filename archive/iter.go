@@ -0,0 +1,323 @@
+package archive
+
+// In this file: the iterators over the messages and the files of the
+// archive.
+//
+// The iterators have the type of the iter.Seq2 of Go 1.23, so that they can
+// be ranged over:
+//
+//	for m, err := range ar.Messages("C01") {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(m.Text)
+//	}
+//
+// and passed where the iter.Seq2 is expected, while the module still builds
+// with the older Go versions, where they are called with the yield function.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// errStop is returned by the walk functions, when the consumer of the
+// iterator stops the iteration.
+var errStop = errors.New("stop")
+
+// FileRef is the file, attached to the message of the archive.
+type FileRef struct {
+	ChannelID string
+	// MessageTS is the timestamp of the message, the file is attached to.
+	MessageTS string
+	// File is the file, the local paths in the URLPrivate and the
+	// URLPrivateDownload are relative to the root of the archive.
+	File slack.File
+}
+
+// Messages returns the iterator over the messages of the channel, including
+// the thread replies, that are yielded as the separate messages, and have
+// the ThreadReplies empty.  The messages are read from the files of the
+// archive one at a time, without loading the conversation.  They are yielded
+// in the order they are stored: day by day for the exports, and file by file
+// for the dumps, where the replies follow their parent.  The duplicates,
+// i.e. the messages of the overlapping dumps, are yielded once.  On error,
+// the error is yielded, and the iteration stops.
+func (ar *Archive) Messages(channelID string) func(yield func(types.Message, error) bool) {
+	return func(yield func(types.Message, error) bool) {
+		seen := make(map[string]bool)
+		emit := func(m types.Message) error {
+			if seen[m.Timestamp] {
+				return nil
+			}
+			seen[m.Timestamp] = true
+			if !yield(m, nil) {
+				return errStop
+			}
+			return nil
+		}
+		err := ar.walkMessages(channelID, func(m types.Message) error {
+			replies := m.ThreadReplies
+			m.ThreadReplies = nil
+			if err := emit(m); err != nil {
+				return err
+			}
+			for _, r := range replies {
+				if err := emit(r); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStop) {
+			yield(types.Message{}, err)
+		}
+	}
+}
+
+// Threads returns the iterator over the threads of the channel: the parent
+// messages with the ThreadReplies.  The threads of the dumps, that are
+// stored with their parent, are yielded as they are read.  The replies of
+// the exports, and of the thread dumps, are stored separately from their
+// parent, so they are collected while the channel is read, and these threads
+// are yielded at the end, sorted by the parent timestamp.  The replies
+// without the parent are not yielded.  On error, the error is yielded, and
+// the iteration stops.
+func (ar *Archive) Threads(channelID string) func(yield func(types.Message, error) bool) {
+	return func(yield func(types.Message, error) bool) {
+		var (
+			seen    = make(map[string]bool) // yielded threads.
+			parents = make(map[string]types.Message)
+			replies = make(map[string][]types.Message)
+		)
+		err := ar.walkMessages(channelID, func(m types.Message) error {
+			switch {
+			case len(m.ThreadReplies) > 0:
+				if seen[m.Timestamp] {
+					return nil
+				}
+				seen[m.Timestamp] = true
+				if !yield(m, nil) {
+					return errStop
+				}
+			case isReply(m):
+				replies[m.ThreadTimestamp] = append(replies[m.ThreadTimestamp], m)
+			case m.IsThread():
+				parents[m.Timestamp] = m
+			}
+			return nil
+		})
+		if err != nil {
+			if !errors.Is(err, errStop) {
+				yield(types.Message{}, err)
+			}
+			return
+		}
+		tss := make([]string, 0, len(parents))
+		for ts := range parents {
+			if !seen[ts] && len(replies[ts]) > 0 {
+				tss = append(tss, ts)
+			}
+		}
+		sort.Strings(tss)
+		for _, ts := range tss {
+			parent := parents[ts]
+			parent.ThreadReplies = dedupe(replies[ts])
+			if !yield(parent, nil) {
+				return
+			}
+		}
+	}
+}
+
+// dedupe sorts the messages, and removes the duplicates.
+func dedupe(msgs []types.Message) []types.Message {
+	types.SortMessages(msgs)
+	out := msgs[:0]
+	for i, m := range msgs {
+		if i > 0 && m.Timestamp == msgs[i-1].Timestamp {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// Files returns the iterator over the files of all channels of the archive,
+// see FileRef.  The files are not opened, use the FS of the archive to read
+// the downloaded ones.  On error, the error is yielded, and the iteration
+// stops.
+func (ar *Archive) Files() func(yield func(FileRef, error) bool) {
+	return func(yield func(FileRef, error) bool) {
+		chans, err := ar.Channels()
+		if err != nil {
+			yield(FileRef{}, err)
+			return
+		}
+		for _, ch := range chans {
+			stop := false
+			ar.Messages(ch.ID)(func(m types.Message, err error) bool {
+				if err != nil {
+					yield(FileRef{}, err)
+					stop = true
+					return false
+				}
+				for _, f := range m.Files {
+					if !yield(FileRef{ChannelID: ch.ID, MessageTS: m.Timestamp, File: f}, nil) {
+						stop = true
+						return false
+					}
+				}
+				return true
+			})
+			if stop {
+				return
+			}
+		}
+	}
+}
+
+// walkMessages calls fn for each message of the channel, as they are stored
+// in the archive, the messages are decoded one at a time.
+func (ar *Archive) walkMessages(channelID string, fn func(types.Message) error) error {
+	switch ar.typ {
+	case TExport:
+		return ar.walkExport(channelID, fn)
+	case TDump:
+		return ar.walkDump(channelID, fn)
+	}
+	return ErrUnknownFormat
+}
+
+func (ar *Archive) walkExport(channelID string, fn func(types.Message) error) error {
+	ch, err := ar.Channel(channelID)
+	if err != nil {
+		return err
+	}
+	dir := ExportDir(ar.fsys, ch)
+	entries, err := fs.ReadDir(ar.fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// channel without messages.
+			return nil
+		}
+		return err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		err := walkFile(ar.fsys, path.Join(dir, name), func(dec *json.Decoder) error {
+			return decodeMessages(dec, func(m types.Message) error {
+				// export stores the file references relative to the
+				// channel directory.
+				rebaseFiles([]types.Message{m}, dir)
+				return fn(m)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ar *Archive) walkDump(channelID string, fn func(types.Message) error) error {
+	files, ok := ar.dumpIdx[channelID]
+	if !ok {
+		return fmt.Errorf("%s: %w", channelID, ErrNotFound)
+	}
+	for _, name := range files {
+		err := walkFile(ar.fsys, name, func(dec *json.Decoder) error {
+			if err := expectDelim(dec, '{'); err != nil {
+				return err
+			}
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if key, _ := tok.(string); key != "messages" {
+					var skip json.RawMessage
+					if err := dec.Decode(&skip); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := decodeMessages(dec, fn); err != nil {
+					return err
+				}
+			}
+			return expectDelim(dec, '}')
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkFile opens the file name, and calls fn with the decoder of it.  The
+// errors, except errStop, are prefixed with the name of the file.
+func walkFile(fsys fs.FS, name string, fn func(*json.Decoder) error) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = fn(json.NewDecoder(f))
+	if err != nil && !errors.Is(err, errStop) {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return err
+}
+
+// decodeMessages decodes the JSON array of the messages, or null, from dec,
+// one message at a time, and calls fn for each.
+func decodeMessages(dec *json.Decoder, fn func(types.Message) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("expected the array of messages, got %v", tok)
+	}
+	for dec.More() {
+		var m types.Message
+		if err := dec.Decode(&m); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// expectDelim reads the delimiter want from dec.
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %q, got %v", want, tok)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build go1.23
+
+package archive
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestArchive_rangeOverFunc(t *testing.T) {
+	ar, err := New(testExportFS, "test")
+	require.NoError(t, err)
+
+	var seq iter.Seq2[types.Message, error] = ar.Messages("C01")
+	var texts []string
+	for m, err := range seq {
+		require.NoError(t, err)
+		texts = append(texts, m.Text)
+		if len(texts) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"parent", "hello"}, texts)
+
+	for f, err := range ar.Files() {
+		require.NoError(t, err)
+		assert.Equal(t, "F01", f.File.ID)
+	}
+	for m, err := range ar.Threads("C01") {
+		require.NoError(t, err)
+		assert.Len(t, m.ThreadReplies, 2)
+	}
+}
@@ -0,0 +1,178 @@
+package archive
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// collect collects the timestamps of the messages, yielded by seq, until the
+// error or n messages (0 - all).
+func collect(seq func(yield func(types.Message, error) bool), n int) ([]string, error) {
+	var (
+		tss []string
+		err error
+	)
+	seq(func(m types.Message, e error) bool {
+		if e != nil {
+			err = e
+			return false
+		}
+		tss = append(tss, m.Timestamp)
+		return n == 0 || len(tss) < n
+	})
+	return tss, err
+}
+
+func TestArchive_Messages(t *testing.T) {
+	t.Run("export", func(t *testing.T) {
+		ar, err := New(testExportFS, "test")
+		require.NoError(t, err)
+		tss, err := collect(ar.Messages("C01"), 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1672531200.000100", "1672531300.000100", "1672617600.000200", "1672617600.000300"}, tss)
+
+		var files []string
+		ar.Messages("C01")(func(m types.Message, err error) bool {
+			for _, f := range m.Files {
+				files = append(files, f.URLPrivate)
+			}
+			return true
+		})
+		assert.Equal(t, []string{"general/attachments/F01-a.txt"}, files, "the paths are relative to the root")
+	})
+	t.Run("dump", func(t *testing.T) {
+		ar, err := New(testDumpFS, "test")
+		require.NoError(t, err)
+		tss, err := collect(ar.Messages("C01"), 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1672531400.000100", "1672531500.000100", "1672531200.000100", "1672531300.000100"}, tss)
+	})
+	t.Run("dump with the replies and the duplicates", func(t *testing.T) {
+		ar, err := New(fstest.MapFS{
+			"C01.json": {Data: []byte(`{"name":"general","channel_id":"C01","messages":[
+				{"ts":"1.000100","thread_ts":"1.000100","slackdump_thread_replies":[{"ts":"2.000100","thread_ts":"1.000100"}]},
+				{"ts":"3.000100"}
+			]}`)},
+			"C01_2.json": {Data: []byte(`{"channel_id":"C01","messages":[{"ts":"3.000100"},{"ts":"4.000100"}],"name":"general"}`)},
+		}, "test")
+		require.NoError(t, err)
+		var got []types.Message
+		ar.Messages("C01")(func(m types.Message, err error) bool {
+			require.NoError(t, err)
+			got = append(got, m)
+			return true
+		})
+		require.Len(t, got, 4)
+		assert.Equal(t, "2.000100", got[1].Timestamp, "the reply follows the parent")
+		assert.Empty(t, got[0].ThreadReplies)
+		assert.Equal(t, "4.000100", got[3].Timestamp)
+	})
+	t.Run("stop", func(t *testing.T) {
+		ar, err := New(testExportFS, "test")
+		require.NoError(t, err)
+		tss, err := collect(ar.Messages("C01"), 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1672531200.000100"}, tss)
+	})
+	t.Run("not found", func(t *testing.T) {
+		ar, err := New(testDumpFS, "test")
+		require.NoError(t, err)
+		_, err = collect(ar.Messages("C99"), 0)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("invalid file", func(t *testing.T) {
+		ar, err := New(fstest.MapFS{
+			"channels.json":           {Data: []byte(`[{"id":"C01","name":"general"}]`)},
+			"general/2023-01-01.json": {Data: []byte(`[{"ts":"1.000100"},{"ts":`)},
+		}, "test")
+		require.NoError(t, err)
+		tss, err := collect(ar.Messages("C01"), 0)
+		assert.Error(t, err)
+		assert.Equal(t, []string{"1.000100"}, tss, "the messages before the error are yielded")
+	})
+}
+
+func TestArchive_Threads(t *testing.T) {
+	threads := func(t *testing.T, ar *Archive, channelID string) []types.Message {
+		var got []types.Message
+		ar.Threads(channelID)(func(m types.Message, err error) bool {
+			require.NoError(t, err)
+			got = append(got, m)
+			return true
+		})
+		return got
+	}
+	t.Run("export", func(t *testing.T) {
+		ar, err := New(testExportFS, "test")
+		require.NoError(t, err)
+		got := threads(t, ar, "C01")
+		require.Len(t, got, 1)
+		assert.Equal(t, "parent", got[0].Text)
+		require.Len(t, got[0].ThreadReplies, 2)
+		assert.Equal(t, "reply", got[0].ThreadReplies[0].Text)
+		assert.Equal(t, "bcast", got[0].ThreadReplies[1].Text)
+	})
+	t.Run("thread dump", func(t *testing.T) {
+		ar, err := New(testDumpFS, "test")
+		require.NoError(t, err)
+		got := threads(t, ar, "C01")
+		require.Len(t, got, 1)
+		assert.Equal(t, "parent", got[0].Text)
+		assert.Len(t, got[0].ThreadReplies, 1)
+	})
+	t.Run("error", func(t *testing.T) {
+		ar, err := New(testDumpFS, "test")
+		require.NoError(t, err)
+		var gotErr error
+		ar.Threads("C99")(func(m types.Message, err error) bool {
+			gotErr = err
+			return true
+		})
+		assert.ErrorIs(t, gotErr, ErrNotFound)
+	})
+}
+
+func TestArchive_Files(t *testing.T) {
+	ar, err := New(testExportFS, "test")
+	require.NoError(t, err)
+	var got []FileRef
+	ar.Files()(func(f FileRef, err error) bool {
+		require.NoError(t, err)
+		got = append(got, f)
+		return true
+	})
+	require.Len(t, got, 1)
+	assert.Equal(t, "C01", got[0].ChannelID)
+	assert.Equal(t, "1672531200.000100", got[0].MessageTS)
+	assert.Equal(t, "F01", got[0].File.ID)
+	assert.Equal(t, "general/attachments/F01-a.txt", got[0].File.URLPrivate)
+
+	t.Run("error", func(t *testing.T) {
+		ar, err := New(fstest.MapFS{
+			"channels.json":           {Data: []byte(`[{"id":"C01","name":"general"},{"id":"C02","name":"random"}]`)},
+			"general/2023-01-01.json": {Data: []byte(`{}`)},
+			"random/2023-01-01.json":  {Data: []byte(`[{"ts":"1.000100","files":[{"id":"F01"}]}]`)},
+		}, "test")
+		require.NoError(t, err)
+		var (
+			n      int
+			gotErr error
+		)
+		ar.Files()(func(f FileRef, err error) bool {
+			if err != nil {
+				gotErr = err
+			}
+			n++
+			return true
+		})
+		assert.Error(t, gotErr)
+		assert.False(t, errors.Is(gotErr, errStop))
+		assert.Equal(t, 1, n, "the iteration stops on error")
+	})
+}
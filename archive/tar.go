@@ -0,0 +1,227 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// isTar returns true if the name has the tarball extension.
+func isTar(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// openTar opens the tarball name.  The uncompressed tarball is read in
+// place: it is indexed once, and the files are read at their offsets.  The
+// gzip stream doesn't allow the random access, so the compressed tarball is
+// decompressed to the temporary file first, which is removed on Close.
+func openTar(name string) (*tarFS, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	lname := strings.ToLower(name)
+	if !strings.HasSuffix(lname, ".gz") && !strings.HasSuffix(lname, ".tgz") {
+		return newTarFS(f, f)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	tmp, err := os.CreateTemp("", "slackdump-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	rm := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	if _, err := io.Copy(tmp, gz); err != nil {
+		rm()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		rm()
+		return nil, err
+	}
+	tfs, err := newTarFS(tmp, closerFunc(func() error {
+		defer os.Remove(tmp.Name())
+		return tmp.Close()
+	}))
+	if err != nil {
+		rm()
+		return nil, err
+	}
+	return tfs, nil
+}
+
+type closerFunc func() error
+
+func (fn closerFunc) Close() error { return fn() }
+
+// tarFS is the read-only filesystem of the tarball.
+type tarFS struct {
+	r       io.ReaderAt
+	closer  io.Closer
+	entries map[string]*tarEntry // by the clean path, the root is "."
+}
+
+// tarEntry is the file or the directory in the tarball.
+type tarEntry struct {
+	name    string // clean path
+	mode    fs.FileMode
+	modTime time.Time
+	off     int64 // offset of the file data
+	size    int64
+	// children are the sorted base names of the directory entries.
+	children []string
+}
+
+// newTarFS indexes the tarball in rs.  The directories, that are missing in
+// the tarball, are created from the file paths.
+func newTarFS(rs interface {
+	io.ReadSeeker
+	io.ReaderAt
+}, closer io.Closer) (*tarFS, error) {
+	tfs := &tarFS{
+		r:       rs,
+		closer:  closer,
+		entries: map[string]*tarEntry{".": {name: ".", mode: fs.ModeDir | 0555}},
+	}
+	tr := tar.NewReader(rs)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			closer.Close()
+			return nil, err
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if !fs.ValidPath(name) || name == "." {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			tfs.add(&tarEntry{name: name, mode: fs.ModeDir | hdr.FileInfo().Mode().Perm(), modTime: hdr.ModTime})
+		case tar.TypeReg, tar.TypeRegA:
+			// the data of the current entry starts at the current
+			// position, as the reader doesn't read ahead.
+			off, err := rs.Seek(0, io.SeekCurrent)
+			if err != nil {
+				closer.Close()
+				return nil, err
+			}
+			tfs.add(&tarEntry{name: name, mode: hdr.FileInfo().Mode().Perm(), modTime: hdr.ModTime, off: off, size: hdr.Size})
+		}
+	}
+	for _, e := range tfs.entries {
+		sort.Strings(e.children)
+	}
+	return tfs, nil
+}
+
+// add adds the entry and its parent directories to the index.
+func (tfs *tarFS) add(e *tarEntry) {
+	if old, ok := tfs.entries[e.name]; ok {
+		if old.mode.IsDir() && e.mode.IsDir() {
+			old.mode, old.modTime = e.mode, e.modTime
+			return
+		}
+		// the later entry of the same name replaces the earlier one.
+		e.children = old.children
+		tfs.entries[e.name] = e
+		return
+	}
+	tfs.entries[e.name] = e
+	dir := path.Dir(e.name)
+	parent, ok := tfs.entries[dir]
+	if !ok {
+		parent = &tarEntry{name: dir, mode: fs.ModeDir | 0555}
+		tfs.add(parent)
+	}
+	parent.children = append(parent.children, path.Base(e.name))
+}
+
+func (tfs *tarFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	e, ok := tfs.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if e.mode.IsDir() {
+		return &tarDir{tfs: tfs, e: e}, nil
+	}
+	return &tarFile{e: e, SectionReader: io.NewSectionReader(tfs.r, e.off, e.size)}, nil
+}
+
+func (tfs *tarFS) Close() error {
+	return tfs.closer.Close()
+}
+
+// tarEntry implements fs.FileInfo and fs.DirEntry.
+func (e *tarEntry) Name() string               { return path.Base(e.name) }
+func (e *tarEntry) Size() int64                { return e.size }
+func (e *tarEntry) Mode() fs.FileMode          { return e.mode }
+func (e *tarEntry) ModTime() time.Time         { return e.modTime }
+func (e *tarEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e *tarEntry) Sys() any                   { return nil }
+func (e *tarEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e *tarEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+// tarFile is the open file of the tarball.
+type tarFile struct {
+	e *tarEntry
+	*io.SectionReader
+}
+
+func (f *tarFile) Stat() (fs.FileInfo, error) { return f.e, nil }
+func (f *tarFile) Close() error               { return nil }
+
+// tarDir is the open directory of the tarball.
+type tarDir struct {
+	tfs *tarFS
+	e   *tarEntry
+	pos int // position in e.children
+}
+
+func (d *tarDir) Stat() (fs.FileInfo, error) { return d.e, nil }
+func (d *tarDir) Close() error               { return nil }
+
+func (d *tarDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.e.name, Err: errors.New("is a directory")}
+}
+
+func (d *tarDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.e.children[d.pos:]
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && n < len(rest) {
+		rest = rest[:n]
+	}
+	entries := make([]fs.DirEntry, len(rest))
+	for i, name := range rest {
+		entries[i] = d.tfs.entries[path.Join(d.e.name, name)]
+	}
+	d.pos += len(rest)
+	return entries, nil
+}
@@ -0,0 +1,116 @@
+package slackdump
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/progress"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestSession_Archive(t *testing.T) {
+	t.Run("saves conversations", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mockWorkspace(mc)
+		mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+			&slack.GetConversationHistoryResponse{
+				Messages:      []slack.Message{testMsg1.Message},
+				SlackResponse: slack.SlackResponse{Ok: true},
+			},
+			nil,
+		)
+		mockConvInfo(mc, "CHM82GF99", "unittest")
+
+		prev := fsadapter.NewDirectory(t.TempDir())
+		sd := &Session{client: mc, fs: prev, options: DefOptions}
+		dir := t.TempDir()
+		res, err := sd.Archive(context.Background(), dir, time.Time{}, time.Time{}, "CHM82GF99")
+		require.NoError(t, err)
+		assert.Equal(t, prev, sd.fs, "session filesystem is restored")
+		assert.Nil(t, sd.options.Progress, "progress reporter is restored")
+		require.Len(t, res.Channels, 1)
+		assert.Equal(t, ChannelResult{ID: "CHM82GF99", Name: "unittest", Messages: 1, Duration: res.Channels[0].Duration}, res.Channels[0])
+
+		data, err := os.ReadFile(filepath.Join(dir, "CHM82GF99.json"))
+		require.NoError(t, err)
+		var got types.Conversation
+		require.NoError(t, json.Unmarshal(data, &got))
+		assert.Equal(t, "unittest", got.Name)
+		assert.Equal(t, []types.Message{testMsg1}, got.Messages)
+		assert.FileExists(t, filepath.Join(dir, ManifestFile))
+		assert.FileExists(t, filepath.Join(dir, WorkspaceFile))
+	})
+	t.Run("error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mockWorkspace(mc)
+		mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(nil, errors.New("test error"))
+
+		sd := &Session{client: mc, options: DefOptions}
+		sd.options.Tier3Retries = 0
+		res, err := sd.Archive(context.Background(), t.TempDir(), time.Time{}, time.Time{}, "CHM82GF99")
+		assert.ErrorContains(t, err, "CHM82GF99")
+		if assert.Len(t, res.Failed(), 1) {
+			assert.ErrorContains(t, res.Failed()[0].Err, "test error")
+		}
+	})
+	t.Run("deadline budget", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mockWorkspace(mc)
+		mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+			&slack.GetConversationHistoryResponse{
+				Messages:      []slack.Message{testMsg1.Message},
+				SlackResponse: slack.SlackResponse{Ok: true},
+			},
+			nil,
+		)
+		mockConvInfo(mc, "CHM82GF99", "unittest")
+
+		// the budget is spent after the first conversation.
+		rep := &budgetReporter{}
+		sd := &Session{client: mc, options: DefOptions}
+		sd.options.Progress = rep
+		rep.sd = sd
+		dir := t.TempDir()
+		res, err := sd.Archive(context.Background(), dir, time.Time{}, time.Time{}, "CHM82GF99", "C02", "C03")
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		assert.Equal(t, []string{"C02", "C03"}, res.Pending())
+		assert.FileExists(t, filepath.Join(dir, "CHM82GF99.json"), "complete conversation is saved")
+	})
+	t.Run("no links", func(t *testing.T) {
+		sd := &Session{options: DefOptions}
+		_, err := sd.Archive(context.Background(), t.TempDir(), time.Time{}, time.Time{})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewEntityList(t *testing.T) {
+	el, err := NewEntityList("C01", "^C02")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C01"}, el.Include)
+	assert.Equal(t, []string{"C02"}, el.Exclude)
+}
+
+// budgetReporter spends the deadline budget of the session, once the
+// first conversation is finished.
+type budgetReporter struct {
+	progress.Nop
+	sd *Session
+}
+
+func (r *budgetReporter) ChannelFinished(context.Context, string, error) {
+	r.sd.deadline = time.Now().Add(-time.Second)
+}
@@ -0,0 +1,97 @@
+package slackdump
+
+// In this file: the Enterprise Grid audit logs.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"runtime/trace"
+	"strconv"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// auditPerReq is the page size of the audit logs requests, the API maximum
+// is 9999.
+const auditPerReq = 1000
+
+// AuditFilter is the filter of the audit log entries.  Zero values are not
+// applied.
+type AuditFilter struct {
+	Oldest time.Time
+	Latest time.Time
+	Action string // action name, or comma-separated names, i.e. "user_login"
+	Actor  string // user ID of the actor
+	Entity string // ID of the entity: user, channel, file, app, etc.
+}
+
+// values returns the query values of the filter.
+func (f AuditFilter) values() url.Values {
+	v := url.Values{}
+	if !f.Oldest.IsZero() {
+		v.Set("oldest", strconv.FormatInt(f.Oldest.Unix(), 10))
+	}
+	if !f.Latest.IsZero() {
+		v.Set("latest", strconv.FormatInt(f.Latest.Unix(), 10))
+	}
+	for k, s := range map[string]string{"action": f.Action, "actor": f.Actor, "entity": f.Entity} {
+		if s != "" {
+			v.Set(k, s)
+		}
+	}
+	return v
+}
+
+// auditLogsResponse is the response of the audit logs method.
+type auditLogsResponse struct {
+	Entries          []types.AuditEntry     `json:"entries"`
+	ResponseMetadata slack.ResponseMetadata `json:"response_metadata"`
+}
+
+// StreamAuditLogs retrieves the audit log entries of the Enterprise Grid
+// organisation, that match the filter f, and calls fn for each page of the
+// entries, newest first.  The audit logs are only available to the
+// organisation owners, with the user token of the org-level app, that has the
+// auditlogs:read scope, they are not supported by the sessions, created with
+// NewWithClient.
+func (sd *Session) StreamAuditLogs(ctx context.Context, f AuditFilter, fn func([]types.AuditEntry) error) error {
+	ctx, task := trace.NewTask(ctx, "StreamAuditLogs")
+	defer task.End()
+
+	var (
+		query = f.values()
+		l     = sd.limiter(network.Tier3)
+	)
+	query.Set("limit", strconv.Itoa(auditPerReq))
+	for {
+		if err := sd.proceed(ctx); err != nil {
+			return err
+		}
+		var resp auditLogsResponse
+		if err := network.WithRetry(ctx, l, sd.options.Tier3Retries, func() error {
+			return sd.api.audit(ctx, "logs", query, &resp)
+		}); err != nil {
+			var ser slack.SlackErrorResponse
+			if errors.As(err, &ser) && ser.Err == "feature_not_enabled" {
+				return fmt.Errorf("audit logs are only available for Enterprise Grid: %w", err)
+			}
+			return network.Classify("", err)
+		}
+		if len(resp.Entries) > 0 {
+			if err := fn(resp.Entries); err != nil {
+				return err
+			}
+		}
+		if resp.ResponseMetadata.Cursor == "" {
+			break
+		}
+		query.Set("cursor", resp.ResponseMetadata.Cursor)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package slackdump
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestSession_StreamAuditLogs(t *testing.T) {
+	newSession := func(t *testing.T, h http.HandlerFunc) *Session {
+		srv := httptest.NewServer(h)
+		t.Cleanup(srv.Close)
+		return &Session{api: &apiClient{cl: srv.Client(), token: "xoxp-test", auditURL: srv.URL + "/audit/v1/"}, options: DefOptions}
+	}
+	t.Run("pagination and filter", func(t *testing.T) {
+		pages := []string{
+			`{"entries":[{"id":"e2","date_create":1672617600,"action":"user_login"}],"response_metadata":{"next_cursor":"next"}}`,
+			`{"entries":[{"id":"e1","date_create":1672531200,"action":"user_login","details":{"x":1}}],"response_metadata":{"next_cursor":""}}`,
+		}
+		var n int
+		sd := newSession(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "/audit/v1/logs", r.URL.Path)
+			assert.Equal(t, "Bearer xoxp-test", r.Header.Get("Authorization"))
+			q := r.URL.Query()
+			assert.Equal(t, "1672531200", q.Get("oldest"))
+			assert.Equal(t, "user_login", q.Get("action"))
+			assert.Empty(t, q.Get("latest"))
+			if n == 1 {
+				assert.Equal(t, "next", q.Get("cursor"))
+			}
+			w.Write([]byte(pages[n]))
+			n++
+		})
+		var got []types.AuditEntry
+		err := sd.StreamAuditLogs(context.Background(), AuditFilter{
+			Oldest: time.Unix(1672531200, 0),
+			Action: "user_login",
+		}, func(entries []types.AuditEntry) error {
+			got = append(got, entries...)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, "e2", got[0].ID)
+		assert.JSONEq(t, `{"id":"e1","date_create":1672531200,"action":"user_login","details":{"x":1}}`, string(got[1].Raw))
+	})
+	t.Run("not enterprise", func(t *testing.T) {
+		sd := newSession(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"ok":false,"error":"feature_not_enabled"}`))
+		})
+		err := sd.StreamAuditLogs(context.Background(), AuditFilter{}, func([]types.AuditEntry) error { return nil })
+		assert.ErrorContains(t, err, "only available for Enterprise Grid")
+	})
+	t.Run("no api client", func(t *testing.T) {
+		sd := &Session{options: DefOptions}
+		err := sd.StreamAuditLogs(context.Background(), AuditFilter{}, func([]types.AuditEntry) error { return nil })
+		assert.ErrorIs(t, err, errNoAPIClient)
+	})
+}
@@ -25,6 +25,10 @@ type browserOpts struct {
 	browser      browser.Browser
 	flow         BrowserAuthUI
 	loginTimeout time.Duration
+	proxy        string
+	noProxy      string
+	domain       string
+	insecure     bool
 }
 
 type BrowserAuthUI interface {
@@ -52,13 +56,13 @@ func NewBrowserAuth(ctx context.Context, opts ...Option) (BrowserAuth, error) {
 		}
 		defer br.opts.flow.Stop()
 	}
-	if wsp, err := sanitize(br.opts.workspace); err != nil {
+	if wsp, err := sanitize(br.opts.workspace, br.opts.domain); err != nil {
 		return br, err
 	} else {
 		br.opts.workspace = wsp
 	}
 
-	auther, err := browser.New(br.opts.workspace, browser.OptBrowser(br.opts.browser), browser.OptTimeout(br.opts.loginTimeout))
+	auther, err := browser.New(br.opts.workspace, browser.OptBrowser(br.opts.browser), browser.OptTimeout(br.opts.loginTimeout), browser.OptProxy(br.opts.proxy, br.opts.noProxy), browser.OptDomain(br.opts.domain), browser.OptInsecure(br.opts.insecure))
 	if err != nil {
 		return br, err
 	}
@@ -78,8 +82,13 @@ func (BrowserAuth) Type() Type {
 	return TypeBrowser
 }
 
-func sanitize(workspace string) (string, error) {
-	if !strings.Contains(workspace, ".slack.com") {
+// sanitize returns the workspace name from the workspace name or URL on the
+// domain, if the domain is empty, it is "slack.com".
+func sanitize(workspace string, domain string) (string, error) {
+	if domain == "" {
+		domain = "slack.com"
+	}
+	if !strings.Contains(workspace, "."+strings.TrimPrefix(domain, ".")) {
 		return workspace, nil
 	}
 	if strings.HasPrefix(workspace, "https://") {
@@ -39,6 +39,40 @@ func OptTimeout(d time.Duration) Option {
 	}
 }
 
+// OptProxy sets the proxy of the browser, the proxy URL is in the
+// "scheme://[user:password@]host:port" form, where scheme is http, https or
+// socks5.  bypass is the comma-separated list of domains, that are connected
+// to directly.
+func OptProxy(proxyURL, bypass string) Option {
+	return func(c *Client) {
+		if proxyURL == "" {
+			return
+		}
+		c.proxy = newProxy(proxyURL, bypass)
+	}
+}
+
+// OptDomain sets the domain of the workspaces, i.e. "slack-gov.com" for
+// GovSlack, if empty, "slack.com" is used.
+func OptDomain(domain string) Option {
+	return func(c *Client) {
+		if domain == "" {
+			return
+		}
+		c.domain = "." + strings.TrimPrefix(domain, ".")
+	}
+}
+
+// OptInsecure makes the browser ignore the TLS certificate errors, i.e. of
+// the corporate proxy, that terminates TLS.  The browsers don't use the
+// custom root certificates, they must be installed into the browser or the
+// system certificate store instead.
+func OptInsecure(b bool) Option {
+	return func(c *Client) {
+		c.insecure = b
+	}
+}
+
 func (e *Browser) Set(v string) error {
 	v = strings.ToLower(v)
 	for i := 0; i < len(_Browser_index)-1; i++ {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -24,6 +25,9 @@ type Client struct {
 	pageClosed   chan bool // will receive a notification that the page is closed prematurely.
 	br           Browser
 	loginTimeout float64 // slack login page timeout in milliseconds.
+	proxy        *playwright.Proxy
+	domain       string // domain of the workspaces, i.e. ".slack.com"
+	insecure     bool   // ignore the TLS certificate errors
 }
 
 var Logger logger.Interface = logger.Default
@@ -40,6 +44,7 @@ func New(workspace string, opts ...Option) (*Client, error) {
 		pageClosed:   make(chan bool, 1),
 		br:           Bfirefox,
 		loginTimeout: float64(DefLoginTimeout.Milliseconds()),
+		domain:       slackDomain,
 	}
 	for _, opt := range opts {
 		opt(cl)
@@ -75,6 +80,7 @@ func (cl *Client) Authenticate(ctx context.Context) (string, []*http.Cookie, err
 
 	opts := playwright.BrowserTypeLaunchOptions{
 		Headless: _b(false),
+		Proxy:    cl.proxy,
 	}
 
 	browser, err := cl.br.client(pw).Launch(opts)
@@ -83,7 +89,9 @@ func (cl *Client) Authenticate(ctx context.Context) (string, []*http.Cookie, err
 	}
 	defer browser.Close()
 
-	context, err := browser.NewContext()
+	context, err := browser.NewContext(playwright.BrowserNewContextOptions{
+		IgnoreHttpsErrors: _b(cl.insecure),
+	})
 	if err != nil {
 		return "", nil, err
 	}
@@ -92,7 +100,7 @@ func (cl *Client) Authenticate(ctx context.Context) (string, []*http.Cookie, err
 	// disable the "cookies" nag screen.
 	if err := context.AddCookies([]playwright.OptionalCookie{
 		{
-			Domain:  _s(slackDomain),
+			Domain:  _s(cl.domain),
 			Path:    _s("/"),
 			Name:    "OptanonAlertBoxClosed",
 			Value:   time.Now().Add(-10 * time.Minute).Format(time.RFC3339),
@@ -109,7 +117,7 @@ func (cl *Client) Authenticate(ctx context.Context) (string, []*http.Cookie, err
 	// page close sentinel.
 	page.On("close", func() { trace.Log(ctx, "user", "page closed"); close(cl.pageClosed) })
 
-	uri := fmt.Sprintf("https://%s"+slackDomain, cl.workspace)
+	uri := fmt.Sprintf("https://%s%s", cl.workspace, cl.domain)
 	l().Debugf("opening browser URL=%s", uri)
 
 	if _, err := page.Goto(uri); err != nil {
@@ -142,6 +150,26 @@ func (cl *Client) Authenticate(ctx context.Context) (string, []*http.Cookie, err
 	return token, convertCookies(state.Cookies), nil
 }
 
+// newProxy returns the playwright proxy for the proxy URL, the credentials
+// are passed separately, as playwright does not accept them in the URL.
+func newProxy(proxyURL, bypass string) *playwright.Proxy {
+	p := &playwright.Proxy{Server: proxyURL}
+	if bypass != "" {
+		p.Bypass = playwright.String(bypass)
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.User == nil {
+		return p
+	}
+	p.Username = playwright.String(u.User.Username())
+	if pass, ok := u.User.Password(); ok {
+		p.Password = playwright.String(pass)
+	}
+	u.User = nil
+	p.Server = u.String()
+	return p
+}
+
 var errPageClosed = errors.New("browser closed or timed out")
 
 // withBrowserGuard starts the function fn in a goroutine, and waits for it to
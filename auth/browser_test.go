@@ -5,6 +5,7 @@ import "testing"
 func Test_sanitize(t *testing.T) {
 	type args struct {
 		workspace string
+		domain    string
 	}
 	tests := []struct {
 		name    string
@@ -12,15 +13,17 @@ func Test_sanitize(t *testing.T) {
 		want    string
 		wantErr bool
 	}{
-		{"not a URL", args{"blahblah"}, "blahblah", false},
-		{"url slash", args{"https://blahblah.slack.com/"}, "blahblah", false},
-		{"url no slash", args{"https://blahblah.slack.com"}, "blahblah", false},
-		{"url no schema slash", args{"blahblah.slack.com/"}, "blahblah", false},
-		{"url no schema no slash", args{"blahblah.slack.com"}, "blahblah", false},
+		{"not a URL", args{"blahblah", ""}, "blahblah", false},
+		{"url slash", args{"https://blahblah.slack.com/", ""}, "blahblah", false},
+		{"url no slash", args{"https://blahblah.slack.com", ""}, "blahblah", false},
+		{"url no schema slash", args{"blahblah.slack.com/", ""}, "blahblah", false},
+		{"url no schema no slash", args{"blahblah.slack.com", ""}, "blahblah", false},
+		{"govslack url", args{"https://blahblah.slack-gov.com/", "slack-gov.com"}, "blahblah", false},
+		{"other domain", args{"https://blahblah.slack.com/", "slack-gov.com"}, "https://blahblah.slack.com/", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := sanitize(tt.args.workspace)
+			got, err := sanitize(tt.args.workspace, tt.args.domain)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("sanitize() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -33,6 +33,30 @@ func BrowserWithBrowser(b browser.Browser) Option {
 	}
 }
 
+// BrowserWithProxy sets the proxy of the browser, see browser.OptProxy.
+func BrowserWithProxy(proxyURL, noProxy string) Option {
+	return func(o *options) {
+		o.browserOpts.proxy = proxyURL
+		o.browserOpts.noProxy = noProxy
+	}
+}
+
+// BrowserWithDomain sets the domain of the workspace, i.e. "slack-gov.com"
+// for GovSlack, see browser.OptDomain.
+func BrowserWithDomain(domain string) Option {
+	return func(o *options) {
+		o.browserOpts.domain = domain
+	}
+}
+
+// BrowserWithInsecure makes the browser ignore the TLS certificate errors,
+// see browser.OptInsecure.
+func BrowserWithInsecure(b bool) Option {
+	return func(o *options) {
+		o.browserOpts.insecure = b
+	}
+}
+
 func BrowserWithTimeout(d time.Duration) Option {
 	return func(o *options) {
 		if d < 0 {
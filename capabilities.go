@@ -0,0 +1,141 @@
+package slackdump
+
+// In this file: the probe of what the token can do.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"runtime/trace"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+// Access is the result of the capability probe.
+type Access int
+
+const (
+	AccessUnknown Access = iota // the probe failed with an unrelated error.
+	AccessAllowed
+	AccessDenied
+)
+
+func (a Access) String() string {
+	switch a {
+	case AccessAllowed:
+		return "yes"
+	case AccessDenied:
+		return "no"
+	default:
+		return "unknown"
+	}
+}
+
+// Capability is the result of the probe of one capability of the token.
+type Capability struct {
+	Name     string
+	Features string // slackdump features, that require the capability.
+	Access   Access
+	Reason   string // the error of the probe, if the access is not allowed.
+}
+
+// probeMissing are the API errors, that mean that the probed object does not
+// exist, the call itself is allowed.
+var probeMissing = map[string]bool{
+	"file_not_found":    true,
+	"channel_not_found": true,
+	"invalid_arguments": true,
+}
+
+// probeID is the ID of the object, that doesn't exist, it is requested by the
+// probes, that need the ID.
+const probeID = "000000000"
+
+// Capabilities probes what the token can do, with one cheap API call per
+// capability, and returns the results along with the slackdump features, that
+// depend on them.  It works for all token types, as opposed to Scopes, that
+// are only known for the app tokens.
+func (sd *Session) Capabilities(ctx context.Context) ([]Capability, error) {
+	ctx, task := trace.NewTask(ctx, "Capabilities")
+	defer task.End()
+
+	conversations := func(typ string) func() error {
+		return func() error {
+			_, _, err := sd.client.GetConversationsContext(ctx, &slack.GetConversationsParameters{Types: []string{typ}, Limit: 1, ExcludeArchived: true})
+			return err
+		}
+	}
+	probes := []struct {
+		name     string
+		features string
+		fn       func() error
+	}{
+		{"public channels", "export, dump and list of the public channels", conversations("public_channel")},
+		{"private channels", "export and dump of the private channels", conversations("private_channel")},
+		{"direct messages", "export and dump of the direct messages, dump @user", conversations("im")},
+		{"group messages", "export and dump of the group messages", conversations("mpim")},
+		{"users", "users.json, list of the users, the user names", func() error {
+			_, err := sd.client.GetUserInfoContext(ctx, sd.wspInfo.UserID)
+			return err
+		}},
+		{"user groups", "usergroups.json, the @-group mentions", func() error {
+			_, err := sd.client.GetUserGroupsContext(ctx)
+			return err
+		}},
+		{"files", "-download, -embed-files, -file-comments", func() error {
+			_, _, _, err := sd.client.GetFileInfoContext(ctx, "F"+probeID, 1, 1)
+			return err
+		}},
+		{"emoji", "-emoji", func() error {
+			_, err := sd.client.GetEmojiContext(ctx)
+			return err
+		}},
+		{"search", "search command, -search", func() error {
+			_, err := sd.client.SearchMessagesContext(ctx, "slackdump", slack.SearchParameters{Count: 1, Page: 1})
+			return err
+		}},
+		{"admin", "-admin", func() error {
+			var resp json.RawMessage
+			return sd.api.call(ctx, "admin.conversations.getConversationPrefs", url.Values{"channel_id": {"C" + probeID}}, &resp)
+		}},
+		{"audit logs", "-audit, -export-membership", func() error {
+			var resp json.RawMessage
+			return sd.api.audit(ctx, "logs", url.Values{"limit": {"1"}}, &resp)
+		}},
+	}
+	caps := make([]Capability, 0, len(probes))
+	for _, p := range probes {
+		if err := sd.proceed(ctx); err != nil {
+			return caps, err
+		}
+		err := network.WithRetry(ctx, sd.limiter(network.NoTier), sd.options.Tier3Retries, p.fn)
+		if IsInterrupted(ctx, err) {
+			return caps, err
+		}
+		access, reason := probeAccess(err)
+		caps = append(caps, Capability{Name: p.name, Features: p.features, Access: access, Reason: reason})
+	}
+	return caps, nil
+}
+
+// probeAccess returns the access and the reason for the error err of the
+// probe.
+func probeAccess(err error) (Access, string) {
+	if err == nil {
+		return AccessAllowed, ""
+	}
+	var ser slack.SlackErrorResponse
+	switch {
+	case errors.As(err, &ser) && probeMissing[ser.Err]:
+		return AccessAllowed, ""
+	case errors.As(err, &ser) && adminDenied[ser.Err]:
+		return AccessDenied, ser.Err
+	case errors.As(err, &ser):
+		return AccessUnknown, ser.Err
+	default:
+		return AccessUnknown, err.Error()
+	}
+}
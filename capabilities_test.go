@@ -0,0 +1,96 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_Capabilities(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mc := newmockClienter(ctrl)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/admin.conversations.getConversationPrefs":
+			fmt.Fprint(w, `{"ok":false,"error":"not_an_admin"}`)
+		case "/audit/v1/logs":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"ok":false,"error":"feature_not_enabled"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	sd := &Session{
+		client:  mc,
+		api:     &apiClient{cl: srv.Client(), token: "xoxp-test", baseURL: srv.URL, auditURL: srv.URL + "/audit/v1/"},
+		wspInfo: &slack.AuthTestResponse{UserID: "U01"},
+		options: DefOptions,
+	}
+
+	missingScope := slack.SlackErrorResponse{Err: "missing_scope"}
+	mc.EXPECT().GetConversationsContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, p *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+			if p.Types[0] == "im" || p.Types[0] == "mpim" {
+				return nil, "", missingScope
+			}
+			return []slack.Channel{}, "", nil
+		}).Times(4)
+	mc.EXPECT().GetUserInfoContext(gomock.Any(), "U01").Return(&slack.User{ID: "U01"}, nil)
+	mc.EXPECT().GetUserGroupsContext(gomock.Any()).Return(nil, errors.New("connection reset"))
+	mc.EXPECT().GetFileInfoContext(gomock.Any(), "F"+probeID, 1, 1).Return(nil, nil, nil, slack.SlackErrorResponse{Err: "file_not_found"})
+	mc.EXPECT().GetEmojiContext(gomock.Any()).Return(map[string]string{}, nil)
+	mc.EXPECT().SearchMessagesContext(gomock.Any(), "slackdump", gomock.Any()).Return(nil, slack.SlackErrorResponse{Err: "not_allowed_token_type"})
+
+	caps, err := sd.Capabilities(context.Background())
+	require.NoError(t, err)
+	got := make(map[string]Access, len(caps))
+	for _, c := range caps {
+		got[c.Name] = c.Access
+		assert.NotEmpty(t, c.Features, c.Name)
+	}
+	assert.Equal(t, map[string]Access{
+		"public channels":  AccessAllowed,
+		"private channels": AccessAllowed,
+		"direct messages":  AccessDenied,
+		"group messages":   AccessDenied,
+		"users":            AccessAllowed,
+		"user groups":      AccessUnknown,
+		"files":            AccessAllowed,
+		"emoji":            AccessAllowed,
+		"search":           AccessDenied,
+		"admin":            AccessDenied,
+		"audit logs":       AccessDenied,
+	}, got)
+	assert.Equal(t, "missing_scope", caps[2].Reason)
+}
+
+func Test_probeAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantAccess Access
+		wantReason string
+	}{
+		{"ok", nil, AccessAllowed, ""},
+		{"not found", fmt.Errorf("callback error: %w", slack.SlackErrorResponse{Err: "channel_not_found"}), AccessAllowed, ""},
+		{"denied", fmt.Errorf("callback error: %w", slack.SlackErrorResponse{Err: "missing_scope"}), AccessDenied, "missing_scope"},
+		{"other API error", slack.SlackErrorResponse{Err: "ratelimited"}, AccessUnknown, "ratelimited"},
+		{"no API client", errNoAPIClient, AccessUnknown, errNoAPIClient.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access, reason := probeAccess(tt.err)
+			assert.Equal(t, tt.wantAccess, access)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
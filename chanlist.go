@@ -0,0 +1,147 @@
+package slackdump
+
+// In this file: the resumable channel listing.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/encio"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// chanListDirPrefix is the prefix of the directory of the channel listing in
+// the cache directory.
+const chanListDirPrefix = "channels-list-"
+
+// chanListStateFile is the name of the state file of the channel listing.
+const chanListStateFile = "state.cache"
+
+// chanListState is the state of the channel listing, that is saved after
+// each page of the channels, so that the interrupted listing is resumed from
+// the cursor of the next page.
+type chanListState struct {
+	Types    []string  `json:"types"`
+	Cursor   string    `json:"cursor,omitempty"` // cursor of the next page.
+	Pages    int       `json:"pages"`            // number of the saved pages.
+	Complete bool      `json:"complete"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished,omitempty"`
+}
+
+// chanList persists the pages of the channel listing in the directory dir.
+type chanList struct {
+	dir   string
+	state chanListState
+}
+
+// chanListDir returns the directory of the listing of the channel types
+// chanTypes in the workspace teamID.
+func (sd *Session) chanListDir(teamID string, chanTypes []string) string {
+	tt := append([]string{}, chanTypes...)
+	sort.Strings(tt)
+	sum := sha256.Sum256([]byte(strings.Join(tt, ",")))
+	return filepath.Join(sd.options.CacheDir, chanListDirPrefix+teamID+"-"+hex.EncodeToString(sum[:4]))
+}
+
+// openChanList opens the channel listing in the directory dir.  If there's
+// no listing, it can't be read, or the complete listing is older than maxAge,
+// the new listing is started.
+func openChanList(dir string, chanTypes []string, maxAge time.Duration) (*chanList, error) {
+	cl := &chanList{dir: dir}
+	if err := readEncJSON(filepath.Join(dir, chanListStateFile), &cl.state); err == nil && !(cl.state.Complete && time.Since(cl.state.Finished) > maxAge) {
+		return cl, nil
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	cl.state = chanListState{Types: chanTypes, Started: time.Now().UTC()}
+	return cl, nil
+}
+
+// pageFile returns the name of the file of the page n (1-based).
+func (cl *chanList) pageFile(n int) string {
+	return filepath.Join(cl.dir, fmt.Sprintf("%06d.cache", n))
+}
+
+// replay calls cb for each saved page, in order.
+func (cl *chanList) replay(cb func(types.Channels) error) (int, error) {
+	var total int
+	for n := 1; n <= cl.state.Pages; n++ {
+		var chans types.Channels
+		if err := readEncJSON(cl.pageFile(n), &chans); err != nil {
+			return total, err
+		}
+		if err := cb(chans); err != nil {
+			return total, err
+		}
+		total += len(chans)
+	}
+	return total, nil
+}
+
+// add saves the page of the channels chans, and the cursor of the next page,
+// the empty cursor completes the listing.  The page is saved before the
+// state, so that the state never refers to the page, that was not saved.
+func (cl *chanList) add(chans []slack.Channel, next string) error {
+	if err := writeEncJSON(cl.pageFile(cl.state.Pages+1), chans); err != nil {
+		return err
+	}
+	st := cl.state
+	st.Pages++
+	st.Cursor = next
+	if next == "" {
+		st.Complete = true
+		st.Finished = time.Now().UTC()
+	}
+	if err := writeEncJSON(filepath.Join(cl.dir, chanListStateFile), st); err != nil {
+		return err
+	}
+	cl.state = st
+	return nil
+}
+
+// readEncJSON decodes the encrypted JSON file filename into v.
+func readEncJSON(filename string, v any) error {
+	f, err := encio.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeEncJSON encodes v into the encrypted file filename, the file is
+// replaced atomically.
+func writeEncJSON(filename string, v any) error {
+	tmp := filename + ".tmp"
+	f, err := encio.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(v); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
@@ -0,0 +1,79 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_GetChannels_resume(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mc := newmockClienter(ctrl)
+	sd := &Session{client: mc, wspInfo: &slack.AuthTestResponse{TeamID: "T01"}, options: DefOptions}
+	sd.options.CacheDir = t.TempDir()
+	sd.options.Tier2Boost = 60000 // no throttling
+	WithChanListCache(true)(&sd.options)
+
+	pages := map[string]struct {
+		chans []slack.Channel
+		next  string
+	}{
+		"":   {[]slack.Channel{{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01"}}}}, "c2"},
+		"c2": {[]slack.Channel{{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C02"}}}}, ""},
+	}
+	list := func(failOn string) func(context.Context, *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+		return func(_ context.Context, p *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+			if p.Cursor == failOn {
+				return nil, "", errors.New("connection reset by peer")
+			}
+			pg := pages[p.Cursor]
+			return pg.chans, pg.next, nil
+		}
+	}
+	ids := func(chans []slack.Channel) []string {
+		var ids []string
+		for _, ch := range chans {
+			ids = append(ids, ch.ID)
+		}
+		return ids
+	}
+
+	// the listing dies on the second page.
+	mc.EXPECT().GetConversationsContext(gomock.Any(), gomock.Any()).DoAndReturn(list("c2")).Times(2)
+	_, err := sd.GetChannels(ctx)
+	require.Error(t, err)
+
+	// it is resumed from the cursor of the second page.
+	mc.EXPECT().GetConversationsContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, p *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+			assert.Equal(t, "c2", p.Cursor)
+			return list("-")(ctx, p)
+		})
+	chans, err := sd.GetChannels(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C01", "C02"}, ids(chans))
+
+	// the complete listing is reused without the API calls.
+	chans, err = sd.GetChannels(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C01", "C02"}, ids(chans))
+
+	// the other channel types are listed separately.
+	mc.EXPECT().GetConversationsContext(gomock.Any(), gomock.Any()).DoAndReturn(list("-")).Times(2)
+	_, err = sd.GetChannels(ctx, "public_channel")
+	require.NoError(t, err)
+
+	// the stale listing is fetched anew.
+	sd.options.MaxChanCacheAge = time.Nanosecond
+	mc.EXPECT().GetConversationsContext(gomock.Any(), gomock.Any()).DoAndReturn(list("-")).Times(2)
+	chans, err = sd.GetChannels(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C01", "C02"}, ids(chans))
+}
@@ -4,7 +4,10 @@ package slackdump
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"runtime/trace"
+	"strings"
 	"time"
 
 	"github.com/slack-go/slack"
@@ -56,8 +59,30 @@ func (sd *Session) getChannels(ctx context.Context, chanTypes []string, cb func(
 
 	params := &slack.GetConversationsParameters{Types: chanTypes, Limit: sd.options.ChannelsPerReq}
 	fetchStart := time.Now()
-	var total int
+	var (
+		total int
+		cl    *chanList // saved pages of the listing, if enabled.
+	)
+	if sd.options.ChanListCache && sd.wspInfo != nil {
+		var err error
+		if cl, err = openChanList(sd.chanListDir(sd.wspInfo.TeamID, chanTypes), chanTypes, sd.options.MaxChanCacheAge); err != nil {
+			sd.l().Printf("warning: the channel listing is not cached: %s", err)
+		} else if cl.state.Pages > 0 {
+			if total, err = cl.replay(cb); err != nil {
+				return err
+			}
+			if cl.state.Complete {
+				sd.l().Printf("channels loaded from the cache, total: %d channels, listed at %s", total, cl.state.Finished.Local().Format(time.RFC3339))
+				return nil
+			}
+			sd.l().Printf("resuming the channel listing after %d channels", total)
+			params.Cursor = cl.state.Cursor
+		}
+	}
 	for i := 1; ; i++ {
+		if err := sd.proceed(ctx); err != nil {
+			return err
+		}
 		var (
 			chans   []slack.Channel
 			nextcur string
@@ -71,9 +96,15 @@ func (sd *Session) getChannels(ctx context.Context, chanTypes []string, cb func(
 			return err
 
 		}); err != nil {
-			return err
+			return network.Classify("", err)
 		}
 
+		if cl != nil {
+			if err := cl.add(chans, nextcur); err != nil {
+				sd.l().Printf("warning: failed to cache the channel listing: %s", err)
+				cl = nil
+			}
+		}
 		if err := cb(chans); err != nil {
 			return err
 		}
@@ -104,6 +135,9 @@ func (sd *Session) GetChannelMembers(ctx context.Context, channelID string) ([]s
 	var ids []string
 	var cursor string
 	for {
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
 		var uu []string
 		var next string
 		if err := network.WithRetry(ctx, sd.limiter(network.Tier4), sd.options.Tier4Retries, func() error {
@@ -114,7 +148,7 @@ func (sd *Session) GetChannelMembers(ctx context.Context, channelID string) ([]s
 			})
 			return err
 		}); err != nil {
-			return nil, err
+			return nil, network.Classify(channelID, err)
 		}
 		ids = append(ids, uu...)
 
@@ -125,3 +159,56 @@ func (sd *Session) GetChannelMembers(ctx context.Context, channelID string) ([]s
 	}
 	return ids, nil
 }
+
+// FindUser returns the user, that has the ID, the username, the display
+// name, the real name or the email equal to s, from the user cache.  It
+// returns an error, if there's no such user, or several users match.
+func (sd *Session) FindUser(s string) (slack.User, error) {
+	if len(sd.Users) == 0 {
+		return slack.User{}, ErrNoUserCache
+	}
+	found := sd.Users.Match(s)
+	switch len(found) {
+	case 0:
+		return slack.User{}, fmt.Errorf("user %q not found", s)
+	case 1:
+		return found[0], nil
+	}
+	ids := make([]string, len(found))
+	for i, u := range found {
+		ids[i] = u.ID
+	}
+	return slack.User{}, fmt.Errorf("%q matches several users, use the user ID: %s", s, strings.Join(ids, " "))
+}
+
+// errStop stops the channel streaming, once the channel is found.
+var errStop = errors.New("stop")
+
+// DMChannel returns the ID of the direct message conversation with the user,
+// see FindUser for the supported values of user.  The conversation is looked
+// up in the direct messages of the current user, it is not opened, if it
+// does not exist.
+func (sd *Session) DMChannel(ctx context.Context, user string) (string, error) {
+	ctx, task := trace.NewTask(ctx, "DMChannel")
+	defer task.End()
+
+	u, err := sd.FindUser(user)
+	if err != nil {
+		return "", err
+	}
+	var id string
+	if err := sd.StreamChannels(ctx, []string{"im"}, func(ch slack.Channel) error {
+		if ch.User == u.ID {
+			id = ch.ID
+			return errStop
+		}
+		return nil
+	}); err != nil && !errors.Is(err, errStop) {
+		return "", err
+	}
+	if id == "" {
+		return "", fmt.Errorf("no direct messages with %s (%s)", u.Name, u.ID)
+	}
+	sd.l().Printf("direct messages with %s (%s): %s", u.Name, u.ID, id)
+	return id, nil
+}
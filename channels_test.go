@@ -105,7 +105,7 @@ func TestSession_getChannels(t *testing.T) {
 
 func TestSession_GetChannels(t *testing.T) {
 	type fields struct {
-		client    clienter
+		client    Slacker
 		Users     types.Users
 		UserIndex structures.UserIndex
 		options   Options
@@ -236,3 +236,60 @@ func TestSession_GetChannelMembers(t *testing.T) {
 		})
 	}
 }
+
+func TestSession_DMChannel(t *testing.T) {
+	users := types.Users{
+		{ID: "U01", Name: "alice", Profile: slack.UserProfile{Email: "alice@example.com"}},
+		{ID: "U02", Name: "bob"},
+	}
+	im := func(id, user string) slack.Channel {
+		return slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: id, IsIM: true, User: user}}}
+	}
+	tests := []struct {
+		name    string
+		user    string
+		expect  func(mc *mockClienter)
+		want    string
+		wantErr bool
+	}{
+		{
+			"found on the second page",
+			"alice@example.com",
+			func(mc *mockClienter) {
+				first := mc.EXPECT().GetConversationsContext(gomock.Any(), gomock.Any()).Return([]slack.Channel{im("D02", "U02")}, "cursor", nil)
+				mc.EXPECT().GetConversationsContext(gomock.Any(), gomock.Any()).Return([]slack.Channel{im("D01", "U01")}, "", nil).After(first)
+			},
+			"D01",
+			false,
+		},
+		{
+			"no direct messages",
+			"bob",
+			func(mc *mockClienter) {
+				mc.EXPECT().GetConversationsContext(gomock.Any(), gomock.Any()).Return([]slack.Channel{im("D01", "U01")}, "", nil)
+			},
+			"",
+			true,
+		},
+		{
+			"unknown user",
+			"carol",
+			func(mc *mockClienter) {},
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := newmockClienter(gomock.NewController(t))
+			tt.expect(mc)
+			sd := &Session{client: mc, Users: users, options: DefOptions}
+			got, err := sd.DMChannel(context.Background(), tt.user)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Session.DMChannel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
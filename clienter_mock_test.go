@@ -13,7 +13,7 @@ import (
 	slack "github.com/slack-go/slack"
 )
 
-// mockClienter is a mock of clienter interface.
+// mockClienter is a mock of Slacker interface.
 type mockClienter struct {
 	ctrl     *gomock.Controller
 	recorder *mockClienterMockRecorder
@@ -36,6 +36,21 @@ func (m *mockClienter) EXPECT() *mockClienterMockRecorder {
 	return m.recorder
 }
 
+// AuthTestContext mocks base method.
+func (m *mockClienter) AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthTestContext", ctx)
+	ret0, _ := ret[0].(*slack.AuthTestResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthTestContext indicates an expected call of AuthTestContext.
+func (mr *mockClienterMockRecorder) AuthTestContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthTestContext", reflect.TypeOf((*mockClienter)(nil).AuthTestContext), ctx)
+}
+
 // GetConversationHistoryContext mocks base method.
 func (m *mockClienter) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
 	m.ctrl.T.Helper()
@@ -128,6 +143,54 @@ func (mr *mockClienterMockRecorder) GetFile(downloadURL, writer interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFile", reflect.TypeOf((*mockClienter)(nil).GetFile), downloadURL, writer)
 }
 
+// GetFileInfoContext mocks base method.
+func (m *mockClienter) GetFileInfoContext(ctx context.Context, fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileInfoContext", ctx, fileID, count, page)
+	ret0, _ := ret[0].(*slack.File)
+	ret1, _ := ret[1].([]slack.Comment)
+	ret2, _ := ret[2].(*slack.Paging)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// GetFileInfoContext indicates an expected call of GetFileInfoContext.
+func (mr *mockClienterMockRecorder) GetFileInfoContext(ctx, fileID, count, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileInfoContext", reflect.TypeOf((*mockClienter)(nil).GetFileInfoContext), ctx, fileID, count, page)
+}
+
+// GetReactionsContext mocks base method.
+func (m *mockClienter) GetReactionsContext(ctx context.Context, item slack.ItemRef, params slack.GetReactionsParameters) ([]slack.ItemReaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReactionsContext", ctx, item, params)
+	ret0, _ := ret[0].([]slack.ItemReaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReactionsContext indicates an expected call of GetReactionsContext.
+func (mr *mockClienterMockRecorder) GetReactionsContext(ctx, item, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReactionsContext", reflect.TypeOf((*mockClienter)(nil).GetReactionsContext), ctx, item, params)
+}
+
+// GetScheduledMessagesContext mocks base method.
+func (m *mockClienter) GetScheduledMessagesContext(ctx context.Context, params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledMessagesContext", ctx, params)
+	ret0, _ := ret[0].([]slack.ScheduledMessage)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetScheduledMessagesContext indicates an expected call of GetScheduledMessagesContext.
+func (mr *mockClienterMockRecorder) GetScheduledMessagesContext(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledMessagesContext", reflect.TypeOf((*mockClienter)(nil).GetScheduledMessagesContext), ctx, params)
+}
+
 // GetTeamInfo mocks base method.
 func (m *mockClienter) GetTeamInfo() (*slack.TeamInfo, error) {
 	m.ctrl.T.Helper()
@@ -143,6 +206,86 @@ func (mr *mockClienterMockRecorder) GetTeamInfo() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamInfo", reflect.TypeOf((*mockClienter)(nil).GetTeamInfo))
 }
 
+// GetTeamInfoContext mocks base method.
+func (m *mockClienter) GetTeamInfoContext(ctx context.Context) (*slack.TeamInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeamInfoContext", ctx)
+	ret0, _ := ret[0].(*slack.TeamInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeamInfoContext indicates an expected call of GetTeamInfoContext.
+func (mr *mockClienterMockRecorder) GetTeamInfoContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamInfoContext", reflect.TypeOf((*mockClienter)(nil).GetTeamInfoContext), ctx)
+}
+
+// GetTeamProfileContext mocks base method.
+func (m *mockClienter) GetTeamProfileContext(ctx context.Context) (*slack.TeamProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeamProfileContext", ctx)
+	ret0, _ := ret[0].(*slack.TeamProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeamProfileContext indicates an expected call of GetTeamProfileContext.
+func (mr *mockClienterMockRecorder) GetTeamProfileContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamProfileContext", reflect.TypeOf((*mockClienter)(nil).GetTeamProfileContext), ctx)
+}
+
+// GetUserGroupsContext mocks base method.
+func (m *mockClienter) GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetUserGroupsContext", varargs...)
+	ret0, _ := ret[0].([]slack.UserGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserGroupsContext indicates an expected call of GetUserGroupsContext.
+func (mr *mockClienterMockRecorder) GetUserGroupsContext(ctx interface{}, options ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserGroupsContext", reflect.TypeOf((*mockClienter)(nil).GetUserGroupsContext), varargs...)
+}
+
+// GetUserInfoContext mocks base method.
+func (m *mockClienter) GetUserInfoContext(ctx context.Context, user string) (*slack.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserInfoContext", ctx, user)
+	ret0, _ := ret[0].(*slack.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserInfoContext indicates an expected call of GetUserInfoContext.
+func (mr *mockClienterMockRecorder) GetUserInfoContext(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserInfoContext", reflect.TypeOf((*mockClienter)(nil).GetUserInfoContext), ctx, user)
+}
+
+// GetUserProfileContext mocks base method.
+func (m *mockClienter) GetUserProfileContext(ctx context.Context, params *slack.GetUserProfileParameters) (*slack.UserProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserProfileContext", ctx, params)
+	ret0, _ := ret[0].(*slack.UserProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserProfileContext indicates an expected call of GetUserProfileContext.
+func (mr *mockClienterMockRecorder) GetUserProfileContext(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfileContext", reflect.TypeOf((*mockClienter)(nil).GetUserProfileContext), ctx, params)
+}
+
 // GetUsersContext mocks base method.
 func (m *mockClienter) GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error) {
 	m.ctrl.T.Helper()
@@ -178,3 +321,49 @@ func (mr *mockClienterMockRecorder) GetUsersInConversationContext(ctx, params in
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersInConversationContext", reflect.TypeOf((*mockClienter)(nil).GetUsersInConversationContext), ctx, params)
 }
+
+// ListStarsContext mocks base method.
+func (m *mockClienter) ListStarsContext(ctx context.Context, params slack.StarsParameters) ([]slack.Item, *slack.Paging, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStarsContext", ctx, params)
+	ret0, _ := ret[0].([]slack.Item)
+	ret1, _ := ret[1].(*slack.Paging)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListStarsContext indicates an expected call of ListStarsContext.
+func (mr *mockClienterMockRecorder) ListStarsContext(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStarsContext", reflect.TypeOf((*mockClienter)(nil).ListStarsContext), ctx, params)
+}
+
+// SearchFilesContext mocks base method.
+func (m *mockClienter) SearchFilesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchFiles, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchFilesContext", ctx, query, params)
+	ret0, _ := ret[0].(*slack.SearchFiles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchFilesContext indicates an expected call of SearchFilesContext.
+func (mr *mockClienterMockRecorder) SearchFilesContext(ctx, query, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFilesContext", reflect.TypeOf((*mockClienter)(nil).SearchFilesContext), ctx, query, params)
+}
+
+// SearchMessagesContext mocks base method.
+func (m *mockClienter) SearchMessagesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchMessagesContext", ctx, query, params)
+	ret0, _ := ret[0].(*slack.SearchMessages)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchMessagesContext indicates an expected call of SearchMessagesContext.
+func (mr *mockClienterMockRecorder) SearchMessagesContext(ctx, query, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchMessagesContext", reflect.TypeOf((*mockClienter)(nil).SearchMessagesContext), ctx, query, params)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/rusq/slackdump/v2/export"
 	"github.com/rusq/slackdump/v2/internal/app/config"
 	"github.com/rusq/slackdump/v2/internal/app/ui"
+	"github.com/rusq/slackdump/v2/internal/output"
 	"github.com/rusq/slackdump/v2/internal/structures"
 )
 
@@ -273,3 +274,30 @@ func surveyEmojis(p *params) error {
 	}
 	return nil
 }
+
+// questOnExists asks the user what to do with the existing output location.
+func questOnExists(location string) (output.Strategy, error) {
+	var options = make([]string, len(output.Strategies))
+	for i, s := range output.Strategies {
+		options[i] = s.Strategy.String()
+	}
+	q := &survey.Select{
+		Message: fmt.Sprintf("%s already exists, what to do with it?", location),
+		Options: options,
+		Description: func(value string, index int) string {
+			return output.Strategies[index].Description
+		},
+	}
+	var resp string
+	if err := survey.AskOne(q, &resp); err != nil {
+		return output.Fail, err
+	}
+	return output.Parse(resp)
+}
+
+// isTerminal returns true, if the standard input is the terminal, so that
+// the user can be asked.
+func isTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
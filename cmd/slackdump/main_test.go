@@ -2,9 +2,13 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/rusq/slackdump/v2"
@@ -74,6 +78,8 @@ func Test_checkParameters(t *testing.T) {
 
 					Input:   config.Input{List: &structures.EntityList{}},
 					Output:  config.Output{Filename: "-", Format: "text"},
+					Follow:  config.FollowParams{Interval: defFollowInterval},
+					Notify:  config.NotifyParams{StallAfter: defStallAfter},
 					Options: slackdump.DefOptions,
 				}},
 			false,
@@ -95,6 +101,8 @@ func Test_checkParameters(t *testing.T) {
 					FilenameTemplate: defFilenameTemplate,
 					Input:            config.Input{List: &structures.EntityList{}},
 					Output:           config.Output{Filename: "-", Format: "text"},
+					Follow:           config.FollowParams{Interval: defFollowInterval},
+					Notify:           config.NotifyParams{StallAfter: defStallAfter},
 					Options:          slackdump.DefOptions,
 				}},
 			false,
@@ -156,3 +164,70 @@ func Test_trunc(t *testing.T) {
 		})
 	}
 }
+
+func Test_lookupCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		wantOK bool
+	}{
+		{"empty", nil, false},
+		{"view", []string{"view", "export.zip"}, true},
+		{"legacy flags", []string{"-export", "view"}, false},
+		{"channel ID", []string{"C01"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, ok := lookupCommand(tt.args)
+			assert.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, tt.args[0], cmd.Name)
+			}
+		})
+	}
+}
+
+func Test_isOneshot(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"empty", nil, false},
+		{"flag", []string{"-export", "out.zip", "-oneshot"}, true},
+		{"double dash", []string{"--oneshot"}, true},
+		{"false", []string{"-oneshot=false"}, false},
+		{"true", []string{"-oneshot=true"}, true},
+		{"channel ID", []string{"oneshot"}, false},
+		{"after terminator", []string{"--", "-oneshot"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isOneshot(tt.args))
+		})
+	}
+}
+
+func Test_exitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, exitOK},
+		{"token expired", fmt.Errorf("application error: %w", slackdump.ErrTokenExpired), exitFatal},
+		{"invalid auth", slack.SlackErrorResponse{Err: "invalid_auth"}, exitFatal},
+		{"partial", &app.PartialError{Failed: []string{"C01"}}, exitPartial},
+		{"budget", fmt.Errorf("run interrupted: %w", slackdump.ErrBudgetExceeded), exitRetry},
+		{"cancelled", fmt.Errorf("run interrupted: %w", context.Canceled), exitRetry},
+		{"rate limited", &slackdump.ErrRateLimited{RetryAfter: time.Minute, Err: errors.New("slow down")}, exitRetry},
+		{"server error", slack.StatusCodeError{Code: 503, Status: "Service Unavailable"}, exitRetry},
+		{"not found", slack.StatusCodeError{Code: 404, Status: "Not Found"}, exitFatal},
+		{"other", errors.New("disk full"), exitFatal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.err))
+		})
+	}
+}
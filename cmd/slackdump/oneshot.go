@@ -0,0 +1,124 @@
+package main
+
+// In this file: the unattended single run for the container schedulers.
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/auth/browser"
+	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/internal/app"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// Exit codes of the -oneshot run.  The scheduler should retry the run only on
+// exitRetry, i.e. with the Kubernetes Pod failure policy, the interrupted run
+// resumes from the -state.
+const (
+	exitOK      = 0  // the run has completed
+	exitFatal   = 1  // configuration or authentication error, retrying won't help
+	exitPartial = 3  // the run has completed, but some conversations have failed
+	exitRetry   = 75 // transient error or interrupted run, EX_TEMPFAIL in sysexits.h
+)
+
+// errNoCredentials is returned in the -oneshot mode, if there are no
+// credentials, as the browser login is not possible.
+var errNoCredentials = errors.New("no credentials: set " + envSlackToken + " (and " + envSlackCookie + " for the browser tokens), or log in once with the same -cache-dir")
+
+// noLogin is the credentials, that never start the browser login, if there
+// are no token and cookie.
+type noLogin struct {
+	app.SlackCreds
+}
+
+func (c noLogin) AuthProvider(ctx context.Context, workspace string, browser browser.Browser) (auth.Provider, error) {
+	if c.IsEmpty() {
+		return nil, errNoCredentials
+	}
+	return c.SlackCreds.AuthProvider(ctx, workspace, browser)
+}
+
+// isOneshot returns true if the -oneshot flag is set in args.  It is checked
+// before the command line is parsed, to decide on the banner.
+func isOneshot(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != "oneshot" {
+			continue
+		}
+		if !hasValue {
+			return true
+		}
+		v, err := strconv.ParseBool(value)
+		return err == nil && v
+	}
+	return false
+}
+
+// runOneshot runs the dumper once without any prompts, and returns the exit
+// code, see exitCode.  The errors are written to the JSON log.
+func runOneshot(ctx context.Context, p params, cfgErr error) int {
+	lg := logger.Default
+	lg.SetFlags(0)
+	lg.SetOutput(logger.JSON(os.Stderr))
+	lg.Printf("slackdump %s (commit: %s) built on: %s", version, commit, date)
+	if cfgErr != nil {
+		lg.Printf("configuration error: %s", cfgErr)
+		return exitFatal
+	}
+	// the skipped conversations are reported with the exit code.
+	p.appCfg.FailPartial = true
+	err := run(ctx, p)
+	code := exitCode(err)
+	if err != nil {
+		lg.Printf("%s (exit code: %d)", err, code)
+	}
+	return code
+}
+
+// exitCode returns the exit code of the -oneshot run, that has finished with
+// the error err.
+func exitCode(err error) int {
+	var (
+		pe  *app.PartialError
+		fe  *export.FailedError
+		rl  *slackdump.ErrRateLimited
+		ne  net.Error
+		sce slack.StatusCodeError
+		rle *slack.RateLimitedError
+	)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, slackdump.ErrTokenExpired) || isInvalidAuth(err):
+		return exitFatal
+	case errors.As(err, &pe) || errors.As(err, &fe):
+		return exitPartial
+	case errors.Is(err, slackdump.ErrBudgetExceeded) || errors.Is(err, slackdump.ErrCallBudgetExceeded):
+		return exitRetry
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		// i.e. the pod is evicted.
+		return exitRetry
+	case errors.As(err, &rl) || errors.As(err, &rle) || errors.As(err, &ne):
+		return exitRetry
+	case errors.As(err, &sce) && (sce.Code >= http.StatusInternalServerError || sce.Code == http.StatusTooManyRequests):
+		return exitRetry
+	}
+	return exitFatal
+}
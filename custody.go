@@ -0,0 +1,90 @@
+package slackdump
+
+// In this file: the chain-of-custody log of the archive.
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+)
+
+// CustodyFile is the name of the chain-of-custody log in the root of the
+// archive.  It is in the JSON Lines format, one CustodyRecord per line, the
+// records are only appended to it.
+const CustodyFile = "custody.jsonl"
+
+// CustodyOp is the operation performed on the archive.
+type CustodyOp string
+
+const (
+	CustodyCreated   CustodyOp = "created"   // the archive was created
+	CustodyUpdated   CustodyOp = "updated"   // new messages were added to the archive
+	CustodyRenamed   CustodyOp = "renamed"   // files of the archive were renamed
+	CustodyPruned    CustodyOp = "pruned"    // expired messages were removed from the archive
+	CustodyCompacted CustodyOp = "compacted" // duplicate messages were removed from the archive
+	CustodyHold      CustodyOp = "hold"      // the legal hold was placed on the archive
+	CustodyReleased  CustodyOp = "released"  // the legal hold was released
+	CustodyFetched   CustodyOp = "fetched"   // the referenced files were downloaded into the archive
+)
+
+var (
+	// CustodyTool is the name and the version of the tool, that is recorded
+	// in the custody log.  The slackdump command sets it to its version.
+	CustodyTool = "slackdump"
+	// CustodyOperator is the operator, that is recorded in the custody log.
+	// If it is empty, the current OS user and the host name are recorded.
+	CustodyOperator = ""
+)
+
+// CustodyRecord is the record of the chain-of-custody log.
+type CustodyRecord struct {
+	Time      time.Time `json:"time"`
+	Operation CustodyOp `json:"operation"`
+	Tool      string    `json:"tool"`
+	Operator  string    `json:"operator,omitempty"`
+	Details   string    `json:"details,omitempty"`
+}
+
+// NewCustodyRecord returns the record of the operation op, performed now by
+// the current operator.
+func NewCustodyRecord(op CustodyOp, details string) CustodyRecord {
+	return CustodyRecord{
+		Time:      time.Now().UTC(),
+		Operation: op,
+		Tool:      CustodyTool,
+		Operator:  custodyOperator(),
+		Details:   details,
+	}
+}
+
+func custodyOperator() string {
+	if CustodyOperator != "" {
+		return CustodyOperator
+	}
+	var name string
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
+
+// AppendCustody appends the record rec to the custody log in the root of the
+// filesystem fs.  The ZIP archives can't be appended to, so the log is
+// created with the single record.
+func AppendCustody(fs fsadapter.FS, rec CustodyRecord) error {
+	w, err := fsadapter.Append(fs, CustodyFile)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(w).Encode(rec); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,55 @@
+package slackdump
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+)
+
+func readCustody(t *testing.T, name string) []CustodyRecord {
+	t.Helper()
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+	var recs []CustodyRecord
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var rec CustodyRecord
+		require.NoError(t, json.Unmarshal(s.Bytes(), &rec))
+		recs = append(recs, rec)
+	}
+	require.NoError(t, s.Err())
+	return recs
+}
+
+func TestAppendCustody(t *testing.T) {
+	defer func(tool, op string) { CustodyTool, CustodyOperator = tool, op }(CustodyTool, CustodyOperator)
+	CustodyTool, CustodyOperator = "slackdump v2.9.9", "jdoe"
+
+	dir := t.TempDir()
+	fsa := fsadapter.NewDirectory(dir)
+	require.NoError(t, AppendCustody(fsa, NewCustodyRecord(CustodyCreated, "workspace T01")))
+	require.NoError(t, AppendCustody(fsa, NewCustodyRecord(CustodyRenamed, "")))
+
+	recs := readCustody(t, filepath.Join(dir, CustodyFile))
+	require.Len(t, recs, 2)
+	assert.Equal(t, CustodyCreated, recs[0].Operation)
+	assert.Equal(t, "workspace T01", recs[0].Details)
+	assert.Equal(t, "slackdump v2.9.9", recs[0].Tool)
+	assert.Equal(t, "jdoe", recs[0].Operator)
+	assert.Equal(t, CustodyRenamed, recs[1].Operation)
+	assert.False(t, recs[1].Time.Before(recs[0].Time))
+}
+
+func Test_custodyOperator(t *testing.T) {
+	defer func(op string) { CustodyOperator = op }(CustodyOperator)
+	CustodyOperator = ""
+	assert.NotEmpty(t, custodyOperator())
+}
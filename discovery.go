@@ -0,0 +1,367 @@
+package slackdump
+
+// In this file: the Discovery API backend.
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+// Backend is the API, that the conversations and the users are fetched
+// with.
+type Backend uint8
+
+const (
+	// BackendAPI is the regular Web API, the default.
+	BackendAPI Backend = iota
+	// BackendDiscovery is the Discovery API of Enterprise Grid, that is
+	// available to the compliance tokens of the Org Owners.  It can access
+	// all the conversations of the organisation, including the DMs of all
+	// users, see discoveryClient.
+	BackendDiscovery
+)
+
+var backendNames = map[Backend]string{
+	BackendAPI:       "api",
+	BackendDiscovery: "discovery",
+}
+
+func (b Backend) String() string {
+	return backendNames[b]
+}
+
+// Set sets the backend from its name, it satisfies the flag.Value.
+func (b *Backend) Set(s string) error {
+	for k, v := range backendNames {
+		if strings.EqualFold(v, s) {
+			*b = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown backend: %q, use one of: api, discovery", s)
+}
+
+// discoveryClient is the Slacker, that fetches the conversations, their
+// messages and the users with the Discovery API methods, the rest of the
+// calls, i.e. the file downloads, go to the Web API client.  The results are
+// converted to the ones of the Web API, so that the output is the same.
+//
+// The Discovery API returns the thread replies with the messages of the
+// conversation history.  They are collected, while the history is fetched,
+// and the thread replies are served from them, if the history was fetched
+// up to the present, otherwise the history after the thread is scanned.
+type discoveryClient struct {
+	Slacker
+	api *apiClient
+
+	mu sync.Mutex
+	// threads are the threads, seen in the history, by the channel ID and
+	// the thread timestamp, the parent, if seen, is the first message.
+	threads map[string]map[string][]slack.Message
+	// complete is set for the channels, which history was fetched up to the
+	// present, so that the threads have all their replies.
+	complete map[string]bool
+}
+
+// newDiscoveryClient returns the Discovery API client, it checks that the
+// token can call the Discovery API.
+func newDiscoveryClient(ctx context.Context, cl Slacker, api *apiClient) (*discoveryClient, string, error) {
+	var resp struct {
+		Enterprise struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"enterprise"`
+	}
+	if err := api.call(ctx, "discovery.enterprise.info", nil, &resp); err != nil {
+		return nil, "", fmt.Errorf("discovery backend: %w", err)
+	}
+	dc := &discoveryClient{
+		Slacker:  cl,
+		api:      api,
+		threads:  make(map[string]map[string][]slack.Message),
+		complete: make(map[string]bool),
+	}
+	return dc, resp.Enterprise.Name, nil
+}
+
+// discoveryChannel is the conversation, as returned by the Discovery API.
+type discoveryChannel struct {
+	slack.Channel
+	DateCreated int64 `json:"date_created"`
+}
+
+func (c discoveryChannel) channel() slack.Channel {
+	ch := c.Channel
+	if ch.Created == 0 {
+		ch.Created = slack.JSONTime(c.DateCreated)
+	}
+	if strings.HasPrefix(ch.ID, "D") {
+		ch.IsIM = true
+	}
+	return ch
+}
+
+// chanType returns the conversation type of the channel, as in the Types
+// of the slack.GetConversationsParameters.
+func chanType(ch slack.Channel) string {
+	switch {
+	case ch.IsIM:
+		return "im"
+	case ch.IsMpIM:
+		return "mpim"
+	case ch.IsPrivate:
+		return "private_channel"
+	}
+	return "public_channel"
+}
+
+// nextCursor returns the cursor of the next page of the Discovery API
+// response, some methods return it as the offset.
+func nextCursor(meta slack.ResponseMetadata, offset string) string {
+	if meta.Cursor != "" {
+		return meta.Cursor
+	}
+	return offset
+}
+
+func (dc *discoveryClient) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	form := url.Values{}
+	if params.Cursor != "" {
+		form.Set("cursor", params.Cursor)
+	}
+	if params.Limit > 0 {
+		form.Set("limit", strconv.Itoa(params.Limit))
+	}
+	var resp struct {
+		Channels         []discoveryChannel     `json:"channels"`
+		Offset           string                 `json:"offset"`
+		ResponseMetadata slack.ResponseMetadata `json:"response_metadata"`
+	}
+	if err := dc.api.call(ctx, "discovery.conversations.list", form, &resp); err != nil {
+		return nil, "", err
+	}
+	types := make(map[string]bool, len(params.Types))
+	for _, t := range params.Types {
+		types[t] = true
+	}
+	chans := make([]slack.Channel, 0, len(resp.Channels))
+	for _, c := range resp.Channels {
+		ch := c.channel()
+		if len(types) > 0 && !types[chanType(ch)] {
+			continue
+		}
+		if params.ExcludeArchived && ch.IsArchived {
+			continue
+		}
+		chans = append(chans, ch)
+	}
+	return chans, nextCursor(resp.ResponseMetadata, resp.Offset), nil
+}
+
+func (dc *discoveryClient) GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	var resp struct {
+		Info []discoveryChannel `json:"info"`
+	}
+	if err := dc.api.call(ctx, "discovery.conversations.info", url.Values{"channel": {input.ChannelID}}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Info) == 0 {
+		return nil, slack.SlackErrorResponse{Err: "channel_not_found"}
+	}
+	ch := resp.Info[0].channel()
+	return &ch, nil
+}
+
+// history fetches the page of the conversation history, that is older than
+// latest, newest first.
+func (dc *discoveryClient) history(ctx context.Context, channelID, oldest, latest string, inclusive bool, limit int) ([]slack.Message, bool, error) {
+	form := url.Values{"channel": {channelID}}
+	if oldest != "" {
+		form.Set("oldest", oldest)
+	}
+	if latest != "" {
+		form.Set("latest", latest)
+	}
+	if inclusive {
+		form.Set("inclusive", "true")
+	}
+	if limit > 0 {
+		form.Set("limit", strconv.Itoa(limit))
+	}
+	var resp struct {
+		Messages []slack.Message `json:"messages"`
+		HasMore  bool            `json:"has_more"`
+	}
+	if err := dc.api.call(ctx, "discovery.conversations.history", form, &resp); err != nil {
+		return nil, false, err
+	}
+	return resp.Messages, resp.HasMore, nil
+}
+
+// GetConversationHistoryContext returns the page of the top level messages
+// of the history, the thread replies are collected for
+// GetConversationRepliesContext.  The cursor is the timestamp of the last
+// message of the previous page.
+func (dc *discoveryClient) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	latest, inclusive := params.Latest, params.Inclusive
+	if params.Cursor != "" {
+		latest, inclusive = params.Cursor, false
+	}
+	msgs, hasMore, err := dc.history(ctx, params.ChannelID, params.Oldest, latest, inclusive, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	threads := dc.threads[params.ChannelID]
+	if params.Cursor == "" || threads == nil {
+		// the new scan of the history.
+		threads = make(map[string][]slack.Message)
+		dc.threads[params.ChannelID] = threads
+		dc.complete[params.ChannelID] = params.Latest == ""
+	}
+	resp := &slack.GetConversationHistoryResponse{SlackResponse: slack.SlackResponse{Ok: true}, HasMore: hasMore}
+	for _, m := range msgs {
+		if isReply(m) {
+			threads[m.ThreadTimestamp] = append(threads[m.ThreadTimestamp], m)
+			if m.SubType != "thread_broadcast" {
+				continue
+			}
+		}
+		if replies, ok := threads[m.Timestamp]; ok && !isReply(m) {
+			// the replies are newer than the parent, so they are on this or
+			// the previous pages.
+			if m.ThreadTimestamp == "" {
+				m.ThreadTimestamp = m.Timestamp
+			}
+			if m.ReplyCount == 0 {
+				m.ReplyCount = len(replies)
+			}
+			threads[m.Timestamp] = append([]slack.Message{m}, replies...)
+		}
+		resp.Messages = append(resp.Messages, m)
+	}
+	if hasMore && len(msgs) > 0 {
+		resp.ResponseMetaData.NextCursor = msgs[len(msgs)-1].Timestamp
+	}
+	return resp, nil
+}
+
+// GetConversationRepliesContext returns the parent and the replies of the
+// thread in one page.  The thread is taken from the fetched history, if it
+// is complete, otherwise the history after the thread is scanned for the
+// replies.
+func (dc *discoveryClient) GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	dc.mu.Lock()
+	thread, ok := dc.threads[params.ChannelID][params.Timestamp]
+	ok = ok && dc.complete[params.ChannelID] && len(thread) > 0 && thread[0].Timestamp == params.Timestamp
+	dc.mu.Unlock()
+	if !ok {
+		var err error
+		if thread, err = dc.scanThread(ctx, params.ChannelID, params.Timestamp); err != nil {
+			return nil, false, "", err
+		}
+	}
+	if len(thread) == 0 {
+		return nil, false, "", slack.SlackErrorResponse{Err: "thread_not_found"}
+	}
+	msgs := []slack.Message{thread[0]}
+	for _, m := range sortedReplies(thread[1:]) {
+		if (params.Oldest != "" && m.Timestamp < params.Oldest) || (params.Latest != "" && m.Timestamp > params.Latest) {
+			continue
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs, false, "", nil
+}
+
+// scanThread fetches the history of the channel from the thread threadTS to
+// the present, and returns the parent and the replies of the thread.
+func (dc *discoveryClient) scanThread(ctx context.Context, channelID, threadTS string) ([]slack.Message, error) {
+	var (
+		parent  *slack.Message
+		replies []slack.Message
+		latest  string
+	)
+	for {
+		msgs, hasMore, err := dc.history(ctx, channelID, threadTS, latest, true, 0)
+		if err != nil {
+			return nil, err
+		}
+		for i, m := range msgs {
+			switch {
+			case m.Timestamp == threadTS:
+				parent = &msgs[i]
+			case m.ThreadTimestamp == threadTS:
+				replies = append(replies, m)
+			}
+		}
+		if !hasMore || len(msgs) == 0 {
+			break
+		}
+		latest = msgs[len(msgs)-1].Timestamp
+	}
+	if parent == nil {
+		return nil, nil
+	}
+	return append([]slack.Message{*parent}, replies...), nil
+}
+
+func sortedReplies(msgs []slack.Message) []slack.Message {
+	out := append([]slack.Message{}, msgs...)
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Timestamp < out[j].Timestamp
+	})
+	return out
+}
+
+// isReply returns true if the message is the thread reply.
+func isReply(m slack.Message) bool {
+	return m.ThreadTimestamp != "" && m.ThreadTimestamp != m.Timestamp
+}
+
+// GetUsersContext returns all users of the organisation, the options are
+// ignored.
+func (dc *discoveryClient) GetUsersContext(ctx context.Context, _ ...slack.GetUsersOption) ([]slack.User, error) {
+	var (
+		users  []slack.User
+		cursor string
+	)
+	for {
+		form := url.Values{"include_deleted": {"true"}}
+		if cursor != "" {
+			form.Set("cursor", cursor)
+		}
+		var resp struct {
+			Users            []slack.User           `json:"users"`
+			Offset           string                 `json:"offset"`
+			ResponseMetadata slack.ResponseMetadata `json:"response_metadata"`
+		}
+		if err := dc.api.call(ctx, "discovery.users.list", form, &resp); err != nil {
+			return nil, err
+		}
+		users = append(users, resp.Users...)
+		if cursor = nextCursor(resp.ResponseMetadata, resp.Offset); cursor == "" || len(resp.Users) == 0 {
+			break
+		}
+	}
+	return users, nil
+}
+
+func (dc *discoveryClient) GetUserInfoContext(ctx context.Context, user string) (*slack.User, error) {
+	var resp struct {
+		User slack.User `json:"user"`
+	}
+	if err := dc.api.call(ctx, "discovery.user.info", url.Values{"user": {user}}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.User, nil
+}
@@ -0,0 +1,187 @@
+package slackdump
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackend_Set(t *testing.T) {
+	var b Backend
+	require.NoError(t, b.Set("Discovery"))
+	assert.Equal(t, BackendDiscovery, b)
+	assert.Equal(t, "discovery", b.String())
+	assert.Error(t, b.Set("admin"))
+}
+
+// discoveryServer returns the server that serves the Discovery API methods,
+// the responses are returned by fn, called with the method name and the
+// request, it records the calls.
+func discoveryServer(t *testing.T, fn func(method string, r *http.Request) string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := path.Base(r.URL.Path)
+		calls = append(calls, method)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(fn(method, r)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func testDiscoveryClient(t *testing.T, srv *httptest.Server) *discoveryClient {
+	t.Helper()
+	dc, name, err := newDiscoveryClient(context.Background(), nil, &apiClient{cl: srv.Client(), token: "xoxp-test", baseURL: srv.URL + "/"})
+	require.NoError(t, err)
+	assert.Equal(t, "Acme", name)
+	return dc
+}
+
+const enterpriseInfo = `{"ok":true,"enterprise":{"id":"E01","name":"Acme"}}`
+
+func Test_newDiscoveryClient_notAllowed(t *testing.T) {
+	srv, _ := discoveryServer(t, func(method string, r *http.Request) string {
+		return `{"ok":false,"error":"not_allowed_token_type"}`
+	})
+	_, _, err := newDiscoveryClient(context.Background(), nil, &apiClient{cl: srv.Client(), token: "xoxp-test", baseURL: srv.URL + "/"})
+	assert.ErrorContains(t, err, "not_allowed_token_type")
+}
+
+func Test_discoveryClient_GetConversationsContext(t *testing.T) {
+	srv, _ := discoveryServer(t, func(method string, r *http.Request) string {
+		switch method {
+		case "discovery.enterprise.info":
+			return enterpriseInfo
+		case "discovery.conversations.list":
+			if r.FormValue("cursor") == "" {
+				return `{"ok":true,"channels":[{"id":"C01","name":"general","date_created":1600000000},{"id":"D01"}],"offset":"D01"}`
+			}
+			return `{"ok":true,"channels":[{"id":"G01","is_private":true,"is_archived":true}]}`
+		}
+		t.Errorf("unexpected method: %s", method)
+		return `{}`
+	})
+	dc := testDiscoveryClient(t, srv)
+
+	chans, next, err := dc.GetConversationsContext(context.Background(), &slack.GetConversationsParameters{Types: AllChanTypes})
+	require.NoError(t, err)
+	assert.Equal(t, "D01", next)
+	require.Len(t, chans, 2)
+	assert.Equal(t, slack.JSONTime(1600000000), chans[0].Created)
+	assert.True(t, chans[1].IsIM, "DM is detected by the ID")
+
+	chans, _, err = dc.GetConversationsContext(context.Background(), &slack.GetConversationsParameters{Types: []string{"im"}})
+	require.NoError(t, err)
+	require.Len(t, chans, 1)
+	assert.Equal(t, "D01", chans[0].ID)
+
+	chans, next, err = dc.GetConversationsContext(context.Background(), &slack.GetConversationsParameters{Cursor: next, ExcludeArchived: true})
+	require.NoError(t, err)
+	assert.Empty(t, chans)
+	assert.Empty(t, next)
+}
+
+func Test_discoveryClient_threads(t *testing.T) {
+	srv, calls := discoveryServer(t, func(method string, r *http.Request) string {
+		switch method {
+		case "discovery.enterprise.info":
+			return enterpriseInfo
+		case "discovery.conversations.history":
+			// newest first, the replies are in the history.
+			switch r.FormValue("latest") {
+			case "":
+				return `{"ok":true,"has_more":true,"messages":[
+					{"ts":"3.000000","thread_ts":"1.000000","text":"reply 2"},
+					{"ts":"2.000000","thread_ts":"1.000000","text":"reply 1"}
+				]}`
+			case "2.000000":
+				return `{"ok":true,"messages":[{"ts":"1.000000","text":"parent"},{"ts":"0.500000","text":"old"}]}`
+			}
+		}
+		t.Errorf("unexpected request: %s %v", method, r.Form)
+		return `{}`
+	})
+	dc := testDiscoveryClient(t, srv)
+	ctx := context.Background()
+
+	resp, err := dc.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{ChannelID: "C01", Inclusive: true})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Messages, "replies are not in the history")
+	assert.True(t, resp.HasMore)
+	require.Equal(t, "2.000000", resp.ResponseMetaData.NextCursor)
+
+	resp, err = dc.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{ChannelID: "C01", Cursor: resp.ResponseMetaData.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, resp.Messages, 2)
+	parent := resp.Messages[0]
+	assert.Equal(t, "1.000000", parent.ThreadTimestamp)
+	assert.Equal(t, 2, parent.ReplyCount)
+
+	*calls = nil
+	msgs, hasMore, _, err := dc.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{ChannelID: "C01", Timestamp: "1.000000"})
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	require.Len(t, msgs, 3)
+	assert.Equal(t, []string{"parent", "reply 1", "reply 2"}, []string{msgs[0].Text, msgs[1].Text, msgs[2].Text})
+	assert.Empty(t, *calls, "the thread is served from the history")
+}
+
+func Test_discoveryClient_scanThread(t *testing.T) {
+	srv, _ := discoveryServer(t, func(method string, r *http.Request) string {
+		switch method {
+		case "discovery.enterprise.info":
+			return enterpriseInfo
+		case "discovery.conversations.history":
+			switch {
+			case r.FormValue("oldest") != "1.000000":
+				return `{"ok":true,"messages":[]}`
+			case r.FormValue("latest") == "":
+				return `{"ok":true,"has_more":true,"messages":[{"ts":"4.000000","text":"other"},{"ts":"3.000000","thread_ts":"1.000000","text":"reply 2"}]}`
+			default:
+				return `{"ok":true,"messages":[{"ts":"2.000000","thread_ts":"1.000000","text":"reply 1"},{"ts":"1.000000","thread_ts":"1.000000","reply_count":2,"text":"parent"}]}`
+			}
+		}
+		t.Errorf("unexpected method: %s", method)
+		return `{}`
+	})
+	dc := testDiscoveryClient(t, srv)
+
+	msgs, _, _, err := dc.GetConversationRepliesContext(context.Background(), &slack.GetConversationRepliesParameters{ChannelID: "C01", Timestamp: "1.000000", Latest: "2.500000"})
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "parent", msgs[0].Text)
+	assert.Equal(t, "reply 1", msgs[1].Text, "replies after latest are skipped")
+
+	_, _, _, err = dc.GetConversationRepliesContext(context.Background(), &slack.GetConversationRepliesParameters{ChannelID: "C01", Timestamp: "9.000000"})
+	assert.Error(t, err)
+}
+
+func Test_discoveryClient_GetUsersContext(t *testing.T) {
+	srv, _ := discoveryServer(t, func(method string, r *http.Request) string {
+		switch method {
+		case "discovery.enterprise.info":
+			return enterpriseInfo
+		case "discovery.users.list":
+			if r.FormValue("cursor") == "" {
+				return `{"ok":true,"users":[{"id":"U01"}],"response_metadata":{"next_cursor":"next"}}`
+			}
+			return `{"ok":true,"users":[{"id":"U02","deleted":true}]}`
+		}
+		t.Errorf("unexpected method: %s", method)
+		return `{}`
+	})
+	dc := testDiscoveryClient(t, srv)
+
+	users, err := dc.GetUsersContext(context.Background())
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "U01", users[0].ID)
+	assert.True(t, users[1].Deleted)
+}
@@ -0,0 +1,26 @@
+// Package slackdump is the library for archiving Slack workspaces, it is the
+// engine of the slackdump command, and can be embedded into Go programs.
+//
+// Create the Session with one of the auth providers, then archive the
+// conversations:
+//
+//	prov, err := auth.NewValueAuth(token, cookie)
+//	if err != nil {
+//		return err
+//	}
+//	sd, err := slackdump.New(ctx, prov, slackdump.DownloadFiles(true))
+//	if err != nil {
+//		return err
+//	}
+//	res, err := sd.Archive(ctx, "archive.zip", time.Time{}, time.Time{}, "C01", "D02")
+//
+// The Result contains the per-conversation message counts, durations, skip
+// reasons and errors, and the file download statistics.
+//
+// Session.Dump and Session.DumpRaw return the conversation without saving it,
+// GetUsers and GetChannels return the users and the channels of the
+// workspace.  Session.Process passes the records of the conversation to the
+// processor pipeline as they are fetched, see the processor package.  The
+// Slack Export compatible archives are created by the export
+// package, see export.Create.
+package slackdump
@@ -17,8 +17,12 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/metrics"
 	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/otrace"
 	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/progress"
+	"github.com/rusq/slackdump/v2/sanitize"
 )
 
 const (
@@ -34,6 +38,7 @@ type Client struct {
 	limiter *rate.Limiter
 	fs      fsadapter.FS
 	dlog    logger.Interface
+	prog    progress.Reporter
 
 	retries int
 	workers int
@@ -43,7 +48,10 @@ type Client struct {
 	wg           *sync.WaitGroup
 	started      bool
 
-	nameFn FilenameFunc
+	nameFn  FilenameFunc
+	profile sanitize.Profile
+	window  Window   // daily download window, see Schedule.
+	skip    SkipList // files, that fail run after run, see Skip.
 }
 
 // FilenameFunc is the file naming function that should return the output
@@ -103,6 +111,14 @@ func Logger(l logger.Interface) Option {
 	}
 }
 
+// Progress sets the reporter of the download progress.  By default, the
+// progress is written to the log.
+func Progress(r progress.Reporter) Option {
+	return func(c *Client) {
+		c.prog = r
+	}
+}
+
 func WithNameFunc(fn FilenameFunc) Option {
 	return func(c *Client) {
 		if fn != nil {
@@ -113,6 +129,36 @@ func WithNameFunc(fn FilenameFunc) Option {
 	}
 }
 
+// Sanitize sets the sanitization profile of the file names, that are
+// returned by the naming function.  The default is sanitize.Default.
+func Sanitize(p sanitize.Profile) Option {
+	return func(c *Client) {
+		c.profile = p
+	}
+}
+
+// SkipList is the persistent list of the files, that failed to download in
+// several runs in a row, *slackdump.SkipList satisfies it.
+type SkipList interface {
+	// Skipped returns the reason and true, if the item is skipped.
+	Skipped(kind, id string) (string, bool)
+	// Fail records the failure of the item.
+	Fail(kind, id string, err error) error
+	// Clear removes the item, that was downloaded.
+	Clear(kind, id string) error
+}
+
+// skipKind is the skip-list kind of the files, see slackdump.SkipFile.
+const skipKind = "file"
+
+// Skip sets the skip-list of the files:  the files on it are not downloaded,
+// and the download failures are recorded on it.
+func Skip(sl SkipList) Option {
+	return func(c *Client) {
+		c.skip = sl
+	}
+}
+
 // New initialises new file downloader.
 func New(client Downloader, fs fsadapter.FS, opts ...Option) *Client {
 	if client == nil {
@@ -126,6 +172,7 @@ func New(client Downloader, fs fsadapter.FS, opts ...Option) *Client {
 		retries: defRetries,
 		workers: defNumWorkers,
 		nameFn:  Filename,
+		profile: sanitize.Default,
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -134,7 +181,11 @@ func New(client Downloader, fs fsadapter.FS, opts ...Option) *Client {
 }
 
 // SaveFile saves a single file to the specified directory synchrounously.
+// Outside of the download window, it waits for the window to open.
 func (c *Client) SaveFile(ctx context.Context, dir string, f *slack.File) (int64, error) {
+	if err := c.waitWindow(ctx); err != nil {
+		return 0, err
+	}
 	return c.saveFile(ctx, dir, f)
 }
 
@@ -166,7 +217,7 @@ func (c *Client) startWorkers(ctx context.Context, req <-chan fileRequest) *sync
 	if c.workers == 0 {
 		c.workers = defNumWorkers
 	}
-	seenC := c.fltSeen(req)
+	seenC := c.fltWindow(ctx, c.fltSeen(ctx, req))
 	var wg sync.WaitGroup
 	// create workers
 	for i := 0; i < c.workers; i++ {
@@ -192,13 +243,13 @@ func (c *Client) worker(ctx context.Context, reqC <-chan fileRequest) {
 			if !moar {
 				return
 			}
-			c.l().Debugf("saving %q to %s, size: %d", c.nameFn(req.File), req.Directory, req.File.Size)
 			n, err := c.saveFile(ctx, req.Directory, req.File)
+			metrics.Backlog.Add(-1)
+			c.pr().FileDone(ctx, req.Directory, req.File, n, err)
 			if err != nil {
-				c.l().Printf("error saving %q to %q: %s", c.nameFn(req.File), req.Directory, err)
 				break
 			}
-			c.l().Printf("file %q saved to %s: %d bytes written", c.nameFn(req.File), req.Directory, n)
+			metrics.FilesDownloaded.Inc()
 		}
 	}
 }
@@ -219,6 +270,7 @@ func (c *Client) AsyncDownloader(ctx context.Context, dir string, fileDlQueue <-
 	go func() {
 		defer close(req)
 		for f := range fileDlQueue {
+			metrics.Backlog.Add(1)
 			req <- fileRequest{Directory: dir, File: f}
 		}
 	}()
@@ -235,15 +287,26 @@ func (c *Client) AsyncDownloader(ctx context.Context, dir string, fileDlQueue <-
 }
 
 // saveFileWithLimiter saves the file to specified directory, it will use the provided limiter l for throttling.
-func (c *Client) saveFile(ctx context.Context, dir string, sf *slack.File) (int64, error) {
+func (c *Client) saveFile(ctx context.Context, dir string, sf *slack.File) (_ int64, err error) {
 	if c.fs == nil {
 		return 0, ErrNoFS
 	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	ctx, span := otrace.Start(ctx, "file.download", otrace.String("file.id", sf.ID), otrace.Int("file.size", sf.Size))
+	defer func() { span.End(err) }()
 	if mode := sf.Mode; mode == "hidden_by_limit" || mode == "external" || sf.IsExternal {
 		trace.Logf(ctx, "info", "file %q is not downloadable", sf.Name)
 		return 0, nil
 	}
-	filePath := filepath.Join(dir, c.nameFn(sf))
+	if c.skip != nil {
+		if reason, ok := c.skip.Skipped(skipKind, sf.ID); ok {
+			c.l().Printf("skipping the file %s, it is on the skip-list, the last error: %s", sf.ID, reason)
+			return 0, nil
+		}
+	}
+	filePath := filepath.Join(dir, c.filename(sf))
 
 	tf, err := os.CreateTemp("", "")
 	if err != nil {
@@ -266,8 +329,19 @@ func (c *Client) saveFile(ctx context.Context, dir string, sf *slack.File) (int6
 		}
 		return nil
 	}); err != nil {
+		err = network.Classify("", err)
+		if c.skip != nil && ctx.Err() == nil {
+			if serr := c.skip.Fail(skipKind, sf.ID, err); serr != nil {
+				c.l().Printf("error saving the skip-list: %s", serr)
+			}
+		}
 		return 0, err
 	}
+	if c.skip != nil {
+		if err := c.skip.Clear(skipKind, sf.ID); err != nil {
+			c.l().Printf("error saving the skip-list: %s", err)
+		}
+	}
 
 	// at this point, temporary file position would be at EOF, we need to reset
 	// it prior to copying.
@@ -281,7 +355,7 @@ func (c *Client) saveFile(ctx context.Context, dir string, sf *slack.File) (int6
 	}
 	defer fsf.Close()
 
-	n, err := io.Copy(fsf, tf)
+	n, err := io.Copy(ctxWriter{ctx, fsf}, tf)
 	if err != nil {
 		return 0, err
 	}
@@ -289,6 +363,20 @@ func (c *Client) saveFile(ctx context.Context, dir string, sf *slack.File) (int6
 	return int64(n), nil
 }
 
+// ctxWriter is the writer that fails once the context is cancelled, so that
+// the copying of a large file is interrupted.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw ctxWriter) Write(p []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(p)
+}
+
 func stdFilenameFn(f *slack.File) string {
 	return fmt.Sprintf("%s-%s", f.ID, f.Name)
 }
@@ -327,8 +415,14 @@ func (c *Client) DownloadFile(dir string, f slack.File) (string, error) {
 	if !started {
 		return "", ErrNotStarted
 	}
+	metrics.Backlog.Add(1)
 	c.fileRequests <- fileRequest{Directory: dir, File: &f}
-	return path.Join(dir, Filename(&f)), nil
+	return path.Join(dir, c.filename(&f)), nil
+}
+
+// filename returns the sanitized output filename of the file f.
+func (c *Client) filename(f *slack.File) string {
+	return c.profile.Name(c.nameFn(f))
 }
 
 func (c *Client) l() logger.Interface {
@@ -337,3 +431,10 @@ func (c *Client) l() logger.Interface {
 	}
 	return c.dlog
 }
+
+func (c *Client) pr() progress.Reporter {
+	if c.prog == nil {
+		return progress.NewLog(c.l())
+	}
+	return c.prog
+}
@@ -19,6 +19,8 @@ import (
 	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/internal/fixtures"
 	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
+	"github.com/rusq/slackdump/v2/progress"
+	"github.com/rusq/slackdump/v2/sanitize"
 )
 
 var (
@@ -172,6 +174,24 @@ func TestSession_saveFile(t *testing.T) {
 			int64(file1.Size),
 			false,
 		},
+		{
+			"cancelled context",
+			fields{
+				l:       rate.NewLimiter(defLimit, 1),
+				fs:      fsadapter.NewDirectory(tmpdir),
+				retries: defRetries,
+				workers: defNumWorkers,
+				nameFn:  Filename,
+			},
+			args{
+				cancelled(),
+				"02",
+				&file2,
+			},
+			func(mc *mock_downloader.MockDownloader) {},
+			int64(0),
+			true,
+		},
 		{
 			"getfile rekt",
 			fields{
@@ -280,6 +300,38 @@ func TestSession_newFileDownloader(t *testing.T) {
 		assert.FileExists(t, filename)
 
 	})
+	t.Run("cancelled context does not block the senders", func(t *testing.T) {
+		mc := mock_downloader.NewMockDownloader(gomock.NewController(t))
+		sd := Client{
+			client:  mc,
+			fs:      fsadapter.NewDirectory(tmpdir),
+			limiter: tl,
+			retries: 3,
+			workers: 1,
+			nameFn:  Filename,
+			prog:    progress.Nop{},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		filesC := make(chan *slack.File)
+		done, err := sd.AsyncDownloader(ctx, ".", filesC)
+		require.NoError(t, err)
+		sent := make(chan struct{})
+		go func() {
+			defer close(sent)
+			for _, f := range []*slack.File{&file1, &file2, &file9} {
+				filesC <- f
+			}
+			close(filesC)
+		}()
+		select {
+		case <-sent:
+		case <-time.After(5 * time.Second):
+			t.Fatal("senders are blocked")
+		}
+		<-done
+	})
 }
 
 func TestSession_worker(t *testing.T) {
@@ -336,6 +388,25 @@ func TestSession_worker(t *testing.T) {
 		_, err := os.Stat(filepath.Join(tmpdir, "01", Filename(&file1)))
 		assert.True(t, os.IsNotExist(err))
 	})
+	t.Run("reports progress", func(t *testing.T) {
+		mc := mock_downloader.NewMockDownloader(gomock.NewController(t))
+		sd := newClient(mc)
+		rec := &doneReporter{}
+		sd.prog = rec
+
+		mc.EXPECT().
+			GetFile(file1.URLPrivateDownload, gomock.Any()).
+			Return(errors.New("rekt")).
+			Times(1)
+
+		reqC := make(chan fileRequest, 1)
+		reqC <- fileRequest{Directory: "01", File: &file1}
+		close(reqC)
+
+		sd.worker(context.Background(), reqC)
+		assert.Equal(t, []string{"01"}, rec.dirs)
+		assert.Len(t, rec.errs, 1)
+	})
 	t.Run("cancelled context", func(t *testing.T) {
 		mc := mock_downloader.NewMockDownloader(gomock.NewController(t))
 		sd := newClient(mc)
@@ -349,6 +420,20 @@ func TestSession_worker(t *testing.T) {
 	})
 }
 
+// doneReporter records the finished downloads.
+type doneReporter struct {
+	progress.Nop
+	dirs []string
+	errs []error
+}
+
+func (r *doneReporter) FileDone(_ context.Context, dir string, _ *slack.File, _ int64, err error) {
+	r.dirs = append(r.dirs, dir)
+	if err != nil {
+		r.errs = append(r.errs, err)
+	}
+}
+
 func TestClient_startWorkers(t *testing.T) {
 	t.Run("check that start actually starts workers", func(t *testing.T) {
 		const qSz = 10
@@ -465,4 +550,66 @@ func TestClient_DownloadFile(t *testing.T) {
 
 		c.Stop()
 	})
+	t.Run("filename is sanitized", func(t *testing.T) {
+		c := clientWithMock(t, dir)
+		Sanitize(sanitize.WindowsSafe)(c)
+		c.Start(context.Background())
+
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(nil)
+
+		filename, err := c.DownloadFile("01", slack.File{ID: "F01", Name: "notes: 12/05?.txt", URLPrivateDownload: "url"})
+		require.NoError(t, err)
+		c.Stop()
+		assert.Equal(t, "01/F01-notes_ 12_05_.txt", filename)
+		assert.FileExists(t, filepath.Join(dir, filename))
+	})
+}
+
+// cancelled returns the cancelled context.
+func cancelled() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+// skipList is the SkipList of the file IDs.
+type skipList map[string]bool
+
+func (s skipList) Skipped(kind, id string) (string, bool) {
+	return "server error", s[kind+":"+id]
+}
+
+func (s skipList) Fail(kind, id string, err error) error {
+	s[kind+":"+id] = true
+	return nil
+}
+
+func (s skipList) Clear(kind, id string) error {
+	delete(s, kind+":"+id)
+	return nil
+}
+
+func TestClient_SaveFile_skip(t *testing.T) {
+	mc := mock_downloader.NewMockDownloader(gomock.NewController(t))
+	sl := skipList{"file:" + file1.ID: true, "file:" + file2.ID: true}
+	c := New(mc, fsadapter.NewDirectory(t.TempDir()), Retries(1), Skip(sl))
+
+	// file1 is skipped, and not requested.
+	n, err := c.SaveFile(context.Background(), "x", &file1)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	mc.EXPECT().GetFile(file2.URLPrivateDownload, gomock.Any()).Return(nil)
+	sl["file:"+file2.ID] = false
+	_, err = c.SaveFile(context.Background(), "x", &file2)
+	require.NoError(t, err)
+	assert.NotContains(t, sl, "file:"+file2.ID, "the downloaded file must be cleared")
+
+	mc.EXPECT().GetFile(file3.URLPrivateDownload, gomock.Any()).Return(errors.New("500"))
+	_, err = c.SaveFile(context.Background(), "x", &file3)
+	require.Error(t, err)
+	assert.True(t, sl["file:"+file3.ID], "the failure must be recorded")
 }
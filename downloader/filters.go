@@ -1,8 +1,17 @@
 package downloader
 
+import (
+	"context"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/metrics"
+)
+
 // fltSeen filters the files from filesC to ensure that no duplicates
-// are downloaded.
-func (c *Client) fltSeen(filesC <-chan fileRequest) <-chan fileRequest {
+// are downloaded.  The files that pass the filter are reported as queued.
+// Once the context is cancelled, the workers stop, and the files are
+// discarded, so that the senders are not blocked.
+func (c *Client) fltSeen(ctx context.Context, filesC <-chan fileRequest) <-chan fileRequest {
 	dlQ := make(chan fileRequest)
 	go func() {
 		// closing stop will lead to all worker goroutines to terminate.
@@ -13,13 +22,79 @@ func (c *Client) fltSeen(filesC <-chan fileRequest) <-chan fileRequest {
 		seen := make(map[string]bool)
 		// files queue must be closed by the caller (see DumpToDir.(1))
 		for f := range filesC {
+			if ctx.Err() != nil {
+				metrics.Backlog.Add(-1)
+				continue
+			}
 			id := f.File.ID + f.Directory
 			if _, ok := seen[id]; ok {
 				c.l().Debugf("already seen %q, skipping", Filename(f.File))
+				metrics.Backlog.Add(-1)
 				continue
 			}
 			seen[id] = true
-			dlQ <- f
+			c.pr().FileQueued(ctx, f.Directory, f.File)
+			select {
+			case <-ctx.Done():
+				metrics.Backlog.Add(-1)
+			case dlQ <- f:
+			}
+		}
+	}()
+	return dlQ
+}
+
+// fltWindow passes the files from filesC to the workers only within the
+// download window, outside of it, the files are queued in memory, so that
+// the senders are not blocked.  Without the window, filesC is returned as is.
+func (c *Client) fltWindow(ctx context.Context, filesC <-chan fileRequest) <-chan fileRequest {
+	if c.window.IsZero() {
+		return filesC
+	}
+	dlQ := make(chan fileRequest)
+	go func() {
+		defer close(dlQ)
+
+		var (
+			queue  []fileRequest
+			paused bool
+		)
+		for filesC != nil || len(queue) > 0 {
+			now := time.Now()
+			open := c.window.Contains(now)
+			if open == paused {
+				paused = !open
+				if paused {
+					c.l().Printf("outside of the download window %s, the downloads are paused", c.window)
+				} else {
+					c.l().Printf("the download window %s is open, resuming %d queued download(s)", c.window, len(queue))
+				}
+			}
+			var (
+				sendC chan<- fileRequest // nil blocks, unless the window is open.
+				next  fileRequest
+			)
+			if open && len(queue) > 0 {
+				sendC, next = dlQ, queue[0]
+			}
+			t := time.NewTimer(c.window.untilChange(now))
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				metrics.Backlog.Add(-len(queue))
+				return
+			case f, ok := <-filesC:
+				if !ok {
+					filesC = nil
+					break
+				}
+				queue = append(queue, f)
+			case sendC <- next:
+				queue[0] = fileRequest{}
+				queue = queue[1:]
+			case <-t.C:
+			}
+			t.Stop()
 		}
 	}()
 	return dlQ
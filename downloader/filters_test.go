@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"context"
 	"testing"
 
 	"github.com/rusq/slackdump/v2/internal/fixtures"
@@ -40,7 +41,7 @@ func Test_fltSeen(t *testing.T) {
 		}()
 
 		c := Client{}
-		dlqC := c.fltSeen(filesC)
+		dlqC := c.fltSeen(context.Background(), filesC)
 
 		var got []fileRequest
 		for f := range dlqC {
@@ -61,7 +62,7 @@ func BenchmarkFltSeen(b *testing.B) {
 		}
 	}()
 	c := Client{}
-	outputC := c.fltSeen(inputC)
+	outputC := c.fltSeen(context.Background(), inputC)
 
 	for n := 0; n < b.N; n++ {
 		for out := range outputC {
@@ -0,0 +1,148 @@
+package downloader
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window is the daily time window of the file downloads, in the local time.
+// The window, that ends before it starts, i.e. 22:00-06:00, spans midnight.
+// The zero Window is always open.
+type Window struct {
+	Start time.Duration // offset of the start from midnight.
+	End   time.Duration // offset of the end from midnight.
+}
+
+var _ flag.Value = new(Window)
+
+const day = 24 * time.Hour
+
+// ParseWindow parses the window in the "HH:MM-HH:MM" format.
+func ParseWindow(s string) (Window, error) {
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return Window{}, fmt.Errorf("invalid download window %q, use HH:MM-HH:MM, i.e. 22:00-06:00", s)
+	}
+	var w Window
+	var err error
+	if w.Start, err = parseClock(start); err != nil {
+		return Window{}, err
+	}
+	if w.End, err = parseClock(end); err != nil {
+		return Window{}, err
+	}
+	if w.Start == w.End {
+		return Window{}, fmt.Errorf("invalid download window %q: the window is empty", s)
+	}
+	return w, nil
+}
+
+// parseClock parses the time of the day in the "HH:MM" format, and returns
+// its offset from midnight.  "24:00" is the end of the day.
+func parseClock(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "24:00" {
+		return day, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of the day %q, use HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// IsZero returns true, if the window is not set.
+func (w Window) IsZero() bool {
+	return w.Start == w.End
+}
+
+func (w Window) String() string {
+	if w.IsZero() {
+		return ""
+	}
+	return clock(w.Start) + "-" + clock(w.End)
+}
+
+func clock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", d/time.Hour, d%time.Hour/time.Minute)
+}
+
+// Set sets the window from the "HH:MM-HH:MM" string, the empty string unsets
+// it.  It is the flag.Value interface.
+func (w *Window) Set(s string) error {
+	if s == "" {
+		*w = Window{}
+		return nil
+	}
+	v, err := ParseWindow(s)
+	if err != nil {
+		return err
+	}
+	*w = v
+	return nil
+}
+
+// sinceMidnight returns the offset of t from the midnight of its day.
+func sinceMidnight(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// Contains returns true, if the time t is within the window.
+func (w Window) Contains(t time.Time) bool {
+	if w.IsZero() {
+		return true
+	}
+	d := sinceMidnight(t)
+	if w.Start < w.End {
+		return w.Start <= d && d < w.End
+	}
+	return d >= w.Start || d < w.End
+}
+
+// untilChange returns the duration from t until the window opens or closes.
+func (w Window) untilChange(t time.Time) time.Duration {
+	d := sinceMidnight(t)
+	until := func(b time.Duration) time.Duration {
+		if u := (b - d + day) % day; u > 0 {
+			return u
+		}
+		return day
+	}
+	s, e := until(w.Start%day), until(w.End%day)
+	if s < e {
+		return s
+	}
+	return e
+}
+
+// Schedule restricts the file downloads to the daily time window w.  Outside
+// of the window, the files are queued in memory, so that the messages are
+// fetched without waiting for the downloads, and the queue is resumed once
+// the window opens.  The downloads in progress are completed, when the window
+// closes.
+func Schedule(w Window) Option {
+	return func(c *Client) {
+		c.window = w
+	}
+}
+
+// waitWindow blocks until the download window is open, or the context is
+// cancelled.
+func (c *Client) waitWindow(ctx context.Context) error {
+	for !c.window.Contains(time.Now()) {
+		wait := c.window.untilChange(time.Now())
+		c.l().Printf("outside of the download window %s, the downloads are paused for %s", c.window, wait.Round(time.Minute))
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	return nil
+}
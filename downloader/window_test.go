@@ -0,0 +1,140 @@
+package downloader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Window
+		wantErr bool
+	}{
+		{"22:00-06:00", Window{Start: 22 * time.Hour, End: 6 * time.Hour}, false},
+		{"09:30 - 17:45", Window{Start: 9*time.Hour + 30*time.Minute, End: 17*time.Hour + 45*time.Minute}, false},
+		{"00:00-24:00", Window{Start: 0, End: day}, false},
+		{"22:00", Window{}, true},
+		{"25:00-06:00", Window{}, true},
+		{"06:00-06:00", Window{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseWindow(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWindow_Set(t *testing.T) {
+	var w Window
+	require.NoError(t, w.Set("22:00-06:00"))
+	assert.Equal(t, "22:00-06:00", w.String())
+	require.NoError(t, w.Set(""))
+	assert.True(t, w.IsZero())
+	assert.Equal(t, "", w.String())
+}
+
+func at(hh, mm int) time.Time {
+	return time.Date(2023, 3, 15, hh, mm, 0, 0, time.Local)
+}
+
+func TestWindow_Contains(t *testing.T) {
+	night := Window{Start: 22 * time.Hour, End: 6 * time.Hour}
+	day := Window{Start: 9 * time.Hour, End: 17 * time.Hour}
+	tests := []struct {
+		name string
+		w    Window
+		t    time.Time
+		want bool
+	}{
+		{"night, before midnight", night, at(23, 0), true},
+		{"night, after midnight", night, at(3, 0), true},
+		{"night, start", night, at(22, 0), true},
+		{"night, end", night, at(6, 0), false},
+		{"night, day time", night, at(12, 0), false},
+		{"day, inside", day, at(12, 0), true},
+		{"day, outside", day, at(20, 0), false},
+		{"zero", Window{}, at(12, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.w.Contains(tt.t))
+		})
+	}
+}
+
+func TestWindow_untilChange(t *testing.T) {
+	night := Window{Start: 22 * time.Hour, End: 6 * time.Hour}
+	assert.Equal(t, 10*time.Hour, night.untilChange(at(12, 0)), "until the start")
+	assert.Equal(t, 3*time.Hour, night.untilChange(at(3, 0)), "until the end")
+	assert.Equal(t, 7*time.Hour+30*time.Minute, night.untilChange(at(22, 30)), "until the end, after midnight")
+	assert.Equal(t, 8*time.Hour, night.untilChange(at(22, 0)), "at the start")
+}
+
+// closedWindow returns the window, that opens in an hour.
+func closedWindow() Window {
+	now := sinceMidnight(time.Now())
+	return Window{Start: (now + time.Hour) % day, End: (now + 2*time.Hour) % day}
+}
+
+func TestClient_fltWindow(t *testing.T) {
+	t.Run("no window", func(t *testing.T) {
+		c := &Client{dlog: logger.Silent}
+		filesC := make(chan fileRequest)
+		assert.Equal(t, (<-chan fileRequest)(filesC), c.fltWindow(context.Background(), filesC))
+	})
+	t.Run("files are queued outside of the window", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		c := &Client{dlog: logger.Silent, window: closedWindow()}
+
+		filesC := make(chan fileRequest)
+		dlQ := c.fltWindow(ctx, filesC)
+		sent := make(chan struct{})
+		go func() {
+			defer close(sent)
+			for i := 0; i < 2*defFileBufSz; i++ {
+				filesC <- fileRequest{Directory: "x", File: &file1}
+			}
+		}()
+		select {
+		case <-sent:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the sender is blocked")
+		}
+		select {
+		case <-dlQ:
+			t.Fatal("the file is passed outside of the window")
+		case <-time.After(50 * time.Millisecond):
+		}
+		cancel()
+		_, ok := <-dlQ
+		assert.False(t, ok, "the queue must be closed on cancel")
+	})
+	t.Run("files are passed within the window", func(t *testing.T) {
+		now := sinceMidnight(time.Now())
+		c := &Client{dlog: logger.Silent, window: Window{Start: (now - time.Hour + day) % day, End: (now + time.Hour) % day}}
+
+		filesC := make(chan fileRequest, 2)
+		filesC <- fileRequest{Directory: "x", File: &file1}
+		filesC <- fileRequest{Directory: "x", File: &file2}
+		close(filesC)
+		var got []fileRequest
+		for f := range c.fltWindow(context.Background(), filesC) {
+			got = append(got, f)
+		}
+		assert.Equal(t, []fileRequest{{Directory: "x", File: &file1}, {Directory: "x", File: &file2}}, got)
+	})
+}
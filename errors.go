@@ -0,0 +1,73 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+// Typed errors returned by the Session, StreamChannels and the file
+// downloader, so that the callers can branch on the failure mode:
+//
+//	var rl *slackdump.ErrRateLimited
+//	switch {
+//	case errors.As(err, &rl):
+//		// retry after rl.RetryAfter
+//	case errors.Is(err, slackdump.ErrTokenExpired):
+//		// reauthenticate
+//	case errors.Is(err, slackdump.ErrNotInChannel):
+//		// join the channel
+//	}
+//
+// The original Slack API error is wrapped, and is available to errors.As.
+type (
+	// ErrRateLimited is returned if the API kept rate limiting the requests,
+	// and the number of retries was exceeded.
+	ErrRateLimited = network.ErrRateLimited
+	// ErrNoAccess is returned if the conversation does not exist or the user
+	// does not have access to it.
+	ErrNoAccess = network.ErrNoAccess
+)
+
+var (
+	// ErrTokenExpired is returned if the token is invalid, expired or
+	// revoked.
+	ErrTokenExpired = network.ErrTokenExpired
+	// ErrNotInChannel is returned, wrapped in ErrNoAccess, if the user is not
+	// a member of the conversation.
+	ErrNotInChannel = network.ErrNotInChannel
+)
+
+// ErrBudgetExceeded is returned if the deadline budget of the session, see
+// WithDeadlineBudget, is spent.
+var ErrBudgetExceeded = errors.New("deadline budget exceeded")
+
+// ErrCallBudgetExceeded is returned if the maximum number of the API calls,
+// see WithAPIUsage, is reached.
+var ErrCallBudgetExceeded = errors.New("API call budget exceeded")
+
+// IsInterrupted returns true if err is the result of the run being
+// interrupted, either by the context, or by the spent deadline or API call
+// budget.
+func IsInterrupted(ctx context.Context, err error) bool {
+	return errors.Is(err, ErrBudgetExceeded) || errors.Is(err, ErrCallBudgetExceeded) || (err != nil && ctx.Err() != nil)
+}
+
+// proceed returns the context error, if the context is cancelled,
+// ErrBudgetExceeded, if the deadline budget is spent, or
+// ErrCallBudgetExceeded, if the API call budget is spent.  It is called by the
+// API loops before each request.
+func (sd *Session) proceed(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !sd.deadline.IsZero() && !time.Now().Before(sd.deadline) {
+		return ErrBudgetExceeded
+	}
+	if sd.options.APIUsage != nil && sd.options.APIUsage.Exceeded() {
+		return ErrCallBudgetExceeded
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_typedErrors(t *testing.T) {
+	t.Run("not in channel", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+			nil, slack.SlackErrorResponse{Err: "not_in_channel"},
+		)
+		sd := &Session{client: mc, options: DefOptions}
+		_, err := sd.DumpAll(context.Background(), "C01")
+
+		var na *ErrNoAccess
+		if assert.ErrorAs(t, err, &na) {
+			assert.Equal(t, "C01", na.Channel)
+		}
+		assert.ErrorIs(t, err, ErrNotInChannel)
+		assert.False(t, errors.Is(err, ErrTokenExpired))
+	})
+	t.Run("response not ok", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+			&slack.GetConversationHistoryResponse{SlackResponse: slack.SlackResponse{Ok: false, Error: "channel_not_found"}}, nil,
+		)
+		sd := &Session{client: mc, options: DefOptions}
+		_, err := sd.DumpAll(context.Background(), "C01")
+
+		var na *ErrNoAccess
+		assert.ErrorAs(t, err, &na)
+		assert.False(t, errors.Is(err, ErrNotInChannel))
+	})
+	t.Run("token expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mc.EXPECT().GetConversationsContext(gomock.Any(), gomock.Any()).Return(
+			nil, "", slack.SlackErrorResponse{Err: "token_revoked"},
+		)
+		sd := &Session{client: mc, options: DefOptions}
+		err := sd.StreamChannels(context.Background(), AllChanTypes, func(ch slack.Channel) error { return nil })
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+}
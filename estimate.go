@@ -0,0 +1,140 @@
+package slackdump
+
+// In this file: the estimate of the disk space, that the conversations take.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+	"golang.org/x/time/rate"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/structures"
+)
+
+// SizeEstimate is the rough estimate of the disk space, that the
+// conversations take, see Session.EstimateSize.
+type SizeEstimate struct {
+	Conversations int   // number of the conversations.
+	Sampled       int   // number of the sampled conversations.
+	Messages      int64 // estimated number of the messages, including the thread replies.
+	MessageBytes  int64 // estimated size of the messages JSON.
+	FileBytes     int64 // estimated size of the files, 0 if the files are not downloaded.
+}
+
+// Total returns the estimated size of the messages and the files.
+func (e SizeEstimate) Total() int64 {
+	return e.MessageBytes + e.FileBytes
+}
+
+// EstimateSize estimates the disk space, that the conversations channelIDs
+// take within the time range from oldest to latest.  Up to sample
+// conversations, evenly spread over the list, are sampled: the first page of
+// the history is fetched, and the number of the messages is extrapolated from
+// the time span of the page to the time range, or to the lifetime of the
+// conversation, and their size, and the size of the files, from the
+// messages of the page.  The conversations, that are not sampled, are
+// assumed to be the average of the sampled ones.  Each sampled conversation
+// costs one or two API calls.
+func (sd *Session) EstimateSize(ctx context.Context, channelIDs []string, oldest, latest time.Time, sample int) (SizeEstimate, error) {
+	est := SizeEstimate{Conversations: len(channelIDs)}
+	if len(channelIDs) == 0 || sample <= 0 {
+		return est, nil
+	}
+	if sample > len(channelIDs) {
+		sample = len(channelIDs)
+	}
+	l := sd.limiter(network.Tier3)
+	for i := 0; i < sample; i++ {
+		channelID := channelIDs[i*len(channelIDs)/sample]
+		s, err := sd.sampleSize(ctx, l, channelID, oldest, latest)
+		if err != nil {
+			return est, fmt.Errorf("estimating the size of %s: %w", channelID, err)
+		}
+		est.Sampled++
+		est.Messages += s.Messages
+		est.MessageBytes += s.MessageBytes
+		est.FileBytes += s.FileBytes
+	}
+	if est.Sampled < est.Conversations {
+		scale := func(n int64) int64 {
+			return n * int64(est.Conversations) / int64(est.Sampled)
+		}
+		est.Messages, est.MessageBytes, est.FileBytes = scale(est.Messages), scale(est.MessageBytes), scale(est.FileBytes)
+	}
+	return est, nil
+}
+
+// sampleSize estimates the size of the conversation from the first page of
+// its history.
+func (sd *Session) sampleSize(ctx context.Context, l *rate.Limiter, channelID string, oldest, latest time.Time) (SizeEstimate, error) {
+	var resp *slack.GetConversationHistoryResponse
+	if err := network.WithRetry(ctx, l, sd.options.Tier3Retries, func() error {
+		var err error
+		resp, err = sd.client.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID: channelID,
+			Limit:     sd.options.ConversationsPerReq,
+			Oldest:    structures.FormatSlackTS(oldest),
+			Latest:    structures.FormatSlackTS(latest),
+			Inclusive: true,
+		})
+		return err
+	}); err != nil {
+		return SizeEstimate{}, network.Classify(channelID, err)
+	}
+	msgs := resp.Messages
+	if len(msgs) == 0 {
+		return SizeEstimate{}, nil
+	}
+	n := int64(len(msgs))
+	top := n
+	if resp.HasMore {
+		// the messages are returned newest first.
+		first, err := structures.ParseSlackTS(msgs[len(msgs)-1].Timestamp)
+		if err != nil {
+			return SizeEstimate{}, err
+		}
+		end := latest
+		if end.IsZero() {
+			end = time.Now()
+		}
+		start := oldest
+		if start.IsZero() {
+			ci, err := sd.getChannelInfo(ctx, l, channelID)
+			if err != nil {
+				return SizeEstimate{}, err
+			}
+			start = time.Unix(int64(ci.Created), 0)
+		}
+		if span := end.Sub(first); span > 0 && first.After(start) {
+			top = int64(float64(n) * float64(end.Sub(start)) / float64(span))
+		}
+	}
+
+	var replies, fileBytes int64
+	for _, m := range msgs {
+		replies += int64(m.ReplyCount)
+		for _, f := range m.Files {
+			fileBytes += int64(f.Size)
+		}
+	}
+	data, err := json.Marshal(msgs)
+	if err != nil {
+		return SizeEstimate{}, err
+	}
+	// the replies are assumed to be the same as the messages of the page.
+	total := top + top*replies/n
+	est := SizeEstimate{
+		Conversations: 1,
+		Sampled:       1,
+		Messages:      total,
+		MessageBytes:  total * int64(len(data)) / n,
+	}
+	if sd.options.DumpFiles {
+		est.FileBytes = total * fileBytes / n
+	}
+	return est, nil
+}
@@ -0,0 +1,73 @@
+package slackdump
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_EstimateSize(t *testing.T) {
+	var (
+		// the complete history of C1: two messages, one with the thread of
+		// two replies, and the file.
+		c1 = []slack.Message{
+			{Msg: slack.Msg{Timestamp: "1700000100.000000", ReplyCount: 2, Files: []slack.File{{ID: "F1", Size: 1000}}}},
+			{Msg: slack.Msg{Timestamp: "1700000000.000000"}},
+		}
+		// the first page of C2, the page spans 1 day of the 10 days of the
+		// conversation lifetime.
+		c2 = []slack.Message{
+			{Msg: slack.Msg{Timestamp: "1700864000.000000"}},
+			{Msg: slack.Msg{Timestamp: "1700777600.000000"}},
+		}
+		latest = time.Unix(1700864000, 0)
+	)
+	mc := newmockClienter(gomock.NewController(t))
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, p *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+			resp := &slack.GetConversationHistoryResponse{SlackResponse: slack.SlackResponse{Ok: true}}
+			switch p.ChannelID {
+			case "C1":
+				resp.Messages = c1
+			case "C2":
+				resp.Messages = c2
+				resp.HasMore = true
+			}
+			return resp, nil
+		}).Times(2)
+	mc.EXPECT().GetConversationInfoContext(gomock.Any(), &slack.GetConversationInfoInput{ChannelID: "C2"}).Return(
+		&slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C2", Created: slack.JSONTime(1700000000)}}}, nil)
+
+	opts := DefOptions
+	opts.DumpFiles = true
+	sd := &Session{client: mc, options: opts}
+	got, err := sd.EstimateSize(context.Background(), []string{"C1", "X", "C2", "Y"}, time.Time{}, latest, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	c1data, _ := json.Marshal(c1)
+	c2data, _ := json.Marshal(c2)
+	want := SizeEstimate{
+		Conversations: 4,
+		Sampled:       2,
+		// C1: 2 messages and 2 replies, C2: 2 messages per day for 10 days.
+		Messages:     2 * (4 + 20),
+		MessageBytes: 2 * (int64(len(c1data))*4/2 + int64(len(c2data))*20/2),
+		FileBytes:    2 * (4 * 1000 / 2),
+	}
+	assert.Equal(t, want, got)
+	assert.Equal(t, want.MessageBytes+want.FileBytes, got.Total())
+}
+
+func TestSession_EstimateSize_empty(t *testing.T) {
+	sd := &Session{options: DefOptions}
+	got, err := sd.EstimateSize(context.Background(), nil, time.Time{}, time.Time{}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, SizeEstimate{}, got)
+}
@@ -0,0 +1,87 @@
+package export
+
+// In this file: the channel_info.json.
+
+import (
+	"sort"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// ChannelInfo is the entry of the channel_info.json.  It is not a part of the
+// Slack export, it keeps the channel metadata, that the standard layout
+// omits: the topic and the purpose with the author and the time they were
+// set, and the history of the membership, topic, purpose and name changes, as
+// recorded by the channel event messages.
+type ChannelInfo struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name,omitempty"`
+	Created    int64          `json:"created"`
+	Creator    string         `json:"creator,omitempty"`
+	IsArchived bool           `json:"is_archived"`
+	Topic      slack.Topic    `json:"topic"`
+	Purpose    slack.Purpose  `json:"purpose"`
+	Members    []string       `json:"members"`
+	History    []ChannelEvent `json:"history,omitempty"` // oldest first.
+}
+
+// ChannelEvent is the channel event, i.e. the user joining the channel or
+// setting the topic.
+type ChannelEvent struct {
+	TS      string `json:"ts"`
+	SubType string `json:"subtype"` // message subtype, i.e. "channel_join"
+	User    string `json:"user,omitempty"`
+	Inviter string `json:"inviter,omitempty"`
+	// Value is the new topic, purpose or name of the channel.
+	Value   string `json:"value,omitempty"`
+	OldName string `json:"old_name,omitempty"`
+}
+
+// channelEvents returns the channel events, found in the messages msgs, sorted
+// by time.
+func channelEvents(msgs []types.Message) []ChannelEvent {
+	var evts []ChannelEvent
+	for _, m := range msgs {
+		ev := ChannelEvent{TS: m.Timestamp, SubType: m.SubType, User: m.User}
+		switch m.SubType {
+		case slack.MsgSubTypeChannelJoin, slack.MsgSubTypeGroupJoin:
+			ev.Inviter = m.Inviter
+		case slack.MsgSubTypeChannelLeave, slack.MsgSubTypeGroupLeave,
+			slack.MsgSubTypeChannelArchive, slack.MsgSubTypeChannelUnarchive,
+			slack.MsgSubTypeGroupArchive, slack.MsgSubTypeGroupUnarchive:
+		case slack.MsgSubTypeChannelTopic, slack.MsgSubTypeGroupTopic:
+			ev.Value = m.Topic
+		case slack.MsgSubTypeChannelPurpose, slack.MsgSubTypeGroupPurpose:
+			ev.Value = m.Purpose
+		case slack.MsgSubTypeChannelName, slack.MsgSubTypeGroupName:
+			ev.Value, ev.OldName = m.Name, m.OldName
+		default:
+			continue
+		}
+		evts = append(evts, ev)
+	}
+	sort.Slice(evts, func(i, j int) bool { return evts[i].TS < evts[j].TS })
+	return evts
+}
+
+// channelInfo returns the channel_info.json entries of the channels chans.
+// history contains the channel events, keyed by channel ID.
+func channelInfo(chans []slack.Channel, history map[string][]ChannelEvent) []ChannelInfo {
+	info := make([]ChannelInfo, 0, len(chans))
+	for _, ch := range chans {
+		info = append(info, ChannelInfo{
+			ID:         ch.ID,
+			Name:       ch.Name,
+			Created:    int64(ch.Created),
+			Creator:    ch.Creator,
+			IsArchived: ch.IsArchived,
+			Topic:      ch.Topic,
+			Purpose:    ch.Purpose,
+			Members:    ch.Members,
+			History:    history[ch.ID],
+		})
+	}
+	return info
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func Test_channelEvents(t *testing.T) {
+	msg := func(ts, subtype string, set func(m *slack.Msg)) types.Message {
+		var m types.Message
+		m.Timestamp = ts
+		m.SubType = subtype
+		m.User = "U01"
+		if set != nil {
+			set(&m.Msg)
+		}
+		return m
+	}
+	msgs := []types.Message{
+		msg("5.0", slack.MsgSubTypeChannelName, func(m *slack.Msg) { m.Name, m.OldName = "new", "old" }),
+		msg("4.0", "", nil),
+		msg("3.0", slack.MsgSubTypeChannelTopic, func(m *slack.Msg) { m.Topic = "the topic" }),
+		msg("2.0", slack.MsgSubTypeChannelLeave, nil),
+		msg("1.0", slack.MsgSubTypeChannelJoin, func(m *slack.Msg) { m.Inviter = "U02" }),
+		msg("0.5", slack.MsgSubTypeBotMessage, nil),
+	}
+	want := []ChannelEvent{
+		{TS: "1.0", SubType: slack.MsgSubTypeChannelJoin, User: "U01", Inviter: "U02"},
+		{TS: "2.0", SubType: slack.MsgSubTypeChannelLeave, User: "U01"},
+		{TS: "3.0", SubType: slack.MsgSubTypeChannelTopic, User: "U01", Value: "the topic"},
+		{TS: "5.0", SubType: slack.MsgSubTypeChannelName, User: "U01", Value: "new", OldName: "old"},
+	}
+	assert.Equal(t, want, channelEvents(msgs))
+	assert.Empty(t, channelEvents(nil))
+}
+
+func Test_channelInfo(t *testing.T) {
+	var ch slack.Channel
+	ch.ID = "C01"
+	ch.Name = "general"
+	ch.Created = 1672531200
+	ch.Creator = "U01"
+	ch.IsArchived = true
+	ch.Topic = slack.Topic{Value: "topic", Creator: "U02", LastSet: 1672531300}
+	ch.Members = []string{"U01", "U02"}
+	history := map[string][]ChannelEvent{"C01": {{TS: "1.0", SubType: slack.MsgSubTypeChannelArchive, User: "U01"}}}
+
+	got := channelInfo([]slack.Channel{ch}, history)
+	assert.Equal(t, []ChannelInfo{{
+		ID:         "C01",
+		Name:       "general",
+		Created:    1672531200,
+		Creator:    "U01",
+		IsArchived: true,
+		Topic:      ch.Topic,
+		Members:    []string{"U01", "U02"},
+		History:    history["C01"],
+	}}, got)
+}
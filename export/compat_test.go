@@ -0,0 +1,180 @@
+package export_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/sanitize"
+	"github.com/rusq/slackdump/v2/slacktest"
+)
+
+// dayFile is the name of the per-day message file.
+var dayFile = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\.json$`)
+
+// viewerMessage is the part of the message, that slack-export-viewer uses.
+type viewerMessage struct {
+	Type    string        `json:"type"`
+	User    string        `json:"user"`
+	TS      string        `json:"ts"`
+	Replies []slack.Reply `json:"replies"`
+}
+
+// readViewer reads the export in dir the way slack-export-viewer does (see
+// reader.py): the users, the four conversation indexes, the directories of
+// the conversations, found by the name or, for the direct messages, by the ID,
+// the per-day files and the thread replies.  It returns the number of
+// messages of each conversation directory.
+func readViewer(dir string) (map[string]int, error) {
+	read := func(name string, v any) error {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, v)
+	}
+	var users []slack.User
+	if err := read("users.json", &users); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, errors.New("no users")
+	}
+	var dirs []string
+	for _, name := range []string{"channels.json", "groups.json", "mpims.json"} {
+		var chans []struct {
+			Name    string   `json:"name"`
+			Members []string `json:"members"`
+		}
+		if err := read(name, &chans); err != nil {
+			return nil, err
+		}
+		for _, ch := range chans {
+			if ch.Name == "" {
+				return nil, fmt.Errorf("%s: empty name", name)
+			}
+			if name == "mpims.json" && len(ch.Members) == 0 {
+				return nil, fmt.Errorf("%s: %s has no members", name, ch.Name)
+			}
+			dirs = append(dirs, ch.Name)
+		}
+	}
+	var dms []export.DM
+	if err := read("dms.json", &dms); err != nil {
+		return nil, err
+	}
+	for _, dm := range dms {
+		if len(dm.Members) == 0 {
+			return nil, fmt.Errorf("dms.json: %s has no members", dm.ID)
+		}
+		dirs = append(dirs, dm.ID)
+	}
+
+	counts := make(map[string]int)
+	for _, d := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, d, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		var msgs []viewerMessage
+		for _, f := range files {
+			if !dayFile.MatchString(filepath.Base(f)) {
+				return nil, fmt.Errorf("%s: not a day file", f)
+			}
+			var day []viewerMessage
+			if err := read(filepath.Join(d, filepath.Base(f)), &day); err != nil {
+				return nil, fmt.Errorf("%s: %w", f, err)
+			}
+			msgs = append(msgs, day...)
+		}
+		byUserTS := make(map[slack.Reply]bool, len(msgs))
+		for _, m := range msgs {
+			if m.Type != "message" || m.TS == "" {
+				return nil, fmt.Errorf("%s: invalid message: %+v", d, m)
+			}
+			byUserTS[slack.Reply{User: m.User, Timestamp: m.TS}] = true
+		}
+		for _, m := range msgs {
+			for _, r := range m.Replies {
+				if !byUserTS[r] {
+					return nil, fmt.Errorf("%s: reply %s of %s not found", d, r.Timestamp, m.TS)
+				}
+			}
+		}
+		counts[d] = len(msgs)
+	}
+	// each conversation directory should be in the index, otherwise the
+	// viewer does not show it.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if _, ok := counts[e.Name()]; e.IsDir() && !ok {
+			return nil, fmt.Errorf("directory %s is not in the index", e.Name())
+		}
+	}
+	return counts, nil
+}
+
+func TestExport_viewerCompat(t *testing.T) {
+	newClient := func() *slacktest.Client {
+		cl := slacktest.New()
+		// "con" is the reserved device name on Windows, it is renamed by the
+		// windows-safe profile.
+		var con slack.Channel
+		con.ID, con.Name, con.NameNormalized, con.IsChannel, con.Created = "C0CON", "con", "con", true, 1672531200
+		var mpim slack.Channel
+		mpim.ID, mpim.Name, mpim.NameNormalized, mpim.IsMpIM, mpim.IsGroup, mpim.Created = "G0MPIM", "mpdm-alice--bob-1", "mpdm-alice--bob-1", true, true, 1672531200
+		cl.Channels = append(cl.Channels, con, mpim)
+		cl.Messages["C0CON"] = []slack.Message{{Msg: slack.Msg{Type: "message", User: slacktest.UserBob, Timestamp: "1672531200.000200", Text: "console"}}}
+		cl.Messages["G0MPIM"] = []slack.Message{{Msg: slack.Msg{Type: "message", User: slacktest.UserAlice, Timestamp: "1672531200.000300", Text: "group chat"}}}
+		cl.Members["C0CON"] = []string{slacktest.UserBob}
+		cl.Members["G0MPIM"] = []string{slacktest.UserAlice, slacktest.UserBob}
+		return cl
+	}
+	run := func(t *testing.T, compat bool) string {
+		sd, err := slackdump.NewWithClient(context.Background(), newClient(), slackdump.CacheDir(t.TempDir()), slackdump.WithLogger(logger.Silent))
+		require.NoError(t, err)
+		dir := t.TempDir()
+		_, err = export.Create(context.Background(), sd, dir, export.Options{
+			List:            &structures.EntityList{},
+			Type:            export.TStandard,
+			Logger:          logger.Silent,
+			FilenameProfile: sanitize.WindowsSafe,
+			ViewerCompat:    compat,
+		})
+		require.NoError(t, err)
+		return dir
+	}
+
+	t.Run("compatible", func(t *testing.T) {
+		counts, err := readViewer(run(t, true))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{
+			"general":            5, // 3 messages and 2 thread replies.
+			"random":             1,
+			"secret":             1,
+			"_con":               1,
+			"mpdm-alice--bob-1":  1,
+			slacktest.DMAliceBob: 1,
+		}, counts)
+	})
+	t.Run("renamed directory is not found without compat", func(t *testing.T) {
+		_, err := readViewer(run(t, false))
+		assert.ErrorContains(t, err, "_con")
+	})
+}
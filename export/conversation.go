@@ -14,11 +14,12 @@ import (
 const dateFmt = "2006-01-02"
 
 // byDate sorts the messages by date and returns a map date->[]ExportMessage.
-// userIdx should contain the users in the conversation for populating the
-// required fields.  Threads are flattened.
-func (Export) byDate(c *types.Conversation, userIdx structures.UserIndex) (messagesByDate, error) {
+// The date is the day, the month or the constant, depending on the Layout of
+// the export, see Layout.key.  userIdx should contain the users in the
+// conversation for populating the required fields.  Threads are flattened.
+func (se *Export) byDate(c *types.Conversation, userIdx structures.UserIndex) (messagesByDate, error) {
 	msgsByDate := make(map[string][]*ExportMessage, 0)
-	if err := flattenMsgs(msgsByDate, c.Messages, userIdx); err != nil {
+	if err := flattenMsgs(msgsByDate, c.Messages, userIdx, se.opts.Layout); err != nil {
 		return nil, err
 	}
 
@@ -45,20 +46,20 @@ func (mbd messagesByDate) validate() error {
 	return nil
 }
 
-// flattenMsgs takes the messages input, splits them by the date and
-// populates the msgsByDate map.
-func flattenMsgs(msgsByDate messagesByDate, messages []types.Message, usrIdx structures.UserIndex) error {
+// flattenMsgs takes the messages input, splits them by the date, according to
+// the layout l, and populates the msgsByDate map.
+func flattenMsgs(msgsByDate messagesByDate, messages []types.Message, usrIdx structures.UserIndex, l Layout) error {
 	for i := range messages {
 		expMsg := newExportMessage(&messages[i], usrIdx)
 
 		if len(messages[i].ThreadReplies) > 0 {
 			// Recursive call:  are you ready, mr. stack?
-			if err := flattenMsgs(msgsByDate, messages[i].ThreadReplies, usrIdx); err != nil {
+			if err := flattenMsgs(msgsByDate, messages[i].ThreadReplies, usrIdx, l); err != nil {
 				return fmt.Errorf("thread ID %s: %w", messages[i].Timestamp, err)
 			}
 		}
 
-		formattedDt := expMsg.slackdumpTime.Format(dateFmt)
+		formattedDt := l.key(expMsg.slackdumpTime)
 		msgsByDate[formattedDt] = append(msgsByDate[formattedDt], expMsg)
 	}
 
@@ -37,6 +37,34 @@ func TestConversation_ByDate(t *testing.T) {
 	assert.Equal(t, want, convDt)
 }
 
+func TestConversation_ByDate_layout(t *testing.T) {
+	conversations := fixtures.Load[types.Conversation](fixtures.TestConversationJSON)
+	users := fixtures.Load[types.Users](fixtures.UsersJSON)
+
+	daily, err := (&Export{}).byDate(&conversations, users.IndexByID())
+	require.NoError(t, err)
+	total := 0
+	for _, msgs := range daily {
+		total += len(msgs)
+	}
+
+	monthly, err := (&Export{opts: Options{Layout: LMonthly}}).byDate(&conversations, users.IndexByID())
+	require.NoError(t, err)
+	n := 0
+	for k, msgs := range monthly {
+		_, err := time.Parse("2006-01", k)
+		assert.NoError(t, err, k)
+		n += len(msgs)
+	}
+	assert.Equal(t, total, n)
+	assert.LessOrEqual(t, len(monthly), len(daily))
+
+	single, err := (&Export{opts: Options{Layout: LChannel}}).byDate(&conversations, users.IndexByID())
+	require.NoError(t, err)
+	require.Len(t, single, 1)
+	assert.Len(t, single[channelFile], total)
+}
+
 func zeroSlackdumpTime(m messagesByDate) {
 	for _, msgs := range m {
 		for i := range msgs {
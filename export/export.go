@@ -8,16 +8,24 @@ import (
 	"io"
 	"path/filepath"
 	"runtime/trace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/slack-go/slack"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/internal/metrics"
 	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/otrace"
 	"github.com/rusq/slackdump/v2/internal/structures"
 	"github.com/rusq/slackdump/v2/internal/structures/files/dl"
 	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/progress"
 	"github.com/rusq/slackdump/v2/types"
 )
 
@@ -28,6 +36,23 @@ type Export struct {
 	lg logger.Interface
 	dl dl.Exporter
 
+	// failed holds the conversations that failed with transient errors, they
+	// are retried at the end of the run.
+	failed network.RetryQueue
+	// res is the result of the export.
+	res *slackdump.Result
+	// userGroups are the user groups of the workspace, saved into the
+	// usergroups.json, so that the @-group mentions can be resolved.
+	userGroups []slack.UserGroup
+	// history is the channel events, keyed by channel ID, collected from
+	// the exported messages for the channel_info.json.
+	history   map[string][]ChannelEvent
+	historyMu sync.Mutex
+	// resolved are the users, that are missing from the user list, but are
+	// referenced in the exported messages, see resolveUsers.
+	resolved types.Users
+	usersMu  sync.Mutex
+
 	// options
 	opts Options
 }
@@ -39,21 +64,57 @@ func New(sd *slackdump.Session, fs fsadapter.FS, cfg Options) *Export {
 		cfg.Logger = logger.Default
 	}
 	network.SetLogger(cfg.Logger)
+	if cfg.Type != TNoDownload && !sd.CanReadFiles() {
+		cfg.Logger.Printf("warning: the token has no files:read scope, only the metadata of the files will be exported")
+		cfg.Type = TNoDownload
+	}
 
+	res := slackdump.NewResult()
 	se := &Export{
 		fs:   fs,
 		sd:   sd,
 		lg:   cfg.Logger,
 		opts: cfg,
-		dl:   newFileExporter(cfg.Type, fs, sd.Client(), cfg.Logger, cfg.ExportToken),
+		dl: newFileExporter(cfg.Type, fs, sd.API(), cfg.Logger, cfg.ExportToken,
+			downloader.Progress(res.Reporter(progress.NewLog(cfg.Logger))),
+			downloader.Sanitize(cfg.FilenameProfile),
+			downloader.Schedule(cfg.DownloadWindow),
+			downloader.Skip(sd.SkipList())),
+		res: res,
 	}
 	return se
 }
 
+// Create exports the workspace into the target directory or ZIP file, it is
+// the shortcut for New and Run, for programs that embed slackdump.  The export
+// is not a method of slackdump.Session, because the export package depends on
+// it.  The Result is returned even if the export fails.
+func Create(ctx context.Context, sd *slackdump.Session, target string, opts Options) (*slackdump.Result, error) {
+	fs, err := fsadapter.New(target)
+	if err != nil {
+		return nil, err
+	}
+	se := New(sd, fs, opts)
+	if err := se.Run(ctx); err != nil {
+		fs.Close()
+		return se.Result(), err
+	}
+	return se.Result(), fs.Close()
+}
+
+// Result returns the result of the export, it is complete after Run returns.
+func (se *Export) Result() *slackdump.Result {
+	if se.res == nil {
+		se.res = slackdump.NewResult()
+	}
+	return se.res
+}
+
 // Run runs the export.
 func (se *Export) Run(ctx context.Context) error {
 	ctx, task := trace.NewTask(ctx, "export.Run")
 	defer task.End()
+	defer se.Result().Finish()
 
 	// export users to users.json
 	users, err := se.sd.GetUsers(ctx)
@@ -61,6 +122,19 @@ func (se *Export) Run(ctx context.Context) error {
 		se.td(ctx, "error", "GetUsers: %s", err)
 		return err
 	}
+	if se.opts.Events != nil {
+		se.opts.Events.Users(users)
+	}
+
+	// export user groups to usergroups.json, it's not a part of the Slack
+	// export, so the error is not fatal.  If the run is interrupted, it is
+	// handled by the conversation export, so that the pending conversations
+	// are recorded.
+	se.userGroups, err = se.sd.GetUserGroups(ctx)
+	if err != nil {
+		se.td(ctx, "warn", "GetUserGroups: %s", err)
+		se.l().Printf("warning: user groups are not exported: %s", err)
+	}
 
 	// export channels to channels.json
 	if err := se.messages(ctx, users); err != nil {
@@ -88,19 +162,67 @@ func (se *Export) messages(ctx context.Context, users types.Users) error {
 
 	chans, err := se.exportChannels(ctx, users.IndexByID())
 	if err != nil {
-		return fmt.Errorf("export error: %w", err)
+		if !slackdump.IsInterrupted(ctx, err) {
+			return fmt.Errorf("export error: %w", err)
+		}
+		// save the index of the complete conversations, so that the partial
+		// export is usable.
+		se.l().Printf("export interrupted: %s, %d channel(s) exported", err, len(chans))
+		if len(chans) > 0 {
+			if ierr := se.writeIndex(chans, users); ierr != nil {
+				return ierr
+			}
+		}
+		return fmt.Errorf("export interrupted: %w", err)
+	}
+
+	if err := se.retryFailed(ctx, users.IndexByID(), chans); err != nil {
+		return err
+	}
+	if se.opts.Membership {
+		if err := se.saveMembership(ctx, chans); err != nil {
+			return err
+		}
+	}
+
+	ids := make([]string, len(chans))
+	for i := range chans {
+		ids[i] = chans[i].ID
+	}
+	if err := se.sd.SaveAdmin(ctx, se.fs, ids); err != nil {
+		return err
+	}
+	if err := se.sd.SaveIntegrations(ctx, se.fs); err != nil {
+		return err
 	}
 
+	return se.writeIndex(chans, users)
+}
+
+// writeIndex writes the index files and the manifest of the export.
+func (se *Export) writeIndex(chans []slack.Channel, users types.Users) error {
+	users = se.withResolved(users)
+	if se.opts.ViewerCompat {
+		chans = se.viewerNames(chans)
+	}
 	idx, err := createIndex(chans, users, se.sd.CurrentUserID())
 	if err != nil {
 		return fmt.Errorf("failed to create an index: %w", err)
 	}
+	if !se.opts.ViewerCompat {
+		// slack-export-viewer reads just the users.json.
+		idx.Users, idx.TeamUsers = splitUsers(users, se.sd.CurrentUserID())
+	}
+	idx.UserGroups = se.userGroups
+	se.historyMu.Lock()
+	idx.ChannelInfo = channelInfo(chans, se.history)
+	se.historyMu.Unlock()
 
 	if err := idx.Marshal(se.fs); err != nil {
 		return err
 	}
 
-	return nil
+	return slackdump.WriteManifest(se.fs, se.sd.Manifest())
 }
 
 func (se *Export) exportChannels(ctx context.Context, uidx structures.UserIndex) ([]slack.Channel, error) {
@@ -127,41 +249,21 @@ func (se *Export) exclusiveExport(ctx context.Context, uidx structures.UserIndex
 		if include, ok := listIdx[ch.ID]; ok && !include {
 			trace.Logf(ctx, "info", "skipping %s", ch.ID)
 			se.lg.Printf("skipping: %s", ch.ID)
+			se.Result().Add(slackdump.ChannelResult{ID: ch.ID, Name: ch.Name, Skipped: skipExcluded})
 			return nil
 		}
 
-		var eg errgroup.Group
-
-		// 1. get members
-		var members []string
-		eg.Go(func() error {
-			var err error
-			members, err = se.sd.GetChannelMembers(ctx, ch.ID)
-			if err != nil {
-				return fmt.Errorf("error getting info for %s: %w", ch.ID, err)
-			}
-			return nil
-		})
-
-		// 2. export conversation
-		eg.Go(func() error {
-			if err := se.exportConversation(ctx, uidx, ch); err != nil {
-				return fmt.Errorf("error exporting conversation %s: %w", ch.ID, err)
+		if err := se.exportChannel(ctx, uidx, &ch); err != nil {
+			if slackdump.IsInterrupted(ctx, err) {
+				se.Result().Interrupt(err, ch.ID)
 			}
-			return nil
-		})
-
-		// wait for both to finish
-		if err := eg.Wait(); err != nil {
 			return err
 		}
-
-		ch.Members = members
 		chans = append(chans, ch)
 		return nil
 
 	}); err != nil {
-		return nil, fmt.Errorf("channels: error: %w", err)
+		return chans, fmt.Errorf("channels: error: %w", err)
 	}
 	se.l().Printf("  out of which exported:  %d", len(chans))
 	return chans, nil
@@ -184,80 +286,244 @@ func (se *Export) inclusiveExport(ctx context.Context, uidx structures.UserIndex
 	elIdx := list.Index()
 
 	// we need the current user to be able to build an index of DMs.
-	for _, entry := range list.Include {
+	for i, entry := range list.Include {
+		if err := ctx.Err(); err != nil {
+			se.Result().Interrupt(err, list.Include[i:]...)
+			return chans, err
+		}
 		if include, ok := elIdx[entry]; ok && !include {
 			se.td(ctx, "info", "skipping %s", entry)
 			se.lg.Printf("skipping: %s", entry)
+			se.Result().Add(slackdump.ChannelResult{ID: entry, Skipped: skipExcluded})
 			continue
 		}
 		sl, err := structures.ParseLink(entry)
 		if err != nil {
 			return nil, err
 		}
-		ch, err := se.sd.Client().GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: sl.Channel, IncludeLocale: true, IncludeNumMembers: true})
+		ch, err := se.sd.API().GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: sl.Channel, IncludeLocale: true, IncludeNumMembers: true})
 		if err != nil {
+			if slackdump.IsInterrupted(ctx, err) {
+				se.Result().Interrupt(err, list.Include[i:]...)
+				return chans, err
+			}
 			return nil, fmt.Errorf("error getting info for %s: %w", sl, err)
 		}
 
-		var eg errgroup.Group
-
-		var members []string
-		eg.Go(func() error {
-			var err error
-			members, err = se.sd.GetChannelMembers(ctx, ch.ID)
-			if err != nil {
-				return fmt.Errorf("error getting members for %s: %w", sl, err)
+		if err := se.exportChannel(ctx, uidx, ch); err != nil {
+			if slackdump.IsInterrupted(ctx, err) {
+				se.Result().Interrupt(err, list.Include[i:]...)
+				return chans, err
 			}
-			return nil
-		})
-
-		eg.Go(func() error {
-			if err := se.exportConversation(ctx, uidx, *ch); err != nil {
-				return fmt.Errorf("error exporting convesation %s: %w", ch.ID, err)
-			}
-			return nil
-		})
-
-		if err := eg.Wait(); err != nil {
 			return nil, err
 		}
-
-		ch.Members = members
-
 		chans = append(chans, *ch)
 	}
 
 	return chans, nil
 }
 
-// exportConversation exports one conversation.
-func (se *Export) exportConversation(ctx context.Context, userIdx structures.UserIndex, ch slack.Channel) error {
+// exportChannel fetches the members of the channel ch into ch.Members, and
+// exports the conversation.  The members are fetched concurrently with the
+// conversation, unless the events are synthesized, as it requires the members.
+func (se *Export) exportChannel(ctx context.Context, uidx structures.UserIndex, ch *slack.Channel) error {
+	var members []string
+	getMembers := func() error {
+		var err error
+		members, err = se.sd.GetChannelMembers(ctx, ch.ID)
+		if err != nil {
+			return fmt.Errorf("error getting members for %s: %w", ch.ID, err)
+		}
+		return nil
+	}
+	export := func(ch slack.Channel) error {
+		if err := se.exportConversation(ctx, uidx, ch); err != nil {
+			if errors.Is(err, slackdump.ErrSkipListed) {
+				se.l().Printf("skipping: %s", err)
+				se.Result().Add(slackdump.ChannelResult{ID: ch.ID, Name: ch.Name, Skipped: err.Error()})
+				return nil
+			}
+			if se.queueFailed(ch.ID, err) {
+				return nil
+			}
+			if !slackdump.IsInterrupted(ctx, err) {
+				se.Result().Add(slackdump.ChannelResult{ID: ch.ID, Name: ch.Name, Err: err})
+			}
+			return fmt.Errorf("error exporting conversation %s: %w", ch.ID, err)
+		}
+		return nil
+	}
+
+	if se.opts.SynthEvents {
+		if err := getMembers(); err != nil {
+			return err
+		}
+		ch.Members = members
+		return export(*ch)
+	}
+	var eg errgroup.Group
+	eg.Go(getMembers)
+	c := *ch
+	eg.Go(func() error { return export(c) })
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	ch.Members = members
+	return nil
+}
+
+// exportConversation exports one conversation.  If the events are
+// synthesized, ch.Members must be set.
+func (se *Export) exportConversation(ctx context.Context, userIdx structures.UserIndex, ch slack.Channel) (err error) {
 	ctx, task := trace.NewTask(ctx, "export.conversation")
 	defer task.End()
+	ctx, span := otrace.Start(ctx, "export.conversation", otrace.String("channel.id", ch.ID))
+	defer func() { span.End(err) }()
 
-	messages, err := se.sd.DumpRaw(ctx, ch.ID, se.opts.Oldest, se.opts.Latest, se.dl.ProcessFunc(validName(ch)))
+	cr := slackdump.ChannelResult{ID: ch.ID, Name: ch.Name}
+	start := time.Now()
+	name := se.dirName(ch)
+	messages, err := se.sd.DumpRaw(ctx, ch.ID, se.opts.Oldest, se.opts.Latest, se.dl.ProcessFunc(name))
 	if err != nil {
 		return fmt.Errorf("failed to dump %q (%s): %w", ch.Name, ch.ID, err)
 	}
+	if err := se.resolveUsers(ctx, userIdx, messages); err != nil {
+		return err
+	}
+	if err := se.saveConversation(ctx, userIdx, ch, name, messages); err != nil {
+		return err
+	}
+	metrics.MessagesArchived.Add(len(messages.Messages))
+	cr.CountMessages(messages.Messages)
+	cr.Duration = time.Since(start)
+	se.Result().Add(cr)
+
+	return nil
+}
+
+// saveConversation converts the messages of the conversation cnv of the
+// channel ch to the export format, and saves them into the directory name.
+// If the events are synthesized, ch.Members must be set.
+func (se *Export) saveConversation(ctx context.Context, userIdx structures.UserIndex, ch slack.Channel, name string, cnv *types.Conversation) error {
+	// the channel history and the event stream get the original messages.
+	original := cnv.Messages
+	evts := channelEvents(original)
+	messages := *cnv
+	if se.opts.SynthEvents {
+		messages.Messages = append(synthEvents(ch, original), original...)
+	}
 	if len(messages.Messages) == 0 {
 		// empty result set
 		return nil
 	}
 
-	msgs, err := se.byDate(messages, userIdx)
+	_, cspan := otrace.Start(ctx, "export.convert", otrace.Int("messages", len(messages.Messages)))
+	msgs, err := se.byDate(&messages, userIdx)
+	cspan.End(err)
 	if err != nil {
 		return fmt.Errorf("exportConversation: error: %w", err)
 	}
 
-	name := validName(ch)
-
 	if err := se.saveChannel(name, msgs); err != nil {
 		return err
 	}
-
+	if se.opts.Events != nil {
+		se.opts.Events.Messages(ch.ID, original)
+	}
+	if len(evts) > 0 {
+		se.historyMu.Lock()
+		if se.history == nil {
+			se.history = make(map[string][]ChannelEvent)
+		}
+		se.history[ch.ID] = evts
+		se.historyMu.Unlock()
+	}
 	return nil
 }
 
+// queueFailed places the channel into the retry queue, if the err is
+// transient and retries are enabled.  It returns true if the channel was
+// queued.
+func (se *Export) queueFailed(channelID string, err error) bool {
+	if se.opts.FailedRetries <= 0 || !se.failed.Add(channelID, err) {
+		return false
+	}
+	se.l().Printf("failed to export %s (will retry at the end of the run): %s", channelID, err)
+	return true
+}
+
+// retryFailed retries the export of the conversations that failed with the
+// transient errors during the main pass.  chans should contain all the
+// channels of the export.  It returns an error if any of the conversations
+// could not be exported after all the attempts.
+func (se *Export) retryFailed(ctx context.Context, uidx structures.UserIndex, chans []slack.Channel) error {
+	if se.failed.Len() == 0 {
+		return nil
+	}
+	byID := make(map[string]slack.Channel, len(chans))
+	for _, ch := range chans {
+		byID[ch.ID] = ch
+	}
+	se.l().Printf("retrying %d failed conversation(s)", se.failed.Len())
+	failed := se.failed.Run(ctx, se.opts.FailedRetries, se.opts.FailedRetryDelay, func(id string) error {
+		ch, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("internal error: unknown channel %s", id)
+		}
+		return se.exportConversation(ctx, uidx, ch)
+	})
+	if len(failed) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(failed))
+	for id, err := range failed {
+		se.l().Printf("giving up on %s: %s", id, err)
+		se.Result().Add(slackdump.ChannelResult{ID: id, Name: byID[id].Name, Err: err})
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return &FailedError{IDs: ids}
+}
+
+// FailedError is returned by Run, if some of the conversations could not be
+// exported after all the retry attempts.
+type FailedError struct {
+	IDs []string // sorted conversation IDs
+}
+
+func (e *FailedError) Error() string {
+	return fmt.Sprintf("failed to export %d conversation(s), to try again, run the export with: %s", len(e.IDs), strings.Join(e.IDs, " "))
+}
+
+// skipExcluded is the skip reason of the conversations excluded by the list.
+const skipExcluded = "excluded"
+
+// dirName returns the name of the directory of the channel ch.  The name is
+// sanitized with the filename profile, if it changes, the export viewers,
+// that look up the directory by the name in the index, won't find it, unless
+// the index is made viewer compatible.
+func (se *Export) dirName(ch slack.Channel) string {
+	name := validName(ch)
+	dir := se.opts.FilenameProfile.Name(name)
+	if dir != name && !se.opts.ViewerCompat {
+		se.l().Printf("warning: the directory of %s is %q, export viewers won't find it, use the viewer compatible mode", name, dir)
+	}
+	return dir
+}
+
+// viewerNames returns the channels chans with the names replaced by the names
+// of their directories, so that the export viewers find them.
+func (se *Export) viewerNames(chans []slack.Channel) []slack.Channel {
+	renamed := make([]slack.Channel, len(chans))
+	for i, ch := range chans {
+		if !ch.IsIM {
+			ch.Name = se.dirName(ch)
+		}
+		renamed[i] = ch
+	}
+	return renamed
+}
+
 // validName returns the channel or user name. Following the naming convention
 // described by @niklasdahlheimer in this post (thanks to @Neznakomec for
 // discovering it):
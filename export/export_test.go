@@ -7,6 +7,7 @@ import (
 	"errors"
 	"io"
 	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -392,6 +393,7 @@ func TestExport_exportConversation(t *testing.T) {
 			var testUserIdx structures.UserIndex
 			if tt.mocks.rets.dumpRawErr == nil {
 				testUserIdx = types.Users(tt.args.users).IndexByID()
+				dumper.EXPECT().GetUsersInfo(gomock.Any(), gomock.Any()).Return(nil, nil)
 				msgmap, _ := exp.byDate(&tt.mocks.conv, testUserIdx)
 				fs.EXPECT().
 					Create(gomock.Any()).MinTimes(1).MaxTimes(len(msgmap)).
@@ -411,3 +413,37 @@ func TestExport_exportConversation(t *testing.T) {
 		})
 	}
 }
+
+func TestExport_retryFailed(t *testing.T) {
+	ch := slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C42"}, Name: "general"}}
+	transient := slack.StatusCodeError{Code: http.StatusServiceUnavailable}
+	t.Run("succeeds on retry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dumper := NewMockdumper(ctrl)
+		dl := mock_dl.NewMockExporter(ctrl)
+		exp := &Export{sd: dumper, dl: dl, fs: fsadapter.NewDirectory(t.TempDir()), opts: Options{FailedRetries: 2, FailedRetryDelay: time.Millisecond}}
+
+		assert.True(t, exp.queueFailed(ch.ID, transient))
+		dl.EXPECT().ProcessFunc(gomock.Any()).Return(nil)
+		dumper.EXPECT().DumpRaw(gomock.Any(), ch.ID, gomock.Any(), gomock.Any(), gomock.Any()).Return(&types.Conversation{ID: ch.ID}, nil)
+
+		assert.NoError(t, exp.retryFailed(context.Background(), nil, []slack.Channel{ch}))
+	})
+	t.Run("gives up", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dumper := NewMockdumper(ctrl)
+		dl := mock_dl.NewMockExporter(ctrl)
+		exp := &Export{sd: dumper, dl: dl, fs: fsadapter.NewDirectory(t.TempDir()), opts: Options{FailedRetries: 2, FailedRetryDelay: time.Millisecond}}
+
+		assert.True(t, exp.queueFailed(ch.ID, transient))
+		dl.EXPECT().ProcessFunc(gomock.Any()).Return(nil).Times(2)
+		dumper.EXPECT().DumpRaw(gomock.Any(), ch.ID, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, transient).Times(2)
+
+		err := exp.retryFailed(context.Background(), nil, []slack.Channel{ch})
+		assert.ErrorContains(t, err, "C42")
+	})
+	t.Run("retries disabled", func(t *testing.T) {
+		exp := &Export{}
+		assert.False(t, exp.queueFailed(ch.ID, transient))
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/types"
 	"github.com/slack-go/slack"
 )
@@ -20,6 +21,10 @@ type dumper interface {
 	// GetUsers gets the list of all users from the Slack API.
 	GetUsers(ctx context.Context) (types.Users, error)
 
+	// GetUsersInfo gets the users with the ids from the Slack API, the
+	// unknown users are skipped.
+	GetUsersInfo(ctx context.Context, ids ...string) (types.Users, error)
+
 	// CurrentUserID gets the ID of the user running the tool.
 	CurrentUserID() string
 
@@ -27,12 +32,29 @@ type dumper interface {
 	// streams them to the provided callback.
 	StreamChannels(ctx context.Context, chanTypes []string, cb func(ch slack.Channel) error) error
 
-	// Client gets the Slack client being used.
-	Client() *slack.Client
+	// API gets the Slack client being used.
+	API() slackdump.Slacker
 
 	// DumpRaw gets data from the Slack API and returns a Conversation object.
 	DumpRaw(ctx context.Context, link string, oldest time.Time, latest time.Time, processFn ...slackdump.ProcessFunc) (*types.Conversation, error)
 
 	// GetChannelMembers gets the list of members for a channel.
 	GetChannelMembers(ctx context.Context, channelID string) ([]string, error)
+
+	// GetUserGroups gets the list of all user groups from the Slack API.
+	GetUserGroups(ctx context.Context) ([]slack.UserGroup, error)
+
+	// Manifest returns the manifest of the archive.
+	Manifest() slackdump.Manifest
+
+	// SaveAdmin saves the admin settings of the conversations, if enabled.
+	SaveAdmin(ctx context.Context, fs fsadapter.FS, links []string) error
+
+	// SaveIntegrations saves the inventory of the apps, the bots and the
+	// integrations, if enabled.
+	SaveIntegrations(ctx context.Context, fs fsadapter.FS) error
+
+	// StreamAuditLogs streams the audit log entries of the Enterprise Grid
+	// organisation, that match the filter.
+	StreamAuditLogs(ctx context.Context, f slackdump.AuditFilter, fn func([]types.AuditEntry) error) error
 }
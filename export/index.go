@@ -21,6 +21,20 @@ type index struct {
 	MPIMs    []slack.Channel `filename:"mpims.json,omitempty"`
 	DMs      []DM            `filename:"dms.json,omitempty"`
 	Users    []slack.User    `filename:"users.json"`
+	// UserGroups is not a part of the Slack export, see Export.userGroups.
+	UserGroups []slack.UserGroup `filename:"usergroups.json,omitempty"`
+	// ChannelInfo is not a part of the Slack export, see ChannelInfo.
+	ChannelInfo []ChannelInfo `filename:"channel_info.json,omitempty"`
+	// TeamUsers are the users of the other teams of the Enterprise Grid
+	// organisation, by the team ID, see splitUsers.  They are written to
+	// the files, named by teamUsersFile.
+	TeamUsers map[string]types.Users `filename:"-"`
+}
+
+// teamUsersFile returns the name of the file with the users of the team
+// teamID.
+func teamUsersFile(teamID string) string {
+	return "users-" + teamID + ".json"
 }
 
 // DM respresents a direct Message entry in dms.json.
@@ -113,5 +127,35 @@ func (idx *index) Marshal(fs fsadapter.FS) error {
 			return err
 		}
 	}
+	for team, users := range idx.TeamUsers {
+		if err := serializeToFS(fs, teamUsersFile(team), users); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// splitUsers splits the users of the Enterprise Grid organisation by team.
+// It returns the users of the team of the current user, and the users
+// without the team, that go to users.json, and the users of the other teams
+// by the team ID.  If all users are of the same team, teams is nil.
+func splitUsers(users types.Users, currentUserID string) (home types.Users, teams map[string]types.Users) {
+	teams = users.ByTeam()
+	if len(teams) < 2 {
+		return users, nil
+	}
+	var homeID string
+	for _, u := range users {
+		if u.ID == currentUserID {
+			homeID = u.TeamID
+			break
+		}
+	}
+	home = teams[homeID]
+	if homeID != "" {
+		home = append(home, teams[""]...)
+	}
+	delete(teams, homeID)
+	delete(teams, "")
+	return home, teams
+}
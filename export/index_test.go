@@ -3,8 +3,11 @@ package export
 import (
 	"testing"
 
-	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/types"
 )
 
 func TestIndex_Marshal(t *testing.T) {
@@ -39,3 +42,19 @@ func TestIndex_Marshal(t *testing.T) {
 		})
 	}
 }
+
+func Test_splitUsers(t *testing.T) {
+	users := types.Users{
+		{ID: "W01", TeamID: "T01"},
+		{ID: "W02", TeamID: "T02"},
+		{ID: "USLACKBOT"},
+		{ID: "W03", TeamID: "T01"},
+	}
+	home, teams := splitUsers(users, "W02")
+	assert.Equal(t, types.Users{users[1], users[2]}, home)
+	assert.Equal(t, map[string]types.Users{"T01": {users[0], users[3]}}, teams)
+
+	home, teams = splitUsers(users[:1], "W01")
+	assert.Equal(t, users[:1], home)
+	assert.Nil(t, teams, "single team is not split")
+}
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Layout is the layout of the message files in the channel directories of
+// the export.
+type Layout uint8
+
+const (
+	// LDaily is one file per day, i.e. "general/2024-01-31.json", as in the
+	// exports made by Slack.
+	LDaily Layout = iota
+	// LMonthly is one file per month, i.e. "general/2024-01.json".
+	LMonthly
+	// LChannel is one file per channel, "general/all.json".
+	LChannel
+)
+
+// channelFile is the name of the message file of the LChannel layout,
+// without the extension.
+const channelFile = "all"
+
+var layoutNames = map[Layout]string{
+	LDaily:   "daily",
+	LMonthly: "monthly",
+	LChannel: "channel",
+}
+
+func (l Layout) String() string {
+	if s, ok := layoutNames[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("Layout(%d)", uint8(l))
+}
+
+// Set sets the layout from its name, it is the flag.Value interface.
+func (l *Layout) Set(s string) error {
+	for v, name := range layoutNames {
+		if strings.EqualFold(s, name) {
+			*l = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown export layout: %q, use one of: daily, monthly or channel", s)
+}
+
+// key returns the name of the message file, without the extension, for the
+// message with the time t.
+func (l Layout) key(t time.Time) string {
+	switch l {
+	case LMonthly:
+		return t.Format("2006-01")
+	case LChannel:
+		return channelFile
+	default:
+		return t.Format(dateFmt)
+	}
+}
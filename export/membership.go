@@ -0,0 +1,156 @@
+package export
+
+// In this file: the channel membership history.
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// MembershipEvent is the entry of the channel membership history, that is
+// saved to the membership file of the channel, see membershipFile.
+type MembershipEvent struct {
+	Time    int64  `json:"time"`         // unix time, seconds.
+	TS      string `json:"ts,omitempty"` // timestamp of the event message.
+	Action  string `json:"action"`       // "join" or "leave".
+	User    string `json:"user"`
+	Inviter string `json:"inviter,omitempty"`
+	// Sources are the sources of the event: "audit" for the audit logs,
+	// "messages" for the channel event messages, or both.
+	Sources []string `json:"sources"`
+}
+
+const (
+	actionJoin  = "join"
+	actionLeave = "leave"
+
+	sourceAudit    = "audit"
+	sourceMessages = "messages"
+)
+
+// auditMembership maps the audit log actions of the membership changes to
+// the MembershipEvent actions.
+var auditMembership = map[string]string{
+	"user_channel_join":  actionJoin,
+	"user_channel_leave": actionLeave,
+}
+
+// mergeWindow is the maximum difference in seconds between the audit log
+// entry and the event message of the same membership change.
+const mergeWindow = 60
+
+// membershipFile returns the name of the membership history file of the
+// channel channelID.
+func membershipFile(channelID string) string {
+	return "membership-" + channelID + ".json"
+}
+
+// saveMembership saves the membership history of the channels chans.  The
+// history is reconstructed from the audit logs, if the token has access to
+// them, and from the join and leave messages of the exported conversations.
+func (se *Export) saveMembership(ctx context.Context, chans []slack.Channel) error {
+	include := make(map[string]bool, len(chans))
+	for _, ch := range chans {
+		include[ch.ID] = true
+	}
+	audit := make(map[string][]MembershipEvent)
+	f := slackdump.AuditFilter{
+		Oldest: se.opts.Oldest,
+		Latest: se.opts.Latest,
+		Action: "user_channel_join,user_channel_leave",
+	}
+	if err := se.sd.StreamAuditLogs(ctx, f, func(entries []types.AuditEntry) error {
+		for _, e := range entries {
+			action, ok := auditMembership[e.Action]
+			if !ok || e.Entity.Channel == nil || !include[e.Entity.Channel.ID] {
+				continue
+			}
+			id := e.Entity.Channel.ID
+			audit[id] = append(audit[id], MembershipEvent{
+				Time:    e.DateCreate,
+				Action:  action,
+				User:    e.Actor.User.ID,
+				Sources: []string{sourceAudit},
+			})
+		}
+		return nil
+	}); err != nil {
+		if slackdump.IsInterrupted(ctx, err) {
+			return err
+		}
+		se.td(ctx, "warn", "StreamAuditLogs: %s", err)
+		se.l().Printf("warning: the audit logs are not available, the membership history is reconstructed from the messages: %s", err)
+	}
+
+	se.historyMu.Lock()
+	defer se.historyMu.Unlock()
+	for _, ch := range chans {
+		evts := membershipHistory(audit[ch.ID], se.history[ch.ID])
+		if len(evts) == 0 {
+			continue
+		}
+		if err := serializeToFS(se.fs, membershipFile(ch.ID), evts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// membershipHistory merges the audit log events and the join and leave
+// events of the channel messages history, oldest first.  The audit log
+// event and the message of the same change are merged into one event.
+func membershipHistory(audit []MembershipEvent, history []ChannelEvent) []MembershipEvent {
+	evts := append([]MembershipEvent(nil), audit...)
+	for _, h := range history {
+		var action string
+		switch h.SubType {
+		case slack.MsgSubTypeChannelJoin, slack.MsgSubTypeGroupJoin:
+			action = actionJoin
+		case slack.MsgSubTypeChannelLeave, slack.MsgSubTypeGroupLeave:
+			action = actionLeave
+		default:
+			continue
+		}
+		t, err := structures.ParseSlackTS(h.TS)
+		if err != nil {
+			continue
+		}
+		if i := findEvent(evts[:len(audit)], action, h.User, t.Unix()); i >= 0 {
+			evts[i].TS, evts[i].Inviter = h.TS, h.Inviter
+			evts[i].Sources = append(evts[i].Sources, sourceMessages)
+			continue
+		}
+		evts = append(evts, MembershipEvent{
+			Time:    t.Unix(),
+			TS:      h.TS,
+			Action:  action,
+			User:    h.User,
+			Inviter: h.Inviter,
+			Sources: []string{sourceMessages},
+		})
+	}
+	sort.SliceStable(evts, func(i, j int) bool { return evts[i].Time < evts[j].Time })
+	return evts
+}
+
+// findEvent returns the index of the audit event in evts, that is not yet
+// merged with a message, and matches the action of the user at the time t,
+// or -1.
+func findEvent(evts []MembershipEvent, action, user string, t int64) int {
+	for i, ev := range evts {
+		if ev.TS != "" || ev.Action != action || !strings.EqualFold(ev.User, user) {
+			continue
+		}
+		if d := ev.Time - t; -mergeWindow <= d && d <= mergeWindow {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,27 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_membershipHistory(t *testing.T) {
+	audit := []MembershipEvent{
+		{Time: 1672531230, Action: actionJoin, User: "U01", Sources: []string{sourceAudit}},
+		{Time: 1672531100, Action: actionLeave, User: "U03", Sources: []string{sourceAudit}},
+	}
+	history := []ChannelEvent{
+		{TS: "1672531200.000100", SubType: slack.MsgSubTypeChannelJoin, User: "U01", Inviter: "U02"},
+		{TS: "1672531300.000100", SubType: slack.MsgSubTypeChannelTopic, User: "U01", Value: "topic"},
+		{TS: "1672617600.000100", SubType: slack.MsgSubTypeGroupLeave, User: "U01"},
+	}
+	want := []MembershipEvent{
+		{Time: 1672531100, Action: actionLeave, User: "U03", Sources: []string{sourceAudit}},
+		{Time: 1672531230, TS: "1672531200.000100", Action: actionJoin, User: "U01", Inviter: "U02", Sources: []string{sourceAudit, sourceMessages}},
+		{Time: 1672617600, TS: "1672617600.000100", Action: actionLeave, User: "U01", Sources: []string{sourceMessages}},
+	}
+	assert.Equal(t, want, membershipHistory(audit, history))
+	assert.Empty(t, membershipHistory(nil, nil))
+}
@@ -22,7 +22,10 @@ type ExportMessage struct {
 	UserProfile     *ExportUserProfile `json:"user_profile"`
 	ReplyUsersCount int                `json:"reply_users_count"`
 	ReplyUsers      []string           `json:"reply_users"`
-	slackdumpTime   time.Time          `json:"-"`
+	// FileComments is not a part of the Slack export, see
+	// types.Message.FileComments.
+	FileComments  map[string][]slack.Comment `json:"slackdump_file_comments,omitempty"`
+	slackdumpTime time.Time                  `json:"-"`
 }
 
 type ExportUserProfile struct {
@@ -55,6 +58,7 @@ func newExportMessage(msg *types.Message, users structures.UserIndex) *ExportMes
 	expMsg := ExportMessage{Msg: &msg.Msg}
 
 	expMsg.UserTeam = msg.Team
+	expMsg.FileComments = msg.FileComments
 	expMsg.SourceTeam = msg.Team
 	expMsg.slackdumpTime, _ = msg.Datetime()
 
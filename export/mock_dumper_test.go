@@ -11,6 +11,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	slackdump "github.com/rusq/slackdump/v2"
+	fsadapter "github.com/rusq/slackdump/v2/fsadapter"
 	types "github.com/rusq/slackdump/v2/types"
 	slack "github.com/slack-go/slack"
 )
@@ -38,18 +39,18 @@ func (m *Mockdumper) EXPECT() *MockdumperMockRecorder {
 	return m.recorder
 }
 
-// Client mocks base method.
-func (m *Mockdumper) Client() *slack.Client {
+// API mocks base method.
+func (m *Mockdumper) API() slackdump.Slacker {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Client")
-	ret0, _ := ret[0].(*slack.Client)
+	ret := m.ctrl.Call(m, "API")
+	ret0, _ := ret[0].(slackdump.Slacker)
 	return ret0
 }
 
-// Client indicates an expected call of Client.
-func (mr *MockdumperMockRecorder) Client() *gomock.Call {
+// API indicates an expected call of API.
+func (mr *MockdumperMockRecorder) API() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Client", reflect.TypeOf((*Mockdumper)(nil).Client))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "API", reflect.TypeOf((*Mockdumper)(nil).API))
 }
 
 // CurrentUserID mocks base method.
@@ -101,6 +102,21 @@ func (mr *MockdumperMockRecorder) GetChannelMembers(ctx, channelID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelMembers", reflect.TypeOf((*Mockdumper)(nil).GetChannelMembers), ctx, channelID)
 }
 
+// GetUserGroups mocks base method.
+func (m *Mockdumper) GetUserGroups(ctx context.Context) ([]slack.UserGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserGroups", ctx)
+	ret0, _ := ret[0].([]slack.UserGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserGroups indicates an expected call of GetUserGroups.
+func (mr *MockdumperMockRecorder) GetUserGroups(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserGroups", reflect.TypeOf((*Mockdumper)(nil).GetUserGroups), ctx)
+}
+
 // GetUsers mocks base method.
 func (m *Mockdumper) GetUsers(ctx context.Context) (types.Users, error) {
 	m.ctrl.T.Helper()
@@ -116,6 +132,82 @@ func (mr *MockdumperMockRecorder) GetUsers(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*Mockdumper)(nil).GetUsers), ctx)
 }
 
+// GetUsersInfo mocks base method.
+func (m *Mockdumper) GetUsersInfo(ctx context.Context, ids ...string) (types.Users, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range ids {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetUsersInfo", varargs...)
+	ret0, _ := ret[0].(types.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersInfo indicates an expected call of GetUsersInfo.
+func (mr *MockdumperMockRecorder) GetUsersInfo(ctx interface{}, ids ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, ids...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersInfo", reflect.TypeOf((*Mockdumper)(nil).GetUsersInfo), varargs...)
+}
+
+// Manifest mocks base method.
+func (m *Mockdumper) Manifest() slackdump.Manifest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Manifest")
+	ret0, _ := ret[0].(slackdump.Manifest)
+	return ret0
+}
+
+// Manifest indicates an expected call of Manifest.
+func (mr *MockdumperMockRecorder) Manifest() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Manifest", reflect.TypeOf((*Mockdumper)(nil).Manifest))
+}
+
+// SaveAdmin mocks base method.
+func (m *Mockdumper) SaveAdmin(ctx context.Context, fs fsadapter.FS, links []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAdmin", ctx, fs, links)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAdmin indicates an expected call of SaveAdmin.
+func (mr *MockdumperMockRecorder) SaveAdmin(ctx, fs, links interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAdmin", reflect.TypeOf((*Mockdumper)(nil).SaveAdmin), ctx, fs, links)
+}
+
+// SaveIntegrations mocks base method.
+func (m *Mockdumper) SaveIntegrations(ctx context.Context, fs fsadapter.FS) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIntegrations", ctx, fs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIntegrations indicates an expected call of SaveIntegrations.
+func (mr *MockdumperMockRecorder) SaveIntegrations(ctx, fs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIntegrations", reflect.TypeOf((*Mockdumper)(nil).SaveIntegrations), ctx, fs)
+}
+
+// StreamAuditLogs mocks base method.
+func (m *Mockdumper) StreamAuditLogs(ctx context.Context, f slackdump.AuditFilter, fn func([]types.AuditEntry) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamAuditLogs", ctx, f, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamAuditLogs indicates an expected call of StreamAuditLogs.
+func (mr *MockdumperMockRecorder) StreamAuditLogs(ctx, f, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamAuditLogs", reflect.TypeOf((*Mockdumper)(nil).StreamAuditLogs), ctx, f, fn)
+}
+
 // StreamChannels mocks base method.
 func (m *Mockdumper) StreamChannels(ctx context.Context, chanTypes []string, cb func(slack.Channel) error) error {
 	m.ctrl.T.Helper()
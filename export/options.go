@@ -3,8 +3,11 @@ package export
 import (
 	"time"
 
+	"github.com/rusq/slackdump/v2/downloader"
 	"github.com/rusq/slackdump/v2/internal/structures"
 	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/sanitize"
+	"github.com/rusq/slackdump/v2/types"
 )
 
 // Options allows to configure slack export options.
@@ -15,6 +18,42 @@ type Options struct {
 	List        *structures.EntityList
 	Type        ExportType
 	ExportToken string
+	// FailedRetries is the number of retry passes for the conversations that
+	// failed with transient errors, 0 disables the retries.
+	FailedRetries int
+	// FailedRetryDelay is the initial delay before the retry pass.
+	FailedRetryDelay time.Duration
+	// FilenameProfile is the sanitization profile of the channel
+	// directory and the attachment file names.
+	FilenameProfile sanitize.Profile
+	// ViewerCompat makes the export compatible with slack-export-viewer: the
+	// names in the index match the names of the channel directories, if
+	// they were changed by the FilenameProfile.
+	ViewerCompat bool
+	// SynthEvents enables the synthesis of the channel_join messages of the
+	// channel members and the channel_archive message of the archived
+	// channels, that are missing from the history, as in the exports made by
+	// Slack.
+	SynthEvents bool
+	// Membership enables the membership history files of the channels,
+	// reconstructed from the audit logs, if the token has access to them,
+	// and from the join and leave messages.
+	Membership bool
+	// DownloadWindow is the daily time window of the file downloads, the
+	// zero window is always open.
+	DownloadWindow downloader.Window
+	// Layout is the layout of the message files in the channel
+	// directories, one file per day by default.
+	Layout Layout
+	// Events, if set, receives the users and the messages of each
+	// conversation as they are exported.
+	Events EventWriter
+}
+
+// EventWriter receives the exported records.
+type EventWriter interface {
+	Users(users types.Users)
+	Messages(channelID string, msgs []types.Message)
 }
 
 func (opt Options) IsFilesEnabled() bool {
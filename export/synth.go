@@ -0,0 +1,131 @@
+package export
+
+// In this file: synthesized channel event messages.
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// synthEvents returns the channel_join messages of the members of the channel
+// ch, that have no join message in msgs, and the channel_archive message, if
+// the channel is archived, and the archive message is missing.  Some import
+// tools expect these messages, that are present in the exports made by
+// Slack.
+//
+// The time of the join is unknown, so the joins are dated at the channel
+// creation, one unit of the timestamp fraction (a microsecond) apart.  The
+// archive message is dated one unit after the latest message, and has no
+// user, as the user who archived the channel is unknown.  The timestamps of
+// the existing messages are skipped, so that the events do not collide with
+// them.  The direct messages have no events.
+func synthEvents(ch slack.Channel, msgs []types.Message) []types.Message {
+	if ch.IsIM || ch.IsMpIM {
+		return nil
+	}
+	var (
+		joined   = make(map[string]bool)
+		used     = make(map[string]bool) // timestamps of the messages
+		archived bool
+		latest   string
+	)
+	for _, m := range msgs {
+		used[m.Timestamp] = true
+		for _, r := range m.ThreadReplies {
+			used[r.Timestamp] = true
+		}
+		switch m.SubType {
+		case slack.MsgSubTypeChannelJoin, slack.MsgSubTypeGroupJoin:
+			joined[m.User] = true
+		case slack.MsgSubTypeChannelArchive, slack.MsgSubTypeGroupArchive:
+			archived = true
+		}
+		if tsLess(latest, m.Timestamp) {
+			latest = m.Timestamp
+		}
+	}
+
+	// free returns the first unused timestamp after ts.
+	free := func(ts string) string {
+		for ts = nextTS(ts); used[ts]; ts = nextTS(ts) {
+		}
+		used[ts] = true
+		return ts
+	}
+
+	members := append([]string(nil), ch.Members...)
+	sort.Strings(members)
+	var (
+		evts   []types.Message
+		cursor = fmt.Sprintf("%d.%06d", int64(ch.Created), 0)
+	)
+	for _, id := range members {
+		if joined[id] {
+			continue
+		}
+		cursor = free(cursor)
+		evts = append(evts, synthMessage(
+			cursor,
+			slack.MsgSubTypeChannelJoin,
+			id,
+			fmt.Sprintf("<@%s> has joined the channel", id),
+		))
+	}
+	if ch.IsArchived && !archived {
+		ts := cursor
+		if latest != "" && tsLess(ts, latest) {
+			ts = latest
+		}
+		ts = free(ts)
+		evts = append(evts, synthMessage(ts, slack.MsgSubTypeChannelArchive, "", "This channel has been archived"))
+	}
+	return evts
+}
+
+// synthMessage returns the channel event message.
+func synthMessage(ts, subtype, user, text string) types.Message {
+	return types.Message{Message: slack.Message{Msg: slack.Msg{
+		Type:      slack.TYPE_MESSAGE,
+		SubType:   subtype,
+		Timestamp: ts,
+		User:      user,
+		Text:      text,
+	}}}
+}
+
+// tsLess returns true if the slack timestamp a is before b.
+func tsLess(a, b string) bool {
+	ta, _ := structures.ParseSlackTS(a)
+	tb, _ := structures.ParseSlackTS(b)
+	return ta.Before(tb)
+}
+
+// nextTS returns the slack timestamp ts advanced by one unit of its fraction,
+// i.e. "1600000000.000100" becomes "1600000000.000101".  The fraction that
+// overflows carries into the seconds.
+func nextTS(ts string) string {
+	sec, frac, _ := strings.Cut(ts, ".")
+	if frac == "" {
+		frac = "000000"
+	}
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		s = 0
+	}
+	n, err := strconv.ParseUint(frac, 10, 64)
+	if err != nil {
+		n = 0
+	}
+	n++
+	if len(strconv.FormatUint(n, 10)) > len(frac) {
+		s, n = s+1, 0
+	}
+	return fmt.Sprintf("%d.%0*d", s, len(frac), n)
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func Test_synthEvents(t *testing.T) {
+	channel := func(archived bool, members ...string) slack.Channel {
+		var ch slack.Channel
+		ch.ID, ch.Created, ch.IsChannel, ch.IsArchived, ch.Members = "C01", 1600000000, true, archived, members
+		return ch
+	}
+	msg := func(ts, subtype, user string) types.Message {
+		var m types.Message
+		m.Timestamp, m.SubType, m.User = ts, subtype, user
+		return m
+	}
+	msgs := []types.Message{
+		msg("1600000200.000100", "", "U01"),
+		msg("1600000100.000000", slack.MsgSubTypeChannelJoin, "U02"),
+	}
+
+	got := synthEvents(channel(true, "U03", "U02", "U01"), msgs)
+	assert.Equal(t, []types.Message{
+		synthMessage("1600000000.000001", slack.MsgSubTypeChannelJoin, "U01", "<@U01> has joined the channel"),
+		synthMessage("1600000000.000002", slack.MsgSubTypeChannelJoin, "U03", "<@U03> has joined the channel"),
+		synthMessage("1600000200.000101", slack.MsgSubTypeChannelArchive, "", "This channel has been archived"),
+	}, got)
+
+	msgs = append(msgs, msg("1600000300.000000", slack.MsgSubTypeChannelArchive, "U01"))
+	assert.Empty(t, synthEvents(channel(true, "U02"), msgs), "nothing is missing")
+
+	msgs = []types.Message{
+		msg("1600000000.000001", "", "U01"),
+		msg("1600000000.000003", "", "U01"),
+		msg("1600000000.999999", "", "U01"),
+	}
+	got = synthEvents(channel(true, "U01", "U02"), msgs)
+	assert.Equal(t, []types.Message{
+		synthMessage("1600000000.000002", slack.MsgSubTypeChannelJoin, "U01", "<@U01> has joined the channel"),
+		synthMessage("1600000000.000004", slack.MsgSubTypeChannelJoin, "U02", "<@U02> has joined the channel"),
+		synthMessage("1600000001.000000", slack.MsgSubTypeChannelArchive, "", "This channel has been archived"),
+	}, got, "collisions with the existing messages")
+
+	var im slack.Channel
+	im.ID, im.IsIM, im.Members = "D01", true, []string{"U01", "U02"}
+	assert.Empty(t, synthEvents(im, nil), "direct messages")
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"context"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// resolveUsers adds the users, that are referenced in the conversation cnv,
+// but are missing from the user index uidx, to it, i.e. the deleted users,
+// that are not listed anymore, or the external users.  They are fetched with
+// users.info, the ones, that can't be fetched, are added as tombstones, see
+// types.Tombstone.  The added users are saved into users.json along with the
+// rest, see writeIndex.  If uidx is empty, the user cache is disabled, and
+// nothing is done.
+func (se *Export) resolveUsers(ctx context.Context, uidx structures.UserIndex, cnv *types.Conversation) error {
+	if len(uidx) == 0 {
+		return nil
+	}
+	var missing []string
+	for _, id := range cnv.UserIDs() {
+		if _, ok := uidx[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	found, err := se.sd.GetUsersInfo(ctx, missing...)
+	if err != nil {
+		if slackdump.IsInterrupted(ctx, err) {
+			return err
+		}
+		se.td(ctx, "warn", "GetUsersInfo: %s", err)
+		se.l().Printf("warning: %s: the missing users are not resolved: %s", cnv.ID, err)
+		return nil
+	}
+	byID := found.IndexByID()
+	added := make(types.Users, 0, len(missing))
+	for _, id := range missing {
+		if u, ok := byID[id]; ok {
+			added = append(added, *u)
+			continue
+		}
+		se.l().Debugf("%s: user %s can't be fetched, adding the tombstone", cnv.ID, id)
+		added = append(added, types.Tombstone(id))
+	}
+	for i := range added {
+		uidx[added[i].ID] = &added[i]
+	}
+	se.usersMu.Lock()
+	se.resolved = append(se.resolved, added...)
+	se.usersMu.Unlock()
+	return nil
+}
+
+// withResolved returns the users with the users, added by resolveUsers.
+func (se *Export) withResolved(users types.Users) types.Users {
+	se.usersMu.Lock()
+	defer se.usersMu.Unlock()
+	if len(se.resolved) == 0 {
+		return users
+	}
+	return append(append(make(types.Users, 0, len(users)+len(se.resolved)), users...), se.resolved...)
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestExport_resolveUsers(t *testing.T) {
+	cnv := &types.Conversation{ID: "C01", Messages: []types.Message{
+		{Message: slack.Message{Msg: slack.Msg{User: "U01", Text: "hi <@U03>"}}},
+		{Message: slack.Message{Msg: slack.Msg{User: "U02"}}},
+	}}
+	users := types.Users{{ID: "U01", Name: "alice"}}
+
+	t.Run("fetches and adds tombstones", func(t *testing.T) {
+		dumper := NewMockdumper(gomock.NewController(t))
+		se := &Export{sd: dumper, lg: logger.Silent}
+		dumper.EXPECT().GetUsersInfo(gomock.Any(), "U02", "U03").Return(types.Users{{ID: "U02", Name: "bob", Deleted: true}}, nil)
+
+		uidx := users.IndexByID()
+		require.NoError(t, se.resolveUsers(context.Background(), uidx, cnv))
+		assert.Equal(t, "bob", uidx["U02"].Name)
+		assert.Equal(t, types.Tombstone("U03"), *uidx["U03"])
+		assert.Equal(t, "(deactivated) U03", uidx.DisplayName("U03"))
+
+		got := se.withResolved(users)
+		assert.Len(t, got, 3)
+		assert.Len(t, users, 1, "the original list is not modified")
+	})
+	t.Run("api error is not fatal", func(t *testing.T) {
+		dumper := NewMockdumper(gomock.NewController(t))
+		se := &Export{sd: dumper, lg: logger.Silent}
+		dumper.EXPECT().GetUsersInfo(gomock.Any(), "U02", "U03").Return(nil, errors.New("missing_scope"))
+
+		uidx := users.IndexByID()
+		require.NoError(t, se.resolveUsers(context.Background(), uidx, cnv))
+		assert.Len(t, uidx, 1, "the users are not marked as deleted on the error")
+	})
+	t.Run("no user cache", func(t *testing.T) {
+		se := &Export{sd: NewMockdumper(gomock.NewController(t)), lg: logger.Silent}
+		uidx := types.Users{}.IndexByID()
+		require.NoError(t, se.resolveUsers(context.Background(), uidx, cnv))
+		assert.Empty(t, uidx)
+	})
+}
@@ -0,0 +1,141 @@
+package export
+
+// In this file: the conversion of the dumped conversations to the export
+// while dumping.
+
+import (
+	"context"
+	"fmt"
+	"runtime/trace"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// transformBufSz is the number of the dumped conversations, that can wait
+// for the conversion, before Add blocks.
+const transformBufSz = 16
+
+// Transformer converts the conversations, dumped by the session, to the
+// Slack Export format while the dump is running, so that the export is
+// complete at the end of the same pass, without fetching the conversations
+// again, or converting the dump afterwards.  The conversations are converted
+// by the background goroutine in the order they are added.
+//
+// The files are not downloaded into the export, the messages keep the
+// references to the files of the dump.  Threads are not converted, as the
+// export consists of the complete channels.
+type Transformer struct {
+	se    *Export
+	users types.Users
+	uidx  structures.UserIndex
+
+	convC chan *types.Conversation
+	done  chan struct{}
+
+	// the fields below are owned by the goroutine until done is closed.
+	chans []slack.Channel
+	seen  map[string]bool
+	err   error
+}
+
+// NewTransformer starts the Transformer, that writes the export of the
+// conversations, and the index of the users to fs.  The Type of opts is
+// ignored, the List, Oldest and Latest are the concern of the dump.
+func NewTransformer(ctx context.Context, sd *slackdump.Session, fs fsadapter.FS, users types.Users, opts Options) *Transformer {
+	opts.Type = TNoDownload
+	return newTransformer(ctx, New(sd, fs, opts), users)
+}
+
+func newTransformer(ctx context.Context, se *Export, users types.Users) *Transformer {
+	t := &Transformer{
+		se:    se,
+		users: users,
+		uidx:  users.IndexByID(),
+		convC: make(chan *types.Conversation, transformBufSz),
+		done:  make(chan struct{}),
+		seen:  make(map[string]bool),
+	}
+	go t.run(ctx)
+	return t
+}
+
+// Add queues the dumped conversation cnv for the conversion.  cnv must not
+// be modified after the call.
+func (t *Transformer) Add(ctx context.Context, cnv *types.Conversation) error {
+	if cnv.ThreadTS != "" {
+		t.se.l().Printf("transform: %s: threads are not converted to the export", cnv)
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case t.convC <- cnv:
+		return nil
+	}
+}
+
+// Close waits for the queued conversations to be converted, and writes the
+// index files and the manifest of the export.  It returns the first error of
+// the conversion, the conversations after it are not converted.
+func (t *Transformer) Close() error {
+	close(t.convC)
+	<-t.done
+	if t.err != nil {
+		return t.err
+	}
+	defer t.se.Result().Finish()
+	return t.se.writeIndex(t.chans, t.users)
+}
+
+// Result returns the result of the conversion, it is complete after Close
+// returns.
+func (t *Transformer) Result() *slackdump.Result {
+	return t.se.Result()
+}
+
+func (t *Transformer) run(ctx context.Context) {
+	defer close(t.done)
+	for cnv := range t.convC {
+		if t.err != nil {
+			continue
+		}
+		t.err = t.convert(ctx, cnv)
+	}
+}
+
+// convert fetches the channel info and the members of the channel of cnv,
+// that are required for the index, and saves the conversation.
+func (t *Transformer) convert(ctx context.Context, cnv *types.Conversation) error {
+	ctx, task := trace.NewTask(ctx, "export.transform")
+	defer task.End()
+
+	start := time.Now()
+	ch, err := t.se.sd.API().GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: cnv.ID, IncludeLocale: true, IncludeNumMembers: true})
+	if err != nil {
+		return fmt.Errorf("transform: error getting info for %s: %w", cnv.ID, err)
+	}
+	ch.Members, err = t.se.sd.GetChannelMembers(ctx, cnv.ID)
+	if err != nil {
+		return fmt.Errorf("transform: error getting members for %s: %w", cnv.ID, err)
+	}
+	if err := t.se.resolveUsers(ctx, t.uidx, cnv); err != nil {
+		return fmt.Errorf("transform: %w", err)
+	}
+	if err := t.se.saveConversation(ctx, t.uidx, *ch, t.se.dirName(*ch), cnv); err != nil {
+		return fmt.Errorf("transform: %w", err)
+	}
+	cr := slackdump.ChannelResult{ID: ch.ID, Name: ch.Name, Duration: time.Since(start)}
+	cr.CountMessages(cnv.Messages)
+	t.se.Result().Add(cr)
+	if !t.seen[ch.ID] {
+		t.seen[ch.ID] = true
+		t.chans = append(t.chans, *ch)
+	}
+	return nil
+}
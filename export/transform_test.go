@@ -0,0 +1,55 @@
+package export_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/slacktest"
+)
+
+func TestTransformer(t *testing.T) {
+	ctx := context.Background()
+	cl := slacktest.New()
+	sd, err := slackdump.NewWithClient(ctx, cl, slackdump.CacheDir(t.TempDir()), slackdump.WithLogger(logger.Silent))
+	require.NoError(t, err)
+	users, err := sd.GetUsers(ctx)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	tr := export.NewTransformer(ctx, sd, fsadapter.NewDirectory(dir), users, export.Options{Logger: logger.Silent})
+	for _, link := range []string{slacktest.ChannelGeneral, slacktest.ChannelRandom, slacktest.ChannelGeneral + ":" + slacktest.ThreadTS} {
+		cnv, err := sd.DumpAll(ctx, link)
+		require.NoError(t, err)
+		require.NoError(t, tr.Add(ctx, cnv))
+	}
+	// the retried conversation is converted again, but is indexed once.
+	cnv, err := sd.DumpAll(ctx, slacktest.ChannelRandom)
+	require.NoError(t, err)
+	require.NoError(t, tr.Add(ctx, cnv))
+	require.NoError(t, tr.Close())
+
+	counts, err := readViewer(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"general": 5, "random": 1}, counts)
+	assert.Len(t, tr.Result().Channels, 3)
+}
+
+func TestTransformer_error(t *testing.T) {
+	ctx := context.Background()
+	sd, err := slackdump.NewWithClient(ctx, slacktest.New(), slackdump.CacheDir(t.TempDir()), slackdump.WithLogger(logger.Silent))
+	require.NoError(t, err)
+	cnv, err := sd.DumpAll(ctx, slacktest.ChannelRandom)
+	require.NoError(t, err)
+
+	tr := export.NewTransformer(ctx, sd, fsadapter.NewDirectory(t.TempDir()), nil, export.Options{Logger: logger.Silent})
+	cnv.ID = "C0UNKNOWN"
+	require.NoError(t, tr.Add(ctx, cnv))
+	assert.ErrorContains(t, tr.Close(), "C0UNKNOWN")
+}
@@ -0,0 +1,83 @@
+package slackdump
+
+// In this file: the legacy file comments.
+
+import (
+	"context"
+	"runtime/trace"
+
+	"github.com/slack-go/slack"
+	"golang.org/x/time/rate"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// fileCommentsPerReq is the number of the file comments per files.info
+// request, the API maximum is 100.
+const fileCommentsPerReq = 100
+
+// populateFileComments fetches the comments of the files of the messages
+// msgs and their thread replies, that have them, and sets the FileComments of
+// the messages.  If the comments of the file can't be fetched, i.e. the file
+// was deleted, the file is skipped.
+func (sd *Session) populateFileComments(ctx context.Context, msgs []types.Message) error {
+	ctx, task := trace.NewTask(ctx, "populateFileComments")
+	defer task.End()
+
+	l := sd.pooledLimiter(network.Tier4)
+	var populate func(msgs []types.Message) error
+	populate = func(msgs []types.Message) error {
+		for i := range msgs {
+			if err := populate(msgs[i].ThreadReplies); err != nil {
+				return err
+			}
+			for _, f := range msgs[i].Files {
+				if f.CommentsCount == 0 {
+					continue
+				}
+				comments, err := sd.fileComments(ctx, l, f.ID)
+				if err != nil {
+					if IsInterrupted(ctx, err) {
+						return err
+					}
+					sd.l().Printf("warning: failed to get the comments of the file %s: %s", f.ID, network.Classify(f.ID, err))
+					continue
+				}
+				if msgs[i].FileComments == nil {
+					msgs[i].FileComments = make(map[string][]slack.Comment)
+				}
+				msgs[i].FileComments[f.ID] = comments
+			}
+		}
+		return nil
+	}
+	return populate(msgs)
+}
+
+// fileComments returns all comments of the file fileID, oldest first, as
+// returned by the API.
+func (sd *Session) fileComments(ctx context.Context, l *rate.Limiter, fileID string) ([]slack.Comment, error) {
+	var all []slack.Comment
+	for page := 1; ; page++ {
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
+		var (
+			comments []slack.Comment
+			paging   *slack.Paging
+		)
+		if err := network.WithRetry(ctx, l, sd.options.Tier4Retries, func() error {
+			var err error
+			_, comments, paging, err = sd.client.GetFileInfoContext(ctx, fileID, fileCommentsPerReq, page)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		all = append(all, comments...)
+		if paging == nil || page >= paging.Pages || len(comments) == 0 {
+			break
+		}
+	}
+	return all, nil
+}
@@ -0,0 +1,45 @@
+package slackdump
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestSession_populateFileComments(t *testing.T) {
+	withFiles := func(ts string, files ...slack.File) types.Message {
+		var m types.Message
+		m.Timestamp = ts
+		m.Files = files
+		return m
+	}
+	parent := withFiles("100.0")
+	parent.ThreadReplies = []types.Message{withFiles("101.0", slack.File{ID: "F01", CommentsCount: 101})}
+	msgs := []types.Message{
+		withFiles("99.0", slack.File{ID: "F00"}),
+		parent,
+		withFiles("102.0", slack.File{ID: "F02", CommentsCount: 1}),
+	}
+	page1 := make([]slack.Comment, 100)
+	page2 := []slack.Comment{{ID: "Fc101", Comment: "last"}}
+
+	ctrl := gomock.NewController(t)
+	mc := newmockClienter(ctrl)
+	mc.EXPECT().GetFileInfoContext(gomock.Any(), "F01", fileCommentsPerReq, 1).Return(&slack.File{ID: "F01"}, page1, &slack.Paging{Page: 1, Pages: 2}, nil)
+	mc.EXPECT().GetFileInfoContext(gomock.Any(), "F01", fileCommentsPerReq, 2).Return(&slack.File{ID: "F01"}, page2, &slack.Paging{Page: 2, Pages: 2}, nil)
+	mc.EXPECT().GetFileInfoContext(gomock.Any(), "F02", fileCommentsPerReq, 1).Return(nil, nil, nil, slack.SlackErrorResponse{Err: "file_deleted"})
+
+	sd := &Session{client: mc, options: DefOptions}
+	require.NoError(t, sd.populateFileComments(context.Background(), msgs))
+	got := msgs[1].ThreadReplies[0].FileComments["F01"]
+	assert.Len(t, got, 101, "all pages are fetched")
+	assert.Equal(t, "last", got[100].Comment)
+	assert.Nil(t, msgs[0].FileComments, "files without comments are not requested")
+	assert.Nil(t, msgs[2].FileComments, "deleted file is skipped")
+}
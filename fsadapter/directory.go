@@ -35,7 +35,20 @@ func (fs Directory) Create(fpath string) (io.WriteCloser, error) {
 	if err := mkdirAll(nodeDir); err != nil {
 		return nil, err
 	}
-	return os.Create(node)
+	return os.Create(LongPath(node))
+}
+
+// Append opens the file in the directory for appending, the file is created,
+// if it does not exist.
+func (fs Directory) Append(fpath string) (io.WriteCloser, error) {
+	node := filepath.Join(fs.dir, fpath)
+	if err := fs.ensureSubdir(node); err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", node, err)
+	}
+	if err := mkdirAll(filepath.Dir(node)); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(LongPath(node), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
 }
 
 // ErrIllegalDir is returned, if the file path reference is outside of the
@@ -61,6 +74,7 @@ func mkdirAll(name string) error {
 	if name == "" {
 		return errors.New("empty directory")
 	}
+	name = LongPath(name)
 
 	fi, err := os.Stat(name)
 	if err == nil && fi.IsDir() {
@@ -85,7 +99,7 @@ func (fs Directory) WriteFile(name string, data []byte, perm os.FileMode) error
 	if err := mkdirAll(filepath.Dir(node)); err != nil {
 		return err
 	}
-	return os.WriteFile(node, data, perm)
+	return os.WriteFile(LongPath(node), data, perm)
 }
 
 // Close is a noop for Directory.
@@ -233,3 +233,20 @@ func TestDirectory_WriteFile(t *testing.T) {
 		})
 	}
 }
+
+func TestDirectory_Append(t *testing.T) {
+	fs := NewDirectory(t.TempDir())
+	for _, s := range []string{"one\n", "two\n"} {
+		w, err := Append(fs, filepath.Join("sub", "log.txt"))
+		require.NoError(t, err)
+		_, err = w.Write([]byte(s))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+	data, err := os.ReadFile(filepath.Join(fs.dir, "sub", "log.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(data))
+
+	_, err = fs.Append(filepath.Join("..", "log.txt"))
+	assert.ErrorIs(t, err, ErrIllegalDir)
+}
@@ -21,13 +21,33 @@ type FSCloser interface {
 	io.Closer
 }
 
+// Appender is a FS that can append to the existing files.
+type Appender interface {
+	Append(string) (io.WriteCloser, error)
+}
+
+// Append opens the file name of fs for appending, if fs is an Appender,
+// otherwise, i.e. for the ZIP archives, that are written once, the file is
+// created.
+func Append(fs FS, name string) (io.WriteCloser, error) {
+	if a, ok := fs.(Appender); ok {
+		return a.Append(name)
+	}
+	return fs.Create(name)
+}
+
 // New returns appropriate filesystem based on the name of the location.
 // Logic is simple:
 //   - if location has a known extension, the appropriate adapter is returned.
+//   - if location is Stdout ("-"), the ZIP archive is written to the Standard
+//     Output.
 //   - else: it's a directory.
 //
 // Currently supported extensions: ".zip" (case insensitive)
 func New(location string) (FSCloser, error) {
+	if location == Stdout {
+		return NewZipFile(location)
+	}
 	switch strings.ToUpper(filepath.Ext(location)) {
 	case ".ZIP":
 		return NewZipFile(location)
@@ -0,0 +1,28 @@
+package fsadapter
+
+import "strings"
+
+// maxPath is the length of the path, starting from which the extended-length
+// prefix is added on Windows.  It is the MAX_PATH (260) less the space for the
+// 8.3 file name, as the directories are limited to 248 characters.
+const maxPath = 248
+
+const (
+	extPrefix    = `\\?\`
+	extUNCPrefix = `\\?\UNC\`
+	devicePrefix = `\\.\`
+)
+
+// extendedPath returns the extended-length form of the absolute Windows path
+// p, i.e. `\\?\C:\dir\file` or `\\?\UNC\server\share\file` for the UNC paths.
+// The path must be clean, as the extended-length paths are not normalised by
+// Windows.
+func extendedPath(p string) string {
+	switch {
+	case strings.HasPrefix(p, extPrefix), strings.HasPrefix(p, devicePrefix):
+		return p
+	case strings.HasPrefix(p, `\\`):
+		return extUNCPrefix + p[2:]
+	}
+	return extPrefix + p
+}
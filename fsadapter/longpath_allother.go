@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package fsadapter
+
+// LongPath returns the path p unchanged, the paths are only limited on
+// Windows.
+func LongPath(p string) string {
+	return p
+}
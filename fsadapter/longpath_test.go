@@ -0,0 +1,24 @@
+package fsadapter
+
+import "testing"
+
+func Test_extendedPath(t *testing.T) {
+	tests := []struct {
+		name string
+		p    string
+		want string
+	}{
+		{"drive", `C:\Users\me\export\C01\attachments\F01-a.txt`, `\\?\C:\Users\me\export\C01\attachments\F01-a.txt`},
+		{"unc", `\\server\share\export\C01.json`, `\\?\UNC\server\share\export\C01.json`},
+		{"already extended", `\\?\C:\export`, `\\?\C:\export`},
+		{"already extended unc", `\\?\UNC\server\share`, `\\?\UNC\server\share`},
+		{"device", `\\.\pipe\slackdump`, `\\.\pipe\slackdump`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extendedPath(tt.p); got != tt.want {
+				t.Errorf("extendedPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
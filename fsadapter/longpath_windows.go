@@ -0,0 +1,21 @@
+//go:build windows
+// +build windows
+
+package fsadapter
+
+import "path/filepath"
+
+// LongPath returns the path p, that can be passed to the os package functions
+// on Windows, even if it is longer than MAX_PATH:  the long paths are made
+// absolute, and the extended-length prefix is added.  The UNC paths, i.e.
+// //server/share/dir, are normalised to the Windows form.
+func LongPath(p string) string {
+	if p == "" {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil || len(abs) < maxPath {
+		return filepath.Clean(p)
+	}
+	return extendedPath(abs)
+}
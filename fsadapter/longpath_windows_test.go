@@ -0,0 +1,37 @@
+//go:build windows
+// +build windows
+
+package fsadapter
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongPath(t *testing.T) {
+	assert.Equal(t, `C:\export\C01.json`, LongPath(`C:/export/C01.json`))
+	assert.Equal(t, `\\server\share\export`, LongPath(`//server/share/export`))
+	long := `C:\` + strings.Repeat(`a\`, 150) + "file.txt"
+	assert.Equal(t, `\\?\`+long, LongPath(long))
+}
+
+func TestDirectory_longPath(t *testing.T) {
+	dir := t.TempDir()
+	fs := NewDirectory(dir)
+	name := filepath.Join(strings.Repeat("d", 100), strings.Repeat("t", 100), strings.Repeat("f", 100)+".json")
+	f, err := fs.Create(name)
+	require.NoError(t, err)
+	_, err = io.WriteString(f, "{}")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, fs.WriteFile(name+".bak", []byte("{}"), 0644))
+
+	_, err = os.Stat(LongPath(filepath.Join(dir, name)))
+	assert.NoError(t, err)
+}
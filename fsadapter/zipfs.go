@@ -17,15 +17,33 @@ import (
 
 var _ FS = &ZIP{}
 
-// ZIP is a filesystem adapter for zip files.
+// Stdout is the file name, that NewZipFile and New treat as the Standard
+// Output.
+const Stdout = "-"
+
+// ZIP is a filesystem adapter for zip files.  The archive is written
+// sequentially, the file contents are streamed into the archive as they are
+// written, and the output is never seeked, so it can be a pipe.  ZIP64
+// records are written for the files and archives larger than 4 GiB, or with
+// more than 65535 entries.
 type ZIP struct {
 	zw   *zip.Writer
 	mu   sync.Mutex
-	f    *os.File
+	f    *os.File        // output file, nil if the output is a stream.
+	wc   io.WriteCloser  // optional filter between the zip writer and the file.
+	own  bool            // true, if the zip writer was created by ZIP, and should be closed on Close.
 	seen map[string]bool // seen holds the list of seen directories.
 }
 
+// FilterFunc wraps the writer w, i.e. with the encryption or compression
+// stream.  Closing the returned writer must flush all the data to w, but must
+// not close it.
+type FilterFunc func(w io.Writer) (io.WriteCloser, error)
+
 func (z *ZIP) String() string {
+	if z.f == nil {
+		return "<zip archive: stream>"
+	}
 	return fmt.Sprintf("<zip archive: %s>", z.f.Name())
 }
 
@@ -34,14 +52,56 @@ func NewZIP(zw *zip.Writer) *ZIP {
 	return &ZIP{zw: zw, seen: make(map[string]bool)}
 }
 
-// NewZipFile returns a new ZIP filesystem adapter for a given filename.
+// NewZipFile returns a new ZIP filesystem adapter for a given filename.  If
+// the filename is Stdout, the archive is written to the Standard Output.
 func NewZipFile(filename string) (*ZIP, error) {
-	f, err := os.Create(filename)
+	if filename == Stdout {
+		return NewZipStream(os.Stdout, nil)
+	}
+	f, err := os.Create(LongPath(filename))
 	if err != nil {
 		return nil, err
 	}
 	zw := zip.NewWriter(f)
-	return &ZIP{zw: zw, f: f, seen: make(map[string]bool)}, nil
+	return &ZIP{zw: zw, f: f, own: true, seen: make(map[string]bool)}, nil
+}
+
+// NewZipFileFilter returns a new ZIP filesystem adapter for a given filename.
+// The ZIP stream is passed through the filter fn before it's written to the
+// file, i.e. to have the archive encrypted on the fly.  If the filename is
+// Stdout, the archive is written to the Standard Output.
+func NewZipFileFilter(filename string, fn FilterFunc) (*ZIP, error) {
+	if filename == Stdout {
+		return NewZipStream(os.Stdout, fn)
+	}
+	f, err := os.Create(LongPath(filename))
+	if err != nil {
+		return nil, err
+	}
+	wc, err := fn(f)
+	if err != nil {
+		f.Close()
+		os.Remove(LongPath(filename))
+		return nil, err
+	}
+	zw := zip.NewWriter(wc)
+	return &ZIP{zw: zw, f: f, wc: wc, own: true, seen: make(map[string]bool)}, nil
+}
+
+// NewZipStream returns a new ZIP filesystem adapter, that writes the archive
+// to w, i.e. a pipe or a network connection, through the optional filter fn.
+// Close finalises the archive, but does not close w.
+func NewZipStream(w io.Writer, fn FilterFunc) (*ZIP, error) {
+	z := &ZIP{own: true, seen: make(map[string]bool)}
+	if fn != nil {
+		wc, err := fn(w)
+		if err != nil {
+			return nil, err
+		}
+		z.wc, w = wc, wc
+	}
+	z.zw = zip.NewWriter(w)
+	return z, nil
 }
 
 // normalizePath reassembles the path in correct format for ZIP file.
@@ -139,7 +199,8 @@ func (z *ZIP) WriteFile(filename string, data []byte, _ os.FileMode) error {
 }
 
 // Close closes the underlying zip writer and the file handle.  It is only
-// necessary if ZIP was initialised using NewZipFile
+// necessary if ZIP was initialised using NewZipFile, NewZipFileFilter or
+// NewZipStream.
 func (z *ZIP) Close() error {
 	if !z.ourHandles() {
 		// we don't own the handles, so just bail out.
@@ -156,15 +217,23 @@ func (z *ZIP) closeHandles() error {
 	if err := z.zw.Close(); err != nil {
 		return err
 	}
+	if z.wc != nil {
+		if err := z.wc.Close(); err != nil {
+			if z.f != nil {
+				z.f.Close()
+			}
+			return err
+		}
+	}
 	if z.f == nil {
 		return nil
 	}
 	return z.f.Close()
 }
 
-// ourHandles returns true if we own the file handle.
+// ourHandles returns true if we own the zip writer.
 func (z *ZIP) ourHandles() bool {
-	return z.f != nil
+	return z.own
 }
 
 // syncWriter is a wrapper around an io.Writer that ensures that the underlying
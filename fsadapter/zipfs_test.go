@@ -287,3 +287,104 @@ func Test_syncWriter_Close(t *testing.T) {
 		sw.Close()
 	})
 }
+
+// prefixFilter is the test filter, that writes the prefix before the data.
+type prefixFilter struct {
+	w      io.Writer
+	closed bool
+}
+
+func (pf *prefixFilter) Write(p []byte) (int, error) { return pf.w.Write(p) }
+func (pf *prefixFilter) Close() error {
+	pf.closed = true
+	return nil
+}
+
+func TestNewZipFileFilter(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "test.zip.enc")
+	var pf *prefixFilter
+	zf, err := NewZipFileFilter(zipPath, func(w io.Writer) (io.WriteCloser, error) {
+		pf = &prefixFilter{w: w}
+		_, err := w.Write([]byte("HDR"))
+		return pf, err
+	})
+	require.NoError(t, err)
+	require.NoError(t, zf.WriteFile("dir/file.txt", []byte("hello"), 0644))
+	require.NoError(t, zf.Close())
+	assert.True(t, pf.closed, "filter must be closed")
+
+	data, err := os.ReadFile(zipPath)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data, []byte("HDR")))
+	zr, err := zip.NewReader(bytes.NewReader(data[3:]), int64(len(data)-3))
+	require.NoError(t, err)
+	f, err := zr.Open("dir/file.txt")
+	require.NoError(t, err)
+	got, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(got))
+
+	t.Run("filter error", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "x.zip")
+		_, err := NewZipFileFilter(name, func(w io.Writer) (io.WriteCloser, error) {
+			return nil, fmt.Errorf("boom")
+		})
+		require.Error(t, err)
+		assert.NoFileExists(t, name)
+	})
+}
+
+// pipeWriter is the non-seekable writer, like a pipe.
+type pipeWriter struct {
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (pw *pipeWriter) Write(p []byte) (int, error) { return pw.buf.Write(p) }
+func (pw *pipeWriter) Close() error {
+	pw.closed = true
+	return nil
+}
+
+func TestNewZipStream(t *testing.T) {
+	t.Run("zip64 entry count", func(t *testing.T) {
+		const n = 70000 // more than uint16 max, requires ZIP64 end of central directory.
+		var pw pipeWriter
+		zf, err := NewZipStream(&pw, nil)
+		require.NoError(t, err)
+		for i := 0; i < n; i++ {
+			w, err := zf.Create(fmt.Sprintf("f%05d.txt", i))
+			require.NoError(t, err)
+			_, err = io.WriteString(w, "x")
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+		}
+		require.NoError(t, zf.Close())
+		assert.False(t, pw.closed, "the stream must not be closed")
+
+		zr, err := zip.NewReader(bytes.NewReader(pw.buf.Bytes()), int64(pw.buf.Len()))
+		require.NoError(t, err)
+		assert.Len(t, zr.File, n)
+	})
+	t.Run("filter", func(t *testing.T) {
+		var pw pipeWriter
+		var pf *prefixFilter
+		zf, err := NewZipStream(&pw, func(w io.Writer) (io.WriteCloser, error) {
+			pf = &prefixFilter{w: w}
+			return pf, nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, zf.WriteFile("a/b.txt", []byte("hello"), 0644))
+		require.NoError(t, zf.Close())
+		assert.True(t, pf.closed, "filter must be closed")
+		assert.Equal(t, "<zip archive: stream>", zf.String())
+
+		zr, err := zip.NewReader(bytes.NewReader(pw.buf.Bytes()), int64(pw.buf.Len()))
+		require.NoError(t, err)
+		f, err := zr.Open("a/b.txt")
+		require.NoError(t, err)
+		got, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(got))
+	})
+}
@@ -0,0 +1,184 @@
+package slackdump
+
+// In this file: the inventory of the apps, the bots and the integrations.
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"runtime/trace"
+	"strconv"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// IntegrationsFile is the name of the integrations inventory file in the root
+// of the archive.
+const IntegrationsFile = "integrations.json"
+
+// Integrations returns the inventory of the integrations of the workspace:
+// the apps, that are approved or restricted by the admins, with their
+// scopes, the bot users, and the integration log, i.e. the incoming
+// webhooks.  The apps require the admin or owner user token with the
+// admin.apps:read scope, and the integration log, the admin.teams:read or
+// the admin scope.  The parts that the token can't access are skipped, and
+// the reason is recorded in the Skipped field.  The bots are taken from the
+// users of the session.
+func (sd *Session) Integrations(ctx context.Context) (*types.Integrations, error) {
+	ctx, task := trace.NewTask(ctx, "Integrations")
+	defer task.End()
+
+	intg := &types.Integrations{Created: time.Now().UTC()}
+	for _, u := range sd.Users {
+		if !u.IsBot && u.Profile.BotID == "" {
+			continue
+		}
+		intg.Bots = append(intg.Bots, types.Bot{UserID: u.ID, BotID: u.Profile.BotID, AppID: u.Profile.ApiAppID, Name: u.Name, Deleted: u.Deleted})
+	}
+
+	parts := []struct {
+		name string
+		fn   func() error
+	}{
+		{"apps", func() error {
+			for _, status := range []string{"approved", "restricted"} {
+				apps, err := sd.listApps(ctx, status)
+				if err != nil {
+					return err
+				}
+				intg.Apps = append(intg.Apps, apps...)
+			}
+			return nil
+		}},
+		{"logs", func() (err error) {
+			intg.Logs, err = sd.integrationLogs(ctx)
+			return err
+		}},
+	}
+	for _, p := range parts {
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
+		err := p.fn()
+		if err == nil {
+			continue
+		}
+		var ser slack.SlackErrorResponse
+		if !errors.Is(err, errNoAPIClient) && !(errors.As(err, &ser) && adminDenied[ser.Err]) {
+			return nil, err
+		}
+		sd.l().Debugf("integrations: skipping %s: %s", p.name, err)
+		if intg.Skipped == nil {
+			intg.Skipped = make(map[string]string)
+		}
+		intg.Skipped[p.name] = err.Error()
+	}
+	return intg, nil
+}
+
+// listApps returns the apps with the status, "approved" or "restricted".
+func (sd *Session) listApps(ctx context.Context, status string) ([]types.App, error) {
+	type appResp struct {
+		App struct {
+			ID                     string `json:"id"`
+			Name                   string `json:"name"`
+			Description            string `json:"description"`
+			IsInternal             bool   `json:"is_internal"`
+			IsAppDirectoryApproved bool   `json:"is_app_directory_approved"`
+			AppDirectoryURL        string `json:"app_directory_url"`
+		} `json:"app"`
+		Scopes      []types.AppScope `json:"scopes"`
+		DateUpdated int64            `json:"date_updated"`
+	}
+	var (
+		apps   []types.App
+		cursor string
+		l      = sd.limiter(network.Tier2)
+	)
+	for {
+		form := url.Values{"limit": {"100"}}
+		if sd.wspInfo != nil && sd.wspInfo.TeamID != "" {
+			form.Set("team_id", sd.wspInfo.TeamID)
+		}
+		if cursor != "" {
+			form.Set("cursor", cursor)
+		}
+		var resp struct {
+			Approved         []appResp              `json:"approved_apps"`
+			Restricted       []appResp              `json:"restricted_apps"`
+			ResponseMetadata slack.ResponseMetadata `json:"response_metadata"`
+		}
+		if err := network.WithRetry(ctx, l, sd.options.Tier2Retries, func() error {
+			return sd.api.call(ctx, "admin.apps."+status+".list", form, &resp)
+		}); err != nil {
+			return nil, err
+		}
+		for _, a := range append(resp.Approved, resp.Restricted...) {
+			apps = append(apps, types.App{
+				ID:                     a.App.ID,
+				Name:                   a.App.Name,
+				Description:            a.App.Description,
+				IsInternal:             a.App.IsInternal,
+				IsAppDirectoryApproved: a.App.IsAppDirectoryApproved,
+				AppDirectoryURL:        a.App.AppDirectoryURL,
+				Status:                 status,
+				Scopes:                 a.Scopes,
+				DateUpdated:            a.DateUpdated,
+			})
+		}
+		if cursor = resp.ResponseMetadata.Cursor; cursor == "" {
+			break
+		}
+	}
+	return apps, nil
+}
+
+// integrationLogs returns the integration log of the workspace.
+func (sd *Session) integrationLogs(ctx context.Context) ([]types.IntegrationLog, error) {
+	var (
+		logs []types.IntegrationLog
+		l    = sd.limiter(network.Tier2)
+	)
+	for page := 1; ; page++ {
+		var resp struct {
+			Logs   []types.IntegrationLog `json:"logs"`
+			Paging slack.Paging           `json:"paging"`
+		}
+		if err := network.WithRetry(ctx, l, sd.options.Tier2Retries, func() error {
+			return sd.api.call(ctx, "team.integrationLogs", url.Values{"count": {"1000"}, "page": {strconv.Itoa(page)}}, &resp)
+		}); err != nil {
+			return nil, err
+		}
+		logs = append(logs, resp.Logs...)
+		if page >= resp.Paging.Pages {
+			break
+		}
+	}
+	return logs, nil
+}
+
+// SaveIntegrations takes the inventory of the integrations of the workspace,
+// and writes it into the root of the filesystem fs as IntegrationsFile, if
+// the Integrations option is set.  The inventory is auxiliary, so the
+// errors, other than the interruption, are logged and do not stop the run.
+func (sd *Session) SaveIntegrations(ctx context.Context, fs fsadapter.FS) error {
+	if !sd.options.Integrations {
+		return nil
+	}
+	intg, err := sd.Integrations(ctx)
+	if err == nil {
+		err = writeJSON(fs, IntegrationsFile, intg)
+	}
+	if err != nil {
+		if IsInterrupted(ctx, err) {
+			return err
+		}
+		sd.l().Printf("warning: failed to save the integrations: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package slackdump
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestSession_Integrations(t *testing.T) {
+	users := types.Users{
+		{ID: "U01", Name: "alice"},
+		{ID: "U02", Name: "deploybot", IsBot: true, Profile: slack.UserProfile{BotID: "B02", ApiAppID: "A02"}},
+	}
+	t.Run("all parts", func(t *testing.T) {
+		srv, _ := discoveryServer(t, func(method string, r *http.Request) string {
+			switch method {
+			case "admin.apps.approved.list":
+				assert.Equal(t, "T01", r.FormValue("team_id"))
+				if r.FormValue("cursor") == "" {
+					return `{"ok":true,"approved_apps":[{"app":{"id":"A02","name":"Deploy"},"scopes":[{"name":"chat:write","token_type":"bot"}],"date_updated":1600000000}],"response_metadata":{"next_cursor":"next"}}`
+				}
+				return `{"ok":true,"approved_apps":[{"app":{"id":"A03","name":"Polls","is_app_directory_approved":true}}]}`
+			case "admin.apps.restricted.list":
+				return `{"ok":true,"restricted_apps":[{"app":{"id":"A04","name":"Leaky"}}]}`
+			case "team.integrationLogs":
+				return `{"ok":true,"logs":[{"service_id":"S01","service_type":"Incoming WebHooks","user_id":"U01","date":"1600000000","change_type":"added","channel":"general"}],"paging":{"page":1,"pages":1}}`
+			}
+			t.Errorf("unexpected method: %s", method)
+			return `{}`
+		})
+		sd := &Session{
+			api:     &apiClient{cl: srv.Client(), token: "xoxp-test", baseURL: srv.URL + "/"},
+			wspInfo: &slack.AuthTestResponse{TeamID: "T01"},
+			Users:   users,
+			options: DefOptions,
+		}
+		got, err := sd.Integrations(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, got.Skipped)
+		assert.Equal(t, []types.Bot{{UserID: "U02", BotID: "B02", AppID: "A02", Name: "deploybot"}}, got.Bots)
+		require.Len(t, got.Apps, 3)
+		assert.Equal(t, types.App{ID: "A02", Name: "Deploy", Status: "approved", Scopes: []types.AppScope{{Name: "chat:write", TokenType: "bot"}}, DateUpdated: 1600000000}, got.Apps[0])
+		assert.True(t, got.Apps[1].IsAppDirectoryApproved)
+		assert.Equal(t, "restricted", got.Apps[2].Status)
+		require.Len(t, got.Logs, 1)
+		assert.Equal(t, "Incoming WebHooks", got.Logs[0].ServiceType)
+	})
+	t.Run("not an admin", func(t *testing.T) {
+		srv, _ := discoveryServer(t, func(method string, r *http.Request) string {
+			return `{"ok":false,"error":"not_an_admin"}`
+		})
+		sd := &Session{api: &apiClient{cl: srv.Client(), token: "xoxp-test", baseURL: srv.URL + "/"}, Users: users, options: DefOptions}
+		got, err := sd.Integrations(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, got.Bots, 1, "the bots don't require the admin token")
+		assert.Len(t, got.Skipped, 2)
+		assert.Contains(t, got.Skipped["apps"], "not_an_admin")
+	})
+}
+
+func TestSession_SaveIntegrations(t *testing.T) {
+	newSession := func(enabled bool) *Session {
+		opts := DefOptions
+		opts.Integrations = enabled
+		return &Session{options: opts}
+	}
+	t.Run("disabled", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, newSession(false).SaveIntegrations(context.Background(), fsadapter.NewDirectory(dir)))
+		assert.NoFileExists(t, filepath.Join(dir, IntegrationsFile))
+	})
+	t.Run("enabled", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, newSession(true).SaveIntegrations(context.Background(), fsadapter.NewDirectory(dir)))
+		data, err := os.ReadFile(filepath.Join(dir, IntegrationsFile))
+		require.NoError(t, err)
+		var got types.Integrations
+		require.NoError(t, json.Unmarshal(data, &got))
+		assert.Len(t, got.Skipped, 2, "no api client")
+	})
+}
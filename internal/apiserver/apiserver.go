@@ -0,0 +1,320 @@
+// Package apiserver implements the read-only REST API over the archive.
+//
+// Endpoints:
+//
+//	GET /channels                                   - list of channels
+//	GET /channels/{id}                              - channel information
+//	GET /channels/{id}/messages?from=&to=&limit=    - channel messages
+//	GET /search?q=&channel=&user=&from=&to=&limit=  - full text search
+//	GET /users                                      - list of users
+//
+// Time parameters can be specified as the date (2006-01-02), RFC3339
+// timestamp, or Slack timestamp.  All the responses are JSON.
+package apiserver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/fts"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+const (
+	// defSearchLimit is the default number of search results.
+	defSearchLimit = 100
+	// maxSearchLimit is the maximum number of search results.
+	maxSearchLimit = 1000
+)
+
+// Server is the REST API http.Handler.
+type Server struct {
+	ar    *archive.Archive
+	token string
+	idx   *fts.Index
+	lg    logger.Interface
+	mux   *http.ServeMux
+}
+
+// Option is the server option.
+type Option func(*Server)
+
+// WithToken sets the access token.  Clients must send it in the
+// "Authorization: Bearer <token>" header.  If the token is empty, the
+// authentication is disabled.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithIndex sets the full text index for the search endpoint.  If it's not
+// set, search scans all the messages.
+func WithIndex(idx *fts.Index) Option {
+	return func(s *Server) {
+		s.idx = idx
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(lg logger.Interface) Option {
+	return func(s *Server) {
+		if lg != nil {
+			s.lg = lg
+		}
+	}
+}
+
+// New creates the API server for the archive ar.
+func New(ar *archive.Archive, opts ...Option) (*Server, error) {
+	if ar == nil {
+		return nil, errors.New("apiserver: no archive")
+	}
+	s := &Server{
+		ar: ar,
+		lg: logger.Default,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/channels", s.channelsHandler)
+	mux.HandleFunc("/channels/", s.channelHandler)
+	mux.HandleFunc("/search", s.searchHandler)
+	mux.HandleFunc("/users", s.usersHandler)
+	s.mux = mux
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="slackdump"`)
+		writeError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	const prefix = "Bearer "
+	hdr := r.Header.Get("Authorization")
+	if !strings.HasPrefix(hdr, prefix) {
+		return false
+	}
+	token := strings.TrimPrefix(hdr, prefix)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *Server) channelsHandler(w http.ResponseWriter, r *http.Request) {
+	chans, err := s.ar.Channels()
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, chans)
+}
+
+// channelHandler serves /channels/{id} and /channels/{id}/messages
+func (s *Server) channelHandler(w http.ResponseWriter, r *http.Request) {
+	id, sub, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/channels/"), "/"), "/")
+	ch, err := s.ar.Channel(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	switch sub {
+	case "":
+		writeJSON(w, http.StatusOK, ch)
+	case "messages":
+		s.messages(w, r, id)
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// messages writes the messages of the channel, filtered by the time range.
+// Thread replies are included within the parent messages.
+func (s *Server) messages(w http.ResponseWriter, r *http.Request, channelID string) {
+	from, to, err := timeRange(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit, err := intParam(r, "limit", 0, 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cnv, err := s.ar.Conversation(channelID)
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	msgs := make([]types.Message, 0, len(cnv.Messages))
+	for _, m := range cnv.Messages {
+		t, err := structures.ParseSlackTS(m.Timestamp)
+		if err != nil {
+			continue
+		}
+		if (!from.IsZero() && t.Before(from)) || (!to.IsZero() && !t.Before(to)) {
+			continue
+		}
+		msgs = append(msgs, m)
+		if limit > 0 && len(msgs) == limit {
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, msgs)
+}
+
+func (s *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
+	q := fts.Query{
+		Text:      r.URL.Query().Get("q"),
+		ChannelID: r.URL.Query().Get("channel"),
+		UserID:    r.URL.Query().Get("user"),
+	}
+	if strings.TrimSpace(q.Text) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("query parameter q is required"))
+		return
+	}
+	var err error
+	if q.From, q.To, err = timeRange(r); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit, err := intParam(r, "limit", defSearchLimit, maxSearchLimit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var docs []fts.Doc
+	if s.idx != nil {
+		docs = s.idx.Search(q, limit)
+	} else {
+		docs, err = fts.Scan(r.Context(), s.ar, q, limit)
+		if err != nil {
+			s.internalError(w, err)
+			return
+		}
+	}
+	results := make([]searchResult, 0, len(docs))
+	for _, d := range docs {
+		results = append(results, searchResult{
+			ChannelID: d.ChannelID,
+			TS:        d.TS,
+			ThreadTS:  d.ThreadTS,
+			User:      d.User,
+			Text:      d.Text,
+		})
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// searchResult is the search endpoint result.
+type searchResult struct {
+	ChannelID string `json:"channel_id"`
+	TS        string `json:"ts"`
+	ThreadTS  string `json:"thread_ts,omitempty"`
+	User      string `json:"user,omitempty"`
+	Text      string `json:"text"`
+}
+
+func (s *Server) usersHandler(w http.ResponseWriter, r *http.Request) {
+	users, err := s.ar.Users()
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	if users == nil {
+		users = types.Users{}
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *Server) internalError(w http.ResponseWriter, err error) {
+	s.lg.Printf("apiserver: %s", err)
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+// errorResponse is the body of the error response.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	_ = enc.Encode(v)
+}
+
+// timeRange returns the from and to parameters of the request.  If "to" is
+// a date, the whole day is included.
+func timeRange(r *http.Request) (from, to time.Time, err error) {
+	from, _, err = parseTime(r.URL.Query().Get("from"))
+	if err != nil {
+		return from, to, errors.New("invalid from: " + err.Error())
+	}
+	to, isDate, err := parseTime(r.URL.Query().Get("to"))
+	if err != nil {
+		return from, to, errors.New("invalid to: " + err.Error())
+	}
+	if isDate {
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, nil
+}
+
+// parseTime parses the time parameter.  Empty string is the zero time.
+func parseTime(s string) (t time.Time, isDate bool, err error) {
+	if s == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	t, err = structures.ParseSlackTS(s)
+	if err != nil {
+		return time.Time{}, false, errors.New("expected date, RFC3339 or Slack timestamp")
+	}
+	return t, false, nil
+}
+
+// intParam returns the positive integer parameter name, or def, if it's
+// not set.  If max is greater than zero, the value is capped.
+func intParam(r *http.Request, name string, def int, max int) (int, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid " + name)
+	}
+	if max > 0 && n > max {
+		n = max
+	}
+	return n, nil
+}
@@ -0,0 +1,89 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/archive"
+)
+
+var testFS = fstest.MapFS{
+	"channels.json": {Data: []byte(`[{"id":"C01","name":"general"}]`)},
+	"users.json":    {Data: []byte(`[{"id":"U01","name":"alice"}]`)},
+	"general/2023-01-01.json": {Data: []byte(`[
+		{"type":"message","user":"U01","text":"first message","ts":"1672531200.000100","thread_ts":"1672531200.000100","reply_count":1},
+		{"type":"message","user":"U01","text":"a reply","ts":"1672531300.000100","thread_ts":"1672531200.000100"}
+	]`)},
+	"general/2023-01-02.json": {Data: []byte(`[
+		{"type":"message","user":"U01","text":"second message","ts":"1672617600.000100"}
+	]`)},
+}
+
+func testServer(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+	ar, err := archive.New(testFS, "test")
+	require.NoError(t, err)
+	s, err := New(ar, opts...)
+	require.NoError(t, err)
+	return s
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	s := testServer(t, WithToken("secret"))
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+		wantLen    int // length of the returned JSON array, -1 to skip.
+	}{
+		{"no token", http.MethodGet, "/channels", "", http.StatusUnauthorized, -1},
+		{"wrong token", http.MethodGet, "/channels", "wrong", http.StatusUnauthorized, -1},
+		{"post", http.MethodPost, "/channels", "secret", http.StatusMethodNotAllowed, -1},
+		{"channels", http.MethodGet, "/channels", "secret", http.StatusOK, 1},
+		{"channel", http.MethodGet, "/channels/C01", "secret", http.StatusOK, -1},
+		{"unknown channel", http.MethodGet, "/channels/C99/messages", "secret", http.StatusNotFound, -1},
+		{"messages", http.MethodGet, "/channels/C01/messages", "secret", http.StatusOK, 2},
+		{"messages from", http.MethodGet, "/channels/C01/messages?from=2023-01-02", "secret", http.StatusOK, 1},
+		{"messages to date", http.MethodGet, "/channels/C01/messages?to=2023-01-01", "secret", http.StatusOK, 1},
+		{"messages to ts", http.MethodGet, "/channels/C01/messages?to=1672617600.000100", "secret", http.StatusOK, 1},
+		{"messages limit", http.MethodGet, "/channels/C01/messages?limit=1", "secret", http.StatusOK, 1},
+		{"messages bad from", http.MethodGet, "/channels/C01/messages?from=yesterday", "secret", http.StatusBadRequest, -1},
+		{"search", http.MethodGet, "/search?q=message", "secret", http.StatusOK, 2},
+		{"search reply", http.MethodGet, "/search?q=reply&user=U01", "secret", http.StatusOK, 1},
+		{"search no query", http.MethodGet, "/search", "secret", http.StatusBadRequest, -1},
+		{"users", http.MethodGet, "/users", "secret", http.StatusOK, 1},
+		{"not found", http.MethodGet, "/nonexistent", "secret", http.StatusNotFound, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, r)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantLen < 0 {
+				return
+			}
+			var arr []json.RawMessage
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &arr))
+			assert.Len(t, arr, tt.wantLen)
+		})
+	}
+}
+
+func TestServer_noAuth(t *testing.T) {
+	s := testServer(t)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -2,12 +2,25 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"runtime/trace"
 	"time"
 
+	"github.com/rusq/slackdump/v2"
 	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/internal/app/config"
 	"github.com/rusq/slackdump/v2/internal/app/emoji"
+	"github.com/rusq/slackdump/v2/internal/diskspace"
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/otrace"
+	"github.com/rusq/slackdump/v2/internal/output"
+	"github.com/rusq/slackdump/v2/internal/state"
+	"github.com/rusq/slackdump/v2/transport"
 )
 
 // Run starts the Slackdump.
@@ -20,14 +33,130 @@ func Run(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 
 	start := time.Now()
 
-	var err error
+	if err := resolveOutput(&cfg, start); err != nil {
+		return err
+	}
+
+	var st state.Store
+	if cfg.State != "" {
+		var err error
+		if st, err = resumeState(ctx, &cfg); err != nil {
+			return err
+		}
+	}
+
+	ntf, err := cfg.Notify.Notifier(runMode(cfg), cfg.OutputLocation(), cfg.Logger())
+	if err != nil {
+		return err
+	}
+	network.SetRateLimitHook(ntf.RateLimited)
+	defer network.SetRateLimitHook(nil)
+	diskspace.SetLowHook(ntf.DiskLow)
+	defer diskspace.SetLowHook(nil)
+	ntf.Start()
+
+	if cfg.MetricsAddr != "" {
+		mctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go serveMetrics(mctx, cfg.MetricsAddr, cfg.Logger())
+	}
+
+	if cfg.OTLPEndpoint != "" {
+		shutdown, ierr := otrace.Init(cfg.OTLPEndpoint, cfg.Logger())
+		if ierr != nil {
+			return ierr
+		}
+		defer func() {
+			sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if err := shutdown(sctx); err != nil {
+				cfg.Logger().Printf("error sending the traces: %s", err)
+			}
+		}()
+		// the root span of the run, so that all spans are in one trace.
+		var span *otrace.Span
+		ctx, span = otrace.Start(ctx, "slackdump."+runMode(cfg))
+		defer func() { span.End(err) }()
+	}
+
+	usage := slackdump.NewAPIUsage(cfg.MaxAPICalls)
+	cfg.Options.APIUsage = usage
+
+	if cfg.SkipAfter > 0 {
+		sl, err := slackdump.OpenSkipList(filepath.Join(cfg.Options.CacheDir, slackdump.SkipListFile), cfg.SkipAfter)
+		if err != nil {
+			return fmt.Errorf("skip-list: %w", err)
+		}
+		cfg.Options.SkipList = sl
+	}
+
+	if cfg.RecordFile != "" {
+		rec := transport.NewRecorder()
+		// the recorder is the outermost, so that it records what slackdump
+		// sent and received, regardless of the other middleware.
+		cfg.Options.Middleware = append([]transport.Middleware{rec.Middleware()}, cfg.Options.Middleware...)
+		defer func() {
+			if err := rec.SaveFile(cfg.RecordFile, !cfg.RecordPlain); err != nil {
+				cfg.Logger().Printf("error saving the API recording: %s", err)
+				return
+			}
+			cfg.Logger().Printf("API calls recorded to %s", cfg.RecordFile)
+		}()
+	}
+
 	if cfg.ExportName != "" {
 		err = Export(ctx, cfg, prov)
 	} else if cfg.Emoji.Enabled {
 		err = emoji.Download(ctx, cfg, prov)
+	} else if cfg.Personal {
+		err = Personal(ctx, cfg, prov)
+	} else if cfg.Audit.Enabled {
+		err = Audit(ctx, cfg, prov)
+	} else if cfg.Thread {
+		err = DumpThread(ctx, cfg, prov)
+	} else if cfg.Search.Query != "" {
+		err = Search(ctx, cfg, prov)
 	} else {
 		err = Dump(ctx, cfg, prov)
 	}
+	failed := failedIDs(err)
+	var pe *PartialError
+	if errors.As(err, &pe) && !cfg.FailPartial {
+		// the skipped conversations of the dump are not the error of the
+		// run, they are logged and reported to the notifier.
+		cfg.Logger().Printf("%s", err)
+		err = nil
+	}
+	if cfg.ValidateOutput && err == nil {
+		err = validateOutput(ctx, cfg)
+	}
+	if loc := cfg.UploadLocation(); loc != "" && (err == nil || failed != nil) {
+		// partial archives are uploaded as well, so that the saved data is
+		// not lost.
+		if uerr := uploadArchive(ctx, cfg, loc); uerr != nil {
+			if err == nil {
+				err = uerr
+			} else {
+				cfg.Logger().Printf("upload error: %s", uerr)
+			}
+		}
+	}
+	if st != nil && (err == nil || failed != nil) {
+		// the run is complete, the failed conversations are not retried
+		// from the state, the same as with the pending file.
+		if cerr := st.Clear(ctx); cerr != nil {
+			cfg.Logger().Printf("error clearing the run state: %s", cerr)
+		}
+	}
+	if usage.Total() > 0 {
+		rep := usage.Report()
+		cfg.Logger().Print(rep)
+		ntf.SetReport(rep.String())
+	}
+	ntf.Done(err, failed)
+	if cfg.MetricsPush != "" {
+		pushMetrics(cfg.MetricsPush, err, cfg.Logger())
+	}
 	if err != nil {
 		return err
 	}
@@ -35,3 +164,91 @@ func Run(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 	cfg.Logger().Printf("completed, time taken: %s", time.Since(start))
 	return nil
 }
+
+// resolveOutput applies the output strategy of cfg, if the output location
+// already exists, see output.Resolve.  With the Suffix strategy, the output
+// location of cfg is changed, with Resume, the run is resumed from the
+// pending file, unless the state location is set.
+func resolveOutput(cfg *config.Params, now time.Time) error {
+	loc := cfg.OutputLocation()
+	if loc == "" || loc == fsadapter.Stdout {
+		return nil
+	}
+	if exists, err := output.Exists(loc); err != nil || !exists {
+		return err
+	}
+	switch cfg.OnExists {
+	case output.Suffix:
+		// the export name is suffixed, and not the file name, as the latter
+		// has the encryption extension.
+		if cfg.ExportName != "" {
+			cfg.ExportName = output.Suffixed(cfg.ExportName, now)
+		} else {
+			cfg.Output.Base = output.Suffixed(cfg.Output.Base, now)
+		}
+		if exists, err := output.Exists(cfg.OutputLocation()); err != nil || exists {
+			return fmt.Errorf("%w: %s", output.ErrExists, cfg.OutputLocation())
+		}
+		cfg.Logger().Printf("%s exists, writing to %s", loc, cfg.OutputLocation())
+		return nil
+	case output.Resume:
+		if cfg.State == "" {
+			cfg.State = pendingFile
+		}
+	}
+	if _, err := output.Resolve(loc, cfg.OnExists, now); err != nil {
+		return err
+	}
+	switch cfg.OnExists {
+	case output.Overwrite:
+		cfg.Logger().Printf("%s exists, removed", loc)
+	case output.Resume:
+		cfg.Logger().Printf("%s exists, resuming the run from %s", loc, cfg.State)
+	case output.Ask:
+		if fi, err := os.Stat(loc); err == nil && !fi.IsDir() {
+			cfg.Logger().Printf("%s exists, replacing", loc)
+			break
+		}
+		cfg.Logger().Printf("%s exists, merging", loc)
+	default:
+		cfg.Logger().Printf("%s exists, merging", loc)
+	}
+	return nil
+}
+
+// runMode returns the name of the mode for the notifications.
+func runMode(cfg config.Params) string {
+	switch {
+	case cfg.ExportName != "":
+		return "export"
+	case cfg.Emoji.Enabled:
+		return "emoji"
+	case cfg.Personal:
+		return "personal"
+	case cfg.Audit.Enabled:
+		return "audit"
+	case cfg.Thread:
+		return "thread"
+	case cfg.Search.Query != "":
+		return "search"
+	case cfg.ListFlags.FlagsPresent():
+		return "list"
+	}
+	return "dump"
+}
+
+// failedIDs returns the conversations that failed, if err is the partial
+// failure error.
+func failedIDs(err error) []string {
+	var (
+		pe *PartialError
+		fe *export.FailedError
+	)
+	switch {
+	case errors.As(err, &pe):
+		return pe.Failed
+	case errors.As(err, &fe):
+		return fe.IDs
+	}
+	return nil
+}
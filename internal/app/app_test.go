@@ -0,0 +1,61 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/output"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func Test_resolveOutput(t *testing.T) {
+	now := time.Date(2024, 1, 31, 2, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"dump/C01.json": `{}`,
+		"export.zip":    `PK`,
+	})
+	dump := filepath.Join(dir, "dump")
+	newCfg := func(s output.Strategy) config.Params {
+		cfg := config.Params{Output: config.Output{Base: dump}, OnExists: s}
+		cfg.Options.Logger = logger.Silent
+		return cfg
+	}
+
+	cfg := newCfg(output.Suffix)
+	require.NoError(t, resolveOutput(&cfg, now))
+	assert.Equal(t, dump+"-20240131-020000", cfg.Output.Base)
+
+	cfg = newCfg(output.Suffix)
+	cfg.Output.Base, cfg.ExportName, cfg.Encrypt = "", filepath.Join(dir, "export.zip"), "age:age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"
+	writeTree(t, dir, map[string]string{"export.zip.age": `age`})
+	require.NoError(t, resolveOutput(&cfg, now))
+	assert.Equal(t, filepath.Join(dir, "export-20240131-020000.zip"), cfg.ExportName)
+
+	cfg = newCfg(output.Resume)
+	require.NoError(t, resolveOutput(&cfg, now))
+	assert.Equal(t, pendingFile, cfg.State)
+	assert.Equal(t, dump, cfg.Output.Base)
+
+	cfg = newCfg(output.Fail)
+	assert.ErrorIs(t, resolveOutput(&cfg, now), output.ErrExists)
+
+	// without the terminal, i.e. the scheduled "-export backup.zip" runs.
+	cfg = newCfg(output.Ask)
+	cfg.Output.Base, cfg.ExportName = "", filepath.Join(dir, "export.zip")
+	require.NoError(t, resolveOutput(&cfg, now), "existing zip file is replaced")
+	assert.Equal(t, filepath.Join(dir, "export.zip"), cfg.ExportName)
+
+	cfg = newCfg(output.Ask)
+	require.NoError(t, resolveOutput(&cfg, now), "existing directory is merged into")
+	assert.Equal(t, dump, cfg.Output.Base)
+
+	cfg = newCfg(output.Fail)
+	cfg.Output.Base = filepath.Join(dir, "new")
+	assert.NoError(t, resolveOutput(&cfg, now), "no conflict, if the output does not exist")
+}
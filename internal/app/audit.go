@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"runtime/trace"
+	"strconv"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// auditDir is the directory of the audit log files in the output directory
+// or archive.
+const auditDir = "audit"
+
+// Audit saves the audit logs of the Enterprise Grid organisation to the
+// Output.Base directory or archive.
+func Audit(ctx context.Context, cfg config.Params, prov auth.Provider) error {
+	ctx, task := trace.NewTask(ctx, "Audit")
+	defer task.End()
+
+	sess, err := slackdump.NewWithOptions(ctx, prov, cfg.Options)
+	if err != nil {
+		return err
+	}
+	fs, err := fsadapter.New(cfg.Output.Base)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	filter := slackdump.AuditFilter{
+		Oldest: time.Time(cfg.Oldest),
+		Latest: time.Time(cfg.Latest),
+		Action: cfg.Audit.Action,
+		Actor:  cfg.Audit.Actor,
+		Entity: cfg.Audit.Entity,
+	}
+	aw := &auditWriter{fs: fs}
+	err = sess.StreamAuditLogs(ctx, filter, aw.Add)
+	// the complete days are written even if the run is interrupted.
+	if ferr := aw.Flush(); ferr != nil && err == nil {
+		err = ferr
+	}
+	if err != nil {
+		return err
+	}
+	cfg.Logger().Printf("saved %d audit log entries to %s", aw.n, cfg.Output.Base)
+	return slackdump.WriteManifest(fs, sess.Manifest())
+}
+
+// auditWriter writes the audit log entries, one file per day (UTC).  The
+// entries must be added newest first, as the API returns them, each file has
+// the entries of the day sorted oldest first.
+type auditWriter struct {
+	fs      fsadapter.FS
+	day     string             // day of the buffered entries
+	buf     []types.AuditEntry // entries of the day, newest first
+	written map[string]int     // number of files written per day
+	n       int                // total number of entries written
+}
+
+// Add adds the entries, the entries of the previous day are written out, as
+// soon as the entry of the other day is added.
+func (aw *auditWriter) Add(entries []types.AuditEntry) error {
+	for _, e := range entries {
+		day := time.Unix(e.DateCreate, 0).UTC().Format("2006-01-02")
+		if day != aw.day {
+			if err := aw.Flush(); err != nil {
+				return err
+			}
+			aw.day = day
+		}
+		aw.buf = append(aw.buf, e)
+	}
+	return nil
+}
+
+// Flush writes the buffered entries.  If the day was already written, i.e.
+// the entries were not sorted, the file gets the numeric suffix.
+func (aw *auditWriter) Flush() error {
+	if len(aw.buf) == 0 {
+		return nil
+	}
+	if aw.written == nil {
+		aw.written = make(map[string]int)
+	}
+	name := aw.day
+	if n := aw.written[aw.day]; n > 0 {
+		name += "-" + strconv.Itoa(n+1)
+	}
+	entries := make([]types.AuditEntry, len(aw.buf))
+	for i, e := range aw.buf {
+		entries[len(aw.buf)-1-i] = e
+	}
+
+	f, err := aw.fs.Create(path.Join(auditDir, name+".json"))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entries); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	aw.written[aw.day]++
+	aw.n += len(aw.buf)
+	aw.buf = aw.buf[:0]
+	return nil
+}
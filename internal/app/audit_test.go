@@ -0,0 +1,45 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func Test_auditWriter(t *testing.T) {
+	entry := func(id string, date int64) types.AuditEntry {
+		return types.AuditEntry{ID: id, DateCreate: date}
+	}
+	dir := t.TempDir()
+	fs, err := fsadapter.New(dir)
+	require.NoError(t, err)
+
+	aw := &auditWriter{fs: fs}
+	// newest first, as returned by the API, the second day spans two pages.
+	require.NoError(t, aw.Add([]types.AuditEntry{entry("e4", 1672617700), entry("e3", 1672617600)}))
+	require.NoError(t, aw.Add([]types.AuditEntry{entry("e2", 1672531300), entry("e1", 1672531200)}))
+	require.NoError(t, aw.Flush())
+	require.NoError(t, fs.Close())
+	assert.Equal(t, 4, aw.n)
+
+	read := func(name string) []string {
+		data, err := os.ReadFile(filepath.Join(dir, auditDir, name))
+		require.NoError(t, err)
+		var entries []types.AuditEntry
+		require.NoError(t, json.Unmarshal(data, &entries))
+		var ids []string
+		for _, e := range entries {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"e3", "e4"}, read("2023-01-02.json"))
+	assert.Equal(t, []string{"e1", "e2"}, read("2023-01-01.json"))
+}
@@ -30,6 +30,16 @@ var isWSL = os.Getenv("WSL_DISTRO_NAME") != ""
 type SlackCreds struct {
 	Token  string
 	Cookie string
+	// Proxy and NoProxy are the proxy settings of the browser, if the
+	// EZ-Login 3000 is used.
+	Proxy   string
+	NoProxy string
+	// Domain is the domain of the workspace for the EZ-Login 3000, i.e.
+	// "slack-gov.com", if empty, it is "slack.com".
+	Domain string
+	// Insecure disables the TLS certificate verification of the EZ-Login
+	// 3000 browser.
+	Insecure bool
 }
 
 var (
@@ -74,7 +84,7 @@ func (c SlackCreds) AuthProvider(ctx context.Context, workspace string, browser
 	}
 	switch authType {
 	case auth.TypeBrowser:
-		return auth.NewBrowserAuth(ctx, auth.BrowserWithWorkspace(workspace), auth.BrowserWithBrowser(browser))
+		return auth.NewBrowserAuth(ctx, auth.BrowserWithWorkspace(workspace), auth.BrowserWithBrowser(browser), auth.BrowserWithProxy(c.Proxy, c.NoProxy), auth.BrowserWithDomain(c.Domain), auth.BrowserWithInsecure(c.Insecure))
 	case auth.TypeCookieFile:
 		return auth.NewCookieFileAuth(c.Token, c.Cookie)
 	case auth.TypeValue:
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/stream"
+)
+
+// Cat writes the records of the archive src to the output file ("-" for the
+// Stdout) as the NDJSON event stream, the same as the jsonl:// output does,
+// so that the archive can be piped through jq.  If evTypes is not empty,
+// only the events of these types are written, the plural forms, i.e.
+// "messages", are accepted.  If channels is not empty, only the
+// conversations with these IDs are written.
+func Cat(ctx context.Context, src string, output string, evTypes []string, channels []string) error {
+	only := make([]string, 0, len(evTypes))
+	for _, t := range evTypes {
+		t = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(t)), "s")
+		if !stream.IsType(t) {
+			return fmt.Errorf("invalid type: %q, must be one of: messages, files, users, channels", t)
+		}
+		only = append(only, t)
+	}
+
+	ar, err := openArchive(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	f, err := createFile(output)
+	if err != nil {
+		return err
+	}
+	w := stream.NewWriter(f)
+	if len(only) > 0 {
+		w.Only(only...)
+	}
+	if err := catArchive(ctx, ar, w, channels); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// catArchive writes the users and the conversations of the channels of the
+// archive ar to w.
+func catArchive(ctx context.Context, ar *archive.Archive, w *stream.Writer, channels []string) error {
+	if w.Wants(stream.TypeUser) {
+		users, err := ar.Users()
+		if err != nil {
+			return err
+		}
+		w.Users(users)
+	}
+	chans, err := ar.Channels()
+	if err != nil {
+		return err
+	}
+	want := make(map[string]bool, len(channels))
+	for _, id := range channels {
+		want[id] = true
+	}
+	for _, ch := range chans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(want) > 0 && !want[ch.ID] {
+			continue
+		}
+		w.Channel(ch)
+		if !w.Wants(stream.TypeMessage) && !w.Wants(stream.TypeFile) {
+			continue
+		}
+		cnv, err := ar.Conversation(ch.ID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ar.Name(), err)
+		}
+		w.Messages(ch.ID, cnv.Messages)
+	}
+	return w.Err()
+}
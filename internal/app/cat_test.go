@@ -0,0 +1,48 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCat(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"channels.json":           `[{"id": "C01", "name": "general"}, {"id": "C02", "name": "random"}]`,
+		"users.json":              `[{"id": "U01", "name": "alice"}]`,
+		"general/2023-01-01.json": `[{"ts": "1672531200.000100", "text": "hello", "files": [{"id": "F01"}]}]`,
+		"random/2023-01-01.json":  `[{"ts": "1672531200.000200", "text": "random"}]`,
+	})
+	cat := func(t *testing.T, evTypes []string, channels []string) []string {
+		t.Helper()
+		out := filepath.Join(t.TempDir(), "out.jsonl")
+		require.NoError(t, Cat(context.Background(), dir, out, evTypes, channels))
+		f, err := os.Open(out)
+		require.NoError(t, err)
+		defer f.Close()
+		var evs []string
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var ev struct {
+				Type      string `json:"type"`
+				ChannelID string `json:"channel_id"`
+			}
+			require.NoError(t, json.Unmarshal(sc.Bytes(), &ev))
+			evs = append(evs, ev.Type+" "+ev.ChannelID)
+		}
+		return evs
+	}
+
+	assert.Equal(t, []string{"user ", "channel C01", "message C01", "file C01", "channel C02", "message C02"}, cat(t, nil, nil))
+	assert.Equal(t, []string{"message C01", "message C02"}, cat(t, []string{"messages"}, nil))
+	assert.Equal(t, []string{"channel C02", "message C02"}, cat(t, []string{"channel", "message"}, []string{"C02"}))
+
+	assert.Error(t, Cat(context.Background(), dir, filepath.Join(t.TempDir(), "out.jsonl"), []string{"reactions"}, nil))
+}
@@ -0,0 +1,232 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Compact merges the conversation files of the same channel in the dump
+// directory dir into one file per channel, removing the duplicate messages.
+// The duplicates come from the overlapping runs, i.e. the thread, dumped by
+// its link, that is also in the channel file, or the channel dumped again
+// under the different file name template.  The merged conversation is
+// written to the channel file, and the rest of the files are removed.  The
+// conversation, split by -dump-layout, is split again into its parts, and
+// its index is rewritten.  If dryRun is true, the archive is not modified.
+// The exports are not supported, as each run rewrites their daily files, and
+// the ZIP archives should be unpacked first.
+func Compact(ctx context.Context, dir string, dryRun bool, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	if fi, err := os.Stat(dir); err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return fmt.Errorf("%s: not a directory, unpack the ZIP archive first", dir)
+	}
+	ar, err := archive.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+	if ar.Type() != archive.TDump {
+		return fmt.Errorf("%s: compaction is only supported for dumps", dir)
+	}
+	files, err := dumpFiles(ctx, ar)
+	if err != nil {
+		return err
+	}
+	splits, err := splitIndexes(dir)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(files))
+	for id := range files {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var nDups, nRemoved, nMerged int
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cf := files[id]
+		cnv, err := ar.Conversation(id)
+		if err != nil {
+			return err
+		}
+		dups := cf.messages - countMessages(cnv.Messages)
+		// the merged conversation goes to the channel file, or, if the
+		// conversation is split by -dump-layout, to its parts.
+		var (
+			parts = []splitFile{{name: cf.names[0], cnv: cnv}}
+			index *dumpIndex
+		)
+		if si, ok := splits[id]; ok {
+			var layout config.DumpLayout
+			if err := layout.Set(si.Layout); err != nil {
+				return fmt.Errorf("%s: %w", si.name, err)
+			}
+			var idx dumpIndex
+			parts, idx = splitConversation(strings.TrimSuffix(si.name, dumpIndexSuffix), cnv, layout)
+			index = &idx
+		}
+		removed := staleFiles(cf.names, parts)
+		if len(removed) == 0 && len(cf.names) == len(parts) && dups == 0 {
+			continue
+		}
+		nDups += dups
+		nMerged++
+		nRemoved += len(removed)
+		lg.Debugf("%s: merging %d file(s) into %d file(s), %d duplicate message(s)", id, len(cf.names), len(parts), dups)
+		if dryRun {
+			continue
+		}
+		for _, part := range parts {
+			if err := writeIndent(filepath.Join(dir, part.name), part.cnv); err != nil {
+				return err
+			}
+		}
+		if index != nil {
+			if err := writeIndent(filepath.Join(dir, splits[id].name), index); err != nil {
+				return err
+			}
+		}
+		for _, name := range removed {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	summary := fmt.Sprintf("%d duplicate message(s) and %d conversation file(s) of %d channel(s)", nDups, nRemoved, nMerged)
+	if dryRun {
+		lg.Printf("%s would be removed", summary)
+		return nil
+	}
+	lg.Printf("removed %s", summary)
+	if nMerged == 0 {
+		return nil
+	}
+	if _, err := os.Stat(ManifestName(dir)); err == nil {
+		lg.Printf("warning: the archive has changed, the signature %s is no longer valid, sign the archive again", ManifestName(dir)+signatureExt)
+	}
+	return slackdump.AppendCustody(fsadapter.NewDirectory(dir), slackdump.NewCustodyRecord(slackdump.CustodyCompacted, summary+" removed"))
+}
+
+// staleFiles returns the names, that are not the files of the merged parts.
+func staleFiles(names []string, parts []splitFile) []string {
+	var stale []string
+	for _, name := range names {
+		found := false
+		for _, p := range parts {
+			if p.name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			stale = append(stale, name)
+		}
+	}
+	return stale
+}
+
+// writeIndent writes v to the file filename as the indented JSON.
+func writeIndent(filename string, v any) error {
+	data, err := marshalIndent(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0666)
+}
+
+// convFiles are the conversation files of the channel in the dump.
+type convFiles struct {
+	// names of the files, the channel file, that will hold the merged
+	// conversation, goes first.
+	names    []string
+	messages int // total number of messages in the files
+}
+
+// dumpFiles returns the conversation files of the dump archive ar by the
+// channel ID.
+func dumpFiles(ctx context.Context, ar *archive.Archive) (map[string]*convFiles, error) {
+	var (
+		files   = make(map[string]*convFiles)
+		threads = make(map[string][]string) // thread files by the channel ID
+	)
+	if err := walkDumpFiles(ctx, ar, func(name string, cnv *types.Conversation) error {
+		cf, ok := files[cnv.ID]
+		if !ok {
+			cf = &convFiles{}
+			files[cnv.ID] = cf
+		}
+		cf.messages += countMessages(cnv.Messages)
+		if cnv.ThreadTS != "" {
+			threads[cnv.ID] = append(threads[cnv.ID], name)
+			return nil
+		}
+		cf.names = append(cf.names, name)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("there are no conversations in the dump")
+	}
+	// thread files go after the channel files, if the channel was dumped
+	// only by the thread links, the merged conversation goes to the first
+	// thread file.
+	for id, names := range threads {
+		files[id].names = append(files[id].names, names...)
+	}
+	return files, nil
+}
+
+// walkDumpFiles calls fn for each conversation file of the dump archive ar,
+// in the order of the channel ID and the file name.  The files, that are
+// not the conversations, i.e. users.json or admin.json, are skipped by the
+// archive index.
+func walkDumpFiles(ctx context.Context, ar *archive.Archive, fn func(name string, cnv *types.Conversation) error) error {
+	idx := ar.DumpFiles()
+	ids := make([]string, 0, len(idx))
+	for id := range idx {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		for _, name := range idx[id] {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			data, err := fs.ReadFile(ar.FS(), name)
+			if err != nil {
+				return err
+			}
+			var cnv types.Conversation
+			if err := json.Unmarshal(data, &cnv); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if err := fn(name, &cnv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
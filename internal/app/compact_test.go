@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestCompact(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"users.json": `[]`,
+		// the channel with the thread, that was also dumped by its link.
+		"C01.json": `{"name":"general","channel_id":"C01","messages":[
+			{"ts":"1672531200.000100","text":"one"},
+			{"ts":"1672531300.000100","text":"parent","thread_ts":"1672531300.000100","reply_count":2,"slackdump_thread_replies":[
+				{"ts":"1672531400.000100","text":"reply","thread_ts":"1672531300.000100"}
+			]}
+		]}`,
+		"C01-1672531300.000100.json": `{"name":"general","channel_id":"C01","thread_ts":"1672531300.000100","messages":[
+			{"ts":"1672531300.000100","text":"parent","thread_ts":"1672531300.000100","reply_count":2},
+			{"ts":"1672531400.000100","text":"reply","thread_ts":"1672531300.000100"},
+			{"ts":"1672531500.000100","text":"late reply","thread_ts":"1672531300.000100"}
+		]}`,
+		// the channel without duplicates is left as is.
+		"C02.json": `{"name":"random","channel_id":"C02","messages":[{"ts":"1672531200.000100","text":"random"}]}`,
+	})
+	before, err := os.ReadFile(filepath.Join(dir, "C02.json"))
+	require.NoError(t, err)
+
+	t.Run("dry run", func(t *testing.T) {
+		require.NoError(t, Compact(context.Background(), dir, true, logger.Silent))
+		assert.FileExists(t, filepath.Join(dir, "C01-1672531300.000100.json"))
+		assert.NoFileExists(t, filepath.Join(dir, slackdump.CustodyFile))
+	})
+	require.NoError(t, Compact(context.Background(), dir, false, logger.Silent))
+
+	assert.NoFileExists(t, filepath.Join(dir, "C01-1672531300.000100.json"))
+	data, err := os.ReadFile(filepath.Join(dir, "C01.json"))
+	require.NoError(t, err)
+	var cnv types.Conversation
+	require.NoError(t, json.Unmarshal(data, &cnv))
+	assert.Equal(t, "C01", cnv.ID)
+	require.Len(t, cnv.Messages, 2)
+	assert.Len(t, cnv.Messages[1].ThreadReplies, 2, "the thread from the thread file has more replies")
+
+	after, err := os.ReadFile(filepath.Join(dir, "C02.json"))
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	recs := readCustodyLog(t, dir)
+	require.Len(t, recs, 1)
+	assert.Equal(t, slackdump.CustodyCompacted, recs[0].Operation)
+	assert.Contains(t, recs[0].Details, "2 duplicate message(s) and 1 conversation file(s) of 1 channel(s)")
+
+	t.Run("export", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{"channels.json": `[]`})
+		assert.Error(t, Compact(context.Background(), dir, false, logger.Silent))
+	})
+}
+
+func TestCompact_split(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		// the monthly parts, and the thread, that was also dumped by its link.
+		"C01-2023-01.json": `{"name":"general","channel_id":"C01","messages":[
+			{"ts":"1672531200.000100","text":"parent","thread_ts":"1672531200.000100","reply_count":2,"slackdump_thread_replies":[
+				{"ts":"1672531300.000100","text":"reply","thread_ts":"1672531200.000100"}
+			]}
+		]}`,
+		"C01-2023-02.json": `{"name":"general","channel_id":"C01","messages":[{"ts":"1675209600.000100","text":"february"}]}`,
+		"C01-index.json": `{"name":"general","conversation_id":"C01","layout":"monthly","parts":[
+			{"period":"2023-01","file":"C01-2023-01.json","messages":2,"oldest":"1672531200.000100","latest":"1672531200.000100"},
+			{"period":"2023-02","file":"C01-2023-02.json","messages":1,"oldest":"1675209600.000100","latest":"1675209600.000100"}
+		]}`,
+		"C01-1672531200.000100.json": `{"name":"general","channel_id":"C01","thread_ts":"1672531200.000100","messages":[
+			{"ts":"1672531200.000100","text":"parent","thread_ts":"1672531200.000100","reply_count":2},
+			{"ts":"1672531300.000100","text":"reply","thread_ts":"1672531200.000100"},
+			{"ts":"1672531400.000100","text":"late reply","thread_ts":"1672531200.000100"}
+		]}`,
+	})
+	require.NoError(t, Compact(context.Background(), dir, false, logger.Silent))
+
+	assert.NoFileExists(t, filepath.Join(dir, "C01-1672531200.000100.json"))
+	data, err := os.ReadFile(filepath.Join(dir, "C01-2023-01.json"))
+	require.NoError(t, err)
+	var cnv types.Conversation
+	require.NoError(t, json.Unmarshal(data, &cnv))
+	require.Len(t, cnv.Messages, 1)
+	assert.Len(t, cnv.Messages[0].ThreadReplies, 2, "the thread is merged into its part")
+	assert.FileExists(t, filepath.Join(dir, "C01-2023-02.json"))
+
+	data, err = os.ReadFile(filepath.Join(dir, "C01"+dumpIndexSuffix))
+	require.NoError(t, err)
+	var idx dumpIndex
+	require.NoError(t, json.Unmarshal(data, &idx))
+	assert.Equal(t, []dumpPart{
+		{Period: "2023-01", File: "C01-2023-01.json", Messages: 3, Oldest: "1672531200.000100", Latest: "1672531200.000100"},
+		{Period: "2023-02", File: "C01-2023-02.json", Messages: 1, Oldest: "1675209600.000100", Latest: "1675209600.000100"},
+	}, idx.Parts)
+
+	t.Run("compacted split conversation is left as is", func(t *testing.T) {
+		require.NoError(t, Compact(context.Background(), dir, false, logger.Silent))
+		recs := readCustodyLog(t, dir)
+		assert.Len(t, recs, 1)
+	})
+}
@@ -4,14 +4,28 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/slack-go/slack"
 
 	"github.com/rusq/slackdump/v2"
 	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/diskspace"
+	"github.com/rusq/slackdump/v2/internal/encrypt"
+	"github.com/rusq/slackdump/v2/internal/notify"
+	"github.com/rusq/slackdump/v2/internal/output"
+	"github.com/rusq/slackdump/v2/internal/stream"
 	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/internal/upload"
 	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/transport"
 	"github.com/rusq/slackdump/v2/types"
 )
 
@@ -42,25 +56,443 @@ type Params struct {
 	ExportName  string            // export file or directory name.
 	ExportType  export.ExportType // export type, see enum for available options.
 	ExportToken string            // token that will be added to all exported files.
+	Encrypt     string            // export encryption, see encrypt.Parse.
+	// ExportEvents enables the synthesis of the missing channel join and
+	// archive messages in the export, see export.Options.SynthEvents.
+	ExportEvents bool
+	// ExportViewerCompat makes the export compatible with the
+	// slack-export-viewer, see export.Options.ViewerCompat.
+	ExportViewerCompat bool
+	// ExportLayout is the layout of the message files of the export, see
+	// export.Layout.
+	ExportLayout export.Layout
+	// ExportMembership enables the membership history files of the
+	// channels, see export.Options.Membership.
+	ExportMembership bool
+	// DumpExport is the export file or directory name, that the dumped
+	// conversations are converted to while dumping, see export.Transformer.
+	DumpExport string
+	// FailPartial makes the dump return the PartialError, if some of the
+	// conversations have failed, otherwise they are logged and reported to
+	// the notifier, and the run completes without the error.
+	FailPartial bool
+	// ValidateOutput enables the check of the produced JSON files against
+	// the published schemas after the run, see package schema.
+	ValidateOutput bool
+	// DiskSpace is the check of the free space of the output volume.
+	DiskSpace DiskSpaceParams
 
 	Emoji EmojiParams
 
+	// Personal enables the mode, in which the scheduled messages and the
+	// drafts of the current user are saved.
+	Personal bool
+
+	Audit AuditParams
+
+	// Thread enables the single thread mode, in which just the thread of the
+	// permalink in the Input is saved, with the users involved in it, into
+	// the base directory, or into the output file, if the base is not set.
+	Thread bool
+	// DM is the user, the direct messages with whom are dumped, instead of
+	// the conversations in the Input.  See slackdump.Session.FindUser for the
+	// supported values.
+	DM string
+
+	Follow FollowParams
+
+	Search SearchParams
+
+	Notify NotifyParams
+
+	MetricsAddr  string // address to serve the metrics on, empty - disabled
+	MetricsPush  string // Pushgateway URL to push the metrics to after the run, empty - disabled
+	MaxAPICalls  int    // maximum number of API calls of the run, 0 - unlimited
+	SkipAfter    int    // skip the items, that failed in this many runs in a row, see slackdump.SkipList, 0 - disabled
+	OTLPEndpoint string // OTLP/HTTP collector to send the traces to, empty - disabled
+	RecordFile   string // file to record the API calls to, empty - disabled
+	RecordPlain  bool   // write the recording as the plain JSON, not gzipped
+	// State is the location of the run state, see state.Open.  If set, the
+	// interrupted run saves the pending conversations there, and the next
+	// run resumes from them.  Empty - the pending file in the current
+	// directory, without resuming.
+	State string
+	// OnExists is the strategy of resolving the conflict with the existing
+	// output location, see output.Strategy.
+	OnExists output.Strategy
+
+	Upload upload.Config // upload of the finished archive, disabled if Target is empty
+
 	Options slackdump.Options
 }
 
+// DiskSpaceParams are the parameters of the check of the free space of the
+// output volume.
+type DiskSpaceParams struct {
+	// MinFree is the free space, below which the run pauses, until the
+	// space is freed, 0 disables the check.
+	MinFree diskspace.Size
+	// Preflight enables the estimate of the space, that the run needs, and
+	// the check of the free space against it, before the run starts.
+	Preflight bool
+	// Prompt asks the user, whether to continue, when the space is low.  If
+	// it is nil, the preflight fails, and the paused run waits for the
+	// space.
+	Prompt diskspace.PromptFunc
+}
+
+// AuditParams are the parameters of the audit logs mode, in which the audit
+// log of the Enterprise Grid organisation is saved.  The entries are filtered
+// by the Params.Oldest and Params.Latest as well.
+type AuditParams struct {
+	Enabled bool
+	Action  string // action name(s) filter
+	Actor   string // actor user ID filter
+	Entity  string // entity ID filter
+}
+
 type EmojiParams struct {
 	Enabled     bool
 	FailOnError bool
 }
 
+// SearchParams are the parameters of the search mode, in which the messages,
+// that match the query, are saved instead of the conversations.
+type SearchParams struct {
+	Query string // query in the Slack search syntax, empty - disabled
+	Files bool   // save the files, that match the query, as well
+}
+
+// FollowParams are the parameters of the follow mode, in which the dumped
+// conversations are polled for new messages until interrupted.
+type FollowParams struct {
+	Enabled  bool
+	Interval time.Duration // polling interval
+}
+
+// validate validates the follow parameters for the dump into base.
+func (fp FollowParams) validate(base string, latest TimeValue) error {
+	if !fp.Enabled {
+		return nil
+	}
+	if fp.Interval <= 0 {
+		return errors.New("follow interval must be positive")
+	}
+	if strings.EqualFold(filepath.Ext(base), ".zip") {
+		return errors.New("follow mode requires the base directory, ZIP files can't be appended to")
+	}
+	if !time.Time(latest).IsZero() {
+		return errors.New("follow mode can't be used with the latest time limit")
+	}
+	return nil
+}
+
+// NotifyParams are the parameters of the webhook notifications on the run
+// lifecycle events, and of the run report email.
+type NotifyParams struct {
+	Webhooks   Webhooks      // webhooks to notify
+	Events     string        // comma separated list of events, empty - all
+	Template   string        // payload template filename
+	StallAfter time.Duration // rate limit wait, after which the run is considered stalled
+
+	SMTP     notify.SMTP // run report email, disabled if SMTP.Server is empty
+	AttachMB int64       // maximum size of the output to attach to the email, in MiB
+}
+
+// Webhooks is the list of webhooks, it satisfies the flag.Value interface,
+// and can be specified multiple times.
+type Webhooks []notify.Hook
+
+func (w *Webhooks) String() string {
+	if w == nil {
+		return ""
+	}
+	urls := make([]string, len(*w))
+	for i, h := range *w {
+		urls[i] = h.URL
+	}
+	return strings.Join(urls, ",")
+}
+
+func (w *Webhooks) Set(s string) error {
+	h, err := notify.ParseHook(s)
+	if err != nil {
+		return err
+	}
+	*w = append(*w, h)
+	return nil
+}
+
+// Addresses is the list of email addresses, it satisfies the flag.Value
+// interface, and can be specified multiple times, or as a comma separated
+// list.
+type Addresses []string
+
+func (a *Addresses) String() string {
+	if a == nil {
+		return ""
+	}
+	return strings.Join(*a, ", ")
+}
+
+func (a *Addresses) Set(s string) error {
+	list, err := mail.ParseAddressList(s)
+	if err != nil {
+		return fmt.Errorf("invalid email address list %q: %w", s, err)
+	}
+	for _, addr := range list {
+		*a = append(*a, addr.String())
+	}
+	return nil
+}
+
+// Tokens is the list of the API tokens, it satisfies the flag.Value
+// interface, and can be specified multiple times, or as a comma separated
+// list.  The tokens are not printed.
+type Tokens []string
+
+func (t *Tokens) String() string {
+	if t == nil || len(*t) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d token(s)", len(*t))
+}
+
+func (t *Tokens) Set(s string) error {
+	for _, tok := range strings.Split(s, ",") {
+		if tok = strings.TrimSpace(tok); tok != "" {
+			*t = append(*t, tok)
+		}
+	}
+	return nil
+}
+
+func (np NotifyParams) validate() error {
+	if _, err := notify.ParseEvents(np.Events); err != nil {
+		return err
+	}
+	if np.AttachMB < 0 {
+		return errors.New("email attachment size limit can't be negative")
+	}
+	return np.SMTP.Validate()
+}
+
+// Notifier returns the notifier for the run in the given mode.  output is the
+// output file or directory, that is reported in the email.
+func (np NotifyParams) Notifier(mode string, output string, lg logger.Interface) (*notify.Notifier, error) {
+	events, err := notify.ParseEvents(np.Events)
+	if err != nil {
+		return nil, err
+	}
+	opts := []notify.Option{
+		notify.WithEvents(events...),
+		notify.WithStallThreshold(np.StallAfter),
+		notify.WithLogger(lg),
+	}
+	if np.SMTP.Server != "" {
+		smtp := np.SMTP
+		smtp.AttachLimit = np.AttachMB << 20
+		opts = append(opts, notify.WithMail(smtp, output))
+	}
+	if np.Template != "" {
+		tmpl, err := notify.ParseTemplate(np.Template)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, notify.WithTemplate(tmpl))
+	}
+	return notify.New(mode, np.Webhooks, opts...), nil
+}
+
+// Tags is the set of key=value tags, it satisfies the flag.Value interface,
+// and can be specified multiple times.
+type Tags map[string]string
+
+func (t *Tags) String() string {
+	if t == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*t))
+	for k, v := range *t {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (t *Tags) Set(s string) error {
+	k, v, found := strings.Cut(s, "=")
+	if !found || k == "" {
+		return fmt.Errorf("invalid tag %q, expected key=value", s)
+	}
+	if *t == nil {
+		*t = make(Tags)
+	}
+	(*t)[k] = v
+	return nil
+}
+
+// Header is the set of HTTP headers, it satisfies the flag.Value interface,
+// and can be specified multiple times, each value is "Key: Value".
+type Header http.Header
+
+func (h *Header) String() string {
+	if h == nil {
+		return ""
+	}
+	var lines []string
+	for k, vv := range *h {
+		for _, v := range vv {
+			lines = append(lines, k+": "+v)
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, ", ")
+}
+
+func (h *Header) Set(s string) error {
+	k, v, found := strings.Cut(s, ":")
+	k = strings.TrimSpace(k)
+	if !found || k == "" {
+		return fmt.Errorf("invalid header %q, expected \"Key: Value\"", s)
+	}
+	if *h == nil {
+		*h = make(Header)
+	}
+	http.Header(*h).Add(k, strings.TrimSpace(v))
+	return nil
+}
+
+// OutputLocation returns the file or directory of the run output: the export
+// or the base directory.
+func (p *Params) OutputLocation() string {
+	if p.ExportName != "" {
+		return p.ExportFilename()
+	}
+	return p.Output.Base
+}
+
+// ExportFilename returns the name of the export file or directory, if the
+// export is encrypted, the encryption extension is appended, i.e.
+// "export.zip.age".
+func (p *Params) ExportFilename() string {
+	if p.Encrypt == "" || p.ExportName == fsadapter.Stdout {
+		return p.ExportName
+	}
+	enc, err := encrypt.Parse(p.Encrypt)
+	if err != nil {
+		return p.ExportName
+	}
+	return encrypt.Filename(p.ExportName, enc)
+}
+
+func (p *Params) validateEncrypt() error {
+	if p.Encrypt == "" {
+		return nil
+	}
+	if !strings.EqualFold(filepath.Ext(p.ExportName), ".zip") && p.ExportName != fsadapter.Stdout {
+		return errors.New("encryption requires the export to a ZIP file, i.e. -export backup.zip")
+	}
+	_, err := encrypt.Parse(p.Encrypt)
+	return err
+}
+
+func (p *Params) validateURLs() error {
+	for _, s := range []string{p.Options.BaseURL, p.Options.FilesURL, p.MetricsPush} {
+		if s == "" {
+			continue
+		}
+		u, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+		if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid URL %q, must be http(s)://host[:port]", s)
+		}
+	}
+	return nil
+}
+
+func (p *Params) validateProxy() error {
+	if p.Options.Proxy == "" {
+		if p.Options.NoProxy != "" {
+			return errors.New("no-proxy requires the proxy to be set")
+		}
+		return nil
+	}
+	_, err := transport.ParseProxy(p.Options.Proxy)
+	return err
+}
+
+// validateTLS checks, that the certificate files of the TLS configuration
+// can be loaded.
+func (p *Params) validateTLS() error {
+	_, err := p.Options.TLS.Config()
+	return err
+}
+
+// UploadLocation returns the file or directory that should be uploaded after
+// the run, or an empty string, if the upload is disabled.
+func (p *Params) UploadLocation() string {
+	if p.Upload.Target == "" {
+		return ""
+	}
+	return p.OutputLocation()
+}
+
+func (p *Params) validateUpload() error {
+	if p.Upload.Target == "" {
+		return nil
+	}
+	if _, _, err := upload.ParseTarget(p.Upload.Target); err != nil {
+		return err
+	}
+	if p.Upload.Credentials.AccessKeyID == "" || p.Upload.Credentials.SecretAccessKey == "" {
+		return errors.New("upload requires the access key, set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables")
+	}
+	if p.ListFlags.FlagsPresent() {
+		return errors.New("upload can't be used with listing")
+	}
+	if p.Follow.Enabled {
+		return errors.New("upload can't be used in the follow mode, as the run never completes")
+	}
+	if p.OutputLocation() == "" {
+		return errors.New("upload requires the export name or the base directory")
+	}
+	if p.OutputLocation() == fsadapter.Stdout {
+		return errors.New("upload can't be used with the output to the standard output")
+	}
+	return nil
+}
+
 type Output struct {
 	Filename string
-	Format   string // output format
-	Base     string // base directory or zip file
+	Format   string   // output format
+	Base     string   // base directory or zip file
+	TZ       Location // time zone of the text output
+	Lang     Language // language of the text output
+	// Layout is the layout of the conversation files of the dump.
+	Layout DumpLayout
+	// Stream is the write policy of the event stream, see IsStream.
+	Stream stream.Policy
 }
 
 type Input struct {
 	List *structures.EntityList // Include channels
+	// Select is the conversation selector, the selected conversations are
+	// added to the List, see SelectStarred.
+	Select string
+}
+
+// SelectStarred selects the conversations, starred by the current user.
+const SelectStarred = "starred"
+
+func (in *Input) validateSelect() error {
+	switch in.Select {
+	case "", SelectStarred:
+		return nil
+	}
+	return fmt.Errorf("invalid conversation selector: %q, must be %q", in.Select, SelectStarred)
 }
 
 var (
@@ -97,9 +529,18 @@ func (out Output) IsText() bool {
 	return out.Format == OutputTypeText
 }
 
+// IsStream returns true if the output filename is the NDJSON event stream
+// target, i.e. jsonl://stdout.
+func (out Output) IsStream() bool {
+	return stream.IsTarget(out.Filename)
+}
+
 type ListFlags struct {
 	Users    bool
 	Channels bool
+	// Profiles enables fetching the complete profiles, with the custom
+	// fields, of the listed users.
+	Profiles bool
 }
 
 func (lf ListFlags) FlagsPresent() bool {
@@ -110,11 +551,125 @@ var ErrNothingToDo = errors.New("no valid input and no list flags specified")
 
 // Validate checks if the command line parameters have valid values.
 func (p *Params) Validate() error {
+	if err := p.Notify.validate(); err != nil {
+		return err
+	}
+	if err := p.validateUpload(); err != nil {
+		return err
+	}
+	if err := p.validateEncrypt(); err != nil {
+		return err
+	}
+	if err := p.validateProxy(); err != nil {
+		return err
+	}
+	if err := p.validateURLs(); err != nil {
+		return err
+	}
+	if err := p.validateTLS(); err != nil {
+		return err
+	}
+	if err := p.Input.validateSelect(); err != nil {
+		return err
+	}
+	if p.Input.Select != "" && (p.ListFlags.FlagsPresent() || p.Emoji.Enabled || p.Personal || p.Audit.Enabled || p.Thread || p.DM != "" || p.Search.Query != "") {
+		return errors.New("conversation selector is only supported for dumping conversations and export")
+	}
+	if p.MaxAPICalls < 0 {
+		return errors.New("maximum number of API calls can't be negative")
+	}
+	if p.SkipAfter < 0 {
+		return errors.New("number of the failed runs of the skip-list can't be negative")
+	}
+	if p.State != "" && (p.ListFlags.FlagsPresent() || p.Emoji.Enabled || p.Personal || p.Audit.Enabled || p.Thread || p.DM != "" || p.Follow.Enabled || p.Search.Query != "") {
+		return errors.New("run state is only supported for dumping conversations and export")
+	}
+	if p.OnExists == output.Resume && (p.ListFlags.FlagsPresent() || p.Emoji.Enabled || p.Personal || p.Audit.Enabled || p.Thread || p.DM != "" || p.Follow.Enabled || p.Search.Query != "") {
+		return errors.New("resuming the run is only supported for dumping conversations and export")
+	}
+	if p.DumpExport != "" && (p.ExportName != "" || p.ListFlags.FlagsPresent() || p.Emoji.Enabled || p.Personal || p.Audit.Enabled || p.Thread || p.Follow.Enabled || p.Search.Query != "") {
+		return errors.New("converting to the export while dumping is only supported for dumping conversations")
+	}
+	if p.ValidateOutput && (p.ListFlags.FlagsPresent() || p.Emoji.Enabled || p.Personal || p.Audit.Enabled || p.Thread || p.Follow.Enabled || p.Search.Query != "") {
+		return errors.New("validating the output is only supported for dumping conversations and export")
+	}
+	if p.ValidateOutput && (p.Encrypt != "" || p.OutputLocation() == fsadapter.Stdout) {
+		return errors.New("the encrypted output, or the output to the standard output, can't be validated")
+	}
+	if (p.DiskSpace.MinFree > 0 || p.DiskSpace.Preflight) && (p.ListFlags.FlagsPresent() || p.Emoji.Enabled || p.Personal || p.Audit.Enabled || p.Thread || p.Search.Query != "") {
+		return errors.New("disk space checks are only supported for dumping conversations and export")
+	}
+	if (p.DiskSpace.MinFree > 0 || p.DiskSpace.Preflight) && p.OutputLocation() == fsadapter.Stdout {
+		return errors.New("disk space can't be checked for the output to the standard output")
+	}
+	if p.DumpExport != "" && p.DumpExport == p.Output.Base {
+		return errors.New("the dump and the export must have different locations")
+	}
+	if p.ExportMembership && p.ExportName == "" {
+		return errors.New("membership history is only supported in the export mode")
+	}
+	if (p.ExportEvents || p.ExportViewerCompat) && p.ExportName == "" && p.DumpExport == "" {
+		return errors.New("channel event synthesis and the viewer compatibility are only supported in the export mode")
+	}
+	if p.Output.Layout != DLSingle && (p.ExportName != "" || p.ListFlags.FlagsPresent() || p.Emoji.Enabled || p.Personal || p.Audit.Enabled || p.Search.Query != "") {
+		return errors.New("dump layout is only supported for dumping conversations")
+	}
+	if p.ExportLayout != export.LDaily && p.ExportName == "" && p.DumpExport == "" {
+		return errors.New("export layout is only supported in the export mode")
+	}
+	if p.Options.EmbedFiles < 0 {
+		return errors.New("embedded file size can't be negative")
+	}
+	if p.Options.EmbedFiles > 0 && (!p.Options.DumpFiles || p.ExportName != "") {
+		return errors.New("embedding files is only supported for dumping conversations with the file download enabled")
+	}
+	if p.Follow.Enabled && (p.ExportName != "" || p.Emoji.Enabled || p.Personal || p.Audit.Enabled || p.Search.Query != "") {
+		return errors.New("follow mode is only supported for dumping conversations")
+	}
+	if p.Output.IsStream() && (p.ListFlags.FlagsPresent() || p.Emoji.Enabled) {
+		return errors.New("event stream output is only supported for dumping conversations and export")
+	}
+	if p.OutputLocation() == fsadapter.Stdout && p.Output.IsStream() && stream.IsStdout(p.Output.Filename) {
+		return errors.New("the archive and the event stream can't both be written to the standard output")
+	}
+	if err := p.Output.Stream.Validate(); err != nil {
+		return err
+	}
+	if p.Output.Stream != (stream.Policy{}) && !p.Output.IsStream() {
+		return errors.New("stream buffering and syncing options require the event stream output")
+	}
 	if p.ExportName != "" {
 		// slack workspace export mode.
 		return nil
 	}
 
+	if p.Personal {
+		if p.Output.Base == "" {
+			return errors.New("personal mode requires base directory")
+		}
+		return nil
+	}
+
+	if p.Audit.Enabled {
+		if p.Output.Base == "" {
+			return errors.New("audit mode requires base directory")
+		}
+		return nil
+	}
+
+	if p.Search.Query != "" {
+		if p.Output.Base == "" {
+			return errors.New("search mode requires base directory")
+		}
+		if p.Output.Format == "" {
+			p.Output.Format = OutputTypeJSON
+		}
+		if !p.Output.FormatValid() {
+			return fmt.Errorf("invalid output type: %q, must use one of %v", p.Output.Format, []string{OutputTypeJSON, OutputTypeText})
+		}
+		return p.compileValidateTemplate()
+	}
+
 	if p.Emoji.Enabled {
 		// emoji export mode
 		if p.Output.Base == "" {
@@ -123,10 +678,34 @@ func (p *Params) Validate() error {
 		return nil
 	}
 
-	if !p.Input.IsValid() && !p.ListFlags.FlagsPresent() {
+	if p.DM != "" {
+		if p.Input.IsValid() || p.ListFlags.FlagsPresent() || p.Thread {
+			return errors.New("direct messages of the user can't be combined with other conversations or listing")
+		}
+		if p.Options.NoUserCache {
+			return errors.New("direct messages of the user can't be found without the user cache")
+		}
+	} else if !p.Input.IsValid() && p.Input.Select == "" && !p.ListFlags.FlagsPresent() {
 		return ErrNothingToDo
 	}
 
+	if p.Thread {
+		if err := p.validateThread(); err != nil {
+			return err
+		}
+	}
+
+	if p.ListFlags.Profiles && !p.ListFlags.Users {
+		return errors.New("profiles can only be fetched when listing users")
+	}
+
+	if p.Follow.Enabled && p.ListFlags.FlagsPresent() {
+		return errors.New("follow mode can't be used with listing")
+	}
+	if err := p.Follow.validate(p.Output.Base, p.Latest); err != nil {
+		return err
+	}
+
 	// channels and users listings will be in the text format (if not specified otherwise)
 	if p.Output.Format == "" {
 		if p.ListFlags.FlagsPresent() {
@@ -148,6 +727,32 @@ func (p *Params) Validate() error {
 	return nil
 }
 
+// validateThread validates the single thread mode parameters.
+func (p *Params) validateThread() error {
+	if p.ListFlags.FlagsPresent() || p.Follow.Enabled {
+		return errors.New("thread mode can't be used with listing or in the follow mode")
+	}
+	if len(p.Input.List.Include) != 1 || len(p.Input.List.Exclude) != 0 {
+		return errors.New("thread mode requires exactly one thread link")
+	}
+	sl, err := structures.ParseLink(p.Input.List.Include[0])
+	if err != nil {
+		return err
+	}
+	if !sl.IsThread() {
+		return fmt.Errorf("not a thread link: %q", p.Input.List.Include[0])
+	}
+	if p.Output.Base == "" {
+		if p.Options.DumpFiles {
+			return errors.New("thread mode requires the base directory to download files")
+		}
+		if p.Output.IsStream() {
+			return errors.New("event stream output is not supported in the thread mode")
+		}
+	}
+	return nil
+}
+
 func (p *Params) CompileTemplates() (*template.Template, error) {
 	return template.New(FilenameTmplName).Parse(p.FilenameTemplate)
 }
@@ -1,9 +1,15 @@
 package config
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/internal/output"
+	"github.com/rusq/slackdump/v2/internal/stream"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/internal/upload"
 )
 
 func TestConfig_compileValidateTemplate(t *testing.T) {
@@ -79,3 +85,413 @@ func TestConfig_compileValidateTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestFollowParams_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fp      FollowParams
+		base    string
+		latest  TimeValue
+		wantErr bool
+	}{
+		{"disabled", FollowParams{}, "x.zip", TimeValue{}, false},
+		{"ok", FollowParams{Enabled: true, Interval: time.Minute}, "dir", TimeValue{}, false},
+		{"zero interval", FollowParams{Enabled: true}, "dir", TimeValue{}, true},
+		{"zip", FollowParams{Enabled: true, Interval: time.Minute}, "x.ZIP", TimeValue{}, true},
+		{"latest", FollowParams{Enabled: true, Interval: time.Minute}, "dir", TimeValue(time.Now()), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fp.validate(tt.base, tt.latest); (err != nil) != tt.wantErr {
+				t.Errorf("FollowParams.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhooks_Set(t *testing.T) {
+	var w Webhooks
+	if err := w.Set("https://hooks.slack.com/services/x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Set("json=https://example.com/hook"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Set("example.com"); err == nil {
+		t.Error("expected an error for the invalid URL")
+	}
+	if got, want := w.String(), "https://hooks.slack.com/services/x,https://example.com/hook"; got != want {
+		t.Errorf("Webhooks.String() = %q, want %q", got, want)
+	}
+}
+
+func TestAddresses_Set(t *testing.T) {
+	var a Addresses
+	for _, s := range []string{"hr@example.com, Legal <legal@example.com>", "it@example.com"} {
+		if err := a.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Set("not an address"); err == nil {
+		t.Error("expected an error")
+	}
+	if got, want := a.String(), `<hr@example.com>, "Legal" <legal@example.com>, <it@example.com>`; got != want {
+		t.Errorf("Addresses.String() = %q, want %q", got, want)
+	}
+}
+
+func TestTokens_Set(t *testing.T) {
+	var tt Tokens
+	for _, s := range []string{"xoxp-1, xoxp-2,", "xoxb-3"} {
+		if err := tt.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := []string(tt), []string{"xoxp-1", "xoxp-2", "xoxb-3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokens = %v, want %v", got, want)
+	}
+	if got, want := tt.String(), "3 token(s)"; got != want {
+		t.Errorf("Tokens.String() = %q, want %q", got, want)
+	}
+}
+
+func TestParams_validateUpload(t *testing.T) {
+	creds := upload.Credentials{AccessKeyID: "key", SecretAccessKey: "secret"}
+	tests := []struct {
+		name    string
+		p       Params
+		wantErr bool
+	}{
+		{"disabled", Params{}, false},
+		{"export", Params{ExportName: "x.zip", Upload: upload.Config{Target: "s3://bucket", Credentials: creds}}, false},
+		{"dump", Params{Output: Output{Base: "dir"}, Upload: upload.Config{Target: "s3://bucket/prefix", Credentials: creds}}, false},
+		{"no location", Params{Upload: upload.Config{Target: "s3://bucket", Credentials: creds}}, true},
+		{"stdout", Params{ExportName: "-", Upload: upload.Config{Target: "s3://bucket", Credentials: creds}}, true},
+		{"no credentials", Params{ExportName: "x.zip", Upload: upload.Config{Target: "s3://bucket"}}, true},
+		{"invalid target", Params{ExportName: "x.zip", Upload: upload.Config{Target: "bucket", Credentials: creds}}, true},
+		{"follow", Params{Output: Output{Base: "dir"}, Follow: FollowParams{Enabled: true}, Upload: upload.Config{Target: "s3://bucket", Credentials: creds}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.p.validateUpload(); (err != nil) != tt.wantErr {
+				t.Errorf("Params.validateUpload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParams_validateEncrypt(t *testing.T) {
+	const recipient = "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"
+	tests := []struct {
+		name    string
+		p       Params
+		wantErr bool
+	}{
+		{"disabled", Params{}, false},
+		{"age", Params{ExportName: "x.zip", Encrypt: "age:" + recipient}, false},
+		{"gpg", Params{ExportName: "x.ZIP", Encrypt: "gpg:security@example.com"}, false},
+		{"directory", Params{ExportName: "x", Encrypt: "age:" + recipient}, true},
+		{"stdout", Params{ExportName: "-", Encrypt: "age:" + recipient}, false},
+		{"dump", Params{Output: Output{Base: "x.zip"}, Encrypt: "age:" + recipient}, true},
+		{"invalid method", Params{ExportName: "x.zip", Encrypt: "rot13:x"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.p.validateEncrypt(); (err != nil) != tt.wantErr {
+				t.Errorf("Params.validateEncrypt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParams_validateProxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    slackdump.Options
+		wantErr bool
+	}{
+		{"disabled", slackdump.Options{}, false},
+		{"http", slackdump.Options{Proxy: "proxy.corp:3128"}, false},
+		{"socks5", slackdump.Options{Proxy: "socks5://proxy.corp:1080", NoProxy: ".internal.corp"}, false},
+		{"unsupported scheme", slackdump.Options{Proxy: "ftp://proxy.corp"}, true},
+		{"no proxy without proxy", slackdump.Options{NoProxy: ".internal.corp"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Params{Options: tt.opts}
+			if err := p.validateProxy(); (err != nil) != tt.wantErr {
+				t.Errorf("Params.validateProxy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParams_validateURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Params
+		wantErr bool
+	}{
+		{"disabled", Params{}, false},
+		{"govslack", Params{Options: slackdump.Options{BaseURL: "https://slack-gov.com"}}, false},
+		{"proxy", Params{Options: slackdump.Options{BaseURL: "http://slack.corp:8080/", FilesURL: "https://slack-files.corp"}}, false},
+		{"no scheme", Params{Options: slackdump.Options{BaseURL: "slack-gov.com"}}, true},
+		{"invalid files url", Params{Options: slackdump.Options{FilesURL: "ftp://files.corp"}}, true},
+		{"pushgateway", Params{MetricsPush: "http://pushgateway:9091"}, false},
+		{"invalid pushgateway", Params{MetricsPush: "pushgateway:9091"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.p.validateURLs(); (err != nil) != tt.wantErr {
+				t.Errorf("Params.validateURLs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParams_ExportFilename(t *testing.T) {
+	p := Params{ExportName: "x.zip", Encrypt: "gpg:security@example.com"}
+	if got, want := p.ExportFilename(), "x.zip.gpg"; got != want {
+		t.Errorf("Params.ExportFilename() = %q, want %q", got, want)
+	}
+	p.ExportName = "x.zip.gpg"
+	if got, want := p.ExportFilename(), "x.zip.gpg"; got != want {
+		t.Errorf("Params.ExportFilename() = %q, want %q", got, want)
+	}
+	p.ExportName = "-"
+	if got, want := p.ExportFilename(), "-"; got != want {
+		t.Errorf("Params.ExportFilename() = %q, want %q", got, want)
+	}
+}
+
+func TestParams_Validate_stream(t *testing.T) {
+	p := Params{ListFlags: ListFlags{Users: true}, Output: Output{Filename: "jsonl://stdout"}}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the stream with listing")
+	}
+	p = Params{ExportName: "x.zip", Output: Output{Filename: "jsonl://stdout"}}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	p = Params{ExportName: "-", Output: Output{Filename: "jsonl://stdout"}}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the stream and the export to the standard output")
+	}
+	p = Params{ExportName: "-", Output: Output{Filename: "jsonl://events.jsonl"}}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestParams_Validate_profiles(t *testing.T) {
+	p := Params{ListFlags: ListFlags{Channels: true, Profiles: true}, Input: Input{List: new(structures.EntityList)}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the profiles without listing users")
+	}
+	p = Params{ListFlags: ListFlags{Users: true, Profiles: true}, Input: Input{List: new(structures.EntityList)}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestTags_Set(t *testing.T) {
+	var tags Tags
+	for _, s := range []string{"team=hr", "date=2023-01-01", "empty="} {
+		if err := tags.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tags.Set("novalue"); err == nil {
+		t.Error("expected an error")
+	}
+	if got, want := tags.String(), "date=2023-01-01,empty=,team=hr"; got != want {
+		t.Errorf("Tags.String() = %q, want %q", got, want)
+	}
+}
+
+func TestHeader_Set(t *testing.T) {
+	var h Header
+	for _, s := range []string{"X-Proxy-Token: secret", "x-team:  hr", "X-Team: it"} {
+		if err := h.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, s := range []string{"novalue", ": value"} {
+		if err := h.Set(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+	if got, want := h.String(), "X-Proxy-Token: secret, X-Team: hr, X-Team: it"; got != want {
+		t.Errorf("Header.String() = %q, want %q", got, want)
+	}
+}
+
+func TestParams_validateThread(t *testing.T) {
+	const link = "https://ora600.slack.com/archives/CHM82GF99/p1577694990000400"
+	thread := func(base string, download bool, includes ...string) Params {
+		p := Params{
+			Thread:           true,
+			Input:            Input{List: &structures.EntityList{Include: includes}},
+			Output:           Output{Filename: "-", Base: base},
+			FilenameTemplate: "{{.ID}}",
+		}
+		p.Options.DumpFiles = download
+		return p
+	}
+	tests := []struct {
+		name    string
+		p       Params
+		wantErr bool
+	}{
+		{"single json", thread("", false, link), false},
+		{"archive with files", thread("thread.zip", true, link), false},
+		{"internal link", thread("", false, "CHM82GF99:1577694990.000400"), false},
+		{"files without base", thread("", true, link), true},
+		{"channel", thread("", false, "CHM82GF99"), true},
+		{"several links", thread("", false, link, link), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.p.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Params.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParams_Validate_dm(t *testing.T) {
+	p := Params{DM: "alice", Input: Input{List: new(structures.EntityList)}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	p = Params{DM: "alice", Input: Input{List: &structures.EntityList{Include: []string{"C01"}}}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the direct messages with other conversations")
+	}
+	p = Params{DM: "alice", Input: Input{List: new(structures.EntityList)}, FilenameTemplate: "{{.ID}}"}
+	p.Options.NoUserCache = true
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the direct messages without the user cache")
+	}
+}
+
+func TestParams_Validate_dumpExport(t *testing.T) {
+	input := Input{List: &structures.EntityList{Include: []string{"C01"}}}
+	p := Params{DumpExport: "export.zip", ExportViewerCompat: true, Input: input, Output: Output{Base: "dump"}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	p = Params{DumpExport: "export.zip", ExportName: "other.zip", Input: input, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the export mode")
+	}
+	p = Params{DumpExport: "out", Input: input, Output: Output{Base: "out"}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the same location")
+	}
+	p = Params{DumpExport: "export.zip", ExportMembership: true, Input: input, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the membership history")
+	}
+}
+
+func TestParams_Validate_select(t *testing.T) {
+	p := Params{Input: Input{List: new(structures.EntityList), Select: SelectStarred}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	p = Params{Input: Input{List: new(structures.EntityList), Select: "pinned"}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the unknown selector")
+	}
+	p = Params{ListFlags: ListFlags{Channels: true}, Input: Input{List: new(structures.EntityList), Select: SelectStarred}}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the selector with listing")
+	}
+}
+
+func TestParams_Validate_search(t *testing.T) {
+	p := Params{Search: SearchParams{Query: "from:@bob"}, Output: Output{Base: "out"}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if p.Output.Format != OutputTypeJSON {
+		t.Errorf("output format = %q, want %q", p.Output.Format, OutputTypeJSON)
+	}
+	p = Params{Search: SearchParams{Query: "from:@bob"}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the search without the base directory")
+	}
+	p = Params{Search: SearchParams{Query: "from:@bob"}, Output: Output{Base: "out"}, Follow: FollowParams{Enabled: true}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the search in the follow mode")
+	}
+	p = Params{Search: SearchParams{Query: "from:@bob"}, Output: Output{Base: "out"}, OnExists: output.Resume, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for resuming the search")
+	}
+}
+
+func TestDumpLayout(t *testing.T) {
+	var l DumpLayout
+	if err := l.Set("Monthly"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if l != DLMonthly {
+		t.Errorf("layout = %s, want monthly", l)
+	}
+	if err := l.Set("daily"); err == nil {
+		t.Error("expected an error for the unknown layout")
+	}
+	ts := time.Date(2023, 2, 28, 23, 0, 0, 0, time.FixedZone("X", -3*3600))
+	for l, want := range map[DumpLayout]string{DLSingle: "", DLYearly: "2023", DLMonthly: "2023-03"} {
+		if got := l.Period(ts); got != want {
+			t.Errorf("%s.Period() = %q, want %q", l, got, want)
+		}
+	}
+	p := Params{ExportName: "export.zip", Output: Output{Layout: DLYearly}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the dump layout in the export mode")
+	}
+}
+
+func TestParams_Validate_validateOutput(t *testing.T) {
+	p := Params{ExportName: "x.zip", ValidateOutput: true}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	p = Params{ExportName: "-", ValidateOutput: true}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the validation of the standard output")
+	}
+	p = Params{ListFlags: ListFlags{Users: true}, Input: Input{List: new(structures.EntityList)}, FilenameTemplate: "{{.ID}}", ValidateOutput: true}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the validation with listing")
+	}
+}
+
+func TestParams_Validate_streamPolicy(t *testing.T) {
+	p := Params{ExportName: "x.zip", Output: Output{Filename: "jsonl://events.jsonl", Stream: stream.Policy{BufferSize: 65536, Sync: true}}}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	p = Params{ExportName: "x.zip", Output: Output{Filename: "-", Stream: stream.Policy{Sync: true}}}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the stream options without the stream")
+	}
+}
+
+func TestParams_Validate_diskSpace(t *testing.T) {
+	p := Params{ExportName: "x.zip", DiskSpace: DiskSpaceParams{MinFree: 1 << 30, Preflight: true}}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	p = Params{ExportName: "-", DiskSpace: DiskSpaceParams{MinFree: 1 << 30}}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the standard output")
+	}
+	p = Params{ListFlags: ListFlags{Users: true}, Input: Input{List: new(structures.EntityList)}, FilenameTemplate: "{{.ID}}", DiskSpace: DiskSpaceParams{Preflight: true}}
+	if err := p.Validate(); err == nil {
+		t.Error("expected an error for the disk space checks with listing")
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"flag"
+
+	"github.com/rusq/slackdump/v2/internal/i18n"
+)
+
+// Language satisfies flag.Value, it is the language of the rendered output,
+// i.e. "de", see i18n.Lookup.
+type Language struct {
+	cat *i18n.Catalog
+}
+
+var _ flag.Value = &Language{}
+
+func (l *Language) String() string {
+	if l == nil || l.cat == nil {
+		return ""
+	}
+	return l.cat.Lang
+}
+
+func (l *Language) Set(s string) error {
+	cat, err := i18n.Lookup(s)
+	if err != nil {
+		return err
+	}
+	l.cat = cat
+	return nil
+}
+
+// Get returns the catalog of the language, or the default one, if it was
+// not set.
+func (l Language) Get() *i18n.Catalog {
+	if l.cat == nil {
+		cat, _ := i18n.Lookup(i18n.Default)
+		return cat
+	}
+	return l.cat
+}
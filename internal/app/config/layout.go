@@ -0,0 +1,59 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DumpLayout is the layout of the conversation files of the dump.
+type DumpLayout uint8
+
+const (
+	// DLSingle is one file per conversation, i.e. "C01.json".
+	DLSingle DumpLayout = iota
+	// DLYearly is one file per year, i.e. "C01-2024.json".
+	DLYearly
+	// DLMonthly is one file per month, i.e. "C01-2024-01.json".
+	DLMonthly
+)
+
+var _ flag.Value = new(DumpLayout)
+
+var dumpLayoutNames = map[DumpLayout]string{
+	DLSingle:  "single",
+	DLYearly:  "yearly",
+	DLMonthly: "monthly",
+}
+
+func (l DumpLayout) String() string {
+	if s, ok := dumpLayoutNames[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("DumpLayout(%d)", uint8(l))
+}
+
+// Set sets the layout from its name, it is the flag.Value interface.
+func (l *DumpLayout) Set(s string) error {
+	for v, name := range dumpLayoutNames {
+		if strings.EqualFold(s, name) {
+			*l = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown dump layout: %q, use one of: single, yearly or monthly", s)
+}
+
+// Period returns the period of the message with the time t, that is appended
+// to the name of the conversation file, or an empty string for DLSingle.
+func (l DumpLayout) Period(t time.Time) string {
+	switch l {
+	case DLYearly:
+		return t.UTC().Format("2006")
+	case DLMonthly:
+		return t.UTC().Format("2006-01")
+	default:
+		return ""
+	}
+}
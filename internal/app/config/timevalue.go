@@ -1,7 +1,11 @@
 package config
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -30,3 +34,126 @@ func (tv *TimeValue) Set(s string) error {
 	}
 	return nil
 }
+
+// Location satisfies flag.Value, it is the time zone, i.e. "Europe/Berlin",
+// the times are rendered in, in the human-readable outputs.
+type Location struct {
+	loc *time.Location
+}
+
+var _ flag.Value = &Location{}
+
+func (l *Location) String() string {
+	if l == nil || l.loc == nil {
+		return ""
+	}
+	return l.loc.String()
+}
+
+func (l *Location) Set(s string) error {
+	if s == "" {
+		l.loc = nil
+		return nil
+	}
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		return err
+	}
+	l.loc = loc
+	return nil
+}
+
+// Get returns the time zone, or the local time zone, if it was not set.
+func (l Location) Get() *time.Location {
+	if l.loc == nil {
+		return time.Local
+	}
+	return l.loc
+}
+
+// Retention satisfies flag.Value, it is the retention period in years, months,
+// weeks and days, i.e. "2y", "18m", "1y6m" or "90d".
+type Retention struct {
+	Years, Months, Days int
+}
+
+var _ flag.Value = &Retention{}
+
+func (r *Retention) String() string {
+	if r == nil || r.IsZero() {
+		return ""
+	}
+	var sb strings.Builder
+	for _, u := range []struct {
+		n    int
+		unit byte
+	}{{r.Years, 'y'}, {r.Months, 'm'}, {r.Days, 'd'}} {
+		if u.n != 0 {
+			sb.WriteString(strconv.Itoa(u.n))
+			sb.WriteByte(u.unit)
+		}
+	}
+	return sb.String()
+}
+
+func (r *Retention) Set(s string) error {
+	var v Retention
+	rest := strings.ToLower(strings.TrimSpace(s))
+	if rest == "" {
+		return errors.New("empty retention period")
+	}
+	for rest != "" {
+		i := strings.IndexFunc(rest, func(c rune) bool { return c < '0' || '9' < c })
+		if i <= 0 {
+			return fmt.Errorf("invalid retention period: %q, use i.e. 2y, 6m, 2w or 90d", s)
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return fmt.Errorf("invalid retention period: %q: %w", s, err)
+		}
+		switch rest[i] {
+		case 'y':
+			v.Years += n
+		case 'm':
+			v.Months += n
+		case 'w':
+			v.Days += n * 7
+		case 'd':
+			v.Days += n
+		default:
+			return fmt.Errorf("invalid retention period unit: %q, use one of: y, m, w or d", rest[i])
+		}
+		rest = rest[i+1:]
+	}
+	*r = v
+	return nil
+}
+
+// IsZero returns true if the retention period is not set.
+func (r Retention) IsZero() bool {
+	return r.Years == 0 && r.Months == 0 && r.Days == 0
+}
+
+// Cutoff returns the time, the messages before which are outside of the
+// retention period, as of now.
+func (r Retention) Cutoff(now time.Time) time.Time {
+	return now.AddDate(-r.Years, -r.Months, -r.Days)
+}
+
+// Days satisfies flag.Value, it is the duration in whole days.
+type Days time.Duration
+
+var _ flag.Value = new(Days)
+
+func (d *Days) String() string {
+	return strconv.Itoa(int(time.Duration(*d) / (24 * time.Hour)))
+}
+
+func (d *Days) Set(s string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid number of days: %q", s)
+	}
+	*d = Days(time.Duration(n) * 24 * time.Hour)
+	return nil
+}
@@ -41,3 +41,69 @@ func TestTimeValue_Set(t *testing.T) {
 		})
 	}
 }
+
+func TestLanguage_Set(t *testing.T) {
+	var l Language
+	assert.Equal(t, "en", l.Get().Lang, "default is English")
+	assert.NoError(t, l.Set("ja"))
+	assert.Equal(t, "ja", l.String())
+	assert.Error(t, l.Set("xx"))
+	assert.Equal(t, "ja", l.Get().Lang, "unchanged on error")
+}
+
+func TestLocation_Set(t *testing.T) {
+	var l Location
+	assert.Equal(t, time.Local, l.Get(), "default is local")
+	assert.Equal(t, "", l.String())
+
+	assert.NoError(t, l.Set("Europe/Berlin"))
+	assert.Equal(t, "Europe/Berlin", l.String())
+	assert.Equal(t, "Europe/Berlin", l.Get().String())
+
+	assert.Error(t, l.Set("Mars/Olympus_Mons"))
+	assert.Equal(t, "Europe/Berlin", l.String(), "unchanged on error")
+
+	assert.NoError(t, l.Set(""))
+	assert.Equal(t, time.Local, l.Get())
+}
+
+func TestRetention_Set(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Retention
+		wantErr bool
+	}{
+		{"2y", Retention{Years: 2}, false},
+		{"18m", Retention{Months: 18}, false},
+		{"1Y6m", Retention{Years: 1, Months: 6}, false},
+		{"2w3d", Retention{Days: 17}, false},
+		{"90d", Retention{Days: 90}, false},
+		{"", Retention{}, true},
+		{"2", Retention{}, true},
+		{"y", Retention{}, true},
+		{"2h", Retention{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			var r Retention
+			err := r.Set(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Retention.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, r)
+		})
+	}
+	r := Retention{Years: 1, Months: 6}
+	assert.Equal(t, "1y6m", r.String())
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC), r.Cutoff(now))
+}
+
+func TestDays_Set(t *testing.T) {
+	var d Days
+	assert.NoError(t, d.Set("30"))
+	assert.Equal(t, Days(30*24*time.Hour), d)
+	assert.Equal(t, "30", d.String())
+	assert.Error(t, d.Set("-1"))
+	assert.Error(t, d.Set("1w"))
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rusq/slackdump/v2/internal/stats"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// Convert converts the archive src to the output file ("-" for the Stdout)
+// in the format: "graphml" for the social graph of the users, see
+// stats.SocialGraph, or "corpus" for the normalized message text for the NLP
+// pipelines, see stats.Corpus.
+func Convert(ctx context.Context, src string, output string, format string, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	if format != "graphml" && format != "corpus" {
+		return fmt.Errorf("invalid format: %q, must be one of: graphml, corpus", format)
+	}
+	ar, err := openArchive(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	f, err := createFile(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if format == "corpus" {
+		n, err := stats.Corpus(ctx, ar, f)
+		if err != nil {
+			return err
+		}
+		lg.Debugf("%s: %d message(s)", ar.Name(), n)
+		return nil
+	}
+	g, err := stats.SocialGraph(ctx, ar)
+	if err != nil {
+		return err
+	}
+	lg.Debugf("%s: %d user(s), %d edge(s)", ar.Name(), len(g.Nodes), len(g.Edges))
+	return g.ToGraphML(f)
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/diskspace"
+	"github.com/rusq/slackdump/v2/internal/structures"
+)
+
+// estimateSample is the number of the conversations, that are sampled to
+// estimate the size of the run.
+const estimateSample = 10
+
+// sizeEstimator estimates the size of the conversations, it is
+// slackdump.Session.EstimateSize.
+type sizeEstimator interface {
+	EstimateSize(ctx context.Context, channelIDs []string, oldest, latest time.Time, sample int) (slackdump.SizeEstimate, error)
+}
+
+// preflight checks, that the output volume has the space, that the
+// conversations channelIDs need, and the minimum free space on top of it, if
+// the preflight is enabled.  If it doesn't, the user is asked, whether to
+// continue, or, if there's no one to ask, the error is returned.  If the
+// estimate fails, i.e. on the inaccessible conversation, the check is
+// skipped, as the run will report it anyway.
+func preflight(ctx context.Context, cfg config.Params, est sizeEstimator, channelIDs []string) error {
+	if !cfg.DiskSpace.Preflight {
+		return nil
+	}
+	lg := cfg.Logger()
+	loc := outputDir(cfg)
+	free, err := diskspace.Free(loc)
+	if err != nil {
+		lg.Printf("disk space preflight skipped: %s", err)
+		return nil
+	}
+	lg.Printf("estimating the size of %d conversation(s)...", len(channelIDs))
+	e, err := est.EstimateSize(ctx, channelIDs, time.Time(cfg.Oldest), time.Time(cfg.Latest), estimateSample)
+	if err != nil {
+		lg.Printf("disk space preflight skipped: %s", err)
+		return nil
+	}
+	need := diskspace.Size(e.Total()) + cfg.DiskSpace.MinFree
+	lg.Printf("the run needs about %s (%d message(s) in %d conversation(s), %s of files), %s is free on %s",
+		diskspace.Size(e.Total()), e.Messages, e.Conversations, diskspace.Size(e.FileBytes), diskspace.Size(free), loc)
+	if diskspace.Size(free) >= need {
+		return nil
+	}
+	msg := fmt.Sprintf("not enough disk space on %s: %s is free, the run needs about %s", loc, diskspace.Size(free), need)
+	if cfg.DiskSpace.Prompt == nil {
+		return errors.New(msg)
+	}
+	ok, err := cfg.DiskSpace.Prompt(msg + ".  Continue anyway?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// guardFS returns fs, that pauses the run, when the free space of the output
+// volume falls below the minimum, if it is set, see diskspace.Guard.
+func guardFS(ctx context.Context, cfg config.Params, fs fsadapter.FS) fsadapter.FS {
+	if cfg.DiskSpace.MinFree == 0 {
+		return fs
+	}
+	opts := []diskspace.GuardOption{diskspace.WithLogger(cfg.Logger())}
+	if cfg.DiskSpace.Prompt != nil {
+		opts = append(opts, diskspace.WithPrompt(cfg.DiskSpace.Prompt))
+	}
+	return diskspace.NewGuard(ctx, outputDir(cfg), uint64(cfg.DiskSpace.MinFree), opts...).FS(fs)
+}
+
+// outputDir returns the location of the output, the current directory, if
+// it is not set.
+func outputDir(cfg config.Params) string {
+	if loc := cfg.OutputLocation(); loc != "" {
+		return loc
+	}
+	return "."
+}
+
+// listChannelIDs returns the conversation IDs of the input list, the thread
+// links are skipped, as they are negligible.
+func listChannelIDs(el *structures.EntityList) []string {
+	if el == nil {
+		return nil
+	}
+	var ids []string
+	for _, ent := range el.Include {
+		sl, err := structures.ParseLink(ent)
+		if err != nil || sl.IsThread() {
+			continue
+		}
+		ids = append(ids, sl.Channel)
+	}
+	return ids
+}
+
+// exportChannelIDs returns the conversation IDs of the export: the included
+// ones, or all the conversations of the workspace, but the excluded ones.
+func exportChannelIDs(ctx context.Context, sess *slackdump.Session, el *structures.EntityList) ([]string, error) {
+	if el != nil && el.HasIncludes() {
+		return listChannelIDs(el), nil
+	}
+	chans, err := sess.GetChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var excluded map[string]bool
+	if el != nil {
+		excluded = make(map[string]bool, len(el.Exclude))
+		for _, id := range el.Exclude {
+			excluded[id] = true
+		}
+	}
+	ids := make([]string, 0, len(chans))
+	for _, ch := range chans {
+		if !excluded[ch.ID] {
+			ids = append(ids, ch.ID)
+		}
+	}
+	return ids, nil
+}
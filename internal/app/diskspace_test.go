@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/diskspace"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+type fakeEstimator struct {
+	est slackdump.SizeEstimate
+	err error
+	ids []string
+}
+
+func (f *fakeEstimator) EstimateSize(_ context.Context, channelIDs []string, _, _ time.Time, _ int) (slackdump.SizeEstimate, error) {
+	f.ids = channelIDs
+	return f.est, f.err
+}
+
+func TestPreflight(t *testing.T) {
+	dir := t.TempDir()
+	free, err := diskspace.Free(dir)
+	if err != nil {
+		t.Skip(err)
+	}
+	huge := slackdump.SizeEstimate{Conversations: 1, MessageBytes: int64(free) * 2}
+	params := func(prompt diskspace.PromptFunc) config.Params {
+		cfg := config.Params{Output: config.Output{Base: dir}, DiskSpace: config.DiskSpaceParams{Preflight: true, Prompt: prompt}}
+		cfg.Options.Logger = logger.Silent
+		return cfg
+	}
+	t.Run("enough space", func(t *testing.T) {
+		est := &fakeEstimator{est: slackdump.SizeEstimate{Conversations: 1, MessageBytes: 1024}}
+		assert.NoError(t, preflight(context.Background(), params(nil), est, []string{"C1"}))
+		assert.Equal(t, []string{"C1"}, est.ids)
+	})
+	t.Run("not enough space", func(t *testing.T) {
+		err := preflight(context.Background(), params(nil), &fakeEstimator{est: huge}, []string{"C1"})
+		assert.ErrorContains(t, err, "not enough disk space")
+	})
+	t.Run("continued by the user", func(t *testing.T) {
+		prompt := func(string) (bool, error) { return true, nil }
+		assert.NoError(t, preflight(context.Background(), params(prompt), &fakeEstimator{est: huge}, []string{"C1"}))
+	})
+	t.Run("estimate error", func(t *testing.T) {
+		err := preflight(context.Background(), params(nil), &fakeEstimator{err: errors.New("channel_not_found")}, []string{"C1"})
+		assert.NoError(t, err, "the check is skipped")
+	})
+	t.Run("disabled", func(t *testing.T) {
+		cfg := params(nil)
+		cfg.DiskSpace.Preflight = false
+		est := &fakeEstimator{est: huge}
+		assert.NoError(t, preflight(context.Background(), cfg, est, []string{"C1"}))
+		assert.Nil(t, est.ids, "not estimated")
+	})
+}
+
+func TestListChannelIDs(t *testing.T) {
+	el := &structures.EntityList{Include: []string{
+		"C01",
+		"https://ora600.slack.com/archives/C02",
+		"https://ora600.slack.com/archives/C03/p1577694990000400",
+	}}
+	assert.Equal(t, []string{"C01", "C02"}, listChannelIDs(el))
+	assert.Nil(t, listChannelIDs(nil))
+}
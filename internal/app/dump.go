@@ -8,14 +8,21 @@ import (
 	"html/template"
 	"io"
 	"os"
+	"path"
+	"path/filepath"
 	"runtime/trace"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/rusq/slackdump/v2"
 	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/export"
 	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/metrics"
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/stream"
 	"github.com/rusq/slackdump/v2/internal/structures"
 	"github.com/rusq/slackdump/v2/logger"
 	"github.com/rusq/slackdump/v2/types"
@@ -26,6 +33,16 @@ type dump struct {
 	cfg  config.Params
 
 	log logger.Interface
+
+	// events is the event stream, nil if disabled.
+	events *stream.Writer
+	// transform converts the dumped conversations to the export, nil if
+	// disabled.
+	transform *export.Transformer
+
+	// followed is the map of the input link to the dumped conversation, it's
+	// populated in the follow mode.
+	followed map[string]*types.Conversation
 }
 
 func Dump(ctx context.Context, cfg config.Params, prov auth.Provider) error {
@@ -40,6 +57,13 @@ func Dump(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 	if cfg.ListFlags.FlagsPresent() {
 		err = dm.List(ctx)
 	} else {
+		events, closeFn, serr := openStream(cfg)
+		if serr != nil {
+			return serr
+		}
+		defer closeFn()
+		dm.events = events
+
 		var n int
 		n, err = dm.Dump(ctx)
 		cfg.Logger().Printf("dumped %d item(s)", n)
@@ -70,37 +94,169 @@ func newDump(ctx context.Context, cfg config.Params, prov auth.Provider) (*dump,
 //	|  +- ...
 //	+--<ID>.json - json file with conversation and users
 //	+--<ID>.txt  - formatted conversation in text format, if generateText is true.
-func (app *dump) Dump(ctx context.Context) (int, error) {
+//
+// If the DM is set in the config, the direct messages with that user are
+// dumped instead of the input.  If the DumpExport is set, the conversations
+// are also converted to the export there, as they are dumped.
+func (app *dump) Dump(ctx context.Context) (_ int, err error) {
+	if app.cfg.DM != "" {
+		id, err := app.sess.DMChannel(ctx, app.cfg.DM)
+		if err != nil {
+			return 0, err
+		}
+		app.cfg.Input.List = &structures.EntityList{Include: []string{id}}
+	}
+	if err := resolveNames(ctx, app.sess, &app.cfg.Input, app.log); err != nil {
+		return 0, err
+	}
+	if err := selectChannels(ctx, app.sess, &app.cfg.Input, app.log); err != nil {
+		return 0, err
+	}
 	if !app.cfg.Input.IsValid() {
 		return 0, errors.New("no valid input")
 	}
+	if err := preflight(ctx, app.cfg, app.sess, listChannelIDs(app.cfg.Input.List)); err != nil {
+		return 0, err
+	}
 
-	fs, err := fsadapter.New(app.cfg.Output.Base)
+	fsc, err := fsadapter.New(app.cfg.Output.Base)
 	if err != nil {
 		return 0, err
 	}
-	defer fs.Close()
+	defer fsc.Close()
+	fs := metrics.NewFS(guardFS(ctx, app.cfg, fsc))
 	app.sess.SetFS(fs)
+	app.events.Users(app.sess.Users)
+	if err := app.sess.SaveWorkspace(ctx, fs); err != nil {
+		return 0, err
+	}
+	if app.cfg.DumpExport != "" {
+		closeFn, err := app.startTransform(ctx)
+		if err != nil {
+			return 0, err
+		}
+		defer func() {
+			if cerr := closeFn(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}()
+	}
 
 	tmpl, err := app.cfg.CompileTemplates()
 	if err != nil {
 		return 0, err
 	}
 
-	total := 0
+	var (
+		total   = 0
+		failed  network.RetryQueue
+		skipped []string
+		// interrupted is the error that interrupted the run, the rest of the
+		// input is collected into pending.
+		interrupted error
+		pending     []string
+		dumped      []string
+	)
 	if err := app.cfg.Input.Producer(func(channelID string) error {
+		if interrupted != nil {
+			pending = append(pending, channelID)
+			return config.ErrSkip
+		}
 		if err := app.dumpOne(ctx, fs, tmpl, channelID, app.sess.Dump); err != nil {
-			app.log.Printf("error processing: %q (conversation will be skipped): %s", channelID, err)
+			if slackdump.IsInterrupted(ctx, err) {
+				interrupted = err
+				pending = append(pending, channelID)
+				return config.ErrSkip
+			}
+			if errors.Is(err, slackdump.ErrSkipListed) {
+				app.log.Printf("skipping: %s", err)
+				return config.ErrSkip
+			}
+			// the conversation is queued only if there will be a retry pass.
+			if app.cfg.Options.FailedRetries > 0 && failed.Add(channelID, err) {
+				app.log.Printf("error processing: %q (conversation will be retried at the end of the run): %s", channelID, err)
+			} else {
+				app.log.Printf("error processing: %q (conversation will be skipped): %s", channelID, err)
+				skipped = append(skipped, channelID)
+			}
 			return config.ErrSkip
 		}
 		total++
+		dumped = append(dumped, channelID)
 		return nil
 	}); err != nil {
 		return total, err
 	}
+	if err := slackdump.WriteManifest(fs, app.sess.Manifest()); err != nil {
+		return total, err
+	}
+	if interrupted != nil {
+		if err := writePending(ctx, app.cfg, pending); err != nil {
+			return total, err
+		}
+		return total, fmt.Errorf("run interrupted: %w", interrupted)
+	}
+	n, gaveUp := app.retryFailed(ctx, &failed, func(channelID string) error {
+		if err := app.dumpOne(ctx, fs, tmpl, channelID, app.sess.Dump); err != nil {
+			return err
+		}
+		dumped = append(dumped, channelID)
+		return nil
+	})
+	total += n
+	if err := app.sess.SaveAdmin(ctx, fs, dumped); err != nil {
+		return total, err
+	}
+	if err := app.sess.SaveIntegrations(ctx, fs); err != nil {
+		return total, err
+	}
+	if app.cfg.Follow.Enabled {
+		if err := app.follow(ctx, fs, tmpl, app.sess.Dump); err != nil {
+			return total, err
+		}
+	}
+	if len(skipped)+len(gaveUp) > 0 {
+		ids := append(skipped, gaveUp...)
+		sort.Strings(ids)
+		return total, &PartialError{Failed: ids}
+	}
 	return total, nil
 }
 
+// PartialError is returned when the run has completed, but some of the
+// conversations could not be saved.
+type PartialError struct {
+	Failed []string // IDs or links of the failed conversations
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("failed to process %d conversation(s): %s", len(e.Failed), strings.Join(e.Failed, " "))
+}
+
+// retryFailed retries the conversations in the queue q, calling fn for each of
+// them, according to the FailedRetries options.  It returns the number of
+// conversations that were successfully processed and the sorted list of the
+// ones that still fail.
+func (app *dump) retryFailed(ctx context.Context, q *network.RetryQueue, fn func(channelID string) error) (int, []string) {
+	if q.Len() == 0 {
+		return 0, nil
+	}
+	queued := q.Len()
+	app.log.Printf("retrying %d failed conversation(s): %s", queued, strings.Join(q.Items(), " "))
+	failed := q.Run(ctx, app.cfg.Options.FailedRetries, app.cfg.Options.FailedRetryDelay, fn)
+	if len(failed) == 0 {
+		return queued, nil
+	}
+	ids := make([]string, 0, len(failed))
+	for id, err := range failed {
+		app.log.Printf("giving up on %q: %s", id, err)
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	app.log.Printf("to try again, run slackdump with: %s", strings.Join(ids, " "))
+	return queued - len(failed), ids
+}
+
 type dumpFunc func(context.Context, string, time.Time, time.Time, ...slackdump.ProcessFunc) (*types.Conversation, error)
 
 // renderFilename returns the filename that is rendered according to the
@@ -121,14 +277,60 @@ func (app *dump) dumpOne(ctx context.Context, fs fsadapter.FS, filetmpl *templat
 	if err != nil {
 		return err
 	}
+	metrics.MessagesArchived.Add(countMessages(cnv.Messages))
+	app.events.Messages(cnv.ID, cnv.Messages)
+	if app.cfg.Follow.Enabled {
+		if app.followed == nil {
+			app.followed = make(map[string]*types.Conversation)
+		}
+		app.followed[channelInput] = cnv
+	}
+
+	if err := app.writeFiles(fs, renderFilename(filetmpl, cnv), cnv); err != nil {
+		return err
+	}
+	if app.transform != nil {
+		return app.transform.Add(ctx, cnv)
+	}
+	return nil
+}
 
-	return app.writeFiles(fs, renderFilename(filetmpl, cnv), cnv)
+// startTransform starts the conversion of the dumped conversations to the
+// export in the DumpExport location.  The returned function waits for the
+// conversion to finish, and closes the export.
+func (app *dump) startTransform(ctx context.Context) (func() error, error) {
+	fsc, err := fsadapter.New(app.cfg.DumpExport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise the export filesystem: %w", err)
+	}
+	app.log.Printf("converting to the export in: %s", fsc)
+	tr := export.NewTransformer(ctx, app.sess, metrics.NewFS(fsc), app.sess.Users, makeExportOptions(app.cfg))
+	app.transform = tr
+	return func() error {
+		app.transform = nil
+		if err := tr.Close(); err != nil {
+			fsc.Close()
+			return fmt.Errorf("export: %w", err)
+		}
+		return fsc.Close()
+	}, nil
+}
+
+// countMessages returns the number of messages including the thread replies.
+func countMessages(msgs []types.Message) int {
+	n := len(msgs)
+	for i := range msgs {
+		n += countMessages(msgs[i].ThreadReplies)
+	}
+	return n
 }
 
 // writeFiles writes the conversation to disk.  If text output is set, it will
-// also generate a text file having the same name as JSON file.
+// also generate a text file having the same name as JSON file.  The name is
+// sanitized with the filename profile, as it is rendered from the template.
 func (app *dump) writeFiles(fs fsadapter.FS, name string, cnv *types.Conversation) error {
-	if err := app.writeJSON(fs, name+".json", cnv); err != nil {
+	name = app.cfg.Options.FilenameProfile.Path(name)
+	if err := app.writeConversation(fs, name, cnv); err != nil {
 		return err
 	}
 	if app.cfg.Output.IsText() {
@@ -139,6 +341,204 @@ func (app *dump) writeFiles(fs fsadapter.FS, name string, cnv *types.Conversatio
 	return nil
 }
 
+// dumpIndexSuffix is the suffix of the index file of the conversation, that
+// is split by the period.
+const dumpIndexSuffix = "-index.json"
+
+// dumpIndex is the index of the parts of the conversation, that is split by
+// the period.  It has no "channel_id", so that the archive readers don't
+// take it for the conversation.
+type dumpIndex struct {
+	Name   string     `json:"name"`
+	ID     string     `json:"conversation_id"`
+	Layout string     `json:"layout"`
+	Parts  []dumpPart `json:"parts"`
+}
+
+// dumpPart is the part of the conversation in the dumpIndex.
+type dumpPart struct {
+	Period   string `json:"period"`
+	File     string `json:"file"`     // relative to the index file.
+	Messages int    `json:"messages"` // including the thread replies.
+	Oldest   string `json:"oldest"`   // timestamp of the oldest message.
+	Latest   string `json:"latest"`   // timestamp of the latest message.
+}
+
+// writeConversation writes the conversation to name.json, or, if the dump
+// layout is not single, splits it by the period of the messages into
+// name-<period>.json files, each of them is the conversation on its own, so
+// that the archive readers merge them back, and writes their index to
+// name-index.json.  The thread replies stay with their parent message, the
+// thread dumps and the empty conversations are not split.
+func (app *dump) writeConversation(fs fsadapter.FS, name string, cnv *types.Conversation) error {
+	layout := app.cfg.Output.Layout
+	if layout == config.DLSingle || cnv.ThreadTS != "" || len(cnv.Messages) == 0 {
+		return app.writeJSON(fs, name+".json", cnv)
+	}
+	parts, idx := splitConversation(name, cnv, layout)
+	for _, part := range parts {
+		if err := app.writeJSON(fs, part.name, part.cnv); err != nil {
+			return err
+		}
+	}
+	return app.writeJSON(fs, name+dumpIndexSuffix, idx)
+}
+
+// splitFile is the part file of the conversation, see splitConversation.
+type splitFile struct {
+	name string
+	cnv  *types.Conversation
+}
+
+// splitConversation splits the conversation cnv, that is saved under name, by
+// the period of the layout, and returns the part files and their index.
+func splitConversation(name string, cnv *types.Conversation, layout config.DumpLayout) ([]splitFile, dumpIndex) {
+	var (
+		files []splitFile
+		idx   = dumpIndex{Name: cnv.Name, ID: cnv.ID, Layout: layout.String()}
+	)
+	for _, part := range splitByPeriod(cnv.Messages, layout) {
+		filename := name + "-" + part.Period + ".json"
+		files = append(files, splitFile{name: filename, cnv: &types.Conversation{Name: cnv.Name, ID: cnv.ID, Messages: part.msgs}})
+		part.File = path.Base(filename)
+		idx.Parts = append(idx.Parts, part.dumpPart)
+	}
+	return files, idx
+}
+
+// splitIndex is the index file of the split conversation in the dump
+// directory.
+type splitIndex struct {
+	name string // file name, relative to the dump directory.
+	dumpIndex
+}
+
+// partName returns the name of the part file, relative to the dump directory.
+func (si *splitIndex) partName(p dumpPart) string {
+	return path.Join(path.Dir(si.name), p.File)
+}
+
+// isPart returns true, if the file name is the part of the conversation.
+func (si *splitIndex) isPart(name string) bool {
+	for _, p := range si.Parts {
+		if si.partName(p) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitIndexes returns the indexes of the split conversations in the root of
+// the dump directory dir by the channel ID, see -dump-layout.
+func splitIndexes(dir string) (map[string]*splitIndex, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	idx := make(map[string]*splitIndex)
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), dumpIndexSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		si := splitIndex{name: e.Name()}
+		if err := json.Unmarshal(data, &si.dumpIndex); err != nil || si.ID == "" || si.Layout == "" {
+			// not an index, i.e. the conversation, named "-index".
+			continue
+		}
+		idx[si.ID] = &si
+	}
+	return idx, nil
+}
+
+// refresh updates the message counts and the timestamps of the parts of the
+// split conversation in the dump directory dir, after they were rewritten,
+// the parts, that no longer exist, are removed from the index, and the index
+// without the parts is removed.
+func (si *splitIndex) refresh(dir string) error {
+	var parts []dumpPart
+	for _, p := range si.Parts {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(si.partName(p))))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		var cnv types.Conversation
+		if err := json.Unmarshal(data, &cnv); err != nil {
+			return fmt.Errorf("%s: %w", p.File, err)
+		}
+		if len(cnv.Messages) == 0 {
+			continue
+		}
+		p.Messages = countMessages(cnv.Messages)
+		p.Oldest = cnv.Messages[0].Timestamp
+		p.Latest = cnv.Messages[len(cnv.Messages)-1].Timestamp
+		parts = append(parts, p)
+	}
+	si.Parts = parts
+	filename := filepath.Join(dir, filepath.FromSlash(si.name))
+	if len(parts) == 0 {
+		return os.Remove(filename)
+	}
+	return writeIndent(filename, si.dumpIndex)
+}
+
+// refreshSplits refreshes the indexes of the split conversations with the
+// channel IDs ids in the dump directory dir, after their files were
+// rewritten, see splitIndex.refresh.
+func refreshSplits(dir string, ids map[string]bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	splits, err := splitIndexes(dir)
+	if err != nil {
+		return err
+	}
+	for id := range ids {
+		if si, ok := splits[id]; ok {
+			if err := si.refresh(dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// periodPart is the part of the conversation, returned by splitByPeriod.
+type periodPart struct {
+	dumpPart
+	msgs []types.Message
+}
+
+// splitByPeriod splits the messages msgs, sorted by the timestamp, into the
+// parts by the period of the layout l.
+func splitByPeriod(msgs []types.Message, l config.DumpLayout) []periodPart {
+	var (
+		parts []periodPart
+		byKey = make(map[string]int) // index of the part by the period
+	)
+	for _, m := range msgs {
+		t, _ := m.Datetime()
+		period := l.Period(t)
+		i, ok := byKey[period]
+		if !ok {
+			i = len(parts)
+			byKey[period] = i
+			parts = append(parts, periodPart{dumpPart: dumpPart{Period: period, Oldest: m.Timestamp}})
+		}
+		p := &parts[i]
+		p.msgs = append(p.msgs, m)
+		p.Messages += countMessages([]types.Message{m})
+		p.Latest = m.Timestamp
+	}
+	return parts
+}
+
 func (app *dump) writeJSON(fs fsadapter.FS, filename string, m any) error {
 	f, err := fs.Create(filename)
 	if err != nil {
@@ -162,7 +562,12 @@ func (app *dump) writeText(fs fsadapter.FS, filename string, m *types.Conversati
 	}
 	defer f.Close()
 
-	return m.ToText(f, app.sess.UserIndex)
+	cat := app.cfg.Output.Lang.Get()
+	return m.ToTextFormat(f, app.sess.UserIndex, types.TextFormat{
+		Location:   app.cfg.Output.TZ.Get(),
+		TimeLayout: cat.TextTime,
+		Text:       cat.MessageText,
+	})
 }
 
 // reporter is an interface defining output functions
@@ -198,7 +603,7 @@ func createFile(filename string) (f io.WriteCloser, err error) {
 		f = os.Stdout
 		return
 	}
-	return os.Create(filename)
+	return os.Create(fsadapter.LongPath(filename))
 }
 
 // fetchEntity retrieves the data from the API according to the ListFlags.
@@ -209,6 +614,11 @@ func (dm *dump) fetchEntity(ctx context.Context, listFlags config.ListFlags) (re
 		if err != nil {
 			return
 		}
+	case listFlags.Users && listFlags.Profiles:
+		rep, err = dm.sess.GetUserProfiles(ctx)
+		if err != nil {
+			return
+		}
 	case listFlags.Users:
 		rep, err = dm.sess.GetUsers(ctx)
 		if err != nil {
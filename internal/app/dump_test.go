@@ -0,0 +1,54 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func Test_dump_writeConversation(t *testing.T) {
+	dir := t.TempDir()
+	fsa := fsadapter.NewDirectory(dir)
+	app := &dump{cfg: config.Params{Output: config.Output{Layout: config.DLMonthly}}, log: logger.Silent}
+
+	cnv := &types.Conversation{Name: "general", ID: "C01", Messages: []types.Message{
+		testMsg("1672531200.000100", testMsg("1675209600.000100")), // 2023-01-01, reply in 2023-02
+		testMsg("1672617600.000100"),                               // 2023-01-02
+		testMsg("1677628800.000100"),                               // 2023-03-01
+	}}
+	require.NoError(t, app.writeConversation(fsa, "C01", cnv))
+
+	data, err := os.ReadFile(filepath.Join(dir, "C01"+dumpIndexSuffix))
+	require.NoError(t, err)
+	var idx dumpIndex
+	require.NoError(t, json.Unmarshal(data, &idx))
+	assert.Equal(t, dumpIndex{Name: "general", ID: "C01", Layout: "monthly", Parts: []dumpPart{
+		{Period: "2023-01", File: "C01-2023-01.json", Messages: 3, Oldest: "1672531200.000100", Latest: "1672617600.000100"},
+		{Period: "2023-03", File: "C01-2023-03.json", Messages: 1, Oldest: "1677628800.000100", Latest: "1677628800.000100"},
+	}}, idx)
+	assert.NoFileExists(t, filepath.Join(dir, "C01.json"))
+
+	// the archive merges the parts back.
+	ar, err := archive.Open(dir)
+	require.NoError(t, err)
+	defer ar.Close()
+	got, err := ar.Conversation("C01")
+	require.NoError(t, err)
+	require.Len(t, got.Messages, 3)
+	assert.Len(t, got.Messages[0].ThreadReplies, 1)
+
+	// the thread is not split.
+	thread := &types.Conversation{ID: "C01", ThreadTS: "1672531200.000100", Messages: cnv.Messages[:1]}
+	require.NoError(t, app.writeConversation(fsa, "C01-1672531200.000100", thread))
+	assert.FileExists(t, filepath.Join(dir, "C01-1672531200.000100.json"))
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/rusq/slackdump/v2/export"
 	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/encrypt"
+	"github.com/rusq/slackdump/v2/internal/metrics"
 )
 
 // defExportType is the default file export type, if the DumpFiles is
@@ -32,8 +34,23 @@ func Export(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 	if err != nil {
 		return err
 	}
+	if err := resolveNames(ctx, sess, &cfg.Input, cfg.Logger()); err != nil {
+		return err
+	}
+	if err := selectChannels(ctx, sess, &cfg.Input, cfg.Logger()); err != nil {
+		return err
+	}
+	if cfg.DiskSpace.Preflight {
+		ids, err := exportChannelIDs(ctx, sess, cfg.Input.List)
+		if err != nil {
+			return err
+		}
+		if err := preflight(ctx, cfg, sess, ids); err != nil {
+			return err
+		}
+	}
 
-	fs, err := fsadapter.New(cfg.ExportName)
+	fs, err := exportFS(cfg)
 	if err != nil {
 		cfg.Logger().Debugf("Export:  filesystem error: %s", err)
 		return fmt.Errorf("failed to initialise the filesystem: %w", err)
@@ -48,14 +65,44 @@ func Export(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 	cfg.Logger().Debugf("Export:  filesystem: %s", fs)
 	cfg.Logger().Printf("Export:  staring export to: %s", fs)
 
-	e := export.New(sess, fs, makeExportOptions(cfg))
+	events, closeFn, err := openStream(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+	opts := makeExportOptions(cfg)
+	if events != nil {
+		opts.Events = events
+	}
+
+	e := export.New(sess, metrics.NewFS(guardFS(ctx, cfg, fs)), opts)
 	if err := e.Run(ctx); err != nil {
+		// the pending list of the full export would contain only the
+		// interrupted conversation, so it's saved for the include lists only.
+		if slackdump.IsInterrupted(ctx, err) && opts.List.HasIncludes() {
+			if perr := writePending(ctx, cfg, e.Result().Pending()); perr != nil {
+				cfg.Logger().Printf("Export:  %s", perr)
+			}
+		}
 		return err
 	}
 
 	return nil
 }
 
+// exportFS returns the filesystem for the export.  Encrypted exports are
+// always written to the ZIP file.
+func exportFS(cfg config.Params) (fsadapter.FSCloser, error) {
+	if cfg.Encrypt == "" {
+		return fsadapter.New(cfg.ExportName)
+	}
+	enc, err := encrypt.Parse(cfg.Encrypt)
+	if err != nil {
+		return nil, err
+	}
+	return fsadapter.NewZipFileFilter(cfg.ExportFilename(), enc.Encrypt)
+}
+
 func makeExportOptions(cfg config.Params) export.Options {
 	expCfg := export.Options{
 		Oldest:      time.Time(cfg.Oldest),
@@ -64,6 +111,15 @@ func makeExportOptions(cfg config.Params) export.Options {
 		List:        cfg.Input.List,
 		Type:        cfg.ExportType,
 		ExportToken: cfg.ExportToken,
+
+		FailedRetries:    cfg.Options.FailedRetries,
+		FailedRetryDelay: cfg.Options.FailedRetryDelay,
+		FilenameProfile:  cfg.Options.FilenameProfile,
+		SynthEvents:      cfg.ExportEvents,
+		ViewerCompat:     cfg.ExportViewerCompat,
+		Layout:           cfg.ExportLayout,
+		Membership:       cfg.ExportMembership,
+		DownloadWindow:   cfg.Options.DownloadWindow,
 	}
 	// if files requested, but the type is no-download, we need to switch
 	// export type to the default export type, so that the files would
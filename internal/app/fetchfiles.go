@@ -0,0 +1,395 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/trace"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/structures/files"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/sanitize"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// exportAttachments is the directory of the downloaded files in the channel
+// directory of the standard export.
+const exportAttachments = "attachments"
+
+// FetchFiles downloads the files, that are referenced in the export or dump
+// directory dir, but were not downloaded, i.e. the archive was created
+// without -download, and updates the references to point to the downloaded
+// files, the same way as if the archive was created with -download.  This
+// way, the slow file transfer is decoupled from fetching the messages, but
+// it must be done, while the token is valid and the files are not deleted.
+// The files, that were skipped for the lack of the files:read scope, are
+// removed from the manifest.  The Mattermost exports and the ZIP archives are
+// not supported.
+func FetchFiles(ctx context.Context, cfg config.Params, prov auth.Provider, dir string) error {
+	ctx, task := trace.NewTask(ctx, "FetchFiles")
+	defer task.End()
+
+	lg := cfg.Logger()
+	if fi, err := os.Stat(dir); err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return fmt.Errorf("%s: not a directory, unpack the ZIP archive first", dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, mattermostUploads)); err == nil {
+		return fmt.Errorf("%s: the files of the Mattermost export can't be fetched", dir)
+	}
+	ar, err := archive.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	ff := &fileFetcher{dir: dir, ar: ar, lg: lg, profile: cfg.Options.FilenameProfile, jobs: make(map[string]*fetchJob)}
+	if err := ff.walk(ctx, ff.collect); err != nil {
+		return err
+	}
+	if len(ff.jobs) == 0 {
+		lg.Printf("%s: all referenced files are downloaded", dir)
+		return nil
+	}
+	lg.Printf("%s: %d file(s) to download", dir, len(ff.jobs))
+
+	cfg.Options.NoUserCache = true // the users are not needed.
+	sess, err := slackdump.NewWithOptions(ctx, prov, cfg.Options)
+	if err != nil {
+		return err
+	}
+	if !sess.CanReadFiles() {
+		return errors.New("the token has no files:read scope, the files can't be downloaded")
+	}
+	dl := downloader.New(
+		sess.API(),
+		fsadapter.NewDirectory(dir),
+		downloader.Retries(cfg.Options.DownloadRetries),
+		downloader.Logger(lg),
+		downloader.Sanitize(cfg.Options.FilenameProfile),
+		downloader.Schedule(cfg.Options.DownloadWindow),
+	)
+	nDone := ff.download(ctx, dl, cfg.Options.Workers)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if nDone == 0 {
+		return fmt.Errorf("none of %d file(s) were downloaded", len(ff.jobs))
+	}
+	if err := ff.walk(ctx, ff.update); err != nil {
+		return err
+	}
+	if err := ff.updateManifest(); err != nil {
+		return err
+	}
+	summary := fmt.Sprintf("%d of %d file(s) downloaded", nDone, len(ff.jobs))
+	lg.Printf("%s, %d conversation file(s) updated", summary, ff.nRewritten)
+	if _, err := os.Stat(ManifestName(dir)); err == nil {
+		lg.Printf("warning: the archive has changed, the signature %s is no longer valid, sign the archive again", ManifestName(dir)+signatureExt)
+	}
+	return slackdump.AppendCustody(fsadapter.NewDirectory(dir), slackdump.NewCustodyRecord(slackdump.CustodyFetched, summary))
+}
+
+// fetchJob is the file to download.
+type fetchJob struct {
+	file slack.File
+	dir  string // slash separated directory, relative to the archive root.
+	done bool
+}
+
+// fileFetcher downloads the files, referenced in the archive directory.
+type fileFetcher struct {
+	dir     string
+	ar      *archive.Archive
+	lg      logger.Interface
+	profile sanitize.Profile
+
+	// jobs are the files to download by the slash separated target path,
+	// relative to the archive root.
+	jobs       map[string]*fetchJob
+	nRewritten int
+}
+
+// visitFunc is called for each message file of the archive with the
+// messages msgs.  base is the directory, the local paths of the files are
+// relative to, and dir is the directory, the files are downloaded to, both
+// are relative to the archive root.  It returns true, if the messages were
+// changed.
+type visitFunc func(msgs []types.Message, base, dir string) (bool, error)
+
+// walk calls fn for the messages of the conversation files of the dump, or
+// the daily files of the export, and rewrites the files, that were changed.
+func (ff *fileFetcher) walk(ctx context.Context, fn visitFunc) error {
+	switch ff.ar.Type() {
+	case archive.TExport:
+		return ff.walkExport(ctx, fn)
+	case archive.TDump:
+		return ff.walkDump(ctx, fn)
+	default:
+		return archive.ErrUnknownFormat
+	}
+}
+
+// walkExport walks the daily files of the export channels, the files are
+// downloaded into the attachments directory of the channel.  The messages are
+// rewritten with only their files updated, so that the fields unknown to
+// slackdump are not lost.
+func (ff *fileFetcher) walkExport(ctx context.Context, fn visitFunc) error {
+	chans, err := ff.ar.Channels()
+	if err != nil {
+		return err
+	}
+	for _, ch := range chans {
+		chDir := archive.ExportDir(ff.ar.FS(), ch)
+		entries, err := fs.ReadDir(ff.ar.FS(), chDir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		for _, e := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			name := path.Join(chDir, e.Name())
+			if err := ff.visitExportDay(name, chDir, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (ff *fileFetcher) visitExportDay(name string, chDir string, fn visitFunc) error {
+	data, err := os.ReadFile(ff.path(name))
+	if err != nil {
+		return err
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	var changed bool
+	for i, r := range raw {
+		var m types.Message
+		if err := json.Unmarshal(r, &m); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		msgs := []types.Message{m}
+		ok, err := fn(msgs, chDir, path.Join(chDir, exportAttachments))
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if !ok {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(r, &fields); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if fields["files"], err = json.Marshal(msgs[0].Files); err != nil {
+			return err
+		}
+		if raw[i], err = json.Marshal(fields); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return ff.rewrite(name, raw)
+}
+
+// walkDump walks the conversation files of the dump, the files are downloaded
+// into the channel ID directory.
+func (ff *fileFetcher) walkDump(ctx context.Context, fn visitFunc) error {
+	rewritten := make(map[string]bool) // channel IDs
+	if err := walkDumpFiles(ctx, ff.ar, func(name string, cnv *types.Conversation) error {
+		changed, err := fn(cnv.Messages, "", cnv.ID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if !changed {
+			return nil
+		}
+		rewritten[cnv.ID] = true
+		return ff.rewrite(name, cnv)
+	}); err != nil {
+		return err
+	}
+	// the split conversations, see -dump-layout.
+	return refreshSplits(ff.dir, rewritten)
+}
+
+// isRemote returns true, if the file f is not downloaded, and can be.
+func isRemote(f slack.File) bool {
+	if f.Mode == "hidden_by_limit" || f.Mode == "external" || f.IsExternal || f.Mode == "tombstone" {
+		return false
+	}
+	return strings.HasPrefix(f.URLPrivateDownload, "https://") || strings.HasPrefix(f.URLPrivateDownload, "http://")
+}
+
+// target returns the slash separated path of the downloaded file f in the
+// directory dir, the same as the downloader uses.
+func (ff *fileFetcher) target(f slack.File, dir string) string {
+	return path.Join(dir, ff.profile.Name(downloader.Filename(&f)))
+}
+
+// collect is the visitFunc, that adds the files of the messages, that are not
+// downloaded, to the jobs.
+func (ff *fileFetcher) collect(msgs []types.Message, _, dir string) (bool, error) {
+	err := files.Extract(msgs, files.Root, func(f slack.File, _ files.Addr) error {
+		if !isRemote(f) {
+			return nil
+		}
+		if t := ff.target(f, dir); ff.jobs[t] == nil {
+			ff.jobs[t] = &fetchJob{file: f, dir: dir}
+		}
+		return nil
+	})
+	return false, err
+}
+
+// update is the visitFunc, that points the references to the downloaded
+// files.
+func (ff *fileFetcher) update(msgs []types.Message, base, dir string) (bool, error) {
+	var changed bool
+	err := files.Extract(msgs, files.Root, func(f slack.File, addr files.Addr) error {
+		if !isRemote(f) {
+			return nil
+		}
+		t := ff.target(f, dir)
+		if job := ff.jobs[t]; job == nil || !job.done {
+			return nil
+		}
+		rel := strings.TrimPrefix(t, base+"/")
+		changed = true
+		return files.Update(msgs, addr, files.UpdatePathFn(rel))
+	})
+	return changed, err
+}
+
+// download downloads the files of the jobs with n workers, and returns the
+// number of the downloaded files.  The files, that fail to download, are
+// logged and skipped.
+func (ff *fileFetcher) download(ctx context.Context, dl *downloader.Client, n int) int {
+	if n <= 0 {
+		n = 1
+	}
+	targets := make([]string, 0, len(ff.jobs))
+	for t := range ff.jobs {
+		targets = append(targets, t)
+	}
+	sort.Strings(targets)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		nDone int
+		jobC  = make(chan *fetchJob)
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobC {
+				f := job.file
+				if _, err := dl.SaveFile(ctx, job.dir, &f); err != nil {
+					if ctx.Err() == nil {
+						ff.lg.Printf("%s: failed to download %s: %s", job.dir, f.ID, err)
+					}
+					continue
+				}
+				ff.lg.Debugf("%s: downloaded %s", job.dir, f.ID)
+				mu.Lock()
+				job.done = true
+				nDone++
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, t := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		jobC <- ff.jobs[t]
+	}
+	close(jobC)
+	wg.Wait()
+	return nDone
+}
+
+// updateManifest removes the downloaded files from the files, skipped for the
+// lack of the files:read scope, in the manifest of the archive, if it has one.
+func (ff *fileFetcher) updateManifest() error {
+	data, err := os.ReadFile(ff.path(slackdump.ManifestFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var m slackdump.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("%s: %w", slackdump.ManifestFile, err)
+	}
+	if len(m.FilesSkipped) == 0 {
+		return nil
+	}
+	fetched := make(map[string]bool)
+	for _, job := range ff.jobs {
+		if job.done {
+			fetched[job.file.ID] = true
+		}
+	}
+	skipped := m.FilesSkipped[:0]
+	for _, id := range m.FilesSkipped {
+		if !fetched[id] {
+			skipped = append(skipped, id)
+		}
+	}
+	m.FilesSkipped = skipped
+	data, err = marshalIndent(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ff.path(slackdump.ManifestFile), data, 0666)
+}
+
+// rewrite writes v to the archive file name.
+func (ff *fileFetcher) rewrite(name string, v any) error {
+	data, err := marshalIndent(v)
+	if err != nil {
+		return err
+	}
+	ff.lg.Debugf("%s: updating the file references", name)
+	ff.nRewritten++
+	return os.WriteFile(ff.path(name), data, 0666)
+}
+
+// path returns the OS path of the slash separated name in the archive.
+func (ff *fileFetcher) path(name string) string {
+	return filepath.Join(ff.dir, filepath.FromSlash(name))
+}
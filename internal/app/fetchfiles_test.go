@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// fakeGetter returns the URL as the file contents, the URLs containing
+// "fail" fail.
+type fakeGetter struct{}
+
+func (fakeGetter) GetFile(url string, w io.Writer) error {
+	if strings.Contains(url, "fail") {
+		return errors.New("download failed")
+	}
+	_, err := io.WriteString(w, url)
+	return err
+}
+
+func fetchAll(t *testing.T, dir string) *fileFetcher {
+	t.Helper()
+	ar, err := archive.Open(dir)
+	require.NoError(t, err)
+	defer ar.Close()
+
+	ctx := context.Background()
+	ff := &fileFetcher{dir: dir, ar: ar, lg: logger.Silent, jobs: make(map[string]*fetchJob)}
+	require.NoError(t, ff.walk(ctx, ff.collect))
+	dl := downloader.New(fakeGetter{}, fsadapter.NewDirectory(dir), downloader.Retries(1), downloader.Logger(logger.Silent))
+	ff.download(ctx, dl, 2)
+	require.NoError(t, ff.walk(ctx, ff.update))
+	require.NoError(t, ff.updateManifest())
+	return ff
+}
+
+func TestFileFetcher(t *testing.T) {
+	const (
+		remote = `{"id": "F01", "name": "a.txt", "url_private": "https://files.slack.com/a", "url_private_download": "https://files.slack.com/a"}`
+		failed = `{"id": "F02", "name": "b.txt", "url_private": "https://files.slack.com/fail", "url_private_download": "https://files.slack.com/fail"}`
+		local  = `{"id": "F03", "name": "c.txt", "url_private": "attachments/F03-c.txt"}`
+		hidden = `{"id": "F04", "mode": "hidden_by_limit"}`
+	)
+	t.Run("export", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{
+			"channels.json":                 `[{"id": "C01", "name": "general"}]`,
+			"users.json":                    `[]`,
+			"general/2023-01-01.json":       `[{"ts": "1672531200.000100", "text": "hi", "x_unknown": 1, "files": [` + remote + `, ` + failed + `, ` + local + `, ` + hidden + `]}]`,
+			"general/attachments/F03-c.txt": "c",
+		})
+		ff := fetchAll(t, dir)
+		assert.Len(t, ff.jobs, 2)
+		assert.Equal(t, 1, ff.nRewritten)
+
+		data, err := os.ReadFile(filepath.Join(dir, "general", "attachments", "F01-a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "https://files.slack.com/a", string(data))
+
+		data, err = os.ReadFile(filepath.Join(dir, "general", "2023-01-01.json"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"url_private": "attachments/F01-a.txt"`)
+		assert.Contains(t, string(data), `"url_private": "https://files.slack.com/fail"`, "the failed file must not be updated")
+		assert.Contains(t, string(data), "x_unknown", "the unknown fields must be kept")
+	})
+	t.Run("dump", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{
+			"C01.json": `{"name": "general", "channel_id": "C01", "messages": [` +
+				`{"ts": "1672531200.000100", "text": "parent", "thread_ts": "1672531200.000100", "slackdump_thread_replies": [` +
+				`{"ts": "1672531201.000100", "text": "reply", "thread_ts": "1672531200.000100", "files": [` + remote + `]}]},` +
+				`{"ts": "1672531202.000100", "text": "again", "files": [` + remote + `, ` + failed + `]}]}`,
+			"users.json":           `[]`,
+			slackdump.ManifestFile: `{"files_skipped": ["F01", "F02"]}`,
+		})
+		ff := fetchAll(t, dir)
+		assert.Len(t, ff.jobs, 2, "the same file must be downloaded once")
+		assert.FileExists(t, filepath.Join(dir, "C01", "F01-a.txt"))
+
+		data, err := os.ReadFile(filepath.Join(dir, "C01.json"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, strings.Count(string(data), `"url_private": "C01/F01-a.txt"`), "both references must be updated")
+
+		data, err = os.ReadFile(filepath.Join(dir, slackdump.ManifestFile))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"F02"`)
+		assert.NotContains(t, string(data), `"F01"`)
+	})
+	t.Run("nothing to fetch", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{
+			"C01.json":   `{"name": "general", "channel_id": "C01", "messages": [{"ts": "1672531200.000100", "text": "hi", "files": [` + hidden + `]}]}`,
+			"users.json": `[]`,
+		})
+		ff := fetchAll(t, dir)
+		assert.Empty(t, ff.jobs)
+		assert.Zero(t, ff.nRewritten)
+	})
+}
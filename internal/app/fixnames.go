@@ -0,0 +1,201 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/sanitize"
+)
+
+// FixNames renames the files and directories of the archive directory dir,
+// that are invalid under the filename profile p, and updates the references
+// to them, i.e. the attachment paths and the channel names, in the JSON files
+// of the archive.  If dryRun is true, the renames are only logged.  The ZIP
+// archives are not supported, they should be unpacked first.
+func FixNames(ctx context.Context, dir string, p sanitize.Profile, dryRun bool, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	if fi, err := os.Stat(dir); err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return fmt.Errorf("%s: not a directory, unpack the ZIP archive first", dir)
+	}
+	// entries are the paths of the files and directories, relative to dir,
+	// slash separated.
+	var entries []string
+	if err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		if rel != "." {
+			entries = append(entries, filepath.ToSlash(rel))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	// the deepest entries are renamed first, so that the paths of their
+	// parents are still valid.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.Count(entries[i], "/") > strings.Count(entries[j], "/")
+	})
+
+	var (
+		taken = make(map[string]bool) // new paths, for the dry run
+		refs  = make(map[string]string)
+		n     int
+	)
+	for _, e := range entries {
+		parent, old := path.Split(e)
+		name := p.Name(old)
+		if name == old {
+			continue
+		}
+		name = uniqueName(dir, parent, name, taken)
+		lg.Printf("%s -> %s", e, parent+name)
+		if !dryRun {
+			if err := os.Rename(filepath.Join(dir, filepath.FromSlash(e)), filepath.Join(dir, filepath.FromSlash(parent+name))); err != nil {
+				return err
+			}
+		}
+		n++
+		if prev, seen := refs[old]; !seen {
+			refs[old] = name
+		} else if prev != name && prev != "" {
+			// the same name was renamed differently in different
+			// directories, the references can't be updated reliably.
+			lg.Printf("warning: %q is renamed to both %q and %q, the references to it are not updated", old, prev, name)
+			refs[old] = ""
+		}
+	}
+	if dryRun || n == 0 {
+		lg.Printf("%d name(s) to fix", n)
+		return nil
+	}
+	updated, err := updateRefs(ctx, dir, refs)
+	if err != nil {
+		return err
+	}
+	lg.Printf("renamed %d file(s) and directories, updated references in %d JSON file(s)", n, updated)
+	return slackdump.AppendCustody(fsadapter.NewDirectory(dir), slackdump.NewCustodyRecord(slackdump.CustodyRenamed, fmt.Sprintf("%d name(s) fixed with the %s profile", n, p)))
+}
+
+// uniqueName returns the name, that does not exist yet in the parent
+// directory, by appending "~N" to the name, before the extension.
+func uniqueName(dir, parent, name string, taken map[string]bool) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 1; ; i++ {
+		p := parent + candidate
+		if _, err := os.Lstat(filepath.Join(dir, filepath.FromSlash(p))); errors.Is(err, fs.ErrNotExist) && !taken[p] {
+			taken[p] = true
+			return candidate
+		}
+		candidate = base + "~" + strconv.Itoa(i) + ext
+	}
+}
+
+// updateRefs replaces the renamed names in all JSON files of the directory
+// dir.  The name is replaced, if it is the whole JSON string, or the path
+// element of it.  It returns the number of updated files.
+func updateRefs(ctx context.Context, dir string, refs map[string]string) (int, error) {
+	var updated int
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !strings.EqualFold(filepath.Ext(name), ".json") {
+			return nil
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		res := data
+		for old, new := range refs {
+			if new == "" {
+				continue
+			}
+			res = replaceRef(res, old, new)
+		}
+		if bytes.Equal(res, data) {
+			return nil
+		}
+		updated++
+		return os.WriteFile(name, res, 0666)
+	})
+	return updated, err
+}
+
+// replaceRef replaces the name old with new in the JSON data, if it is
+// enclosed in the quotes or slashes.  Both the HTML-escaped encoding of the
+// name, that encoding/json uses by default, and the unescaped one are
+// replaced.
+func replaceRef(data []byte, old, new string) []byte {
+	for _, html := range []bool{true, false} {
+		o, n := jsonString(old, html), jsonString(new, html)
+		var buf bytes.Buffer
+		rest := data
+		for {
+			i := bytes.Index(rest, o)
+			if i < 0 {
+				break
+			}
+			end := i + len(o)
+			if i > 0 && isRefDelim(rest[i-1]) && end < len(rest) && isRefDelim(rest[end]) {
+				buf.Write(rest[:i])
+				buf.Write(n)
+			} else {
+				buf.Write(rest[:end])
+			}
+			rest = rest[end:]
+		}
+		if buf.Len() > 0 {
+			buf.Write(rest)
+			data = buf.Bytes()
+		}
+	}
+	return data
+}
+
+func isRefDelim(b byte) bool {
+	return b == '"' || b == '/'
+}
+
+// jsonString returns the JSON encoding of s without the quotes.
+func jsonString(s string, escapeHTML bool) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(escapeHTML)
+	if err := enc.Encode(s); err != nil {
+		// this should never happen, strings are always encodable.
+		panic(err)
+	}
+	b := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	return b[1 : len(b)-1]
+}
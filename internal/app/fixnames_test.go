@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/sanitize"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+		require.NoError(t, os.WriteFile(name, []byte(content), 0644))
+	}
+}
+
+func TestFixNames(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test archive has names, that are invalid on windows")
+	}
+	const msgs = `[{"text": "see report: q1", "files": [{"name": "report: q1", "url_private": "attachments/F01-report: q1"}]}]`
+	files := map[string]string{
+		"channels.json":                      `[{"id": "C01", "name": "general"}, {"id": "C02", "name": "aux"}]`,
+		"general/2023-01-01.json":            msgs,
+		"general/attachments/F01-report: q1": "data",
+		"general/attachments/F02-x&y<1>.txt": "data",
+		"general/attachments/F02-x&y_1_.txt": "clash",
+		"aux/2023-01-02.json":                `[{"files": [{"url_private": "attachments/F02-x&y<1>.txt"}]}]`,
+		"aux/attachments/F03-fine.txt":       "data",
+	}
+	t.Run("dry run", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, files)
+		require.NoError(t, FixNames(context.Background(), dir, sanitize.WindowsSafe, true, logger.Silent))
+		assert.FileExists(t, filepath.Join(dir, "general", "attachments", "F01-report: q1"))
+	})
+	t.Run("fix", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, files)
+		require.NoError(t, FixNames(context.Background(), dir, sanitize.WindowsSafe, false, logger.Silent))
+
+		assert.FileExists(t, filepath.Join(dir, "general", "attachments", "F01-report_ q1"))
+		assert.FileExists(t, filepath.Join(dir, "general", "attachments", "F02-x&y_1_~1.txt"))
+		assert.FileExists(t, filepath.Join(dir, "_aux", "attachments", "F03-fine.txt"))
+		assert.NoDirExists(t, filepath.Join(dir, "aux"))
+
+		data, err := os.ReadFile(filepath.Join(dir, "general", "2023-01-01.json"))
+		require.NoError(t, err)
+		assert.Equal(t, `[{"text": "see report: q1", "files": [{"name": "report: q1", "url_private": "attachments/F01-report_ q1"}]}]`, string(data))
+
+		data, err = os.ReadFile(filepath.Join(dir, "_aux", "2023-01-02.json"))
+		require.NoError(t, err)
+		assert.Equal(t, `[{"files": [{"url_private": "attachments/F02-x&y_1_~1.txt"}]}]`, string(data))
+
+		data, err = os.ReadFile(filepath.Join(dir, "channels.json"))
+		require.NoError(t, err)
+		assert.Equal(t, `[{"id": "C01", "name": "general"}, {"id": "C02", "name": "_aux"}]`, string(data))
+
+		data, err = os.ReadFile(filepath.Join(dir, slackdump.CustodyFile))
+		require.NoError(t, err)
+		var rec slackdump.CustodyRecord
+		require.NoError(t, json.Unmarshal(data, &rec))
+		assert.Equal(t, slackdump.CustodyRenamed, rec.Operation)
+	})
+	t.Run("not a directory", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "export.zip")
+		require.NoError(t, os.WriteFile(name, nil, 0644))
+		assert.Error(t, FixNames(context.Background(), name, sanitize.WindowsSafe, false, logger.Silent))
+	})
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"sort"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/metrics"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// follow keeps polling the dumped conversations for the new messages every
+// Follow.Interval, and updates the conversation files, until ctx is
+// cancelled.  New replies to the threads are picked up only for the threads
+// that were dumped by link, or the ones that are started after the last
+// message of the channel.
+func (app *dump) follow(ctx context.Context, fs fsadapter.FS, tmpl *template.Template, fn dumpFunc) error {
+	if len(app.followed) == 0 {
+		app.log.Printf("follow: nothing to follow")
+		return nil
+	}
+	links := make([]string, 0, len(app.followed))
+	for link := range app.followed {
+		links = append(links, link)
+	}
+	sort.Strings(links)
+
+	app.log.Printf("follow: polling %d conversation(s) for new messages every %s, press Ctrl+C to stop", len(links), app.cfg.Follow.Interval)
+	t := time.NewTicker(app.cfg.Follow.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			app.log.Printf("follow: stopped")
+			return nil
+		case <-t.C:
+		}
+		for _, link := range links {
+			if ctx.Err() != nil {
+				break
+			}
+			n, err := app.poll(ctx, fs, tmpl, link, fn)
+			if err != nil {
+				if ctx.Err() == nil {
+					app.log.Printf("follow: error polling %q (will try again): %s", link, err)
+				}
+				continue
+			}
+			if n > 0 {
+				app.log.Printf("follow: %d new message(s) in %q", n, link)
+				if err := slackdump.AppendCustody(fs, slackdump.NewCustodyRecord(slackdump.CustodyUpdated, fmt.Sprintf("%d new message(s) in %s", n, link))); err != nil {
+					app.log.Printf("follow: error updating the custody log: %s", err)
+				}
+			}
+		}
+	}
+}
+
+// poll fetches the new messages of the conversation link, merges them with
+// the known messages, and rewrites the conversation files if there is
+// anything new.  It returns the number of new messages.
+func (app *dump) poll(ctx context.Context, fs fsadapter.FS, tmpl *template.Template, link string, fn dumpFunc) (int, error) {
+	known := app.followed[link]
+	oldest := time.Time(app.cfg.Oldest)
+	if sl, err := structures.ParseLink(link); err == nil && !sl.IsThread() && len(known.Messages) > 0 {
+		// fetch from the last known message, so that the new replies of
+		// its thread are also picked up.
+		last, err := structures.ParseSlackTS(known.Messages[len(known.Messages)-1].Timestamp)
+		if err != nil {
+			return 0, err
+		}
+		oldest = last
+	}
+	fresh, err := fn(ctx, link, oldest, time.Time{})
+	if err != nil {
+		return 0, err
+	}
+	updated := updatedMessages(known.Messages, fresh.Messages)
+	merged, n := mergeMessages(known.Messages, fresh.Messages)
+	if n == 0 {
+		return 0, nil
+	}
+	app.events.Messages(known.ID, updated)
+	known.Messages = merged
+	if err := app.writeFiles(fs, renderFilename(tmpl, known), known); err != nil {
+		return 0, err
+	}
+	metrics.MessagesArchived.Add(n)
+	return n, nil
+}
+
+// updatedMessages returns the fresh messages that are not known, or have new
+// thread replies.
+func updatedMessages(known, fresh []types.Message) []types.Message {
+	replies := make(map[string]int, len(known))
+	for _, m := range known {
+		replies[m.Timestamp] = len(m.ThreadReplies)
+	}
+	var updated []types.Message
+	for _, m := range fresh {
+		if n, ok := replies[m.Timestamp]; !ok || len(m.ThreadReplies) > n {
+			updated = append(updated, m)
+		}
+	}
+	return updated
+}
+
+// mergeMessages merges the fresh messages into known ones.  Known messages
+// are replaced with the fresh version, except for the deleted ones, that are
+// kept, see keepDeleted.  It returns the merged messages
+// sorted by timestamp, and the number of new messages and thread replies.
+func mergeMessages(known, fresh []types.Message) ([]types.Message, int) {
+	idx := make(map[string]int, len(known))
+	for i, m := range known {
+		idx[m.Timestamp] = i
+	}
+	merged := append([]types.Message{}, known...)
+	added := 0
+	for _, m := range fresh {
+		i, ok := idx[m.Timestamp]
+		if !ok {
+			idx[m.Timestamp] = len(merged)
+			merged = append(merged, m)
+			added += 1 + len(m.ThreadReplies)
+			continue
+		}
+		if d := len(m.ThreadReplies) - len(merged[i].ThreadReplies); d > 0 {
+			added += d
+		}
+		if m.IsTombstone() && !merged[i].IsTombstone() {
+			m = keepDeleted(merged[i], m)
+		}
+		merged[i] = m
+	}
+	types.SortMessages(merged)
+	return merged, added
+}
+
+// keepDeleted returns the known message, that was deleted since it was
+// archived, marked as the tombstone, with the thread of the tombstone t, so
+// that the archived text is not replaced with the placeholder.
+func keepDeleted(known, t types.Message) types.Message {
+	known.SubType = types.SubTypeTombstone
+	known.Hidden = true
+	known.ReplyCount = t.ReplyCount
+	known.LatestReply = t.LatestReply
+	known.ThreadReplies = t.ThreadReplies
+	return known
+}
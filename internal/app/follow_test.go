@@ -0,0 +1,114 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/stream"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func testMsg(ts string, replies ...types.Message) types.Message {
+	return types.Message{Message: slack.Message{Msg: slack.Msg{Timestamp: ts, Text: "msg " + ts}}, ThreadReplies: replies}
+}
+
+func Test_mergeMessages(t *testing.T) {
+	known := []types.Message{testMsg("1.000001"), testMsg("2.000001")}
+	fresh := []types.Message{testMsg("2.000001", testMsg("2.500001")), testMsg("3.000001")}
+
+	merged, added := mergeMessages(known, fresh)
+	assert.Equal(t, 2, added, "one new message and one new reply")
+	require.Len(t, merged, 3)
+	assert.Equal(t, "3.000001", merged[2].Timestamp)
+	assert.Len(t, merged[1].ThreadReplies, 1)
+	assert.Len(t, known[1].ThreadReplies, 0, "known messages must not be modified")
+
+	_, added = mergeMessages(merged, fresh)
+	assert.Equal(t, 0, added)
+}
+
+func Test_mergeMessages_tombstone(t *testing.T) {
+	known := []types.Message{testMsg("1.000001", testMsg("1.500001"))}
+	tomb := testMsg("1.000001", testMsg("1.500001"), testMsg("1.600001"))
+	tomb.SubType, tomb.Text = types.SubTypeTombstone, types.TombstoneText
+
+	merged, added := mergeMessages(known, []types.Message{tomb})
+	assert.Equal(t, 1, added)
+	require.Len(t, merged, 1)
+	assert.True(t, merged[0].IsTombstone())
+	assert.Equal(t, "msg 1.000001", merged[0].Text, "archived text is kept")
+	assert.Len(t, merged[0].ThreadReplies, 2)
+	assert.False(t, known[0].IsTombstone(), "known messages must not be modified")
+}
+
+func Test_updatedMessages(t *testing.T) {
+	known := []types.Message{testMsg("1.000001"), testMsg("2.000001")}
+	fresh := []types.Message{testMsg("1.000001"), testMsg("2.000001", testMsg("2.500001")), testMsg("3.000001")}
+
+	updated := updatedMessages(known, fresh)
+	require.Len(t, updated, 2, "message with the new reply and the new message")
+	assert.Equal(t, "2.000001", updated[0].Timestamp)
+	assert.Equal(t, "3.000001", updated[1].Timestamp)
+}
+
+func Test_dump_poll(t *testing.T) {
+	dir := t.TempDir()
+	fsa := fsadapter.NewDirectory(dir)
+	cfg := config.Params{FilenameTemplate: "{{.ID}}", Follow: config.FollowParams{Enabled: true, Interval: time.Second}}
+	tmpl, err := cfg.CompileTemplates()
+	require.NoError(t, err)
+	var events bytes.Buffer
+	app := &dump{
+		cfg:    cfg,
+		log:    logger.Silent,
+		events: stream.NewWriter(nopWriteCloser{&events}),
+		followed: map[string]*types.Conversation{
+			"C01": {ID: "C01", Messages: []types.Message{testMsg("1672531200.000100")}},
+		},
+	}
+
+	var gotOldest time.Time
+	fn := func(ctx context.Context, link string, oldest, latest time.Time, _ ...slackdump.ProcessFunc) (*types.Conversation, error) {
+		gotOldest = oldest
+		return &types.Conversation{ID: link, Messages: []types.Message{testMsg("1672531200.000100"), testMsg("1672531300.000100")}}, nil
+	}
+
+	n, err := app.poll(context.Background(), fsa, tmpl, "C01", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, int64(1672531200), gotOldest.Unix(), "must fetch from the last known message")
+
+	data, err := os.ReadFile(filepath.Join(dir, "C01.json"))
+	require.NoError(t, err)
+	var cnv types.Conversation
+	require.NoError(t, json.Unmarshal(data, &cnv))
+	assert.Len(t, cnv.Messages, 2)
+	assert.Equal(t, 1, bytes.Count(events.Bytes(), []byte("\n")), "only the new message is streamed")
+	assert.Contains(t, events.String(), `"ts":"1672531300.000100"`)
+
+	// nothing new, file is not rewritten.
+	require.NoError(t, os.Remove(filepath.Join(dir, "C01.json")))
+	n, err = app.poll(context.Background(), fsa, tmpl, "C01", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	_, err = os.Stat(filepath.Join(dir, "C01.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/hold"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// HoldAdd places the legal hold h on the archive directory dir.  The
+// messages under the hold are not removed by Prune.
+func HoldAdd(dir string, h hold.Hold, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	hs, err := loadHolds(dir)
+	if err != nil {
+		return err
+	}
+	if h.Created.IsZero() {
+		h.Created = time.Now().UTC()
+	}
+	if err := hs.Add(h); err != nil {
+		return err
+	}
+	if err := hold.Save(dir, hs); err != nil {
+		return err
+	}
+	lg.Printf("hold added: %s", h)
+	return slackdump.AppendCustody(fsadapter.NewDirectory(dir), slackdump.NewCustodyRecord(slackdump.CustodyHold, h.String()))
+}
+
+// HoldRemove releases the legal hold with the name from the archive
+// directory dir.
+func HoldRemove(dir string, name string, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	hs, err := loadHolds(dir)
+	if err != nil {
+		return err
+	}
+	h, err := hs.Remove(name)
+	if err != nil {
+		return err
+	}
+	if err := hold.Save(dir, hs); err != nil {
+		return err
+	}
+	lg.Printf("hold released: %s", h)
+	return slackdump.AppendCustody(fsadapter.NewDirectory(dir), slackdump.NewCustodyRecord(slackdump.CustodyReleased, h.String()))
+}
+
+// HoldList writes the legal holds of the archive directory dir to w.
+func HoldList(w io.Writer, dir string) error {
+	hs, err := loadHolds(dir)
+	if err != nil {
+		return err
+	}
+	if len(hs) == 0 {
+		_, err := fmt.Fprintln(w, "no legal holds")
+		return err
+	}
+	for _, h := range hs {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", h.Created.Format(time.RFC3339), h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadHolds(dir string) (hold.Holds, error) {
+	if fi, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("%s: not a directory, the holds can be placed only on the archive directory", dir)
+	}
+	return hold.Load(dir)
+}
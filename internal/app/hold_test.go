@@ -0,0 +1,71 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/hold"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func TestHold(t *testing.T) {
+	dir := t.TempDir()
+	const (
+		old   = "1500000000.000100" // 2017-07-14
+		older = "1400000000.000100" // 2014-05-13
+	)
+	writeTree(t, dir, map[string]string{
+		"channels.json":           `[{"id": "C01", "name": "general"}]`,
+		"users.json":              `[]`,
+		"general/2017-07-14.json": `[{"ts": "` + old + `", "user": "U01", "text": "held"}, {"ts": "` + old + `", "user": "U02", "text": "not held"}]`,
+		"general/2014-05-13.json": `[{"ts": "` + older + `", "user": "U01", "text": "before the hold range"}]`,
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, HoldList(&buf, dir))
+	assert.Equal(t, "no legal holds\n", buf.String())
+
+	h := hold.Hold{
+		Name:   "case-1",
+		Reason: "litigation",
+		Users:  []string{"U01"},
+		From:   time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, HoldAdd(dir, h, logger.Silent))
+	assert.Error(t, HoldAdd(dir, h, logger.Silent), "duplicate hold")
+
+	buf.Reset()
+	require.NoError(t, HoldList(&buf, dir))
+	assert.Contains(t, buf.String(), "case-1 (users: U01; from: 2017-01-01T00:00:00Z): litigation")
+
+	require.NoError(t, Prune(context.Background(), dir, config.Retention{Years: 1}, false, false, logger.Silent))
+	recs := readCustodyLog(t, dir)
+	require.Len(t, recs, 2)
+	assert.Equal(t, slackdump.CustodyHold, recs[0].Operation)
+	assert.Contains(t, recs[1].Details, "2 message(s)")
+	assert.Contains(t, recs[1].Details, "kept under the legal holds: case-1: 1 message(s)")
+
+	ar := filepath.Join(dir, "general", "2017-07-14.json")
+	assert.FileExists(t, ar)
+	assert.NoFileExists(t, filepath.Join(dir, "general", "2014-05-13.json"))
+
+	require.NoError(t, HoldRemove(dir, "case-1", logger.Silent))
+	assert.Error(t, HoldRemove(dir, "case-1", logger.Silent))
+	require.NoError(t, Prune(context.Background(), dir, config.Retention{Years: 1}, false, false, logger.Silent))
+	assert.NoFileExists(t, ar, fmt.Sprintf("%s should be pruned after the hold is released", ar))
+
+	recs = readCustodyLog(t, dir)
+	require.Len(t, recs, 4)
+	assert.Equal(t, slackdump.CustodyReleased, recs[2].Operation)
+
+	assert.Error(t, HoldAdd(filepath.Join(dir, "users.json"), h, logger.Silent), "not a directory")
+}
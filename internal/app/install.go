@@ -0,0 +1,216 @@
+package app
+
+// In this file: installation of the scheduled unattended runs.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// InstallConfig is the configuration of the scheduled unattended runs of
+// slackdump, i.e. the nightly export.
+type InstallConfig struct {
+	Name       string   // name of the service and the timer, or the task
+	Executable string   // absolute path of the slackdump executable
+	Args       []string // arguments of the run, i.e. "-export", "/srv/slack.zip"
+	CacheDir   string   // cache directory with the saved credentials, empty - the default
+	Workspace  string   // workspace of the saved credentials, empty - the current one
+	Schedule   string   // hourly, daily or weekly
+	At         string   // time of the run, "HH:MM", for the hourly runs only the minutes are used
+	User       string   // user to run as, systemd only, empty - root
+	EnvFile    string   // environment file with the SLACK_TOKEN and COOKIE, systemd only
+}
+
+// schedules are the supported schedules of the runs.
+var schedules = []string{"hourly", "daily", "weekly"}
+
+func (c InstallConfig) validate() error {
+	if c.Name == "" || strings.ContainsAny(c.Name, `/\ `) {
+		return fmt.Errorf("invalid service name: %q", c.Name)
+	}
+	if !filepath.IsAbs(c.Executable) {
+		return fmt.Errorf("executable path must be absolute: %q", c.Executable)
+	}
+	if len(c.Args) == 0 {
+		return errors.New("arguments of the run are required, i.e. -export /srv/slack.zip")
+	}
+	found := false
+	for _, s := range schedules {
+		found = found || s == c.Schedule
+	}
+	if !found {
+		return fmt.Errorf("invalid schedule: %q, must be one of %v", c.Schedule, schedules)
+	}
+	if _, _, err := c.time(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// time returns the hour and the minute of the run.
+func (c InstallConfig) time() (int, int, error) {
+	hh, mm, ok := strings.Cut(c.At, ":")
+	h, herr := strconv.Atoi(hh)
+	m, merr := strconv.Atoi(mm)
+	if !ok || herr != nil || merr != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, 0, fmt.Errorf("invalid time of the run: %q, must be HH:MM", c.At)
+	}
+	return h, m, nil
+}
+
+// command returns the command line of the run.
+func (c InstallConfig) command() []string {
+	cmd := []string{c.Executable}
+	if c.CacheDir != "" {
+		cmd = append(cmd, "-cache-dir", c.CacheDir)
+	}
+	if c.Workspace != "" {
+		cmd = append(cmd, "-w", c.Workspace)
+	}
+	return append(cmd, c.Args...)
+}
+
+// SystemdUnits returns the systemd service and timer units of the runs.  The
+// service is the oneshot one, that runs on the timer, the missed runs, i.e.
+// when the machine was off, are started on the boot.  If the CacheDir is
+// empty, the cache directory of the service, /var/cache/<Name>, is used.
+func SystemdUnits(c InstallConfig) (service string, timer string, err error) {
+	if c.CacheDir == "" {
+		c.CacheDir = "/var/cache/" + c.Name
+	}
+	if err := c.validate(); err != nil {
+		return "", "", err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Unit]\nDescription=Slackdump scheduled run (%s)\nWants=network-online.target\nAfter=network-online.target\n\n", c.Name)
+	fmt.Fprintf(&sb, "[Service]\nType=oneshot\n")
+	if c.User != "" {
+		fmt.Fprintf(&sb, "User=%s\n", c.User)
+	}
+	if c.CacheDir == "/var/cache/"+c.Name {
+		fmt.Fprintf(&sb, "CacheDirectory=%s\n", c.Name)
+	}
+	if c.EnvFile != "" {
+		// "-" - the file is optional.
+		fmt.Fprintf(&sb, "EnvironmentFile=-%s\n", c.EnvFile)
+	}
+	quoted := make([]string, 0, len(c.command()))
+	for _, a := range c.command() {
+		quoted = append(quoted, systemdQuote(a))
+	}
+	fmt.Fprintf(&sb, "ExecStart=%s\n", strings.Join(quoted, " "))
+	fmt.Fprintf(&sb, "Nice=10\nIOSchedulingClass=idle\n")
+	service = sb.String()
+
+	h, m, _ := c.time()
+	var cal string
+	switch c.Schedule {
+	case "hourly":
+		cal = fmt.Sprintf("*-*-* *:%02d:00", m)
+	case "daily":
+		cal = fmt.Sprintf("*-*-* %02d:%02d:00", h, m)
+	case "weekly":
+		cal = fmt.Sprintf("Sun *-*-* %02d:%02d:00", h, m)
+	}
+	timer = fmt.Sprintf("[Unit]\nDescription=Slackdump scheduled run (%s) timer\n\n[Timer]\nOnCalendar=%s\nPersistent=true\nRandomizedDelaySec=5m\n\n[Install]\nWantedBy=timers.target\n", c.Name, cal)
+	return service, timer, nil
+}
+
+// systemdQuote quotes the argument of the ExecStart, if needed, and escapes
+// the specifiers and the variables.
+func systemdQuote(s string) string {
+	s = strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// schtasksArgs returns the arguments of the schtasks.exe, that create the
+// task of the runs in the Windows Task Scheduler.  The task runs as the
+// current user, so that the credentials, saved by the user, can be
+// decrypted.
+func schtasksArgs(c InstallConfig) ([]string, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	h, m, _ := c.time()
+	quoted := make([]string, 0, len(c.command()))
+	for _, a := range c.command() {
+		quoted = append(quoted, windowsQuote(a))
+	}
+	args := []string{"/Create", "/F", "/TN", c.Name, "/TR", strings.Join(quoted, " ")}
+	switch c.Schedule {
+	case "hourly":
+		args = append(args, "/SC", "HOURLY", "/ST", fmt.Sprintf("00:%02d", m))
+	case "daily":
+		args = append(args, "/SC", "DAILY", "/ST", fmt.Sprintf("%02d:%02d", h, m))
+	case "weekly":
+		args = append(args, "/SC", "WEEKLY", "/D", "SUN", "/ST", fmt.Sprintf("%02d:%02d", h, m))
+	}
+	return args, nil
+}
+
+// windowsQuote quotes the argument of the Windows command line, if needed.
+func windowsQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// Install installs the scheduled runs.  On Windows, the task is created in
+// the Task Scheduler, on the other systems, the systemd service and timer
+// units are written to the directory dir, i.e. /etc/systemd/system, and the
+// commands to enable them are printed.  If dryRun is true, the units or the
+// schtasks.exe command are printed instead.
+func Install(ctx context.Context, c InstallConfig, dir string, dryRun bool, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	if runtime.GOOS == "windows" {
+		if c.CacheDir == "" {
+			c.CacheDir = CacheDir()
+		}
+		args, err := schtasksArgs(c)
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			fmt.Println("schtasks.exe " + strings.Join(args, " "))
+			return nil
+		}
+		cmd := exec.CommandContext(ctx, "schtasks.exe", args...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("schtasks.exe: %w", err)
+		}
+		lg.Printf("task %q is created, it runs %s", c.Name, c.Schedule)
+		return nil
+	}
+
+	service, timer, err := SystemdUnits(c)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Printf("# %[1]s.service\n%[2]s\n# %[1]s.timer\n%[3]s", c.Name, service, timer)
+		return nil
+	}
+	for name, data := range map[string]string{c.Name + ".service": service, c.Name + ".timer": timer} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			return err
+		}
+	}
+	lg.Printf("systemd units %[1]s.service and %[1]s.timer are written to %[2]s, to enable them, run:\n\n\tsystemctl daemon-reload\n\tsystemctl enable --now %[1]s.timer\n", c.Name, dir)
+	return nil
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdUnits(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "slackdump")
+	c := InstallConfig{
+		Name:       "slackdump",
+		Executable: exe,
+		Args:       []string{"-export", "/srv/backup/slack 100%.zip"},
+		Workspace:  "acme",
+		Schedule:   "daily",
+		At:         "02:30",
+		User:       "backup",
+		EnvFile:    "/etc/slackdump.env",
+	}
+	service, timer, err := SystemdUnits(c)
+	require.NoError(t, err)
+	assert.Contains(t, service, "User=backup\n")
+	assert.Contains(t, service, "CacheDirectory=slackdump\n")
+	assert.Contains(t, service, "EnvironmentFile=-/etc/slackdump.env\n")
+	assert.Contains(t, service, "ExecStart="+systemdQuote(exe)+` -cache-dir /var/cache/slackdump -w acme -export "/srv/backup/slack 100%%.zip"`+"\n")
+	assert.Contains(t, timer, "OnCalendar=*-*-* 02:30:00\n")
+
+	c.Schedule = "weekly"
+	c.CacheDir = "/home/backup/.cache/slackdump"
+	service, timer, err = SystemdUnits(c)
+	require.NoError(t, err)
+	assert.NotContains(t, service, "CacheDirectory=")
+	assert.Contains(t, timer, "OnCalendar=Sun *-*-* 02:30:00\n")
+
+	for _, bad := range []InstallConfig{
+		{Name: "slackdump", Executable: "slackdump", Args: c.Args, Schedule: "daily", At: "02:30"},
+		{Name: "slackdump", Executable: c.Executable, Args: c.Args, Schedule: "monthly", At: "02:30"},
+		{Name: "slackdump", Executable: c.Executable, Args: c.Args, Schedule: "daily", At: "25:00"},
+		{Name: "slackdump", Executable: c.Executable, Schedule: "daily", At: "02:30"},
+	} {
+		_, _, err := SystemdUnits(bad)
+		assert.Error(t, err, "%+v", bad)
+	}
+}
+
+func Test_schtasksArgs(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "Program Files", "slackdump.exe")
+	args, err := schtasksArgs(InstallConfig{
+		Name:       "slackdump",
+		Executable: exe,
+		Args:       []string{"-export", "D:/backup/slack.zip"},
+		CacheDir:   "C:/Users/admin/AppData/Local/slackdump",
+		Schedule:   "hourly",
+		At:         "00:15",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"/Create", "/F", "/TN", "slackdump",
+		"/TR", `"` + exe + `" -cache-dir C:/Users/admin/AppData/Local/slackdump -export D:/backup/slack.zip`,
+		"/SC", "HOURLY", "/ST", "00:15",
+	}, args)
+}
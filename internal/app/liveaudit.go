@@ -0,0 +1,193 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"runtime/trace"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/auth/browser"
+	"github.com/rusq/slackdump/v2/internal/liveaudit"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// LiveAuditParams are the parameters of the comparison of the archive with
+// the live workspace.
+type LiveAuditParams struct {
+	Archive   string // export or dump location
+	Workspace string
+	Creds     SlackCreds
+	Browser   browser.Browser
+
+	Samples int           // number of the sampled channels
+	Window  time.Duration // length of the sampled time range of the channel, 0 - all archived messages
+	Seed    int64         // seed of the sampling, the same seed samples the same channels
+
+	Output string // output file, "-" for the Stdout
+	Format string // "text" or "json"
+
+	Options slackdump.Options
+}
+
+// LiveAudit samples the channels of the archive and the time ranges of
+// them, fetches the messages of the samples from the live workspace, and
+// writes the completeness report of the archive, see liveaudit.Report.
+func LiveAudit(ctx context.Context, p LiveAuditParams) error {
+	ctx, task := trace.NewTask(ctx, "LiveAudit")
+	defer task.End()
+
+	if p.Format != "text" && p.Format != "json" {
+		return fmt.Errorf("invalid format: %q, must be one of: text, json", p.Format)
+	}
+	if p.Samples <= 0 {
+		return errors.New("number of the samples must be positive")
+	}
+	lg := p.Options.Logger
+	if lg == nil {
+		lg = logger.Default
+	}
+	ar, err := openArchive(ctx, p.Archive)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+	man, err := readManifest(ar)
+	if err != nil {
+		return err
+	}
+
+	samples, err := sampleArchive(ar, p.Samples, p.Window, rand.New(rand.NewSource(p.Seed)))
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("%s: no archived messages to compare", ar.Name())
+	}
+
+	prov, err := InitProvider(ctx, p.Options.CacheDir, p.Workspace, p.Creds, p.Browser)
+	if err != nil {
+		return err
+	}
+	sess, err := slackdump.NewWithOptions(ctx, prov, p.Options)
+	if err != nil {
+		return err
+	}
+	if team := sess.Manifest().TeamID; man.TeamID != "" && team != "" && man.TeamID != team {
+		return fmt.Errorf("the archive is of the workspace %s, and the token is of %s", man.TeamID, team)
+	}
+
+	results := make([]liveaudit.Result, 0, len(samples))
+	for _, s := range samples {
+		lg.Printf("comparing %s from %s to %s", s.ChannelID, s.Oldest.Format(time.RFC3339), s.Latest.Format(time.RFC3339))
+		res, err := compareSample(ctx, ar, sess, s, man.Created)
+		if err != nil {
+			return err
+		}
+		results = append(results, res)
+	}
+	rep := liveaudit.NewReport(ar.Name(), man.Created, p.Seed, results)
+
+	f, err := createFile(p.Output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if p.Format == "json" {
+		return rep.WriteJSON(f)
+	}
+	return rep.WriteText(f)
+}
+
+// readManifest reads the manifest of the archive, the zero manifest is
+// returned, if there's none.
+func readManifest(ar *archive.Archive) (slackdump.Manifest, error) {
+	var man slackdump.Manifest
+	data, err := fs.ReadFile(ar.FS(), slackdump.ManifestFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return man, nil
+		}
+		return man, err
+	}
+	if err := json.Unmarshal(data, &man); err != nil {
+		return man, fmt.Errorf("%s: %w", slackdump.ManifestFile, err)
+	}
+	return man, nil
+}
+
+// sampleArchive picks up to n random channels of the archive, that have the
+// messages, and the random time range of the length window of each.
+func sampleArchive(ar *archive.Archive, n int, window time.Duration, rnd *rand.Rand) ([]liveaudit.Sample, error) {
+	chans, err := ar.Channels()
+	if err != nil {
+		return nil, err
+	}
+	var samples []liveaudit.Sample
+	for _, i := range rnd.Perm(len(chans)) {
+		if len(samples) == n {
+			break
+		}
+		ch := chans[i]
+		oldest, latest, err := archivedRange(ar, ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		if oldest.IsZero() {
+			continue
+		}
+		samples = append(samples, liveaudit.Pick(ch.ID, ch.Name, oldest, latest, window, rnd))
+	}
+	return samples, nil
+}
+
+// archivedRange returns the time of the oldest and the latest top level
+// message of the channel in the archive, or zero times, if there are none.
+func archivedRange(ar *archive.Archive, channelID string) (oldest, latest time.Time, err error) {
+	ar.Messages(channelID)(func(m types.Message, e error) bool {
+		if e != nil {
+			err = e
+			return false
+		}
+		if m.IsThread() && m.ThreadTimestamp != m.Timestamp {
+			return true
+		}
+		t, e := structures.ParseSlackTS(m.Timestamp)
+		if e != nil {
+			return true
+		}
+		if oldest.IsZero() || t.Before(oldest) {
+			oldest = t
+		}
+		if t.After(latest) {
+			latest = t
+		}
+		return true
+	})
+	return oldest, latest, err
+}
+
+// compareSample fetches the messages of the sample from the workspace, and
+// compares them with the archived ones.  The errors of fetching, i.e. no
+// access to the channel, are recorded in the result.
+func compareSample(ctx context.Context, ar *archive.Archive, sess *slackdump.Session, s liveaudit.Sample, asOf time.Time) (liveaudit.Result, error) {
+	cnv, err := ar.Conversation(s.ChannelID)
+	if err != nil {
+		return liveaudit.Result{}, err
+	}
+	live, err := sess.DumpRaw(ctx, s.ChannelID, s.Oldest, s.Latest)
+	if err != nil {
+		if ctx.Err() != nil {
+			return liveaudit.Result{}, err
+		}
+		return liveaudit.Result{Sample: s, Error: err.Error()}, nil
+	}
+	return liveaudit.Compare(s, cnv.Messages, live.Messages, asOf), nil
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/structures"
+)
+
+func TestSampleArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"channels.json": `[{"id": "C01", "name": "general"}, {"id": "C02", "name": "empty"}, {"id": "C03", "name": "random"}]`,
+		"manifest.json": `{"created": "2023-02-01T00:00:00Z", "team_id": "T01"}`,
+		"general/2023-01-01.json": `[
+			{"ts": "1672531200.000100", "thread_ts": "1672531200.000100", "text": "parent"},
+			{"ts": "1672704000.000100", "thread_ts": "1672531200.000100", "text": "late reply"}
+		]`,
+		"general/2023-01-02.json": `[{"ts": "1672617600.000100", "text": "hello"}]`,
+		"random/2023-01-05.json":  `[{"ts": "1672876800.000100", "text": "hi"}]`,
+	})
+	ar, err := archive.Open(dir)
+	require.NoError(t, err)
+	defer ar.Close()
+
+	man, err := readManifest(ar)
+	require.NoError(t, err)
+	assert.Equal(t, "T01", man.TeamID)
+	assert.Equal(t, time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), man.Created)
+
+	oldest, latest, err := archivedRange(ar, "C01")
+	require.NoError(t, err)
+	assert.Equal(t, mustParseTS(t, "1672531200.000100"), oldest)
+	assert.Equal(t, mustParseTS(t, "1672617600.000100"), latest, "the replies are not counted")
+
+	samples, err := sampleArchive(ar, 5, 0, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	require.Len(t, samples, 2, "the channel without messages is not sampled")
+	var ids []string
+	for _, s := range samples {
+		ids = append(ids, s.ChannelID)
+	}
+	assert.ElementsMatch(t, []string{"C01", "C03"}, ids)
+
+	samples, err = sampleArchive(ar, 1, 0, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	assert.Len(t, samples, 1)
+}
+
+func mustParseTS(t *testing.T, ts string) time.Time {
+	t.Helper()
+	tm, err := structures.ParseSlackTS(ts)
+	require.NoError(t, err)
+	return tm
+}
+
+func TestLiveAudit_params(t *testing.T) {
+	err := LiveAudit(context.Background(), LiveAuditParams{Archive: t.TempDir(), Samples: 1, Format: "csv"})
+	assert.Error(t, err)
+	err = LiveAudit(context.Background(), LiveAuditParams{Archive: t.TempDir(), Samples: 0, Format: "text"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/auth/browser"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/migrate"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/internal/usermap"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// MigrateParams are the parameters of the workspace migration.
+type MigrateParams struct {
+	// From is the archive location, or the name of the source workspace,
+	// which is exported into the temporary directory first.
+	From      string
+	FromCreds SlackCreds
+	// To is the name of the destination workspace.
+	To      string
+	ToCreds SlackCreds
+	Browser browser.Browser
+
+	Channels []string // channel IDs to migrate, empty - all channels
+	UserMap  string   // user mapping file, see usermap.Load, users not in it are matched by email
+	Journal  string   // journal of the posted messages
+	Create   bool     // create the missing channels
+
+	Options slackdump.Options
+}
+
+// Migrate posts the messages of the archive or the source workspace into the
+// destination workspace.
+func Migrate(ctx context.Context, p MigrateParams) error {
+	if p.To == "" && p.ToCreds.IsEmpty() {
+		return errors.New("destination workspace is required")
+	}
+	lg := p.Options.Logger
+	if lg == nil {
+		lg = logger.Default
+	}
+
+	ar, cleanup, err := migrateSource(ctx, p)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// the destination credentials are not saved, so that they don't replace
+	// the credentials of the source workspace.
+	prov, err := p.ToCreds.AuthProvider(ctx, p.To, p.Browser)
+	if err != nil {
+		return err
+	}
+	dst, err := slackdump.NewWithOptions(ctx, prov, p.Options)
+	if err != nil {
+		return err
+	}
+	srcUsers, err := ar.Users()
+	if err != nil {
+		return err
+	}
+	dstUsers, err := dst.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+	um := make(usermap.Map)
+	if p.UserMap != "" {
+		if um, err = usermap.Load(p.UserMap); err != nil {
+			return err
+		}
+	}
+	users, unresolved := um.Resolve(srcUsers, dstUsers)
+	if len(unresolved) > 0 {
+		lg.Printf("warning: %d user(s) of the mapping were not found in the destination workspace: %s", len(unresolved), strings.Join(unresolved, " "))
+	}
+	lg.Debugf("%d user(s) mapped", len(users))
+	journal, err := migrate.OpenJournal(p.Journal)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	mg := migrate.New(dst.Client(), migrate.WithUsers(migrate.UserMap(users)), migrate.WithJournal(journal), migrate.WithCreate(p.Create), migrate.WithLogger(lg))
+	st, err := mg.Migrate(ctx, ar, p.Channels...)
+	lg.Printf("migrated %d channel(s): %d message(s) and %d file(s) posted, %d skipped in %s", st.Channels, st.Messages, st.Files, st.Skipped, time.Since(start))
+	return err
+}
+
+// migrateSource opens the archive to migrate.  If p.From is not an archive,
+// it's the source workspace, that is exported with files into the temporary
+// directory, which is removed by the returned cleanup function.
+func migrateSource(ctx context.Context, p MigrateParams) (*archive.Archive, func(), error) {
+	if _, err := os.Stat(p.From); err == nil {
+		ar, err := archive.Open(p.From)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ar, func() { ar.Close() }, nil
+	}
+	prov, err := InitProvider(ctx, p.Options.CacheDir, p.From, p.FromCreds, p.Browser)
+	if err != nil {
+		return nil, nil, err
+	}
+	dir, err := os.MkdirTemp("", "slackdump-migrate-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	list, err := structures.MakeEntityList(p.Channels)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	cfg := config.Params{ExportName: dir, Input: config.Input{List: list}, Options: p.Options}
+	cfg.Options.DumpFiles = true
+	if err := Export(ctx, cfg, prov); err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	ar, err := archive.Open(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	return ar, func() {
+		ar.Close()
+		os.RemoveAll(dir)
+	}, nil
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/trace"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+)
+
+// personalFile is the name of the file with the personal data of the
+// current user in the output directory or archive.
+const personalFile = "personal.json"
+
+// Personal saves the scheduled messages, the drafts and the saved items of the
+// current user to the Output.Base directory or archive.
+func Personal(ctx context.Context, cfg config.Params, prov auth.Provider) error {
+	ctx, task := trace.NewTask(ctx, "Personal")
+	defer task.End()
+
+	sess, err := slackdump.NewWithOptions(ctx, prov, cfg.Options)
+	if err != nil {
+		return err
+	}
+	p, err := sess.Personal(ctx)
+	if err != nil {
+		return err
+	}
+
+	fs, err := fsadapter.New(cfg.Output.Base)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	f, err := fs.Create(personalFile)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	cfg.Logger().Printf("saved %d scheduled message(s), %d draft(s) and %d saved item(s) to %s", len(p.Scheduled), len(p.Drafts), len(p.Saved), cfg.Output.Base)
+	return slackdump.WriteManifest(fs, sess.Manifest())
+}
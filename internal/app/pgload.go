@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	_ "github.com/lib/pq" // PostgreSQL driver
+
+	"github.com/rusq/slackdump/v2/internal/pgload"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// LoadPostgres loads the archive src into the PostgreSQL database with the
+// connection string dsn.  If workspace is empty, it is detected from the
+// archive.
+func LoadPostgres(ctx context.Context, src string, dsn string, workspace string, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	ar, err := openArchive(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	st, err := pgload.New(db, pgload.WithWorkspace(workspace), pgload.WithLogger(lg)).Load(ctx, ar)
+	if err != nil {
+		return err
+	}
+	lg.Printf("loaded %d channel(s), %d user(s) and %d message(s) of workspace %s in %s", st.Channels, st.Users, st.Messages, st.Workspace, time.Since(start))
+	return nil
+}
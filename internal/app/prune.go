@@ -0,0 +1,416 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/hold"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/internal/structures/files"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// mattermostUploads is the directory of the files in the Mattermost export.
+const mattermostUploads = "__uploads"
+
+// Prune removes the messages, that are older than the retention period keep,
+// and the downloaded files attached to them, from the export or dump directory
+// dir.  If usePolicies is true, the custom retention policies of the
+// conversations, saved in admin.json with the -admin flag, override keep.  The
+// messages under the legal holds of the archive, see HoldAdd, are kept.  If
+// dryRun is true, the archive is not modified.  The ZIP archives are not
+// supported, they should be unpacked first.
+func Prune(ctx context.Context, dir string, keep config.Retention, usePolicies bool, dryRun bool, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	if keep.IsZero() && !usePolicies {
+		return errors.New("retention period is required")
+	}
+	if fi, err := os.Stat(dir); err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return fmt.Errorf("%s: not a directory, unpack the ZIP archive first", dir)
+	}
+	ar, err := archive.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	now := time.Now()
+	p := &pruner{
+		dir:     dir,
+		dryRun:  dryRun,
+		lg:      lg,
+		removed: make(map[string]bool),
+		kept:    make(map[string]bool),
+	}
+	if !keep.IsZero() {
+		p.cutoff = keep.Cutoff(now)
+	}
+	if usePolicies {
+		if p.policies, err = retentionPolicies(ar.FS(), now); err != nil {
+			return err
+		}
+	}
+	if p.holds, err = hold.Load(dir); err != nil {
+		return err
+	}
+	switch ar.Type() {
+	case archive.TExport:
+		err = p.pruneExport(ctx, ar)
+	case archive.TDump:
+		err = p.pruneDump(ctx, ar)
+	default:
+		err = archive.ErrUnknownFormat
+	}
+	if err != nil {
+		return err
+	}
+	nFiles, err := p.removeFiles()
+	if err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("%d message(s) and %d file(s) outside of the retention period", p.nMsgs, nFiles)
+	if held := p.heldSummary(); held != "" {
+		lg.Printf("expired messages kept under the legal holds: %s", held)
+		summary += ", kept under the legal holds: " + held
+	}
+	if dryRun {
+		lg.Printf("%s would be removed", summary)
+		return nil
+	}
+	lg.Printf("removed %s, rewritten %d and removed %d conversation file(s)", summary, p.nRewritten, p.nDeleted)
+	if p.nMsgs+nFiles == 0 {
+		return nil
+	}
+	details := summary + " removed"
+	if !keep.IsZero() {
+		details += ", retention period " + keep.String()
+	}
+	if usePolicies {
+		details += ", custom retention policies"
+	}
+	if err := slackdump.AppendCustody(fsadapter.NewDirectory(dir), slackdump.NewCustodyRecord(slackdump.CustodyPruned, details)); err != nil {
+		return err
+	}
+	// the custody log is in the archive, so the manifest goes last.
+	return updateManifest(ctx, dir, lg)
+}
+
+// retentionPolicies returns the cutoff times of the conversations with the
+// custom retention policy from the admin file of the archive.
+func retentionPolicies(fsys fs.FS, now time.Time) (map[string]time.Time, error) {
+	data, err := fs.ReadFile(fsys, slackdump.AdminFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%s not found, the archive should be created with the -admin flag", slackdump.AdminFile)
+		}
+		return nil, err
+	}
+	var adm types.Admin
+	if err := json.Unmarshal(data, &adm); err != nil {
+		return nil, fmt.Errorf("%s: %w", slackdump.AdminFile, err)
+	}
+	policies := make(map[string]time.Time)
+	for _, ac := range adm.Conversations {
+		if ac.Retention != nil && ac.Retention.IsPolicyEnabled && ac.Retention.DurationDays > 0 {
+			policies[ac.ChannelID] = now.AddDate(0, 0, -ac.Retention.DurationDays)
+		}
+	}
+	return policies, nil
+}
+
+// pruner removes the old messages from the archive directory.
+type pruner struct {
+	dir      string
+	dryRun   bool
+	lg       logger.Interface
+	cutoff   time.Time            // zero, if the messages are kept by default.
+	policies map[string]time.Time // custom cutoff by channel ID
+	holds    hold.Holds
+	heldBy   map[string]int // number of expired messages by the hold name
+
+	// removed and kept are the references to the files, attached to the
+	// removed and to the kept messages: the slash separated local paths,
+	// relative to the archive root, and the IDs of the Mattermost uploads.
+	removed map[string]bool
+	kept    map[string]bool
+
+	nMsgs      int
+	nRewritten int
+	nDeleted   int
+}
+
+func (p *pruner) channelCutoff(channelID string) time.Time {
+	if c, ok := p.policies[channelID]; ok {
+		return c
+	}
+	return p.cutoff
+}
+
+// expired returns true if the message m of the channel channelID was posted
+// before the cutoff, and is not under the legal hold.
+func (p *pruner) expired(channelID string, m types.Message, cutoff time.Time) bool {
+	if cutoff.IsZero() {
+		return false
+	}
+	t, err := structures.ParseSlackTS(m.Timestamp)
+	if err != nil || !t.Before(cutoff) {
+		return false
+	}
+	if h := p.holds.Match(channelID, m.User, t); h != nil {
+		if p.heldBy == nil {
+			p.heldBy = make(map[string]int)
+		}
+		p.heldBy[h.Name]++
+		return false
+	}
+	return true
+}
+
+// heldSummary returns the number of the expired messages kept by each hold.
+func (p *pruner) heldSummary() string {
+	names := make([]string, 0, len(p.heldBy))
+	for name := range p.heldBy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		names[i] = fmt.Sprintf("%s: %d message(s)", name, p.heldBy[name])
+	}
+	return strings.Join(names, ", ")
+}
+
+// addRefs adds the references to the files of the message m to refs.  base
+// is the directory, the local paths of the files are relative to.
+func addRefs(refs map[string]bool, m types.Message, base string) {
+	for _, f := range m.Files {
+		refs[mattermostUploads+"/"+f.ID] = true
+		for _, p := range []string{f.URLPrivate, f.URLPrivateDownload} {
+			if files.IsLocal(p) {
+				refs[path.Join(base, p)] = true
+			}
+		}
+	}
+	for _, r := range m.ThreadReplies {
+		addRefs(refs, r, base)
+	}
+}
+
+// pruneExport prunes the daily message files of the export channels.  The
+// file paths in the export are relative to the channel directory.
+func (p *pruner) pruneExport(ctx context.Context, ar *archive.Archive) error {
+	chans, err := ar.Channels()
+	if err != nil {
+		return err
+	}
+	for _, ch := range chans {
+		cutoff := p.channelCutoff(ch.ID)
+		dir := archive.ExportDir(ar.FS(), ch)
+		entries, err := fs.ReadDir(ar.FS(), dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		for _, e := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			if err := p.pruneExportDay(path.Join(dir, e.Name()), dir, ch.ID, cutoff); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneExportDay prunes the export daily file name.  The kept messages are
+// written as they are, so that the fields unknown to slackdump are not lost.
+func (p *pruner) pruneExportDay(name string, dir string, channelID string, cutoff time.Time) error {
+	data, err := os.ReadFile(p.path(name))
+	if err != nil {
+		return err
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	keep := raw[:0:0]
+	for _, r := range raw {
+		var m types.Message
+		if err := json.Unmarshal(r, &m); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if p.expired(channelID, m, cutoff) {
+			addRefs(p.removed, m, dir)
+			continue
+		}
+		addRefs(p.kept, m, dir)
+		keep = append(keep, r)
+	}
+	return p.update(name, len(raw)-len(keep), len(keep), func() ([]byte, error) {
+		return marshalIndent(keep)
+	})
+}
+
+// pruneDump prunes the conversation files of the dump archive ar.  The file
+// paths in the dump are relative to the archive root.
+func (p *pruner) pruneDump(ctx context.Context, ar *archive.Archive) error {
+	changed := make(map[string]bool) // channel IDs
+	if err := walkDumpFiles(ctx, ar, func(name string, cnv *types.Conversation) error {
+		before := countMessages(cnv.Messages)
+		cnv.Messages = p.pruneMessages(cnv.Messages, cnv.ID, p.channelCutoff(cnv.ID))
+		after := countMessages(cnv.Messages)
+		if before != after {
+			changed[cnv.ID] = true
+		}
+		return p.update(name, before-after, len(cnv.Messages), func() ([]byte, error) {
+			return marshalIndent(cnv)
+		})
+	}); err != nil {
+		return err
+	}
+	if p.dryRun {
+		return nil
+	}
+	// the split conversations, see -dump-layout.
+	return refreshSplits(p.dir, changed)
+}
+
+// pruneMessages returns the messages, that are not expired.  The replies of
+// the expired thread parent, that are not expired, are kept on the top level,
+// the same way as in the export.
+func (p *pruner) pruneMessages(msgs []types.Message, channelID string, cutoff time.Time) []types.Message {
+	var keep []types.Message
+	for _, m := range msgs {
+		replies := p.pruneMessages(m.ThreadReplies, channelID, cutoff)
+		m.ThreadReplies = nil
+		if p.expired(channelID, m, cutoff) {
+			addRefs(p.removed, m, "")
+			keep = append(keep, replies...)
+			continue
+		}
+		addRefs(p.kept, m, "")
+		m.ThreadReplies = replies
+		keep = append(keep, m)
+	}
+	types.SortMessages(keep)
+	return keep
+}
+
+// update rewrites the conversation file name, if the messages were removed
+// from it, or deletes it, if there are no messages left.
+func (p *pruner) update(name string, removed int, left int, marshal func() ([]byte, error)) error {
+	if removed == 0 {
+		return nil
+	}
+	p.nMsgs += removed
+	if left == 0 {
+		p.lg.Debugf("%s: removing, all %d message(s) are expired", name, removed)
+		p.nDeleted++
+		if p.dryRun {
+			return nil
+		}
+		if err := os.Remove(p.path(name)); err != nil {
+			return err
+		}
+		if dir := path.Dir(name); dir != "." {
+			// the channel directory without the messages and attachments.
+			_ = os.Remove(p.path(dir))
+		}
+		return nil
+	}
+	p.lg.Debugf("%s: removing %d message(s)", name, removed)
+	p.nRewritten++
+	if p.dryRun {
+		return nil
+	}
+	data, err := marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p.path(name), data, 0666)
+}
+
+// removeFiles removes the files, that are attached only to the removed
+// messages, and the directories that become empty.  It returns the number of
+// removed files.
+func (p *pruner) removeFiles() (int, error) {
+	var names []string
+	for ref := range p.removed {
+		if !p.kept[ref] {
+			names = append(names, ref)
+		}
+	}
+	sort.Strings(names)
+	var n int
+	for _, name := range names {
+		fi, err := os.Stat(p.path(name))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				// not downloaded, or not a Mattermost export.
+				continue
+			}
+			return n, err
+		}
+		p.lg.Debugf("removing file %s", name)
+		n++
+		if p.dryRun {
+			continue
+		}
+		if fi.IsDir() {
+			err = os.RemoveAll(p.path(name))
+		} else {
+			err = os.Remove(p.path(name))
+		}
+		if err != nil {
+			return n, err
+		}
+		// remove the emptied directories, i.e. "attachments", up to the
+		// archive root, os.Remove fails on the non-empty ones.
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if os.Remove(p.path(dir)) != nil {
+				break
+			}
+		}
+	}
+	return n, nil
+}
+
+// path returns the OS path of the slash separated name in the archive.
+func (p *pruner) path(name string) string {
+	return filepath.Join(p.dir, filepath.FromSlash(name))
+}
+
+func marshalIndent(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
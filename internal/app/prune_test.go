@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/sign"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestPrune(t *testing.T) {
+	const old = "1500000000.000100" // 2017
+	recent := fmt.Sprintf("%d.000200", time.Now().Add(-24*time.Hour).Unix())
+	keep := config.Retention{Years: 2}
+
+	t.Run("export", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{
+			"channels.json": `[{"id": "C01", "name": "general"}, {"id": "C02", "name": "random"}]`,
+			"users.json":    `[]`,
+			"general/2017-07-14.json": `[{"ts": "` + old + `", "text": "old", "files": [{"id": "F01", "url_private": "attachments/F01-old.txt"}]},` +
+				`{"ts": "` + old + `", "text": "shared", "files": [{"id": "F03", "url_private": "attachments/F03-shared.txt"}]}]`,
+			"general/2023-01-01.json":            `[{"ts": "` + recent + `", "text": "new", "x_unknown": 1, "files": [{"id": "F03", "url_private": "attachments/F03-shared.txt"}]}]`,
+			"general/attachments/F01-old.txt":    "old",
+			"general/attachments/F03-shared.txt": "shared",
+			"random/2017-07-14.json":             `[{"ts": "` + old + `", "text": "old", "files": [{"id": "F02", "url_private": "attachments/F02-old.txt"}]}]`,
+			"random/attachments/F02-old.txt":     "old",
+		})
+		t.Run("dry run", func(t *testing.T) {
+			require.NoError(t, Prune(context.Background(), dir, keep, false, true, logger.Silent))
+			assert.FileExists(t, filepath.Join(dir, "general", "2017-07-14.json"))
+			assert.FileExists(t, filepath.Join(dir, "general", "attachments", "F01-old.txt"))
+			assert.NoFileExists(t, filepath.Join(dir, slackdump.CustodyFile))
+		})
+		require.NoError(t, Prune(context.Background(), dir, keep, false, false, logger.Silent))
+
+		assert.NoFileExists(t, filepath.Join(dir, "general", "2017-07-14.json"))
+		assert.NoFileExists(t, filepath.Join(dir, "general", "attachments", "F01-old.txt"))
+		assert.FileExists(t, filepath.Join(dir, "general", "attachments", "F03-shared.txt"), "file is referenced by the kept message")
+		assert.NoDirExists(t, filepath.Join(dir, "random"), "empty channel directory should be removed")
+
+		data, err := os.ReadFile(filepath.Join(dir, "general", "2023-01-01.json"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "x_unknown", "kept messages must not be changed")
+
+		recs := readCustodyLog(t, dir)
+		require.Len(t, recs, 1)
+		assert.Equal(t, slackdump.CustodyPruned, recs[0].Operation)
+		assert.Contains(t, recs[0].Details, "3 message(s) and 2 file(s)")
+	})
+	t.Run("dump with policies", func(t *testing.T) {
+		dir := t.TempDir()
+		thread := `{"name": "general", "channel_id": "C01", "messages": [` +
+			`{"ts": "` + old + `", "text": "parent", "thread_ts": "` + old + `", "reply_count": 2, "slackdump_thread_replies": [` +
+			`{"ts": "1500000001.000100", "text": "old reply", "thread_ts": "` + old + `", "files": [{"id": "F01", "url_private": "C01/F01-old.txt"}]},` +
+			`{"ts": "` + recent + `", "text": "new reply", "thread_ts": "` + old + `"}]}]}`
+		writeTree(t, dir, map[string]string{
+			"C01.json":        thread,
+			"C01/F01-old.txt": "old",
+			"C02.json":        `{"name": "random", "channel_id": "C02", "messages": [{"ts": "` + old + `", "text": "old"}]}`,
+			"users.json":      `[]`,
+			"admin.json":      `{"conversations": [{"channel_id": "C02", "retention": {"is_policy_enabled": true, "duration_days": 36500}}]}`,
+		})
+		require.NoError(t, Prune(context.Background(), dir, keep, true, false, logger.Silent))
+
+		data, err := os.ReadFile(filepath.Join(dir, "C01.json"))
+		require.NoError(t, err)
+		var cnv types.Conversation
+		require.NoError(t, json.Unmarshal(data, &cnv))
+		require.Len(t, cnv.Messages, 1, "the new reply should be kept on the top level")
+		assert.Equal(t, "new reply", cnv.Messages[0].Text)
+		assert.NoDirExists(t, filepath.Join(dir, "C01"))
+
+		assert.FileExists(t, filepath.Join(dir, "C02.json"), "custom policy keeps the messages for 100 years")
+	})
+	t.Run("manifest", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "archive")
+		writeTree(t, dir, map[string]string{
+			"C01.json": `{"name": "general", "channel_id": "C01", "messages": [{"ts": "` + old + `", "text": "old"}, {"ts": "` + recent + `", "text": "new"}]}`,
+		})
+		manifest, err := sign.Manifest(context.Background(), os.DirFS(dir))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(ManifestName(dir), manifest, 0644))
+
+		require.NoError(t, Prune(context.Background(), dir, keep, false, false, logger.Silent))
+
+		manifest, err = os.ReadFile(ManifestName(dir))
+		require.NoError(t, err)
+		assert.NoError(t, sign.Check(context.Background(), os.DirFS(dir), manifest), "manifest must match the pruned files")
+		assert.Contains(t, string(manifest), slackdump.CustodyFile)
+	})
+	t.Run("split dump", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{
+			"C01-2017.json": `{"name": "general", "channel_id": "C01", "messages": [{"ts": "` + old + `", "text": "old"}]}`,
+			"C01-2024.json": `{"name": "general", "channel_id": "C01", "messages": [{"ts": "` + recent + `", "text": "new"}]}`,
+			"C01-index.json": `{"name": "general", "conversation_id": "C01", "layout": "yearly", "parts": [` +
+				`{"period": "2017", "file": "C01-2017.json", "messages": 1, "oldest": "` + old + `", "latest": "` + old + `"},` +
+				`{"period": "2024", "file": "C01-2024.json", "messages": 1, "oldest": "` + recent + `", "latest": "` + recent + `"}]}`,
+		})
+		require.NoError(t, Prune(context.Background(), dir, keep, false, false, logger.Silent))
+
+		assert.NoFileExists(t, filepath.Join(dir, "C01-2017.json"))
+		data, err := os.ReadFile(filepath.Join(dir, "C01"+dumpIndexSuffix))
+		require.NoError(t, err)
+		var idx dumpIndex
+		require.NoError(t, json.Unmarshal(data, &idx))
+		assert.Equal(t, []dumpPart{{Period: "2024", File: "C01-2024.json", Messages: 1, Oldest: recent, Latest: recent}}, idx.Parts, "the removed part is not in the index")
+	})
+	t.Run("errors", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{"channels.json": `[]`})
+		assert.Error(t, Prune(context.Background(), dir, config.Retention{}, false, false, logger.Silent), "retention period is required")
+		assert.Error(t, Prune(context.Background(), dir, keep, true, false, logger.Silent), "admin.json is required")
+	})
+}
+
+func readCustodyLog(t *testing.T, dir string) []slackdump.CustodyRecord {
+	t.Helper()
+	f, err := os.Open(filepath.Join(dir, slackdump.CustodyFile))
+	require.NoError(t, err)
+	defer f.Close()
+	var recs []slackdump.CustodyRecord
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var rec slackdump.CustodyRecord
+		require.NoError(t, dec.Decode(&rec))
+		recs = append(recs, rec)
+	}
+	return recs
+}
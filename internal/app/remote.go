@@ -0,0 +1,27 @@
+package app
+
+import (
+	"context"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/remote"
+)
+
+// RemoteConfig is the configuration of the access to the remote archives,
+// the command line tool sets it from the environment.
+var RemoteConfig remote.Config
+
+// openArchive opens the archive src for reading, which, in addition to the
+// local directories and files, can be the ZIP file or the uncompressed
+// tarball in the S3 bucket or on the web server, that is read by the ranges,
+// see remote.Open.
+func openArchive(ctx context.Context, src string) (*archive.Archive, error) {
+	if !remote.IsRemote(src) {
+		return archive.Open(src)
+	}
+	f, err := remote.Open(ctx, src, RemoteConfig)
+	if err != nil {
+		return nil, err
+	}
+	return archive.NewPacked(f, f.Size(), src)
+}
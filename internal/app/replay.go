@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path"
+
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/transport"
+)
+
+// replayBoost is the limiter boost for the replay, in events per minute, the
+// recorded responses don't need to be throttled.
+const replayBoost = 60000
+
+// Replay replays the API calls, recorded with the -record flag, from the
+// cassette file through the dump or the export, configured by cfg, without
+// connecting to Slack.  If cfg has no input for the dump, the conversations
+// that were fetched in the recorded session are dumped.  The time frame and
+// the request sizes must be the same as in the recorded session, otherwise
+// the requests won't match the recorded ones.
+func Replay(ctx context.Context, cassette string, cfg config.Params) error {
+	lg := cfg.Logger()
+	f, err := os.Open(cassette)
+	if err != nil {
+		return err
+	}
+	c, err := transport.LoadCassette(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	player := transport.NewPlayer(c)
+	cfg.Options.Middleware = append(cfg.Options.Middleware, player.Middleware())
+	cfg.Options.Tier2Boost, cfg.Options.Tier3Boost, cfg.Options.Tier4Boost = replayBoost, replayBoost, replayBoost
+	// users are fetched from the cassette, if they were not cached in the
+	// recorded session.
+	cacheDir, err := os.MkdirTemp("", "slackdump-replay-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(cacheDir)
+	cfg.Options.CacheDir = cacheDir
+	cfg.Options.NoUserCache = len(recordedCalls(c, "users.list")) == 0
+
+	if cfg.ExportName == "" && (cfg.Input.List == nil || !cfg.Input.IsValid()) {
+		links := recordedLinks(c)
+		if len(links) == 0 {
+			return errors.New("there are no conversations in the cassette")
+		}
+		if cfg.Input.List, err = structures.MakeEntityList(links); err != nil {
+			return err
+		}
+	}
+
+	// the token is not used, the recorded tokens are redacted.
+	prov, err := auth.NewValueAuth("xoxp-replay", "")
+	if err != nil {
+		return err
+	}
+	if err := Run(ctx, cfg, prov); err != nil {
+		return err
+	}
+	if n := player.Unused(); n > 0 {
+		lg.Printf("%d recorded call(s) were not replayed, the output might differ from the recorded session", n)
+	}
+	return nil
+}
+
+// recordedCalls returns the request parameters of the recorded calls of the
+// API method.
+func recordedCalls(c *transport.Cassette, method string) []url.Values {
+	var res []url.Values
+	for _, in := range c.Interactions {
+		u, err := url.Parse(in.Request.URL)
+		if err != nil || path.Base(u.Path) != method {
+			continue
+		}
+		v, err := url.ParseQuery(string(in.Request.Body))
+		if err != nil {
+			continue
+		}
+		res = append(res, v)
+	}
+	return res
+}
+
+// recordedLinks returns the conversations and threads that were dumped in
+// the recorded session.  Threads of the dumped conversations are not
+// included.
+func recordedLinks(c *transport.Cassette) []string {
+	var (
+		links []string
+		seen  = make(map[string]bool)
+	)
+	for _, v := range recordedCalls(c, "conversations.history") {
+		if id := v.Get("channel"); id != "" && !seen[id] {
+			seen[id] = true
+			links = append(links, id)
+		}
+	}
+	for _, v := range recordedCalls(c, "conversations.replies") {
+		id, ts := v.Get("channel"), v.Get("ts")
+		if id == "" || ts == "" || seen[id] || seen[id+":"+ts] {
+			continue
+		}
+		seen[id+":"+ts] = true
+		links = append(links, id+":"+ts)
+	}
+	return links
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/transport"
+)
+
+// fakeSlack serves the canned API responses.
+var fakeSlack = transport.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+	responses := map[string]string{
+		"auth.test":             `{"ok":true,"team_id":"T01","user_id":"U01","team":"test","user":"alice"}`,
+		"users.list":            `{"ok":true,"members":[{"id":"U01","team_id":"T01","name":"alice"}]}`,
+		"conversations.history": `{"ok":true,"messages":[{"type":"message","user":"U01","text":"hello","ts":"1672531200.000100"}],"has_more":false}`,
+		"conversations.info":    `{"ok":true,"channel":{"id":"C01","name":"general"}}`,
+	}
+	body, ok := responses[path.Base(req.URL.Path)]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: req}, nil
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+})
+
+func replayParams(t *testing.T, base string) config.Params {
+	t.Helper()
+	cfg := config.Params{Options: slackdump.DefOptions, FilenameTemplate: "{{.ID}}"}
+	cfg.Options.Logger = logger.Silent
+	cfg.Options.CacheDir = t.TempDir()
+	cfg.Output.Base = base
+	return cfg
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	cassette := filepath.Join(tmp, "cassette.json")
+
+	// record the session
+	cfg := replayParams(t, filepath.Join(tmp, "recorded"))
+	cfg.RecordFile = cassette
+	cfg.Options.Middleware = []transport.Middleware{func(http.RoundTripper) http.RoundTripper { return fakeSlack }}
+	el, err := structures.MakeEntityList([]string{"C01"})
+	require.NoError(t, err)
+	cfg.Input.List = el
+	prov, err := auth.NewValueAuth("xoxp-secret", "")
+	require.NoError(t, err)
+	require.NoError(t, Run(ctx, cfg, prov))
+
+	data, err := os.ReadFile(cassette)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "xoxp-secret")
+
+	// replay it
+	require.NoError(t, Replay(ctx, cassette, replayParams(t, filepath.Join(tmp, "replayed"))))
+
+	want, err := os.ReadFile(filepath.Join(tmp, "recorded", "C01.json"))
+	require.NoError(t, err)
+	got, err := os.ReadFile(filepath.Join(tmp, "replayed", "C01.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(got))
+}
+
+func Test_recordedLinks(t *testing.T) {
+	in := func(method, body string) transport.Interaction {
+		return transport.Interaction{Request: transport.Request{Method: http.MethodPost, URL: "https://slack.com/api/" + method, Body: transport.Body(body)}}
+	}
+	c := &transport.Cassette{Interactions: []transport.Interaction{
+		in("auth.test", "token=REDACTED"),
+		in("conversations.history", "channel=C01&token=REDACTED"),
+		in("conversations.history", "channel=C01&cursor=abc&token=REDACTED"),
+		in("conversations.replies", "channel=C01&token=REDACTED&ts=1.000"),
+		in("conversations.replies", "channel=C02&token=REDACTED&ts=2.000"),
+	}}
+	assert.Equal(t, []string{"C01", "C02:2.000"}, recordedLinks(c))
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/trace"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+)
+
+// Scopes probes what the token of the provider can do, and prints the
+// capability matrix with the slackdump features, that depend on each
+// capability, to w.
+func Scopes(ctx context.Context, cfg config.Params, prov auth.Provider, w io.Writer) error {
+	ctx, task := trace.NewTask(ctx, "Scopes")
+	defer task.End()
+
+	sess, err := slackdump.NewWithOptions(ctx, prov, cfg.Options)
+	if err != nil {
+		return err
+	}
+	caps, err := sess.Capabilities(ctx)
+	if err != nil {
+		return err
+	}
+	return printCapabilities(w, sess.Scopes(), caps)
+}
+
+// printCapabilities prints the token scopes, and the capability matrix.
+func printCapabilities(w io.Writer, scopes []string, caps []slackdump.Capability) error {
+	if scopes == nil {
+		fmt.Fprintln(w, "Scopes: unknown (the browser tokens have the permissions of the user)")
+	} else {
+		fmt.Fprintf(w, "Scopes: %s\n", strings.Join(scopes, ", "))
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CAPABILITY\tACCESS\tFEATURES\tREASON")
+	for _, c := range caps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Name, c.Access, c.Features, c.Reason)
+	}
+	return tw.Flush()
+}
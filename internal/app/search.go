@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"runtime/trace"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+)
+
+// searchFilesFile is the name of the file with the files, that match the
+// search query, in the output directory or archive.
+const searchFilesFile = "search_files.json"
+
+// Search saves the messages, that match the search query, to the Output.Base
+// directory or archive, one file per conversation, named by the filename
+// template, as in the dump mode.  If the Search.Files is set, the files, that
+// match the query, are saved to search_files.json as well.
+func Search(ctx context.Context, cfg config.Params, prov auth.Provider) error {
+	ctx, task := trace.NewTask(ctx, "Search")
+	defer task.End()
+
+	sess, err := slackdump.NewWithOptions(ctx, prov, cfg.Options)
+	if err != nil {
+		return err
+	}
+	dm := &dump{sess: sess, cfg: cfg, log: cfg.Logger()}
+
+	tmpl, err := cfg.CompileTemplates()
+	if err != nil {
+		return err
+	}
+	convs, err := sess.SearchMessages(ctx, cfg.Search.Query)
+	if err != nil {
+		return err
+	}
+
+	fs, err := fsadapter.New(cfg.Output.Base)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	var n int
+	for i := range convs {
+		if err := dm.writeFiles(fs, renderFilename(tmpl, &convs[i]), &convs[i]); err != nil {
+			return err
+		}
+		n += len(convs[i].Messages)
+	}
+	cfg.Logger().Printf("saved %d message(s) in %d conversation(s), that match %q, to %s", n, len(convs), cfg.Search.Query, cfg.Output.Base)
+
+	if cfg.Search.Files {
+		files, err := sess.SearchFiles(ctx, cfg.Search.Query)
+		if err != nil {
+			return err
+		}
+		if err := dm.writeJSON(fs, searchFilesFile, files); err != nil {
+			return err
+		}
+		cfg.Logger().Printf("saved %d file(s), that match %q, to %s", len(files), cfg.Search.Query, searchFilesFile)
+	}
+	return slackdump.WriteManifest(fs, sess.Manifest())
+}
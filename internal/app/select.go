@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// starrer returns the conversations, starred by the current user.
+type starrer interface {
+	StarredChannels(ctx context.Context) ([]string, error)
+}
+
+// selectChannels adds the conversations of the input selector to the include
+// list of the input.  The excluded conversations stay excluded.
+func selectChannels(ctx context.Context, s starrer, in *config.Input, lg logger.Interface) error {
+	if in.Select != config.SelectStarred {
+		return nil
+	}
+	ids, err := s.StarredChannels(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return errors.New("no starred conversations")
+	}
+	lg.Printf("selected %d starred conversation(s)", len(ids))
+	if in.List == nil {
+		in.List = new(structures.EntityList)
+	}
+	in.List.AddInclude(ids...)
+	return nil
+}
+
+// nameResolver resolves the channel names to the IDs.
+type nameResolver interface {
+	ResolveChannels(ctx context.Context, names ...string) (map[string]string, error)
+}
+
+// resolveNames replaces the channel names, i.e. #general, in the include
+// and exclude lists of the input with the channel IDs.
+func resolveNames(ctx context.Context, r nameResolver, in *config.Input, lg logger.Interface) error {
+	if in.List == nil {
+		return nil
+	}
+	var names []string
+	for _, list := range [][]string{in.List.Include, in.List.Exclude} {
+		for _, ent := range list {
+			if structures.IsChannelName(ent) {
+				names = append(names, ent)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	ids, err := r.ResolveChannels(ctx, names...)
+	if err != nil {
+		return err
+	}
+	for _, list := range [][]string{in.List.Include, in.List.Exclude} {
+		for i, ent := range list {
+			if id, ok := ids[ent]; ok {
+				lg.Debugf("%s: resolved to %s", ent, id)
+				list[i] = id
+			}
+		}
+		sort.Strings(list)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+type fakeStarrer []string
+
+func (f fakeStarrer) StarredChannels(context.Context) ([]string, error) {
+	return f, nil
+}
+
+func Test_selectChannels(t *testing.T) {
+	in := config.Input{
+		List:   &structures.EntityList{Include: []string{"C03"}, Exclude: []string{"D01"}},
+		Select: config.SelectStarred,
+	}
+	require.NoError(t, selectChannels(context.Background(), fakeStarrer{"C01", "D01", "C03"}, &in, logger.Silent))
+	assert.Equal(t, &structures.EntityList{Include: []string{"C01", "C03"}, Exclude: []string{"D01"}}, in.List)
+
+	in = config.Input{List: &structures.EntityList{}, Select: config.SelectStarred}
+	assert.Error(t, selectChannels(context.Background(), fakeStarrer{}, &in, logger.Silent), "nothing starred")
+
+	in = config.Input{List: &structures.EntityList{Include: []string{"C03"}}}
+	require.NoError(t, selectChannels(context.Background(), fakeStarrer{"C01"}, &in, logger.Silent))
+	assert.Equal(t, []string{"C03"}, in.List.Include, "no selector")
+}
+
+type fakeResolver map[string]string
+
+func (f fakeResolver) ResolveChannels(_ context.Context, names ...string) (map[string]string, error) {
+	ids := make(map[string]string, len(names))
+	for _, name := range names {
+		id, ok := f[name]
+		if !ok {
+			return nil, errors.New("not found: " + name)
+		}
+		ids[name] = id
+	}
+	return ids, nil
+}
+
+func Test_resolveNames(t *testing.T) {
+	r := fakeResolver{"#general": "C02", "#random": "C01"}
+	in := config.Input{List: &structures.EntityList{Include: []string{"#general", "C03"}, Exclude: []string{"#random"}}}
+	require.NoError(t, resolveNames(context.Background(), r, &in, logger.Silent))
+	assert.Equal(t, &structures.EntityList{Include: []string{"C02", "C03"}, Exclude: []string{"C01"}}, in.List)
+
+	in = config.Input{List: &structures.EntityList{Include: []string{"#missing"}}}
+	assert.Error(t, resolveNames(context.Background(), r, &in, logger.Silent))
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/apiserver"
+	"github.com/rusq/slackdump/v2/internal/metrics"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// shutdownTimeout is the time given to the server to finish serving requests
+// on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+// listenAndServe serves the handler on the address addr until ctx is
+// cancelled.  onListen is called, once the listener is ready.
+func listenAndServe(ctx context.Context, addr string, handler http.Handler, lg logger.Interface, onListen func(addr net.Addr)) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(sctx); err != nil {
+			lg.Printf("server shutdown: %s", err)
+		}
+	}()
+
+	if onListen != nil {
+		onListen(l.Addr())
+	}
+	if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// serveMetrics serves the metrics on the address addr until ctx is
+// cancelled.  Errors are logged, as metrics are not essential for the run.
+func serveMetrics(ctx context.Context, addr string, lg logger.Interface) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	if err := listenAndServe(ctx, addr, mux, lg, func(addr net.Addr) {
+		lg.Printf("serving metrics on http://%s/metrics", addr)
+	}); err != nil {
+		lg.Printf("metrics server: %s", err)
+	}
+}
+
+// pushMetrics pushes the metrics, with the result of the run, to the
+// Pushgateway.  Errors are logged, as metrics are not essential for the run.
+func pushMetrics(gateway string, runErr error, lg logger.Interface) {
+	if runErr == nil {
+		metrics.RunSuccess.Set(1)
+	} else {
+		metrics.RunSuccess.Set(0)
+	}
+	metrics.RunCompleted.Set(time.Now().Unix())
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := metrics.Push(ctx, gateway); err != nil {
+		lg.Printf("error pushing the metrics: %s", err)
+	}
+}
+
+// ServeAPI starts the read-only REST API server for the archive src on the
+// address addr.  Clients must authenticate with the token, if the token is
+// empty, a random one is generated and printed to the log.  If the full text
+// index exists, it is used for search, see View for the index lookup rules.
+func ServeAPI(ctx context.Context, src string, addr string, token string, indexFile string, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	ar, err := openArchive(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	if token == "" {
+		if token, err = randomToken(); err != nil {
+			return err
+		}
+		lg.Printf("generated API token: %s", token)
+	}
+	opts := []apiserver.Option{apiserver.WithToken(token), apiserver.WithLogger(lg)}
+	idx, err := loadIndex(src, indexFile)
+	if err != nil {
+		return err
+	}
+	if idx != nil {
+		opts = append(opts, apiserver.WithIndex(idx))
+	}
+	srv, err := apiserver.New(ar, opts...)
+	if err != nil {
+		return err
+	}
+	return listenAndServe(ctx, addr, srv, lg, func(addr net.Addr) {
+		lg.Printf("serving API for %s %q on http://%s, press Ctrl+C to stop", ar.Type(), ar.Name(), addr)
+	})
+}
+
+// randomToken generates the random access token.
+func randomToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/sign"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+const (
+	manifestExt  = ".sha256"
+	signatureExt = ".minisig"
+)
+
+// ManifestName returns the name of the checksum manifest of the archive src.
+// The manifest is placed next to the archive, and its signature is the
+// manifest name with the ".minisig" extension.
+func ManifestName(src string) string {
+	return filepath.Clean(src) + manifestExt
+}
+
+// GenerateSigningKey generates the new signing key pair, and saves the secret
+// key to name+".key" and the public key to name+".pub".  If the password is
+// empty, the secret key is saved unencrypted.  Existing files are not
+// overwritten.
+func GenerateSigningKey(name string, password []byte, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	sk, err := sign.GenerateKey()
+	if err != nil {
+		return err
+	}
+	var secret []byte
+	if len(password) == 0 {
+		lg.Printf("warning: the secret key is not encrypted, keep it safe")
+		secret, err = sk.MarshalText()
+	} else {
+		secret, err = sk.MarshalEncrypted(password)
+	}
+	if err != nil {
+		return err
+	}
+	public, err := sk.Public().MarshalText()
+	if err != nil {
+		return err
+	}
+	if err := writeNewFile(name+".key", secret, 0600); err != nil {
+		return err
+	}
+	if err := writeNewFile(name+".pub", public, 0644); err != nil {
+		return err
+	}
+	lg.Printf("generated the key %s: secret key %s.key, public key %s.pub", sk.ID, name, name)
+	return nil
+}
+
+func writeNewFile(name string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SignArchive creates the checksum manifest of the files of the archive src,
+// that can be a directory, a ZIP file, or any other file, i.e. the encrypted
+// archive, and signs it with the secret key from the keyFile.  password is
+// called, if the secret key is encrypted.
+func SignArchive(ctx context.Context, src string, keyFile string, password func() ([]byte, error), lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return err
+	}
+	sk, err := sign.ParseSecretKey(data, password)
+	if err != nil {
+		return fmt.Errorf("%s: %w", keyFile, err)
+	}
+	fsys, closer, err := archiveFS(src)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	manifest, err := sign.Manifest(ctx, fsys)
+	if err != nil {
+		return err
+	}
+	name := ManifestName(src)
+	trusted := fmt.Sprintf("timestamp:%d\tfile:%s\thashed", time.Now().Unix(), filepath.Base(name))
+	if err := os.WriteFile(name, manifest, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(name+signatureExt, sk.Sign(manifest, trusted), 0644); err != nil {
+		return err
+	}
+	lg.Printf("signed %d file(s) of %s with the key %s: %s", strings.Count(string(manifest), "\n"), src, sk.ID, name+signatureExt)
+	return nil
+}
+
+// updateManifest regenerates the checksum manifest of the directory archive
+// dir, if it exists, after the archive files were changed.  The signature of
+// the manifest can't be updated without the secret key, so the user is asked
+// to sign the archive again.
+func updateManifest(ctx context.Context, dir string, lg logger.Interface) error {
+	name := ManifestName(dir)
+	if _, err := os.Stat(name); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	manifest, err := sign.Manifest(ctx, os.DirFS(dir))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(name, manifest, 0644); err != nil {
+		return err
+	}
+	lg.Printf("updated the checksum manifest %s, the signature %s is no longer valid, sign the archive again", name, name+signatureExt)
+	return nil
+}
+
+// VerifyArchive verifies the signature of the checksum manifest of the
+// archive src with the public key, and checks the archive files against the
+// manifest.  The public key is the public key file name, or the key itself.
+func VerifyArchive(ctx context.Context, src string, publicKey string, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	data, err := os.ReadFile(publicKey)
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = []byte(publicKey), nil
+	}
+	if err != nil {
+		return err
+	}
+	pk, err := sign.ParsePublicKey(data)
+	if err != nil {
+		return err
+	}
+	name := ManifestName(src)
+	manifest, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	sig, err := os.ReadFile(name + signatureExt)
+	if err != nil {
+		return err
+	}
+	trusted, err := pk.Verify(manifest, sig)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name+signatureExt, err)
+	}
+	lg.Printf("signature of %s is valid, key %s, trusted comment: %s", name, pk.ID, trusted)
+
+	fsys, closer, err := archiveFS(src)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	if err := sign.Check(ctx, fsys, manifest); err != nil {
+		var ce *sign.CheckError
+		if errors.As(err, &ce) {
+			for _, fn := range ce.Modified {
+				lg.Printf("modified: %s", fn)
+			}
+			for _, fn := range ce.Missing {
+				lg.Printf("missing: %s", fn)
+			}
+			for _, fn := range ce.Extra {
+				lg.Printf("not in the manifest: %s", fn)
+			}
+		}
+		return err
+	}
+	lg.Printf("all files of %s match the manifest", src)
+	return nil
+}
+
+// archiveFS returns the filesystem of the archive src.  The directories and
+// ZIP files are opened as is, any other file is the only file of the
+// returned filesystem.
+func archiveFS(src string) (fs.FS, io.Closer, error) {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fi.IsDir() {
+		return os.DirFS(src), io.NopCloser(nil), nil
+	}
+	if strings.EqualFold(filepath.Ext(src), ".zip") {
+		zr, err := zip.OpenReader(src)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, zr, nil
+	}
+	return singleFileFS{FS: os.DirFS(filepath.Dir(src)), name: fi.Name()}, io.NopCloser(nil), nil
+}
+
+// singleFileFS is the filesystem of the directory, that lists only the file
+// name.
+type singleFileFS struct {
+	fs.FS
+	name string
+}
+
+func (s singleFileFS) ReadDir(dir string) ([]fs.DirEntry, error) {
+	if dir != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrNotExist}
+	}
+	entries, err := fs.ReadDir(s.FS, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Name() == s.name {
+			return []fs.DirEntry{e}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/internal/sign"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func TestSignArchive(t *testing.T) {
+	ctx := context.Background()
+	keys := t.TempDir()
+	key := filepath.Join(keys, "archive")
+	require.NoError(t, GenerateSigningKey(key, nil, logger.Silent))
+	assert.Error(t, GenerateSigningKey(key, nil, logger.Silent), "existing keys should not be overwritten")
+	other := filepath.Join(keys, "other")
+	require.NoError(t, GenerateSigningKey(other, nil, logger.Silent))
+
+	files := map[string]string{
+		"channels.json":           `[{"id": "C01", "name": "general"}]`,
+		"general/2023-01-01.json": `[{"text": "hello"}]`,
+	}
+
+	t.Run("directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "export")
+		writeTree(t, dir, files)
+		require.NoError(t, SignArchive(ctx, dir+"/", key+".key", nil, logger.Silent))
+		assert.FileExists(t, dir+".sha256")
+		assert.FileExists(t, dir+".sha256.minisig")
+
+		require.NoError(t, VerifyArchive(ctx, dir, key+".pub", logger.Silent))
+		assert.ErrorIs(t, VerifyArchive(ctx, dir, other+".pub", logger.Silent), sign.ErrKeyMismatch)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "general", "2023-01-01.json"), []byte(`[{"text": "forged"}]`), 0644))
+		var ce *sign.CheckError
+		require.ErrorAs(t, VerifyArchive(ctx, dir, key+".pub", logger.Silent), &ce)
+		assert.Equal(t, []string{"general/2023-01-01.json"}, ce.Modified)
+	})
+	t.Run("zip", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "export.zip")
+		f, err := os.Create(name)
+		require.NoError(t, err)
+		zw := zip.NewWriter(f)
+		for fn, content := range files {
+			w, err := zw.Create(fn)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+		require.NoError(t, f.Close())
+
+		require.NoError(t, SignArchive(ctx, name, key+".key", nil, logger.Silent))
+		manifest, err := os.ReadFile(name + ".sha256")
+		require.NoError(t, err)
+		sums, err := sign.ParseManifest(manifest)
+		require.NoError(t, err)
+		assert.Len(t, sums, 2)
+
+		pub, err := os.ReadFile(key + ".pub")
+		require.NoError(t, err)
+		// the public key can be passed as the string.
+		require.NoError(t, VerifyArchive(ctx, name, string(pub[len(pub)-57:]), logger.Silent))
+	})
+	t.Run("other file", func(t *testing.T) {
+		dir := t.TempDir()
+		name := filepath.Join(dir, "export.zip.age")
+		require.NoError(t, os.WriteFile(name, []byte("encrypted"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "unrelated"), []byte("x"), 0644))
+		require.NoError(t, SignArchive(ctx, name, key+".key", nil, logger.Silent))
+		manifest, err := os.ReadFile(name + ".sha256")
+		require.NoError(t, err)
+		sums, err := sign.ParseManifest(manifest)
+		require.NoError(t, err)
+		assert.Contains(t, sums, "export.zip.age")
+		assert.Len(t, sums, 1)
+		require.NoError(t, VerifyArchive(ctx, name, key+".pub", logger.Silent))
+
+		require.NoError(t, os.WriteFile(name+".sha256", []byte("0000000000000000000000000000000000000000000000000000000000000000  export.zip.age\n"), 0644))
+		assert.ErrorIs(t, VerifyArchive(ctx, name, key+".pub", logger.Silent), sign.ErrInvalidSignature)
+	})
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// skipKinds are the kinds of the skip-list items.
+var skipKinds = []string{slackdump.SkipConversation, slackdump.SkipThread, slackdump.SkipFile}
+
+func openSkipList(cacheDir string) (*slackdump.SkipList, error) {
+	return slackdump.OpenSkipList(filepath.Join(cacheDir, slackdump.SkipListFile), 0)
+}
+
+// SkipListShow writes the items of the skip-list in the cache directory to w.
+// The items, that failed in at least -skip-after runs, are skipped by the
+// runs with that flag.
+func SkipListShow(w io.Writer, cacheDir string) error {
+	sl, err := openSkipList(cacheDir)
+	if err != nil {
+		return err
+	}
+	ee := sl.Entries()
+	if len(ee) == 0 {
+		_, err := fmt.Fprintln(w, "the skip-list is empty")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tID\tFAILED RUNS\tLAST FAILURE\tLAST ERROR")
+	for _, e := range ee {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", e.Kind, e.ID, e.Failures, e.Last.Local().Format(time.RFC3339), e.Reason)
+	}
+	return tw.Flush()
+}
+
+// SkipListRemove removes the items from the skip-list in the cache directory,
+// so that the next run tries them again.  The item is "<kind>:<id>", or the
+// ID, that is removed for all kinds.
+func SkipListRemove(cacheDir string, items []string, lg logger.Interface) error {
+	sl, err := openSkipList(cacheDir)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		kinds := skipKinds
+		id := item
+		if kind, rest, ok := strings.Cut(item, ":"); ok && isSkipKind(kind) {
+			kinds, id = []string{kind}, rest
+		}
+		var found bool
+		for _, kind := range kinds {
+			ok, err := sl.Remove(kind, id)
+			if err != nil {
+				return err
+			}
+			found = found || ok
+		}
+		if !found {
+			return fmt.Errorf("%s is not on the skip-list", item)
+		}
+		lg.Printf("%s removed from the skip-list", item)
+	}
+	return nil
+}
+
+// SkipListReset removes all items from the skip-list in the cache directory.
+func SkipListReset(cacheDir string, lg logger.Interface) error {
+	sl, err := openSkipList(cacheDir)
+	if err != nil {
+		return err
+	}
+	n := len(sl.Entries())
+	if err := sl.Reset(); err != nil {
+		return err
+	}
+	lg.Printf("%d item(s) removed from the skip-list", n)
+	return nil
+}
+
+func isSkipKind(s string) bool {
+	for _, k := range skipKinds {
+		if s == k {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func TestSkipList(t *testing.T) {
+	dir := t.TempDir()
+	sl, err := slackdump.OpenSkipList(filepath.Join(dir, slackdump.SkipListFile), 1)
+	require.NoError(t, err)
+	errServer := errors.New("500 Internal Server Error")
+	require.NoError(t, sl.Fail(slackdump.SkipConversation, "C01", errServer))
+	require.NoError(t, sl.Fail(slackdump.SkipThread, "C01:1.000", errServer))
+	require.NoError(t, sl.Fail(slackdump.SkipFile, "F01", errServer))
+
+	var buf bytes.Buffer
+	require.NoError(t, SkipListShow(&buf, dir))
+	assert.Contains(t, buf.String(), "C01:1.000")
+	assert.Contains(t, buf.String(), errServer.Error())
+
+	require.NoError(t, SkipListRemove(dir, []string{"thread:C01:1.000", "F01"}, logger.Silent))
+	assert.Error(t, SkipListRemove(dir, []string{"file:F02"}, logger.Silent))
+	sl, err = slackdump.OpenSkipList(filepath.Join(dir, slackdump.SkipListFile), 1)
+	require.NoError(t, err)
+	ee := sl.Entries()
+	require.Len(t, ee, 1)
+	assert.Equal(t, "C01", ee[0].ID)
+
+	require.NoError(t, SkipListReset(dir, logger.Silent))
+	buf.Reset()
+	require.NoError(t, SkipListShow(&buf, dir))
+	assert.Equal(t, "the skip-list is empty\n", buf.String())
+}
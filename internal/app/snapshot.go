@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"runtime/trace"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/snapshot"
+)
+
+// snapshotChanTypes are the channel types of the snapshot, the direct
+// messages are not tracked.
+var snapshotChanTypes = []string{"public_channel", "private_channel"}
+
+// Snapshot saves the snapshot of the channel and user lists of the workspace
+// to the output file ("-" for the Stdout).
+func Snapshot(ctx context.Context, cfg config.Params, prov auth.Provider, output string) error {
+	ctx, task := trace.NewTask(ctx, "Snapshot")
+	defer task.End()
+
+	// the users are always fetched from the API, as the cached ones might be
+	// outdated.
+	cfg.Options.UserCachePolicy = slackdump.CPAlways
+	sess, err := slackdump.NewWithOptions(ctx, prov, cfg.Options)
+	if err != nil {
+		return err
+	}
+	chans, err := sess.GetChannels(ctx, snapshotChanTypes...)
+	if err != nil {
+		return err
+	}
+	snap := snapshot.New(sess.Manifest().TeamID, chans, sess.Users)
+
+	f, err := createFile(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := snap.Write(f); err != nil {
+		return err
+	}
+	cfg.Logger().Printf("snapshot of %d channel(s) and %d user(s) saved to %s", len(snap.Channels), len(snap.Users), output)
+	return nil
+}
+
+// SnapshotDiff prints the changes of the workspace between the snapshots
+// oldFile and newFile in the format: "text" or "json".
+func SnapshotDiff(oldFile, newFile string, output string, format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("invalid format: %q, must be one of: text, json", format)
+	}
+	old, err := snapshot.Load(oldFile)
+	if err != nil {
+		return err
+	}
+	new, err := snapshot.Load(newFile)
+	if err != nil {
+		return err
+	}
+	if old.TeamID != "" && new.TeamID != "" && old.TeamID != new.TeamID {
+		return fmt.Errorf("the snapshots are of the different workspaces: %s and %s", old.TeamID, new.TeamID)
+	}
+	d := snapshot.Compare(old, new)
+
+	f, err := createFile(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if format == "json" {
+		return d.WriteJSON(f)
+	}
+	return d.WriteText(f)
+}
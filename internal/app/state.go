@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/state"
+	"github.com/rusq/slackdump/v2/internal/structures"
+)
+
+// pendingFile is the name of the file, that lists the conversations, that
+// were not processed, because the run was interrupted, i.e. by the deadline
+// budget.  It is the input for the next run.
+const pendingFile = "slackdump-pending.txt"
+
+// stateStore returns the store of the run state.  Unless the state location
+// is set, it's the pendingFile in the current directory.
+func stateStore(cfg config.Params) (state.Store, error) {
+	if cfg.State == "" {
+		return state.File(pendingFile), nil
+	}
+	return state.Open(cfg.State, cfg.Upload)
+}
+
+// writePending saves the pending conversation ids into the state store.
+func writePending(ctx context.Context, cfg config.Params, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	st, err := stateStore(cfg)
+	if err != nil {
+		return err
+	}
+	if err := st.Save(ctx, ids); err != nil {
+		return fmt.Errorf("failed to save the pending conversations: %w", err)
+	}
+	if cfg.State == "" {
+		cfg.Logger().Printf("%d conversation(s) were not processed, to continue, run slackdump with: @%s", len(ids), pendingFile)
+	} else {
+		cfg.Logger().Printf("%d conversation(s) were not processed, saved to %s, the next run will continue from there", len(ids), st)
+	}
+	return nil
+}
+
+// resumeState replaces the input of cfg with the pending conversations from
+// the run state, if there are any.  It returns the store, so that the state
+// can be cleared once the run is complete.
+func resumeState(ctx context.Context, cfg *config.Params) (state.Store, error) {
+	st, err := state.Open(cfg.State, cfg.Upload)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := st.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the run state: %w", err)
+	}
+	if len(ids) == 0 {
+		return st, nil
+	}
+	list, err := structures.MakeEntityList(ids)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Input.List = list
+	cfg.Input.Select = ""
+	cfg.Logger().Printf("resuming %d conversation(s) from %s", len(ids), st)
+	return st, nil
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/stats"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// EmojiStats writes the emoji usage statistics of the archive src to the
+// output file ("-" for the Stdout) in the format: "text" for the table of the
+// top emoji, or "csv" for the breakdown by the channel, user and period, the
+// periods are in the time zone loc.
+func EmojiStats(ctx context.Context, src string, output string, format string, period stats.Period, loc *time.Location, top int, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	if format != "text" && format != "csv" {
+		return fmt.Errorf("invalid format: %q, must be one of: text, csv", format)
+	}
+	ar, err := openArchive(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	st, err := stats.Emoji(ctx, ar, period, loc)
+	if err != nil {
+		return err
+	}
+	lg.Debugf("%s: %d emoji usage record(s)", ar.Name(), len(st.Uses))
+
+	f, err := createFile(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if format == "csv" {
+		return st.ToCSV(f)
+	}
+	return st.ToText(f, top)
+}
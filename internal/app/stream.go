@@ -0,0 +1,26 @@
+package app
+
+import (
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/stream"
+)
+
+// openStream opens the NDJSON event stream, if the output is the stream
+// target, otherwise it returns nil Writer, that discards the events.  The
+// returned close function must be called at the end of the run, it logs the
+// stream errors, as they should not fail the run.
+func openStream(cfg config.Params) (*stream.Writer, func(), error) {
+	if !cfg.Output.IsStream() {
+		return nil, func() {}, nil
+	}
+	w, err := stream.Open(cfg.Output.Filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	w.SetPolicy(cfg.Output.Stream)
+	return w, func() {
+		if err := w.Close(); err != nil {
+			cfg.Logger().Printf("event stream error, some events were not written: %s", err)
+		}
+	}, nil
+}
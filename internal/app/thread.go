@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/trace"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// threadUsersFile is the name of the file with the users involved in the
+// thread, in the root of the thread mini-archive.  The archive package reads
+// it as the users of the dump.
+const threadUsersFile = "users.json"
+
+// threadFile is the single file output of the thread mode: the thread with
+// the users involved in it.
+type threadFile struct {
+	*types.Conversation
+	Users types.Users `json:"users"`
+}
+
+// DumpThread saves the single thread of the permalink in the input.  Instead
+// of fetching all users of the workspace, only the users involved in the
+// thread are requested.  If the Output.Base is set, the thread, its files and
+// users are saved into the mini-archive there, otherwise the thread with the
+// users is written as the single JSON to the output file.
+func DumpThread(ctx context.Context, cfg config.Params, prov auth.Provider) error {
+	ctx, task := trace.NewTask(ctx, "DumpThread")
+	defer task.End()
+
+	opts := cfg.Options
+	opts.NoUserCache = true // only the involved users are fetched.
+	sess, err := slackdump.NewWithOptions(ctx, prov, opts)
+	if err != nil {
+		return err
+	}
+	dm := &dump{sess: sess, cfg: cfg, log: cfg.Logger()}
+	link := cfg.Input.List.Include[0]
+	if cfg.Output.Base == "" {
+		return dm.threadFile(ctx, link)
+	}
+	return dm.threadArchive(ctx, link)
+}
+
+// fetchThread fetches the thread of the link and the users involved in it,
+// and populates the session user index with them for the text output.
+func (app *dump) fetchThread(ctx context.Context, link string) (*types.Conversation, types.Users, error) {
+	cnv, err := app.sess.Dump(ctx, link, time.Time(app.cfg.Oldest), time.Time(app.cfg.Latest))
+	if err != nil {
+		return nil, nil, err
+	}
+	users, err := app.sess.GetUsersInfo(ctx, cnv.UserIDs()...)
+	if err != nil {
+		return nil, nil, err
+	}
+	app.sess.Users = users
+	app.sess.UserIndex = users.IndexByID()
+	app.log.Printf("thread %s: %d message(s), %d user(s)", cnv, countMessages(cnv.Messages), len(users))
+	return cnv, users, nil
+}
+
+// threadArchive saves the thread of the link with its files into the
+// mini-archive in the base directory or ZIP file.
+func (app *dump) threadArchive(ctx context.Context, link string) error {
+	fsc, err := fsadapter.New(app.cfg.Output.Base)
+	if err != nil {
+		return err
+	}
+	defer fsc.Close()
+	app.sess.SetFS(fsc)
+
+	tmpl, err := app.cfg.CompileTemplates()
+	if err != nil {
+		return err
+	}
+	cnv, users, err := app.fetchThread(ctx, link)
+	if err != nil {
+		return err
+	}
+	if err := app.writeFiles(fsc, renderFilename(tmpl, cnv), cnv); err != nil {
+		return err
+	}
+	if err := app.writeJSON(fsc, threadUsersFile, users); err != nil {
+		return err
+	}
+	return slackdump.WriteManifest(fsc, app.sess.Manifest())
+}
+
+// threadFile writes the thread of the link with the users into the output
+// file, as JSON, or as text, if the text format is requested.
+func (app *dump) threadFile(ctx context.Context, link string) error {
+	cnv, users, err := app.fetchThread(ctx, link)
+	if err != nil {
+		return err
+	}
+	f, err := createFile(app.cfg.Output.Filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if app.cfg.Output.IsText() {
+		cat := app.cfg.Output.Lang.Get()
+		return cnv.ToTextFormat(f, app.sess.UserIndex, types.TextFormat{
+			Location:   app.cfg.Output.TZ.Get(),
+			TimeLayout: cat.TextTime,
+			Text:       cat.MessageText,
+		})
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(threadFile{Conversation: cnv, Users: users})
+}
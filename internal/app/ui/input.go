@@ -32,3 +32,12 @@ func StringRequire(msg, help string) (string, error) {
 func String(msg, help string) (string, error) {
 	return Input(msg, help, nil)
 }
+
+// Password asks user to input the password, the input is not echoed.
+func Password(msg, help string) (string, error) {
+	var p string
+	if err := survey.AskOne(&survey.Password{Message: msg, Help: help}, &p); err != nil {
+		return "", err
+	}
+	return p, nil
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/upload"
+)
+
+// uploadArchive uploads the finished archive at location to the configured
+// bucket.  Unless set explicitly, objects are tagged with the date of the
+// run.
+func uploadArchive(ctx context.Context, cfg config.Params, location string) error {
+	ucfg := cfg.Upload
+	if ucfg.StateDir == "" {
+		ucfg.StateDir = cfg.Options.CacheDir
+	}
+	tags := make(map[string]string, len(ucfg.Tags)+1)
+	for k, v := range ucfg.Tags {
+		tags[k] = v
+	}
+	if _, ok := tags["date"]; !ok {
+		tags["date"] = time.Now().Format("2006-01-02")
+	}
+	ucfg.Tags = tags
+
+	up, err := upload.New(ucfg, upload.WithLogger(cfg.Logger()))
+	if err != nil {
+		return err
+	}
+	cfg.Logger().Printf("uploading %s to %s", location, ucfg.Target)
+	return up.Upload(ctx, location)
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/usermap"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// GenerateUserMap drafts the user mapping from the users of the archive src,
+// and writes it to the output file in the format f.
+func GenerateUserMap(src string, output string, f usermap.Format, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	ar, err := archive.Open(src)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+	users, err := ar.Users()
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return errors.New("there are no users in the archive")
+	}
+
+	w, err := createFile(output)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := usermap.Generate(users).Write(w, f); err != nil {
+		return err
+	}
+	lg.Debugf("%s: %d user(s) in the mapping", ar.Name(), len(users))
+	return nil
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/schema"
+)
+
+// reDayFile matches the names of the message files of the export.
+var reDayFile = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\.json$`)
+
+// Validate checks the JSON files of the dump or export src against the
+// published schemas, see package schema.  The violations are logged, and the
+// error is returned, if any file does not match its schema.  The files, that
+// have no schema, i.e. the files of the slackdump extensions, are skipped.
+func Validate(ctx context.Context, src string, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	ar, err := openArchive(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	v := validator{fsys: ar.FS(), lg: lg}
+	switch ar.Type() {
+	case archive.TExport:
+		err = v.export(ctx, ar)
+	case archive.TDump:
+		err = v.dump(ctx)
+	default:
+		return fmt.Errorf("%s: %w", src, archive.ErrUnknownFormat)
+	}
+	if err != nil {
+		return err
+	}
+	if v.invalid > 0 {
+		return fmt.Errorf("%s: %d of %d file(s) do not match the schema", src, v.invalid, v.checked)
+	}
+	lg.Printf("%s: %d file(s) match the schema", src, v.checked)
+	return nil
+}
+
+// validateOutput validates the output of the run, and the export, converted
+// while dumping.
+func validateOutput(ctx context.Context, cfg config.Params) error {
+	for _, loc := range []string{cfg.OutputLocation(), cfg.DumpExport} {
+		if loc == "" {
+			continue
+		}
+		if err := Validate(ctx, loc, cfg.Logger()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type validator struct {
+	fsys    fs.FS
+	lg      logger.Interface
+	checked int
+	invalid int
+}
+
+// check validates the file name against the schema sch.
+func (v *validator) check(name string, sch string) error {
+	s, err := schema.Get(sch)
+	if err != nil {
+		return err
+	}
+	data, err := fs.ReadFile(v.fsys, name)
+	if err != nil {
+		return err
+	}
+	v.checked++
+	if err := s.Validate(data); err != nil {
+		v.invalid++
+		var ve *schema.ValidationError
+		if !errors.As(err, &ve) {
+			v.lg.Printf("%s: %s", name, err)
+			return nil
+		}
+		for _, e := range ve.Errors {
+			v.lg.Printf("%s: %s", name, e)
+		}
+	}
+	return nil
+}
+
+// checkIfExists validates the file name, if it exists.
+func (v *validator) checkIfExists(name string, sch string) error {
+	if _, err := fs.Stat(v.fsys, name); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return v.check(name, sch)
+}
+
+// export validates the index files, and the message files of the channels.
+func (v *validator) export(ctx context.Context, ar *archive.Archive) error {
+	for _, f := range []struct {
+		name   string
+		schema string
+	}{
+		{"channels.json", schema.ExportChannels},
+		{"groups.json", schema.ExportChannels},
+		{"mpims.json", schema.ExportChannels},
+		{"dms.json", schema.ExportDMs},
+		{"users.json", schema.Users},
+	} {
+		if err := v.checkIfExists(f.name, f.schema); err != nil {
+			return err
+		}
+	}
+	teamUsers, err := fs.Glob(v.fsys, "users-*.json")
+	if err != nil {
+		return err
+	}
+	for _, name := range teamUsers {
+		if err := v.check(name, schema.Users); err != nil {
+			return err
+		}
+	}
+	chans, err := ar.Channels()
+	if err != nil {
+		return err
+	}
+	for _, ch := range chans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		dir := archive.ExportDir(v.fsys, ch)
+		entries, err := fs.ReadDir(v.fsys, dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		for _, e := range entries {
+			if !e.Type().IsRegular() || !reDayFile.MatchString(e.Name()) {
+				continue
+			}
+			if err := v.check(path.Join(dir, e.Name()), schema.ExportMessages); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dump validates the conversation files and the users of the dump.  The
+// files in the root, that are not conversations, are skipped, the same way
+// as archive.Open does.
+func (v *validator) dump(ctx context.Context) error {
+	if err := v.checkIfExists("users.json", schema.Users); err != nil {
+		return err
+	}
+	entries, err := fs.ReadDir(v.fsys, ".")
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".json") || e.Name() == "users.json" {
+			continue
+		}
+		data, err := fs.ReadFile(v.fsys, e.Name())
+		if err != nil {
+			return err
+		}
+		var hdr struct {
+			ID *string `json:"channel_id"`
+		}
+		if err := json.Unmarshal(data, &hdr); err != nil || hdr.ID == nil {
+			continue
+		}
+		if err := v.check(e.Name(), schema.Conversation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
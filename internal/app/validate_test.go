@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func TestValidate(t *testing.T) {
+	const (
+		users = `[{"id": "U01", "name": "alice"}]`
+		msgs  = `[{"type": "message", "user": "U01", "text": "hi", "ts": "1672531200.000100"}]`
+	)
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr bool
+	}{
+		{
+			"valid export",
+			map[string]string{
+				"channels.json":           `[{"id": "C01", "name": "general", "members": ["U01"]}]`,
+				"dms.json":                `[{"id": "D01", "created": 1672531200, "members": ["U01", "U02"]}]`,
+				"users.json":              users,
+				"general/2023-01-01.json": msgs,
+				"general/layout.json":     `{"not": "checked"}`,
+				"D01/2023-01-01.json":     msgs,
+			},
+			false,
+		},
+		{
+			"invalid export message",
+			map[string]string{
+				"channels.json":           `[{"id": "C01", "name": "general"}]`,
+				"users.json":              users,
+				"general/2023-01-01.json": `[{"type": "message", "text": "no ts"}]`,
+			},
+			true,
+		},
+		{
+			"invalid export users",
+			map[string]string{
+				"channels.json": `[{"id": "C01", "name": "general"}]`,
+				"users.json":    `[{"id": "U01"}]`,
+			},
+			true,
+		},
+		{
+			"valid dump",
+			map[string]string{
+				"C01.json":       `{"name": "general", "channel_id": "C01", "messages": ` + msgs + `}`,
+				"users.json":     users,
+				"workspace.json": `{"team": "not checked"}`,
+			},
+			false,
+		},
+		{
+			"invalid dump",
+			map[string]string{
+				"C01.json": `{"name": "general", "channel_id": "C01", "messages": [{"ts": 1}]}`,
+			},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTree(t, dir, tt.files)
+			err := Validate(context.Background(), dir, logger.Silent)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
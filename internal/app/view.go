@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/fts"
+	"github.com/rusq/slackdump/v2/internal/i18n"
+	"github.com/rusq/slackdump/v2/internal/remote"
+	"github.com/rusq/slackdump/v2/internal/viewer"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// View starts the web viewer for the archives srcs (export or dump
+// directories or ZIP files) on the address addr.  If several archives are
+// given, they are served under one UI with the workspace switcher.  If the
+// full text index file indexFile exists, it is used for search, it can only
+// be specified for a single archive.  If indexFile is empty, the default index
+// location of each archive is checked.  The pages are in the language of the
+// catalog cat, and the message times are displayed in the time zone loc.
+// View blocks until ctx is cancelled.
+func View(ctx context.Context, srcs []string, addr string, indexFile string, loc *time.Location, cat *i18n.Catalog, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	if len(srcs) == 0 {
+		return errors.New("no archives to view")
+	}
+	if indexFile != "" && len(srcs) > 1 {
+		return errors.New("index file can only be specified for a single archive")
+	}
+
+	var viewers []*viewer.Viewer
+	for _, src := range srcs {
+		v, closeFn, err := newViewer(ctx, src, indexFile, loc, cat, lg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", src, err)
+		}
+		defer closeFn()
+		viewers = append(viewers, v)
+	}
+	var handler http.Handler = viewers[0]
+	if len(viewers) > 1 {
+		f, err := viewer.Federate(viewers...)
+		if err != nil {
+			return err
+		}
+		handler = f
+	}
+
+	return listenAndServe(ctx, addr, handler, lg, func(addr net.Addr) {
+		lg.Printf("viewing %d archive(s), open http://%s in your browser, press Ctrl+C to stop", len(viewers), addr)
+	})
+}
+
+// newViewer opens the archive src and creates the viewer for it.  The
+// returned close function must be called when the viewer is no longer
+// needed.
+func newViewer(ctx context.Context, src string, indexFile string, loc *time.Location, cat *i18n.Catalog, lg logger.Interface) (*viewer.Viewer, func(), error) {
+	ar, err := openArchive(ctx, src)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeFn := func() {
+		if err := ar.Close(); err != nil {
+			lg.Printf("failed to close %s: %s", src, err)
+		}
+	}
+
+	opts := []viewer.Option{viewer.WithLogger(lg), viewer.WithLocation(loc), viewer.WithCatalog(cat)}
+	idx, err := loadIndex(src, indexFile)
+	if err != nil {
+		closeFn()
+		return nil, nil, err
+	}
+	if idx != nil {
+		lg.Printf("%s: using the search index created on %s", ar.Name(), idx.Created.Format(time.RFC3339))
+		opts = append(opts, viewer.WithIndex(idx))
+	} else {
+		lg.Debugf("%s: no search index found, run \"slackdump tools index %s\" to speed up the search", ar.Name(), src)
+	}
+
+	v, err := viewer.New(ar, opts...)
+	if err != nil {
+		closeFn()
+		return nil, nil, err
+	}
+	lg.Printf("loaded %s %q", ar.Type(), ar.Name())
+	return v, closeFn, nil
+}
+
+// loadIndex loads the full text index.  If the filename is empty, the
+// default location for the local archive src is tried, and the missing index
+// is not an error.
+func loadIndex(src string, filename string) (*fts.Index, error) {
+	explicit := filename != ""
+	if !explicit {
+		if remote.IsRemote(src) {
+			return nil, nil
+		}
+		filename = fts.DefaultPath(src)
+	}
+	idx, err := fts.Load(filename)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("search index: %w", err)
+	}
+	return idx, nil
+}
+
+// Index builds the full text index for the archive src and saves it to the
+// file output.  If output is empty, the default location is used.
+func Index(ctx context.Context, src string, output string, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	if output == "" {
+		output = fts.DefaultPath(src)
+	}
+	ar, err := archive.Open(src)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	start := time.Now()
+	idx, err := fts.Build(ctx, ar)
+	if err != nil {
+		return err
+	}
+	if err := idx.Save(output); err != nil {
+		return err
+	}
+	lg.Printf("indexed %d messages (%d terms) in %s, index saved to: %s", len(idx.Docs), len(idx.Postings), time.Since(start), output)
+	return nil
+}
+
+// ViewStatic generates the static site for the archive src in the output
+// directory or ZIP file dst, in the language of the catalog cat, with the
+// message times in the time zone loc.
+func ViewStatic(ctx context.Context, src string, dst string, loc *time.Location, cat *i18n.Catalog, lg logger.Interface) error {
+	if lg == nil {
+		lg = logger.Default
+	}
+	ar, err := openArchive(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	fsa, err := fsadapter.New(dst)
+	if err != nil {
+		return err
+	}
+	defer fsa.Close()
+
+	start := time.Now()
+	if err := viewer.GenerateStatic(ctx, fsa, ar, viewer.WithLogger(lg), viewer.WithLocation(loc), viewer.WithCatalog(cat)); err != nil {
+		return err
+	}
+	lg.Printf("static site for %s %q generated in %s, saved to: %s", ar.Type(), ar.Name(), time.Since(start), dst)
+	return nil
+}
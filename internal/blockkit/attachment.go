@@ -0,0 +1,67 @@
+package blockkit
+
+import (
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// Attachment renders the legacy message attachment a, i.e. the link unfurl
+// or the GitHub and Jira preview, as mrkdwn text: the pretext, the author,
+// the title, linked to the title link, the text, the fields as "*Title*:
+// value", the blocks and the footer.  If the attachment has none of them,
+// the fallback text is returned.
+func Attachment(a *slack.Attachment) string {
+	var lines []string
+	add := func(s string) {
+		if s != "" {
+			lines = append(lines, s)
+		}
+	}
+	add(a.Pretext)
+	if a.AuthorName != "" {
+		add(escape(a.AuthorName))
+	} else {
+		add(escape(a.ServiceName))
+	}
+	if a.Title != "" {
+		if a.TitleLink != "" {
+			add("*<" + a.TitleLink + "|" + escape(a.Title) + ">*")
+		} else {
+			add("*" + escape(a.Title) + "*")
+		}
+	}
+	add(a.Text)
+	for _, f := range a.Fields {
+		switch {
+		case f.Title == "":
+			add(f.Value)
+		case f.Value == "":
+			add("*" + escape(f.Title) + "*")
+		default:
+			add("*" + escape(f.Title) + "*: " + f.Value)
+		}
+	}
+	add(Render(a.Blocks))
+	add(escape(a.Footer))
+	if len(lines) == 0 {
+		return escape(a.Fallback)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Attachments renders the attachments of the message as the quoted mrkdwn
+// text, each line of the attachment is prefixed with "> ".
+func Attachments(atts []slack.Attachment) string {
+	var lines []string
+	for i := range atts {
+		s := Attachment(&atts[i])
+		if s == "" {
+			continue
+		}
+		for _, line := range strings.Split(s, "\n") {
+			lines = append(lines, "> "+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
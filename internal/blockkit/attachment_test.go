@@ -0,0 +1,59 @@
+package blockkit
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachment(t *testing.T) {
+	tests := []struct {
+		name string
+		att  slack.Attachment
+		want string
+	}{
+		{
+			"link unfurl",
+			slack.Attachment{
+				ServiceName: "GitHub",
+				Title:       "Fix <race> #42",
+				TitleLink:   "https://github.com/rusq/slackdump/pull/42",
+				Text:        "Fixes the race in the file watcher.",
+				Fallback:    "[rusq/slackdump] Pull request #42",
+			},
+			"GitHub\n*<https://github.com/rusq/slackdump/pull/42|Fix &lt;race&gt; #42>*\nFixes the race in the file watcher.",
+		},
+		{
+			"fields",
+			slack.Attachment{
+				Pretext: "New issue:",
+				Title:   "PROJ-1",
+				Fields: []slack.AttachmentField{
+					{Title: "Status", Value: "Open"},
+					{Title: "Assignee", Value: "<@U01>"},
+					{Value: "no title"},
+				},
+				Footer: "Jira",
+			},
+			"New issue:\n*PROJ-1*\n*Status*: Open\n*Assignee*: <@U01>\nno title\nJira",
+		},
+		{"fallback only", slack.Attachment{Fallback: "a & b"}, "a &amp; b"},
+		{"empty", slack.Attachment{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Attachment(&tt.att))
+		})
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	atts := []slack.Attachment{
+		{Title: "one", Text: "first"},
+		{},
+		{Fallback: "two"},
+	}
+	assert.Equal(t, "> *one*\n> first\n> two", Attachments(atts))
+	assert.Equal(t, "", Attachments(nil))
+}
@@ -0,0 +1,190 @@
+// Package blockkit renders the Slack Block Kit layouts of the messages, such
+// as the polls (Polly, Simple Poll) and the Workflow Builder forms, as the
+// readable text in the Slack mrkdwn format.  The text can be output as is by
+// the text converters, or converted to HTML by the viewer, as any other
+// message text.
+package blockkit
+
+import (
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// Text returns the readable text of the message m.  If the message has the
+// layout blocks, they are rendered, as this is what the Slack client displays,
+// the message text of such messages is only a notification fallback.  The
+// messages, that have only the rich text blocks, i.e. the ones written by the
+// users, have the equivalent text, and it is returned unchanged.
+func Text(m *slack.Message) string {
+	if m.Text != "" && !hasLayout(m.Blocks) {
+		return m.Text
+	}
+	if s := Render(m.Blocks); s != "" {
+		return s
+	}
+	return m.Text
+}
+
+// hasLayout returns true if blocks has any blocks, other than the rich text.
+func hasLayout(blocks slack.Blocks) bool {
+	for _, b := range blocks.BlockSet {
+		if b.BlockType() != slack.MBTRichText {
+			return true
+		}
+	}
+	return false
+}
+
+// Render renders the blocks as mrkdwn text, one or more lines per block.  The
+// interactive elements, i.e. the buttons, are rendered as their labels in the
+// square brackets, the images as their alt text.  The unknown blocks are
+// skipped.
+func Render(blocks slack.Blocks) string {
+	var lines []string
+	for _, b := range blocks.BlockSet {
+		if s := renderBlock(b); s != "" {
+			lines = append(lines, s)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderBlock(b slack.Block) string {
+	switch b := b.(type) {
+	case *slack.HeaderBlock:
+		if s := text(b.Text); s != "" {
+			return "*" + s + "*"
+		}
+	case *slack.SectionBlock:
+		var lines []string
+		for _, t := range append([]*slack.TextBlockObject{b.Text}, b.Fields...) {
+			if s := text(t); s != "" {
+				lines = append(lines, s)
+			}
+		}
+		if b.Accessory != nil && b.Accessory.ButtonElement != nil {
+			lines = append(lines, button(b.Accessory.ButtonElement))
+		}
+		return strings.Join(lines, "\n")
+	case *slack.ContextBlock:
+		var parts []string
+		for _, e := range b.ContextElements.Elements {
+			switch e := e.(type) {
+			case *slack.TextBlockObject:
+				parts = append(parts, text(e))
+			case *slack.ImageBlockElement:
+				parts = append(parts, escape(e.AltText))
+			}
+		}
+		return strings.Join(nonEmpty(parts), " ")
+	case *slack.ActionBlock:
+		if b.Elements == nil {
+			return ""
+		}
+		var parts []string
+		for _, e := range b.Elements.ElementSet {
+			if btn, ok := e.(*slack.ButtonBlockElement); ok {
+				parts = append(parts, button(btn))
+			}
+		}
+		return strings.Join(parts, " ")
+	case *slack.DividerBlock:
+		return "---"
+	case *slack.ImageBlock:
+		if s := text(b.Title); s != "" {
+			return "[image: " + s + "]"
+		}
+		if b.AltText != "" {
+			return "[image: " + escape(b.AltText) + "]"
+		}
+	case *slack.InputBlock:
+		return text(b.Label)
+	case *slack.RichTextBlock:
+		return richText(b)
+	}
+	return ""
+}
+
+// text returns the text of the text object t as mrkdwn.
+func text(t *slack.TextBlockObject) string {
+	if t == nil {
+		return ""
+	}
+	if t.Type == slack.PlainTextType {
+		return escape(t.Text)
+	}
+	return t.Text
+}
+
+func button(b *slack.ButtonBlockElement) string {
+	return "[" + text(b.Text) + "]"
+}
+
+// richText renders the sections of the rich text block.  Lists, quotes and
+// preformatted elements are not decoded by the slack library, and are skipped.
+func richText(b *slack.RichTextBlock) string {
+	var buf strings.Builder
+	for _, e := range b.Elements {
+		sec, ok := e.(*slack.RichTextSection)
+		if !ok {
+			continue
+		}
+		for _, se := range sec.Elements {
+			switch se := se.(type) {
+			case *slack.RichTextSectionTextElement:
+				buf.WriteString(styled(escape(se.Text), se.Style))
+			case *slack.RichTextSectionLinkElement:
+				if se.Text == "" {
+					buf.WriteString("<" + se.URL + ">")
+				} else {
+					buf.WriteString("<" + se.URL + "|" + escape(se.Text) + ">")
+				}
+			case *slack.RichTextSectionUserElement:
+				buf.WriteString("<@" + se.UserID + ">")
+			case *slack.RichTextSectionChannelElement:
+				buf.WriteString("<#" + se.ChannelID + ">")
+			case *slack.RichTextSectionUserGroupElement:
+				buf.WriteString("<!subteam^" + se.UsergroupID + ">")
+			case *slack.RichTextSectionEmojiElement:
+				buf.WriteString(":" + se.Name + ":")
+			}
+		}
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// styled wraps s in the mrkdwn style markers.  The leading and trailing
+// whitespace is left outside of the markers, otherwise mrkdwn ignores them.
+func styled(s string, st *slack.RichTextSectionTextStyle) string {
+	if st == nil || strings.TrimSpace(s) == "" {
+		return s
+	}
+	var open, end string
+	for _, m := range []struct {
+		on     bool
+		marker string
+	}{{st.Code, "`"}, {st.Bold, "*"}, {st.Italic, "_"}, {st.Strike, "~"}} {
+		if m.on {
+			open, end = open+m.marker, m.marker+end
+		}
+	}
+	trimmed := strings.TrimSpace(s)
+	i := strings.Index(s, trimmed)
+	return s[:i] + open + trimmed + end + s[i+len(trimmed):]
+}
+
+// escape escapes the plain text, the way Slack does for the message text.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func nonEmpty(ss []string) []string {
+	res := ss[:0]
+	for _, s := range ss {
+		if s != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
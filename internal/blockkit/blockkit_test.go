@@ -0,0 +1,114 @@
+package blockkit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pollMsg is the poll message, as posted by the poll apps.
+const pollMsg = `{
+	"type": "message",
+	"text": "This content can't be displayed.",
+	"bot_id": "B01POLL",
+	"blocks": [
+		{"type": "header", "text": {"type": "plain_text", "text": "Lunch & Learn?"}},
+		{"type": "section", "text": {"type": "mrkdwn", "text": "*1. Pizza* ` + "`2`" + `\n<@U01> <@U02>"},
+		 "accessory": {"type": "button", "text": {"type": "plain_text", "text": "Vote"}, "value": "1"}},
+		{"type": "section", "text": {"type": "mrkdwn", "text": "*2. Sushi*"}},
+		{"type": "divider"},
+		{"type": "context", "elements": [
+			{"type": "image", "image_url": "https://example.com/i.png", "alt_text": "poll"},
+			{"type": "mrkdwn", "text": "Created by <@U01>"}
+		]},
+		{"type": "actions", "elements": [
+			{"type": "button", "text": {"type": "plain_text", "text": "Add option"}},
+			{"type": "button", "text": {"type": "plain_text", "text": "Close poll"}}
+		]}
+	]
+}`
+
+// workflowMsg is the Workflow Builder form submission message.
+const workflowMsg = `{
+	"type": "message",
+	"text": "",
+	"blocks": [
+		{"type": "section", "fields": [
+			{"type": "mrkdwn", "text": "*Requester*\n<@U01>"},
+			{"type": "plain_text", "text": "Laptop <new>"}
+		]},
+		{"type": "image", "image_url": "https://example.com/i.png", "alt_text": "diagram"}
+	]
+}`
+
+// userMsg is the message, written by the user, it has the rich text block.
+const userMsg = `{
+	"type": "message",
+	"text": "hello <@U02>",
+	"blocks": [
+		{"type": "rich_text", "elements": [
+			{"type": "rich_text_section", "elements": [
+				{"type": "text", "text": "hello "},
+				{"type": "user", "user_id": "U02"}
+			]}
+		]}
+	]
+}`
+
+func unmarshalMsg(t *testing.T, s string) *slack.Message {
+	t.Helper()
+	var m slack.Message
+	require.NoError(t, json.Unmarshal([]byte(s), &m))
+	return &m
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{
+			"poll",
+			pollMsg,
+			"*Lunch &amp; Learn?*\n" +
+				"*1. Pizza* `2`\n<@U01> <@U02>\n[Vote]\n" +
+				"*2. Sushi*\n" +
+				"---\n" +
+				"poll Created by <@U01>\n" +
+				"[Add option] [Close poll]",
+		},
+		{
+			"workflow",
+			workflowMsg,
+			"*Requester*\n<@U01>\nLaptop &lt;new&gt;\n[image: diagram]",
+		},
+		{"user message", userMsg, "hello <@U02>"},
+		{"no blocks", `{"type": "message", "text": "plain"}`, "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Text(unmarshalMsg(t, tt.msg)))
+		})
+	}
+}
+
+func TestRender_richText(t *testing.T) {
+	m := unmarshalMsg(t, `{"blocks": [{"type": "rich_text", "elements": [
+		{"type": "rich_text_section", "elements": [
+			{"type": "text", "text": "see "},
+			{"type": "text", "text": "this ", "style": {"bold": true}},
+			{"type": "link", "url": "https://example.com", "text": "link"},
+			{"type": "text", "text": " in "},
+			{"type": "channel", "channel_id": "C01"},
+			{"type": "text", "text": ", "},
+			{"type": "usergroup", "usergroup_id": "S01"},
+			{"type": "text", "text": " "},
+			{"type": "emoji", "name": "tada"}
+		]}
+	]}]}`)
+	assert.Equal(t, "see *this* <https://example.com|link> in <#C01>, <!subteam^S01> :tada:", Render(m.Blocks))
+}
@@ -0,0 +1,117 @@
+// Package diskspace checks the free space of the output volume: before the
+// run, against the estimate of the space, that the run needs, and while
+// archiving, so that the run pauses, when the disk is almost full, instead of
+// failing with the write error in the middle of the file.
+package diskspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupported is returned by Free on the platforms, where the free space
+// can't be determined.
+var ErrUnsupported = errors.New("free disk space is not available on this platform")
+
+// Free returns the number of bytes, available to the current user on the
+// volume of path.  path may not exist yet, then its nearest existing parent
+// directory is used, i.e. for the output directory, that is created by the
+// run.
+func Free(path string) (uint64, error) {
+	dir, err := volume(path)
+	if err != nil {
+		return 0, err
+	}
+	return free(dir)
+}
+
+// volume returns the nearest existing directory of path.
+func volume(path string) (string, error) {
+	p, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for {
+		fi, err := os.Stat(p)
+		if err == nil {
+			if fi.IsDir() {
+				return p, nil
+			}
+			return filepath.Dir(p), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return "", err
+		}
+		p = parent
+	}
+}
+
+var (
+	mu      sync.RWMutex
+	lowHook func(path string, free, min uint64)
+)
+
+// SetLowHook sets the function, that is called, when the run is paused by
+// the Guard, because the free space of the volume of path fell below min.
+// Set it to nil to remove the hook.
+func SetLowHook(fn func(path string, free, min uint64)) {
+	mu.Lock()
+	defer mu.Unlock()
+	lowHook = fn
+}
+
+func onLow(path string, free, min uint64) {
+	mu.RLock()
+	fn := lowHook
+	mu.RUnlock()
+	if fn != nil {
+		fn(path, free, min)
+	}
+}
+
+// Size is the size in bytes, it is the flag.Value, that accepts the
+// suffixes K, M, G and T, the powers of 1024, i.e. "512M" or "10GiB".
+type Size uint64
+
+var units = []string{"", "K", "M", "G", "T"}
+
+func (s Size) String() string {
+	v := float64(s)
+	i := 0
+	for ; v >= 1024 && i < len(units)-1; i++ {
+		v /= 1024
+	}
+	if i == 0 {
+		return strconv.FormatUint(uint64(s), 10)
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64) + units[i]
+}
+
+// Set parses the size, it is the flag.Value interface.
+func (s *Size) Set(v string) error {
+	num := strings.TrimSpace(strings.ToUpper(v))
+	num = strings.TrimSuffix(strings.TrimSuffix(num, "B"), "I")
+	mult := uint64(1)
+	for i := len(units) - 1; i > 0; i-- {
+		if strings.HasSuffix(num, units[i]) {
+			num = strings.TrimSuffix(num, units[i])
+			mult = 1 << (10 * i)
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || f < 0 {
+		return fmt.Errorf("invalid size: %q, use i.e. 512M or 10G", v)
+	}
+	*s = Size(f * float64(mult))
+	return nil
+}
@@ -0,0 +1,62 @@
+package diskspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Size
+		str     string
+		wantErr bool
+	}{
+		{"100", 100, "100", false},
+		{"512M", 512 << 20, "512.0M", false},
+		{"10GiB", 10 << 30, "10.0G", false},
+		{"1.5gb", 3 << 29, "1.5G", false},
+		{"2T", 2 << 40, "2.0T", false},
+		{"lots", 0, "", true},
+		{"-1G", 0, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var s Size
+			err := s.Set(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.want, s)
+			assert.Equal(t, tt.str, s.String())
+		})
+	}
+}
+
+func TestVolume(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "export.zip")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{dir, file, filepath.Join(dir, "not", "yet", "created")} {
+		got, err := volume(p)
+		assert.NoError(t, err)
+		assert.Equal(t, dir, got, p)
+	}
+}
+
+func TestFree(t *testing.T) {
+	n, err := Free(filepath.Join(t.TempDir(), "new_dir"))
+	if err == ErrUnsupported {
+		t.Skip(err)
+	}
+	assert.NoError(t, err)
+	assert.NotZero(t, n)
+}
@@ -0,0 +1,15 @@
+package diskspace
+
+import "golang.org/x/sys/windows"
+
+func free(dir string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var avail, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &avail, &total, &totalFree); err != nil {
+		return 0, err
+	}
+	return avail, nil
+}
@@ -0,0 +1,185 @@
+package diskspace
+
+// In this file: the Guard, that pauses the writes, while the disk is almost
+// full.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// ErrLowSpace is returned by the Guard, if the user has chosen to stop the
+// run, because the disk is almost full.
+var ErrLowSpace = errors.New("not enough free disk space")
+
+const (
+	// checkInterval is the minimum interval between the checks of the free
+	// space, so that the many small writes don't query the volume each.
+	checkInterval = 5 * time.Second
+	// pollInterval is the interval of the checks, while the run is paused.
+	pollInterval = time.Minute
+)
+
+// PromptFunc asks the user, whether to continue, msg is the question.
+type PromptFunc func(msg string) (bool, error)
+
+// Guard checks the free space of the volume before the writes, and, if it
+// is below the minimum, pauses the run, until the space is freed.  If the
+// prompt is set, the user is asked to free the space, otherwise the volume is
+// polled every minute.  Guard is safe for concurrent use.
+type Guard struct {
+	ctx    context.Context
+	path   string
+	min    uint64
+	prompt PromptFunc
+	lg     logger.Interface
+
+	mu   sync.Mutex
+	last time.Time // time of the last check
+	err  error     // error, that stopped the run
+
+	free         func(string) (uint64, error)
+	pollInterval time.Duration
+}
+
+// GuardOption is the option of the Guard.
+type GuardOption func(*Guard)
+
+// WithPrompt sets the function, that asks the user to free the space, when
+// the disk is almost full.  The run stops with ErrLowSpace, if the user
+// declines.
+func WithPrompt(fn PromptFunc) GuardOption {
+	return func(g *Guard) {
+		g.prompt = fn
+	}
+}
+
+// WithLogger sets the logger of the Guard.
+func WithLogger(lg logger.Interface) GuardOption {
+	return func(g *Guard) {
+		if lg != nil {
+			g.lg = lg
+		}
+	}
+}
+
+// NewGuard creates the Guard of the volume of path, that pauses the run,
+// while it has less than min bytes free.  The wait is cancelled with ctx.
+func NewGuard(ctx context.Context, path string, min uint64, opts ...GuardOption) *Guard {
+	g := &Guard{
+		ctx:          ctx,
+		path:         path,
+		min:          min,
+		lg:           logger.Default,
+		free:         Free,
+		pollInterval: pollInterval,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Check checks the free space of the volume, if it was not checked in the
+// last few seconds, and blocks, while it is below the minimum.  It returns
+// an error, if the context is cancelled, or the user has chosen to stop.
+// If the free space can't be determined, the check is skipped.
+func (g *Guard) Check() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.err != nil {
+		return g.err
+	}
+	if time.Since(g.last) < checkInterval {
+		return nil
+	}
+	g.last = time.Now()
+	free, err := g.free(g.path)
+	if err != nil || free >= g.min {
+		return nil
+	}
+	if err := g.wait(free); err != nil {
+		g.err = err
+		return err
+	}
+	g.last = time.Now()
+	return nil
+}
+
+// wait waits for the free space, free is the current free space.  The
+// caller must hold the lock, so that all writes are paused.
+func (g *Guard) wait(free uint64) error {
+	g.lg.Printf("the disk is almost full: %s free on %s, the minimum is %s, the run is paused until the space is freed", Size(free), g.path, Size(g.min))
+	onLow(g.path, free, g.min)
+	for free < g.min {
+		if g.prompt != nil {
+			ok, err := g.prompt(fmt.Sprintf("The disk is almost full: %s free, the minimum is %s.  Free up some space, and continue?", Size(free), Size(g.min)))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("%w: %s free on %s", ErrLowSpace, Size(free), g.path)
+			}
+		} else {
+			select {
+			case <-g.ctx.Done():
+				return g.ctx.Err()
+			case <-time.After(g.pollInterval):
+			}
+		}
+		var err error
+		if free, err = g.free(g.path); err != nil {
+			return err
+		}
+	}
+	g.lg.Printf("%s free on %s, resuming the run", Size(free), g.path)
+	return nil
+}
+
+// FS returns the filesystem, that checks the free space before creating and
+// writing the files of fsys.
+func (g *Guard) FS(fsys fsadapter.FS) fsadapter.FS {
+	return guardFS{FS: fsys, g: g}
+}
+
+type guardFS struct {
+	fsadapter.FS
+	g *Guard
+}
+
+func (f guardFS) Create(name string) (io.WriteCloser, error) {
+	if err := f.g.Check(); err != nil {
+		return nil, err
+	}
+	return f.FS.Create(name)
+}
+
+func (f guardFS) Append(name string) (io.WriteCloser, error) {
+	if err := f.g.Check(); err != nil {
+		return nil, err
+	}
+	return fsadapter.Append(f.FS, name)
+}
+
+func (f guardFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if err := f.g.Check(); err != nil {
+		return err
+	}
+	return f.FS.WriteFile(name, data, perm)
+}
+
+// String returns the description of the underlying filesystem.
+func (f guardFS) String() string {
+	if s, ok := f.FS.(interface{ String() string }); ok {
+		return s.String()
+	}
+	return "diskspace"
+}
@@ -0,0 +1,76 @@
+package diskspace
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// freeSeq returns the free space function, that returns the values of seq
+// one by one, and the last one after that.
+func freeSeq(seq ...uint64) func(string) (uint64, error) {
+	return func(string) (uint64, error) {
+		v := seq[0]
+		if len(seq) > 1 {
+			seq = seq[1:]
+		}
+		return v, nil
+	}
+}
+
+func testGuard(ctx context.Context, free func(string) (uint64, error), opts ...GuardOption) *Guard {
+	g := NewGuard(ctx, "/archive", 100, append([]GuardOption{WithLogger(logger.Silent)}, opts...)...)
+	g.free = free
+	g.pollInterval = time.Millisecond
+	return g
+}
+
+func TestGuard_Check(t *testing.T) {
+	t.Run("enough space", func(t *testing.T) {
+		assert.NoError(t, testGuard(context.Background(), freeSeq(1000)).Check())
+	})
+	t.Run("paused until freed", func(t *testing.T) {
+		var hooked uint64
+		SetLowHook(func(_ string, free, _ uint64) { hooked = free })
+		defer SetLowHook(nil)
+		g := testGuard(context.Background(), freeSeq(10, 20, 1000))
+		assert.NoError(t, g.Check())
+		assert.Equal(t, uint64(10), hooked)
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		g := testGuard(ctx, freeSeq(10))
+		assert.ErrorIs(t, g.Check(), context.Canceled)
+		assert.ErrorIs(t, g.Check(), context.Canceled, "the error is sticky")
+	})
+	t.Run("prompt", func(t *testing.T) {
+		var asked int
+		prompt := func(string) (bool, error) {
+			asked++
+			return asked < 2, nil
+		}
+		g := testGuard(context.Background(), freeSeq(10), WithPrompt(prompt))
+		assert.ErrorIs(t, g.Check(), ErrLowSpace)
+		assert.Equal(t, 2, asked, "asked again, as the space was not freed")
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		g := testGuard(context.Background(), func(string) (uint64, error) { return 0, ErrUnsupported })
+		assert.NoError(t, g.Check())
+	})
+}
+
+func TestGuard_FS(t *testing.T) {
+	g := testGuard(context.Background(), freeSeq(10), WithPrompt(func(string) (bool, error) { return false, nil }))
+	fsys := g.FS(fsadapter.NewDirectory(t.TempDir()))
+	err := fsys.WriteFile("x.json", []byte("{}"), 0o644)
+	assert.True(t, errors.Is(err, ErrLowSpace))
+	_, err = fsys.Create("y.json")
+	assert.True(t, errors.Is(err, ErrLowSpace))
+}
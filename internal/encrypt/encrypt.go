@@ -0,0 +1,188 @@
+// Package encrypt implements the streaming encryption of the output, so that
+// the archives land on disk already encrypted.
+//
+// The encryption is specified as "<method>:<recipient>", supported methods:
+//
+//	age:<recipient>  - age encryption, recipient is the age public key
+//	                   (age1...), SSH public key, or the recipients file.
+//	                   Multiple recipients are separated by commas.
+//	gpg:<key>        - GnuPG encryption, key is the key ID, fingerprint or
+//	                   email in the gpg keyring, or the public key file.  It
+//	                   requires gpg binary to be installed.
+package encrypt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+)
+
+// Encryptor encrypts the stream.
+type Encryptor interface {
+	// Encrypt returns the writer that encrypts everything written to it and
+	// writes the result to w.  Close must be called to flush the data, it
+	// does not close w.
+	Encrypt(w io.Writer) (io.WriteCloser, error)
+	// Ext returns the conventional file extension, i.e. ".age".
+	Ext() string
+}
+
+// Parse parses the encryption specification, see package documentation.
+func Parse(spec string) (Encryptor, error) {
+	method, arg, found := strings.Cut(spec, ":")
+	if !found || arg == "" {
+		return nil, fmt.Errorf("invalid encryption %q, expected age:<recipient> or gpg:<key>", spec)
+	}
+	switch strings.ToLower(method) {
+	case "age":
+		return newAge(arg)
+	case "gpg":
+		return &gpgEncryptor{key: arg, bin: "gpg"}, nil
+	}
+	return nil, fmt.Errorf("unknown encryption method: %q", method)
+}
+
+// ageEncryptor encrypts with age.
+type ageEncryptor struct {
+	recipients []age.Recipient
+}
+
+func newAge(arg string) (*ageEncryptor, error) {
+	var rcpts []age.Recipient
+	for _, s := range strings.Split(arg, ",") {
+		s = strings.TrimSpace(s)
+		r, err := parseRecipients(s)
+		if err != nil {
+			return nil, err
+		}
+		rcpts = append(rcpts, r...)
+	}
+	if len(rcpts) == 0 {
+		return nil, errors.New("age: no recipients")
+	}
+	return &ageEncryptor{recipients: rcpts}, nil
+}
+
+// parseRecipients parses the recipient s, which is the age or SSH public key,
+// or the name of the recipients file.
+func parseRecipients(s string) ([]age.Recipient, error) {
+	switch {
+	case strings.HasPrefix(s, "age1"):
+		r, err := age.ParseX25519Recipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("age: %w", err)
+		}
+		return []age.Recipient{r}, nil
+	case strings.HasPrefix(s, "ssh-"):
+		r, err := agessh.ParseRecipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("age: %w", err)
+		}
+		return []age.Recipient{r}, nil
+	}
+	f, err := os.Open(s)
+	if err != nil {
+		return nil, fmt.Errorf("age: invalid recipient %q, expected public key or recipients file: %w", s, err)
+	}
+	defer f.Close()
+	rcpts, err := age.ParseRecipients(f)
+	if err != nil {
+		return nil, fmt.Errorf("age: %s: %w", s, err)
+	}
+	return rcpts, nil
+}
+
+func (e *ageEncryptor) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	return age.Encrypt(w, e.recipients...)
+}
+
+func (*ageEncryptor) Ext() string { return ".age" }
+
+// gpgEncryptor encrypts with the gpg binary.
+type gpgEncryptor struct {
+	key string
+	bin string // gpg binary
+}
+
+func (e *gpgEncryptor) args() []string {
+	args := []string{"--batch", "--no-tty", "--encrypt", "--output", "-"}
+	if fi, err := os.Stat(e.key); err == nil && fi.Mode().IsRegular() {
+		return append(args, "--recipient-file", e.key)
+	}
+	return append(args, "--recipient", e.key)
+}
+
+func (e *gpgEncryptor) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	cmd := exec.Command(e.bin, e.args()...)
+	cmd.Stdout = w
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("gpg: %w", err)
+	}
+	return &cmdWriter{WriteCloser: stdin, cmd: cmd, stderr: &stderr}, nil
+}
+
+func (*gpgEncryptor) Ext() string { return ".gpg" }
+
+// cmdWriter writes to the stdin of the command, Close waits for the command
+// to finish.
+type cmdWriter struct {
+	io.WriteCloser
+	cmd    *exec.Cmd
+	stderr *strings.Builder
+
+	waited  bool
+	waitErr error
+}
+
+func (cw *cmdWriter) Write(p []byte) (int, error) {
+	n, err := cw.WriteCloser.Write(p)
+	if err != nil {
+		// the process has likely exited, get the real error.
+		if werr := cw.wait(); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+func (cw *cmdWriter) Close() error {
+	if cw.waited {
+		return cw.waitErr
+	}
+	if err := cw.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return cw.wait()
+}
+
+func (cw *cmdWriter) wait() error {
+	if cw.waited {
+		return cw.waitErr
+	}
+	cw.waited = true
+	if err := cw.cmd.Wait(); err != nil {
+		cw.waitErr = fmt.Errorf("gpg: %w: %s", err, strings.TrimSpace(cw.stderr.String()))
+	}
+	return cw.waitErr
+}
+
+// Filename returns the name of the encrypted file: name with the extension
+// of the encryptor e appended, unless it's already there.
+func Filename(name string, e Encryptor) string {
+	if strings.HasSuffix(strings.ToLower(name), e.Ext()) {
+		return name
+	}
+	return name + e.Ext()
+}
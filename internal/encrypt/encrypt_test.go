@@ -0,0 +1,107 @@
+package encrypt
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	rcptFile := filepath.Join(t.TempDir(), "recipients.txt")
+	require.NoError(t, os.WriteFile(rcptFile, []byte("# backup key\n"+id.Recipient().String()+"\n"), 0644))
+
+	tests := []struct {
+		name    string
+		spec    string
+		wantExt string
+		wantErr bool
+	}{
+		{"age key", "age:" + id.Recipient().String(), ".age", false},
+		{"age file and key", "age:" + rcptFile + "," + id.Recipient().String(), ".age", false},
+		{"age invalid", "age:age1xxx", "", true},
+		{"age missing file", "age:/nonexistent", "", true},
+		{"gpg", "gpg:backup@example.com", ".gpg", false},
+		{"no recipient", "age:", "", true},
+		{"unknown", "rot13:x", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantExt, got.Ext())
+		})
+	}
+}
+
+func Test_ageEncryptor_Encrypt(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	enc, err := Parse("age:" + id.Recipient().String())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := enc.Encrypt(&buf)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "secret data")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.NotContains(t, buf.String(), "secret data")
+
+	r, err := age.Decrypt(&buf, id)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "secret data", string(got))
+}
+
+func Test_gpgEncryptor_args(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.asc")
+	require.NoError(t, os.WriteFile(keyFile, []byte("key"), 0644))
+
+	assert.Equal(t, []string{"--batch", "--no-tty", "--encrypt", "--output", "-", "--recipient", "ABCD1234"}, (&gpgEncryptor{key: "ABCD1234"}).args())
+	assert.Equal(t, []string{"--batch", "--no-tty", "--encrypt", "--output", "-", "--recipient-file", keyFile}, (&gpgEncryptor{key: keyFile}).args())
+}
+
+func Test_gpgEncryptor_Encrypt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires shell")
+	}
+	dir := t.TempDir()
+	// fake gpg, that copies the input to the output.
+	fake := filepath.Join(dir, "gpg")
+	require.NoError(t, os.WriteFile(fake, []byte("#!/bin/sh\ncat\n"), 0755))
+	failing := filepath.Join(dir, "gpg-fail")
+	require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\necho 'no public key' >&2\nexit 2\n"), 0755))
+
+	t.Run("ok", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := (&gpgEncryptor{key: "x", bin: fake}).Encrypt(&buf)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, "data")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Equal(t, "data", buf.String())
+	})
+	t.Run("gpg error", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := (&gpgEncryptor{key: "x", bin: failing}).Encrypt(&buf)
+		require.NoError(t, err)
+		_, _ = io.WriteString(w, "data")
+		err = w.Close()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no public key")
+	})
+}
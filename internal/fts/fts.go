@@ -0,0 +1,263 @@
+// Package fts implements the persistent full-text index over the messages of
+// the archive.
+//
+// The index is an inverted index: each token of the message text maps to the
+// sorted list of the documents (messages) that contain it.  Query terms are
+// AND-ed, and the results can be filtered by channel, user and date.
+package fts
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/blockkit"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// minTokenLen is the minimum length of the token in runes, shorter tokens
+// are not indexed.
+const minTokenLen = 2
+
+// Doc is the indexed message.
+type Doc struct {
+	ChannelID string
+	ThreadTS  string
+	TS        string
+	User      string
+	Unix      int64 // message time, seconds since epoch.
+	Text      string
+}
+
+// Index is the full text index.
+type Index struct {
+	Version  int
+	Archive  string // archive name
+	Created  time.Time
+	Docs     []Doc
+	Postings map[string][]uint32 // token -> sorted doc indexes
+}
+
+// Query is the search query.
+type Query struct {
+	Text      string    // search terms, all must be present in the message.
+	ChannelID string    // optional channel ID.
+	UserID    string    // optional user ID.
+	From      time.Time // optional, messages on or after From.
+	To        time.Time // optional, messages before To.
+}
+
+// Build builds the index over all the messages of the archive.
+func Build(ctx context.Context, ar *archive.Archive) (*Index, error) {
+	chans, err := ar.Channels()
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{
+		Version:  formatVersion,
+		Archive:  ar.Name(),
+		Created:  time.Now(),
+		Postings: make(map[string][]uint32),
+	}
+	for _, ch := range chans {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		cnv, err := ar.Conversation(ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		idx.addMessages(ch.ID, cnv.Messages)
+	}
+	return idx, nil
+}
+
+func (idx *Index) addMessages(channelID string, msgs []types.Message) {
+	for _, m := range msgs {
+		idx.add(channelID, m)
+		idx.addMessages(channelID, m.ThreadReplies)
+	}
+}
+
+func (idx *Index) add(channelID string, m types.Message) {
+	doc := Doc{
+		ChannelID: channelID,
+		ThreadTS:  m.ThreadTimestamp,
+		TS:        m.Timestamp,
+		User:      m.User,
+		Text:      blockkit.Text(&m.Message),
+	}
+	if t, err := structures.ParseSlackTS(m.Timestamp); err == nil {
+		doc.Unix = t.Unix()
+	}
+	id := uint32(len(idx.Docs))
+	idx.Docs = append(idx.Docs, doc)
+	for _, tok := range uniq(tokenize(doc.Text)) {
+		idx.Postings[tok] = append(idx.Postings[tok], id)
+	}
+}
+
+// Search returns the documents that match the query q, at most limit
+// documents are returned, if limit is greater than zero.  Results are sorted
+// by time, newest first.
+func (idx *Index) Search(q Query, limit int) []Doc {
+	terms := uniq(tokenize(q.Text))
+	if len(terms) == 0 {
+		return nil
+	}
+	// start with the shortest posting list to keep the intersections small.
+	lists := make([][]uint32, 0, len(terms))
+	for _, t := range terms {
+		p, ok := idx.Postings[t]
+		if !ok {
+			return nil
+		}
+		lists = append(lists, p)
+	}
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+	ids := lists[0]
+	for _, l := range lists[1:] {
+		ids = intersect(ids, l)
+	}
+
+	var docs []Doc
+	for _, id := range ids {
+		d := idx.Docs[id]
+		if !q.match(d) {
+			continue
+		}
+		docs = append(docs, d)
+	}
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].TS > docs[j].TS })
+	if limit > 0 && len(docs) > limit {
+		docs = docs[:limit]
+	}
+	return docs
+}
+
+// match returns true if the document matches the query filters.
+func (q Query) match(d Doc) bool {
+	if q.ChannelID != "" && d.ChannelID != q.ChannelID {
+		return false
+	}
+	if q.UserID != "" && d.User != q.UserID {
+		return false
+	}
+	if !q.From.IsZero() && d.Unix < q.From.Unix() {
+		return false
+	}
+	if !q.To.IsZero() && d.Unix >= q.To.Unix() {
+		return false
+	}
+	return true
+}
+
+// Match returns true if the text and the message attributes match the query.
+// It can be used to search the messages without an index.
+func (q Query) Match(channelID string, m types.Message) bool {
+	d := Doc{ChannelID: channelID, User: m.User, TS: m.Timestamp}
+	if t, err := structures.ParseSlackTS(m.Timestamp); err == nil {
+		d.Unix = t.Unix()
+	}
+	if !q.match(d) {
+		return false
+	}
+	terms := uniq(tokenize(q.Text))
+	if len(terms) == 0 {
+		return false
+	}
+	toks := make(map[string]bool)
+	for _, t := range tokenize(blockkit.Text(&m.Message)) {
+		toks[t] = true
+	}
+	for _, t := range terms {
+		if !toks[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenize splits the text into lowercase tokens, separated by anything that
+// is not a letter or a digit.
+func tokenize(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	toks := fields[:0]
+	for _, f := range fields {
+		if len([]rune(f)) >= minTokenLen {
+			toks = append(toks, f)
+		}
+	}
+	return toks
+}
+
+// uniq returns the unique strings of ss, preserving the order.
+func uniq(ss []string) []string {
+	seen := make(map[string]bool, len(ss))
+	res := ss[:0]
+	for _, s := range ss {
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		res = append(res, s)
+	}
+	return res
+}
+
+// intersect returns the intersection of sorted lists a and b.
+func intersect(a, b []uint32) []uint32 {
+	var res []uint32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			res = append(res, a[i])
+			i++
+			j++
+		}
+	}
+	return res
+}
+
+// Scan searches the messages of the archive without the index.  It returns
+// the same results as Search on the index built for the archive.
+func Scan(ctx context.Context, ar *archive.Archive, q Query, limit int) ([]Doc, error) {
+	chans, err := ar.Channels()
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{Postings: make(map[string][]uint32)}
+	for _, ch := range chans {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if q.ChannelID != "" && q.ChannelID != ch.ID {
+			continue
+		}
+		cnv, err := ar.Conversation(ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		var walk func(msgs []types.Message)
+		walk = func(msgs []types.Message) {
+			for _, m := range msgs {
+				if q.Match(ch.ID, m) {
+					idx.add(ch.ID, m)
+				}
+				walk(m.ThreadReplies)
+			}
+		}
+		walk(cnv.Messages)
+	}
+	return idx.Search(q, limit), nil
+}
@@ -0,0 +1,114 @@
+package fts
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/archive"
+)
+
+var testFS = fstest.MapFS{
+	"channels.json": {Data: []byte(`[{"id":"C01","name":"general"},{"id":"C02","name":"random"}]`)},
+	"users.json":    {Data: []byte(`[{"id":"U01","name":"alice"},{"id":"U02","name":"bob"}]`)},
+	"general/2023-01-01.json": {Data: []byte(`[
+		{"type":"message","user":"U01","text":"The quick brown fox","ts":"1672531200.000100","thread_ts":"1672531200.000100","reply_count":1},
+		{"type":"message","user":"U02","text":"jumps over the lazy dog","ts":"1672531300.000100","thread_ts":"1672531200.000100"}
+	]`)},
+	"random/2023-02-01.json": {Data: []byte(`[
+		{"type":"message","user":"U02","text":"Quick, quick! Another fox.","ts":"1675209600.000100"}
+	]`)},
+}
+
+func testIndex(t *testing.T) *Index {
+	t.Helper()
+	ar, err := archive.New(testFS, "test")
+	require.NoError(t, err)
+	idx, err := Build(context.Background(), ar)
+	require.NoError(t, err)
+	return idx
+}
+
+func TestBuild(t *testing.T) {
+	idx := testIndex(t)
+	assert.Len(t, idx.Docs, 3)
+	assert.Equal(t, "test", idx.Archive)
+	assert.Equal(t, []uint32{0, 2}, idx.Postings["fox"])
+	assert.Equal(t, []uint32{0, 2}, idx.Postings["quick"], "duplicate tokens must be indexed once")
+	assert.Contains(t, idx.Postings, "lazy", "thread replies must be indexed")
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := testIndex(t)
+	tests := []struct {
+		name   string
+		q      Query
+		limit  int
+		wantTS []string
+	}{
+		{"single term", Query{Text: "FOX"}, 0, []string{"1675209600.000100", "1672531200.000100"}},
+		{"all terms must match", Query{Text: "quick brown"}, 0, []string{"1672531200.000100"}},
+		{"unknown term", Query{Text: "fox elephant"}, 0, nil},
+		{"empty", Query{Text: "!"}, 0, nil},
+		{"limit", Query{Text: "fox"}, 1, []string{"1675209600.000100"}},
+		{"channel", Query{Text: "fox", ChannelID: "C01"}, 0, []string{"1672531200.000100"}},
+		{"user", Query{Text: "the", UserID: "U02"}, 0, []string{"1672531300.000100"}},
+		{"from", Query{Text: "fox", From: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)}, 0, []string{"1675209600.000100"}},
+		{"to", Query{Text: "fox", To: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)}, 0, []string{"1672531200.000100"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range idx.Search(tt.q, tt.limit) {
+				got = append(got, d.TS)
+			}
+			assert.Equal(t, tt.wantTS, got)
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	ar, err := archive.New(testFS, "test")
+	require.NoError(t, err)
+	idx := testIndex(t)
+	for _, q := range []Query{
+		{Text: "fox"},
+		{Text: "the", UserID: "U02"},
+		{Text: "quick", ChannelID: "C02"},
+		{Text: "nothing"},
+	} {
+		got, err := Scan(context.Background(), ar, q, 10)
+		require.NoError(t, err)
+		assert.Equal(t, idx.Search(q, 10), got, q.Text)
+	}
+}
+
+func TestIndex_SaveLoad(t *testing.T) {
+	idx := testIndex(t)
+	filename := filepath.Join(t.TempDir(), "test"+Ext)
+	require.NoError(t, idx.Save(filename))
+
+	got, err := Load(filename)
+	require.NoError(t, err)
+	assert.Equal(t, idx.Docs, got.Docs)
+	assert.Equal(t, idx.Postings, got.Postings)
+
+	idx.Version = formatVersion + 1
+	require.NoError(t, idx.Save(filename))
+	_, err = Load(filename)
+	assert.ErrorIs(t, err, ErrVersion)
+}
+
+func TestDefaultPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("a", "export.idx"), DefaultPath(filepath.Join("a", "export.zip")))
+	assert.Equal(t, filepath.Join("a", "dump.idx"), DefaultPath(filepath.Join("a", "dump")+string(filepath.Separator)))
+}
+
+func Test_tokenize(t *testing.T) {
+	assert.Equal(t, []string{"hello", "wörld", "42"}, tokenize("Hello, Wörld! <42> a"))
+}
@@ -0,0 +1,72 @@
+package fts
+
+// In this file: index persistence.
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// formatVersion is the version of the index file format.  It should be
+// increased on any incompatible change of the Index structure.
+const formatVersion = 1
+
+// Ext is the index file extension.
+const Ext = ".idx"
+
+// ErrVersion is returned by Load, if the index was created by an
+// incompatible version of slackdump and must be rebuilt.
+var ErrVersion = errors.New("incompatible index version, rebuild the index")
+
+// DefaultPath returns the default location of the index file for the archive
+// at location src: the file with idx extension next to the archive
+// directory or ZIP file.
+func DefaultPath(src string) string {
+	src = filepath.Clean(src)
+	return strings.TrimSuffix(src, filepath.Ext(src)) + Ext
+}
+
+// Save writes the index to the file.
+func (idx *Index) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(f)
+	if err := gob.NewEncoder(gz).Encode(idx); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to encode the index: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load loads the index from the file.
+func Load(filename string) (*Index, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: not an index file: %w", filename, err)
+	}
+	defer gz.Close()
+	var idx Index
+	if err := gob.NewDecoder(gz).Decode(&idx); err != nil {
+		return nil, fmt.Errorf("failed to decode the index: %w", err)
+	}
+	if idx.Version != formatVersion {
+		return nil, ErrVersion
+	}
+	return &idx, nil
+}
@@ -0,0 +1,158 @@
+// Package hold implements the legal holds of the archive.  The message, that
+// is under the legal hold, must not be removed from the archive, i.e. by the
+// "slackdump tools prune" command, regardless of the retention period.
+//
+// The holds are stored in the File in the root of the archive directory.
+package hold
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// File is the name of the holds file in the root of the archive.
+const File = "legal_holds.json"
+
+// Hold is the legal hold.  The hold applies to the messages, that match all
+// of its criteria, the empty criterion matches everything, i.e. the hold
+// with only the channel set, holds all messages of that channel.
+type Hold struct {
+	Name     string    `json:"name"`
+	Reason   string    `json:"reason,omitempty"`
+	Created  time.Time `json:"created"`
+	Channels []string  `json:"channels,omitempty"` // channel IDs
+	Users    []string  `json:"users,omitempty"`    // IDs of the message authors
+	// From and To is the range of the message times, inclusive, the zero
+	// time means the open range.
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Matches returns true if the message of the user userID, posted in the
+// channel channelID at the time ts, is under the hold.
+func (h Hold) Matches(channelID, userID string, ts time.Time) bool {
+	if len(h.Channels) > 0 && !contains(h.Channels, channelID) {
+		return false
+	}
+	if len(h.Users) > 0 && !contains(h.Users, userID) {
+		return false
+	}
+	if !h.From.IsZero() && ts.Before(h.From) {
+		return false
+	}
+	if !h.To.IsZero() && ts.After(h.To) {
+		return false
+	}
+	return true
+}
+
+func (h Hold) String() string {
+	var parts []string
+	if len(h.Channels) > 0 {
+		parts = append(parts, "channels: "+strings.Join(h.Channels, ", "))
+	}
+	if len(h.Users) > 0 {
+		parts = append(parts, "users: "+strings.Join(h.Users, ", "))
+	}
+	if !h.From.IsZero() {
+		parts = append(parts, "from: "+h.From.Format(time.RFC3339))
+	}
+	if !h.To.IsZero() {
+		parts = append(parts, "to: "+h.To.Format(time.RFC3339))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "all messages")
+	}
+	s := h.Name + " (" + strings.Join(parts, "; ") + ")"
+	if h.Reason != "" {
+		s += ": " + h.Reason
+	}
+	return s
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Holds is the list of the legal holds, sorted by name.
+type Holds []Hold
+
+// Match returns the first hold, that matches the message, or nil.
+func (hs Holds) Match(channelID, userID string, ts time.Time) *Hold {
+	for i := range hs {
+		if hs[i].Matches(channelID, userID, ts) {
+			return &hs[i]
+		}
+	}
+	return nil
+}
+
+// Add adds the hold h.  The hold names are unique.
+func (hs *Holds) Add(h Hold) error {
+	if h.Name == "" {
+		return errors.New("hold name is required")
+	}
+	if !h.From.IsZero() && !h.To.IsZero() && h.To.Before(h.From) {
+		return errors.New("the end of the hold range is before its start")
+	}
+	for _, v := range *hs {
+		if v.Name == h.Name {
+			return fmt.Errorf("hold %q already exists", h.Name)
+		}
+	}
+	*hs = append(*hs, h)
+	sort.Slice(*hs, func(i, j int) bool { return (*hs)[i].Name < (*hs)[j].Name })
+	return nil
+}
+
+// Remove removes the hold with the name, and returns it.
+func (hs *Holds) Remove(name string) (Hold, error) {
+	for i, v := range *hs {
+		if v.Name == name {
+			*hs = append((*hs)[:i], (*hs)[i+1:]...)
+			return v, nil
+		}
+	}
+	return Hold{}, fmt.Errorf("hold %q not found", name)
+}
+
+// Load loads the holds of the archive directory dir.  If the archive has no
+// holds file, the empty list is returned.
+func Load(dir string) (Holds, error) {
+	data, err := os.ReadFile(filepath.Join(dir, File))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var hs Holds
+	if err := json.Unmarshal(data, &hs); err != nil {
+		return nil, fmt.Errorf("%s: %w", File, err)
+	}
+	return hs, nil
+}
+
+// Save saves the holds hs to the archive directory dir.
+func Save(dir string, hs Holds) error {
+	if hs == nil {
+		hs = Holds{}
+	}
+	data, err := json.MarshalIndent(hs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, File), append(data, '\n'), 0666)
+}
@@ -0,0 +1,69 @@
+package hold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHold_Matches(t *testing.T) {
+	ts := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		h    Hold
+		want bool
+	}{
+		{"everything", Hold{Name: "all"}, true},
+		{"channel", Hold{Channels: []string{"C02", "C01"}}, true},
+		{"other channel", Hold{Channels: []string{"C02"}}, false},
+		{"user", Hold{Users: []string{"U01"}}, true},
+		{"channel and other user", Hold{Channels: []string{"C01"}, Users: []string{"U02"}}, false},
+		{"in range", Hold{From: ts.AddDate(0, -1, 0), To: ts}, true},
+		{"before range", Hold{From: ts.Add(time.Second)}, false},
+		{"after range", Hold{To: ts.Add(-time.Second)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.h.Matches("C01", "U01", ts))
+		})
+	}
+}
+
+func TestHolds(t *testing.T) {
+	var hs Holds
+	require.NoError(t, hs.Add(Hold{Name: "case-2", Users: []string{"U02"}}))
+	require.NoError(t, hs.Add(Hold{Name: "case-1", Channels: []string{"C01"}}))
+	assert.Error(t, hs.Add(Hold{Name: "case-1"}), "duplicate name")
+	assert.Error(t, hs.Add(Hold{}), "no name")
+	assert.Error(t, hs.Add(Hold{Name: "x", From: time.Now(), To: time.Now().Add(-time.Hour)}), "invalid range")
+	assert.Equal(t, "case-1", hs[0].Name, "holds must be sorted")
+
+	assert.Equal(t, "case-1", hs.Match("C01", "U02", time.Now()).Name)
+	assert.Equal(t, "case-2", hs.Match("C02", "U02", time.Now()).Name)
+	assert.Nil(t, hs.Match("C02", "U01", time.Now()))
+
+	dir := t.TempDir()
+	got, err := Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, got, "no holds file")
+
+	require.NoError(t, Save(dir, hs))
+	got, err = Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, hs, got)
+
+	h, err := got.Remove("case-1")
+	require.NoError(t, err)
+	assert.Equal(t, "case-1", h.Name)
+	_, err = got.Remove("case-1")
+	assert.Error(t, err)
+	assert.Len(t, got, 1)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, File), []byte("{"), 0644))
+	_, err = Load(dir)
+	assert.Error(t, err)
+}
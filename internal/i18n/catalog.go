@@ -0,0 +1,79 @@
+package i18n
+
+// catalogs are the supported languages.
+var catalogs = map[string]*Catalog{
+	"en": {
+		Lang:     "en",
+		DateTime: "2006-01-02 15:04:05",
+		TextTime: "02/01/2006 15:04:05 Z0700",
+		msgs: map[string]string{
+			"channel_join":     "%s has joined the channel",
+			"channel_join_by":  "%s was added to the channel by %s",
+			"channel_leave":    "%s has left the channel",
+			"thread_broadcast": "replied in thread:",
+			"tombstone":        "This message was deleted.",
+			"replies.one":      "%d reply",
+			"replies.other":    "%d replies",
+			"thread_in":        "Thread in",
+			"no_messages":      "No messages.",
+			"select_channel":   "Select a channel on the left to view the messages.",
+			"search":           "Search",
+			"search_words":     "words",
+			"all_channels":     "all channels",
+			"user":             "user",
+			"from":             "from",
+			"to":               "to",
+			"nothing_found":    "Nothing found.",
+			"too_many_results": "too many results, only the first ones are shown, try refining the query",
+		},
+	},
+	"de": {
+		Lang:     "de",
+		DateTime: "02.01.2006 15:04:05",
+		TextTime: "02.01.2006 15:04:05 Z0700",
+		msgs: map[string]string{
+			"channel_join":     "%s ist dem Kanal beigetreten",
+			"channel_join_by":  "%s wurde von %s zum Kanal hinzugefügt",
+			"channel_leave":    "%s hat den Kanal verlassen",
+			"thread_broadcast": "hat im Thread geantwortet:",
+			"tombstone":        "Diese Nachricht wurde gelöscht.",
+			"replies.one":      "%d Antwort",
+			"replies.other":    "%d Antworten",
+			"thread_in":        "Thread in",
+			"no_messages":      "Keine Nachrichten.",
+			"select_channel":   "Wählen Sie links einen Kanal aus, um die Nachrichten anzuzeigen.",
+			"search":           "Suche",
+			"search_words":     "Suchbegriffe",
+			"all_channels":     "alle Kanäle",
+			"user":             "Benutzer",
+			"from":             "von",
+			"to":               "bis",
+			"nothing_found":    "Nichts gefunden.",
+			"too_many_results": "zu viele Ergebnisse, nur die ersten werden angezeigt, bitte die Suche eingrenzen",
+		},
+	},
+	"ja": {
+		Lang:     "ja",
+		DateTime: "2006/01/02 15:04:05",
+		TextTime: "2006/01/02 15:04:05 Z0700",
+		msgs: map[string]string{
+			"channel_join":     "%sがチャンネルに参加しました",
+			"channel_join_by":  "%sが%sによってチャンネルに追加されました",
+			"channel_leave":    "%sがチャンネルから退出しました",
+			"thread_broadcast": "スレッドに返信しました:",
+			"tombstone":        "このメッセージは削除されました。",
+			"replies.other":    "%d件の返信",
+			"thread_in":        "スレッド:",
+			"no_messages":      "メッセージはありません。",
+			"select_channel":   "左側のチャンネルを選択すると、メッセージが表示されます。",
+			"search":           "検索",
+			"search_words":     "キーワード",
+			"all_channels":     "すべてのチャンネル",
+			"user":             "ユーザー",
+			"from":             "開始日",
+			"to":               "終了日",
+			"nothing_found":    "見つかりませんでした。",
+			"too_many_results": "結果が多すぎるため、最初の結果のみ表示しています。検索条件を絞り込んでください",
+		},
+	},
+}
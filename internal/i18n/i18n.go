@@ -0,0 +1,116 @@
+// Package i18n is the message catalog of the rendered archives: the viewer,
+// the static site and the text files of the dump.
+//
+// The messages are keyed by the message ID, the translation, that is missing
+// in the catalog of the language, falls back to English.  The messages, that
+// depend on the number, are keyed with the ".one" and ".other" suffixes, see
+// Catalog.N.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/blockkit"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Default is the default language.
+const Default = "en"
+
+// Catalog is the message catalog of the language.
+type Catalog struct {
+	Lang string
+	// DateTime is the layout of the message time in the viewer.
+	DateTime string
+	// TextTime is the layout of the message time in the text files.
+	TextTime string
+	msgs     map[string]string
+}
+
+// Lookup returns the catalog of the language lang, i.e. "de".  The region
+// is ignored, so "de-AT" is the same as "de".  An empty lang is the Default.
+func Lookup(lang string) (*Catalog, error) {
+	if lang == "" {
+		lang = Default
+	}
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	c, ok := catalogs[base]
+	if !ok {
+		return nil, fmt.Errorf("unsupported language: %q, must be one of: %s", lang, strings.Join(Languages(), ", "))
+	}
+	return c, nil
+}
+
+// Languages returns the sorted list of the supported languages.
+func Languages() []string {
+	var res []string
+	for lang := range catalogs {
+		res = append(res, lang)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// T returns the message key, formatted with args, as fmt.Sprintf does.  If
+// there's no message key in any catalog, the key is returned.
+func (c *Catalog) T(key string, args ...any) string {
+	msg, ok := c.msgs[key]
+	if !ok {
+		if msg, ok = catalogs[Default].msgs[key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// N returns the message key for the number n, i.e. "1 reply" or "2 replies",
+// n is passed as the first argument of the message.  The languages, that
+// don't have the plural forms, only define the ".other" messages.
+func (c *Catalog) N(key string, n int, args ...any) string {
+	args = append([]any{n}, args...)
+	if n == 1 {
+		if _, ok := c.msgs[key+".one"]; ok {
+			return c.T(key+".one", args...)
+		}
+	}
+	return c.T(key+".other", args...)
+}
+
+// MessageText returns the text of the message m, as blockkit.Text does,
+// except for the system messages, i.e. "has joined the channel", that are
+// generated by Slack in English, they are translated.  In English, the
+// text generated by Slack is returned as is.  The tombstone of the deleted
+// message is marked as deleted in all languages, and keeps the archived
+// text, if it was archived before the message was deleted.
+func (c *Catalog) MessageText(m *slack.Message) string {
+	if m.SubType == types.SubTypeTombstone {
+		text := blockkit.Text(m)
+		if text == "" || text == types.TombstoneText {
+			return c.T("tombstone")
+		}
+		return c.T("tombstone") + " " + text
+	}
+	if c.Lang == Default {
+		return blockkit.Text(m)
+	}
+	user := "<@" + m.User + ">"
+	switch m.SubType {
+	case slack.MsgSubTypeChannelJoin, slack.MsgSubTypeGroupJoin:
+		if m.Inviter != "" {
+			return c.T("channel_join_by", user, "<@"+m.Inviter+">")
+		}
+		return c.T("channel_join", user)
+	case slack.MsgSubTypeChannelLeave, slack.MsgSubTypeGroupLeave:
+		return c.T("channel_leave", user)
+	case "thread_broadcast":
+		return c.T("thread_broadcast") + " " + blockkit.Text(m)
+	}
+	return blockkit.Text(m)
+}
@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogs_complete(t *testing.T) {
+	for lang, c := range catalogs {
+		assert.Equal(t, lang, c.Lang)
+		assert.NotEmpty(t, c.DateTime, lang)
+		assert.NotEmpty(t, c.TextTime, lang)
+		for key := range catalogs[Default].msgs {
+			if strings.HasSuffix(key, ".one") {
+				continue // not all languages have plural forms.
+			}
+			assert.Contains(t, c.msgs, key, "%s: missing translation", lang)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c, err := Lookup("de-AT")
+	require.NoError(t, err)
+	assert.Equal(t, "de", c.Lang)
+
+	c, err = Lookup("")
+	require.NoError(t, err)
+	assert.Equal(t, Default, c.Lang)
+
+	_, err = Lookup("xx")
+	assert.ErrorContains(t, err, "de, en, ja")
+}
+
+func TestCatalog_N(t *testing.T) {
+	en, _ := Lookup("en")
+	ja, _ := Lookup("ja")
+	assert.Equal(t, "1 reply", en.N("replies", 1))
+	assert.Equal(t, "2 replies", en.N("replies", 2))
+	assert.Equal(t, "1件の返信", ja.N("replies", 1))
+}
+
+func TestCatalog_T(t *testing.T) {
+	de := &Catalog{Lang: "de", msgs: map[string]string{}}
+	assert.Equal(t, "Nothing found.", de.T("nothing_found"), "falls back to English")
+	assert.Equal(t, "unknown", de.T("unknown"))
+}
+
+func TestCatalog_MessageText(t *testing.T) {
+	en, _ := Lookup("en")
+	assert.Equal(t, "<@U01> has joined the channel", en.MessageText(&slack.Message{Msg: slack.Msg{SubType: "channel_join", User: "U01", Inviter: "U02", Text: "<@U01> has joined the channel"}}), "slack text is kept")
+
+	assert.Equal(t, "This message was deleted.", en.MessageText(&slack.Message{Msg: slack.Msg{SubType: "tombstone"}}), "tombstone without text")
+
+	de, _ := Lookup("de")
+	tests := []struct {
+		name string
+		msg  slack.Msg
+		want string
+	}{
+		{"join", slack.Msg{SubType: "channel_join", User: "U01", Text: "<@U01> has joined the channel"}, "<@U01> ist dem Kanal beigetreten"},
+		{"invited", slack.Msg{SubType: "channel_join", User: "U01", Inviter: "U02"}, "<@U01> wurde von <@U02> zum Kanal hinzugefügt"},
+		{"leave", slack.Msg{SubType: "group_leave", User: "U01"}, "<@U01> hat den Kanal verlassen"},
+		{"broadcast", slack.Msg{SubType: "thread_broadcast", Text: "see above"}, "hat im Thread geantwortet: see above"},
+		{"tombstone", slack.Msg{SubType: "tombstone", Text: "This message was deleted."}, "Diese Nachricht wurde gelöscht."},
+		{"archived tombstone", slack.Msg{SubType: "tombstone", Text: "hallo"}, "Diese Nachricht wurde gelöscht. hallo"},
+		{"message", slack.Msg{Text: "hallo"}, "hallo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, de.MessageText(&slack.Message{Msg: tt.msg}))
+		})
+	}
+}
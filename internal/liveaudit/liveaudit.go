@@ -0,0 +1,252 @@
+// Package liveaudit compares the messages of the archive with the messages
+// of the live workspace, to estimate the completeness of the archive: the
+// messages and the thread replies, that were not archived, and the edits,
+// that the archive missed.
+package liveaudit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Sample is the channel and the time range, that are compared.
+type Sample struct {
+	ChannelID string    `json:"channel_id"`
+	Name      string    `json:"name,omitempty"`
+	Oldest    time.Time `json:"oldest"`
+	Latest    time.Time `json:"latest"`
+}
+
+// Result is the result of the comparison of the sample.  The timestamps are
+// the ones of the messages.
+type Result struct {
+	Sample
+	Live     int `json:"live"`     // number of the live messages, including the replies.
+	Archived int `json:"archived"` // number of the live messages, that are archived.
+	// Missing are the messages, that are not in the archive.
+	Missing []string `json:"missing,omitempty"`
+	// MissingReplies are the thread replies, that are not in the archive.
+	MissingReplies []string `json:"missing_replies,omitempty"`
+	// MissingThreads are the parents of the threads, that have the missing
+	// replies.
+	MissingThreads []string `json:"missing_threads,omitempty"`
+	// MissedEdits are the messages, that were edited before the archive was
+	// created, but the archive has the older version.
+	MissedEdits []string `json:"missed_edits,omitempty"`
+	// Deleted are the archived messages, that are not in the workspace
+	// anymore.  They don't affect the completeness.
+	Deleted []string `json:"deleted,omitempty"`
+	// Error is the error of fetching the live messages, i.e. the channel
+	// is not accessible to the token.  The sample is not counted.
+	Error string `json:"error,omitempty"`
+}
+
+// Pick returns the sample of the channel: the random time range of the
+// length window within the time range of its archived messages, from oldest
+// to latest.  If the archived range is shorter than the window, it is
+// sampled whole.
+func Pick(channelID, name string, oldest, latest time.Time, window time.Duration, rnd *rand.Rand) Sample {
+	s := Sample{ChannelID: channelID, Name: name, Oldest: oldest, Latest: latest}
+	span := latest.Sub(oldest)
+	if window <= 0 || span <= window {
+		return s
+	}
+	s.Oldest = oldest.Add(time.Duration(rnd.Int63n(int64(span - window))))
+	s.Latest = s.Oldest.Add(window)
+	return s
+}
+
+// Compare compares the archived messages of the sample s with the live ones.
+// Both are the conversation messages with the thread replies in the
+// ThreadReplies, the top level messages outside of the sample time range
+// are ignored.  The messages and the edits, that are newer than asOf, the
+// creation time of the archive, are ignored, as the archive could not have
+// them.
+func Compare(s Sample, archived, live []types.Message, asOf time.Time) Result {
+	r := Result{Sample: s}
+	arch := make(map[string]types.Message)
+	for _, m := range flatten(s, archived) {
+		arch[m.Timestamp] = m
+	}
+	missingThreads := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, m := range flatten(s, live) {
+		if !asOf.IsZero() && tsTime(m.Timestamp).After(asOf) {
+			continue
+		}
+		r.Live++
+		a, ok := arch[m.Timestamp]
+		seen[m.Timestamp] = true
+		if !ok {
+			if isReply(m) {
+				r.MissingReplies = append(r.MissingReplies, m.Timestamp)
+				missingThreads[m.ThreadTimestamp] = true
+			} else {
+				r.Missing = append(r.Missing, m.Timestamp)
+			}
+			continue
+		}
+		r.Archived++
+		if missedEdit(a, m, asOf) {
+			r.MissedEdits = append(r.MissedEdits, m.Timestamp)
+		}
+	}
+	for ts := range arch {
+		if !seen[ts] {
+			r.Deleted = append(r.Deleted, ts)
+		}
+	}
+	for ts := range missingThreads {
+		r.MissingThreads = append(r.MissingThreads, ts)
+	}
+	for _, ss := range [][]string{r.Missing, r.MissingReplies, r.MissingThreads, r.MissedEdits, r.Deleted} {
+		sort.Strings(ss)
+	}
+	return r
+}
+
+// flatten returns the top level messages, that are within the sample time
+// range, and their thread replies.
+func flatten(s Sample, msgs []types.Message) []types.Message {
+	var out []types.Message
+	for _, m := range msgs {
+		if t := tsTime(m.Timestamp); t.Before(s.Oldest) || (!s.Latest.IsZero() && t.After(s.Latest)) {
+			continue
+		}
+		out = append(out, m)
+		out = append(out, m.ThreadReplies...)
+	}
+	return out
+}
+
+// missedEdit returns true, if the live message m was edited before asOf, and
+// the archived message a does not have this edit.
+func missedEdit(a, m types.Message, asOf time.Time) bool {
+	if m.Edited == nil || (!asOf.IsZero() && tsTime(m.Edited.Timestamp).After(asOf)) {
+		return false
+	}
+	if a.Edited != nil && !tsTime(a.Edited.Timestamp).Before(tsTime(m.Edited.Timestamp)) {
+		return false
+	}
+	return a.Text != m.Text || a.Edited == nil
+}
+
+func isReply(m types.Message) bool {
+	return m.IsThread() && m.ThreadTimestamp != m.Timestamp
+}
+
+func tsTime(ts string) time.Time {
+	t, err := structures.ParseSlackTS(ts)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Report is the completeness report of the archive.
+type Report struct {
+	Archive string    `json:"archive"`
+	AsOf    time.Time `json:"as_of"` // creation time of the archive.
+	Created time.Time `json:"created"`
+	Seed    int64     `json:"seed"` // seed of the sampling.
+	Results []Result  `json:"results"`
+
+	Live           int `json:"live"`
+	Archived       int `json:"archived"`
+	Missing        int `json:"missing"`
+	MissingReplies int `json:"missing_replies"`
+	MissingThreads int `json:"missing_threads"`
+	MissedEdits    int `json:"missed_edits"`
+	// Completeness is the share of the live messages, that are archived
+	// intact, i.e. without the missed edits.
+	Completeness float64 `json:"completeness"`
+	// Lower and Upper are the bounds of the 95% confidence interval of the
+	// completeness of the whole archive, estimated from the samples.
+	Lower float64 `json:"confidence_lower"`
+	Upper float64 `json:"confidence_upper"`
+}
+
+// NewReport creates the report from the results of the samples, that were
+// sampled with the seed.
+func NewReport(archive string, asOf time.Time, seed int64, results []Result) *Report {
+	r := &Report{Archive: archive, AsOf: asOf, Created: time.Now().UTC(), Seed: seed, Results: results}
+	for _, res := range results {
+		if res.Error != "" {
+			continue
+		}
+		r.Live += res.Live
+		r.Archived += res.Archived
+		r.Missing += len(res.Missing)
+		r.MissingReplies += len(res.MissingReplies)
+		r.MissingThreads += len(res.MissingThreads)
+		r.MissedEdits += len(res.MissedEdits)
+	}
+	intact := r.Archived - r.MissedEdits
+	r.Completeness, r.Lower, r.Upper = wilson(intact, r.Live)
+	return r
+}
+
+// wilson returns the share of k in n, and the bounds of its 95% Wilson
+// score interval.  If n is zero, all are zero.
+func wilson(k, n int) (p, lower, upper float64) {
+	if n == 0 {
+		return 0, 0, 0
+	}
+	const z = 1.96
+	nf := float64(n)
+	p = float64(k) / nf
+	denom := 1 + z*z/nf
+	center := (p + z*z/(2*nf)) / denom
+	margin := z * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf)) / denom
+	return p, math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// WriteText writes the report to w as the text table.
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Archive: %s\n", r.Archive)
+	if !r.AsOf.IsZero() {
+		fmt.Fprintf(w, "Created: %s\n", r.AsOf.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "Samples: %d (seed %d), live messages: %d, archived: %d\n\n", len(r.Results), r.Seed, r.Live, r.Archived)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANNEL\tFROM\tTO\tLIVE\tMISSING\tMISSING REPLIES\tMISSED EDITS\tDELETED")
+	for _, res := range r.Results {
+		name := res.ChannelID
+		if res.Name != "" {
+			name += " (#" + res.Name + ")"
+		}
+		from, to := res.Oldest.Format("2006-01-02"), res.Latest.Format("2006-01-02")
+		if res.Error != "" {
+			fmt.Fprintf(tw, "%s\t%s\t%s\terror: %s\t\t\t\t\n", name, from, to, res.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\n", name, from, to, res.Live, len(res.Missing), len(res.MissingReplies), len(res.MissedEdits), len(res.Deleted))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	if r.Live == 0 {
+		_, err := fmt.Fprintln(w, "No live messages in the samples, the completeness is unknown.")
+		return err
+	}
+	fmt.Fprintf(w, "Missing: %d message(s), %d reply(ies) in %d thread(s), missed edits: %d\n", r.Missing, r.MissingReplies, r.MissingThreads, r.MissedEdits)
+	_, err := fmt.Fprintf(w, "Completeness: %.2f%% (95%% confidence: %.2f%% - %.2f%%)\n", r.Completeness*100, r.Lower*100, r.Upper*100)
+	return err
+}
+
+// WriteJSON writes the report to w as JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,124 @@
+package liveaudit
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func msg(ts, text string, replies ...types.Message) types.Message {
+	var m types.Message
+	m.Timestamp = ts
+	m.Text = text
+	if len(replies) > 0 {
+		m.ThreadTimestamp = ts
+		m.ThreadReplies = replies
+	}
+	return m
+}
+
+func reply(parent, ts, text string) types.Message {
+	m := msg(ts, text)
+	m.ThreadTimestamp = parent
+	return m
+}
+
+func edited(m types.Message, ts string) types.Message {
+	m.Edited = &slack.Edited{Timestamp: ts}
+	return m
+}
+
+func TestCompare(t *testing.T) {
+	s := Sample{ChannelID: "C01", Oldest: time.Unix(1000, 0), Latest: time.Unix(2000, 0)}
+	asOf := time.Unix(3000, 0)
+
+	archived := []types.Message{
+		msg("999.000000", "before the sample"),
+		msg("1000.000000", "complete"),
+		msg("1100.000000", "thread", reply("1100.000000", "1110.000000", "first")),
+		msg("1200.000000", "old text"),
+		msg("1300.000000", "deleted since"),
+		edited(msg("1400.000000", "edited"), "1450.000000"),
+	}
+	live := []types.Message{
+		msg("999.000000", "before the sample, missing in the archive"),
+		msg("1000.000000", "complete"),
+		msg("1100.000000", "thread",
+			reply("1100.000000", "1110.000000", "first"),
+			reply("1100.000000", "1120.000000", "missing"),
+			reply("1100.000000", "3100.000000", "after the archive"),
+		),
+		edited(msg("1200.000000", "new text"), "1250.000000"),
+		edited(msg("1400.000000", "edited"), "1450.000000"),
+		edited(msg("1500.000000", "missing"), "1550.000000"),
+		edited(msg("1600.000000", "edited after the archive"), "3200.000000"),
+	}
+	archived = append(archived, msg("1600.000000", "original"))
+
+	got := Compare(s, archived, live, asOf)
+	assert.Equal(t, 8, got.Live)
+	assert.Equal(t, 6, got.Archived)
+	assert.Equal(t, []string{"1500.000000"}, got.Missing)
+	assert.Equal(t, []string{"1120.000000"}, got.MissingReplies)
+	assert.Equal(t, []string{"1100.000000"}, got.MissingThreads)
+	assert.Equal(t, []string{"1200.000000"}, got.MissedEdits)
+	assert.Equal(t, []string{"1300.000000"}, got.Deleted)
+}
+
+func TestPick(t *testing.T) {
+	oldest, latest := time.Unix(0, 0), time.Unix(0, 0).Add(30*24*time.Hour)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		s := Pick("C01", "general", oldest, latest, 7*24*time.Hour, rnd)
+		assert.Equal(t, 7*24*time.Hour, s.Latest.Sub(s.Oldest))
+		assert.False(t, s.Oldest.Before(oldest))
+		assert.False(t, s.Latest.After(latest))
+	}
+	s := Pick("C01", "general", oldest, latest, 60*24*time.Hour, rnd)
+	assert.Equal(t, Sample{ChannelID: "C01", Name: "general", Oldest: oldest, Latest: latest}, s, "the short range is sampled whole")
+	s = Pick("C01", "general", oldest, latest, 0, rnd)
+	assert.Equal(t, oldest, s.Oldest)
+}
+
+func TestNewReport(t *testing.T) {
+	results := []Result{
+		{Live: 90, Archived: 90, MissedEdits: []string{"1.000000"}},
+		{Live: 10, Archived: 8, Missing: []string{"2.000000"}, MissingReplies: []string{"3.000000"}, MissingThreads: []string{"4.000000"}},
+		{Error: "not_in_channel"},
+	}
+	r := NewReport("test", time.Time{}, 42, results)
+	assert.Equal(t, 100, r.Live)
+	assert.Equal(t, 98, r.Archived)
+	assert.Equal(t, 1, r.MissedEdits)
+	assert.InDelta(t, 0.97, r.Completeness, 1e-9)
+	assert.True(t, r.Lower < r.Completeness && r.Completeness < r.Upper)
+	assert.InDelta(t, 0.915, r.Lower, 0.005)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteText(&buf))
+	assert.Contains(t, buf.String(), "Completeness: 97.00%")
+	assert.Contains(t, buf.String(), "error: not_in_channel")
+
+	buf.Reset()
+	require.NoError(t, NewReport("test", time.Time{}, 42, nil).WriteText(&buf))
+	assert.Contains(t, buf.String(), "the completeness is unknown")
+}
+
+func TestWilson(t *testing.T) {
+	p, lower, upper := wilson(10, 10)
+	assert.Equal(t, 1.0, p)
+	assert.Equal(t, 1.0, upper)
+	assert.InDelta(t, 0.722, lower, 0.001)
+
+	p, lower, upper = wilson(0, 0)
+	assert.Zero(t, p)
+	assert.Zero(t, lower)
+	assert.Zero(t, upper)
+}
@@ -0,0 +1,191 @@
+// Package metrics implements the run metrics, exposed in the Prometheus text
+// format, so that the long running slackdump can be monitored like any other
+// service.
+//
+// Metrics are process wide, the same way as the Prometheus default registry.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Metrics of the run.
+var (
+	APICalls         = newCounterVec("slackdump_api_calls_total", "Number of Slack API calls by method.", "method")
+	RateLimited      = newCounterVec("slackdump_api_rate_limited_total", "Number of Slack API calls rate limited (HTTP 429) by method.", "method")
+	MessagesArchived = newCounter("slackdump_messages_archived_total", "Number of messages saved.")
+	FilesDownloaded  = newCounter("slackdump_files_downloaded_total", "Number of files downloaded.")
+	BytesWritten     = newCounter("slackdump_bytes_written_total", "Number of bytes written to the output.")
+	Backlog          = newGauge("slackdump_backlog", "Number of files queued for download.")
+	RunSuccess       = newGauge("slackdump_last_run_success", "1 if the last run has succeeded, 0 otherwise.")
+	RunCompleted     = newGauge("slackdump_last_run_timestamp_seconds", "Unix time of the completion of the last run.")
+)
+
+// collector is the metric that can write itself in the text format.
+type collector interface {
+	write(w io.Writer)
+}
+
+var (
+	mu       sync.Mutex
+	registry []collector
+)
+
+func register(c collector) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry = append(registry, c)
+}
+
+// WriteTo writes all the metrics to w in the Prometheus text format.
+func WriteTo(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, c := range registry {
+		c.write(w)
+	}
+}
+
+// Handler returns the http.Handler that serves the metrics.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteTo(w)
+	})
+}
+
+func writeHeader(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// Counter is the monotonically increasing counter.
+type Counter struct {
+	name string
+	help string
+	v    uint64
+}
+
+func newCounter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	register(c)
+	return c
+}
+
+// Inc increments the counter.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add adds n to the counter.
+func (c *Counter) Add(n int) {
+	if n > 0 {
+		atomic.AddUint64(&c.v, uint64(n))
+	}
+}
+
+// Value returns the current value.
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.v)
+}
+
+func (c *Counter) write(w io.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	fmt.Fprintf(w, "%s %d\n", c.name, c.Value())
+}
+
+// Gauge is the value that can go up and down.
+type Gauge struct {
+	name string
+	help string
+	v    int64
+}
+
+func newGauge(name, help string) *Gauge {
+	g := &Gauge{name: name, help: help}
+	register(g)
+	return g
+}
+
+// Add adds n to the gauge, n can be negative.
+func (g *Gauge) Add(n int) {
+	atomic.AddInt64(&g.v, int64(n))
+}
+
+// Set sets the gauge to n.
+func (g *Gauge) Set(n int64) {
+	atomic.StoreInt64(&g.v, n)
+}
+
+// Value returns the current value.
+func (g *Gauge) Value() int64 {
+	return atomic.LoadInt64(&g.v)
+}
+
+func (g *Gauge) write(w io.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	fmt.Fprintf(w, "%s %d\n", g.name, g.Value())
+}
+
+// CounterVec is the set of counters partitioned by the label value.
+type CounterVec struct {
+	name  string
+	help  string
+	label string
+
+	mu       sync.Mutex
+	counters map[string]*uint64
+}
+
+func newCounterVec(name, help, label string) *CounterVec {
+	cv := &CounterVec{name: name, help: help, label: label, counters: make(map[string]*uint64)}
+	register(cv)
+	return cv
+}
+
+// Inc increments the counter for the label value.
+func (cv *CounterVec) Inc(value string) {
+	cv.mu.Lock()
+	p, ok := cv.counters[value]
+	if !ok {
+		p = new(uint64)
+		cv.counters[value] = p
+	}
+	cv.mu.Unlock()
+	atomic.AddUint64(p, 1)
+}
+
+// Value returns the value of the counter for the label value.
+func (cv *CounterVec) Value(value string) uint64 {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	if p, ok := cv.counters[value]; ok {
+		return atomic.LoadUint64(p)
+	}
+	return 0
+}
+
+func (cv *CounterVec) write(w io.Writer) {
+	writeHeader(w, cv.name, cv.help, "counter")
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	values := make([]string, 0, len(cv.counters))
+	for v := range cv.counters {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	for _, v := range values {
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %d\n", cv.name, cv.label, escapeLabel(v), atomic.LoadUint64(cv.counters[v]))
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
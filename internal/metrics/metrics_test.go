@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+)
+
+func TestWriteTo(t *testing.T) {
+	cv := &CounterVec{name: "test_total", help: "Test.", label: "method", counters: make(map[string]*uint64)}
+	cv.Inc(`conversations.history`)
+	cv.Inc(`conversations.history`)
+	cv.Inc("weird\"label")
+	var buf bytes.Buffer
+	cv.write(&buf)
+	assert.Equal(t, `# HELP test_total Test.
+# TYPE test_total counter
+test_total{method="conversations.history"} 2
+test_total{method="weird\"label"} 1
+`, buf.String())
+
+	buf.Reset()
+	WriteTo(&buf)
+	for _, name := range []string{"slackdump_api_calls_total", "slackdump_messages_archived_total", "slackdump_backlog"} {
+		assert.Contains(t, buf.String(), "# TYPE "+name+" ")
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/conversations.replies" {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	cl := &http.Client{Transport: NewTransport(nil, "")}
+	before := APICalls.Value("conversations.replies")
+	beforeRL := RateLimited.Value("conversations.replies")
+	for _, p := range []string{"/api/conversations.replies", "/api/conversations.replies", "/files/F01"} {
+		resp, err := cl.Get(srv.URL + p)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, before+2, APICalls.Value("conversations.replies"))
+	assert.Equal(t, beforeRL+2, RateLimited.Value("conversations.replies"))
+	assert.Zero(t, APICalls.Value("F01"))
+}
+
+func TestNewFS(t *testing.T) {
+	fsa := NewFS(fsadapter.NewDirectory(t.TempDir()))
+	before := BytesWritten.Value()
+
+	require.NoError(t, fsa.WriteFile("a.txt", []byte("hello"), 0644))
+	w, err := fsa.Create("b.txt")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("world!"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	w, err = fsadapter.Append(fsa, "b.txt")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("!!"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, before+13, BytesWritten.Value())
+	assert.True(t, strings.HasPrefix(fsa.(interface{ String() string }).String(), "<directory"), "must describe the underlying filesystem")
+}
+
+func TestPush(t *testing.T) {
+	var (
+		gotPath string
+		gotBody string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		gotPath = r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+	defer srv.Close()
+
+	require.NoError(t, Push(context.Background(), srv.URL+"/"))
+	assert.Equal(t, "/metrics/job/slackdump", gotPath)
+	assert.Contains(t, gotBody, "# TYPE slackdump_last_run_success gauge\n")
+
+	require.NoError(t, Push(context.Background(), srv.URL+"/metrics/job/nightly/team/hr"))
+	assert.Equal(t, "/metrics/job/nightly/team/hr", gotPath)
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	assert.Error(t, Push(context.Background(), srv.URL))
+}
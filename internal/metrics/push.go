@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// pushJob is the job name of the metrics on the Pushgateway.
+const pushJob = "slackdump"
+
+// Push sends the metrics to the Prometheus Pushgateway at the URL gateway,
+// i.e. http://pushgateway:9091, replacing the metrics of the previous run.
+// The metrics are grouped under the job "slackdump", unless the URL already
+// has the grouping path, i.e. http://pushgateway:9091/metrics/job/nightly.
+//
+// Push is for the short-lived runs, i.e. the Kubernetes CronJobs, that
+// complete before Prometheus could scrape them.
+func Push(ctx context.Context, gateway string) error {
+	url := strings.TrimSuffix(gateway, "/")
+	if !strings.Contains(url, "/metrics/job/") {
+		url += "/metrics/job/" + pushJob
+	}
+	var buf bytes.Buffer
+	WriteTo(&buf)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("pushgateway: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+// transport counts the Slack API calls.
+type transport struct {
+	rt     http.RoundTripper
+	prefix string // API path prefix, see network.APIPrefix
+}
+
+// NewTransport wraps rt, so that Slack API calls and rate limited responses
+// are counted by the API method.  If rt is nil, http.DefaultTransport is
+// used.  The apiPrefix is the URL path prefix of the API methods, see
+// network.APIPrefix.
+func NewTransport(rt http.RoundTripper, apiPrefix string) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{rt: rt, prefix: apiPrefix}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := network.APIMethod(req, t.prefix)
+	if method == "" {
+		return t.rt.RoundTrip(req)
+	}
+	APICalls.Inc(method)
+	resp, err := t.rt.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		RateLimited.Inc(method)
+	}
+	return resp, err
+}
+
+// fs counts the bytes written to the underlying filesystem.
+type fs struct {
+	fsadapter.FS
+}
+
+// NewFS wraps the filesystem fsa, so that the bytes written are counted.
+func NewFS(fsa fsadapter.FS) fsadapter.FS {
+	return fs{fsa}
+}
+
+func (f fs) Create(name string) (io.WriteCloser, error) {
+	wc, err := f.FS.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return countingWriter{wc}, nil
+}
+
+func (f fs) Append(name string) (io.WriteCloser, error) {
+	wc, err := fsadapter.Append(f.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	return countingWriter{wc}, nil
+}
+
+func (f fs) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if err := f.FS.WriteFile(name, data, perm); err != nil {
+		return err
+	}
+	BytesWritten.Add(len(data))
+	return nil
+}
+
+// String returns the description of the underlying filesystem.
+func (f fs) String() string {
+	if s, ok := f.FS.(interface{ String() string }); ok {
+		return s.String()
+	}
+	return "metrics"
+}
+
+type countingWriter struct {
+	io.WriteCloser
+}
+
+func (w countingWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	BytesWritten.Add(n)
+	return n, err
+}
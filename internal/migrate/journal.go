@@ -0,0 +1,50 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Journal is the record of the posted messages.
+type Journal struct {
+	name string
+	// Posted maps the source message, as "channel ID/ts", to the timestamp
+	// of the posted message.
+	Posted map[string]string `json:"posted"`
+}
+
+// OpenJournal reads the journal from the file name, if it exists, or returns
+// the new empty journal, that will be saved to name.
+func OpenJournal(name string) (*Journal, error) {
+	j := &Journal{name: name, Posted: make(map[string]string)}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return j, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if j.Posted == nil {
+		j.Posted = make(map[string]string)
+	}
+	return j, nil
+}
+
+// Save writes the journal to the file, it's a no-op for the in-memory
+// journal.
+func (j *Journal) Save() error {
+	if j.name == "" {
+		return nil
+	}
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(j.name, data, 0644)
+}
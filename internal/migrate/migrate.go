@@ -0,0 +1,401 @@
+// Package migrate posts the messages of the archive into another workspace,
+// for the teams, that consolidate the workspaces.
+//
+// Messages are posted with chat.postMessage on behalf of the token owner
+// (the bot), with the name and the avatar of the original author, so the
+// destination token needs the chat:write.customize scope.  Threads are
+// re-created by posting the replies to the new parent messages, the
+// downloaded files are uploaded to the same thread.  Mentions of the users
+// are rewritten according to the user mapping.
+//
+// The posted messages are recorded in the journal, so that the interrupted
+// migration can be repeated without posting the same message twice.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"golang.org/x/time/rate"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/internal/structures/files"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Source is the archive, that is migrated, it is implemented by
+// archive.Archive.
+type Source interface {
+	Channels() ([]slack.Channel, error)
+	Users() (types.Users, error)
+	Conversation(channelID string) (*types.Conversation, error)
+	FS() fs.FS
+}
+
+// Destination is the Slack API of the destination workspace, it is
+// implemented by slack.Client.
+type Destination interface {
+	GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error)
+	CreateConversationContext(ctx context.Context, params slack.CreateConversationParams) (*slack.Channel, error)
+	PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+	UploadFileContext(ctx context.Context, params slack.FileUploadParameters) (*slack.File, error)
+}
+
+const (
+	// maxAttempts is the number of attempts of the API call.
+	maxAttempts = 3
+	// postInterval is the interval between the posted messages, Slack allows
+	// one message per second per channel.
+	postInterval = time.Second
+)
+
+// Migrator posts the messages of the archive into the destination workspace.
+type Migrator struct {
+	dst     Destination
+	users   UserMap
+	journal *Journal
+	create  bool
+	lg      logger.Interface
+
+	postLim   *rate.Limiter
+	uploadLim *rate.Limiter
+}
+
+// Option is the Migrator option.
+type Option func(*Migrator)
+
+// WithUsers sets the mapping of the source users to the destination users.
+func WithUsers(m UserMap) Option {
+	return func(mg *Migrator) {
+		mg.users = m
+	}
+}
+
+// WithJournal sets the journal of the posted messages.  If not set, the
+// messages are recorded in memory only.
+func WithJournal(j *Journal) Option {
+	return func(mg *Migrator) {
+		if j != nil {
+			mg.journal = j
+		}
+	}
+}
+
+// WithCreate enables creation of the channels, that don't exist in the
+// destination workspace.  If disabled, such channels are skipped.
+func WithCreate(create bool) Option {
+	return func(mg *Migrator) {
+		mg.create = create
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(lg logger.Interface) Option {
+	return func(mg *Migrator) {
+		if lg != nil {
+			mg.lg = lg
+		}
+	}
+}
+
+// New creates the Migrator, that posts to the destination dst.
+func New(dst Destination, opts ...Option) *Migrator {
+	mg := &Migrator{
+		dst:       dst,
+		journal:   &Journal{Posted: make(map[string]string)},
+		lg:        logger.Default,
+		postLim:   rate.NewLimiter(rate.Every(postInterval), 1),
+		uploadLim: network.NewLimiter(network.Tier2, 1, 0),
+	}
+	for _, opt := range opts {
+		opt(mg)
+	}
+	return mg
+}
+
+// Stats is the number of the migrated records.
+type Stats struct {
+	Channels int // migrated channels
+	Messages int // posted messages
+	Files    int // uploaded files
+	Skipped  int // skipped messages and files, i.e. joins or files, that were not downloaded
+}
+
+// Migrate posts the conversations of the archive src.  If channelIDs are
+// given, only those channels are migrated.  Direct messages are skipped, as
+// they can't be posted on behalf of the other users.
+func (mg *Migrator) Migrate(ctx context.Context, src Source, channelIDs ...string) (st Stats, err error) {
+	chans, err := src.Channels()
+	if err != nil {
+		return st, err
+	}
+	users, err := src.Users()
+	if err != nil {
+		return st, err
+	}
+	dstChans, err := mg.destChannels(ctx)
+	if err != nil {
+		return st, fmt.Errorf("destination channels: %w", err)
+	}
+	defer func() {
+		if jerr := mg.journal.Save(); jerr != nil && err == nil {
+			err = jerr
+		}
+	}()
+
+	c := conv{Migrator: mg, src: src, idx: users.IndexByID(), st: &st}
+	include := make(map[string]bool, len(channelIDs))
+	for _, id := range channelIDs {
+		include[id] = true
+	}
+	for _, ch := range chans {
+		if len(include) > 0 && !include[ch.ID] {
+			continue
+		}
+		if ch.IsIM || ch.IsMpIM {
+			mg.lg.Printf("%s: skipping the direct messages", ch.ID)
+			continue
+		}
+		dstID, ok := dstChans[ch.Name]
+		if !ok {
+			if !mg.create {
+				mg.lg.Printf("%s: channel #%s doesn't exist in the destination workspace, skipping", ch.ID, ch.Name)
+				continue
+			}
+			if dstID, err = mg.createChannel(ctx, ch); err != nil {
+				return st, fmt.Errorf("%s: %w", ch.ID, err)
+			}
+		}
+		if err := c.channel(ctx, ch, dstID); err != nil {
+			return st, fmt.Errorf("%s: %w", ch.ID, err)
+		}
+		if err := mg.journal.Save(); err != nil {
+			return st, err
+		}
+		st.Channels++
+	}
+	return st, nil
+}
+
+// destChannels returns the mapping of the channel names to IDs of the
+// destination workspace.
+func (mg *Migrator) destChannels(ctx context.Context) (map[string]string, error) {
+	var (
+		byName = make(map[string]string)
+		params = slack.GetConversationsParameters{Types: []string{"public_channel", "private_channel"}, Limit: 200}
+	)
+	for {
+		var (
+			chans []slack.Channel
+			next  string
+		)
+		if err := network.WithRetry(ctx, mg.uploadLim, maxAttempts, func() (err error) {
+			chans, next, err = mg.dst.GetConversationsContext(ctx, &params)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		for _, ch := range chans {
+			byName[ch.Name] = ch.ID
+		}
+		if next == "" {
+			return byName, nil
+		}
+		params.Cursor = next
+	}
+}
+
+// createChannel creates the channel ch in the destination workspace.
+func (mg *Migrator) createChannel(ctx context.Context, ch slack.Channel) (string, error) {
+	var created *slack.Channel
+	if err := network.WithRetry(ctx, mg.uploadLim, maxAttempts, func() (err error) {
+		created, err = mg.dst.CreateConversationContext(ctx, slack.CreateConversationParams{ChannelName: ch.Name, IsPrivate: ch.IsPrivate})
+		return err
+	}); err != nil {
+		return "", err
+	}
+	mg.lg.Printf("%s: created channel #%s (%s)", ch.ID, ch.Name, created.ID)
+	return created.ID, nil
+}
+
+// conv is the migration of the single archive.
+type conv struct {
+	*Migrator
+	src Source
+	idx structures.UserIndex // source users
+	st  *Stats
+}
+
+// channel posts the messages of the channel ch to the destination channel
+// dstID.
+func (c conv) channel(ctx context.Context, ch slack.Channel, dstID string) error {
+	cnv, err := c.src.Conversation(ch.ID)
+	if err != nil {
+		return err
+	}
+	for _, m := range cnv.Messages {
+		ts, err := c.post(ctx, ch.ID, dstID, m, "")
+		if err != nil {
+			return err
+		}
+		if ts == "" {
+			continue
+		}
+		for _, reply := range m.ThreadReplies {
+			if _, err := c.post(ctx, ch.ID, dstID, reply, ts); err != nil {
+				return err
+			}
+		}
+	}
+	c.lg.Debugf("%s: migrated to %s", ch.ID, dstID)
+	return nil
+}
+
+// postedSubtypes are the message subtypes, that are posted, the rest, i.e.
+// joins and topic changes, are skipped.  The tombstone of the deleted
+// message is posted as the placeholder, so that its thread replies are
+// migrated.
+var postedSubtypes = map[string]bool{
+	"":                     true,
+	"bot_message":          true,
+	"file_share":           true,
+	"me_message":           true,
+	"thread_broadcast":     true,
+	types.SubTypeTombstone: true,
+}
+
+// post posts the message m of the source channel to the destination channel
+// dstID, into the thread threadTS, if it's not empty.  It returns the
+// timestamp of the posted message, or an empty string if the message was
+// skipped.
+func (c conv) post(ctx context.Context, channelID, dstID string, m types.Message, threadTS string) (string, error) {
+	key := channelID + "/" + m.Timestamp
+	if ts, ok := c.journal.Posted[key]; ok {
+		return ts, nil
+	}
+	if !postedSubtypes[m.SubType] {
+		c.st.Skipped++
+		return "", nil
+	}
+	text := c.users.rewrite(m.Text, c.idx)
+	if m.IsTombstone() {
+		text = types.TombstoneText
+	}
+	if text == "" {
+		var names []string
+		for _, f := range m.Files {
+			names = append(names, f.Name)
+		}
+		text = strings.Join(names, ", ")
+	}
+	if text == "" && len(m.Attachments) == 0 {
+		c.st.Skipped++
+		return "", nil
+	}
+	opts := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if name, icon := c.author(m); name != "" {
+		opts = append(opts, slack.MsgOptionUsername(name))
+		if icon != "" {
+			opts = append(opts, slack.MsgOptionIconURL(icon))
+		}
+	}
+	if len(m.Attachments) > 0 {
+		opts = append(opts, slack.MsgOptionAttachments(m.Attachments...))
+	}
+	if threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(threadTS))
+		if m.SubType == "thread_broadcast" {
+			opts = append(opts, slack.MsgOptionBroadcast())
+		}
+	}
+	var ts string
+	if err := network.WithRetry(ctx, c.postLim, maxAttempts, func() (err error) {
+		_, ts, err = c.dst.PostMessageContext(ctx, dstID, opts...)
+		return err
+	}); err != nil {
+		return "", fmt.Errorf("message %s: %w", m.Timestamp, err)
+	}
+	c.journal.Posted[key] = ts
+	c.st.Messages++
+
+	fileTS := ts
+	if threadTS != "" {
+		fileTS = threadTS
+	}
+	for _, f := range m.Files {
+		if err := c.upload(ctx, dstID, f, fileTS); err != nil {
+			return "", fmt.Errorf("message %s: file %s: %w", m.Timestamp, f.ID, err)
+		}
+	}
+	return ts, nil
+}
+
+// upload uploads the downloaded file f into the thread threadTS.  Files, that
+// were not downloaded, are skipped.
+func (c conv) upload(ctx context.Context, dstID string, f slack.File, threadTS string) error {
+	name := localPath(f)
+	if name == "" {
+		c.st.Skipped++
+		return nil
+	}
+	r, err := c.src.FS().Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			c.st.Skipped++
+			return nil
+		}
+		return err
+	}
+	defer r.Close()
+	// the reader can't be rewound, so the upload is not retried.
+	if err := c.uploadLim.Wait(ctx); err != nil {
+		return err
+	}
+	if _, err := c.dst.UploadFileContext(ctx, slack.FileUploadParameters{
+		Reader:          r,
+		Filename:        f.Name,
+		Title:           f.Title,
+		Channels:        []string{dstID},
+		ThreadTimestamp: threadTS,
+	}); err != nil {
+		return err
+	}
+	c.st.Files++
+	return nil
+}
+
+// author returns the name and the avatar URL of the author of the message.
+func (c conv) author(m types.Message) (name, icon string) {
+	if u, ok := c.idx[m.User]; ok && u != nil {
+		name = u.RealName
+		if name == "" {
+			name = u.Name
+		}
+		return name, u.Profile.Image72
+	}
+	if m.BotProfile != nil {
+		if m.BotProfile.Icons != nil {
+			icon = m.BotProfile.Icons.Image72
+		}
+		return m.BotProfile.Name, icon
+	}
+	return m.Username, ""
+}
+
+// localPath returns the path of the downloaded file f within the archive, or
+// an empty string, if the file was not downloaded.
+func localPath(f slack.File) string {
+	for _, p := range []string{f.URLPrivateDownload, f.URLPrivate} {
+		if files.IsLocal(p) {
+			return p
+		}
+	}
+	return ""
+}
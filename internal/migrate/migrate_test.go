@@ -0,0 +1,163 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/rusq/slackdump/v2/archive"
+)
+
+var testFS = fstest.MapFS{
+	"channels.json": {Data: []byte(`[{"id":"C01","name":"general"},{"id":"C02","name":"random"}]`)},
+	"dms.json":      {Data: []byte(`[{"id":"D01","created":1600000000,"members":["U01","U02"]}]`)},
+	"users.json": {Data: []byte(`[
+		{"id":"U01","name":"alice","real_name":"Alice","profile":{"email":"alice@example.com","image_72":"https://example.com/alice.png"}},
+		{"id":"U02","name":"bob","profile":{"email":"bob@example.com"}}
+	]`)},
+	"general/2023-01-01.json": {Data: []byte(`[
+		{"type":"message","user":"U01","text":"parent <@U02>","ts":"1672531200.000100","thread_ts":"1672531200.000100","reply_count":1,
+		 "files":[{"id":"F01","name":"a.txt","url_private":"attachments/F01-a.txt","url_private_download":"attachments/F01-a.txt"}]},
+		{"type":"message","subtype":"channel_join","user":"U02","text":"<@U02> has joined the channel","ts":"1672531250.000100"},
+		{"type":"message","user":"U02","text":"reply <@U01|alice>","ts":"1672531300.000100","thread_ts":"1672531200.000100"}
+	]`)},
+	"general/attachments/F01-a.txt": {Data: []byte("file contents")},
+	"random/2023-01-01.json":        {Data: []byte(`[{"type":"message","user":"U02","text":"random","ts":"1672531200.000100"}]`)},
+	"D01/2023-01-01.json":           {Data: []byte(`[{"type":"message","user":"U01","text":"dm","ts":"1672531200.000100"}]`)},
+}
+
+// post is the message, posted to the fake destination.
+type post struct {
+	Channel  string
+	Text     string
+	Username string
+	ThreadTS string
+}
+
+// fakeDest is the destination workspace, that records the posts.
+type fakeDest struct {
+	channels []slack.Channel
+	posts    []post
+	uploads  []slack.FileUploadParameters
+	contents []string
+	ts       int
+}
+
+func (d *fakeDest) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	return d.channels, "", nil
+}
+
+func (d *fakeDest) CreateConversationContext(ctx context.Context, params slack.CreateConversationParams) (*slack.Channel, error) {
+	ch := slack.Channel{}
+	ch.ID, ch.Name = fmt.Sprintf("CN%d", len(d.channels)), params.ChannelName
+	d.channels = append(d.channels, ch)
+	return &ch, nil
+}
+
+func (d *fakeDest) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	_, v, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	if err != nil {
+		return "", "", err
+	}
+	d.ts++
+	d.posts = append(d.posts, post{Channel: channelID, Text: v.Get("text"), Username: v.Get("username"), ThreadTS: v.Get("thread_ts")})
+	return channelID, fmt.Sprintf("2000000000.%06d", d.ts), nil
+}
+
+func (d *fakeDest) UploadFileContext(ctx context.Context, params slack.FileUploadParameters) (*slack.File, error) {
+	data, err := io.ReadAll(params.Reader)
+	if err != nil {
+		return nil, err
+	}
+	params.Reader = nil
+	d.uploads = append(d.uploads, params)
+	d.contents = append(d.contents, string(data))
+	return &slack.File{}, nil
+}
+
+func testMigrator(dst Destination, opts ...Option) *Migrator {
+	mg := New(dst, opts...)
+	mg.postLim = rate.NewLimiter(rate.Inf, 1)
+	mg.uploadLim = rate.NewLimiter(rate.Inf, 1)
+	return mg
+}
+
+func destChannel(id, name string) slack.Channel {
+	var ch slack.Channel
+	ch.ID, ch.Name = id, name
+	return ch
+}
+
+func TestMigrator_Migrate(t *testing.T) {
+	ar, err := archive.New(testFS, "test")
+	require.NoError(t, err)
+
+	t.Run("existing channels", func(t *testing.T) {
+		dst := &fakeDest{channels: []slack.Channel{destChannel("CX1", "general")}}
+		st, err := testMigrator(dst, WithUsers(UserMap{"U02": "UX2"})).Migrate(context.Background(), ar)
+		require.NoError(t, err)
+		assert.Equal(t, Stats{Channels: 1, Messages: 2, Files: 1, Skipped: 1}, st)
+		assert.Equal(t, []post{
+			{Channel: "CX1", Text: "parent <@UX2>", Username: "Alice"},
+			{Channel: "CX1", Text: "reply @alice", Username: "bob", ThreadTS: "2000000000.000001"},
+		}, dst.posts)
+		require.Len(t, dst.uploads, 1)
+		assert.Equal(t, "a.txt", dst.uploads[0].Filename)
+		assert.Equal(t, "2000000000.000001", dst.uploads[0].ThreadTimestamp)
+		assert.Equal(t, []string{"file contents"}, dst.contents)
+	})
+	t.Run("create channels", func(t *testing.T) {
+		dst := &fakeDest{}
+		st, err := testMigrator(dst, WithCreate(true)).Migrate(context.Background(), ar, "C02", "D01")
+		require.NoError(t, err)
+		assert.Equal(t, Stats{Channels: 1, Messages: 1}, st)
+		assert.Equal(t, []post{{Channel: "CN0", Text: "random", Username: "bob"}}, dst.posts)
+	})
+	t.Run("journal", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "journal.json")
+		j, err := OpenJournal(name)
+		require.NoError(t, err)
+		dst := &fakeDest{channels: []slack.Channel{destChannel("CX1", "general")}}
+		_, err = testMigrator(dst, WithJournal(j)).Migrate(context.Background(), ar)
+		require.NoError(t, err)
+		require.Len(t, dst.posts, 2)
+
+		// the repeated migration doesn't post anything.
+		j, err = OpenJournal(name)
+		require.NoError(t, err)
+		assert.Len(t, j.Posted, 2)
+		st, err := testMigrator(dst, WithJournal(j)).Migrate(context.Background(), ar)
+		require.NoError(t, err)
+		assert.Equal(t, 0, st.Messages)
+		assert.Len(t, dst.posts, 2)
+	})
+}
+
+func TestMigrator_Migrate_tombstone(t *testing.T) {
+	fsys := fstest.MapFS{
+		"channels.json": {Data: []byte(`[{"id":"C01","name":"general"}]`)},
+		"users.json":    {Data: []byte(`[{"id":"U01","name":"alice"}]`)},
+		"general/2023-01-01.json": {Data: []byte(`[
+			{"type":"message","subtype":"tombstone","user":"USLACKBOT","text":"This message was deleted.","hidden":true,"ts":"1672531200.000100","thread_ts":"1672531200.000100","reply_count":1},
+			{"type":"message","user":"U01","text":"reply","ts":"1672531300.000100","thread_ts":"1672531200.000100"}
+		]`)},
+	}
+	ar, err := archive.New(fsys, "test")
+	require.NoError(t, err)
+
+	dst := &fakeDest{channels: []slack.Channel{destChannel("CX1", "general")}}
+	st, err := testMigrator(dst).Migrate(context.Background(), ar)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Channels: 1, Messages: 2}, st)
+	require.Len(t, dst.posts, 2)
+	assert.Equal(t, "This message was deleted.", dst.posts[0].Text)
+	assert.Equal(t, post{Channel: "CX1", Text: "reply", Username: "alice", ThreadTS: "2000000000.000001"}, dst.posts[1])
+}
@@ -0,0 +1,31 @@
+package migrate
+
+import (
+	"regexp"
+
+	"github.com/rusq/slackdump/v2/internal/structures"
+)
+
+// UserMap maps the source user IDs to the destination user IDs, see
+// usermap.Map.Resolve.
+type UserMap map[string]string
+
+// reMention matches the user mentions, i.e. <@U123> or <@U123|alice>.
+var reMention = regexp.MustCompile(`<@([UW][A-Z0-9]+)(?:\|[^>]*)?>`)
+
+// rewrite replaces the mentions of the source users in text with the
+// mentions of the destination users.  Users, that are not mapped, are
+// replaced with their names from the source user index idx, so that the
+// message doesn't mention the wrong user.
+func (um UserMap) rewrite(text string, idx structures.UserIndex) string {
+	return reMention.ReplaceAllStringFunc(text, func(s string) string {
+		id := reMention.FindStringSubmatch(s)[1]
+		if dst, ok := um[id]; ok {
+			return "<@" + dst + ">"
+		}
+		if u, ok := idx[id]; ok && u != nil {
+			return "@" + u.Name
+		}
+		return "@" + id
+	})
+}
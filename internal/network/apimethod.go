@@ -0,0 +1,36 @@
+package network
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defAPIPrefix is the URL path prefix of the Slack API methods on the default
+// API URL.
+const defAPIPrefix = "/api/"
+
+// APIPrefix returns the URL path prefix of the Slack API methods for the API
+// base URL, that may have the path, i.e. "/slack/api/" for the proxy at
+// "https://gw.example.com/slack".
+func APIPrefix(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return defAPIPrefix
+	}
+	return strings.TrimRight(u.Path, "/") + defAPIPrefix
+}
+
+// APIMethod returns the Slack API method name of the request, i.e.
+// "users.list", or an empty string, if it's not an API call.  The prefix is
+// the path prefix of the API methods, see APIPrefix, empty - the default
+// "/api/".
+func APIMethod(req *http.Request, prefix string) string {
+	if prefix == "" {
+		prefix = defAPIPrefix
+	}
+	if !strings.HasPrefix(req.URL.Path, prefix) {
+		return ""
+	}
+	return strings.Trim(strings.TrimPrefix(req.URL.Path, prefix), "/")
+}
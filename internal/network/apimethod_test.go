@@ -0,0 +1,23 @@
+package network
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIPrefix(t *testing.T) {
+	assert.Equal(t, "/api/", APIPrefix("https://slack.com"))
+	assert.Equal(t, "/slack/api/", APIPrefix("https://gw.example.com/slack/"))
+}
+
+func TestAPIMethod(t *testing.T) {
+	assert.Equal(t, "users.list", APIMethod(httptest.NewRequest("POST", "https://slack.com/api/users.list", nil), ""))
+	assert.Equal(t, "conversations.history", APIMethod(httptest.NewRequest("GET", "https://slack.com/api/conversations.history/?limit=1", nil), "/api/"))
+	assert.Equal(t, "", APIMethod(httptest.NewRequest("GET", "https://files.slack.com/files-pri/T-F/x.png", nil), ""))
+
+	prefix := APIPrefix("https://gw.example.com/slack")
+	assert.Equal(t, "users.list", APIMethod(httptest.NewRequest("POST", "https://gw.example.com/slack/api/users.list", nil), prefix), "prefixed base URL")
+	assert.Equal(t, "", APIMethod(httptest.NewRequest("POST", "https://gw.example.com/api/users.list", nil), prefix))
+}
@@ -0,0 +1,116 @@
+package network
+
+// In this file: the typed errors that are returned to the library users.
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+var (
+	// ErrTokenExpired is returned if the token is invalid, revoked or has
+	// expired, or the account has been deactivated.
+	ErrTokenExpired = errors.New("token expired or revoked")
+	// ErrNotInChannel is returned if the user is not a member of the
+	// conversation.  It is always wrapped in ErrNoAccess.
+	ErrNotInChannel = errors.New("not in channel")
+)
+
+// ErrRateLimited is returned if the API kept rate limiting the requests, and
+// the number of retry attempts was exceeded.  RetryAfter is the last wait
+// time that was requested by the API.
+type ErrRateLimited struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ErrRateLimited) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s: %s", e.RetryAfter, e.Err)
+}
+
+func (e *ErrRateLimited) Unwrap() error {
+	return e.Err
+}
+
+// ErrNoAccess is returned if the conversation does not exist, or the
+// user does not have access to it.  Channel is the ID of the conversation,
+// it is empty if the request was not made for a specific conversation.
+type ErrNoAccess struct {
+	Channel string
+	Err     error
+}
+
+func (e *ErrNoAccess) Error() string {
+	if e.Channel == "" {
+		return fmt.Sprintf("no access: %s", e.Err)
+	}
+	return fmt.Sprintf("no access to %s: %s", e.Channel, e.Err)
+}
+
+func (e *ErrNoAccess) Unwrap() error {
+	return e.Err
+}
+
+// kindError attaches the sentinel error kind to err, so that errors.Is
+// matches the kind, while the original error is still available to
+// errors.As.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// Classify converts the Slack API error err, returned for the channel, to
+// one of the typed errors.  Errors that do not match any of the types, and
+// the errors that are already classified, are returned unchanged.
+func Classify(channel string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var (
+		rl *ErrRateLimited
+		na *ErrNoAccess
+	)
+	if errors.As(err, &rl) || errors.As(err, &na) || errors.Is(err, ErrTokenExpired) {
+		return err
+	}
+
+	var (
+		ser slack.SlackErrorResponse
+		sce slack.StatusCodeError
+	)
+	switch {
+	case errors.As(err, &ser):
+		switch ser.Err {
+		case "invalid_auth", "not_authed", "token_expired", "token_revoked", "account_inactive":
+			return &kindError{kind: ErrTokenExpired, err: err}
+		case "not_in_channel":
+			return &ErrNoAccess{Channel: channel, Err: &kindError{kind: ErrNotInChannel, err: err}}
+		case "channel_not_found", "access_denied", "missing_scope", "thread_not_found", "not_allowed_token_type":
+			return &ErrNoAccess{Channel: channel, Err: err}
+		}
+	case errors.As(err, &sce):
+		switch sce.Code {
+		case http.StatusUnauthorized:
+			return &kindError{kind: ErrTokenExpired, err: err}
+		case http.StatusForbidden:
+			return &ErrNoAccess{Channel: channel, Err: err}
+		}
+	}
+	return err
+}
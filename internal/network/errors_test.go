@@ -0,0 +1,71 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantExpired bool
+		wantNoAcc   bool
+		wantNotIn   bool
+	}{
+		{"nil", nil, false, false, false},
+		{"generic", errors.New("boo"), false, false, false},
+		{"invalid auth", slack.SlackErrorResponse{Err: "invalid_auth"}, true, false, false},
+		{"token revoked", slack.SlackErrorResponse{Err: "token_revoked"}, true, false, false},
+		{"not in channel", slack.SlackErrorResponse{Err: "not_in_channel"}, false, true, true},
+		{"channel not found", slack.SlackErrorResponse{Err: "channel_not_found"}, false, true, false},
+		{"wrapped", fmt.Errorf("callback error: %w", slack.SlackErrorResponse{Err: "missing_scope"}), false, true, false},
+		{"401", slack.StatusCodeError{Code: 401}, true, false, false},
+		{"403", slack.StatusCodeError{Code: 403}, false, true, false},
+		{"500", slack.StatusCodeError{Code: 500}, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Classify("C01", tt.err)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.wantExpired, errors.Is(err, ErrTokenExpired))
+			assert.Equal(t, tt.wantNotIn, errors.Is(err, ErrNotInChannel))
+			var na *ErrNoAccess
+			assert.Equal(t, tt.wantNoAcc, errors.As(err, &na))
+			if tt.wantNoAcc {
+				assert.Equal(t, "C01", na.Channel)
+			}
+			var ser slack.SlackErrorResponse
+			var sce slack.StatusCodeError
+			if tt.wantExpired || tt.wantNoAcc {
+				assert.True(t, errors.As(err, &ser) || errors.As(err, &sce), "original error is preserved")
+			} else {
+				assert.Equal(t, tt.err, err, "unclassified error is returned unchanged")
+			}
+		})
+	}
+	t.Run("already classified", func(t *testing.T) {
+		err := Classify("C01", slack.SlackErrorResponse{Err: "channel_not_found"})
+		assert.Same(t, err, Classify("C02", err))
+	})
+}
+
+func TestWithRetry_rateLimited(t *testing.T) {
+	err := WithRetry(context.Background(), rate.NewLimiter(1000, 1), 2, retryFn(100, time.Millisecond, nil))
+	require.Error(t, err)
+	var rl *ErrRateLimited
+	require.ErrorAs(t, err, &rl)
+	assert.Equal(t, time.Millisecond, rl.RetryAfter)
+	assert.ErrorIs(t, err, ErrRetryFailed)
+}
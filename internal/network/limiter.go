@@ -27,3 +27,47 @@ func NewLimiter(t Tier, burst uint, boost int) *rate.Limiter {
 	l := rate.NewLimiter(rate.Limit(callsPerSec), int(burst))
 	return l
 }
+
+// String returns the name of the tier.
+func (t Tier) String() string {
+	switch t {
+	case Tier2:
+		return "tier2"
+	case Tier3:
+		return "tier3"
+	case Tier4:
+		return "tier4"
+	}
+	return "special"
+}
+
+// methodTiers are the documented rate limit tiers of the Slack API methods,
+// that slackdump calls.
+var methodTiers = map[string]Tier{
+	"chat.scheduledMessages.list": Tier3,
+	"conversations.history":       Tier3,
+	"conversations.info":          Tier3,
+	"conversations.list":          Tier2,
+	"conversations.members":       Tier4,
+	"conversations.replies":       Tier3,
+	"emoji.list":                  Tier2,
+	"reactions.get":               Tier3,
+	"search.files":                Tier2,
+	"search.messages":             Tier2,
+	"stars.list":                  Tier3,
+	"team.info":                   Tier3,
+	"team.profile.get":            Tier3,
+	"usergroups.list":             Tier2,
+	"users.info":                  Tier4,
+	"users.list":                  Tier2,
+	"users.profile.get":           Tier4,
+}
+
+// MethodTier returns the rate limit tier of the Slack API method.  The
+// methods with the special rate limits, and unknown methods are NoTier.
+func MethodTier(method string) Tier {
+	if t, ok := methodTiers[method]; ok {
+		return t
+	}
+	return NoTier
+}
@@ -32,6 +32,8 @@ var (
 	// the current attempt.  This variable exists to reduce the test time.
 	waitFn    = cubicWait
 	netWaitFn = expWait
+	// rateLimitHook is called on each rate limit wait, see SetRateLimitHook.
+	rateLimitHook func(wait time.Duration)
 
 	mu sync.RWMutex
 )
@@ -43,8 +45,13 @@ var ErrRetryFailed = errors.New("callback was unable to complete without errors
 // WithRetry will run the callback function fn. If the function returns
 // slack.RateLimitedError, it will delay, and then call it again up to
 // maxAttempts times. It will return an error if it runs out of attempts.
+// If the attempts were exhausted while being rate limited, the returned error
+// is *ErrRateLimited, that wraps ErrRetryFailed.
 func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func() error) error {
-	var ok bool
+	var (
+		ok      bool
+		limited *slack.RateLimitedError // the last rate limit error
+	)
 	if maxAttempts == 0 {
 		maxAttempts = defNumAttempts
 	}
@@ -71,15 +78,22 @@ func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func(
 		)
 		switch {
 		case errors.As(cbErr, &rle):
+			limited = rle
 			tracelogf(ctx, "info", "got rate limited, sleeping %s", rle.RetryAfter)
-			time.Sleep(rle.RetryAfter)
+			onRateLimit(rle.RetryAfter)
+			if err := sleep(ctx, rle.RetryAfter); err != nil {
+				return err
+			}
 			continue
 		case errors.As(cbErr, &sce):
 			if isRecoverable(sce.Code) {
 				// possibly transient error
 				delay := waitFn(attempt)
 				tracelogf(ctx, "info", "got server error %d, sleeping %s", sce.Code, delay)
-				time.Sleep(delay)
+				if err := sleep(ctx, delay); err != nil {
+					return err
+				}
+				limited = nil
 				continue
 			}
 		case errors.As(cbErr, &ne):
@@ -87,7 +101,10 @@ func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func(
 				// possibly transient error
 				delay := netWaitFn(attempt)
 				tracelogf(ctx, "info", "got network error %s, sleeping %s", ne.Op, delay)
-				time.Sleep(delay)
+				if err := sleep(ctx, delay); err != nil {
+					return err
+				}
+				limited = nil
 				continue
 			}
 		}
@@ -95,11 +112,27 @@ func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func(
 		return fmt.Errorf("callback error: %w", cbErr)
 	}
 	if !ok {
+		if limited != nil {
+			return &ErrRateLimited{RetryAfter: limited.RetryAfter, Err: ErrRetryFailed}
+		}
 		return ErrRetryFailed
 	}
 	return nil
 }
 
+// sleep sleeps for the duration d, it returns the context error, if the
+// context is cancelled before d elapses.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
 // isRecoverable returns true if the status code is a recoverable error.
 func isRecoverable(statusCode int) bool {
 	return (statusCode >= http.StatusInternalServerError && statusCode <= 599 && statusCode != 501) || statusCode == 408
@@ -151,3 +184,21 @@ func SetMaxAllowedWaitTime(d time.Duration) {
 
 	maxAllowedWaitTime = d
 }
+
+// SetRateLimitHook sets the function that is called each time the request is
+// rate limited, before waiting.  Set it to nil to remove the hook.
+func SetRateLimitHook(fn func(wait time.Duration)) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	rateLimitHook = fn
+}
+
+func onRateLimit(wait time.Duration) {
+	mu.RLock()
+	fn := rateLimitHook
+	mu.RUnlock()
+	if fn != nil {
+		fn(wait)
+	}
+}
@@ -314,3 +314,33 @@ func Test_isRecoverable(t *testing.T) {
 		})
 	}
 }
+
+func TestSetRateLimitHook(t *testing.T) {
+	var waits []time.Duration
+	SetRateLimitHook(func(wait time.Duration) {
+		waits = append(waits, wait)
+	})
+	defer SetRateLimitHook(nil)
+
+	err := WithRetry(context.Background(), rate.NewLimiter(testRateLimit, 1), 3, retryFn(2, 1*time.Millisecond, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := []time.Duration{time.Millisecond, time.Millisecond}; !reflect.DeepEqual(waits, want) {
+		t.Errorf("hook waits = %v, want %v", waits, want)
+	}
+}
+
+func TestWithRetry_cancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := WithRetry(ctx, rate.NewLimiter(testRateLimit, 1), 3, retryFn(2, time.Hour, nil))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if dur := time.Since(start); dur > time.Second {
+		t.Errorf("the sleep was not interrupted, took %s", dur)
+	}
+}
@@ -0,0 +1,131 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime/trace"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// IsTransient returns true if the error looks like a temporary failure, i.e.
+// server errors, timeouts and network hiccups, or if WithRetry has ran out of
+// attempts.  Such operations have a good chance of succeeding if retried later.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	var (
+		sce slack.StatusCodeError
+		ne  net.Error
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, ErrRetryFailed), errors.Is(err, context.DeadlineExceeded):
+		return true
+	case errors.As(err, &sce):
+		return isRecoverable(sce.Code)
+	case errors.As(err, &ne):
+		return true
+	}
+	return false
+}
+
+// RetryQueue collects the items that failed with transient errors during the
+// main pass, so that they could be retried at the end of the run.  It is safe
+// for concurrent use.
+type RetryQueue struct {
+	mu    sync.Mutex
+	items map[string]error
+}
+
+// Add queues the item id, if err is transient, and returns true.  If the error
+// is not transient, the item is not queued and Add returns false.
+func (q *RetryQueue) Add(id string, err error) bool {
+	if !IsTransient(err) {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.items == nil {
+		q.items = make(map[string]error)
+	}
+	q.items[id] = err
+	return true
+}
+
+// Len returns the number of items in the queue.
+func (q *RetryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Items returns the sorted list of queued items.
+func (q *RetryQueue) Items() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ids := make([]string, 0, len(q.items))
+	for id := range q.items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Run retries the queued items up to maxAttempts times, calling fn for each
+// of them.  Before each attempt it waits for delay, doubling it on every pass.
+// Items that succeed or fail with a permanent error are removed from the
+// queue.  It returns the map of items that failed to complete along with their
+// last errors.
+func (q *RetryQueue) Run(ctx context.Context, maxAttempts int, delay time.Duration, fn func(id string) error) map[string]error {
+	failed := make(map[string]error)
+	for attempt := 0; attempt < maxAttempts && q.Len() > 0; attempt++ {
+		wait := delay << uint(attempt)
+		if wait > maxAllowedWaitTime {
+			wait = maxAllowedWaitTime
+		}
+		tracelogf(ctx, "info", "retry pass %d/%d: %d item(s), waiting %s", attempt+1, maxAttempts, q.Len(), wait)
+		select {
+		case <-ctx.Done():
+			return q.drain(failed, ctx.Err())
+		case <-time.After(wait):
+		}
+		for _, id := range q.Items() {
+			err := fn(id)
+			q.mu.Lock()
+			delete(q.items, id)
+			q.mu.Unlock()
+			if err == nil {
+				trace.Logf(ctx, "info", "retry of %s succeeded", id)
+				delete(failed, id)
+				continue
+			}
+			failed[id] = err
+			if !q.Add(id, err) {
+				trace.Logf(ctx, "error", "retry of %s failed permanently: %s", id, err)
+			}
+		}
+	}
+	return q.drain(failed, nil)
+}
+
+// drain moves all the items remaining in the queue into failed map.  If err
+// is not nil, it is used instead of the queued errors.
+func (q *RetryQueue) drain(failed map[string]error, err error) map[string]error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, qerr := range q.items {
+		if err != nil {
+			qerr = err
+		}
+		failed[id] = qerr
+	}
+	q.items = nil
+	return failed
+}
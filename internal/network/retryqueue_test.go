@@ -0,0 +1,93 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"generic", errors.New("boo boo"), false},
+		{"retry failed", ErrRetryFailed, true},
+		{"wrapped retry failed", fmt.Errorf("channel: %w", ErrRetryFailed), true},
+		{"500", slack.StatusCodeError{Code: 500}, true},
+		{"404", slack.StatusCodeError{Code: 404}, false},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("refused")}, true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"cancelled", context.Canceled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTransient(tt.err))
+		})
+	}
+}
+
+func TestRetryQueue_Run(t *testing.T) {
+	transient := slack.StatusCodeError{Code: 503}
+	t.Run("succeeds on the second pass", func(t *testing.T) {
+		var q RetryQueue
+		assert.True(t, q.Add("C1", transient))
+		assert.True(t, q.Add("C2", transient))
+		assert.False(t, q.Add("C3", errors.New("permanent")))
+		assert.Equal(t, []string{"C1", "C2"}, q.Items())
+
+		calls := map[string]int{}
+		failed := q.Run(context.Background(), 3, time.Millisecond, func(id string) error {
+			calls[id]++
+			if id == "C2" && calls[id] < 2 {
+				return transient
+			}
+			return nil
+		})
+		assert.Empty(t, failed)
+		assert.Equal(t, map[string]int{"C1": 1, "C2": 2}, calls)
+		assert.Equal(t, 0, q.Len())
+	})
+	t.Run("runs out of attempts", func(t *testing.T) {
+		var q RetryQueue
+		q.Add("C1", transient)
+		n := 0
+		failed := q.Run(context.Background(), 2, time.Millisecond, func(id string) error {
+			n++
+			return transient
+		})
+		assert.Equal(t, 2, n)
+		assert.Equal(t, map[string]error{"C1": transient}, failed)
+	})
+	t.Run("permanent error on retry", func(t *testing.T) {
+		var q RetryQueue
+		q.Add("C1", transient)
+		errPermanent := errors.New("channel_not_found")
+		n := 0
+		failed := q.Run(context.Background(), 5, time.Millisecond, func(id string) error {
+			n++
+			return errPermanent
+		})
+		assert.Equal(t, 1, n)
+		assert.Equal(t, map[string]error{"C1": errPermanent}, failed)
+	})
+	t.Run("cancelled context", func(t *testing.T) {
+		var q RetryQueue
+		q.Add("C1", transient)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		failed := q.Run(ctx, 5, time.Hour, func(id string) error {
+			t.Fatal("should not be called")
+			return nil
+		})
+		assert.Equal(t, map[string]error{"C1": context.Canceled}, failed)
+	})
+}
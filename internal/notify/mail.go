@@ -0,0 +1,265 @@
+package notify
+
+// In this file: emailing of the run report via SMTP.
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SMTP is the configuration of the run report email.
+type SMTP struct {
+	// Server is the SMTP server address in the host:port form.  Port 465
+	// uses implicit TLS, on other ports STARTTLS is used if the server
+	// supports it.
+	Server string
+	// From is the sender address.
+	From string
+	// To is the list of recipients.
+	To []string
+	// Username and Password are the SMTP credentials, if Username is empty,
+	// no authentication is performed.
+	Username string
+	Password string
+	// AttachLimit is the maximum size of the output file, that is attached to
+	// the report.  If zero, the output is never attached.
+	AttachLimit int64
+}
+
+// Validate validates the SMTP configuration.
+func (s SMTP) Validate() error {
+	if s.Server == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(s.Server); err != nil {
+		return fmt.Errorf("invalid SMTP server address %q, expected host:port: %w", s.Server, err)
+	}
+	if s.From == "" {
+		return errors.New("email requires the sender address")
+	}
+	if len(s.To) == 0 {
+		return errors.New("email requires at least one recipient")
+	}
+	for _, a := range append([]string{s.From}, s.To...) {
+		if _, err := mail.ParseAddress(a); err != nil {
+			return fmt.Errorf("invalid email address %q: %w", a, err)
+		}
+	}
+	return nil
+}
+
+// WithMail enables the emailing of the run report on completion.  output is
+// the file or directory of the run output, it is mentioned in the report,
+// and, if it is a file not larger than the AttachLimit, attached to it.
+func WithMail(cfg SMTP, output string) Option {
+	return func(n *Notifier) {
+		if cfg.Server == "" {
+			return
+		}
+		n.mail = &mailer{cfg: cfg, output: output}
+	}
+}
+
+// mailer sends the run reports.
+type mailer struct {
+	cfg    SMTP
+	output string
+}
+
+// send emails the report for the event ev.
+func (m *mailer) send(ev Event) error {
+	msg, err := m.message(ev)
+	if err != nil {
+		return err
+	}
+	return m.deliver(msg)
+}
+
+// message returns the MIME message of the report.
+func (m *mailer) message(ev Event) ([]byte, error) {
+	var body strings.Builder
+	body.WriteString(eventText(ev))
+	body.WriteString("\n")
+
+	attach := ""
+	if m.output != "" {
+		fi, err := os.Stat(m.output)
+		switch {
+		case err != nil:
+			fmt.Fprintf(&body, "\noutput: %s (%s)\n", m.output, err)
+		case fi.IsDir():
+			fmt.Fprintf(&body, "\noutput: %s (directory)\n", m.output)
+		case m.cfg.AttachLimit > 0 && fi.Size() <= m.cfg.AttachLimit:
+			fmt.Fprintf(&body, "\noutput: %s (%s, attached)\n", m.output, humanSize(fi.Size()))
+			attach = m.output
+		default:
+			fmt.Fprintf(&body, "\noutput: %s (%s)\n", m.output, humanSize(fi.Size()))
+		}
+	}
+
+	var buf bytes.Buffer
+	hdr := []string{
+		"From: " + m.cfg.From,
+		"To: " + strings.Join(m.cfg.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", fmt.Sprintf("slackdump %s: %s", ev.Mode, ev.Type)),
+		"Date: " + ev.Time.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+	}
+	for _, h := range hdr {
+		buf.WriteString(h + "\r\n")
+	}
+	if attach == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		buf.WriteString(crlf(body.String()))
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mw.Boundary())
+	pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := pw.Write([]byte(crlf(body.String()))); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(attach)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(attach)
+	pw, err = mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType("application/octet-stream", map[string]string{"name": name})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBase64(pw, data); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deliver delivers the message to the SMTP server.
+func (m *mailer) deliver(msg []byte) error {
+	host, port, err := net.SplitHostPort(m.cfg.Server)
+	if err != nil {
+		return err
+	}
+	d := net.Dialer{Timeout: defTimeout}
+	var conn net.Conn
+	if port == "465" {
+		conn, err = tls.DialWithDialer(&d, "tcp", m.cfg.Server, &tls.Config{ServerName: host})
+	} else {
+		conn, err = d.Dial("tcp", m.cfg.Server)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(defTimeout))
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if _, isTLS := conn.(*tls.Conn); !isTLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+				return err
+			}
+		}
+	}
+	if m.cfg.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("server doesn't support authentication")
+		}
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(envelope(m.cfg.From)); err != nil {
+		return err
+	}
+	for _, rcpt := range m.cfg.To {
+		if err := c.Rcpt(envelope(rcpt)); err != nil {
+			return fmt.Errorf("recipient %s: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// envelope returns the bare address of "Name <address>" for the SMTP
+// envelope.
+func envelope(s string) string {
+	a, err := mail.ParseAddress(s)
+	if err != nil {
+		return s
+	}
+	return a.Address
+}
+
+// writeBase64 writes the base64 encoded data, split into 76 character lines.
+func writeBase64(w io.Writer, data []byte) error {
+	const lineLen = 76
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 0 {
+		n := lineLen
+		if len(enc) < n {
+			n = len(enc)
+		}
+		if _, err := w.Write([]byte(enc[:n] + "\r\n")); err != nil {
+			return err
+		}
+		enc = enc[n:]
+	}
+	return nil
+}
+
+// crlf converts the line endings to CRLF.
+func crlf(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
+
+// humanSize returns the size in human readable form.
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// fakeSMTP is the minimal SMTP server, that accepts one message without
+// authentication.
+type fakeSMTP struct {
+	addr  string
+	rcpts []string
+	msgC  chan string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	s := &fakeSMTP{addr: l.Addr().String(), msgC: make(chan string, 1)}
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s.serve(conn)
+	}()
+	return s
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.rcpts = append(s.rcpts, strings.TrimSpace(line)[len("RCPT TO:"):])
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var msg strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				msg.WriteString(l)
+			}
+			s.msgC <- msg.String()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTP_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       SMTP
+		wantErr bool
+	}{
+		{"disabled", SMTP{}, false},
+		{"ok", SMTP{Server: "smtp.example.com:587", From: "Slackdump <slackdump@example.com>", To: []string{"hr@example.com"}}, false},
+		{"no port", SMTP{Server: "smtp.example.com", From: "slackdump@example.com", To: []string{"hr@example.com"}}, true},
+		{"no sender", SMTP{Server: "smtp.example.com:25", To: []string{"hr@example.com"}}, true},
+		{"no recipients", SMTP{Server: "smtp.example.com:25", From: "slackdump@example.com"}, true},
+		{"invalid recipient", SMTP{Server: "smtp.example.com:25", From: "slackdump@example.com", To: []string{"hr"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.s.Validate()
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestNotifier_Done_mail(t *testing.T) {
+	t.Run("report with attachment", func(t *testing.T) {
+		srv := newFakeSMTP(t)
+		output := filepath.Join(t.TempDir(), "export.zip")
+		require.NoError(t, os.WriteFile(output, []byte("zip data"), 0644))
+
+		n := New("export", nil, WithLogger(logger.Silent), WithMail(SMTP{
+			Server:      srv.addr,
+			From:        "Slackdump <slackdump@example.com>",
+			To:          []string{"hr@example.com", "Legal <legal@example.com>"},
+			AttachLimit: 1024,
+		}, output))
+		n.Done(errors.New("failed to process 1 conversation(s): C01"), []string{"C01"})
+
+		raw := <-srv.msgC
+		assert.Equal(t, []string{"<hr@example.com>", "<legal@example.com>"}, srv.rcpts)
+		msg, err := mail.ReadMessage(strings.NewReader(raw))
+		require.NoError(t, err)
+		assert.Equal(t, "slackdump export: partial_failure", msg.Header.Get("Subject"))
+
+		mt, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		require.Equal(t, "multipart/mixed", mt)
+		mr := multipart.NewReader(msg.Body, params["boundary"])
+		text, err := mr.NextPart()
+		require.NoError(t, err)
+		body, _ := io.ReadAll(text)
+		assert.Contains(t, string(body), "slackdump export completed, but 1 conversation(s) failed")
+		assert.Contains(t, string(body), "failed: C01")
+		assert.Contains(t, string(body), "export.zip (8 B, attached)")
+
+		att, err := mr.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "export.zip", att.FileName())
+		assert.Equal(t, "base64", att.Header.Get("Content-Transfer-Encoding"))
+		data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, att))
+		require.NoError(t, err)
+		assert.Equal(t, "zip data", string(data))
+	})
+	t.Run("too large to attach", func(t *testing.T) {
+		srv := newFakeSMTP(t)
+		output := filepath.Join(t.TempDir(), "export.zip")
+		require.NoError(t, os.WriteFile(output, make([]byte, 2048), 0644))
+
+		n := New("export", nil, WithLogger(logger.Silent), WithMail(SMTP{
+			Server:      srv.addr,
+			From:        "slackdump@example.com",
+			To:          []string{"hr@example.com"},
+			AttachLimit: 1024,
+		}, output))
+		n.Done(nil, nil)
+
+		msg, err := mail.ReadMessage(strings.NewReader(<-srv.msgC))
+		require.NoError(t, err)
+		assert.Equal(t, "slackdump export: complete", msg.Header.Get("Subject"))
+		assert.Contains(t, msg.Header.Get("Content-Type"), "text/plain")
+		body, _ := io.ReadAll(msg.Body)
+		assert.Contains(t, string(body), "export.zip (2.0 KiB)\r\n")
+	})
+}
+
+func Test_humanSize(t *testing.T) {
+	assert.Equal(t, "512 B", humanSize(512))
+	assert.Equal(t, "1.5 KiB", humanSize(1536))
+	assert.Equal(t, "10.0 MiB", humanSize(10<<20))
+}
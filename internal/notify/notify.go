@@ -0,0 +1,446 @@
+// Package notify implements the webhook notifications on the run lifecycle
+// events, so that the failure of the unattended run doesn't go unnoticed.
+//
+// Two payload formats are supported: the generic JSON, where the event is
+// posted as is, and the Slack incoming webhook, where the event is posted as
+// a text message.  Payloads can be customised with text/template.
+//
+// Additionally, the run report can be emailed via SMTP on completion, see
+// SMTP.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/diskspace"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// EventType is the type of the lifecycle event.
+type EventType string
+
+const (
+	// EventStart is sent when the run starts.
+	EventStart EventType = "start"
+	// EventComplete is sent when the run completes successfully.
+	EventComplete EventType = "complete"
+	// EventPartialFailure is sent when the run completes, but some of the
+	// conversations could not be saved.
+	EventPartialFailure EventType = "partial_failure"
+	// EventFailure is sent when the run fails.
+	EventFailure EventType = "failure"
+	// EventRateLimit is sent when the run is stalled by Slack rate limits.
+	EventRateLimit EventType = "rate_limit"
+	// EventDiskLow is sent when the run is paused, because the disk is
+	// almost full.
+	EventDiskLow EventType = "disk_low"
+)
+
+// EventTypes is the list of all the event types.
+var EventTypes = []EventType{EventStart, EventComplete, EventPartialFailure, EventFailure, EventRateLimit, EventDiskLow}
+
+const (
+	// defTimeout is the default timeout of a webhook request.
+	defTimeout = 30 * time.Second
+	// defStallThreshold is the default rate limit wait time, after which the
+	// run is considered stalled.
+	defStallThreshold = 1 * time.Minute
+	// stallInterval is the minimum interval between the rate limit
+	// notifications.
+	stallInterval = 15 * time.Minute
+)
+
+// Event is the lifecycle event.  It is the generic JSON payload and the data
+// of the payload template.
+type Event struct {
+	Type     EventType     `json:"event"`
+	Time     time.Time     `json:"time"`
+	Mode     string        `json:"mode"`               // dump, export or emoji
+	Message  string        `json:"message"`            // human readable description
+	Duration time.Duration `json:"-"`                  // time since start of the run
+	Failed   []string      `json:"failed,omitempty"`   // IDs of the failed conversations
+	Error    string        `json:"error,omitempty"`    // error message for the failure
+	Wait     time.Duration `json:"-"`                  // rate limit wait time
+	Elapsed  string        `json:"duration,omitempty"` // Duration formatted for JSON
+	Report   string        `json:"report,omitempty"`   // details of the completed run, i.e. the API usage
+}
+
+// Format is the webhook payload format.
+type Format int
+
+const (
+	// FormatJSON is the generic JSON payload.
+	FormatJSON Format = iota
+	// FormatSlack is the Slack incoming webhook payload.
+	FormatSlack
+)
+
+// Hook is the webhook.
+type Hook struct {
+	URL    string
+	Format Format
+}
+
+// ParseHook parses the webhook definition, which is the URL optionally
+// prefixed with the format: "json=URL" or "slack=URL".  If the format is
+// omitted, the Slack format is used for the Slack incoming webhook URLs
+// (hooks.slack.com), and JSON for everything else.
+func ParseHook(s string) (Hook, error) {
+	var h Hook
+	rawURL := s
+	if prefix, rest, found := strings.Cut(s, "="); found && !strings.Contains(prefix, ":") {
+		switch strings.ToLower(prefix) {
+		case "json":
+			h.Format = FormatJSON
+		case "slack":
+			h.Format = FormatSlack
+		default:
+			return h, fmt.Errorf("unknown webhook format: %q", prefix)
+		}
+		rawURL = rest
+	} else if u, err := url.Parse(s); err == nil && u.Host == "hooks.slack.com" {
+		h.Format = FormatSlack
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return h, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return h, fmt.Errorf("invalid webhook URL %q: must be http or https", rawURL)
+	}
+	h.URL = rawURL
+	return h, nil
+}
+
+// ParseEvents parses the comma separated list of event types.
+func ParseEvents(s string) ([]EventType, error) {
+	var events []EventType
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		et, ok := lookupEvent(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown event: %q, must be one of %v", name, EventTypes)
+		}
+		events = append(events, et)
+	}
+	return events, nil
+}
+
+func lookupEvent(name string) (EventType, bool) {
+	for _, et := range EventTypes {
+		if string(et) == strings.ToLower(name) {
+			return et, true
+		}
+	}
+	return "", false
+}
+
+// Notifier sends the events to the webhooks, and emails the run report.  The
+// zero Notifier, or the Notifier without hooks and email, does nothing.
+type Notifier struct {
+	hooks  []Hook
+	events map[EventType]bool // nil - all events
+	tmpl   *template.Template
+	client *http.Client
+	mail   *mailer
+	lg     logger.Interface
+	mode   string
+	start  time.Time
+
+	stallThreshold time.Duration
+	mu             sync.Mutex
+	lastStall      time.Time
+	report         string
+}
+
+// Option is the Notifier option.
+type Option func(*Notifier)
+
+// WithEvents limits the events that are sent.  If no events are given, all
+// events are sent.
+func WithEvents(events ...EventType) Option {
+	return func(n *Notifier) {
+		if len(events) == 0 {
+			n.events = nil
+			return
+		}
+		n.events = make(map[EventType]bool, len(events))
+		for _, et := range events {
+			n.events[et] = true
+		}
+	}
+}
+
+// WithTemplate sets the payload template.  For JSON webhooks, it renders the
+// request body, for Slack webhooks it renders the message text.
+func WithTemplate(tmpl *template.Template) Option {
+	return func(n *Notifier) {
+		n.tmpl = tmpl
+	}
+}
+
+// WithStallThreshold sets the rate limit wait time, after which the run is
+// considered stalled.
+func WithStallThreshold(d time.Duration) Option {
+	return func(n *Notifier) {
+		if d > 0 {
+			n.stallThreshold = d
+		}
+	}
+}
+
+// WithClient sets the HTTP client.
+func WithClient(cl *http.Client) Option {
+	return func(n *Notifier) {
+		if cl != nil {
+			n.client = cl
+		}
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(lg logger.Interface) Option {
+	return func(n *Notifier) {
+		if lg != nil {
+			n.lg = lg
+		}
+	}
+}
+
+// New creates the Notifier for the run in the given mode.
+func New(mode string, hooks []Hook, opts ...Option) *Notifier {
+	n := &Notifier{
+		hooks:          hooks,
+		client:         &http.Client{Timeout: defTimeout},
+		lg:             logger.Default,
+		mode:           mode,
+		start:          time.Now(),
+		stallThreshold: defStallThreshold,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// ParseTemplate parses the payload template from the file.
+func ParseTemplate(filename string) (*template.Template, error) {
+	tmpl, err := template.ParseFiles(filename)
+	if err != nil {
+		return nil, fmt.Errorf("webhook template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Start sends the start event.
+func (n *Notifier) Start() {
+	n.Send(Event{Type: EventStart, Message: "started"})
+}
+
+// SetReport sets the details of the run, that are sent with the completion
+// event, see Done.
+func (n *Notifier) SetReport(report string) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.report = report
+}
+
+// Done sends the completion event for the run that returned err.  failed is
+// the list of conversations that could not be saved.  If the email is
+// configured, the run report is emailed as well.
+func (n *Notifier) Done(err error, failed []string) {
+	var ev Event
+	switch {
+	case len(failed) > 0:
+		// the dump completes without the error, unless requested.
+		ev = Event{
+			Type:    EventPartialFailure,
+			Message: fmt.Sprintf("completed, but %d conversation(s) failed", len(failed)),
+			Failed:  failed,
+		}
+		if err != nil {
+			ev.Error = err.Error()
+		}
+	case err == nil:
+		ev = Event{Type: EventComplete, Message: "completed"}
+	default:
+		ev = Event{Type: EventFailure, Message: "failed", Error: err.Error()}
+	}
+	if n != nil {
+		n.mu.Lock()
+		ev.Report = n.report
+		n.mu.Unlock()
+	}
+	n.Send(ev)
+	if n != nil && n.mail != nil {
+		if err := n.mail.send(n.fill(ev)); err != nil {
+			n.lg.Printf("email: %s", err)
+		}
+	}
+}
+
+// RateLimited should be called on each rate limit wait.  If the wait is
+// longer than the stall threshold, the rate limit event is sent, but not more
+// often than once in 15 minutes.
+func (n *Notifier) RateLimited(wait time.Duration) {
+	if n == nil || wait < n.stallThreshold {
+		return
+	}
+	n.mu.Lock()
+	if !n.lastStall.IsZero() && time.Since(n.lastStall) < stallInterval {
+		n.mu.Unlock()
+		return
+	}
+	n.lastStall = time.Now()
+	n.mu.Unlock()
+	n.Send(Event{Type: EventRateLimit, Message: fmt.Sprintf("rate limited, waiting %s", wait), Wait: wait})
+}
+
+// DiskLow should be called, when the run is paused, because the volume of
+// path has only free bytes, less than min, free.  It sends the disk_low
+// event.
+func (n *Notifier) DiskLow(path string, free, min uint64) {
+	if n == nil {
+		return
+	}
+	n.Send(Event{Type: EventDiskLow, Message: fmt.Sprintf("paused, the disk is almost full: %s free on %s, the minimum is %s", diskspace.Size(free), path, diskspace.Size(min))})
+}
+
+// Send sends the event to all the hooks.  Delivery errors are logged, and are
+// not returned, so that the notification failure doesn't fail the run.
+func (n *Notifier) Send(ev Event) {
+	if n == nil || len(n.hooks) == 0 || (n.events != nil && !n.events[ev.Type]) {
+		return
+	}
+	ev = n.fill(ev)
+	for _, h := range n.hooks {
+		if err := n.post(h, ev); err != nil {
+			n.lg.Printf("webhook %s: %s", redact(h.URL), err)
+		}
+	}
+}
+
+// fill fills the time, mode and duration of the event.
+func (n *Notifier) fill(ev Event) Event {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	ev.Mode = n.mode
+	ev.Duration = ev.Time.Sub(n.start).Round(time.Second)
+	if ev.Type != EventStart {
+		ev.Elapsed = ev.Duration.String()
+	}
+	return ev
+}
+
+func (n *Notifier) post(h Hook, ev Event) error {
+	body, err := n.payload(h.Format, ev)
+	if err != nil {
+		return err
+	}
+	// context is not inherited from the run, as the final notifications are
+	// sent when it might be already cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), defTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+// payload returns the request body for the event ev in the format f.
+func (n *Notifier) payload(f Format, ev Event) ([]byte, error) {
+	var text string
+	if n.tmpl != nil {
+		var buf bytes.Buffer
+		if err := n.tmpl.Execute(&buf, ev); err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		if f == FormatJSON {
+			return buf.Bytes(), nil
+		}
+		text = buf.String()
+	}
+	switch f {
+	case FormatJSON:
+		return json.Marshal(ev)
+	case FormatSlack:
+		if text == "" {
+			text = slackText(ev)
+		}
+		return json.Marshal(struct {
+			Text string `json:"text"`
+		}{text})
+	}
+	return nil, errors.New("unknown webhook format")
+}
+
+// slackText returns the default Slack message text.
+func slackText(ev Event) string {
+	icons := map[EventType]string{
+		EventStart:          ":arrow_forward:",
+		EventComplete:       ":white_check_mark:",
+		EventPartialFailure: ":warning:",
+		EventFailure:        ":x:",
+		EventRateLimit:      ":hourglass:",
+		EventDiskLow:        ":floppy_disk:",
+	}
+	return icons[ev.Type] + " " + eventText(ev)
+}
+
+// eventText returns the plain text description of the event.
+func eventText(ev Event) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "slackdump %s %s", ev.Mode, ev.Message)
+	if ev.Elapsed != "" && ev.Type != EventRateLimit && ev.Type != EventDiskLow {
+		fmt.Fprintf(&buf, " (time taken: %s)", ev.Elapsed)
+	}
+	if len(ev.Failed) > 0 {
+		fmt.Fprintf(&buf, "\nfailed: %s", strings.Join(ev.Failed, " "))
+	}
+	if ev.Error != "" {
+		fmt.Fprintf(&buf, "\nerror: %s", ev.Error)
+	}
+	if ev.Report != "" {
+		fmt.Fprintf(&buf, "\n\n%s", ev.Report)
+	}
+	return buf.String()
+}
+
+// redact removes the path and the query from the URL, as webhook URLs usually
+// contain secrets.
+func redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return u.Scheme + "://" + u.Host
+}
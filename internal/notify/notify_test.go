@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func TestParseHook(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Hook
+		wantErr bool
+	}{
+		{"json", "https://example.com/hook?a=b", Hook{URL: "https://example.com/hook?a=b", Format: FormatJSON}, false},
+		{"slack detected", "https://hooks.slack.com/services/T/B/X", Hook{URL: "https://hooks.slack.com/services/T/B/X", Format: FormatSlack}, false},
+		{"explicit slack", "slack=https://chat.example.com/hook", Hook{URL: "https://chat.example.com/hook", Format: FormatSlack}, false},
+		{"explicit json", "JSON=https://hooks.slack.com/x", Hook{URL: "https://hooks.slack.com/x", Format: FormatJSON}, false},
+		{"unknown format", "xml=https://example.com", Hook{}, true},
+		{"not http", "ftp://example.com", Hook{}, true},
+		{"no host", "slack=/hook", Hook{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHook(tt.s)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	got, err := ParseEvents("start, FAILURE,,rate_limit")
+	require.NoError(t, err)
+	assert.Equal(t, []EventType{EventStart, EventFailure, EventRateLimit}, got)
+
+	got, err = ParseEvents("")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = ParseEvents("start,finish")
+	assert.Error(t, err)
+}
+
+// recorder is the test webhook server, that records the request bodies.
+type recorder struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	rec.mu.Lock()
+	rec.bodies = append(rec.bodies, string(data))
+	rec.mu.Unlock()
+}
+
+func testServer(t *testing.T) (*recorder, *httptest.Server) {
+	rec := new(recorder)
+	srv := httptest.NewServer(rec)
+	t.Cleanup(srv.Close)
+	return rec, srv
+}
+
+func TestNotifier_Done(t *testing.T) {
+	rec, srv := testServer(t)
+	n := New("dump", []Hook{{URL: srv.URL, Format: FormatJSON}, {URL: srv.URL + "/slack", Format: FormatSlack}}, WithLogger(logger.Silent))
+
+	n.SetReport("API calls: 42 in 1m0s")
+	n.Done(errors.New("boom"), []string{"C01", "C02"})
+	require.Len(t, rec.bodies, 2)
+
+	var ev Event
+	require.NoError(t, json.Unmarshal([]byte(rec.bodies[0]), &ev))
+	assert.Equal(t, EventPartialFailure, ev.Type)
+	assert.Equal(t, "dump", ev.Mode)
+	assert.Equal(t, []string{"C01", "C02"}, ev.Failed)
+	assert.Equal(t, "boom", ev.Error)
+	assert.Equal(t, "API calls: 42 in 1m0s", ev.Report)
+
+	var msg struct{ Text string }
+	require.NoError(t, json.Unmarshal([]byte(rec.bodies[1]), &msg))
+	assert.Contains(t, msg.Text, ":warning: slackdump dump completed, but 2 conversation(s) failed")
+	assert.Contains(t, msg.Text, "failed: C01 C02")
+	assert.Contains(t, msg.Text, "\n\nAPI calls: 42 in 1m0s")
+
+	t.Run("partial failure without the error", func(t *testing.T) {
+		rec, srv := testServer(t)
+		New("dump", []Hook{{URL: srv.URL}}, WithLogger(logger.Silent)).Done(nil, []string{"C01"})
+		require.Len(t, rec.bodies, 1)
+		var ev Event
+		require.NoError(t, json.Unmarshal([]byte(rec.bodies[0]), &ev))
+		assert.Equal(t, EventPartialFailure, ev.Type)
+		assert.Equal(t, []string{"C01"}, ev.Failed)
+		assert.Empty(t, ev.Error)
+	})
+}
+
+func TestNotifier_Send(t *testing.T) {
+	t.Run("events filter", func(t *testing.T) {
+		rec, srv := testServer(t)
+		n := New("export", []Hook{{URL: srv.URL}}, WithEvents(EventFailure), WithLogger(logger.Silent))
+		n.Start()
+		n.Done(nil, nil)
+		n.Done(errors.New("boom"), nil)
+		require.Len(t, rec.bodies, 1)
+		assert.Contains(t, rec.bodies[0], `"event":"failure"`)
+	})
+	t.Run("template", func(t *testing.T) {
+		rec, srv := testServer(t)
+		tmpl := template.Must(template.New("").Parse(`{{.Mode}} {{.Type}}{{range .Failed}} {{.}}{{end}}`))
+		n := New("dump", []Hook{{URL: srv.URL, Format: FormatSlack}, {URL: srv.URL, Format: FormatJSON}}, WithTemplate(tmpl), WithLogger(logger.Silent))
+		n.Done(errors.New("boom"), []string{"C01"})
+		require.Len(t, rec.bodies, 2)
+		assert.Equal(t, `{"text":"dump partial_failure C01"}`, rec.bodies[0])
+		assert.Equal(t, `dump partial_failure C01`, rec.bodies[1])
+	})
+	t.Run("no hooks", func(t *testing.T) {
+		var n *Notifier
+		n.Start() // must not panic
+		New("dump", nil).Done(nil, nil)
+	})
+}
+
+func TestNotifier_RateLimited(t *testing.T) {
+	rec, srv := testServer(t)
+	n := New("dump", []Hook{{URL: srv.URL}}, WithStallThreshold(time.Minute), WithLogger(logger.Silent))
+	n.RateLimited(time.Second)
+	assert.Empty(t, rec.bodies, "short waits are not stalls")
+	n.RateLimited(2 * time.Minute)
+	n.RateLimited(2 * time.Minute)
+	require.Len(t, rec.bodies, 1, "stall notifications must be throttled")
+	assert.Contains(t, rec.bodies[0], `"event":"rate_limit"`)
+}
+
+func TestNotifier_DiskLow(t *testing.T) {
+	rec, srv := testServer(t)
+	n := New("export", []Hook{{URL: srv.URL}}, WithLogger(logger.Silent))
+	n.DiskLow("/archive", 10<<20, 1<<30)
+	require.Len(t, rec.bodies, 1)
+	assert.Contains(t, rec.bodies[0], `"event":"disk_low"`)
+	assert.Contains(t, rec.bodies[0], "10.0M free on /archive")
+}
+
+func Test_redact(t *testing.T) {
+	assert.Equal(t, "https://hooks.slack.com", redact("https://hooks.slack.com/services/T/B/secret"))
+}
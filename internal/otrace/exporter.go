@@ -0,0 +1,298 @@
+package otrace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+const (
+	// tracesPath is the OTLP/HTTP path of the traces.
+	tracesPath = "/v1/traces"
+	// serviceName is the service.name resource attribute.
+	serviceName = "slackdump"
+
+	// batchSize is the number of spans, that triggers sending.
+	batchSize = 512
+	// maxQueue is the maximum number of pending spans, the spans over it are
+	// dropped, if the collector can't keep up.
+	maxQueue = 8 * batchSize
+	// flushInterval is the interval, at which the pending spans are sent.
+	flushInterval = 5 * time.Second
+	// sendTimeout is the timeout of one batch request.
+	sendTimeout = 30 * time.Second
+)
+
+// exporter sends the ended spans to the collector in batches.
+type exporter struct {
+	url    string
+	client *http.Client
+	lg     logger.Interface
+
+	mu      sync.Mutex
+	queue   []*Span
+	dropped int
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Init enables tracing, the spans are sent to the OTLP/HTTP collector
+// endpoint, i.e. http://localhost:4318.  The traces path "/v1/traces" is
+// appended to the endpoint, unless it is already there.  The returned
+// function sends the pending spans and disables tracing, it must be called
+// before the program exits.  Sending errors are logged, as the traces are not
+// essential for the run.
+func Init(endpoint string, lg logger.Interface) (shutdown func(context.Context) error, err error) {
+	u, err := tracesURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if lg == nil {
+		lg = logger.Default
+	}
+	e := &exporter{
+		url:    u,
+		client: &http.Client{Timeout: sendTimeout},
+		lg:     lg,
+		kick:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	mu.Lock()
+	exp = e
+	mu.Unlock()
+	go e.loop()
+
+	return func(ctx context.Context) error {
+		mu.Lock()
+		if exp == e {
+			exp = nil
+		}
+		mu.Unlock()
+		close(e.stop)
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return e.flush(ctx)
+	}, nil
+}
+
+// tracesURL returns the traces URL for the collector endpoint.
+func tracesURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid OTLP endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("invalid OTLP endpoint %q: must be http(s)://host:port", endpoint)
+	}
+	if !strings.HasSuffix(u.Path, tracesPath) {
+		u.Path = strings.TrimSuffix(u.Path, "/") + tracesPath
+	}
+	return u.String(), nil
+}
+
+// add queues the ended span.
+func (e *exporter) add(s *Span) {
+	e.mu.Lock()
+	if len(e.queue) >= maxQueue {
+		e.dropped++
+		e.mu.Unlock()
+		return
+	}
+	e.queue = append(e.queue, s)
+	n := len(e.queue)
+	e.mu.Unlock()
+	if n >= batchSize {
+		select {
+		case e.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// loop sends the spans until stopped.
+func (e *exporter) loop() {
+	defer close(e.done)
+	t := time.NewTicker(flushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-t.C:
+		case <-e.kick:
+		}
+		if err := e.flush(context.Background()); err != nil {
+			e.lg.Printf("traces: %s", err)
+		}
+	}
+}
+
+// flush sends all pending spans.
+func (e *exporter) flush(ctx context.Context) error {
+	e.mu.Lock()
+	spans := e.queue
+	dropped := e.dropped
+	e.queue, e.dropped = nil, 0
+	e.mu.Unlock()
+	if dropped > 0 {
+		e.lg.Printf("traces: %d spans dropped, the collector is too slow", dropped)
+	}
+	for len(spans) > 0 {
+		n := batchSize
+		if n > len(spans) {
+			n = len(spans)
+		}
+		if err := e.send(ctx, spans[:n]); err != nil {
+			return err
+		}
+		spans = spans[n:]
+	}
+	return nil
+}
+
+// send sends one batch of spans.
+func (e *exporter) send(ctx context.Context, spans []*Span) error {
+	data, err := json.Marshal(encode(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// The OTLP JSON encoding of the traces, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttr `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []otlpAttr `json:"attributes,omitempty"`
+	Status            otlpStatus `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttr struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"` // int64 is a string in JSON
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+var errUnsupported = errors.New("unsupported attribute type")
+
+func encodeAttr(a Attr) (otlpAttr, error) {
+	oa := otlpAttr{Key: a.Key}
+	switch v := a.Value.(type) {
+	case string:
+		oa.Value.StringValue = &v
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		oa.Value.IntValue = &s
+	case bool:
+		oa.Value.BoolValue = &v
+	default:
+		return oa, errUnsupported
+	}
+	return oa, nil
+}
+
+func encodeAttrs(attrs []Attr) []otlpAttr {
+	var oas []otlpAttr
+	for _, a := range attrs {
+		oa, err := encodeAttr(a)
+		if err != nil {
+			continue
+		}
+		oas = append(oas, oa)
+	}
+	return oas
+}
+
+// encode returns the OTLP request for the spans.
+func encode(spans []*Span) otlpTraces {
+	oss := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		oss = append(oss, otlpSpan{
+			TraceID:           s.traceID,
+			SpanID:            s.spanID,
+			ParentSpanID:      s.parentID,
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        encodeAttrs(s.attrs),
+			Status:            otlpStatus{Code: s.status, Message: s.errMsg},
+		})
+		s.mu.Unlock()
+	}
+	return otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: encodeAttrs([]Attr{String("service.name", serviceName)})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: serviceName}, Spans: oss}},
+	}}}
+}
@@ -0,0 +1,155 @@
+// Package otrace implements the OpenTelemetry traces of the run.  The spans
+// of the Slack API calls and of the pipeline stages, i.e. the conversation
+// and thread fetching, file downloads and conversion, are sent to the
+// collector, such as Jaeger or Grafana Tempo, with the OTLP/HTTP protocol in
+// the JSON encoding, so that the performance of the large runs can be
+// analysed.
+//
+// Tracing is process wide, the same way as the metrics.  Until Init is
+// called, the spans are not recorded, and Start costs next to nothing.
+package otrace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Span kinds, as defined by OTLP.
+const (
+	kindInternal = 1
+	kindClient   = 3
+)
+
+// Status codes, as defined by OTLP.
+const (
+	statusOK    = 1
+	statusError = 2
+)
+
+var (
+	mu  sync.RWMutex
+	exp *exporter // current exporter, nil if tracing is disabled
+)
+
+// Attr is the span attribute.
+type Attr struct {
+	Key   string
+	Value any // string, int64, bool
+}
+
+// String returns the string attribute.
+func String(key, value string) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Int returns the integer attribute.
+func Int(key string, value int) Attr {
+	return Attr{Key: key, Value: int64(value)}
+}
+
+// Bool returns the boolean attribute.
+func Bool(key string, value bool) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Span is the timed operation.  The nil Span, returned when the tracing is
+// disabled, is valid, and does nothing.
+type Span struct {
+	exp *exporter
+
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	kind     int
+	start    time.Time
+
+	mu     sync.Mutex
+	attrs  []Attr
+	end    time.Time
+	status int
+	errMsg string
+}
+
+type spanKey struct{}
+
+// Start starts the span name, that is the child of the span in ctx, if any.
+// The returned context carries the new span.  The span must be ended with
+// End.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return start(ctx, name, kindInternal, attrs)
+}
+
+func start(ctx context.Context, name string, kind int, attrs []Attr) (context.Context, *Span) {
+	mu.RLock()
+	e := exp
+	mu.RUnlock()
+	if e == nil {
+		return ctx, nil
+	}
+	s := &Span{
+		exp:    e,
+		spanID: randomID(8),
+		name:   name,
+		kind:   kind,
+		start:  time.Now(),
+		attrs:  attrs,
+	}
+	if parent := FromContext(ctx); parent != nil {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		s.traceID = randomID(16)
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// FromContext returns the span from the context, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// SetAttr adds the attributes to the span.
+func (s *Span) SetAttr(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// End ends the span and queues it for sending.  If err is not nil, the span
+// status is set to error.  Calls after the first one are ignored.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = time.Now()
+	if err != nil {
+		s.status = statusError
+		s.errMsg = err.Error()
+	} else if s.status == 0 {
+		s.status = statusOK
+	}
+	s.mu.Unlock()
+	s.exp.add(s)
+}
+
+// randomID returns n random bytes in hex.
+func randomID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // this should never happen
+	}
+	return hex.EncodeToString(b)
+}
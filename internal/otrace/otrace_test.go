@@ -0,0 +1,160 @@
+package otrace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// collector is the fake OTLP/HTTP collector.
+type collector struct {
+	mu    sync.Mutex
+	spans []otlpSpan
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != tracesPath || r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	var req otlpTraces
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+}
+
+func (c *collector) byName() map[string]otlpSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := make(map[string]otlpSpan, len(c.spans))
+	for _, s := range c.spans {
+		m[s.Name] = s
+	}
+	return m
+}
+
+func TestInit(t *testing.T) {
+	var col collector
+	srv := httptest.NewServer(&col)
+	defer srv.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/conversations.replies" {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer api.Close()
+
+	shutdown, err := Init(srv.URL, logger.Silent)
+	require.NoError(t, err)
+
+	ctx, root := Start(context.Background(), "run", String("mode", "dump"))
+	cctx, conv := Start(ctx, "conversation.fetch", String("channel.id", "C01"))
+	cl := &http.Client{Transport: NewTransport(nil, "")}
+	for _, p := range []string{"/api/conversations.history", "/api/conversations.replies", "/files/F01"} {
+		req, err := http.NewRequestWithContext(cctx, http.MethodGet, api.URL+p, nil)
+		require.NoError(t, err)
+		resp, err := cl.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	conv.SetAttr(Int("messages", 42))
+	conv.End(errors.New("not_in_channel"))
+	root.End(nil)
+
+	require.NoError(t, shutdown(context.Background()))
+
+	_, span := Start(context.Background(), "after shutdown")
+	assert.Nil(t, span, "tracing must be disabled after shutdown")
+	span.End(nil) // must not panic
+
+	spans := col.byName()
+	require.Len(t, spans, 4)
+	run, fetch := spans["run"], spans["conversation.fetch"]
+	hist, repl := spans["slack conversations.history"], spans["slack conversations.replies"]
+
+	assert.Empty(t, run.ParentSpanID)
+	assert.Len(t, run.TraceID, 32)
+	assert.Len(t, run.SpanID, 16)
+	for _, s := range []otlpSpan{fetch, hist, repl} {
+		assert.Equal(t, run.TraceID, s.TraceID, s.Name)
+	}
+	assert.Equal(t, run.SpanID, fetch.ParentSpanID)
+	assert.Equal(t, fetch.SpanID, hist.ParentSpanID)
+	assert.Equal(t, fetch.SpanID, repl.ParentSpanID)
+
+	assert.Equal(t, kindInternal, fetch.Kind)
+	assert.Equal(t, kindClient, hist.Kind)
+	assert.Equal(t, otlpStatus{Code: statusOK}, run.Status)
+	assert.Equal(t, otlpStatus{Code: statusError, Message: "not_in_channel"}, fetch.Status)
+	assert.Equal(t, statusOK, hist.Status.Code)
+	assert.Equal(t, statusError, repl.Status.Code)
+
+	attrs := func(s otlpSpan) map[string]string {
+		m := make(map[string]string)
+		for _, a := range s.Attributes {
+			switch {
+			case a.Value.StringValue != nil:
+				m[a.Key] = *a.Value.StringValue
+			case a.Value.IntValue != nil:
+				m[a.Key] = *a.Value.IntValue
+			case a.Value.BoolValue != nil && *a.Value.BoolValue:
+				m[a.Key] = "true"
+			}
+		}
+		return m
+	}
+	assert.Equal(t, map[string]string{"channel.id": "C01", "messages": "42"}, attrs(fetch))
+	assert.Equal(t, "conversations.replies", attrs(repl)["slack.method"])
+	assert.Equal(t, "429", attrs(repl)["http.status_code"])
+	assert.Equal(t, "true", attrs(repl)["slack.rate_limited"])
+}
+
+func TestStart_disabled(t *testing.T) {
+	ctx := context.Background()
+	got, span := Start(ctx, "noop")
+	assert.Nil(t, span)
+	assert.Equal(t, ctx, got)
+	span.SetAttr(String("a", "b"))
+	span.End(errors.New("ignored"))
+}
+
+func Test_tracesURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{"http://localhost:4318", "http://localhost:4318/v1/traces", false},
+		{"http://localhost:4318/", "http://localhost:4318/v1/traces", false},
+		{"https://tempo.example.com/otlp", "https://tempo.example.com/otlp/v1/traces", false},
+		{"http://localhost:4318/v1/traces", "http://localhost:4318/v1/traces", false},
+		{"localhost:4318", "", true},
+		{"grpc://localhost:4317", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			got, err := tracesURL(tt.endpoint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tracesURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
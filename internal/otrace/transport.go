@@ -0,0 +1,54 @@
+package otrace
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+// transport records the Slack API calls as the client spans.
+type transport struct {
+	rt     http.RoundTripper
+	prefix string // API path prefix, see network.APIPrefix
+}
+
+// NewTransport wraps rt, so that each Slack API call is recorded as the span
+// "slack <method>", the child of the span in the request context.  If rt is
+// nil, http.DefaultTransport is used.  The apiPrefix is the URL path prefix of the API methods, see
+// network.APIPrefix.
+func NewTransport(rt http.RoundTripper, apiPrefix string) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{rt: rt, prefix: apiPrefix}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := network.APIMethod(req, t.prefix)
+	if method == "" {
+		return t.rt.RoundTrip(req)
+	}
+	_, span := start(req.Context(), "slack "+method, kindClient, []Attr{
+		String("slack.method", method),
+		String("http.method", req.Method),
+	})
+	if span == nil {
+		return t.rt.RoundTrip(req)
+	}
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		span.End(err)
+		return nil, err
+	}
+	span.SetAttr(Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		span.SetAttr(Bool("slack.rate_limited", true), String("slack.retry_after", resp.Header.Get("Retry-After")))
+	}
+	if resp.StatusCode >= 400 {
+		span.End(fmt.Errorf("HTTP %s", resp.Status))
+	} else {
+		span.End(nil)
+	}
+	return resp, nil
+}
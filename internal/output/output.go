@@ -0,0 +1,189 @@
+// Package output negotiates the output location of the run, if it already
+// exists, i.e. it's the directory or the ZIP file of the previous run.  The
+// Strategy tells, what to do with it: fail, resume the interrupted run, merge
+// into it, write to the new location with the timestamp suffix, or overwrite
+// it.
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Strategy is the strategy of resolving the conflict with the existing
+// output.
+type Strategy uint8
+
+const (
+	// Ask asks the user, see Strategies for the choices.  Without the
+	// terminal, it merges into the existing directory, and replaces the
+	// existing file, the same as before the strategies were introduced.
+	Ask Strategy = iota
+	// Fail fails the run.
+	Fail
+	// Resume continues the interrupted run from the pending conversations,
+	// keeping the saved files.
+	Resume
+	// Merge writes into the existing directory, replacing the files with
+	// the same names.
+	Merge
+	// Suffix writes to the new location, with the timestamp suffix, i.e.
+	// "export-20240131-020000.zip".
+	Suffix
+	// Overwrite removes the existing output before the run.
+	Overwrite
+)
+
+// ErrExists is returned by Resolve with the Fail strategy.
+var ErrExists = errors.New("output already exists")
+
+var strategyNames = map[Strategy]string{
+	Ask:       "ask",
+	Fail:      "fail",
+	Resume:    "resume",
+	Merge:     "merge",
+	Suffix:    "suffix",
+	Overwrite: "overwrite",
+}
+
+// Strategies are the strategies, that the user can choose from, with their
+// descriptions, in the order of the prompt.
+var Strategies = []struct {
+	Strategy    Strategy
+	Description string
+}{
+	{Resume, "continue the interrupted run, keeping the saved files"},
+	{Merge, "write into the existing directory, replacing the files with the same names"},
+	{Suffix, "write to the new directory or file with the timestamp suffix"},
+	{Overwrite, "delete the existing output, and start from scratch"},
+	{Fail, "stop, leaving the existing output as is"},
+}
+
+func (s Strategy) String() string {
+	if name, ok := strategyNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Strategy(%d)", uint8(s))
+}
+
+// Set sets the strategy from its name, it is the flag.Value interface.
+func (s *Strategy) Set(v string) error {
+	p, err := Parse(v)
+	if err != nil {
+		return err
+	}
+	*s = p
+	return nil
+}
+
+// Parse returns the strategy with the name v.
+func Parse(v string) (Strategy, error) {
+	for s, name := range strategyNames {
+		if strings.EqualFold(v, name) {
+			return s, nil
+		}
+	}
+	return Ask, fmt.Errorf("unknown output strategy: %q, use one of: ask, fail, resume, merge, suffix or overwrite", v)
+}
+
+// Exists returns true, if the location is the existing file, or the
+// non-empty directory.
+func Exists(location string) (bool, error) {
+	fi, err := os.Stat(location)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !fi.IsDir() {
+		return true, nil
+	}
+	d, err := os.Open(location)
+	if err != nil {
+		return false, err
+	}
+	defer d.Close()
+	if _, err := d.Readdirnames(1); err != nil {
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Resolve applies the strategy s to the existing location, and returns the
+// location to write to:  for Suffix, it's the suffixed name (see Suffixed),
+// for the others, it's the location itself.  Overwrite removes the location.
+// Resume and Merge are not possible with the ZIP files, as they can't be
+// appended to.  Ask must be resolved by the caller in the terminal, otherwise
+// the directory is merged into, and the file is left to be replaced by the
+// writer.
+func Resolve(location string, s Strategy, now time.Time) (string, error) {
+	switch s {
+	case Fail:
+		return "", fmt.Errorf("%w: %s, choose what to do with it with -on-exists", ErrExists, location)
+	case Ask, Resume, Merge:
+		fi, err := os.Stat(location)
+		if err != nil {
+			return "", err
+		}
+		if !fi.IsDir() {
+			if s == Ask {
+				return location, nil
+			}
+			return "", fmt.Errorf("can't %s into the existing file %s, use the suffix or overwrite strategy", s, location)
+		}
+		return location, nil
+	case Suffix:
+		name := Suffixed(location, now)
+		if _, err := os.Stat(name); err == nil {
+			return "", fmt.Errorf("%w: %s", ErrExists, name)
+		}
+		return name, nil
+	case Overwrite:
+		if err := remove(location); err != nil {
+			return "", err
+		}
+		return location, nil
+	}
+	return "", fmt.Errorf("internal error: unsupported output strategy: %s", s)
+}
+
+// remove removes the location, refusing to remove the root or the current
+// directory, that could have been given by mistake.
+func remove(location string) error {
+	abs, err := filepath.Abs(location)
+	if err != nil {
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if filepath.Dir(abs) == abs || abs == wd {
+		return fmt.Errorf("refusing to overwrite %s", abs)
+	}
+	return os.RemoveAll(abs)
+}
+
+// suffixLayout is the layout of the timestamp suffix.
+const suffixLayout = "20060102-150405"
+
+// Suffixed returns the name with the timestamp suffix t, inserted before the
+// extension of the file, i.e. "export-20240131-020000.zip", or appended to
+// the directory name.
+func Suffixed(name string, t time.Time) string {
+	name = strings.TrimRight(name, `/\`)
+	ext := filepath.Ext(name)
+	if fi, err := os.Stat(name); err == nil && fi.IsDir() {
+		ext = ""
+	}
+	return strings.TrimSuffix(name, ext) + "-" + t.Format(suffixLayout) + ext
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExists(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.Mkdir(empty, 0o755))
+	file := filepath.Join(dir, "export.zip")
+	require.NoError(t, os.WriteFile(file, []byte("PK"), 0o644))
+
+	for name, want := range map[string]bool{
+		dir:                             true,
+		empty:                           false,
+		file:                            true,
+		filepath.Join(dir, "not_there"): false,
+	} {
+		got, err := Exists(name)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, name)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	now := time.Date(2024, 1, 31, 2, 0, 0, 0, time.UTC)
+	setup := func(t *testing.T) (dir string, file string) {
+		base := t.TempDir()
+		dir = filepath.Join(base, "dump")
+		require.NoError(t, os.Mkdir(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "C01.json"), []byte("{}"), 0o644))
+		file = filepath.Join(base, "export.zip")
+		require.NoError(t, os.WriteFile(file, []byte("PK"), 0o644))
+		return dir, file
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		dir, _ := setup(t)
+		_, err := Resolve(dir, Fail, now)
+		assert.True(t, errors.Is(err, ErrExists))
+	})
+	t.Run("merge", func(t *testing.T) {
+		dir, file := setup(t)
+		got, err := Resolve(dir, Merge, now)
+		require.NoError(t, err)
+		assert.Equal(t, dir, got)
+		assert.FileExists(t, filepath.Join(dir, "C01.json"))
+
+		_, err = Resolve(file, Resume, now)
+		assert.Error(t, err, "zip files can't be appended to")
+	})
+	t.Run("ask", func(t *testing.T) {
+		// without the terminal.
+		dir, file := setup(t)
+		got, err := Resolve(dir, Ask, now)
+		require.NoError(t, err)
+		assert.Equal(t, dir, got)
+		assert.FileExists(t, filepath.Join(dir, "C01.json"), "directory is merged into")
+
+		got, err = Resolve(file, Ask, now)
+		require.NoError(t, err, "zip file is replaced, i.e. in the scheduled runs")
+		assert.Equal(t, file, got)
+	})
+	t.Run("suffix", func(t *testing.T) {
+		dir, file := setup(t)
+		got, err := Resolve(dir, Suffix, now)
+		require.NoError(t, err)
+		assert.Equal(t, dir+"-20240131-020000", got)
+
+		got, err = Resolve(file, Suffix, now)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(filepath.Dir(file), "export-20240131-020000.zip"), got)
+	})
+	t.Run("overwrite", func(t *testing.T) {
+		dir, file := setup(t)
+		for _, loc := range []string{dir, file} {
+			got, err := Resolve(loc, Overwrite, now)
+			require.NoError(t, err)
+			assert.Equal(t, loc, got)
+			assert.NoFileExists(t, loc)
+		}
+		_, err := Resolve(".", Overwrite, now)
+		assert.Error(t, err, "current directory must not be removed")
+	})
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range Strategies {
+		got, err := Parse(s.Strategy.String())
+		require.NoError(t, err)
+		assert.Equal(t, s.Strategy, got)
+	}
+	_, err := Parse("append")
+	assert.Error(t, err)
+}
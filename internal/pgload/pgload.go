@@ -0,0 +1,281 @@
+// Package pgload loads the archive into the normalized PostgreSQL schema.
+//
+// Rows are upserted, messages are keyed by the workspace, channel and ts, so
+// loading the next incremental archive into the same database updates the
+// changed messages and adds the new ones, keeping the database current.
+package pgload
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// execer is the subset of *sql.Tx that is used by the loader.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Loader loads archives into the database.
+type Loader struct {
+	db        *sql.DB
+	workspace string
+	lg        logger.Interface
+}
+
+// Option is the Loader option.
+type Option func(*Loader)
+
+// WithWorkspace sets the workspace ID, under which the archive is loaded.  If
+// not set, it is the team ID of the archive users.
+func WithWorkspace(id string) Option {
+	return func(l *Loader) {
+		l.workspace = id
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(lg logger.Interface) Option {
+	return func(l *Loader) {
+		if lg != nil {
+			l.lg = lg
+		}
+	}
+}
+
+// New creates the Loader for the database db.
+func New(db *sql.DB, opts ...Option) *Loader {
+	l := &Loader{db: db, lg: logger.Default}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Stats is the number of the loaded records.
+type Stats struct {
+	Workspace string
+	Channels  int
+	Users     int
+	Messages  int
+}
+
+// Load creates the schema, if it doesn't exist, and loads the archive ar.
+// Each conversation is loaded in its own transaction, so that the interrupted
+// load can be repeated.
+func (l *Loader) Load(ctx context.Context, ar *archive.Archive) (Stats, error) {
+	var st Stats
+	if err := l.inTx(ctx, func(tx execer) error {
+		return createSchema(ctx, tx)
+	}); err != nil {
+		return st, fmt.Errorf("schema: %w", err)
+	}
+
+	chans, err := ar.Channels()
+	if err != nil {
+		return st, err
+	}
+	users, err := ar.Users()
+	if err != nil {
+		return st, err
+	}
+	st.Workspace = l.workspace
+	if st.Workspace == "" {
+		st.Workspace = workspaceID(users)
+	}
+	if st.Workspace == "" {
+		return st, errors.New("unable to detect the workspace ID, there are no users in the archive, set the workspace explicitly")
+	}
+	if err := l.inTx(ctx, func(tx execer) error {
+		if _, err := tx.ExecContext(ctx, upsertWorkspace, st.Workspace, ar.Name(), time.Now()); err != nil {
+			return err
+		}
+		if err := loadUsers(ctx, tx, st.Workspace, users); err != nil {
+			return err
+		}
+		return loadChannels(ctx, tx, st.Workspace, chans)
+	}); err != nil {
+		return st, err
+	}
+	st.Users, st.Channels = len(users), len(chans)
+
+	for _, ch := range chans {
+		if err := ctx.Err(); err != nil {
+			return st, err
+		}
+		cnv, err := ar.Conversation(ch.ID)
+		if err != nil {
+			if errors.Is(err, archive.ErrNotFound) {
+				continue
+			}
+			return st, err
+		}
+		var n int
+		if err := l.inTx(ctx, func(tx execer) (err error) {
+			n, err = loadMessages(ctx, tx, st.Workspace, ch.ID, cnv.Messages)
+			return err
+		}); err != nil {
+			return st, fmt.Errorf("%s: %w", ch.ID, err)
+		}
+		l.lg.Debugf("%s: %d message(s) loaded", ch.ID, n)
+		st.Messages += n
+	}
+	return st, nil
+}
+
+// inTx calls fn in the transaction, which is committed if fn succeeds.
+func (l *Loader) inTx(ctx context.Context, fn func(tx execer) error) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func createSchema(ctx context.Context, tx execer) error {
+	for _, stmt := range schema {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workspaceID returns the most common team ID of the users.
+func workspaceID(users types.Users) string {
+	count := make(map[string]int)
+	var id string
+	for _, u := range users {
+		if u.TeamID == "" {
+			continue
+		}
+		count[u.TeamID]++
+		if count[u.TeamID] > count[id] || (count[u.TeamID] == count[id] && u.TeamID < id) {
+			id = u.TeamID
+		}
+	}
+	return id
+}
+
+func loadUsers(ctx context.Context, tx execer, ws string, users types.Users) error {
+	for _, u := range users {
+		raw, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, upsertUser,
+			ws, u.ID, u.Name, u.RealName, u.Profile.DisplayName, u.Profile.Email, u.IsBot, u.Deleted, u.TZ, string(raw),
+		); err != nil {
+			return fmt.Errorf("user %s: %w", u.ID, err)
+		}
+	}
+	return nil
+}
+
+func loadChannels(ctx context.Context, tx execer, ws string, chans []slack.Channel) error {
+	for _, ch := range chans {
+		raw, err := json.Marshal(ch)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, upsertChannel,
+			ws, ch.ID, ch.Name, ch.IsPrivate, ch.IsIM, ch.IsMpIM, ch.IsArchived, ch.Topic.Value, ch.Purpose.Value, nullTime(unixTime(int64(ch.Created))), string(raw),
+		); err != nil {
+			return fmt.Errorf("channel %s: %w", ch.ID, err)
+		}
+	}
+	return nil
+}
+
+// loadMessages loads the messages and their thread replies, it returns the
+// number of the loaded messages.
+func loadMessages(ctx context.Context, tx execer, ws string, channelID string, msgs []types.Message) (int, error) {
+	var n int
+	for _, m := range msgs {
+		if err := loadMessage(ctx, tx, ws, channelID, m.Message); err != nil {
+			return n, fmt.Errorf("message %s: %w", m.Timestamp, err)
+		}
+		n++
+		nr, err := loadMessages(ctx, tx, ws, channelID, m.ThreadReplies)
+		n += nr
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func loadMessage(ctx context.Context, tx execer, ws string, channelID string, m slack.Message) error {
+	created, err := structures.ParseSlackTS(m.Timestamp)
+	if err != nil {
+		return err
+	}
+	var edited any
+	if m.Edited != nil {
+		if t, err := structures.ParseSlackTS(m.Edited.Timestamp); err == nil {
+			edited = t
+		}
+	}
+	var threadTS any
+	if m.ThreadTimestamp != "" {
+		threadTS = m.ThreadTimestamp
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, upsertMessage,
+		ws, channelID, m.Timestamp, threadTS, m.User, m.SubType, m.Text, created, edited, m.ReplyCount, string(raw),
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteReactions, ws, channelID, m.Timestamp); err != nil {
+		return err
+	}
+	for _, r := range m.Reactions {
+		for _, user := range r.Users {
+			if _, err := tx.ExecContext(ctx, upsertReaction, ws, channelID, m.Timestamp, r.Name, user); err != nil {
+				return fmt.Errorf("reaction %s: %w", r.Name, err)
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, deleteFiles, ws, channelID, m.Timestamp); err != nil {
+		return err
+	}
+	for _, f := range m.Files {
+		if _, err := tx.ExecContext(ctx, upsertFile, ws, channelID, m.Timestamp, f.ID, f.Name, f.Title, f.Mimetype, int64(f.Size), f.URLPrivate); err != nil {
+			return fmt.Errorf("file %s: %w", f.ID, err)
+		}
+	}
+	return nil
+}
+
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0).UTC()
+}
+
+// nullTime returns nil for the zero time, so that it's stored as NULL.
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
@@ -0,0 +1,163 @@
+package pgload
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+var testFS = fstest.MapFS{
+	"channels.json": {Data: []byte(`[{"id":"C01","name":"general","created":1672531200,"topic":{"value":"chat"}}]`)},
+	"users.json":    {Data: []byte(`[{"id":"U01","team_id":"T01","name":"alice","profile":{"email":"alice@example.com"}},{"id":"U02","team_id":"T01","name":"bob"}]`)},
+	"general/2023-01-01.json": {Data: []byte(`[
+		{"type":"message","user":"U01","text":"hello","ts":"1672531200.000100","thread_ts":"1672531200.000100","reply_count":1,
+		 "reactions":[{"name":"wave","count":2,"users":["U01","U02"]}]},
+		{"type":"message","user":"U02","text":"hi","ts":"1672531300.000100","thread_ts":"1672531200.000100",
+		 "files":[{"id":"F01","name":"a.png","mimetype":"image/png","size":3}]}
+	]`)},
+}
+
+// recorder is the fake database/sql driver, that records the executed
+// statements.
+type recorder struct {
+	mu      sync.Mutex
+	stmts   []stmt
+	commits int
+	// failOn fails the statement that contains the string.
+	failOn string
+}
+
+type stmt struct {
+	query string
+	args  []driver.Value
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return &recConn{r}, nil }
+
+type recConn struct{ r *recorder }
+
+func (c *recConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *recConn) Close() error                        { return nil }
+func (c *recConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *recConn) Commit() error                       { c.r.mu.Lock(); c.r.commits++; c.r.mu.Unlock(); return nil }
+func (c *recConn) Rollback() error                     { return nil }
+
+func (c *recConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	if c.r.failOn != "" && strings.Contains(query, c.r.failOn) {
+		return nil, errors.New("test error")
+	}
+	vals := make([]driver.Value, len(args))
+	for i, a := range args {
+		vals[i] = a.Value
+	}
+	c.r.stmts = append(c.r.stmts, stmt{query, vals})
+	return driver.RowsAffected(1), nil
+}
+
+// executed returns the statements that start with prefix.
+func (r *recorder) executed(prefix string) []stmt {
+	var res []stmt
+	for _, s := range r.stmts {
+		if strings.HasPrefix(s.query, prefix) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// Connect implements driver.Connector.
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return &recConn{r}, nil }
+
+// Driver implements driver.Connector.
+func (r *recorder) Driver() driver.Driver { return r }
+
+func testDB(t *testing.T) (*sql.DB, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	db := sql.OpenDB(rec)
+	t.Cleanup(func() { db.Close() })
+	return db, rec
+}
+
+func testArchive(t *testing.T) *archive.Archive {
+	t.Helper()
+	ar, err := archive.New(testFS, "test.zip")
+	require.NoError(t, err)
+	return ar
+}
+
+func TestLoader_Load(t *testing.T) {
+	t.Run("loads the archive", func(t *testing.T) {
+		db, rec := testDB(t)
+		st, err := New(db, WithLogger(logger.Silent)).Load(context.Background(), testArchive(t))
+		require.NoError(t, err)
+		assert.Equal(t, Stats{Workspace: "T01", Channels: 1, Users: 2, Messages: 2}, st)
+
+		assert.Len(t, rec.executed("CREATE TABLE"), 6)
+		assert.Equal(t, 3, rec.commits, "schema, workspace and one conversation")
+
+		ws := rec.executed("INSERT INTO workspaces")
+		require.Len(t, ws, 1)
+		assert.Equal(t, []driver.Value{"T01", "test.zip"}, ws[0].args[:2])
+
+		users := rec.executed("INSERT INTO users")
+		require.Len(t, users, 2)
+		assert.Equal(t, "alice@example.com", users[0].args[5])
+
+		ch := rec.executed("INSERT INTO channels")
+		require.Len(t, ch, 1)
+		assert.Equal(t, []driver.Value{"T01", "C01", "general"}, ch[0].args[:3])
+		assert.Equal(t, "chat", ch[0].args[7])
+
+		msgs := rec.executed("INSERT INTO messages")
+		require.Len(t, msgs, 2)
+		assert.Equal(t, []driver.Value{"T01", "C01", "1672531200.000100", "1672531200.000100", "U01", "", "hello"}, msgs[0].args[:7])
+		assert.Equal(t, int64(1), msgs[0].args[9], "reply count")
+		assert.Equal(t, "1672531300.000100", msgs[1].args[2], "thread reply")
+
+		reactions := rec.executed("INSERT INTO reactions")
+		require.Len(t, reactions, 2)
+		assert.Equal(t, []driver.Value{"T01", "C01", "1672531200.000100", "wave", "U02"}, reactions[1].args)
+
+		files := rec.executed("INSERT INTO files")
+		require.Len(t, files, 1)
+		assert.Equal(t, []driver.Value{"T01", "C01", "1672531300.000100", "F01", "a.png", "", "image/png", int64(3), ""}, files[0].args)
+
+		assert.Len(t, rec.executed("DELETE FROM reactions"), 2, "reactions are replaced for each message")
+	})
+	t.Run("explicit workspace", func(t *testing.T) {
+		db, _ := testDB(t)
+		st, err := New(db, WithWorkspace("acme"), WithLogger(logger.Silent)).Load(context.Background(), testArchive(t))
+		require.NoError(t, err)
+		assert.Equal(t, "acme", st.Workspace)
+	})
+	t.Run("error", func(t *testing.T) {
+		db, rec := testDB(t)
+		rec.failOn = "INSERT INTO files"
+		_, err := New(db, WithLogger(logger.Silent)).Load(context.Background(), testArchive(t))
+		assert.ErrorContains(t, err, "C01")
+		assert.Equal(t, 2, rec.commits, "the failed conversation is not committed")
+	})
+}
+
+func Test_upsert(t *testing.T) {
+	assert.Equal(t,
+		"INSERT INTO t (a, b, c) VALUES ($1, $2, $3) ON CONFLICT (a, b) DO UPDATE SET c = EXCLUDED.c",
+		upsert("t", []string{"a", "b"}, []string{"c"}))
+	assert.Equal(t,
+		"INSERT INTO t (a) VALUES ($1) ON CONFLICT (a) DO NOTHING",
+		upsert("t", []string{"a"}, nil))
+}
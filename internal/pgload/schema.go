@@ -0,0 +1,123 @@
+package pgload
+
+// In this file: the database schema and the upsert statements.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// schema is the database schema.  All tables are keyed by the workspace, so
+// that several workspaces can be loaded into the same database.  Messages are
+// keyed by channel and ts, the complete message is kept in the raw column.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS workspaces (
+		id        TEXT PRIMARY KEY,
+		archive   TEXT NOT NULL,
+		loaded_at TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS channels (
+		workspace_id TEXT NOT NULL REFERENCES workspaces (id),
+		id           TEXT NOT NULL,
+		name         TEXT NOT NULL,
+		is_private   BOOLEAN NOT NULL,
+		is_im        BOOLEAN NOT NULL,
+		is_mpim      BOOLEAN NOT NULL,
+		is_archived  BOOLEAN NOT NULL,
+		topic        TEXT NOT NULL,
+		purpose      TEXT NOT NULL,
+		created      TIMESTAMPTZ,
+		raw          JSONB NOT NULL,
+		PRIMARY KEY (workspace_id, id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS users (
+		workspace_id TEXT NOT NULL REFERENCES workspaces (id),
+		id           TEXT NOT NULL,
+		name         TEXT NOT NULL,
+		real_name    TEXT NOT NULL,
+		display_name TEXT NOT NULL,
+		email        TEXT NOT NULL,
+		is_bot       BOOLEAN NOT NULL,
+		deleted      BOOLEAN NOT NULL,
+		tz           TEXT NOT NULL,
+		raw          JSONB NOT NULL,
+		PRIMARY KEY (workspace_id, id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS messages (
+		workspace_id TEXT NOT NULL,
+		channel_id   TEXT NOT NULL,
+		ts           TEXT NOT NULL,
+		thread_ts    TEXT,
+		user_id      TEXT NOT NULL,
+		subtype      TEXT NOT NULL,
+		text         TEXT NOT NULL,
+		created      TIMESTAMPTZ NOT NULL,
+		edited       TIMESTAMPTZ,
+		reply_count  INTEGER NOT NULL,
+		raw          JSONB NOT NULL,
+		PRIMARY KEY (workspace_id, channel_id, ts),
+		FOREIGN KEY (workspace_id, channel_id) REFERENCES channels (workspace_id, id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS messages_thread_idx ON messages (workspace_id, channel_id, thread_ts)`,
+	`CREATE INDEX IF NOT EXISTS messages_user_idx ON messages (workspace_id, user_id)`,
+	`CREATE TABLE IF NOT EXISTS reactions (
+		workspace_id TEXT NOT NULL,
+		channel_id   TEXT NOT NULL,
+		ts           TEXT NOT NULL,
+		name         TEXT NOT NULL,
+		user_id      TEXT NOT NULL,
+		PRIMARY KEY (workspace_id, channel_id, ts, name, user_id),
+		FOREIGN KEY (workspace_id, channel_id, ts) REFERENCES messages (workspace_id, channel_id, ts) ON DELETE CASCADE
+	)`,
+	`CREATE TABLE IF NOT EXISTS files (
+		workspace_id TEXT NOT NULL,
+		channel_id   TEXT NOT NULL,
+		ts           TEXT NOT NULL,
+		id           TEXT NOT NULL,
+		name         TEXT NOT NULL,
+		title        TEXT NOT NULL,
+		mimetype     TEXT NOT NULL,
+		size         BIGINT NOT NULL,
+		url_private  TEXT NOT NULL,
+		PRIMARY KEY (workspace_id, channel_id, ts, id),
+		FOREIGN KEY (workspace_id, channel_id, ts) REFERENCES messages (workspace_id, channel_id, ts) ON DELETE CASCADE
+	)`,
+}
+
+// upsert returns the INSERT ... ON CONFLICT DO UPDATE statement for the table
+// with the primary key columns key and the other columns cols.
+func upsert(table string, key []string, cols []string) string {
+	all := append(append([]string{}, key...), cols...)
+	params := make([]string, len(all))
+	for i := range all {
+		params[i] = fmt.Sprintf("$%d", i+1)
+	}
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO ",
+		table, strings.Join(all, ", "), strings.Join(params, ", "), strings.Join(key, ", "))
+	if len(cols) == 0 {
+		return stmt + "NOTHING"
+	}
+	set := make([]string, len(cols))
+	for i, c := range cols {
+		set[i] = c + " = EXCLUDED." + c
+	}
+	return stmt + "UPDATE SET " + strings.Join(set, ", ")
+}
+
+var (
+	upsertWorkspace = upsert("workspaces", []string{"id"}, []string{"archive", "loaded_at"})
+	upsertChannel   = upsert("channels", []string{"workspace_id", "id"},
+		[]string{"name", "is_private", "is_im", "is_mpim", "is_archived", "topic", "purpose", "created", "raw"})
+	upsertUser = upsert("users", []string{"workspace_id", "id"},
+		[]string{"name", "real_name", "display_name", "email", "is_bot", "deleted", "tz", "raw"})
+	upsertMessage = upsert("messages", []string{"workspace_id", "channel_id", "ts"},
+		[]string{"thread_ts", "user_id", "subtype", "text", "created", "edited", "reply_count", "raw"})
+	upsertReaction = upsert("reactions", []string{"workspace_id", "channel_id", "ts", "name", "user_id"}, nil)
+	upsertFile     = upsert("files", []string{"workspace_id", "channel_id", "ts", "id"},
+		[]string{"name", "title", "mimetype", "size", "url_private"})
+
+	// reactions and files of the message are replaced on each load, as they
+	// could have been removed since the previous archive.
+	deleteReactions = "DELETE FROM reactions WHERE workspace_id = $1 AND channel_id = $2 AND ts = $3"
+	deleteFiles     = "DELETE FROM files WHERE workspace_id = $1 AND channel_id = $2 AND ts = $3"
+)
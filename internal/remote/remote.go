@@ -0,0 +1,271 @@
+// Package remote implements the read access to the remote archives, stored in
+// the S3-compatible storage or on the web server, without downloading them.
+//
+// The archive file is read by the ranges, which is enough for the ZIP files
+// and the uncompressed tarballs, see archive.NewPacked, as only their index
+// and the requested files are read.  The ranges are read in blocks, that are
+// cached in memory and, optionally, on the disk, so that the repeated views
+// of the same archive don't download the same blocks again.
+package remote
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rusq/slackdump/v2/internal/upload"
+)
+
+const (
+	// blockSize is the size of the range, that is read and cached at once.
+	blockSize = 1 << 20
+	// memBlocks is the number of the blocks, that are kept in memory.
+	memBlocks = 64
+)
+
+// Config is the configuration of the remote access.
+type Config struct {
+	// S3 provides the endpoint, region and credentials for the s3://
+	// locations, the rest of the settings are ignored.
+	S3 upload.Config
+	// CacheDir is the directory of the disk cache of the blocks.  If empty,
+	// the blocks are cached only in memory.
+	CacheDir string
+	// Client is the HTTP client for the http:// and https:// locations.  If
+	// nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// IsRemote returns true if the location is the remote file URL.
+func IsRemote(location string) bool {
+	scheme, _, found := strings.Cut(location, "://")
+	if !found {
+		return false
+	}
+	switch strings.ToLower(scheme) {
+	case "s3", "http", "https":
+		return true
+	}
+	return false
+}
+
+// source is the remote file storage.
+type source interface {
+	// size returns the size of the file.
+	size(ctx context.Context) (int64, error)
+	// getRange returns n bytes of the file, starting at the offset off.
+	getRange(ctx context.Context, off, n int64) ([]byte, error)
+}
+
+// File is the remote file, read by the ranges.  It implements io.ReaderAt.
+type File struct {
+	// ctx is the context of the requests, io.ReaderAt has no context.
+	ctx      context.Context
+	src      source
+	location string
+	size     int64
+	cacheDir string // cache directory of the file, or empty
+
+	mu     sync.Mutex
+	blocks map[int64][]byte
+	order  []int64 // blocks in the order of reading, for the eviction
+}
+
+// Open opens the remote file at location, which is s3://bucket/key,
+// http://host/path or https://host/path.  The web server must support the
+// range requests.
+func Open(ctx context.Context, location string, cfg Config) (*File, error) {
+	var src source
+	scheme, _, _ := strings.Cut(location, "://")
+	switch strings.ToLower(scheme) {
+	case "s3":
+		obj, err := upload.NewObject(cfg.S3, location)
+		if err != nil {
+			return nil, err
+		}
+		src = s3Source{obj}
+	case "http", "https":
+		cl := cfg.Client
+		if cl == nil {
+			cl = http.DefaultClient
+		}
+		src = httpSource{cl: cl, url: location}
+	default:
+		return nil, fmt.Errorf("unsupported remote location: %s, expected s3:// or http(s):// URL", location)
+	}
+	size, err := src.size(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{
+		ctx:      ctx,
+		src:      src,
+		location: location,
+		size:     size,
+		blocks:   make(map[int64][]byte),
+	}
+	if cfg.CacheDir != "" {
+		// the cache is keyed by the location and the size, so that the
+		// replaced archive of the different size is not read from the
+		// stale cache.
+		h := sha256.Sum256([]byte(location))
+		f.cacheDir = filepath.Join(cfg.CacheDir, hex.EncodeToString(h[:8])+"-"+strconv.FormatInt(size, 10))
+		if err := os.MkdirAll(f.cacheDir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Size returns the size of the file.
+func (f *File) Size() int64 {
+	return f.size
+}
+
+func (f *File) String() string {
+	return f.location
+}
+
+// ReadAt implements io.ReaderAt.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("remote: negative offset")
+	}
+	var n int
+	for n < len(p) {
+		if off >= f.size {
+			return n, io.EOF
+		}
+		data, err := f.block(off / blockSize)
+		if err != nil {
+			return n, err
+		}
+		c := copy(p[n:], data[off%blockSize:])
+		n += c
+		off += int64(c)
+	}
+	return n, nil
+}
+
+// block returns the block with the index idx from the memory cache, the
+// disk cache, or the storage.
+func (f *File) block(idx int64) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if data, ok := f.blocks[idx]; ok {
+		return data, nil
+	}
+	data, err := f.load(idx)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.order) == memBlocks {
+		delete(f.blocks, f.order[0])
+		f.order = f.order[1:]
+	}
+	f.blocks[idx] = data
+	f.order = append(f.order, idx)
+	return data, nil
+}
+
+// load reads the block idx from the disk cache, or from the storage, and
+// saves it to the disk cache.
+func (f *File) load(idx int64) ([]byte, error) {
+	off := idx * blockSize
+	n := int64(blockSize)
+	if off+n > f.size {
+		n = f.size - off
+	}
+	var name string
+	if f.cacheDir != "" {
+		name = filepath.Join(f.cacheDir, strconv.FormatInt(idx, 10))
+		if data, err := os.ReadFile(name); err == nil && int64(len(data)) == n {
+			return data, nil
+		}
+	}
+	data, err := f.src.getRange(f.ctx, off, n)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != n {
+		return nil, fmt.Errorf("%s: short read at %d: %d bytes instead of %d", f.location, off, len(data), n)
+	}
+	if name != "" {
+		// the failure to cache is not fatal, the block is read again
+		// next time.
+		_ = os.WriteFile(name, data, 0600)
+	}
+	return data, nil
+}
+
+// s3Source is the object in the S3 bucket.
+type s3Source struct {
+	obj *upload.Object
+}
+
+func (s s3Source) size(ctx context.Context) (int64, error) {
+	return s.obj.Size(ctx)
+}
+
+func (s s3Source) getRange(ctx context.Context, off, n int64) ([]byte, error) {
+	return s.obj.GetRange(ctx, off, n)
+}
+
+// httpSource is the file on the web server.
+type httpSource struct {
+	cl  *http.Client
+	url string
+}
+
+func (s httpSource) size(ctx context.Context) (int64, error) {
+	resp, err := s.do(ctx, http.MethodHead, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.ContentLength < 0 {
+		return 0, fmt.Errorf("%s: the server didn't report the file size", s.url)
+	}
+	return resp.ContentLength, nil
+}
+
+func (s httpSource) getRange(ctx context.Context, off, n int64) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", off, off+n-1)}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("%s: range requests are not supported by the server", s.url)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, n))
+}
+
+// do executes the request, and returns the response, if the status is 2xx.
+func (s httpSource) do(ctx context.Context, method string, hdr http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range hdr {
+		req.Header[k] = v
+	}
+	resp, err := s.cl.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", s.url, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,78 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeServer serves data with the range support, and counts the GET
+// requests.
+func rangeServer(data []byte, gets *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(gets, 1)
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+}
+
+func TestFile_ReadAt(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), blockSize/4) // 2.5 blocks
+	var gets int32
+	srv := rangeServer(data, &gets)
+	defer srv.Close()
+	cacheDir := t.TempDir()
+
+	f, err := Open(context.Background(), srv.URL+"/export.zip", Config{CacheDir: cacheDir})
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), f.Size())
+
+	// across the block boundary.
+	p := make([]byte, 20)
+	n, err := f.ReadAt(p, blockSize-10)
+	require.NoError(t, err)
+	assert.Equal(t, 20, n)
+	assert.Equal(t, data[blockSize-10:blockSize+10], p)
+	assert.Equal(t, int32(2), gets)
+
+	// the tail of the file.
+	n, err = f.ReadAt(p, int64(len(data))-5)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, int32(3), gets)
+
+	// from the memory cache.
+	_, err = f.ReadAt(p, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), gets)
+
+	// from the disk cache.
+	f, err = Open(context.Background(), srv.URL+"/export.zip", Config{CacheDir: cacheDir})
+	require.NoError(t, err)
+	_, err = f.ReadAt(p, blockSize)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), gets)
+}
+
+func TestOpen(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	_, err := Open(context.Background(), srv.URL+"/missing.zip", Config{})
+	assert.Error(t, err)
+	_, err = Open(context.Background(), "ftp://example.com/export.zip", Config{})
+	assert.Error(t, err)
+
+	assert.True(t, IsRemote("s3://bucket/export.zip"))
+	assert.True(t, IsRemote("HTTPS://example.com/export.zip"))
+	assert.False(t, IsRemote("export.zip"))
+	assert.False(t, IsRemote("redis://localhost"))
+}
@@ -0,0 +1,187 @@
+package sign
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// Manifest returns the manifest of the SHA-256 checksums of all regular files
+// of fsys, sorted by path, in the format of the sha256sum tool:
+//
+//	<hex checksum>  <slash separated path>
+func Manifest(ctx context.Context, fsys fs.FS) ([]byte, error) {
+	sums, err := checksums(ctx, fsys)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		sum, line := sums[name], name
+		if strings.ContainsAny(name, "\\\n") {
+			// sha256sum escapes these names, and marks the line with the
+			// backslash.
+			line = strings.NewReplacer("\\", "\\\\", "\n", "\\n").Replace(name)
+			buf.WriteString("\\")
+		}
+		fmt.Fprintf(&buf, "%s  %s\n", sum, line)
+	}
+	return buf.Bytes(), nil
+}
+
+// CheckError is returned by Check, if the files do not match the manifest.
+type CheckError struct {
+	Modified []string // files with the different checksum
+	Missing  []string // files in the manifest, that do not exist
+	Extra    []string // files, that are not in the manifest
+}
+
+func (e *CheckError) Error() string {
+	var parts []string
+	for _, p := range []struct {
+		what  string
+		names []string
+	}{{"modified", e.Modified}, {"missing", e.Missing}, {"not in the manifest", e.Extra}} {
+		if len(p.names) > 0 {
+			parts = append(parts, fmt.Sprintf("%d file(s) %s", len(p.names), p.what))
+		}
+	}
+	return "archive does not match the manifest: " + strings.Join(parts, ", ")
+}
+
+// Check checks the files of fsys against the manifest, created by Manifest.
+// If the files do not match, it returns *CheckError.
+func Check(ctx context.Context, fsys fs.FS, manifest []byte) error {
+	want, err := ParseManifest(manifest)
+	if err != nil {
+		return err
+	}
+	got, err := checksums(ctx, fsys)
+	if err != nil {
+		return err
+	}
+	var ce CheckError
+	for name, sum := range want {
+		if gotSum, ok := got[name]; !ok {
+			ce.Missing = append(ce.Missing, name)
+		} else if gotSum != sum {
+			ce.Modified = append(ce.Modified, name)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			ce.Extra = append(ce.Extra, name)
+		}
+	}
+	if len(ce.Modified)+len(ce.Missing)+len(ce.Extra) == 0 {
+		return nil
+	}
+	sort.Strings(ce.Modified)
+	sort.Strings(ce.Missing)
+	sort.Strings(ce.Extra)
+	return &ce
+}
+
+// ParseManifest parses the manifest and returns the map of the file paths to
+// their checksums.
+func ParseManifest(manifest []byte) (map[string]string, error) {
+	sums := make(map[string]string)
+	s := bufio.NewScanner(bytes.NewReader(manifest))
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSuffix(s.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		escaped := strings.HasPrefix(line, "\\")
+		if escaped {
+			line = line[1:]
+		}
+		sum, name, ok := strings.Cut(line, "  ")
+		if !ok || len(sum) != sha256.Size*2 {
+			return nil, fmt.Errorf("manifest line %d: invalid format", n)
+		}
+		if _, err := hex.DecodeString(sum); err != nil {
+			return nil, fmt.Errorf("manifest line %d: invalid checksum: %w", n, err)
+		}
+		if escaped {
+			name = unescapeName(name)
+		}
+		sums[name] = strings.ToLower(sum)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(sums) == 0 {
+		return nil, errors.New("manifest is empty")
+	}
+	return sums, nil
+}
+
+func unescapeName(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				sb.WriteByte('\n')
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// checksums returns the map of the paths of the regular files in fsys to
+// their hex encoded SHA-256 checksums.
+func checksums(ctx context.Context, fsys fs.FS) (map[string]string, error) {
+	sums := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		sum, err := checksum(fsys, name)
+		if err != nil {
+			return err
+		}
+		sums[name] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+func checksum(fsys fs.FS, name string) (string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
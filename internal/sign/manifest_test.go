@@ -0,0 +1,64 @@
+package sign
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"channels.json":                  {Data: []byte(`[{"id":"C01","name":"general"}]`)},
+		"general/2023-01-01.json":        {Data: []byte(`[]`)},
+		"general/attachments/F01-a\\b":   {Data: []byte("data")},
+		"general/attachments/F02-report": {Data: []byte("report")},
+	}
+}
+
+func TestManifest(t *testing.T) {
+	ctx := context.Background()
+	m, err := Manifest(ctx, testFS())
+	require.NoError(t, err)
+	assert.Equal(t,
+		"32b55018746a605970cb79c4730f1288a2d37860057c834c246aa933671fcb47  channels.json\n"+
+			"4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945  general/2023-01-01.json\n"+
+			"\\3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7  general/attachments/F01-a\\\\b\n"+
+			"845e91831319e89c4d656bdb80c278ac09a7230d61e5dfd2e1b1fbb436ac8917  general/attachments/F02-report\n",
+		string(m))
+
+	sums, err := ParseManifest(m)
+	require.NoError(t, err)
+	assert.Contains(t, sums, "general/attachments/F01-a\\b")
+	assert.Len(t, sums, 4)
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	m, err := Manifest(ctx, testFS())
+	require.NoError(t, err)
+
+	t.Run("ok", func(t *testing.T) {
+		assert.NoError(t, Check(ctx, testFS(), m))
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		fsys := testFS()
+		fsys["general/2023-01-01.json"] = &fstest.MapFile{Data: []byte(`[{"text":"forged"}]`)}
+		delete(fsys, "general/attachments/F02-report")
+		fsys["general/attachments/F03-new"] = &fstest.MapFile{Data: []byte("new")}
+
+		err := Check(ctx, fsys, m)
+		var ce *CheckError
+		require.ErrorAs(t, err, &ce)
+		assert.Equal(t, []string{"general/2023-01-01.json"}, ce.Modified)
+		assert.Equal(t, []string{"general/attachments/F02-report"}, ce.Missing)
+		assert.Equal(t, []string{"general/attachments/F03-new"}, ce.Extra)
+		assert.Equal(t, "archive does not match the manifest: 1 file(s) modified, 1 file(s) missing, 1 file(s) not in the manifest", err.Error())
+	})
+	t.Run("invalid manifest", func(t *testing.T) {
+		assert.Error(t, Check(ctx, testFS(), []byte("not a manifest\n")))
+		assert.Error(t, Check(ctx, testFS(), nil))
+	})
+}
@@ -0,0 +1,343 @@
+// Package sign implements the detached signatures of the archives.  The
+// signatures and the keys are in the minisign format, see
+// https://jedisct1.github.io/minisign/, so that the archive can be verified
+// with the minisign tool, without installing slackdump.
+//
+// The archive is not signed directly, instead, the manifest of the SHA-256
+// checksums of all its files is created, see Manifest, and the manifest is
+// signed.  The manifest is in the sha256sum format, so the files of the
+// unpacked archive can be checked with the standard tools, too.
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	untrustedPrefix = "untrusted comment: "
+	trustedPrefix   = "trusted comment: "
+)
+
+var (
+	algEd       = [2]byte{'E', 'd'} // Ed25519 over the message, the legacy signatures.
+	algPrehash  = [2]byte{'E', 'D'} // Ed25519 over the BLAKE2b-512 of the message.
+	kdfScrypt   = [2]byte{'S', 'c'}
+	kdfNone     = [2]byte{0, 0}
+	checksumAlg = [2]byte{'B', '2'}
+)
+
+var (
+	// ErrInvalidSignature is returned, if the signature does not match the
+	// message.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrKeyMismatch is returned, if the message is signed with another key.
+	ErrKeyMismatch = errors.New("the signature was created with a different key")
+	// ErrPassword is returned, if the password of the encrypted secret key is
+	// wrong.
+	ErrPassword = errors.New("wrong password or corrupted secret key")
+)
+
+// KeyID is the random key identifier, it allows to tell which key the
+// signature was created with.
+type KeyID [8]byte
+
+func (id KeyID) String() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+// PublicKey is the public key, that verifies the signatures.
+type PublicKey struct {
+	ID  KeyID
+	key ed25519.PublicKey
+}
+
+// SecretKey is the secret key, that creates the signatures.
+type SecretKey struct {
+	ID  KeyID
+	key ed25519.PrivateKey
+}
+
+// GenerateKey generates the new key pair.
+func GenerateKey() (*SecretKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sk := &SecretKey{key: key}
+	if _, err := io.ReadFull(rand.Reader, sk.ID[:]); err != nil {
+		return nil, err
+	}
+	return sk, nil
+}
+
+// Public returns the public key of the secret key.
+func (sk *SecretKey) Public() *PublicKey {
+	return &PublicKey{ID: sk.ID, key: sk.key.Public().(ed25519.PublicKey)}
+}
+
+// MarshalText returns the public key file contents.
+func (pk *PublicKey) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(algEd[:])
+	buf.Write(pk.ID[:])
+	buf.Write(pk.key)
+	return encodeFile("minisign public key "+pk.ID.String(), buf.Bytes()), nil
+}
+
+// ParsePublicKey parses the public key file contents, or the public key
+// itself, i.e. "RWQf6LRC...".
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	line := strings.TrimSpace(string(data))
+	if strings.HasPrefix(line, untrustedPrefix) {
+		lines := splitLines(line)
+		if len(lines) < 2 {
+			return nil, errors.New("invalid public key file")
+		}
+		line = lines[1]
+	}
+	b, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(b) != 2+8+ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+	if !bytes.Equal(b[:2], algEd[:]) {
+		return nil, fmt.Errorf("unsupported public key algorithm: %q", b[:2])
+	}
+	pk := &PublicKey{key: ed25519.PublicKey(b[10:])}
+	copy(pk.ID[:], b[2:10])
+	return pk, nil
+}
+
+// secret key layout: sig_alg(2) kdf_alg(2) chk_alg(2) kdf_salt(32)
+// kdf_opslimit(8) kdf_memlimit(8) keynum(8) sk(64) chk(32).
+const (
+	skSaltOff   = 6
+	skOpsOff    = skSaltOff + 32
+	skMemOff    = skOpsOff + 8
+	skKeynumOff = skMemOff + 8
+	skLen       = skKeynumOff + 8 + ed25519.PrivateKeySize + blake2b.Size256
+)
+
+// minisign defaults of the scrypt parameters for the encrypted keys.
+const (
+	defOpsLimit = 33554432
+	defMemLimit = 1073741824
+)
+
+// MarshalText returns the unencrypted secret key file contents.  See
+// MarshalEncrypted to protect the key with the password.
+func (sk *SecretKey) MarshalText() ([]byte, error) {
+	return sk.marshal(nil, 0, 0)
+}
+
+// MarshalEncrypted returns the secret key file contents, with the key
+// encrypted with the password, the same way as minisign does.  The password
+// derivation requires 1 GiB of memory.
+func (sk *SecretKey) MarshalEncrypted(password []byte) ([]byte, error) {
+	return sk.marshal(password, defOpsLimit, defMemLimit)
+}
+
+func (sk *SecretKey) marshal(password []byte, ops, mem uint64) ([]byte, error) {
+	b := make([]byte, skLen)
+	copy(b, algEd[:])
+	copy(b[4:], checksumAlg[:])
+	keynum := b[skKeynumOff:]
+	copy(keynum, sk.ID[:])
+	copy(keynum[8:], sk.key)
+	chk := sk.checksum()
+	copy(keynum[8+ed25519.PrivateKeySize:], chk[:])
+	comment := "minisign unencrypted secret key"
+	if password != nil {
+		copy(b[2:], kdfScrypt[:])
+		if _, err := io.ReadFull(rand.Reader, b[skSaltOff:skOpsOff]); err != nil {
+			return nil, err
+		}
+		binary.LittleEndian.PutUint64(b[skOpsOff:], ops)
+		binary.LittleEndian.PutUint64(b[skMemOff:], mem)
+		stream, err := keyStream(password, b[skSaltOff:skOpsOff], ops, mem)
+		if err != nil {
+			return nil, err
+		}
+		xor(keynum, stream)
+		comment = "minisign encrypted secret key"
+	}
+	return encodeFile(comment, b), nil
+}
+
+// ParseSecretKey parses the secret key file contents.  If the key is
+// encrypted, the password function is called to get the password.
+func ParseSecretKey(data []byte, password func() ([]byte, error)) (*SecretKey, error) {
+	lines := splitLines(string(data))
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], untrustedPrefix) {
+		return nil, errors.New("invalid secret key file")
+	}
+	b, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(b) != skLen {
+		return nil, errors.New("invalid secret key")
+	}
+	if !bytes.Equal(b[:2], algEd[:]) || !bytes.Equal(b[4:6], checksumAlg[:]) {
+		return nil, errors.New("unsupported secret key algorithm")
+	}
+	keynum := b[skKeynumOff:]
+	switch kdf := [2]byte{b[2], b[3]}; kdf {
+	case kdfNone:
+	case kdfScrypt:
+		if password == nil {
+			return nil, errors.New("the secret key is encrypted, and the password is not provided")
+		}
+		pass, err := password()
+		if err != nil {
+			return nil, err
+		}
+		stream, err := keyStream(pass, b[skSaltOff:skOpsOff], binary.LittleEndian.Uint64(b[skOpsOff:]), binary.LittleEndian.Uint64(b[skMemOff:]))
+		if err != nil {
+			return nil, err
+		}
+		xor(keynum, stream)
+	default:
+		return nil, fmt.Errorf("unsupported key derivation algorithm: %q", kdf[:])
+	}
+	sk := &SecretKey{key: ed25519.PrivateKey(keynum[8 : 8+ed25519.PrivateKeySize])}
+	copy(sk.ID[:], keynum[:8])
+	chk := sk.checksum()
+	if subtle.ConstantTimeCompare(chk[:], keynum[8+ed25519.PrivateKeySize:]) != 1 {
+		return nil, ErrPassword
+	}
+	return sk, nil
+}
+
+// checksum is the checksum of the secret key, it detects the wrong
+// password.
+func (sk *SecretKey) checksum() [blake2b.Size256]byte {
+	var buf bytes.Buffer
+	buf.Write(algEd[:])
+	buf.Write(sk.ID[:])
+	buf.Write(sk.key)
+	return blake2b.Sum256(buf.Bytes())
+}
+
+// keyStream returns the key stream, that encrypts the secret key, derived
+// from the password with scrypt.
+func keyStream(password, salt []byte, ops, mem uint64) ([]byte, error) {
+	nLog2, p := scryptParams(ops, mem)
+	if nLog2 > 30 || p == 0 {
+		return nil, errors.New("unsupported key derivation parameters")
+	}
+	return scrypt.Key(password, salt, 1<<nLog2, scryptR, int(p), skLen-skKeynumOff)
+}
+
+const scryptR = 8
+
+// scryptParams returns the scrypt parameters log2(N) and p for the opslimit
+// and memlimit the same way as libsodium does.
+func scryptParams(ops, mem uint64) (nLog2 uint, p uint64) {
+	if ops < 32768 {
+		ops = 32768
+	}
+	maxN := mem / (scryptR * 128)
+	if ops < mem/32 {
+		maxN = ops / (scryptR * 4)
+	}
+	for nLog2 = 1; nLog2 < 63; nLog2++ {
+		if uint64(1)<<nLog2 > maxN/2 {
+			break
+		}
+	}
+	if ops < mem/32 {
+		return nLog2, 1
+	}
+	maxrp := (ops / 4) / (uint64(1) << nLog2)
+	if maxrp > 0x3fffffff {
+		maxrp = 0x3fffffff
+	}
+	return nLog2, maxrp / scryptR
+}
+
+// Sign returns the signature file contents of the message msg.  The trusted
+// comment is signed together with the signature, i.e. to prove the time of
+// the signature.
+func (sk *SecretKey) Sign(msg []byte, trustedComment string) []byte {
+	return sk.sign(msg, trustedComment, algPrehash)
+}
+
+// sign returns the signature file contents of the message msg, signed with
+// the algorithm alg, algPrehash or the legacy algEd.
+func (sk *SecretKey) sign(msg []byte, trustedComment string, alg [2]byte) []byte {
+	if alg == algPrehash {
+		h := blake2b.Sum512(msg)
+		msg = h[:]
+	}
+	var sig bytes.Buffer
+	sig.Write(alg[:])
+	sig.Write(sk.ID[:])
+	sig.Write(ed25519.Sign(sk.key, msg))
+	global := ed25519.Sign(sk.key, append(sig.Bytes()[10:], trustedComment...))
+
+	var buf bytes.Buffer
+	buf.Write(encodeFile("signature from slackdump secret key "+sk.ID.String(), sig.Bytes()))
+	buf.WriteString(trustedPrefix + trustedComment + "\n")
+	buf.WriteString(base64.StdEncoding.EncodeToString(global) + "\n")
+	return buf.Bytes()
+}
+
+// Verify verifies the signature file contents sig of the message msg, and
+// returns the trusted comment.
+func (pk *PublicKey) Verify(msg []byte, sig []byte) (string, error) {
+	lines := splitLines(string(sig))
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], untrustedPrefix) || !strings.HasPrefix(lines[2], trustedPrefix) {
+		return "", errors.New("invalid signature file")
+	}
+	b, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(b) != 2+8+ed25519.SignatureSize {
+		return "", errors.New("invalid signature")
+	}
+	if !bytes.Equal(b[2:10], pk.ID[:]) {
+		return "", ErrKeyMismatch
+	}
+	switch [2]byte{b[0], b[1]} {
+	case algEd:
+	case algPrehash:
+		h := blake2b.Sum512(msg)
+		msg = h[:]
+	default:
+		return "", fmt.Errorf("unsupported signature algorithm: %q", b[:2])
+	}
+	if !ed25519.Verify(pk.key, msg, b[10:]) {
+		return "", ErrInvalidSignature
+	}
+	trusted := strings.TrimPrefix(lines[2], trustedPrefix)
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || !ed25519.Verify(pk.key, append(b[10:], trusted...), global) {
+		return "", fmt.Errorf("trusted comment: %w", ErrInvalidSignature)
+	}
+	return trusted, nil
+}
+
+func xor(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+func encodeFile(comment string, data []byte) []byte {
+	return []byte(untrustedPrefix + comment + "\n" + base64.StdEncoding.EncodeToString(data) + "\n")
+}
+
+func splitLines(s string) []string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
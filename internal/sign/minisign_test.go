@@ -0,0 +1,154 @@
+package sign
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	sk, err := GenerateKey()
+	require.NoError(t, err)
+	pk := sk.Public()
+
+	msg := []byte("the manifest")
+	sig := sk.Sign(msg, "timestamp:1700000000\tfile:export.zip.sha256\thashed")
+
+	trusted, err := pk.Verify(msg, sig)
+	require.NoError(t, err)
+	assert.Equal(t, "timestamp:1700000000\tfile:export.zip.sha256\thashed", trusted)
+
+	t.Run("modified message", func(t *testing.T) {
+		_, err := pk.Verify([]byte("the manifest!"), sig)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+	t.Run("modified trusted comment", func(t *testing.T) {
+		forged := bytes.Replace(sig, []byte("timestamp:1700000000"), []byte("timestamp:1800000000"), 1)
+		_, err := pk.Verify(msg, forged)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+	t.Run("another key", func(t *testing.T) {
+		other, err := GenerateKey()
+		require.NoError(t, err)
+		_, err = other.Public().Verify(msg, sig)
+		assert.ErrorIs(t, err, ErrKeyMismatch)
+	})
+	t.Run("garbage", func(t *testing.T) {
+		_, err := pk.Verify(msg, []byte("not a signature"))
+		assert.Error(t, err)
+	})
+}
+
+func TestPublicKey(t *testing.T) {
+	sk, err := GenerateKey()
+	require.NoError(t, err)
+	pk := sk.Public()
+
+	data, err := pk.MarshalText()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "untrusted comment: minisign public key "+pk.ID.String()+"\n"))
+	assert.True(t, strings.HasPrefix(strings.Split(string(data), "\n")[1], "RW"), "minisign public keys start with RW")
+
+	got, err := ParsePublicKey(data)
+	require.NoError(t, err)
+	assert.Equal(t, pk, got)
+
+	// bare key, as passed on the command line.
+	got, err = ParsePublicKey([]byte(strings.Split(string(data), "\n")[1]))
+	require.NoError(t, err)
+	assert.Equal(t, pk, got)
+
+	_, err = ParsePublicKey([]byte("RWQ"))
+	assert.Error(t, err)
+}
+
+func TestSecretKey(t *testing.T) {
+	sk, err := GenerateKey()
+	require.NoError(t, err)
+	msg := []byte("message")
+
+	t.Run("unencrypted", func(t *testing.T) {
+		data, err := sk.MarshalText()
+		require.NoError(t, err)
+		got, err := ParseSecretKey(data, func() ([]byte, error) {
+			t.Fatal("password should not be requested")
+			return nil, nil
+		})
+		require.NoError(t, err)
+		_, err = sk.Public().Verify(msg, got.Sign(msg, "test"))
+		assert.NoError(t, err)
+	})
+	t.Run("encrypted", func(t *testing.T) {
+		// small parameters, to keep the test fast.
+		data, err := sk.marshal([]byte("secret"), 32768, 16<<20)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "unencrypted")
+
+		got, err := ParseSecretKey(data, func() ([]byte, error) { return []byte("secret"), nil })
+		require.NoError(t, err)
+		_, err = sk.Public().Verify(msg, got.Sign(msg, "test"))
+		assert.NoError(t, err)
+
+		_, err = ParseSecretKey(data, func() ([]byte, error) { return []byte("wrong"), nil })
+		assert.ErrorIs(t, err, ErrPassword)
+
+		errPrompt := errors.New("cancelled")
+		_, err = ParseSecretKey(data, func() ([]byte, error) { return nil, errPrompt })
+		assert.ErrorIs(t, err, errPrompt)
+
+		_, err = ParseSecretKey(data, nil)
+		assert.Error(t, err)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseSecretKey([]byte("untrusted comment: x\nAAAA\n"), nil)
+		assert.Error(t, err)
+	})
+}
+
+// Known answer: the key, and the signature of the message with the trusted
+// comment, created by minisign, see the testdata of aead.dev/minisign.
+const (
+	kaSecretKey = "untrusted comment: minisign encrypted secret key\nRWRTY0Iytaz5znJmUO5kBt5xVkvpBl+29A7pZH86phD4h8vD3V8AAAACAAAAAAAAAEAAAAAA9vH9EcS6NdXNIEGhYGoqG1CiL4aptyJreJ4IfuT4+1h+OgVaY/vi0HsbCP0Y6n/wcy0AN0wOXmVDPP33jZqv82YCj2fH+/6MRuAfzNQYoLvc3sH/8bIwqdfpKIjDRZhvqRf063RFYoI=\n"
+	kaPassword  = "correct horse battery staple"
+	kaPublicKey = "untrusted comment: minisign public key C373193807678450\nRWRQhGcHOBlzw4CoKyugkk4ioDfoxlXxC9LBx+VNhJ3w9w+cAxgvPsuo\n"
+	kaMessage   = "Hello World!\n"
+	kaSignature = "untrusted comment: signature from minisign secret key\nRWRQhGcHOBlzwxrJCyuC+rJfHSfyRKRxkuwa3JJ0bWEs7RHjL1OUmqnTr+V1B9JzFuJIH/ybR2Eus9oEZKt9RbitpF/L4D3+5wg=\ntrusted comment: timestamp:1614549543\tfile:message.txt\nP/722+ynQ+tIy0qadFHwLx5MsyNz/jDKJkDWQj4dDD2OKnVte8m/M14mwPE/1NMwzShPMSBhMXqZGdbe+UZjDg==\n"
+)
+
+func TestKnownAnswer(t *testing.T) {
+	pk, err := ParsePublicKey([]byte(kaPublicKey))
+	require.NoError(t, err)
+	assert.Equal(t, "C373193807678450", pk.ID.String())
+
+	trusted, err := pk.Verify([]byte(kaMessage), []byte(kaSignature))
+	require.NoError(t, err)
+	assert.Equal(t, "timestamp:1614549543\tfile:message.txt", trusted)
+
+	if testing.Short() {
+		t.Skip("decrypting the key requires 1 GiB of memory")
+	}
+	sk, err := ParseSecretKey([]byte(kaSecretKey), func() ([]byte, error) { return []byte(kaPassword), nil })
+	require.NoError(t, err)
+	assert.Equal(t, pk, sk.Public())
+
+	// the untrusted comment differs, and minisign versions before 0.10
+	// created the legacy signatures.
+	_, want, _ := strings.Cut(kaSignature, "\n")
+	_, got, _ := strings.Cut(string(sk.sign([]byte(kaMessage), trusted, algEd)), "\n")
+	assert.Equal(t, want, got)
+}
+
+func Test_scryptParams(t *testing.T) {
+	// the minisign defaults.
+	nLog2, p := scryptParams(defOpsLimit, defMemLimit)
+	assert.Equal(t, uint(20), nLog2)
+	assert.Equal(t, uint64(1), p)
+	// the minimal ones.
+	nLog2, p = scryptParams(32768, 16<<20)
+	assert.Equal(t, uint(10), nLog2)
+	assert.Equal(t, uint64(1), p)
+}
@@ -0,0 +1,159 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Objects of the changes.
+const (
+	ObjChannel = "channel"
+	ObjUser    = "user"
+)
+
+// Kinds of the changes.
+const (
+	Added        = "added"
+	Removed      = "removed" // deleted, or not visible to the token anymore.
+	Renamed      = "renamed"
+	Archived     = "archived"
+	Unarchived   = "unarchived"
+	MadePrivate  = "made private"
+	Deactivated  = "deactivated"
+	Reactivated  = "reactivated"
+	EmailChanged = "email changed"
+)
+
+// Change is the change of the channel or the user between the snapshots.
+type Change struct {
+	Object string `json:"object"` // ObjChannel or ObjUser.
+	ID     string `json:"id"`
+	Name   string `json:"name"` // the name in the newer snapshot, or in the older one, if removed.
+	Kind   string `json:"kind"`
+	Old    string `json:"old,omitempty"` // old value, for the renames and the email changes.
+	New    string `json:"new,omitempty"`
+}
+
+// Diff is the list of the changes between the snapshots, the channels go
+// first, then the users, each sorted by ID.
+type Diff struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Changes []Change  `json:"changes"`
+}
+
+// Compare returns the changes of the workspace from the snapshot old to the
+// snapshot new.
+func Compare(old, new *Snapshot) *Diff {
+	d := &Diff{From: old.Created, To: new.Created, Changes: []Change{}}
+
+	oldChans := make(map[string]Channel, len(old.Channels))
+	for _, ch := range old.Channels {
+		oldChans[ch.ID] = ch
+	}
+	for _, ch := range new.Channels {
+		o, ok := oldChans[ch.ID]
+		if !ok {
+			d.add(ObjChannel, ch.ID, ch.Name, Added, "", "")
+			continue
+		}
+		delete(oldChans, ch.ID)
+		if o.Name != ch.Name {
+			d.add(ObjChannel, ch.ID, ch.Name, Renamed, o.Name, ch.Name)
+		}
+		if !o.Private && ch.Private {
+			d.add(ObjChannel, ch.ID, ch.Name, MadePrivate, "", "")
+		}
+		if o.Archived != ch.Archived {
+			d.add(ObjChannel, ch.ID, ch.Name, pick(ch.Archived, Archived, Unarchived), "", "")
+		}
+	}
+	for _, ch := range old.Channels {
+		if _, ok := oldChans[ch.ID]; ok {
+			d.add(ObjChannel, ch.ID, ch.Name, Removed, "", "")
+		}
+	}
+
+	oldUsers := make(map[string]User, len(old.Users))
+	for _, u := range old.Users {
+		oldUsers[u.ID] = u
+	}
+	for _, u := range new.Users {
+		o, ok := oldUsers[u.ID]
+		if !ok {
+			d.add(ObjUser, u.ID, u.Name, Added, "", "")
+			continue
+		}
+		delete(oldUsers, u.ID)
+		if o.Name != u.Name {
+			d.add(ObjUser, u.ID, u.Name, Renamed, o.Name, u.Name)
+		}
+		if o.Deleted != u.Deleted {
+			d.add(ObjUser, u.ID, u.Name, pick(u.Deleted, Deactivated, Reactivated), "", "")
+		}
+		if o.Email != u.Email {
+			d.add(ObjUser, u.ID, u.Name, EmailChanged, o.Email, u.Email)
+		}
+	}
+	for _, u := range old.Users {
+		if _, ok := oldUsers[u.ID]; ok {
+			d.add(ObjUser, u.ID, u.Name, Removed, "", "")
+		}
+	}
+	sort.SliceStable(d.Changes, func(i, j int) bool {
+		ci, cj := d.Changes[i], d.Changes[j]
+		if ci.Object != cj.Object {
+			return ci.Object == ObjChannel
+		}
+		return ci.ID < cj.ID
+	})
+	return d
+}
+
+func (d *Diff) add(obj, id, name, kind, old, new string) {
+	d.Changes = append(d.Changes, Change{Object: obj, ID: id, Name: name, Kind: kind, Old: old, New: new})
+}
+
+func pick(b bool, t, f string) string {
+	if b {
+		return t
+	}
+	return f
+}
+
+// WriteText writes the changes to w as the text table.
+func (d *Diff) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Changes from %s to %s: %d\n", d.From.Format(time.RFC3339), d.To.Format(time.RFC3339), len(d.Changes))
+	if len(d.Changes) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "OBJECT\tID\tNAME\tCHANGE\tDETAILS")
+	for _, c := range d.Changes {
+		name := c.Name
+		switch c.Object {
+		case ObjChannel:
+			name = "#" + name
+		case ObjUser:
+			name = "@" + name
+		}
+		var details string
+		if c.Old != "" || c.New != "" {
+			details = c.Old + " -> " + c.New
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Object, c.ID, name, c.Kind, details)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the diff to w as JSON.
+func (d *Diff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
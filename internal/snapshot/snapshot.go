@@ -0,0 +1,102 @@
+// Package snapshot implements the snapshots of the channel and user lists of
+// the workspace, and the comparison of two snapshots, so that the admins can
+// track the changes of the workspace between the dates without dumping the
+// messages.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// Channel is the channel in the snapshot.
+type Channel struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Private  bool   `json:"private,omitempty"`
+	Archived bool   `json:"archived,omitempty"`
+	Members  int    `json:"members,omitempty"`
+	Created  int64  `json:"created,omitempty"` // unix time, seconds.
+}
+
+// User is the user in the snapshot.
+type User struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Deleted  bool   `json:"deleted,omitempty"`
+	Bot      bool   `json:"bot,omitempty"`
+	Admin    bool   `json:"admin,omitempty"`
+}
+
+// Snapshot is the channel and user lists of the workspace at the time
+// Created, sorted by ID.
+type Snapshot struct {
+	Created  time.Time `json:"created"`
+	TeamID   string    `json:"team_id,omitempty"`
+	Channels []Channel `json:"channels"`
+	Users    []User    `json:"users"`
+}
+
+// New returns the snapshot of the channels chans and the users of the
+// workspace teamID, taken now.
+func New(teamID string, chans []slack.Channel, users []slack.User) *Snapshot {
+	s := &Snapshot{
+		Created:  time.Now().UTC(),
+		TeamID:   teamID,
+		Channels: make([]Channel, 0, len(chans)),
+		Users:    make([]User, 0, len(users)),
+	}
+	for _, ch := range chans {
+		s.Channels = append(s.Channels, Channel{
+			ID:       ch.ID,
+			Name:     ch.Name,
+			Private:  ch.IsPrivate,
+			Archived: ch.IsArchived,
+			Members:  ch.NumMembers,
+			Created:  int64(ch.Created),
+		})
+	}
+	for _, u := range users {
+		s.Users = append(s.Users, User{
+			ID:       u.ID,
+			Name:     u.Name,
+			RealName: u.RealName,
+			Email:    u.Profile.Email,
+			Deleted:  u.Deleted,
+			Bot:      u.IsBot,
+			Admin:    u.IsAdmin,
+		})
+	}
+	sort.Slice(s.Channels, func(i, j int) bool { return s.Channels[i].ID < s.Channels[j].ID })
+	sort.Slice(s.Users, func(i, j int) bool { return s.Users[i].ID < s.Users[j].ID })
+	return s
+}
+
+// Load reads the snapshot from the file name.
+func Load(name string) (*Snapshot, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var s Snapshot
+	if err := json.NewDecoder(f).Decode(&s); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &s, nil
+}
+
+// Write writes the snapshot to w as JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
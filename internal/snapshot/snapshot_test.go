@@ -0,0 +1,98 @@
+package snapshot
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func channel(id, name string, private, archived bool) slack.Channel {
+	var ch slack.Channel
+	ch.ID, ch.Name, ch.IsPrivate, ch.IsArchived = id, name, private, archived
+	return ch
+}
+
+func user(id, name, email string, deleted bool) slack.User {
+	return slack.User{ID: id, Name: name, Deleted: deleted, Profile: slack.UserProfile{Email: email}}
+}
+
+func TestSnapshot_roundtrip(t *testing.T) {
+	s := New("T01", []slack.Channel{channel("C02", "random", false, false), channel("C01", "general", false, false)}, []slack.User{user("U01", "alice", "alice@example.com", false)})
+	assert.Equal(t, "C01", s.Channels[0].ID, "sorted by ID")
+
+	name := filepath.Join(t.TempDir(), "snapshot.json")
+	var buf bytes.Buffer
+	require.NoError(t, s.Write(&buf))
+	require.NoError(t, os.WriteFile(name, buf.Bytes(), 0644))
+	got, err := Load(name)
+	require.NoError(t, err)
+	assert.Equal(t, s.TeamID, got.TeamID)
+	assert.Equal(t, s.Channels, got.Channels)
+	assert.Equal(t, s.Users, got.Users)
+	assert.True(t, s.Created.Equal(got.Created))
+}
+
+func TestCompare(t *testing.T) {
+	old := New("T01",
+		[]slack.Channel{
+			channel("C01", "general", false, false),
+			channel("C02", "old-name", false, false),
+			channel("C03", "project", false, false),
+			channel("C04", "gone", false, false),
+			channel("C05", "secret", false, true),
+		},
+		[]slack.User{
+			user("U01", "alice", "alice@example.com", false),
+			user("U02", "bob", "bob@example.com", false),
+			user("U03", "carol", "carol@example.com", true),
+		},
+	)
+	old.Created = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	new := New("T01",
+		[]slack.Channel{
+			channel("C01", "general", false, false),
+			channel("C02", "new-name", true, false),
+			channel("C03", "project", false, true),
+			channel("C05", "secret", false, false),
+			channel("C06", "launch", false, false),
+		},
+		[]slack.User{
+			user("U01", "alice", "alice@new.example.com", false),
+			user("U02", "bob", "bob@example.com", true),
+			user("U03", "carol", "carol@example.com", false),
+			user("U04", "dave", "", false),
+		},
+	)
+	new.Created = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	d := Compare(old, new)
+	want := []Change{
+		{Object: ObjChannel, ID: "C02", Name: "new-name", Kind: Renamed, Old: "old-name", New: "new-name"},
+		{Object: ObjChannel, ID: "C02", Name: "new-name", Kind: MadePrivate},
+		{Object: ObjChannel, ID: "C03", Name: "project", Kind: Archived},
+		{Object: ObjChannel, ID: "C04", Name: "gone", Kind: Removed},
+		{Object: ObjChannel, ID: "C05", Name: "secret", Kind: Unarchived},
+		{Object: ObjChannel, ID: "C06", Name: "launch", Kind: Added},
+		{Object: ObjUser, ID: "U01", Name: "alice", Kind: EmailChanged, Old: "alice@example.com", New: "alice@new.example.com"},
+		{Object: ObjUser, ID: "U02", Name: "bob", Kind: Deactivated},
+		{Object: ObjUser, ID: "U03", Name: "carol", Kind: Reactivated},
+		{Object: ObjUser, ID: "U04", Name: "dave", Kind: Added},
+	}
+	assert.Equal(t, want, d.Changes)
+	assert.Empty(t, Compare(new, new).Changes)
+
+	var buf bytes.Buffer
+	require.NoError(t, d.WriteText(&buf))
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "Changes from 2024-01-01T00:00:00Z to 2024-02-01T00:00:00Z: 10\n"), out)
+	assert.Contains(t, out, "#new-name")
+	assert.Contains(t, out, "old-name -> new-name")
+	assert.Contains(t, out, "@bob")
+}
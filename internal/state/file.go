@@ -0,0 +1,37 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// File keeps the state in the local file.
+type File string
+
+func (f File) Load(ctx context.Context) ([]string, error) {
+	data, err := os.ReadFile(string(f))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return decode(data), nil
+}
+
+func (f File) Save(ctx context.Context, ids []string) error {
+	return os.WriteFile(string(f), encode(ids), 0644)
+}
+
+func (f File) Clear(ctx context.Context) error {
+	if err := os.Remove(string(f)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (f File) String() string {
+	return string(f)
+}
@@ -0,0 +1,203 @@
+package state
+
+// In this file: minimal Redis client, that implements GET, SET and DEL with
+// the RESP2 protocol.
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defRedisPort = "6379"
+	defRedisKey  = "slackdump:pending"
+	redisTimeout = 30 * time.Second
+)
+
+// redisStore keeps the state in the Redis key.
+type redisStore struct {
+	addr     string
+	tls      bool
+	username string
+	password string
+	db       int
+	key      string
+}
+
+func newRedis(location string) (*redisStore, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid redis location %q, expected redis://host[:port][/db][?key=name]", location)
+	}
+	r := &redisStore{
+		addr: u.Host,
+		tls:  u.Scheme == "rediss",
+		key:  u.Query().Get("key"),
+	}
+	if u.Port() == "" {
+		r.addr = net.JoinHostPort(u.Hostname(), defRedisPort)
+	}
+	if r.key == "" {
+		r.key = defRedisKey
+	}
+	if u.User != nil {
+		r.username = u.User.Username()
+		r.password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if r.db, err = strconv.Atoi(db); err != nil || r.db < 0 {
+			return nil, fmt.Errorf("invalid redis database number: %q", db)
+		}
+	}
+	return r, nil
+}
+
+func (r *redisStore) Load(ctx context.Context) ([]string, error) {
+	v, err := r.do(ctx, "GET", r.key)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, nil
+	}
+	data, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected reply to GET: %v", v)
+	}
+	return decode(data), nil
+}
+
+func (r *redisStore) Save(ctx context.Context, ids []string) error {
+	_, err := r.do(ctx, "SET", r.key, string(encode(ids)))
+	return err
+}
+
+func (r *redisStore) Clear(ctx context.Context) error {
+	_, err := r.do(ctx, "DEL", r.key)
+	return err
+}
+
+func (r *redisStore) String() string {
+	scheme := "redis"
+	if r.tls {
+		scheme = "rediss"
+	}
+	return fmt.Sprintf("%s://%s/%d?key=%s", scheme, r.addr, r.db, url.QueryEscape(r.key))
+}
+
+// do connects to the server, authenticates, selects the database and runs
+// the command.  It returns the reply of the command.
+func (r *redisStore) do(ctx context.Context, cmd ...string) (any, error) {
+	d := net.Dialer{Timeout: redisTimeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if r.tls {
+		host, _, _ := net.SplitHostPort(r.addr)
+		td := tls.Dialer{NetDialer: &d, Config: &tls.Config{ServerName: host}}
+		conn, err = td.DialContext(ctx, "tcp", r.addr)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", r.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(redisTimeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	_ = conn.SetDeadline(deadline)
+
+	var cmds [][]string
+	if r.password != "" {
+		if r.username != "" {
+			cmds = append(cmds, []string{"AUTH", r.username, r.password})
+		} else {
+			cmds = append(cmds, []string{"AUTH", r.password})
+		}
+	}
+	if r.db != 0 {
+		cmds = append(cmds, []string{"SELECT", strconv.Itoa(r.db)})
+	}
+	cmds = append(cmds, cmd)
+
+	br := bufio.NewReader(conn)
+	var reply any
+	for _, c := range cmds {
+		if _, err := conn.Write(respCommand(c)); err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		if reply, err = readReply(br); err != nil {
+			return nil, fmt.Errorf("redis %s: %w", c[0], err)
+		}
+	}
+	return reply, nil
+}
+
+// respCommand returns the command encoded as the RESP array of bulk strings.
+func respCommand(args []string) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(a), a)
+	}
+	return []byte(sb.String())
+}
+
+// redisError is the error reply of the server.
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// readReply reads one reply.  It returns the string for the simple string,
+// int64 for the integer, []byte for the bulk string, nil for the null bulk
+// string, and redisError for the error.  Arrays are not supported, as the
+// commands used don't return them.
+func readReply(br *bufio.Reader) (any, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+	return nil, fmt.Errorf("unsupported reply: %q", line)
+}
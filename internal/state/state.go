@@ -0,0 +1,113 @@
+// Package state implements the storage of the run state, so that the run,
+// interrupted by the budget, can be resumed on another machine, i.e. on the
+// next stateless CI runner.
+//
+// The state is the list of the pending conversations, see
+// slackdump.Result.Pending, one per line, the same as the pending file, so
+// that it can be used as the input file.  It is kept in the local file, the
+// S3 object or the Redis key, see Open.
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"net/url"
+	"strings"
+
+	"github.com/rusq/slackdump/v2/internal/upload"
+)
+
+// Store is the storage of the run state.
+type Store interface {
+	// Load returns the pending conversations, or nil, if there's no state.
+	Load(ctx context.Context) ([]string, error)
+	// Save replaces the state with the pending conversations ids.
+	Save(ctx context.Context, ids []string) error
+	// Clear removes the state, once the run is complete.
+	Clear(ctx context.Context) error
+	// String returns the location of the state.
+	String() string
+}
+
+// Open returns the store for the location, which is one of:
+//   - path/to/file or file:///path/to/file - the local file;
+//   - s3://bucket/key - the S3 object, s3cfg provides the endpoint, region
+//     and credentials;
+//   - redis://[[user]:password@]host[:port][/db][?key=name] - the Redis key,
+//     "slackdump:pending" by default, use rediss:// for TLS.
+func Open(location string, s3cfg upload.Config) (Store, error) {
+	scheme, _, found := strings.Cut(location, "://")
+	if !found {
+		return File(location), nil
+	}
+	switch scheme {
+	case "file":
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, err
+		}
+		return File(u.Path), nil
+	case "s3":
+		obj, err := upload.NewObject(s3cfg, location)
+		if err != nil {
+			return nil, err
+		}
+		return s3Store{obj}, nil
+	case "redis", "rediss":
+		return newRedis(location)
+	}
+	return nil, errors.New("unsupported state location: " + location + ", expected a file, s3:// or redis:// URL")
+}
+
+// encode returns the state data for the ids.
+func encode(ids []string) []byte {
+	var buf bytes.Buffer
+	for _, id := range ids {
+		buf.WriteString(id)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// decode returns the ids from the state data, empty lines are skipped.
+func decode(data []byte) []string {
+	var ids []string
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		if id := strings.TrimSpace(s.Text()); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// s3Store keeps the state in the S3 object.
+type s3Store struct {
+	obj *upload.Object
+}
+
+func (s s3Store) Load(ctx context.Context) ([]string, error) {
+	data, err := s.obj.Get(ctx)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return decode(data), nil
+}
+
+func (s s3Store) Save(ctx context.Context, ids []string) error {
+	return s.obj.Put(ctx, encode(ids))
+}
+
+func (s s3Store) Clear(ctx context.Context) error {
+	return s.obj.Delete(ctx)
+}
+
+func (s s3Store) String() string {
+	return s.obj.String()
+}
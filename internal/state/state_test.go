@@ -0,0 +1,240 @@
+package state
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/internal/upload"
+)
+
+func TestOpen(t *testing.T) {
+	s3cfg := upload.Config{Credentials: upload.Credentials{AccessKeyID: "key", SecretAccessKey: "secret"}}
+	tests := []struct {
+		location string
+		want     string
+		wantErr  bool
+	}{
+		{"state.txt", "state.txt", false},
+		{"file:///var/lib/slackdump/state.txt", "/var/lib/slackdump/state.txt", false},
+		{"s3://ci/slackdump/state.txt", "s3://ci/slackdump/state.txt", false},
+		{"s3://ci", "", true},
+		{"redis://localhost", "redis://localhost:6379/0?key=slackdump%3Apending", false},
+		{"rediss://:secret@redis.example.com:6380/2?key=nightly", "rediss://redis.example.com:6380/2?key=nightly", false},
+		{"redis://localhost/db", "", true},
+		{"ftp://example.com/state.txt", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			got, err := Open(tt.location, s3cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+// testStore runs the store through the state lifecycle.
+func testStore(t *testing.T, st Store) {
+	t.Helper()
+	ctx := context.Background()
+	ids, err := st.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, ids, "no state")
+
+	require.NoError(t, st.Save(ctx, []string{"C01", "C02"}))
+	ids, err = st.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C01", "C02"}, ids)
+
+	require.NoError(t, st.Save(ctx, []string{"C02"}))
+	ids, err = st.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C02"}, ids)
+
+	require.NoError(t, st.Clear(ctx))
+	ids, err = st.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, ids)
+	assert.NoError(t, st.Clear(ctx), "clearing the missing state is not an error")
+}
+
+func TestFile(t *testing.T) {
+	testStore(t, File(filepath.Join(t.TempDir(), "slackdump-pending.txt")))
+}
+
+func TestS3(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		objects = make(map[string][]byte)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	st, err := Open("s3://ci/state.txt", upload.Config{
+		Endpoint:    srv.URL,
+		Credentials: upload.Credentials{AccessKeyID: "key", SecretAccessKey: "secret"},
+	})
+	require.NoError(t, err)
+	testStore(t, st)
+}
+
+// fakeRedis is the in-memory Redis server, that supports the commands used
+// by the store.
+type fakeRedis struct {
+	mu       sync.Mutex
+	password string
+	dbs      map[string]map[string]string
+}
+
+func (s *fakeRedis) serve(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+	var (
+		authed = s.password == ""
+		db     = "0"
+	)
+	for {
+		args, err := readCommand(br)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		reply := s.exec(args, &authed, &db)
+		s.mu.Unlock()
+		io.WriteString(conn, reply)
+	}
+}
+
+func (s *fakeRedis) exec(args []string, authed *bool, db *string) string {
+	cmd := strings.ToUpper(args[0])
+	if cmd == "AUTH" {
+		if args[len(args)-1] != s.password {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		*authed = true
+		return "+OK\r\n"
+	}
+	if !*authed {
+		return "-NOAUTH Authentication required.\r\n"
+	}
+	if s.dbs[*db] == nil {
+		s.dbs[*db] = make(map[string]string)
+	}
+	kv := s.dbs[*db]
+	switch cmd {
+	case "SELECT":
+		*db = args[1]
+		return "+OK\r\n"
+	case "GET":
+		v, ok := kv[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "SET":
+		kv[args[1]] = args[2]
+		return "+OK\r\n"
+	case "DEL":
+		_, ok := kv[args[1]]
+		delete(kv, args[1])
+		if ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+func readCommand(br *bufio.Reader) ([]string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = br.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedis(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	srv := &fakeRedis{password: "secret", dbs: make(map[string]map[string]string)}
+	go srv.serve(l)
+
+	st, err := Open("redis://:secret@"+l.Addr().String()+"/3?key=ci:pending", upload.Config{})
+	require.NoError(t, err)
+	testStore(t, st)
+
+	require.NoError(t, st.Save(context.Background(), []string{"C01"}))
+	srv.mu.Lock()
+	assert.Equal(t, "C01\n", srv.dbs["3"]["ci:pending"])
+	srv.mu.Unlock()
+
+	t.Run("wrong password", func(t *testing.T) {
+		st, err := Open("redis://:wrong@"+l.Addr().String(), upload.Config{})
+		require.NoError(t, err)
+		_, err = st.Load(context.Background())
+		assert.ErrorContains(t, err, "WRONGPASS")
+	})
+}
@@ -0,0 +1,80 @@
+package stats
+
+// In this file: the text corpus for the NLP pipelines.
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/mrkdwn"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// corpusHeader is the header of the corpus columns.
+var corpusHeader = []string{"channel_id", "channel", "user_id", "user", "ts", "thread_ts", "text"}
+
+// Corpus writes the messages of the archive ar to w as the tab-separated
+// corpus, one message per line, with the header, see corpusHeader.  The text
+// is normalized with mrkdwn.Normalize.  The bot messages, the channel events
+// and the messages without the text are skipped.  It returns the number of
+// the messages written.
+func Corpus(ctx context.Context, ar *archive.Archive, w io.Writer) (int, error) {
+	users, err := ar.Users()
+	if err != nil {
+		return 0, err
+	}
+	uidx := users.IndexByID()
+
+	bw := bufio.NewWriter(w)
+	if err := writeRow(bw, corpusHeader); err != nil {
+		return 0, err
+	}
+	var (
+		n     int
+		names = make(map[string]string) // channel ID to name
+	)
+	if err := Walk(ctx, ar, func(ch *slack.Channel, m *types.Message) error {
+		if m.User == "" || m.SubType != "" && m.SubType != slack.MsgSubTypeThreadBroadcast {
+			return nil
+		}
+		text := mrkdwn.Normalize(m.Text)
+		if text == "" {
+			return nil
+		}
+		name, ok := names[ch.ID]
+		if !ok {
+			name = channelName(uidx, ch)
+			names[ch.ID] = name
+		}
+		var user string
+		if u, ok := uidx[m.User]; ok {
+			user = u.Name
+		}
+		n++
+		return writeRow(bw, []string{ch.ID, name, m.User, user, m.Timestamp, m.ThreadTimestamp, text})
+	}); err != nil {
+		return n, err
+	}
+	return n, bw.Flush()
+}
+
+// writeRow writes the tab-separated row, the tabs and the line breaks in the
+// values are replaced with spaces.
+func writeRow(w io.StringWriter, row []string) error {
+	vals := make([]string, len(row))
+	for i, v := range row {
+		vals[i] = strings.Map(func(r rune) rune {
+			if r == '\t' || r == '\n' || r == '\r' {
+				return ' '
+			}
+			return r
+		}, v)
+	}
+	_, err := w.WriteString(strings.Join(vals, "\t") + "\n")
+	return err
+}
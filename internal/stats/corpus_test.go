@@ -0,0 +1,22 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorpus(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := Corpus(context.Background(), testArchive(t), &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	want := "channel_id\tchannel\tuser_id\tuser\tts\tthread_ts\ttext\n" +
+		"C01\t#general\tU01\talice\t1672531200.000100\t1672531200.000100\thello :wave: at 12:30:45\n" +
+		"C01\t#general\tU02\tbob\t1672531300.000100\t1672531200.000100\thi :wave:\n" +
+		"C01\t#general\tU02\tbob\t1675209600.000100\t\tfallback :x:\n"
+	assert.Equal(t, want, buf.String())
+}
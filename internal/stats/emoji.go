@@ -0,0 +1,263 @@
+package stats
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// EmojiUse is the number of uses of the emoji by the user in the channel
+// during the period.
+type EmojiUse struct {
+	Period      string
+	ChannelID   string
+	ChannelName string
+	// UserID is the user, who reacted with, or posted the emoji.  It is empty
+	// for the reactions of the users, that were truncated by the API.
+	UserID    string
+	UserName  string
+	Emoji     string // emoji name, without the skin tone.
+	Reactions int    // number of reactions.
+	Inline    int    // number of uses in the message text.
+}
+
+// EmojiStats is the emoji usage statistics of the archive.
+type EmojiStats struct {
+	Period Period
+	// Uses is sorted by the period, channel, user and emoji.
+	Uses []EmojiUse
+}
+
+type emojiKey struct {
+	period, channelID, userID, emoji string
+}
+
+// Emoji computes the emoji usage statistics over the archive ar, aggregated
+// by the period p in the time zone loc.  The reactions are counted at the time of the message,
+// as the time of the reaction is not known.  The skin tone variants are
+// counted as the base emoji.
+func Emoji(ctx context.Context, ar *archive.Archive, p Period, loc *time.Location) (*EmojiStats, error) {
+	users, err := ar.Users()
+	if err != nil {
+		return nil, err
+	}
+	uidx := users.IndexByID()
+
+	var (
+		counts = make(map[emojiKey]*EmojiUse)
+		names  = make(map[string]string) // channel ID to name
+	)
+	use := func(ch *slack.Channel, period, userID, emoji string) *EmojiUse {
+		k := emojiKey{period, ch.ID, userID, baseEmoji(emoji)}
+		u, ok := counts[k]
+		if !ok {
+			if _, ok := names[ch.ID]; !ok {
+				names[ch.ID] = channelName(uidx, ch)
+			}
+			u = &EmojiUse{Period: period, ChannelID: ch.ID, ChannelName: names[ch.ID], UserID: userID, Emoji: k.emoji}
+			if userID != "" {
+				u.UserName = uidx.Username(userID)
+			}
+			counts[k] = u
+		}
+		return u
+	}
+	if err := Walk(ctx, ar, func(ch *slack.Channel, m *types.Message) error {
+		t, err := m.Datetime()
+		if err != nil {
+			return nil // not a message.
+		}
+		period := p.Bucket(t.In(loc))
+		for _, r := range m.Reactions {
+			for _, uid := range r.Users {
+				use(ch, period, uid, r.Name).Reactions++
+			}
+			if n := r.Count - len(r.Users); n > 0 {
+				use(ch, period, "", r.Name).Reactions += n
+			}
+		}
+		for _, name := range inlineEmoji(&m.Message) {
+			use(ch, period, m.User, name).Inline++
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	st := &EmojiStats{Period: p, Uses: make([]EmojiUse, 0, len(counts))}
+	for _, u := range counts {
+		st.Uses = append(st.Uses, *u)
+	}
+	sort.Slice(st.Uses, func(i, j int) bool {
+		a, b := st.Uses[i], st.Uses[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		if a.ChannelID != b.ChannelID {
+			return a.ChannelID < b.ChannelID
+		}
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.Emoji < b.Emoji
+	})
+	return st, nil
+}
+
+// channelName returns the display name of the channel, the archives of the
+// older versions don't have the normalized name.
+func channelName(uidx structures.UserIndex, ch *slack.Channel) string {
+	c := *ch
+	if c.NameNormalized == "" {
+		c.NameNormalized = c.Name
+	}
+	return uidx.ChannelName(&c)
+}
+
+// baseEmoji strips the skin tone from the emoji name, i.e.
+// "thumbsup::skin-tone-2" becomes "thumbsup".
+func baseEmoji(name string) string {
+	name, _, _ = strings.Cut(name, "::")
+	return name
+}
+
+// reEmoji matches the emoji code in the message text.
+var reEmoji = regexp.MustCompile(`:([a-z0-9_+'-]+):`)
+
+// inlineEmoji returns the names of the emoji in the message.  The rich text
+// blocks are used, if the message has them, otherwise, the emoji codes in the
+// text are matched.
+func inlineEmoji(m *slack.Message) []string {
+	var (
+		res      []string
+		richText bool
+	)
+	for _, b := range m.Blocks.BlockSet {
+		rt, ok := b.(*slack.RichTextBlock)
+		if !ok {
+			continue
+		}
+		richText = true
+		for _, e := range rt.Elements {
+			sec, ok := e.(*slack.RichTextSection)
+			if !ok {
+				continue
+			}
+			for _, se := range sec.Elements {
+				if em, ok := se.(*slack.RichTextSectionEmojiElement); ok {
+					res = append(res, em.Name)
+				}
+			}
+		}
+	}
+	if richText {
+		return res
+	}
+	for _, loc := range reEmoji.FindAllStringSubmatchIndex(m.Text, -1) {
+		// skip the time and the like, i.e. "12:30:45".
+		if loc[0] > 0 && isAlnum(m.Text[loc[0]-1]) {
+			continue
+		}
+		name := m.Text[loc[2]:loc[3]]
+		if strings.HasPrefix(name, "skin-tone-") {
+			continue
+		}
+		res = append(res, name)
+	}
+	return res
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// EmojiTotal is the total number of uses of the emoji.
+type EmojiTotal struct {
+	Emoji     string
+	Reactions int
+	Inline    int
+}
+
+// Totals returns the total uses of each emoji, the most used first.
+func (st *EmojiStats) Totals() []EmojiTotal {
+	idx := make(map[string]int)
+	var res []EmojiTotal
+	for _, u := range st.Uses {
+		i, ok := idx[u.Emoji]
+		if !ok {
+			i = len(res)
+			idx[u.Emoji] = i
+			res = append(res, EmojiTotal{Emoji: u.Emoji})
+		}
+		res[i].Reactions += u.Reactions
+		res[i].Inline += u.Inline
+	}
+	sort.Slice(res, func(i, j int) bool {
+		ti, tj := res[i].Reactions+res[i].Inline, res[j].Reactions+res[j].Inline
+		if ti != tj {
+			return ti > tj
+		}
+		return res[i].Emoji < res[j].Emoji
+	})
+	return res
+}
+
+// ToText writes the top most used emoji to w as the table, if top is 0, all
+// emoji are written.
+func (st *EmojiStats) ToText(w io.Writer, top int) error {
+	const strFormat = "%s\t%s\t%s\t%s\n"
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer writer.Flush()
+
+	if _, err := fmt.Fprintf(writer, strFormat, "Emoji", "Reactions", "Inline", "Total"); err != nil {
+		return fmt.Errorf("writer error: %w", err)
+	}
+	totals := st.Totals()
+	if top > 0 && top < len(totals) {
+		totals = totals[:top]
+	}
+	for _, t := range totals {
+		if _, err := fmt.Fprintf(writer, "%s\t%d\t%d\t%d\n", ":"+t.Emoji+":", t.Reactions, t.Inline, t.Reactions+t.Inline); err != nil {
+			return fmt.Errorf("writer error: %w", err)
+		}
+	}
+	return nil
+}
+
+// ToCSV writes the statistics to w as CSV, one row per EmojiUse, with the
+// header.  The period column is omitted, if the statistics are not broken
+// down by time.
+func (st *EmojiStats) ToCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"period", "channel_id", "channel", "user_id", "user", "emoji", "reactions", "inline"}
+	if st.Period == All {
+		header = header[1:]
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, u := range st.Uses {
+		row := []string{u.Period, u.ChannelID, u.ChannelName, u.UserID, u.UserName, u.Emoji, strconv.Itoa(u.Reactions), strconv.Itoa(u.Inline)}
+		if st.Period == All {
+			row = row[1:]
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
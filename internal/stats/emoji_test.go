@@ -0,0 +1,106 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/archive"
+)
+
+var testFS = fstest.MapFS{
+	"channels.json": {Data: []byte(`[{"id":"C01","name":"general"}]`)},
+	"users.json":    {Data: []byte(`[{"id":"U01","name":"alice"},{"id":"U02","name":"bob"}]`)},
+	"general/2023-01-01.json": {Data: []byte(`[
+		{"type":"message","user":"U01","text":"hello :wave: at 12:30:45","ts":"1672531200.000100","thread_ts":"1672531200.000100","reply_count":1,
+		 "reactions":[{"name":"wave","count":3,"users":["U01","U02"]},{"name":"+1::skin-tone-2","count":1,"users":["U02"]}]},
+		{"type":"message","user":"U02","text":"hi :wave::skin-tone-3:","ts":"1672531300.000100","thread_ts":"1672531200.000100"}
+	]`)},
+	"general/2023-02-01.json": {Data: []byte(`[
+		{"type":"message","user":"U02","text":"fallback :x:","ts":"1675209600.000100",
+		 "blocks":[{"type":"rich_text","elements":[{"type":"rich_text_section","elements":[
+			{"type":"text","text":"party "},{"type":"emoji","name":"tada"}
+		 ]}]}]}
+	]`)},
+}
+
+func testArchive(t *testing.T) *archive.Archive {
+	t.Helper()
+	ar, err := archive.New(testFS, "test")
+	require.NoError(t, err)
+	return ar
+}
+
+func TestEmoji(t *testing.T) {
+	st, err := Emoji(context.Background(), testArchive(t), Month, time.UTC)
+	require.NoError(t, err)
+	want := []EmojiUse{
+		{Period: "2023-01", ChannelID: "C01", ChannelName: "#general", UserID: "", Emoji: "wave", Reactions: 1},
+		{Period: "2023-01", ChannelID: "C01", ChannelName: "#general", UserID: "U01", UserName: "alice", Emoji: "wave", Reactions: 1, Inline: 1},
+		{Period: "2023-01", ChannelID: "C01", ChannelName: "#general", UserID: "U02", UserName: "bob", Emoji: "+1", Reactions: 1},
+		{Period: "2023-01", ChannelID: "C01", ChannelName: "#general", UserID: "U02", UserName: "bob", Emoji: "wave", Reactions: 1, Inline: 1},
+		{Period: "2023-02", ChannelID: "C01", ChannelName: "#general", UserID: "U02", UserName: "bob", Emoji: "tada", Inline: 1},
+	}
+	assert.Equal(t, want, st.Uses)
+
+	assert.Equal(t, []EmojiTotal{
+		{Emoji: "wave", Reactions: 3, Inline: 2},
+		{Emoji: "+1", Reactions: 1},
+		{Emoji: "tada", Inline: 1},
+	}, st.Totals())
+}
+
+func TestEmojiStats_ToCSV(t *testing.T) {
+	st := &EmojiStats{Period: All, Uses: []EmojiUse{
+		{ChannelID: "C01", ChannelName: "#general", UserID: "U01", UserName: "alice", Emoji: "wave", Reactions: 2, Inline: 1},
+	}}
+	var buf bytes.Buffer
+	require.NoError(t, st.ToCSV(&buf))
+	assert.Equal(t, "channel_id,channel,user_id,user,emoji,reactions,inline\nC01,#general,U01,alice,wave,2,1\n", buf.String())
+
+	st.Period = Day
+	st.Uses[0].Period = "2023-01-01"
+	buf.Reset()
+	require.NoError(t, st.ToCSV(&buf))
+	assert.Contains(t, buf.String(), "period,channel_id")
+	assert.Contains(t, buf.String(), "2023-01-01,C01,")
+}
+
+func TestInlineEmoji(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"no emoji", nil},
+		{":smile: and :+1::skin-tone-2:", []string{"smile", "+1"}},
+		{"at 12:30:45 and http://x:80:", nil},
+		{":a::b:", []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, inlineEmoji(&slack.Message{Msg: slack.Msg{Text: tt.text}}), tt.text)
+	}
+}
+
+func TestPeriod_Bucket(t *testing.T) {
+	// 2023-01-31 23:30 UTC is already February in Berlin.
+	ts := time.Date(2023, 1, 31, 23, 30, 0, 0, time.UTC)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	assert.Equal(t, "2023-01", Month.Bucket(ts))
+	assert.Equal(t, "2023-02-01", Day.Bucket(ts.In(berlin)))
+	assert.Equal(t, "", All.Bucket(ts))
+}
+
+func TestParsePeriod(t *testing.T) {
+	p, err := ParsePeriod("year")
+	require.NoError(t, err)
+	assert.Equal(t, Year, p)
+	_, err = ParsePeriod("week")
+	assert.Error(t, err)
+}
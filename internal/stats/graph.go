@@ -0,0 +1,232 @@
+package stats
+
+// In this file: the social graph of the mentions, replies and reactions.
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// GraphNode is the user in the social graph.
+type GraphNode struct {
+	ID       string
+	Name     string // username, empty for the users, that are not in the archive.
+	RealName string
+	Messages int // number of messages posted.
+}
+
+// GraphEdge is the interaction of the user Source with the user Target.
+type GraphEdge struct {
+	Source    string
+	Target    string
+	Mentions  int // mentions of the Target in the messages of the Source.
+	Replies   int // replies of the Source in the threads of the Target.
+	Reactions int // reactions of the Source to the messages of the Target.
+}
+
+// Weight returns the total number of the interactions.
+func (e GraphEdge) Weight() int {
+	return e.Mentions + e.Replies + e.Reactions
+}
+
+// Graph is the directed social graph of the users of the archive.  Nodes are
+// sorted by ID, edges by the source and the target.
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// reMention matches the user mention in the message text, i.e. "<@U01>" or
+// "<@U01|alice>".
+var reMention = regexp.MustCompile(`<@([UW][A-Z0-9]+)(?:\|[^>]*)?>`)
+
+// SocialGraph builds the social graph of the archive ar: the users are the
+// nodes, the edges are weighted by the number of mentions, thread replies
+// and reactions.  The interactions of the user with themselves are not
+// counted.
+func SocialGraph(ctx context.Context, ar *archive.Archive) (*Graph, error) {
+	users, err := ar.Users()
+	if err != nil {
+		return nil, err
+	}
+	uidx := users.IndexByID()
+
+	var (
+		nodes = make(map[string]*GraphNode)
+		edges = make(map[[2]string]*GraphEdge)
+		// parents are the authors of the thread parent messages, by the
+		// channel ID and the thread timestamp.
+		parents = make(map[[2]string]string)
+	)
+	node := func(id string) *GraphNode {
+		u, known := uidx[id]
+		if known {
+			// the user may be referenced by the enterprise ID.
+			id = u.ID
+		}
+		n, ok := nodes[id]
+		if !ok {
+			n = &GraphNode{ID: id}
+			if known {
+				n.Name, n.RealName = u.Name, u.RealName
+			}
+			nodes[id] = n
+		}
+		return n
+	}
+	edge := func(src, dst string) *GraphEdge {
+		if src == "" || dst == "" {
+			return nil
+		}
+		s, d := node(src), node(dst)
+		if s.ID == d.ID {
+			return nil
+		}
+		k := [2]string{s.ID, d.ID}
+		e, ok := edges[k]
+		if !ok {
+			e = &GraphEdge{Source: s.ID, Target: d.ID}
+			edges[k] = e
+		}
+		return e
+	}
+	if err := Walk(ctx, ar, func(ch *slack.Channel, m *types.Message) error {
+		if m.User == "" || m.SubType != "" && m.SubType != slack.MsgSubTypeThreadBroadcast {
+			return nil // bot messages and channel events.
+		}
+		node(m.User).Messages++
+		switch {
+		case m.ThreadTimestamp == "" || m.ThreadTimestamp == m.Timestamp:
+			parents[[2]string{ch.ID, m.Timestamp}] = m.User
+		default:
+			if e := edge(m.User, parents[[2]string{ch.ID, m.ThreadTimestamp}]); e != nil {
+				e.Replies++
+			}
+		}
+		for _, sm := range reMention.FindAllStringSubmatch(m.Text, -1) {
+			if e := edge(m.User, sm[1]); e != nil {
+				e.Mentions++
+			}
+		}
+		for _, r := range m.Reactions {
+			for _, uid := range r.Users {
+				if e := edge(uid, m.User); e != nil {
+					e.Reactions++
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	g := &Graph{Nodes: make([]GraphNode, 0, len(nodes)), Edges: make([]GraphEdge, 0, len(edges))}
+	for _, n := range nodes {
+		g.Nodes = append(g.Nodes, *n)
+	}
+	for _, e := range edges {
+		g.Edges = append(g.Edges, *e)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].Source != g.Edges[j].Source {
+			return g.Edges[i].Source < g.Edges[j].Source
+		}
+		return g.Edges[i].Target < g.Edges[j].Target
+	})
+	return g, nil
+}
+
+// graphML is the GraphML document, see http://graphml.graphdrawing.org.
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string           `xml:"id,attr"`
+	EdgeDefault string           `xml:"edgedefault,attr"`
+	Nodes       []graphMLElement `xml:"node"`
+	Edges       []graphMLElement `xml:"edge"`
+}
+
+type graphMLElement struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr,omitempty"`
+	Target string        `xml:"target,attr,omitempty"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ToGraphML writes the graph to w in the GraphML format, that the network
+// analysis tools, i.e. Gephi, yEd or NetworkX, read.
+func (g *Graph) ToGraphML(w io.Writer) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{"name", "node", "name", "string"},
+			{"real_name", "node", "real_name", "string"},
+			{"messages", "node", "messages", "int"},
+			{"weight", "edge", "weight", "int"},
+			{"mentions", "edge", "mentions", "int"},
+			{"replies", "edge", "replies", "int"},
+			{"reactions", "edge", "reactions", "int"},
+		},
+		Graph: graphMLGraph{ID: "slack", EdgeDefault: "directed"},
+	}
+	for _, n := range g.Nodes {
+		el := graphMLElement{ID: n.ID, Data: []graphMLData{{"messages", strconv.Itoa(n.Messages)}}}
+		if n.RealName != "" {
+			el.Data = append([]graphMLData{{"real_name", n.RealName}}, el.Data...)
+		}
+		if n.Name != "" {
+			el.Data = append([]graphMLData{{"name", n.Name}}, el.Data...)
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, el)
+	}
+	for i, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLElement{
+			ID:     "e" + strconv.Itoa(i),
+			Source: e.Source,
+			Target: e.Target,
+			Data: []graphMLData{
+				{"weight", strconv.Itoa(e.Weight())},
+				{"mentions", strconv.Itoa(e.Mentions)},
+				{"replies", strconv.Itoa(e.Replies)},
+				{"reactions", strconv.Itoa(e.Reactions)},
+			},
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
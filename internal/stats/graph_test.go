@@ -0,0 +1,50 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/archive"
+)
+
+func TestSocialGraph(t *testing.T) {
+	fsys := fstest.MapFS{
+		"channels.json":           testFS["channels.json"],
+		"users.json":              {Data: []byte(`[{"id":"U01","name":"alice","real_name":"Alice"},{"id":"U02","name":"bob","enterprise_user":{"id":"W02"}}]`)},
+		"general/2023-01-01.json": testFS["general/2023-01-01.json"],
+		"general/2023-02-01.json": {Data: []byte(`[
+			{"type":"message","user":"W02","text":"ping <@U01|alice> and <@U03>, <@U02>","ts":"1675209600.000100"},
+			{"type":"message","subtype":"channel_join","user":"U03","text":"<@U03> has joined the channel","ts":"1675209700.000100"}
+		]`)},
+	}
+	ar, err := archive.New(fsys, "test")
+	require.NoError(t, err)
+
+	g, err := SocialGraph(context.Background(), ar)
+	require.NoError(t, err)
+	assert.Equal(t, []GraphNode{
+		{ID: "U01", Name: "alice", RealName: "Alice", Messages: 1},
+		{ID: "U02", Name: "bob", Messages: 2},
+		{ID: "U03"},
+	}, g.Nodes)
+	assert.Equal(t, []GraphEdge{
+		{Source: "U02", Target: "U01", Mentions: 1, Replies: 1, Reactions: 2},
+		{Source: "U02", Target: "U03", Mentions: 1},
+	}, g.Edges)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.ToGraphML(&buf))
+	var doc graphML
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Graph.Edges, 2)
+	assert.Equal(t, graphMLElement{ID: "e0", Source: "U02", Target: "U01", Data: []graphMLData{
+		{"weight", "4"}, {"mentions", "1"}, {"replies", "1"}, {"reactions", "2"},
+	}}, doc.Graph.Edges[0])
+	assert.Equal(t, []graphMLData{{"name", "alice"}, {"real_name", "Alice"}, {"messages", "1"}}, doc.Graph.Nodes[0].Data)
+}
@@ -0,0 +1,93 @@
+// Package stats computes the usage statistics over the messages of the
+// archive.
+//
+// Walk feeds every message of the archive, including the thread replies, to
+// the collector, the collector aggregates the counts by the channel, the user
+// and the time period, i.e. the month of the message.
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Period is the time period, the statistics are aggregated by.
+type Period string
+
+const (
+	Day   Period = "day"
+	Month Period = "month"
+	Year  Period = "year"
+	All   Period = "all" // no time breakdown.
+)
+
+// ParsePeriod parses the period name.
+func ParsePeriod(s string) (Period, error) {
+	switch p := Period(s); p {
+	case Day, Month, Year, All:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid period: %q, must be one of: day, month, year, all", s)
+}
+
+// Bucket returns the name of the period the time t belongs to, i.e.
+// "2023-01" for the Month, in the time zone of t.
+func (p Period) Bucket(t time.Time) string {
+	switch p {
+	case Day:
+		return t.Format("2006-01-02")
+	case Month:
+		return t.Format("2006-01")
+	case Year:
+		return t.Format("2006")
+	}
+	return ""
+}
+
+// WalkFunc is called for each message m of the channel ch.
+type WalkFunc func(ch *slack.Channel, m *types.Message) error
+
+// Walk calls fn for every message of the archive ar, the thread replies
+// follow their parent message.  The channels that are listed, but have no
+// messages in the archive, are skipped.
+func Walk(ctx context.Context, ar *archive.Archive, fn WalkFunc) error {
+	chans, err := ar.Channels()
+	if err != nil {
+		return err
+	}
+	for i := range chans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cnv, err := ar.Conversation(chans[i].ID)
+		if err != nil {
+			if errors.Is(err, archive.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if err := walkMessages(&chans[i], cnv.Messages, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkMessages(ch *slack.Channel, msgs []types.Message, fn WalkFunc) error {
+	for i := range msgs {
+		if err := fn(ch, &msgs[i]); err != nil {
+			return err
+		}
+		if err := walkMessages(ch, msgs[i].ThreadReplies, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
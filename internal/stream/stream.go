@@ -0,0 +1,303 @@
+// Package stream implements the NDJSON event stream of the archived records,
+// that is emitted while archiving, so that it can be piped into other tools,
+// i.e. jq, vector.dev or Kafka producers.
+//
+// Each line is one JSON event:
+//
+//	{"type":"user","data":{...slack.User}}
+//	{"type":"channel","channel_id":"C01","data":{...slack.Channel}}
+//	{"type":"message","channel_id":"C01","ts":"1672531200.000100","data":{...slack.Message}}
+//	{"type":"file","channel_id":"C01","ts":"1672531200.000100","data":{...slack.File}}
+//
+// Thread replies are emitted as separate message events.  The file event is
+// emitted after the event of the message that it is attached to, ts is the
+// timestamp of that message.
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Scheme is the prefix of the output target, that enables the stream.
+const Scheme = "jsonl://"
+
+// Event types.
+const (
+	TypeMessage = "message"
+	TypeFile    = "file"
+	TypeUser    = "user"
+	TypeChannel = "channel"
+)
+
+// Event is the stream event.
+type Event struct {
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"` // time of the event
+	ChannelID string    `json:"channel_id,omitempty"`
+	TS        string    `json:"ts,omitempty"` // message timestamp
+	Data      any       `json:"data"`
+}
+
+// IsTarget returns true if the output target s is the stream target.
+func IsTarget(s string) bool {
+	return strings.HasPrefix(strings.ToLower(s), Scheme)
+}
+
+// IsStdout returns true if the stream target s is the Standard Output.
+func IsStdout(s string) bool {
+	if !IsTarget(s) {
+		return false
+	}
+	name := s[len(Scheme):]
+	return name == "stdout" || name == "-"
+}
+
+// Open opens the stream target, which is "jsonl://stdout", "jsonl://stderr",
+// or "jsonl://<filename>".
+func Open(target string) (*Writer, error) {
+	if !IsTarget(target) {
+		return nil, fmt.Errorf("invalid stream target %q, must start with %s", target, Scheme)
+	}
+	name := target[len(Scheme):]
+	switch name {
+	case "":
+		return nil, fmt.Errorf("invalid stream target %q, expected %sstdout or %s<filename>", target, Scheme, Scheme)
+	case "stdout", "-":
+		return NewWriter(nopCloser{os.Stdout}), nil
+	case "stderr":
+		return NewWriter(nopCloser{os.Stderr}), nil
+	}
+	f, err := os.Create(fsadapter.LongPath(name))
+	if err != nil {
+		return nil, err
+	}
+	return NewWriter(f), nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// Writer writes the events.  It is safe for concurrent use.  Write errors do
+// not interrupt the archiving: the first error is recorded, and the
+// subsequent events are discarded.  The nil Writer discards all events.
+type Writer struct {
+	mu  sync.Mutex
+	wc  io.WriteCloser
+	enc *json.Encoder
+	err error
+	now func() time.Time
+	// only is the set of the event types to write, nil - all.
+	only map[string]bool
+
+	policy Policy
+	bw     *bufio.Writer // write buffer, nil if unbuffered.
+	timer  *time.Timer   // flush timer, nil if the buffer was flushed.
+	closed bool
+}
+
+// Policy is the write policy of the Writer, that trades the durability of
+// the events for the throughput.  The zero Policy writes each event as it is
+// emitted, and leaves the syncing to the operating system.
+type Policy struct {
+	// BufferSize is the size of the write buffer in bytes, the events are
+	// written, once the buffer is full.  0 disables the buffering.
+	BufferSize int
+	// FlushInterval is the maximum time, that the buffered events wait
+	// before they are written, 0 waits until the buffer is full.
+	FlushInterval time.Duration
+	// Sync writes the buffered events, and syncs the file to the disk at the
+	// end of each chunk, the events of one call, i.e. one page of the
+	// messages, so that the crash loses at most the chunk being written.
+	// The standard output and error are not synced.
+	Sync bool
+}
+
+// Validate returns an error, if the policy is invalid.
+func (p Policy) Validate() error {
+	if p.BufferSize < 0 {
+		return errors.New("stream buffer size can't be negative")
+	}
+	if p.FlushInterval < 0 {
+		return errors.New("stream flush interval can't be negative")
+	}
+	return nil
+}
+
+// NewWriter creates the Writer, that writes events to wc.
+func NewWriter(wc io.WriteCloser) *Writer {
+	return &Writer{wc: wc, enc: json.NewEncoder(wc), now: time.Now}
+}
+
+// SetPolicy sets the write policy of the Writer.  It must be called before
+// the first event.
+func (w *Writer) SetPolicy(p Policy) {
+	w.policy = p
+	if p.BufferSize > 0 {
+		w.bw = bufio.NewWriterSize(w.wc, p.BufferSize)
+		w.enc = json.NewEncoder(w.bw)
+	}
+}
+
+// IsType returns true if typ is one of the event types.
+func IsType(typ string) bool {
+	switch typ {
+	case TypeMessage, TypeFile, TypeUser, TypeChannel:
+		return true
+	}
+	return false
+}
+
+// Only restricts the events, that are written, to the event types types,
+// the rest are discarded.  It must be called before the first event.
+func (w *Writer) Only(types ...string) {
+	w.only = make(map[string]bool, len(types))
+	for _, t := range types {
+		w.only[t] = true
+	}
+}
+
+// Wants returns true if the events of the type typ are written.
+func (w *Writer) Wants(typ string) bool {
+	return w != nil && (w.only == nil || w.only[typ])
+}
+
+// Users emits the user events.
+func (w *Writer) Users(users types.Users) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range users {
+		w.emit(Event{Type: TypeUser, Data: users[i]})
+	}
+	w.endChunk()
+}
+
+// Channel emits the channel event.
+func (w *Writer) Channel(ch slack.Channel) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.emit(Event{Type: TypeChannel, ChannelID: ch.ID, Data: ch})
+	w.endChunk()
+}
+
+// Messages emits the message events for the messages of the channel,
+// including the thread replies, and the file events for their files.
+func (w *Writer) Messages(channelID string, msgs []types.Message) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages(channelID, msgs)
+	w.endChunk()
+}
+
+func (w *Writer) messages(channelID string, msgs []types.Message) {
+	for i := range msgs {
+		m := msgs[i].Message
+		w.emit(Event{Type: TypeMessage, ChannelID: channelID, TS: m.Timestamp, Data: m})
+		w.files(channelID, m.Timestamp, m.Files)
+		w.messages(channelID, msgs[i].ThreadReplies)
+	}
+}
+
+func (w *Writer) files(channelID string, ts string, files []slack.File) {
+	for i := range files {
+		w.emit(Event{Type: TypeFile, ChannelID: channelID, TS: ts, Data: files[i]})
+	}
+}
+
+// emit writes the event, the caller must hold the lock.
+func (w *Writer) emit(ev Event) {
+	if w.err != nil || !w.Wants(ev.Type) {
+		return
+	}
+	ev.Time = w.now()
+	w.err = w.enc.Encode(ev)
+	if w.bw != nil && w.policy.FlushInterval > 0 && w.timer == nil && w.bw.Buffered() > 0 {
+		w.timer = time.AfterFunc(w.policy.FlushInterval, w.timedFlush)
+	}
+}
+
+// endChunk writes the buffered events and syncs the file, if the policy
+// says so, the caller must hold the lock.
+func (w *Writer) endChunk() {
+	if !w.policy.Sync {
+		return
+	}
+	w.flush()
+	if w.err != nil {
+		return
+	}
+	if f, ok := w.wc.(interface{ Sync() error }); ok {
+		w.err = f.Sync()
+	}
+}
+
+// flush writes the buffered events, the caller must hold the lock.
+func (w *Writer) flush() {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if w.bw == nil || w.err != nil {
+		return
+	}
+	w.err = w.bw.Flush()
+}
+
+func (w *Writer) timedFlush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.timer = nil
+	w.flush()
+}
+
+// Err returns the first write error.
+func (w *Writer) Err() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close closes the underlying writer, and returns the first write error, if
+// any.
+func (w *Writer) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.endChunk()
+	w.flush()
+	w.closed = true
+	if err := w.wc.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
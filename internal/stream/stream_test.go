@@ -0,0 +1,183 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+type bufCloser struct{ bytes.Buffer }
+
+func (*bufCloser) Close() error { return nil }
+
+// decode decodes the NDJSON events.
+func decode(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+	var evs []map[string]any
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		var ev map[string]any
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &ev), sc.Text())
+		evs = append(evs, ev)
+	}
+	return evs
+}
+
+func TestWriter(t *testing.T) {
+	var buf bufCloser
+	w := NewWriter(&buf)
+	w.now = func() time.Time { return time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	w.Users(types.Users{{ID: "U01", Name: "alice"}})
+	w.Channel(slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01"}, Name: "general"}})
+	parent := types.Message{Message: slack.Message{Msg: slack.Msg{Timestamp: "1.000", ThreadTimestamp: "1.000", Text: "hello"}}}
+	parent.Files = []slack.File{{ID: "F01", Name: "a.png"}}
+	parent.ThreadReplies = []types.Message{{Message: slack.Message{Msg: slack.Msg{Timestamp: "2.000", ThreadTimestamp: "1.000", Text: "reply"}}}}
+	w.Messages("C01", []types.Message{parent})
+	require.NoError(t, w.Close())
+
+	evs := decode(t, buf.Bytes())
+	require.Len(t, evs, 5)
+	var kinds []string
+	for _, ev := range evs {
+		kinds = append(kinds, ev["type"].(string))
+		assert.Equal(t, "2023-01-01T00:00:00Z", ev["time"])
+	}
+	assert.Equal(t, []string{TypeUser, TypeChannel, TypeMessage, TypeFile, TypeMessage}, kinds)
+
+	assert.Equal(t, "alice", evs[0]["data"].(map[string]any)["name"])
+	assert.Equal(t, "C01", evs[1]["channel_id"])
+	assert.Equal(t, "general", evs[1]["data"].(map[string]any)["name"])
+	assert.Equal(t, "C01", evs[2]["channel_id"])
+	assert.Equal(t, "1.000", evs[2]["ts"])
+	assert.NotContains(t, evs[2]["data"], "slackdump_thread_replies", "replies are emitted separately")
+	assert.Equal(t, "1.000", evs[3]["ts"], "file has the ts of the message")
+	assert.Equal(t, "F01", evs[3]["data"].(map[string]any)["id"])
+	assert.Equal(t, "reply", evs[4]["data"].(map[string]any)["text"])
+}
+
+type failWriter struct{ n int }
+
+func (f *failWriter) Write(p []byte) (int, error) {
+	f.n++
+	return 0, errors.New("broken pipe")
+}
+
+func (*failWriter) Close() error { return nil }
+
+func TestWriter_error(t *testing.T) {
+	fw := &failWriter{}
+	w := NewWriter(fw)
+	w.Users(types.Users{{ID: "U01"}, {ID: "U02"}})
+	assert.EqualError(t, w.Err(), "broken pipe")
+	assert.Equal(t, 1, fw.n, "events after the error are discarded")
+	assert.Error(t, w.Close())
+
+	var nilW *Writer
+	nilW.Users(types.Users{{ID: "U01"}})
+	assert.NoError(t, nilW.Close())
+}
+
+func TestOpen(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "events.jsonl")
+	w, err := Open("jsonl://" + name)
+	require.NoError(t, err)
+	w.Users(types.Users{{ID: "U01"}})
+	require.NoError(t, w.Close())
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Len(t, decode(t, data), 1)
+
+	_, err = Open("jsonl://")
+	assert.Error(t, err)
+	_, err = Open("events.jsonl")
+	assert.Error(t, err)
+	assert.True(t, IsTarget("JSONL://stdout"))
+	assert.True(t, IsStdout("jsonl://-"))
+	assert.False(t, IsStdout("jsonl://events.jsonl"))
+}
+
+func TestWriter_Only(t *testing.T) {
+	var buf bufCloser
+	w := NewWriter(&buf)
+	w.Only(TypeMessage)
+	assert.True(t, w.Wants(TypeMessage))
+	assert.False(t, w.Wants(TypeFile))
+
+	w.Users(types.Users{{ID: "U01"}})
+	msg := types.Message{Message: slack.Message{Msg: slack.Msg{Timestamp: "1.000", Files: []slack.File{{ID: "F01"}}}}}
+	w.Messages("C01", []types.Message{msg})
+	require.NoError(t, w.Close())
+
+	evs := decode(t, buf.Bytes())
+	require.Len(t, evs, 1)
+	assert.Equal(t, TypeMessage, evs[0]["type"])
+
+	var nilW *Writer
+	assert.False(t, nilW.Wants(TypeMessage))
+}
+
+// syncBuf is the buffer, that counts the syncs.
+type syncBuf struct {
+	bufCloser
+	syncs int
+}
+
+func (b *syncBuf) Sync() error {
+	b.syncs++
+	return nil
+}
+
+func TestWriter_SetPolicy(t *testing.T) {
+	msgs := []types.Message{{Message: slack.Message{Msg: slack.Msg{Timestamp: "1.000", Text: "hello"}}}}
+	t.Run("buffered", func(t *testing.T) {
+		var buf syncBuf
+		w := NewWriter(&buf)
+		w.SetPolicy(Policy{BufferSize: 4096})
+		w.Messages("C01", msgs)
+		assert.Zero(t, buf.Len(), "the event is buffered")
+		require.NoError(t, w.Close())
+		assert.Len(t, decode(t, buf.Bytes()), 1)
+		assert.Zero(t, buf.syncs)
+	})
+	t.Run("sync", func(t *testing.T) {
+		var buf syncBuf
+		w := NewWriter(&buf)
+		w.SetPolicy(Policy{BufferSize: 4096, Sync: true})
+		w.Messages("C01", msgs)
+		assert.Len(t, decode(t, buf.Bytes()), 1, "the chunk is written")
+		w.Messages("C01", msgs)
+		assert.Equal(t, 2, buf.syncs, "synced after each chunk")
+		require.NoError(t, w.Close())
+	})
+	t.Run("flush interval", func(t *testing.T) {
+		var buf syncBuf
+		w := NewWriter(&buf)
+		w.SetPolicy(Policy{BufferSize: 4096, FlushInterval: 10 * time.Millisecond})
+		w.Messages("C01", msgs)
+		assert.Eventually(t, func() bool {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			return buf.Len() > 0
+		}, time.Second, 5*time.Millisecond)
+		require.NoError(t, w.Close())
+		assert.Len(t, decode(t, buf.Bytes()), 1)
+	})
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	assert.NoError(t, Policy{}.Validate())
+	assert.Error(t, Policy{BufferSize: -1}.Validate())
+	assert.Error(t, Policy{FlushInterval: -time.Second}.Validate())
+}
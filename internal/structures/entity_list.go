@@ -15,6 +15,9 @@ const (
 	// for export or when downloading conversations.
 	excludePrefix = "^"
 	filePrefix    = "@"
+	// namePrefix is the prefix of the channel names, i.e. #general, they are
+	// resolved to the channel IDs before the run.
+	namePrefix = "#"
 
 	// maxFileEntries is the maximum non-empty entries that will be read from
 	// the file. Who ever needs more than 64Ki channels.
@@ -35,6 +38,23 @@ func hasFilePrefix(s string) bool {
 	return strings.HasPrefix(s, filePrefix)
 }
 
+// IsChannelName returns true if s is the channel name, i.e. #general.
+func IsChannelName(s string) bool {
+	return len(s) > len(namePrefix) && strings.HasPrefix(s, namePrefix) && !strings.ContainsAny(s, " \t,")
+}
+
+// parseEntity returns the channel name as is, or the parsed link.
+func parseEntity(ent string) (string, error) {
+	if IsChannelName(ent) {
+		return ent, nil
+	}
+	sl, err := ParseLink(ent)
+	if err != nil {
+		return "", err
+	}
+	return sl.String(), nil
+}
+
 // MakeEntityList creates an EntityList from a slice of IDs or URLs (entites).
 func MakeEntityList(entities []string) (*EntityList, error) {
 	var el EntityList
@@ -120,6 +140,20 @@ func (el *EntityList) Index() map[string]bool {
 	return idx
 }
 
+// AddInclude adds the entities to the include list, unless they are already
+// included or excluded.
+func (el *EntityList) AddInclude(ents ...string) {
+	idx := el.Index()
+	for _, ent := range ents {
+		if _, seen := idx[ent]; seen {
+			continue
+		}
+		idx[ent] = true
+		el.Include = append(el.Include, ent)
+	}
+	sort.Strings(el.Include)
+}
+
 func (el *EntityList) HasIncludes() bool {
 	return len(el.Include) > 0
 }
@@ -147,11 +181,11 @@ func buildEntityIndex(entities []string) (map[string]bool, error) {
 			if trimmed == "" {
 				continue
 			}
-			sl, err := ParseLink(trimmed)
+			ent, err := parseEntity(trimmed)
 			if err != nil {
 				return nil, err
 			}
-			excluded = append(excluded, sl.String())
+			excluded = append(excluded, ent)
 		case hasFilePrefix(ent):
 			trimmed := strings.TrimPrefix(ent, filePrefix)
 			if trimmed == "" {
@@ -159,11 +193,11 @@ func buildEntityIndex(entities []string) (map[string]bool, error) {
 			}
 			files = append(files, trimmed)
 		default:
-			sl, err := ParseLink(ent)
+			ent, err := parseEntity(ent)
 			if err != nil {
 				return nil, err
 			}
-			index[sl.String()] = true
+			index[ent] = true
 		}
 	}
 	// process files
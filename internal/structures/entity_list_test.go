@@ -60,6 +60,15 @@ func TestMakeEntityList(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"channel names",
+			args{[]string{"#general", "^#random", "C01"}},
+			&EntityList{
+				Include: []string{"#general", "C01"},
+				Exclude: []string{"#random"},
+			},
+			false,
+		},
 		{
 			"only excludes",
 			args{[]string{"^one", "^two", "^three"}},
@@ -305,6 +314,15 @@ func TestEntityList_HasIncludes(t *testing.T) {
 	}
 }
 
+func TestEntityList_AddInclude(t *testing.T) {
+	el := &EntityList{Include: []string{"C2"}, Exclude: []string{"C3"}}
+	el.AddInclude("C4", "C1", "C2", "C3")
+	want := &EntityList{Include: []string{"C1", "C2", "C4"}, Exclude: []string{"C3"}}
+	if !reflect.DeepEqual(el, want) {
+		t.Errorf("EntityList.AddInclude() = %v, want %v", el, want)
+	}
+}
+
 func TestEntityList_HasExcludes(t *testing.T) {
 	type fields struct {
 		Include []string
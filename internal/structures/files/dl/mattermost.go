@@ -22,17 +22,18 @@ type Mattermost struct {
 
 // NewMattermost returns the dl, that downloads the files into
 // the __uploads directory, so that it could be transformed into bulk import
-// by mmetl and imported into mattermost with mmctl import bulk.
-func NewMattermost(fs fsadapter.FS, cl *slack.Client, l logger.Interface, token string) *Mattermost {
+// by mmetl and imported into mattermost with mmctl import bulk.  The options
+// opts are passed to the downloader.
+func NewMattermost(fs fsadapter.FS, cl downloader.Downloader, l logger.Interface, token string, opts ...downloader.Option) *Mattermost {
 	return &Mattermost{
 		base: base{
 			l:     l,
 			token: token,
-			dl: downloader.New(cl, fs, downloader.Logger(l), downloader.WithNameFunc(
+			dl: downloader.New(cl, fs, append([]downloader.Option{downloader.Logger(l), downloader.WithNameFunc(
 				func(f *slack.File) string {
 					return f.Name
 				},
-			)),
+			)}, opts...)...),
 		},
 	}
 }
@@ -47,11 +48,21 @@ func (md *Mattermost) ProcessFunc(_ string) slackdump.ProcessFunc {
 		total := 0
 		if err := files.Extract(msgs, files.Root, func(file slack.File, addr files.Addr) error {
 			filedir := filepath.Join(baseDir, file.ID)
-			_, err := md.dl.DownloadFile(filedir, file)
+			filename, err := md.dl.DownloadFile(filedir, file)
 			if err != nil {
 				return err
 			}
 			total++
+			if name := filepath.Base(filename); name != file.Name {
+				// mmetl looks up the file by its name, so it must match the
+				// sanitized name on disk.
+				if err := files.Update(msgs, addr, func(f *slack.File) error {
+					f.Name = name
+					return nil
+				}); err != nil {
+					return err
+				}
+			}
 			if md.token != "" {
 				return files.Update(msgs, addr, files.UpdateTokenFn(md.token))
 			}
@@ -22,11 +22,12 @@ type Std struct {
 }
 
 // NewStd returns standard dl, which downloads files into
-// "channel_id/attachments" directory.
-func NewStd(fs fsadapter.FS, cl *slack.Client, l logger.Interface, token string) *Std {
+// "channel_id/attachments" directory.  The options opts are passed to the
+// downloader.
+func NewStd(fs fsadapter.FS, cl downloader.Downloader, l logger.Interface, token string, opts ...downloader.Option) *Std {
 	return &Std{
 		base: base{
-			dl:    downloader.New(cl, fs, downloader.Logger(l)),
+			dl:    downloader.New(cl, fs, append([]downloader.Option{downloader.Logger(l)}, opts...)...),
 			l:     l,
 			token: token,
 		}}
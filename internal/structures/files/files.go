@@ -1,7 +1,9 @@
 package files
 
 import (
+	"encoding/base64"
 	"net/url"
+	"strings"
 
 	"github.com/slack-go/slack"
 )
@@ -69,6 +71,24 @@ func UpdatePathFn(path string) UpdateFunc {
 	}
 }
 
+// dataPrefix is the prefix of the data URLs.
+const dataPrefix = "data:"
+
+// DataURL returns the data URL with the file data, encoded in base64, i.e.
+// "data:text/plain;base64,aGVsbG8=".
+func DataURL(mimetype string, data []byte) string {
+	if mimetype == "" {
+		mimetype = "application/octet-stream"
+	}
+	return dataPrefix + mimetype + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// IsLocal returns true if the file reference p is the path of the downloaded
+// file within the archive, and not the URL or the embedded data URL.
+func IsLocal(p string) bool {
+	return p != "" && !strings.Contains(p, "://") && !strings.HasPrefix(p, dataPrefix)
+}
+
 // addToken updates the uri, adding the t= query parameter with token value.
 // if token or url is empty, it does nothing.
 func addToken(uri string, token string) (string, error) {
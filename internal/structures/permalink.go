@@ -0,0 +1,21 @@
+package structures
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Permalink returns the link to the message with the timestamp ts in the
+// channel channelID on the workspace with the URL baseURL, i.e.
+// https://xxxx.slack.com/.  If the message is a thread reply, threadTS
+// should be set to the timestamp of the thread parent.  The link has the
+// same format as the one Slack returns from chat.getPermalink, i.e.:
+//
+//	https://xxxx.slack.com/archives/C123/p1672531260000200?thread_ts=1672531200.000100&cid=C123
+func Permalink(baseURL, channelID, ts, threadTS string) string {
+	link := strings.TrimRight(baseURL, "/") + "/archives/" + channelID + "/p" + strings.Replace(ts, ".", "", 1)
+	if threadTS == "" || threadTS == ts {
+		return link
+	}
+	return link + "?thread_ts=" + url.QueryEscape(threadTS) + "&cid=" + url.QueryEscape(channelID)
+}
@@ -0,0 +1,40 @@
+package structures
+
+import "testing"
+
+func TestPermalink(t *testing.T) {
+	type args struct {
+		baseURL   string
+		channelID string
+		ts        string
+		threadTS  string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			"message",
+			args{"https://ora600.slack.com/", "CHM82GF99", "1577694990.000400", ""},
+			"https://ora600.slack.com/archives/CHM82GF99/p1577694990000400",
+		},
+		{
+			"thread parent",
+			args{"https://ora600.slack.com", "CHM82GF99", "1577694990.000400", "1577694990.000400"},
+			"https://ora600.slack.com/archives/CHM82GF99/p1577694990000400",
+		},
+		{
+			"thread reply",
+			args{"https://ora600.slack.com/", "CHM82GF99", "1577695000.000100", "1577694990.000400"},
+			"https://ora600.slack.com/archives/CHM82GF99/p1577695000000100?thread_ts=1577694990.000400&cid=CHM82GF99",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Permalink(tt.args.baseURL, tt.args.channelID, tt.args.ts, tt.args.threadTS); got != tt.want {
+				t.Errorf("Permalink() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
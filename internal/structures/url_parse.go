@@ -101,6 +101,11 @@ func ParseURL(slackURL string) (*SlackLink, error) {
 			return nil, ErrUnsupportedURL
 		}
 		ui.ThreadTS = FormatSlackTS(ts)
+		// the permalink of a reply points to the reply, the thread is in
+		// the thread_ts parameter.
+		if tts := uri.Query().Get("thread_ts"); tts != "" {
+			ui.ThreadTS = tts
+		}
 		fallthrough
 	case 2:
 		// channel
@@ -116,9 +121,14 @@ func ParseURL(slackURL string) (*SlackLink, error) {
 
 // Sample: https://ora600.slack.com/archives/CHM82GF99/p1577694990000400
 //
+// The GovSlack workspaces are on slack-gov.com.
+//
+// The permalinks of the thread replies have the query, i.e.
+// ?thread_ts=1577694990.000100&cid=CHM82GF99.
+//
 // > Your workspace URL can only contain lowercase letters, numbers and dashes
 // > (and must start with a letter or number).
-var slackURLRe = regexp.MustCompile(`^https:\/\/[a-zA-Z0-9]{1}[-\w]+\.slack\.com\/archives\/[A-Z]{1}[A-Z0-9]+(\/p(\d+))?$`)
+var slackURLRe = regexp.MustCompile(`^https:\/\/[a-zA-Z0-9]{1}[-\w]+\.slack(-gov)?\.com\/archives\/[A-Z]{1}[A-Z0-9]+(\/p(\d+)(\?thread_ts=\d+\.\d+(&cid=[A-Z0-9]+)?)?)?$`)
 
 // IsValidSlackURL returns true if the value looks like valid Slack URL, false
 // if not.
@@ -42,6 +42,12 @@ func TestParseURL(t *testing.T) {
 			want:    &SlackLink{Channel: "CHM82GF99", ThreadTS: "1577694990.000400"},
 			wantErr: false,
 		},
+		{
+			name:    "govslack thread",
+			args:    args{"https://agency.slack-gov.com/archives/CHM82GF99/p1577694990000400"},
+			want:    &SlackLink{Channel: "CHM82GF99", ThreadTS: "1577694990.000400"},
+			wantErr: false,
+		},
 		{
 			name:    "thread with extra data in the URL",
 			args:    args{sampleThreadURL + "/xxxx"},
@@ -108,6 +114,12 @@ func TestParseURL(t *testing.T) {
 			want:    &SlackLink{Channel: "CHANNEL", ThreadTS: "1645551829.244659"},
 			wantErr: false,
 		},
+		{
+			name:    "thread reply permalink",
+			args:    args{sampleThreadURL + "?thread_ts=1577694980.000100&cid=CHM82GF99"},
+			want:    &SlackLink{Channel: "CHM82GF99", ThreadTS: "1577694980.000100"},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -9,13 +9,22 @@ import (
 // UserIndex is a mapping of user ID to the *slack.User.
 type UserIndex map[string]*slack.User
 
-// NewUserIndex creates a new UserIndex from slack Users slice
+// NewUserIndex creates a new UserIndex from slack Users slice.  The users of
+// the Enterprise Grid organisation are also indexed by their enterprise ID,
+// as the messages in the channels, shared between the teams of the
+// organisation, may reference the users of the other teams by it.
 func NewUserIndex(us []slack.User) UserIndex {
 	var usermap = make(UserIndex, len(us))
 
 	for i := range us {
 		usermap[(us)[i].ID] = &us[i]
 	}
+	for i := range us {
+		eid := us[i].Enterprise.ID
+		if _, seen := usermap[eid]; eid != "" && !seen {
+			usermap[eid] = &us[i]
+		}
+	}
 
 	return usermap
 }
@@ -29,13 +38,22 @@ func (idx UserIndex) Username(id string) string {
 	})
 }
 
+// DeactivatedPrefix is the prefix of the display name of the deactivated
+// user.
+const DeactivatedPrefix = "(deactivated) "
+
 // DisplayName tries to resolve the display name by ID. if the index is empty, it
 // returns the user ID. If the user is not found in index, is assumes that it is
 // an external user and returns ID with "external" prefix. If it does find the
-// user and display name is unavailble, it returns the Real Name.
+// user and display name is unavailble, it returns the Real Name.  The names of
+// the deactivated users are prefixed with DeactivatedPrefix.
 func (idx UserIndex) DisplayName(id string) string {
 	return idx.userattr(id, func(user *slack.User) string {
-		return nvl(user.Profile.DisplayName, user.RealName)
+		name := nvl(user.Profile.DisplayName, user.RealName)
+		if user.Deleted {
+			return DeactivatedPrefix + name
+		}
+		return name
 	})
 }
 
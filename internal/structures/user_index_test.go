@@ -3,6 +3,9 @@ package structures
 import (
 	"testing"
 
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
 	"github.com/rusq/slackdump/v2/internal/fixtures"
 )
 
@@ -84,3 +87,24 @@ func Test_nvl(t *testing.T) {
 		})
 	}
 }
+
+func TestNewUserIndex_enterprise(t *testing.T) {
+	users := []slack.User{
+		{ID: "U01", Name: "alice", Enterprise: slack.EnterpriseUser{ID: "W01"}},
+		{ID: "W02", Name: "bob", Enterprise: slack.EnterpriseUser{ID: "W02"}},
+	}
+	idx := NewUserIndex(users)
+	assert.Equal(t, "alice", idx.Username("W01"), "user of the other team is resolved by the enterprise ID")
+	assert.Equal(t, "bob", idx.Username("W02"))
+	assert.Len(t, idx, 3)
+}
+
+func TestUserIndex_DisplayName_deactivated(t *testing.T) {
+	idx := NewUserIndex([]slack.User{
+		{ID: "U01", RealName: "Alice", Profile: slack.UserProfile{DisplayName: "alice"}},
+		{ID: "U02", RealName: "Jane", Deleted: true},
+	})
+	assert.Equal(t, "alice", idx.DisplayName("U01"))
+	assert.Equal(t, "(deactivated) Jane", idx.DisplayName("U02"))
+	assert.Equal(t, "<external>:U03", idx.DisplayName("U03"))
+}
@@ -0,0 +1,111 @@
+package upload
+
+// In this file: the single object access, for the small objects, that are
+// read and written as a whole, i.e. the run state, and for the ranged reads
+// of the remote archives.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+)
+
+// Object is the object in the S3 bucket.
+type Object struct {
+	s3     *s3Client
+	bucket string
+	key    string
+}
+
+// NewObject returns the object at the location s3://bucket/key.  The
+// endpoint, region and credentials are taken from cfg, the rest of the
+// settings, including the Target, are ignored.
+func NewObject(cfg Config, location string) (*Object, error) {
+	bucket, key, err := ParseTarget(location)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, fmt.Errorf("invalid object location %q, expected s3://bucket/key", location)
+	}
+	if cfg.Credentials.AccessKeyID == "" || cfg.Credentials.SecretAccessKey == "" {
+		return nil, errors.New("s3: access key ID and secret access key are required")
+	}
+	s3, err := newS3Client(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{s3: s3, bucket: bucket, key: key}, nil
+}
+
+// Get returns the contents of the object.  If the object doesn't exist, the
+// error wraps fs.ErrNotExist.
+func (o *Object) Get(ctx context.Context) ([]byte, error) {
+	resp, err := o.s3.do(ctx, http.MethodGet, o.s3.objectURL(o.bucket, o.key, nil), nil, nil, 0)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("%s: %w", o, fs.ErrNotExist)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Size returns the size of the object.  If the object doesn't exist, the
+// error wraps fs.ErrNotExist.
+func (o *Object) Size(ctx context.Context) (int64, error) {
+	size, err := o.s3.headObject(ctx, o.bucket, o.key)
+	if err != nil {
+		if isNotFound(err) {
+			return 0, fmt.Errorf("%s: %w", o, fs.ErrNotExist)
+		}
+		return 0, err
+	}
+	return size, nil
+}
+
+// GetRange returns n bytes of the object, starting at the offset off.
+func (o *Object) GetRange(ctx context.Context, off, n int64) ([]byte, error) {
+	hdr := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", off, off+n-1)}}
+	resp, err := o.s3.do(ctx, http.MethodGet, o.s3.objectURL(o.bucket, o.key, nil), hdr, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("%s: range requests are not supported by the storage", o)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, n))
+}
+
+// Put replaces the contents of the object with data.
+func (o *Object) Put(ctx context.Context, data []byte) error {
+	return o.s3.putObject(ctx, o.bucket, o.key, nil, bytes.NewReader(data), int64(len(data)))
+}
+
+// Delete deletes the object.  Deleting the object, that doesn't exist, is not
+// an error.
+func (o *Object) Delete(ctx context.Context) error {
+	resp, err := o.s3.do(ctx, http.MethodDelete, o.s3.objectURL(o.bucket, o.key, nil), nil, nil, 0)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return drain(resp)
+}
+
+func (o *Object) String() string {
+	return "s3://" + o.bucket + "/" + o.key
+}
+
+func isNotFound(err error) bool {
+	var s3err *S3Error
+	return errors.As(err, &s3err) && s3err.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,55 @@
+package upload
+
+import (
+	"context"
+	"io/fs"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObject(t *testing.T) {
+	s3 := newFakeS3()
+	srv := httptest.NewServer(s3)
+	defer srv.Close()
+
+	o, err := NewObject(Config{
+		Endpoint:    srv.URL,
+		Credentials: Credentials{AccessKeyID: "key", SecretAccessKey: "secret"},
+	}, "s3://ci/slackdump/state.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "s3://ci/slackdump/state.txt", o.String())
+	ctx := context.Background()
+
+	_, err = o.Get(ctx)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	require.NoError(t, o.Put(ctx, []byte("C01\nC02\n")))
+	assert.Equal(t, []byte("C01\nC02\n"), s3.objects["/ci/slackdump/state.txt"])
+	data, err := o.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "C01\nC02\n", string(data))
+
+	size, err := o.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), size)
+	data, err = o.GetRange(ctx, 4, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "C02", string(data))
+
+	require.NoError(t, o.Delete(ctx))
+	assert.NotContains(t, s3.objects, "/ci/slackdump/state.txt")
+	assert.NoError(t, o.Delete(ctx), "deleting a missing object is not an error")
+}
+
+func TestNewObject(t *testing.T) {
+	creds := Credentials{AccessKeyID: "key", SecretAccessKey: "secret"}
+	_, err := NewObject(Config{Credentials: creds}, "s3://ci")
+	assert.Error(t, err, "key is required")
+	_, err = NewObject(Config{}, "s3://ci/state.txt")
+	assert.Error(t, err, "credentials are required")
+	_, err = NewObject(Config{Credentials: creds}, "redis://localhost/state")
+	assert.Error(t, err)
+}
@@ -0,0 +1,362 @@
+package upload
+
+// In this file: minimal S3 client, that implements the subset of the S3 API
+// required for uploads, with AWS Signature Version 4 authentication.  It works
+// with AWS S3, MinIO, and other S3-compatible storages, i.e. GCS in the
+// interoperability mode.
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// unsignedPayload is used instead of the payload hash, so that the
+	// request body can be streamed.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	amzDateFmt      = "20060102T150405Z"
+	defRegion       = "us-east-1"
+)
+
+// Credentials are the S3 access credentials.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // optional, for temporary credentials
+}
+
+// s3Client is the S3 API client.
+type s3Client struct {
+	endpoint  *url.URL
+	region    string
+	pathStyle bool
+	creds     Credentials
+	cl        *http.Client
+	now       func() time.Time
+}
+
+// S3Error is the error returned by the S3 API.
+type S3Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *S3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("s3: %s: %s", e.Code, e.Message)
+}
+
+// objectURL returns the URL of the object key in bucket.  Virtual host style
+// is used, unless pathStyle is set (MinIO and most other self-hosted
+// storages require the path style).
+func (c *s3Client) objectURL(bucket, key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	if c.pathStyle {
+		u.Path = "/" + bucket + "/" + key
+	} else {
+		u.Host = bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+// do executes the signed request, and returns the response, if the status
+// is 2xx, otherwise it returns the S3Error.  The response body must be closed
+// by the caller.
+func (c *s3Client) do(ctx context.Context, method string, u *url.URL, hdr http.Header, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawPath = u.RawPath
+	for k, v := range hdr {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	c.sign(req)
+	resp, err := c.cl.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, parseError(resp.StatusCode, resp.Body)
+	}
+	return resp, nil
+}
+
+func parseError(code int, r io.Reader) error {
+	s3err := &S3Error{StatusCode: code}
+	data, _ := io.ReadAll(io.LimitReader(r, 64*1024))
+	_ = xml.Unmarshal(data, s3err)
+	return s3err
+}
+
+// sign signs the request with AWS Signature Version 4.
+func (c *s3Client) sign(req *http.Request) {
+	t := c.now().UTC()
+	amzDate := t.Format(amzDateFmt)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if c.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.creds.SessionToken)
+	}
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	}
+	req.Header.Set("Authorization", c.authorization(req, t))
+}
+
+// authorization returns the value of the Authorization header for the
+// request at time t.
+func (c *s3Client) authorization(req *http.Request, t time.Time) string {
+	signed, canonHdrs := canonicalHeaders(req)
+	canonReq := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonHdrs,
+		signed,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	date := t.Format("20060102")
+	scope := date + "/" + c.region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + t.Format(amzDateFmt) + "\n" + scope + "\n" + hexSHA256([]byte(canonReq))
+
+	key := hmacSHA256([]byte("AWS4"+c.creds.SecretAccessKey), date)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	return "AWS4-HMAC-SHA256 Credential=" + c.creds.AccessKeyID + "/" + scope + ", SignedHeaders=" + signed + ", Signature=" + sig
+}
+
+// canonicalHeaders returns the signed headers list and the canonical
+// headers.  Host, content type and all X-Amz-* headers are signed.
+func canonicalHeaders(req *http.Request) (signed string, canonical string) {
+	hdrs := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if lk == "content-type" || lk == "content-md5" || lk == "range" || strings.HasPrefix(lk, "x-amz-") {
+			hdrs[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(hdrs))
+	for k := range hdrs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var buf strings.Builder
+	for _, k := range names {
+		buf.WriteString(k + ":" + hdrs[k] + "\n")
+	}
+	return strings.Join(names, ";"), buf.String()
+}
+
+// canonicalQuery returns the query string sorted by key, with the keys and
+// values encoded according to the SigV4 rules.
+func canonicalQuery(q url.Values) string {
+	if len(q) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vals := append([]string{}, q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode encodes s according to the SigV4 rules: all characters except
+// the unreserved ones are percent-encoded.  Slashes are encoded only if
+// encodeSlash is true.
+func uriEncode(s string, encodeSlash bool) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			buf.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			buf.WriteByte(c)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+// headObject returns the size of the object, or an error.  If the object
+// doesn't exist, the S3Error with http.StatusNotFound is returned.
+func (c *s3Client) headObject(ctx context.Context, bucket, key string) (int64, error) {
+	resp, err := c.do(ctx, http.MethodHead, c.objectURL(bucket, key, nil), nil, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.ContentLength, nil
+}
+
+// putObject uploads the object in a single request.
+func (c *s3Client) putObject(ctx context.Context, bucket, key string, hdr http.Header, body io.Reader, size int64) error {
+	resp, err := c.do(ctx, http.MethodPut, c.objectURL(bucket, key, nil), hdr, body, size)
+	if err != nil {
+		return err
+	}
+	return drain(resp)
+}
+
+// createMultipartUpload initiates the multipart upload and returns the
+// upload ID.
+func (c *s3Client) createMultipartUpload(ctx context.Context, bucket, key string, hdr http.Header) (string, error) {
+	resp, err := c.do(ctx, http.MethodPost, c.objectURL(bucket, key, url.Values{"uploads": {""}}), hdr, nil, 0)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var res struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", fmt.Errorf("s3: invalid response: %w", err)
+	}
+	if res.UploadID == "" {
+		return "", errors.New("s3: no upload ID in the response")
+	}
+	return res.UploadID, nil
+}
+
+// part is the uploaded part of the multipart upload.
+type part struct {
+	PartNumber int    `xml:"PartNumber" json:"n"`
+	ETag       string `xml:"ETag" json:"etag"`
+	Size       int64  `xml:"Size" json:"size"`
+}
+
+// uploadPart uploads the part of the multipart upload, and returns its ETag.
+func (c *s3Client) uploadPart(ctx context.Context, bucket, key, uploadID string, num int, body io.Reader, size int64) (string, error) {
+	q := url.Values{"partNumber": {strconv.Itoa(num)}, "uploadId": {uploadID}}
+	resp, err := c.do(ctx, http.MethodPut, c.objectURL(bucket, key, q), nil, body, size)
+	if err != nil {
+		return "", err
+	}
+	etag := resp.Header.Get("ETag")
+	if err := drain(resp); err != nil {
+		return "", err
+	}
+	return etag, nil
+}
+
+// listParts returns the parts of the multipart upload that are already
+// uploaded.
+func (c *s3Client) listParts(ctx context.Context, bucket, key, uploadID string) ([]part, error) {
+	var (
+		parts  []part
+		marker string
+	)
+	for {
+		q := url.Values{"uploadId": {uploadID}}
+		if marker != "" {
+			q.Set("part-number-marker", marker)
+		}
+		resp, err := c.do(ctx, http.MethodGet, c.objectURL(bucket, key, q), nil, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		var res struct {
+			Parts                []part `xml:"Part"`
+			IsTruncated          bool   `xml:"IsTruncated"`
+			NextPartNumberMarker string `xml:"NextPartNumberMarker"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3: invalid response: %w", err)
+		}
+		parts = append(parts, res.Parts...)
+		if !res.IsTruncated || res.NextPartNumberMarker == "" {
+			return parts, nil
+		}
+		marker = res.NextPartNumberMarker
+	}
+}
+
+// completeMultipartUpload completes the upload from the parts.
+func (c *s3Client) completeMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []part) error {
+	type completePart struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	var req struct {
+		XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+		Parts   []completePart `xml:"Part"`
+	}
+	for _, p := range parts {
+		req.Parts = append(req.Parts, completePart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	data, err := xml.Marshal(req)
+	if err != nil {
+		return err
+	}
+	hdr := http.Header{"Content-Type": {"application/xml"}}
+	resp, err := c.do(ctx, http.MethodPost, c.objectURL(bucket, key, url.Values{"uploadId": {uploadID}}), hdr, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// S3 may return 200 OK with the error in the body.
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(body, []byte("<Error>")) {
+		return parseError(resp.StatusCode, bytes.NewReader(body))
+	}
+	return nil
+}
+
+func drain(resp *http.Response) error {
+	defer resp.Body.Close()
+	_, err := io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,74 @@
+package upload
+
+// In this file: multipart upload state, that allows to resume the
+// interrupted uploads.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// state is the state of the multipart upload.
+type state struct {
+	filename string
+
+	Bucket   string    `json:"bucket"`
+	Key      string    `json:"key"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	PartSize int64     `json:"part_size"`
+	UploadID string    `json:"upload_id"`
+}
+
+// newState returns the state of the upload of the file fi to the bucket
+// key.  The state file name is derived from the bucket and key, so that it
+// can be found on the next attempt.
+func newState(dir, bucket, key string, fi os.FileInfo) *state {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	h := sha256.Sum256([]byte(bucket + "/" + key))
+	return &state{
+		filename: filepath.Join(dir, "slackdump-upload-"+hex.EncodeToString(h[:8])+".json"),
+		Bucket:   bucket,
+		Key:      key,
+		Size:     fi.Size(),
+		ModTime:  fi.ModTime().UTC(),
+	}
+}
+
+// load loads the saved state.  It returns os.ErrNotExist, if there's no
+// saved state for the same file.
+func (s *state) load() error {
+	data, err := os.ReadFile(s.filename)
+	if err != nil {
+		return err
+	}
+	var saved state
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	if saved.Bucket != s.Bucket || saved.Key != s.Key || saved.Size != s.Size || !saved.ModTime.Equal(s.ModTime) {
+		// the file has changed since.
+		return os.ErrNotExist
+	}
+	s.UploadID = saved.UploadID
+	s.PartSize = saved.PartSize
+	return nil
+}
+
+func (s *state) save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.filename, data, 0600)
+}
+
+func (s *state) remove() {
+	_ = os.Remove(s.filename)
+}
@@ -0,0 +1,359 @@
+// Package upload implements the upload of the finished archives to the
+// S3-compatible object storage.
+//
+// Files larger than the part size are uploaded with the multipart upload.
+// The state of the multipart upload is saved, so that the interrupted upload
+// is resumed on the next attempt.  Files that already exist in the bucket
+// with the same size are skipped.
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+const (
+	// DefPartSize is the default part size of the multipart upload.
+	DefPartSize = 16 << 20
+	// minPartSize is the minimum part size allowed by S3.
+	minPartSize = 5 << 20
+	// maxParts is the maximum number of parts in the multipart upload.
+	maxParts = 10000
+	// defAttempts is the number of attempts for each request.
+	defAttempts = 3
+)
+
+// SSE types.
+const (
+	SSENone = ""
+	SSES3   = "AES256"
+	SSEKMS  = "aws:kms"
+)
+
+// Config is the uploader configuration.
+type Config struct {
+	// Target is the destination in the form of s3://bucket/prefix.
+	Target string
+	// Endpoint is the S3 endpoint URL, i.e. https://minio.example.com:9000.
+	// If empty, AWS S3 endpoint for the Region is used.
+	Endpoint string
+	// Region is the bucket region.
+	Region string
+	// PathStyle enables the path style bucket addressing, it is enabled
+	// automatically for the custom endpoints.
+	PathStyle bool
+	// Credentials are the access credentials.
+	Credentials Credentials
+	// SSE is the server side encryption type: SSES3, SSEKMS or SSENone.
+	SSE string
+	// KMSKeyID is the KMS key ID for SSEKMS. If empty, the default key is used.
+	KMSKeyID string
+	// Tags are the object tags.
+	Tags map[string]string
+	// PartSize is the multipart upload part size.
+	PartSize int64
+	// StateDir is the directory, where the multipart upload state is saved.
+	// If empty, the system temporary directory is used.
+	StateDir string
+}
+
+// Uploader uploads files to the S3 bucket.
+type Uploader struct {
+	cfg    Config
+	bucket string
+	prefix string
+	s3     *s3Client
+	lg     logger.Interface
+}
+
+// Option is the Uploader option.
+type Option func(*Uploader)
+
+// WithLogger sets the logger.
+func WithLogger(lg logger.Interface) Option {
+	return func(u *Uploader) {
+		if lg != nil {
+			u.lg = lg
+		}
+	}
+}
+
+// WithHTTPClient sets the HTTP client.
+func WithHTTPClient(cl *http.Client) Option {
+	return func(u *Uploader) {
+		if cl != nil {
+			u.s3.cl = cl
+		}
+	}
+}
+
+// ParseTarget parses the s3://bucket/prefix target.
+func ParseTarget(target string) (bucket, prefix string, err error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "s3" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid upload target %q, expected s3://bucket/prefix", target)
+	}
+	return u.Host, strings.Trim(u.Path, "/"), nil
+}
+
+// New creates the Uploader.
+func New(cfg Config, opts ...Option) (*Uploader, error) {
+	bucket, prefix, err := ParseTarget(cfg.Target)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Credentials.AccessKeyID == "" || cfg.Credentials.SecretAccessKey == "" {
+		return nil, errors.New("upload: access key ID and secret access key are required")
+	}
+	switch cfg.SSE {
+	case SSENone, SSES3, SSEKMS:
+	default:
+		return nil, fmt.Errorf("upload: invalid server side encryption: %q, must be one of %q or %q", cfg.SSE, SSES3, SSEKMS)
+	}
+	if cfg.KMSKeyID != "" && cfg.SSE != SSEKMS {
+		return nil, errors.New("upload: KMS key requires the aws:kms server side encryption")
+	}
+	if cfg.PartSize == 0 {
+		cfg.PartSize = DefPartSize
+	}
+	if cfg.PartSize < minPartSize {
+		return nil, fmt.Errorf("upload: part size must be at least %d bytes", minPartSize)
+	}
+	s3, err := newS3Client(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	u := &Uploader{
+		cfg:    cfg,
+		bucket: bucket,
+		prefix: prefix,
+		s3:     s3,
+		lg:     logger.Default,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u, nil
+}
+
+// newS3Client returns the S3 client for the endpoint, region and credentials
+// of the cfg.  It sets the default region, and enables the path style for the
+// custom endpoints.
+func newS3Client(cfg *Config) (*s3Client, error) {
+	if cfg.Region == "" {
+		cfg.Region = defRegion
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+		if cfg.Region == defRegion {
+			endpoint = "https://s3.amazonaws.com"
+		}
+	} else {
+		cfg.PathStyle = true
+	}
+	ep, err := url.Parse(endpoint)
+	if err != nil || ep.Host == "" || (ep.Scheme != "http" && ep.Scheme != "https") {
+		return nil, fmt.Errorf("upload: invalid endpoint: %q", endpoint)
+	}
+	ep.Path = ""
+	return &s3Client{
+		endpoint:  ep,
+		region:    cfg.Region,
+		pathStyle: cfg.PathStyle,
+		creds:     cfg.Credentials,
+		cl:        http.DefaultClient,
+		now:       time.Now,
+	}, nil
+}
+
+// Upload uploads the file or directory location to the bucket.  Objects are
+// placed under the prefix, followed by the base name of the location, i.e.
+// s3://bucket/backups + /data/export.zip = backups/export.zip.
+func (u *Uploader) Upload(ctx context.Context, location string) error {
+	location = filepath.Clean(location)
+	fi, err := os.Stat(location)
+	if err != nil {
+		return err
+	}
+	base := path.Join(u.prefix, filepath.Base(location))
+	if !fi.IsDir() {
+		return u.uploadFile(ctx, location, base)
+	}
+	var files []string
+	if err := filepath.WalkDir(location, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, name)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, name := range files {
+		rel, err := filepath.Rel(location, name)
+		if err != nil {
+			return err
+		}
+		if err := u.uploadFile(ctx, name, path.Join(base, filepath.ToSlash(rel))); err != nil {
+			return err
+		}
+	}
+	u.lg.Printf("upload: %d file(s) uploaded to s3://%s/%s", len(files), u.bucket, base)
+	return nil
+}
+
+// uploadFile uploads the file name to the object key.
+func (u *Uploader) uploadFile(ctx context.Context, name string, key string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if size, err := u.s3.headObject(ctx, u.bucket, key); err == nil && size == fi.Size() {
+		u.lg.Debugf("upload: %s already exists, skipping", key)
+		return nil
+	}
+	if fi.Size() <= u.cfg.PartSize {
+		return u.retry(ctx, func() error {
+			return u.s3.putObject(ctx, u.bucket, key, u.objectHeaders(), io.NewSectionReader(f, 0, fi.Size()), fi.Size())
+		})
+	}
+	return u.multipart(ctx, f, fi, key)
+}
+
+// objectHeaders returns the headers for the new object: encryption and
+// tags.
+func (u *Uploader) objectHeaders() http.Header {
+	hdr := http.Header{"Content-Type": {"application/octet-stream"}}
+	if u.cfg.SSE != SSENone {
+		hdr.Set("X-Amz-Server-Side-Encryption", u.cfg.SSE)
+	}
+	if u.cfg.KMSKeyID != "" {
+		hdr.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", u.cfg.KMSKeyID)
+	}
+	if len(u.cfg.Tags) > 0 {
+		tags := make(url.Values, len(u.cfg.Tags))
+		for k, v := range u.cfg.Tags {
+			tags.Set(k, v)
+		}
+		hdr.Set("X-Amz-Tagging", tags.Encode())
+	}
+	return hdr
+}
+
+// multipart uploads the file f with the multipart upload, resuming the
+// previous upload if its state is found.
+func (u *Uploader) multipart(ctx context.Context, f *os.File, fi os.FileInfo, key string) error {
+	partSize := u.cfg.PartSize
+	for fi.Size()/partSize >= maxParts {
+		partSize *= 2
+	}
+	st := newState(u.cfg.StateDir, u.bucket, key, fi)
+	uploaded := make(map[int]part)
+	if err := st.load(); err == nil && st.PartSize == partSize {
+		parts, err := u.s3.listParts(ctx, u.bucket, key, st.UploadID)
+		if err == nil {
+			for _, p := range parts {
+				uploaded[p.PartNumber] = p
+			}
+			u.lg.Printf("upload: resuming %s, %d part(s) already uploaded", key, len(uploaded))
+		} else {
+			u.lg.Debugf("upload: unable to resume %s: %s", key, err)
+			st.UploadID = ""
+		}
+	} else {
+		st.UploadID = ""
+	}
+	if st.UploadID == "" {
+		var id string
+		if err := u.retry(ctx, func() (err error) {
+			id, err = u.s3.createMultipartUpload(ctx, u.bucket, key, u.objectHeaders())
+			return err
+		}); err != nil {
+			return err
+		}
+		st.UploadID = id
+		st.PartSize = partSize
+		if err := st.save(); err != nil {
+			u.lg.Printf("upload: unable to save the upload state, it won't be possible to resume: %s", err)
+		}
+	}
+
+	var parts []part
+	for num, off := 1, int64(0); off < fi.Size(); num, off = num+1, off+partSize {
+		size := partSize
+		if off+size > fi.Size() {
+			size = fi.Size() - off
+		}
+		if p, ok := uploaded[num]; ok && p.Size == size {
+			parts = append(parts, p)
+			continue
+		}
+		var etag string
+		if err := u.retry(ctx, func() (err error) {
+			etag, err = u.s3.uploadPart(ctx, u.bucket, key, st.UploadID, num, io.NewSectionReader(f, off, size), size)
+			return err
+		}); err != nil {
+			return fmt.Errorf("upload: %s part %d: %w", key, num, err)
+		}
+		parts = append(parts, part{PartNumber: num, ETag: etag, Size: size})
+		u.lg.Debugf("upload: %s part %d uploaded", key, num)
+	}
+	if err := u.retry(ctx, func() error {
+		return u.s3.completeMultipartUpload(ctx, u.bucket, key, st.UploadID, parts)
+	}); err != nil {
+		return err
+	}
+	st.remove()
+	return nil
+}
+
+// retry calls fn up to defAttempts times, while it returns transient errors.
+func (u *Uploader) retry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < defAttempts; attempt++ {
+		if err = fn(); err == nil || !isTransient(err) {
+			return err
+		}
+		delay := time.Duration(1<<uint(attempt)) * time.Second
+		u.lg.Debugf("upload: %s, retrying in %s", err, delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return err
+}
+
+func isTransient(err error) bool {
+	var s3err *S3Error
+	if errors.As(err, &s3err) {
+		return s3err.StatusCode >= 500 || s3err.StatusCode == http.StatusTooManyRequests
+	}
+	return !errors.Is(err, context.Canceled)
+}
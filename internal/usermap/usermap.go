@@ -0,0 +1,256 @@
+// Package usermap implements the mapping of the users of the source
+// workspace to the identities in the target system, i.e. the destination
+// workspace of the migration.
+//
+// The mapping is kept in CSV or JSON file, that can be drafted from the
+// archive users with Generate, and then edited by hand.  Each entry maps the
+// source user ID to the target user ID or name, and/or the email, see
+// Map.Resolve.
+package usermap
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Format is the format of the mapping file.
+type Format string
+
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+)
+
+// FormatOf returns the format of the file name by its extension, files
+// without the .json extension are CSV.
+func FormatOf(name string) Format {
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		return JSON
+	}
+	return CSV
+}
+
+// Entry is the mapping of the single source user.
+type Entry struct {
+	Source string `json:"source"`           // source user ID
+	Name   string `json:"name,omitempty"`   // source user name, for reference only
+	Target string `json:"target,omitempty"` // target user ID or name
+	Email  string `json:"email,omitempty"`  // target user email
+}
+
+// Map is the user mapping, keyed by the source user ID.
+type Map map[string]Entry
+
+// csvHeader is the header of the CSV file.
+var csvHeader = []string{"source", "name", "target", "email"}
+
+// Load reads the mapping from the file name, the format is detected by the
+// file extension, see FormatOf.
+func Load(name string) (Map, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := Read(f, FormatOf(name))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return m, nil
+}
+
+// Read reads the mapping in format f from r.
+//
+// The CSV has the columns source, name, target and email, lines starting
+// with # are comments.  The columns can be in any order, and all but source
+// are optional.  If the first line is not the header, the columns are source,
+// target and, optionally, email.
+func Read(r io.Reader, f Format) (Map, error) {
+	switch f {
+	case JSON:
+		var entries []Entry
+		if err := json.NewDecoder(r).Decode(&entries); err != nil {
+			return nil, err
+		}
+		return fromEntries(entries)
+	case CSV:
+		return readCSV(r)
+	}
+	return nil, fmt.Errorf("unsupported user mapping format: %q", f)
+}
+
+func readCSV(r io.Reader) (Map, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	recs, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	// column indexes of source, name, target and email, -1 if missing.
+	cols := []int{0, -1, 1, 2}
+	if len(recs) > 0 && isHeader(recs[0]) {
+		cols = []int{-1, -1, -1, -1}
+		for i, name := range recs[0] {
+			for j, h := range csvHeader {
+				if strings.EqualFold(strings.TrimSpace(name), h) {
+					cols[j] = i
+				}
+			}
+		}
+		recs = recs[1:]
+	}
+	field := func(rec []string, col int) string {
+		if col < 0 || col >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[col])
+	}
+	entries := make([]Entry, 0, len(recs))
+	for _, rec := range recs {
+		entries = append(entries, Entry{
+			Source: field(rec, cols[0]),
+			Name:   field(rec, cols[1]),
+			Target: field(rec, cols[2]),
+			Email:  field(rec, cols[3]),
+		})
+	}
+	return fromEntries(entries)
+}
+
+// isHeader returns true if the record is the CSV header, i.e. has the source
+// column.
+func isHeader(rec []string) bool {
+	for _, name := range rec {
+		if strings.EqualFold(strings.TrimSpace(name), csvHeader[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+func fromEntries(entries []Entry) (Map, error) {
+	m := make(Map, len(entries))
+	for _, e := range entries {
+		if e.Source == "" {
+			return nil, errors.New("entry without the source user ID")
+		}
+		if _, ok := m[e.Source]; ok {
+			return nil, fmt.Errorf("duplicate entry for the user %s", e.Source)
+		}
+		m[e.Source] = e
+	}
+	return m, nil
+}
+
+// Entries returns the entries of the mapping sorted by the source user ID.
+func (m Map) Entries() []Entry {
+	entries := make([]Entry, 0, len(m))
+	for _, e := range m {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Source < entries[j].Source })
+	return entries
+}
+
+// Write writes the mapping in format f to w.
+func (m Map) Write(w io.Writer, f Format) error {
+	switch f {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m.Entries())
+	case CSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, e := range m.Entries() {
+			if err := cw.Write([]string{e.Source, e.Name, e.Target, e.Email}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unsupported user mapping format: %q", f)
+}
+
+// Generate drafts the mapping of the users: the target is left empty, and
+// the email is the email of the source user, so that the users are matched
+// by email, unless the target is filled in.  Deleted users and bots are
+// included, as their messages are in the archive.
+func Generate(users types.Users) Map {
+	m := make(Map, len(users))
+	for _, u := range users {
+		name := u.RealName
+		if name == "" {
+			name = u.Name
+		}
+		m[u.ID] = Entry{Source: u.ID, Name: name, Email: u.Profile.Email}
+	}
+	return m
+}
+
+// Resolve returns the mapping of the source user IDs to the user IDs of the
+// target workspace with users dst.  The entry is resolved by its target,
+// which is the user ID or the name, and then by its email.  The source users
+// src, that are not in the mapping, are matched by their own email.  It also
+// returns the sorted source IDs of the entries, that have the target or the
+// email, but don't match any user.
+func (m Map) Resolve(src, dst types.Users) (ids map[string]string, unresolved []string) {
+	var (
+		byID    = make(map[string]bool, len(dst))
+		byName  = make(map[string]string, len(dst))
+		byEmail = make(map[string]string, len(dst))
+	)
+	for _, u := range dst {
+		if u.Deleted {
+			continue
+		}
+		byID[u.ID] = true
+		byName[strings.ToLower(u.Name)] = u.ID
+		if email := strings.ToLower(u.Profile.Email); email != "" {
+			byEmail[email] = u.ID
+		}
+	}
+	lookup := func(target, email string) (string, bool) {
+		if byID[target] {
+			return target, true
+		}
+		if id, ok := byName[strings.ToLower(strings.TrimPrefix(target, "@"))]; ok && target != "" {
+			return id, true
+		}
+		id, ok := byEmail[strings.ToLower(email)]
+		return id, ok && email != ""
+	}
+
+	ids = make(map[string]string)
+	for _, e := range m {
+		if id, ok := lookup(e.Target, e.Email); ok {
+			ids[e.Source] = id
+		} else if e.Target != "" || e.Email != "" {
+			unresolved = append(unresolved, e.Source)
+		}
+	}
+	sort.Strings(unresolved)
+	for _, u := range src {
+		if _, ok := m[u.ID]; ok {
+			continue
+		}
+		if id, ok := lookup("", u.Profile.Email); ok {
+			ids[u.ID] = id
+		}
+	}
+	return ids, unresolved
+}
@@ -0,0 +1,127 @@
+package usermap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func user(id, name, email string) slack.User {
+	var u slack.User
+	u.ID, u.Name, u.Profile.Email = id, name, email
+	return u
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  Format
+		want    Map
+		wantErr bool
+	}{
+		{
+			"csv with header",
+			"source,name,target,email\nU01,Alice,alice.smith,\nU02,Bob,,bob@example.com\n",
+			CSV,
+			Map{
+				"U01": {Source: "U01", Name: "Alice", Target: "alice.smith"},
+				"U02": {Source: "U02", Name: "Bob", Email: "bob@example.com"},
+			},
+			false,
+		},
+		{
+			"csv reordered header",
+			"email,source\nbob@example.com,U02\n",
+			CSV,
+			Map{"U02": {Source: "U02", Email: "bob@example.com"}},
+			false,
+		},
+		{
+			"csv without header",
+			"# source,target\nU01,UX1\n U02 , UX2 , bob@example.com\n",
+			CSV,
+			Map{
+				"U01": {Source: "U01", Target: "UX1"},
+				"U02": {Source: "U02", Target: "UX2", Email: "bob@example.com"},
+			},
+			false,
+		},
+		{
+			"json",
+			`[{"source":"U01","target":"UX1"},{"source":"U02","email":"bob@example.com"}]`,
+			JSON,
+			Map{
+				"U01": {Source: "U01", Target: "UX1"},
+				"U02": {Source: "U02", Email: "bob@example.com"},
+			},
+			false,
+		},
+		{"duplicate", "U01,UX1\nU01,UX2\n", CSV, nil, true},
+		{"no source", `[{"target":"UX1"}]`, JSON, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(strings.NewReader(tt.data), tt.format)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMap_Write(t *testing.T) {
+	m := Generate(types.Users{user("U02", "bob", "bob@example.com"), user("U01", "alice", "")})
+	for _, f := range []Format{CSV, JSON} {
+		t.Run(string(f), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, m.Write(&buf, f))
+			got, err := Read(&buf, f)
+			require.NoError(t, err)
+			assert.Equal(t, m, got)
+		})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, m.Write(&buf, CSV))
+	assert.Equal(t, "source,name,target,email\nU01,alice,,\nU02,bob,,bob@example.com\n", buf.String())
+}
+
+func TestMap_Resolve(t *testing.T) {
+	src := types.Users{
+		user("U01", "alice", "alice@example.com"),
+		user("U02", "bob", "bob@example.com"),
+		user("U03", "carol", "carol@example.com"),
+		user("U04", "dave", ""),
+	}
+	gone := user("UX5", "eve", "eve@example.com")
+	gone.Deleted = true
+	dst := types.Users{
+		user("UX1", "alice.smith", "alice@corp.example.com"),
+		user("UX2", "bob", "Bob@Example.com"),
+		user("UX3", "carol", "carol@example.com"),
+		gone,
+	}
+	m := Map{
+		"U01": {Source: "U01", Target: "@Alice.Smith"},
+		"U02": {Source: "U02", Email: "bob@example.com"},
+		"U03": {Source: "U03"}, // no target, not matched
+		"U05": {Source: "U05", Target: "UX5"},
+		"U06": {Source: "U06", Target: "UX2"},
+	}
+	ids, unresolved := m.Resolve(src, dst)
+	assert.Equal(t, map[string]string{"U01": "UX1", "U02": "UX2", "U06": "UX2"}, ids)
+	assert.Equal(t, []string{"U05"}, unresolved)
+
+	ids, unresolved = Map{}.Resolve(src, dst)
+	assert.Equal(t, map[string]string{"U02": "UX2", "U03": "UX3"}, ids, "matched by email")
+	assert.Empty(t, unresolved)
+}
@@ -0,0 +1,100 @@
+package viewer
+
+// In this file: serving multiple archives.
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// workspace is the entry of the workspace switcher.
+type workspace struct {
+	Name string
+	Base string
+}
+
+// Federation serves several archives under one UI.  Each archive is mounted
+// under its own /w/<name> path, and users are resolved within the archive.
+type Federation struct {
+	mux        *http.ServeMux
+	viewers    []*Viewer
+	workspaces []workspace
+}
+
+// Federate mounts viewers under one handler.  Viewers must not be used on
+// their own after being federated.
+func Federate(viewers ...*Viewer) (*Federation, error) {
+	if len(viewers) == 0 {
+		return nil, errors.New("viewer: no archives to federate")
+	}
+	f := &Federation{
+		viewers: viewers,
+		mux:     http.NewServeMux(),
+	}
+	seen := make(map[string]bool, len(viewers))
+	for _, v := range viewers {
+		slug := uniqSlug(seen, slugify(v.ar.Name()))
+		v.base = strings.TrimSuffix(workspacePrefix, "/") + "/" + slug
+		v.workspaces = &f.workspaces
+		f.workspaces = append(f.workspaces, workspace{Name: v.ar.Name(), Base: v.base})
+		f.mux.Handle(v.base+"/", http.StripPrefix(v.base, v))
+	}
+	f.mux.HandleFunc("/", f.rootHandler)
+	return f, nil
+}
+
+func (f *Federation) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mux.ServeHTTP(w, r)
+}
+
+// rootHandler redirects to the first workspace.  Slack permalinks are
+// resolved in the first workspace that has the channel.
+func (f *Federation) rootHandler(w http.ResponseWriter, r *http.Request) {
+	if pl, ok := parsePermalink(r.URL.Path, r.URL.Query()); ok {
+		for _, v := range f.viewers {
+			if target, err := v.resolve(pl); err == nil {
+				http.Redirect(w, r, target, http.StatusFound)
+				return
+			}
+		}
+		http.Error(w, "message is not in the archives", http.StatusNotFound)
+		return
+	}
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	http.Redirect(w, r, f.workspaces[0].Base+"/", http.StatusFound)
+}
+
+// slugify converts the archive name to the URL path element.
+func slugify(name string) string {
+	s := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		case r == '-' || r == '_' || r == '.':
+			return r
+		}
+		return '-'
+	}, name)
+	s = strings.Trim(s, "-.")
+	if s == "" {
+		s = "archive"
+	}
+	return s
+}
+
+// uniqSlug returns the unique slug, appending the number, if slug was
+// already seen.
+func uniqSlug(seen map[string]bool, slug string) string {
+	s := slug
+	for i := 2; seen[s]; i++ {
+		s = slug + "-" + strconv.Itoa(i)
+	}
+	seen[s] = true
+	return s
+}
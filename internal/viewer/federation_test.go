@@ -0,0 +1,82 @@
+package viewer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/archive"
+)
+
+func TestFederate(t *testing.T) {
+	otherFS := fstest.MapFS{
+		"channels.json":                 {Data: []byte(`[{"id":"C01","name":"other-general"}]`)},
+		"users.json":                    {Data: []byte(`[{"id":"U01","name":"carol","profile":{"display_name":"Carol"}}]`)},
+		"other-general/2023-01-01.json": {Data: []byte(`[{"type":"message","user":"U01","text":"hi from other","ts":"1672531200.000100"}]`)},
+	}
+	ar1, err := archive.New(testFS, "Team One")
+	require.NoError(t, err)
+	ar2, err := archive.New(otherFS, "Team One")
+	require.NoError(t, err)
+	v1, err := New(ar1)
+	require.NoError(t, err)
+	v2, err := New(ar2)
+	require.NoError(t, err)
+
+	f, err := Federate(v1, v2)
+	require.NoError(t, err)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		f.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/")
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/w/team-one/", w.Header().Get("Location"))
+
+	w = get("/w/team-one/c/C01")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "@Alice")
+	assert.Contains(t, w.Body.String(), `href="/w/team-one/t/C01/1672531200.000100"`)
+	assert.Contains(t, w.Body.String(), `href="/w/team-one-2/"`, "workspace switcher")
+
+	w = get("/w/team-one-2/c/C01")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "hi from other")
+	assert.Contains(t, w.Body.String(), "Carol", "users must be resolved within the workspace")
+
+	w = get("/w/team-one/archive/general/attachments/F01-a.txt")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = get("/archives/C01/p1672531300000100")
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/w/team-one/t/C01/1672531200.000100#1672531300.000100", w.Header().Get("Location"))
+
+	w = get("/archives/C99")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	_, err = Federate()
+	assert.Error(t, err)
+}
+
+func Test_slugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"export.zip", "export.zip"},
+		{"My Workspace", "my-workspace"},
+		{"///", "archive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, slugify(tt.name))
+		})
+	}
+}
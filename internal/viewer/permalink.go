@@ -0,0 +1,125 @@
+package viewer
+
+// In this file: Slack permalink resolution.
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// permalinkPrefix is the path prefix of Slack permalinks.
+const permalinkPrefix = "/archives/"
+
+// permalink is the parsed Slack permalink.
+type permalink struct {
+	ChannelID string
+	TS        string // message timestamp, empty for the channel link.
+	ThreadTS  string // optional thread timestamp, from the thread_ts parameter.
+}
+
+// parsePermalink parses the Slack permalink path and the query, i.e.:
+//
+//	/archives/C123/p1672531200000100?thread_ts=1672531100.000100&cid=C123
+//
+// The path may contain anything before the "/archives/", so that the full
+// permalink can be pasted after the viewer address.
+func parsePermalink(p string, query url.Values) (permalink, bool) {
+	i := strings.Index(strings.ToLower(p), permalinkPrefix)
+	if i < 0 {
+		return permalink{}, false
+	}
+	parts := strings.Split(strings.Trim(p[i+len(permalinkPrefix):], "/"), "/")
+	if len(parts) == 0 || len(parts) > 2 || parts[0] == "" {
+		return permalink{}, false
+	}
+	pl := permalink{ChannelID: parts[0], ThreadTS: query.Get("thread_ts")}
+	if len(parts) == 2 {
+		ts, ok := permalinkTS(parts[1])
+		if !ok {
+			return permalink{}, false
+		}
+		pl.TS = ts
+	}
+	return pl, true
+}
+
+// permalinkTS converts the message ID of the permalink, i.e.
+// "p1672531200000100" to the Slack timestamp "1672531200.000100".
+func permalinkTS(id string) (string, bool) {
+	const secLen = 10
+	if len(id) <= secLen+1 || id[0] != 'p' {
+		return "", false
+	}
+	for _, r := range id[1:] {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return id[1:secLen+1] + "." + id[secLen+1:], true
+}
+
+// permalinkHandler redirects the Slack permalink to the archived message.
+func (v *Viewer) permalinkHandler(w http.ResponseWriter, r *http.Request) {
+	pl, ok := parsePermalink(r.URL.Path, r.URL.Query())
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	target, err := v.resolve(pl)
+	if err != nil {
+		http.Error(w, "message is not in the archive: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// resolve returns the viewer URL for the permalink.  Messages within threads
+// resolve to the thread page, so that the thread context is displayed.
+func (v *Viewer) resolve(pl permalink) (string, error) {
+	if _, err := v.ar.Channel(pl.ChannelID); err != nil {
+		return "", err
+	}
+	if pl.TS == "" {
+		return v.page("c", pl.ChannelID), nil
+	}
+	if pl.ThreadTS != "" && pl.ThreadTS != pl.TS {
+		return v.page("t", pl.ChannelID, pl.ThreadTS) + "#" + pl.TS, nil
+	}
+	cnv, err := v.ar.Conversation(pl.ChannelID)
+	if err != nil {
+		return "", err
+	}
+	parent, found := findMessage(cnv.Messages, pl.TS)
+	switch {
+	case !found:
+		return "", errMessageNotFound
+	case parent != nil && parent.Timestamp != pl.TS:
+		return v.page("t", pl.ChannelID, parent.Timestamp) + "#" + pl.TS, nil
+	case parent != nil:
+		return v.page("t", pl.ChannelID, pl.TS), nil
+	}
+	return v.page("c", pl.ChannelID) + "#" + pl.TS, nil
+}
+
+// findMessage looks for the message with the timestamp ts.  If the message
+// is a thread parent or a reply, the parent message is returned.
+func findMessage(msgs []types.Message, ts string) (parent *types.Message, found bool) {
+	for i := range msgs {
+		m := &msgs[i]
+		if m.Timestamp == ts {
+			if len(m.ThreadReplies) > 0 {
+				return m, true
+			}
+			return nil, true
+		}
+		for _, rm := range m.ThreadReplies {
+			if rm.Timestamp == ts {
+				return m, true
+			}
+		}
+	}
+	return nil, false
+}
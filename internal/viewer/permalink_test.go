@@ -0,0 +1,63 @@
+package viewer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parsePermalink(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		query  string
+		want   permalink
+		wantOK bool
+	}{
+		{"channel", "/archives/C01", "", permalink{ChannelID: "C01"}, true},
+		{"message", "/archives/C01/p1672531200000100", "", permalink{ChannelID: "C01", TS: "1672531200.000100"}, true},
+		{"reply", "/archives/C01/p1672531300000100", "thread_ts=1672531200.000100&cid=C01", permalink{ChannelID: "C01", TS: "1672531300.000100", ThreadTS: "1672531200.000100"}, true},
+		{"full url pasted", "/https:/team.slack.com/archives/C01/p1672531200000100", "", permalink{ChannelID: "C01", TS: "1672531200.000100"}, true},
+		{"invalid ts", "/archives/C01/p16725x", "", permalink{}, false},
+		{"too many parts", "/archives/C01/p1672531200000100/x", "", permalink{}, false},
+		{"not a permalink", "/c/C01", "", permalink{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, _ := url.ParseQuery(tt.query)
+			got, ok := parsePermalink(tt.path, q)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestViewer_permalink(t *testing.T) {
+	v := testViewer(t)
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantLoc    string
+	}{
+		{"channel", "/archives/C01", http.StatusFound, "/c/C01"},
+		{"thread parent", "/archives/C01/p1672531200000100", http.StatusFound, "/t/C01/1672531200.000100"},
+		{"reply without thread_ts", "/archives/C01/p1672531300000100", http.StatusFound, "/t/C01/1672531200.000100#1672531300.000100"},
+		{"reply with thread_ts", "/archives/C01/p1672531300000100?thread_ts=1672531200.000100", http.StatusFound, "/t/C01/1672531200.000100#1672531300.000100"},
+		{"pasted after address", "/https:/team.slack.com/archives/C01/p1672531200000100", http.StatusFound, "/t/C01/1672531200.000100"},
+		{"search box", "/search?q=" + url.QueryEscape("https://team.slack.com/archives/C01/p1672531300000100"), http.StatusFound, "/t/C01/1672531200.000100#1672531300.000100"},
+		{"unknown message", "/archives/C01/p1000000000000100", http.StatusNotFound, ""},
+		{"unknown channel", "/archives/C99", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			v.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantLoc, w.Header().Get("Location"))
+		})
+	}
+}
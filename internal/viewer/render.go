@@ -0,0 +1,120 @@
+package viewer
+
+// In this file: template functions.
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/blockkit"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/mrkdwn"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func (v *Viewer) funcMap() template.FuncMap {
+	return template.FuncMap{
+		"channelName":     func(ch *slack.Channel) string { return v.uidx.ChannelName(ch) },
+		"sender":          v.sender,
+		"userName":        v.uidx.DisplayName,
+		"avatar":          v.avatar,
+		"msgTime":         v.msgTime,
+		"mrkdwn":          v.mrkdwn,
+		"text":            func(m types.Message) string { return v.cat.MessageText(&m.Message) },
+		"t":               v.cat.T,
+		"n":               v.cat.N,
+		"lang":            func() string { return v.cat.Lang },
+		"fileURL":         v.fileURL,
+		"link":            v.link,
+		"page":            v.page,
+		"isImage":         isImage,
+		"blocks":          blockkit.Render,
+		"attachmentColor": attachmentColor,
+	}
+}
+
+// sender returns the display name of the message sender.
+func (v *Viewer) sender(m types.Message) string {
+	if name := v.uidx.Sender(&m.Message); name != "" {
+		return name
+	}
+	if m.Username != "" {
+		return m.Username
+	}
+	if m.BotID != "" {
+		return "bot:" + m.BotID
+	}
+	return "unknown"
+}
+
+// avatar returns the avatar URL of the message sender, or an empty string if
+// it's not known.
+func (v *Viewer) avatar(m types.Message) string {
+	if u, ok := v.uidx[m.User]; ok && u.Profile.Image48 != "" {
+		return u.Profile.Image48
+	}
+	if m.Icons != nil && m.Icons.IconURL != "" {
+		return m.Icons.IconURL
+	}
+	return ""
+}
+
+// msgTime returns the time of the message m in the viewer time zone.
+func (v *Viewer) msgTime(m types.Message) string {
+	t, err := structures.ParseSlackTS(m.Timestamp)
+	if err != nil {
+		return m.Timestamp
+	}
+	return t.In(v.loc).Format(v.cat.DateTime)
+}
+
+// msgText returns the text of the message m, with the Block Kit layout, i.e.
+// of the polls and workflows, rendered.  The system messages are not
+// translated, see i18n.Catalog.MessageText.
+func msgText(m types.Message) string {
+	return blockkit.Text(&m.Message)
+}
+
+// mrkdwn converts the Slack markup of the message text to HTML, resolving the
+// user and user group mentions and linking the channel mentions.
+func (v *Viewer) mrkdwn(text string) template.HTML {
+	return template.HTML(mrkdwn.HTML(text, mrkdwn.Resolver{
+		User:       v.uidx.DisplayName,
+		UserGroup:  v.groupName,
+		ChannelURL: func(id string) string { return v.page("c", id) },
+	}))
+}
+
+// groupName returns the handle of the user group id, or an empty string, if
+// the group is not known.
+func (v *Viewer) groupName(id string) string {
+	return v.groups[id]
+}
+
+func isImage(f slack.File) bool {
+	return strings.HasPrefix(f.Mimetype, "image/")
+}
+
+// reHexColor matches the hex colour of the attachment, i.e. "36a64f".
+var reHexColor = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// attachmentColor returns the CSS colour of the attachment colour c, that is
+// either the hex colour, or one of the named colours.  It returns an empty
+// string for the unknown colours.
+func attachmentColor(c string) template.CSS {
+	switch c {
+	case "good":
+		return "#2eb886"
+	case "warning":
+		return "#daa038"
+	case "danger":
+		return "#a30200"
+	}
+	if m := reHexColor.FindStringSubmatch(c); m != nil {
+		return template.CSS("#" + m[1])
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package viewer
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/fts"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// dateFmt is the format of the date filters.
+const dateFmt = "2006-01-02"
+
+// searchResult is a single message found by search.
+type searchResult struct {
+	Channel *slack.Channel
+	Message types.Message
+}
+
+// searchForm is the search form values.
+type searchForm struct {
+	Channel string
+	User    string
+	From    string
+	To      string
+}
+
+// searchHandler serves /search?q=<query>&channel=<id>&user=<name or
+// id>&from=<date>&to=<date>.  It uses the full text index, if it's available,
+// otherwise it scans all the messages in the archive.
+func (v *Viewer) searchHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		q    = strings.TrimSpace(r.URL.Query().Get("q"))
+		form = searchForm{
+			Channel: r.URL.Query().Get("channel"),
+			User:    strings.TrimSpace(r.URL.Query().Get("user")),
+			From:    r.URL.Query().Get("from"),
+			To:      r.URL.Query().Get("to"),
+		}
+	)
+	if u, err := url.Parse(q); err == nil && u.Host != "" {
+		// permalink pasted into the search box.
+		if pl, ok := parsePermalink(u.Path, u.Query()); ok {
+			if target, err := v.resolve(pl); err == nil {
+				http.Redirect(w, r, target, http.StatusFound)
+				return
+			}
+		}
+	}
+	p := v.newPage(v.cat.T("search") + ": " + q)
+	p.Query = q
+	p.Form = form
+	if q == "" {
+		v.render(w, "search.html", p)
+		return
+	}
+	query, err := v.makeQuery(q, form)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var results []searchResult
+	if v.idx != nil {
+		results = v.searchIndex(query, maxSearchResults)
+	} else {
+		results, err = v.search(query, maxSearchResults)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	p.Results = results
+	if len(results) == maxSearchResults {
+		p.Note = v.cat.T("too_many_results")
+	}
+	v.render(w, "search.html", p)
+}
+
+// makeQuery converts the search form values to the index query.  User can
+// be specified as an ID, username or display name.
+func (v *Viewer) makeQuery(q string, form searchForm) (fts.Query, error) {
+	query := fts.Query{
+		Text:      q,
+		ChannelID: form.Channel,
+		UserID:    v.lookupUser(strings.TrimPrefix(form.User, "@")),
+	}
+	if form.From != "" {
+		from, err := time.ParseInLocation(dateFmt, form.From, v.loc)
+		if err != nil {
+			return query, err
+		}
+		query.From = from
+	}
+	if form.To != "" {
+		to, err := time.ParseInLocation(dateFmt, form.To, v.loc)
+		if err != nil {
+			return query, err
+		}
+		query.To = to.AddDate(0, 0, 1) // inclusive
+	}
+	return query, nil
+}
+
+// lookupUser returns the ID of the user with the given ID, username or
+// display name.  If the user is not found, name is returned.
+func (v *Viewer) lookupUser(name string) string {
+	if name == "" {
+		return ""
+	}
+	if _, ok := v.uidx[name]; ok {
+		return name
+	}
+	for _, u := range v.uidx {
+		if strings.EqualFold(u.Name, name) || strings.EqualFold(u.Profile.DisplayName, name) {
+			return u.ID
+		}
+	}
+	return name
+}
+
+// searchIndex searches the full text index.
+func (v *Viewer) searchIndex(q fts.Query, limit int) []searchResult {
+	docs := v.idx.Search(q, limit)
+	results := make([]searchResult, 0, len(docs))
+	for _, d := range docs {
+		ch := v.channel(d.ChannelID)
+		if ch == nil {
+			continue
+		}
+		var m types.Message
+		m.Channel = d.ChannelID
+		m.User = d.User
+		m.Timestamp = d.TS
+		m.ThreadTimestamp = d.ThreadTS
+		m.Text = d.Text
+		results = append(results, searchResult{Channel: ch, Message: m})
+	}
+	return results
+}
+
+// search scans all the messages of the archive.
+func (v *Viewer) search(q fts.Query, limit int) ([]searchResult, error) {
+	var results []searchResult
+	for i := range v.channels {
+		ch := &v.channels[i]
+		if q.ChannelID != "" && q.ChannelID != ch.ID {
+			continue
+		}
+		cnv, err := v.ar.Conversation(ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		var walk func(msgs []types.Message) bool
+		walk = func(msgs []types.Message) bool {
+			for _, m := range msgs {
+				if q.Match(ch.ID, m) {
+					results = append(results, searchResult{Channel: ch, Message: m})
+					if len(results) >= limit {
+						return false
+					}
+				}
+				if !walk(m.ThreadReplies) {
+					return false
+				}
+			}
+			return true
+		}
+		if !walk(cnv.Messages) {
+			break
+		}
+	}
+	return results, nil
+}
+
+// channel returns the channel with the given ID or nil.
+func (v *Viewer) channel(id string) *slack.Channel {
+	for i := range v.channels {
+		if v.channels[i].ID == id {
+			return &v.channels[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package viewer
+
+// In this file: static site generation.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// searchIndexFile is the file name of the client-side search index of the
+// static site.
+const searchIndexFile = "search-index.js"
+
+// staticDoc is the entry of the client-side search index.  Field names are
+// short to keep the index small.
+type staticDoc struct {
+	Channel  string `json:"c"`
+	TS       string `json:"t"`
+	ThreadTS string `json:"th,omitempty"`
+	User     string `json:"u,omitempty"`
+	Sender   string `json:"s"`
+	Text     string `json:"x"`
+}
+
+// staticIndex is the client-side search index.
+type staticIndex struct {
+	Channels map[string]string `json:"channels"` // ID -> display name
+	Docs     []staticDoc       `json:"docs"`
+}
+
+// GenerateStatic renders the viewer for the archive ar into the static
+// HTML pages, that can be hosted on any web server, i.e. GitHub Pages or S3.
+// The search on the static site is done in the browser over the prebuilt
+// index.  Files from the archive are copied to the site.
+func GenerateStatic(ctx context.Context, fsa fsadapter.FS, ar *archive.Archive, opts ...Option) error {
+	v, err := New(ar, opts...)
+	if err != nil {
+		return err
+	}
+	v.static = true
+	return v.generate(ctx, fsa)
+}
+
+func (v *Viewer) generate(ctx context.Context, fsa fsadapter.FS) error {
+	if err := v.writePage(fsa, "index.html", "index.html", v.newPage(v.ar.Name())); err != nil {
+		return err
+	}
+	sp := v.newPage(v.cat.T("search"))
+	if err := v.writePage(fsa, "search.html", "search.html", sp); err != nil {
+		return err
+	}
+
+	sidx := staticIndex{Channels: make(map[string]string, len(v.channels))}
+	for i := range v.channels {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ch := &v.channels[i]
+		sidx.Channels[ch.ID] = v.uidx.ChannelName(ch)
+		cnv, err := v.ar.Conversation(ch.ID)
+		if err != nil {
+			return err
+		}
+		if err := v.writePage(fsa, v.page("c", ch.ID), "channel.html", v.channelPage(ch, cnv)); err != nil {
+			return err
+		}
+		for j := range cnv.Messages {
+			m := &cnv.Messages[j]
+			if len(m.ThreadReplies) == 0 {
+				continue
+			}
+			if err := v.writePage(fsa, v.page("t", ch.ID, m.Timestamp), "thread.html", v.threadPage(ch, m)); err != nil {
+				return err
+			}
+		}
+		sidx.Docs = v.appendDocs(sidx.Docs, ch.ID, cnv.Messages)
+	}
+	if err := writeSearchIndex(fsa, sidx); err != nil {
+		return err
+	}
+	if err := copyFS(fsa, "static", assets, "static", nil); err != nil {
+		return err
+	}
+	// files of the archive, conversation files are skipped, as they are
+	// already rendered.
+	return copyFS(fsa, strings.Trim(archivePrefix, "/"), v.ar.FS(), ".", func(name string) bool {
+		return path.Ext(name) != ".json"
+	})
+}
+
+func (v *Viewer) appendDocs(docs []staticDoc, channelID string, msgs []types.Message) []staticDoc {
+	for _, m := range msgs {
+		docs = append(docs, staticDoc{
+			Channel:  channelID,
+			TS:       m.Timestamp,
+			ThreadTS: m.ThreadTimestamp,
+			User:     m.User,
+			Sender:   v.sender(m),
+			Text:     msgText(m),
+		})
+		docs = v.appendDocs(docs, channelID, m.ThreadReplies)
+	}
+	return docs
+}
+
+// writePage renders the template tmpl to the file name.
+func (v *Viewer) writePage(fsa fsadapter.FS, name string, tmpl string, p page) error {
+	p.Root = strings.Repeat("../", strings.Count(name, "/"))
+	var buf bytes.Buffer
+	if err := v.tmpl.ExecuteTemplate(&buf, tmpl, p); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return fsa.WriteFile(name, buf.Bytes(), 0644)
+}
+
+func writeSearchIndex(fsa fsadapter.FS, sidx staticIndex) error {
+	data, err := json.Marshal(sidx)
+	if err != nil {
+		return err
+	}
+	// the index is loaded as a script, so that the site works when opened
+	// from the local disk, where browsers block fetch requests.
+	var buf bytes.Buffer
+	buf.WriteString("var searchIndex = ")
+	buf.Write(data)
+	buf.WriteString(";\n")
+	return fsa.WriteFile(searchIndexFile, buf.Bytes(), 0644)
+}
+
+// copyFS copies files from the directory root of fsys to the directory dst
+// of fsa.  If filter is not nil, only files for which it returns true are
+// copied.
+func copyFS(fsa fsadapter.FS, dst string, fsys fs.FS, root string, filter func(name string) bool) error {
+	return fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || (filter != nil && !filter(name)) {
+			return nil
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+		if root == "." {
+			rel = name
+		}
+		return copyFile(fsa, path.Join(dst, rel), fsys, name)
+	})
+}
+
+func copyFile(fsa fsadapter.FS, dst string, fsys fs.FS, name string) error {
+	src, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	w, err := fsa.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
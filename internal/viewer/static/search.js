@@ -0,0 +1,97 @@
+// Client-side search for the static site.  The index is loaded from the
+// search-index.js, that defines the searchIndex variable.
+(function () {
+  "use strict";
+  var maxResults = 200;
+  var params = new URLSearchParams(window.location.search);
+  var q = (params.get("q") || "").trim();
+  var channel = params.get("channel") || "";
+  var user = (params.get("user") || "").replace(/^@/, "").toLowerCase();
+  var from = params.get("from") || "";
+  var to = params.get("to") || "";
+  var results = document.getElementById("results");
+
+  // fill in the form inputs.
+  document.querySelectorAll("form input, form select").forEach(function (el) {
+    if (params.has(el.name)) {
+      el.value = params.get(el.name);
+    }
+  });
+
+  if (!q || typeof searchIndex === "undefined") {
+    return;
+  }
+
+  function tokens(s) {
+    return s.toLowerCase().split(/[^\p{L}\p{N}]+/u).filter(function (t) { return t.length >= 2; });
+  }
+
+  function day(ts) {
+    return new Date(parseFloat(ts) * 1000).toISOString().slice(0, 10);
+  }
+
+  function pageURL(doc) {
+    if (doc.th) {
+      return "t/" + doc.c + "/" + doc.th + ".html#" + doc.t;
+    }
+    return "c/" + doc.c + ".html#" + doc.t;
+  }
+
+  var terms = tokens(q);
+  var found = [];
+  for (var i = 0; i < searchIndex.docs.length && found.length < maxResults; i++) {
+    var d = searchIndex.docs[i];
+    if (channel && d.c !== channel) continue;
+    if (user && d.u.toLowerCase() !== user && d.s.toLowerCase() !== user) continue;
+    if (from && day(d.t) < from) continue;
+    if (to && day(d.t) > to) continue;
+    var have = {};
+    tokens(d.x).forEach(function (t) { have[t] = true; });
+    if (terms.length && terms.every(function (t) { return have[t]; })) {
+      found.push(d);
+    }
+  }
+  found.sort(function (a, b) { return b.t < a.t ? -1 : b.t > a.t ? 1 : 0; });
+
+  if (found.length === 0) {
+    results.textContent = results.dataset.nothingFound || "Nothing found.";
+    return;
+  }
+  found.forEach(function (d) {
+    var div = document.createElement("div");
+    div.className = "result";
+    var a = document.createElement("a");
+    a.href = pageURL(d);
+    a.textContent = searchIndex.channels[d.c] || d.c;
+    div.appendChild(a);
+    results.appendChild(div);
+
+    var msg = document.createElement("div");
+    msg.className = "message";
+    var meta = document.createElement("div");
+    meta.className = "meta";
+    var sender = document.createElement("span");
+    sender.className = "sender";
+    sender.textContent = d.s;
+    var time = document.createElement("span");
+    time.className = "time";
+    time.textContent = " " + new Date(parseFloat(d.t) * 1000).toLocaleString();
+    meta.appendChild(sender);
+    meta.appendChild(time);
+    var text = document.createElement("div");
+    text.className = "text";
+    text.textContent = d.x;
+    var body = document.createElement("div");
+    body.className = "body";
+    body.appendChild(meta);
+    body.appendChild(text);
+    msg.appendChild(body);
+    results.appendChild(msg);
+  });
+  if (found.length === maxResults) {
+    var note = document.createElement("p");
+    note.className = "note";
+    note.textContent = "too many results, only the first ones are shown, try refining the query";
+    results.appendChild(note);
+  }
+})();
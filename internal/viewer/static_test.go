@@ -0,0 +1,54 @@
+package viewer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/fsadapter"
+)
+
+func TestGenerateStatic(t *testing.T) {
+	ar, err := archive.New(testFS, "test")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, GenerateStatic(context.Background(), fsadapter.NewDirectory(dir), ar))
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		require.NoError(t, err, name)
+		return string(data)
+	}
+
+	index := read("index.html")
+	assert.Contains(t, index, `href="c/C01.html"`)
+	assert.NotContains(t, index, "<base")
+	assert.Contains(t, index, `action="search.html"`)
+
+	channel := read("c/C01.html")
+	assert.Contains(t, channel, `<base href="../">`)
+	assert.Contains(t, channel, `href="t/C01/1672531200.000100.html"`)
+	assert.Contains(t, channel, `href="archive/general/attachments/F01-a.txt"`)
+
+	thread := read("t/C01/1672531200.000100.html")
+	assert.Contains(t, thread, `<base href="../../">`)
+	assert.Contains(t, thread, "needle in a reply")
+
+	assert.Contains(t, read("search.html"), `src="search-index.js"`)
+	sidx := read(searchIndexFile)
+	assert.True(t, strings.HasPrefix(sidx, "var searchIndex = {"))
+	assert.Contains(t, sidx, "needle in a reply")
+
+	assert.Equal(t, "file contents", read("archive/general/attachments/F01-a.txt"))
+	assert.NotEmpty(t, read("static/style.css"))
+	assert.NotEmpty(t, read("static/search.js"))
+	_, err = os.Stat(filepath.Join(dir, "archive", "channels.json"))
+	assert.True(t, os.IsNotExist(err), "json files must not be copied")
+}
@@ -0,0 +1,363 @@
+// Package viewer implements the web interface for browsing the slackdump
+// archives, see package archive for the supported formats.
+package viewer
+
+import (
+	"embed"
+	"errors"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/fts"
+	"github.com/rusq/slackdump/v2/internal/i18n"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/internal/structures/files"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+//go:embed templates/*.html static/*
+var assets embed.FS
+
+// staticPages maps the viewer paths to the static site pages.
+var staticPages = map[string]string{
+	"/":       "index.html",
+	"/search": "search.html",
+}
+
+var errMessageNotFound = errors.New("message not found")
+
+const (
+	// maxSearchResults is the maximum number of search results displayed.
+	maxSearchResults = 200
+	// archivePrefix is the URL prefix for the files within the archive.
+	archivePrefix = "/archive/"
+	// workspacePrefix is the URL prefix for the federated workspaces.
+	workspacePrefix = "/w/"
+)
+
+// Viewer is the archive viewer http.Handler.
+type Viewer struct {
+	ar   *archive.Archive
+	tmpl *template.Template
+	mux  *http.ServeMux
+	lg   logger.Interface
+	idx  *fts.Index     // optional full text index
+	loc  *time.Location // time zone of the message times
+	cat  *i18n.Catalog  // language of the pages
+	// base is the URL path prefix, under which the viewer is mounted, see
+	// Federate.
+	base       string
+	workspaces *[]workspace // all federated workspaces
+	// static is set when rendering the static site, see GenerateStatic.
+	static bool
+
+	channels []slack.Channel
+	uidx     structures.UserIndex
+	// groups maps the user group ID to its handle, to resolve the @-group
+	// mentions.
+	groups map[string]string
+}
+
+// Option is the viewer option.
+type Option func(*Viewer)
+
+// WithLogger sets the logger for the viewer.
+func WithLogger(lg logger.Interface) Option {
+	return func(v *Viewer) {
+		if lg != nil {
+			v.lg = lg
+		}
+	}
+}
+
+// WithIndex sets the full text index that is used for search.  If it's not
+// set, the search scans all the messages.
+func WithIndex(idx *fts.Index) Option {
+	return func(v *Viewer) {
+		v.idx = idx
+	}
+}
+
+// WithLocation sets the time zone, the message times are displayed in, and
+// the search dates are interpreted in.  The default is the local time zone.
+func WithLocation(loc *time.Location) Option {
+	return func(v *Viewer) {
+		if loc != nil {
+			v.loc = loc
+		}
+	}
+}
+
+// WithCatalog sets the language of the pages, the default is English.
+func WithCatalog(cat *i18n.Catalog) Option {
+	return func(v *Viewer) {
+		if cat != nil {
+			v.cat = cat
+		}
+	}
+}
+
+// New creates a new viewer for the archive ar.
+func New(ar *archive.Archive, opts ...Option) (*Viewer, error) {
+	if ar == nil {
+		return nil, errors.New("viewer: no archive")
+	}
+	v := &Viewer{
+		ar:  ar,
+		lg:  logger.Default,
+		loc: time.Local,
+	}
+	v.cat, _ = i18n.Lookup(i18n.Default)
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	chans, err := ar.Channels()
+	if err != nil {
+		return nil, err
+	}
+	users, err := ar.Users()
+	if err != nil {
+		return nil, err
+	}
+	v.uidx = users.IndexByID()
+	ugs, err := ar.UserGroups()
+	if err != nil {
+		return nil, err
+	}
+	v.groups = make(map[string]string, len(ugs))
+	for _, ug := range ugs {
+		v.groups[ug.ID] = ug.Handle
+	}
+	v.channels = sortChannels(chans, v.uidx)
+
+	tmpl, err := template.New("").Funcs(v.funcMap()).ParseFS(assets, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+	v.tmpl = tmpl
+
+	static, err := fs.Sub(assets, "static")
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", v.indexHandler)
+	mux.HandleFunc("/c/", v.channelHandler)
+	mux.HandleFunc("/t/", v.threadHandler)
+	mux.HandleFunc("/search", v.searchHandler)
+	mux.HandleFunc(permalinkPrefix, v.permalinkHandler)
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(static))))
+	mux.Handle(archivePrefix, http.StripPrefix(archivePrefix, http.FileServer(http.FS(ar.FS()))))
+	v.mux = mux
+
+	return v, nil
+}
+
+func (v *Viewer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mux.ServeHTTP(w, r)
+}
+
+// sortChannels sorts channels, so that public channels come first, then
+// private, then group and direct messages.  Within the group, channels are
+// sorted by name.
+func sortChannels(chans []slack.Channel, uidx structures.UserIndex) []slack.Channel {
+	rank := func(ch *slack.Channel) int {
+		switch {
+		case ch.IsIM:
+			return 3
+		case ch.IsMpIM:
+			return 2
+		case ch.IsPrivate || ch.IsGroup:
+			return 1
+		}
+		return 0
+	}
+	sorted := append([]slack.Channel{}, chans...)
+	for i := range sorted {
+		// exports do not always have the normalized name, which is used by
+		// ChannelName.
+		if sorted[i].NameNormalized == "" {
+			sorted[i].NameNormalized = sorted[i].Name
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ri, rj := rank(&sorted[i]), rank(&sorted[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return uidx.ChannelName(&sorted[i]) < uidx.ChannelName(&sorted[j])
+	})
+	return sorted
+}
+
+// page is the data passed to the page templates.
+type page struct {
+	Title    string
+	Archive  string
+	Channels []slack.Channel
+	Current  string // current channel ID
+	Channel  *slack.Channel
+	Messages []types.Message
+	Thread   *types.Message
+	Query    string
+	Form     searchForm
+	Results  []searchResult
+	Note     string
+
+	Base       string
+	Workspaces []workspace
+
+	// Root is the relative path to the root of the static site, it is set
+	// only when rendering the static site.
+	Root   string
+	Static bool
+}
+
+func (v *Viewer) newPage(title string) page {
+	p := page{
+		Title:    title,
+		Archive:  v.ar.Name(),
+		Channels: v.channels,
+		Base:     v.base,
+		Static:   v.static,
+	}
+	if v.workspaces != nil {
+		p.Workspaces = *v.workspaces
+	}
+	return p
+}
+
+func (v *Viewer) render(w http.ResponseWriter, name string, p page) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := v.tmpl.ExecuteTemplate(w, name, p); err != nil {
+		v.lg.Printf("viewer: template %s: %s", name, err)
+	}
+}
+
+// channelPage returns the page with the conversation.
+func (v *Viewer) channelPage(ch *slack.Channel, cnv *types.Conversation) page {
+	p := v.newPage(v.uidx.ChannelName(ch))
+	p.Current = ch.ID
+	p.Channel = ch
+	p.Messages = cnv.Messages
+	return p
+}
+
+// threadPage returns the page with the thread.
+func (v *Viewer) threadPage(ch *slack.Channel, parent *types.Message) page {
+	p := v.newPage(v.uidx.ChannelName(ch) + " thread")
+	p.Current = ch.ID
+	p.Channel = ch
+	p.Thread = parent
+	return p
+}
+
+func (v *Viewer) indexHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := parsePermalink(r.URL.Path, r.URL.Query()); ok {
+		// full permalink pasted after the viewer address.
+		v.permalinkHandler(w, r)
+		return
+	}
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	v.render(w, "index.html", v.newPage(v.ar.Name()))
+}
+
+// channelHandler serves /c/<channel_id>.
+func (v *Viewer) channelHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/c/"), "/")
+	ch, cnv, ok := v.conversation(w, r, id)
+	if !ok {
+		return
+	}
+	v.render(w, "channel.html", v.channelPage(&ch, cnv))
+}
+
+// threadHandler serves /t/<channel_id>/<thread_ts>.
+func (v *Viewer) threadHandler(w http.ResponseWriter, r *http.Request) {
+	id, ts, found := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/t/"), "/"), "/")
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	ch, cnv, ok := v.conversation(w, r, id)
+	if !ok {
+		return
+	}
+	for i := range cnv.Messages {
+		if cnv.Messages[i].Timestamp == ts {
+			v.render(w, "thread.html", v.threadPage(&ch, &cnv.Messages[i]))
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// conversation loads the conversation, if an error occurs, it is written to w
+// and ok is false.
+func (v *Viewer) conversation(w http.ResponseWriter, r *http.Request, id string) (ch slack.Channel, cnv *types.Conversation, ok bool) {
+	ch, err := v.ar.Channel(id)
+	if err != nil {
+		http.NotFound(w, r)
+		return ch, nil, false
+	}
+	cnv, err = v.ar.Conversation(id)
+	if err != nil {
+		v.lg.Printf("viewer: conversation %s: %s", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return ch, nil, false
+	}
+	return ch, cnv, true
+}
+
+// link returns the URL path within the viewer, parts are concatenated.  On
+// the static site, links are relative to the site root.
+func (v *Viewer) link(parts ...string) string {
+	p := strings.Join(parts, "")
+	if v.static {
+		if name, ok := staticPages[p]; ok {
+			return name
+		}
+		return strings.TrimPrefix(p, "/")
+	}
+	return v.base + p
+}
+
+// page returns the link to the page of the kind ("c" for channel, "t" for
+// thread) with the given IDs.
+func (v *Viewer) page(kind string, ids ...string) string {
+	p := v.link("/", kind, "/", strings.Join(ids, "/"))
+	if v.static {
+		p += ".html"
+	}
+	return p
+}
+
+// fileURL returns the URL for the file reference p.  Local files are
+// served from the archive, the embedded files (see slackdump.WithEmbedFiles)
+// are passed as is, except the ones, that could run scripts.
+func (v *Viewer) fileURL(p string) any {
+	if strings.HasPrefix(p, "data:") {
+		if mimetype, _, _ := strings.Cut(strings.TrimPrefix(p, "data:"), ";"); strings.Contains(mimetype, "html") || strings.Contains(mimetype, "svg") {
+			return p
+		}
+		return template.URL(p)
+	}
+	if !files.IsLocal(p) {
+		return p
+	}
+	return v.link(archivePrefix, strings.TrimPrefix(path.Clean(p), "/"))
+}
@@ -0,0 +1,185 @@
+package viewer
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/archive"
+	"github.com/rusq/slackdump/v2/internal/fts"
+	"github.com/rusq/slackdump/v2/internal/i18n"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+var testFS = fstest.MapFS{
+	"channels.json": {Data: []byte(`[{"id":"C01","name":"general"}]`)},
+	"users.json":    {Data: []byte(`[{"id":"U01","name":"alice","profile":{"display_name":"Alice","image_48":"https://example.com/a.png"}}]`)},
+	"general/2023-01-01.json": {Data: []byte(`[
+		{"type":"message","user":"U01","text":"parent <@U01>","ts":"1672531200.000100","thread_ts":"1672531200.000100","reply_count":1,
+		 "files":[{"id":"F01","name":"a.txt","url_private":"attachments/F01-a.txt"}],
+		 "slackdump_file_comments":{"F01":[{"id":"Fc01","user":"U01","comment":"legacy comment"}]},
+		 "attachments":[{"color":"good","title":"Example","title_link":"https://example.com","text":"preview <@U01>","fields":[{"title":"Status","value":"Open"}]}]},
+		{"type":"message","user":"U01","text":"needle in a reply","ts":"1672531300.000100","thread_ts":"1672531200.000100"}
+	]`)},
+	"general/attachments/F01-a.txt": {Data: []byte("file contents")},
+}
+
+func testViewer(t *testing.T) *Viewer {
+	t.Helper()
+	ar, err := archive.New(testFS, "test")
+	require.NoError(t, err)
+	v, err := New(ar)
+	require.NoError(t, err)
+	return v
+}
+
+func TestViewer_ServeHTTP(t *testing.T) {
+	v := testViewer(t)
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   []string
+	}{
+		{"index", "/", http.StatusOK, []string{"#general", `href="/c/C01"`}},
+		{"channel", "/c/C01", http.StatusOK, []string{"parent", "@Alice", "1 reply", "/archive/general/attachments/F01-a.txt", "legacy comment", "https://example.com/a.png"}},
+		{"attachment", "/c/C01", http.StatusOK, []string{
+			`<div class="attachment" style="border-left-color: #2eb886">`,
+			`<a href="https://example.com" rel="noreferrer" target="_blank">Example</a>`,
+			`preview <span class="mention">@Alice</span>`,
+			`<span class="field-title">Status</span> Open`,
+		}},
+		{"unknown channel", "/c/C99", http.StatusNotFound, nil},
+		{"thread", "/t/C01/1672531200.000100", http.StatusOK, []string{"needle in a reply"}},
+		{"unknown thread", "/t/C01/1.000", http.StatusNotFound, nil},
+		{"search", "/search?q=NEEDLE", http.StatusOK, []string{"needle in a reply"}},
+		{"file", "/archive/general/attachments/F01-a.txt", http.StatusOK, []string{"file contents"}},
+		{"static", "/static/style.css", http.StatusOK, []string{"sidebar"}},
+		{"not found", "/nonexistent", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			v.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			resp := w.Result()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			for _, want := range tt.wantBody {
+				assert.Contains(t, string(body), want)
+			}
+		})
+	}
+}
+
+func TestViewer_catalog(t *testing.T) {
+	ar, err := archive.New(testFS, "test")
+	require.NoError(t, err)
+	de, err := i18n.Lookup("de")
+	require.NoError(t, err)
+	v, err := New(ar, WithCatalog(de))
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/c/C01", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<html lang="de">`)
+	assert.Contains(t, w.Body.String(), "1 Antwort")
+	assert.Contains(t, w.Body.String(), `placeholder="Suche"`)
+}
+
+func TestViewer_msgTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	v := &Viewer{loc: berlin}
+	v.cat, _ = i18n.Lookup("en")
+	// 12:00 UTC, summer time in Berlin.
+	m := types.Message{Message: slack.Message{Msg: slack.Msg{Timestamp: "1688212800.000100"}}}
+	assert.Equal(t, "2023-07-01 14:00:00", v.msgTime(m))
+	v.cat, _ = i18n.Lookup("de")
+	assert.Equal(t, "01.07.2023 14:00:00", v.msgTime(m))
+}
+
+func TestViewer_mrkdwn(t *testing.T) {
+	v := &Viewer{
+		uidx:   structures.NewUserIndex([]slack.User{{ID: "U01", Name: "alice", Profile: slack.UserProfile{DisplayName: "Alice"}}}),
+		groups: map[string]string{"S01": "everyone"},
+	}
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain", "hello", "hello"},
+		{"escaped", "a &lt;b&gt; &amp; c", "a &lt;b&gt; &amp; c"},
+		{"user", "hi <@U01>", `hi <span class="mention">@Alice</span>`},
+		{"channel", "see <#C01|general>", `see <a class="mention" href="/c/C01">#general</a>`},
+		{"special", "<!here>", `<span class="mention">@here</span>`},
+		{"user group", "hi <!subteam^S01>", `hi <span class="mention">@everyone</span>`},
+		{"user group with label", "<!subteam^S02|@admins>", `<span class="mention">@admins</span>`},
+		{"unknown user group", "<!subteam^S02>", `<span class="mention">@S02</span>`},
+		{"link", "<https://example.com|site>", `<a href="https://example.com" rel="noreferrer" target="_blank">site</a>`},
+		{"unsafe link", "<javascript:alert(1)|click>", "click"},
+		{"newline", "a\nb", "a<br>b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(v.mrkdwn(tt.text)))
+		})
+	}
+}
+
+func TestViewer_search(t *testing.T) {
+	ar, err := archive.New(testFS, "test")
+	require.NoError(t, err)
+	idx, err := fts.Build(context.Background(), ar)
+	require.NoError(t, err)
+
+	for name, opts := range map[string][]Option{"scan": nil, "index": {WithIndex(idx)}} {
+		t.Run(name, func(t *testing.T) {
+			v, err := New(ar, append(opts, WithLocation(time.UTC))...)
+			require.NoError(t, err)
+			tests := []struct {
+				name    string
+				query   string
+				wantHit bool
+			}{
+				{"found", "q=needle", true},
+				{"user by name", "q=needle&user=alice", true},
+				{"other user", "q=needle&user=U99", false},
+				{"channel", "q=needle&channel=C01", true},
+				{"date range", "q=needle&from=2023-01-01&to=2023-01-01", true},
+				{"date outside", "q=needle&from=2023-01-02", false},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					w := httptest.NewRecorder()
+					v.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search?"+tt.query, nil))
+					require.Equal(t, http.StatusOK, w.Code)
+					assert.Equal(t, tt.wantHit, strings.Contains(w.Body.String(), "needle in a reply"))
+				})
+			}
+		})
+	}
+	w := httptest.NewRecorder()
+	testViewer(t).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search?q=x&from=bad", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_attachmentColor(t *testing.T) {
+	assert.Equal(t, template.CSS("#a30200"), attachmentColor("danger"))
+	assert.Equal(t, template.CSS("#36a64f"), attachmentColor("36a64f"))
+	assert.Equal(t, template.CSS("#fff"), attachmentColor("#fff"))
+	assert.Equal(t, template.CSS(""), attachmentColor("red;background:url(x)"))
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// jsonWriter writes each log message as the JSON object on its own line.
+type jsonWriter struct {
+	w io.Writer
+}
+
+type jsonRecord struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"msg"`
+}
+
+// JSON returns the writer, that writes each log message, written to it, as a
+// JSON object on its own line, i.e. {"time":"...","msg":"..."}, for the log
+// collectors.  The logger should have no flags, as the time is added by the
+// writer:
+//
+//	lg.SetFlags(0)
+//	lg.SetOutput(logger.JSON(os.Stderr))
+func JSON(w io.Writer) io.Writer {
+	return jsonWriter{w: w}
+}
+
+func (j jsonWriter) Write(p []byte) (int, error) {
+	b, err := json.Marshal(jsonRecord{Time: time.Now().UTC(), Message: strings.TrimRight(string(p), "\n")})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := j.w.Write(append(b, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
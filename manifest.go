@@ -0,0 +1,59 @@
+package slackdump
+
+// In this file: the archive manifest.
+
+import (
+	"time"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+)
+
+// ManifestFile is the name of the manifest file in the root of the archive.
+const ManifestFile = "manifest.json"
+
+// Manifest describes how the archive was created.  It is written into the
+// root of the archive by Archive, the export and the dump mode of the
+// slackdump command.
+type Manifest struct {
+	Created time.Time `json:"created"`
+	TeamID  string    `json:"team_id,omitempty"`
+	URL     string    `json:"url,omitempty"` // workspace URL
+	// UserAgent is the User-Agent of the API requests, it is empty if
+	// the net/http default was used.
+	UserAgent string `json:"user_agent,omitempty"`
+	// FilesSkipped are the IDs of the files, that were not downloaded,
+	// because the token has no files:read scope.  Only the metadata of
+	// these files is in the archive.
+	FilesSkipped []string `json:"files_skipped,omitempty"`
+}
+
+// Manifest returns the manifest of the archive, that is created by the
+// session now.
+func (sd *Session) Manifest() Manifest {
+	m := Manifest{
+		Created:      time.Now().UTC(),
+		UserAgent:    sd.UserAgent(),
+		FilesSkipped: sd.skippedFiles(),
+	}
+	if sd.wspInfo != nil {
+		m.TeamID = sd.wspInfo.TeamID
+		m.URL = sd.wspInfo.URL
+	}
+	return m
+}
+
+// WriteManifest writes the manifest m into the root of the filesystem fs, and
+// records the creation of the archive in the custody log, see AppendCustody.
+func WriteManifest(fs fsadapter.FS, m Manifest) error {
+	if err := writeJSON(fs, ManifestFile, m); err != nil {
+		return err
+	}
+	var details string
+	if m.TeamID != "" {
+		details = "workspace " + m.TeamID
+		if m.URL != "" {
+			details += " (" + m.URL + ")"
+		}
+	}
+	return AppendCustody(fs, NewCustodyRecord(CustodyCreated, details))
+}
@@ -0,0 +1,42 @@
+package slackdump
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+)
+
+func TestWriteManifest(t *testing.T) {
+	sd := &Session{
+		wspInfo: &slack.AuthTestResponse{TeamID: "T01", URL: "https://example.slack.com/"},
+		options: DefOptions,
+	}
+	WithUserAgent("corp-agent/1.0")(&sd.options)
+
+	dir := t.TempDir()
+	m := sd.Manifest()
+	require.NoError(t, WriteManifest(fsadapter.NewDirectory(dir), m))
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	require.NoError(t, err)
+	var got Manifest
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "T01", got.TeamID)
+	assert.Equal(t, "https://example.slack.com/", got.URL)
+	assert.Equal(t, "corp-agent/1.0", got.UserAgent)
+	assert.True(t, m.Created.Equal(got.Created))
+
+	recs := readCustody(t, filepath.Join(dir, CustodyFile))
+	require.Len(t, recs, 1)
+	assert.Equal(t, CustodyCreated, recs[0].Operation)
+	assert.Equal(t, "workspace T01 (https://example.slack.com/)", recs[0].Details)
+
+	assert.NotPanics(t, func() { (&Session{}).Manifest() }, "session without the workspace info")
+}
@@ -13,7 +13,9 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/otrace"
 	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/progress"
 	"github.com/rusq/slackdump/v2/types"
 )
 
@@ -61,7 +63,7 @@ func (sd *Session) DumpRaw(ctx context.Context, link string, oldest, latest time
 	return sd.dump(ctx, sl, oldest, latest, processFn...)
 }
 
-func (sd *Session) dump(ctx context.Context, sl structures.SlackLink, oldest, latest time.Time, processFn ...ProcessFunc) (*types.Conversation, error) {
+func (sd *Session) dump(ctx context.Context, sl structures.SlackLink, oldest, latest time.Time, processFn ...ProcessFunc) (cnv *types.Conversation, err error) {
 	ctx, task := trace.NewTask(ctx, "dump")
 	defer task.End()
 	trace.Logf(ctx, "info", "sl: %q", sl)
@@ -69,18 +71,83 @@ func (sd *Session) dump(ctx context.Context, sl structures.SlackLink, oldest, la
 		return nil, errors.New("invalid link")
 	}
 
+	kind, id := skipItem(sl)
+	if reason, ok := sd.options.SkipList.Skipped(kind, id); ok {
+		return nil, skipListed(kind, id, reason)
+	}
+
+	sd.pr().ChannelStarted(ctx, sl.String())
+	defer func() { sd.pr().ChannelFinished(ctx, sl.String(), err) }()
+	defer func() {
+		var te *threadError
+		if err == nil {
+			sd.skipClear(kind, id)
+		} else if !errors.As(err, &te) {
+			// the failed thread is on the skip-list by itself.
+			sd.skipFail(ctx, kind, id, err)
+		}
+	}()
 	if sl.IsThread() {
-		return sd.dumpThreadAsConversation(ctx, sl, oldest, latest, processFn...)
+		cnv, err = sd.dumpThreadAsConversation(ctx, sl, oldest, latest, processFn...)
 	} else {
-		return sd.dumpChannel(ctx, sl.Channel, oldest, latest, processFn...)
+		cnv, err = sd.dumpChannel(ctx, sl.Channel, oldest, latest, processFn...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sd.skipFiles(cnv.Messages)
+	if sd.options.Permalinks && sd.wspInfo != nil {
+		setPermalinks(sd.wspInfo.URL, cnv.ID, cnv.Messages)
+	}
+	if sd.options.ReactionsThreshold > 0 {
+		if err := sd.populateReactions(ctx, cnv.ID, cnv.Messages, sd.options.ReactionsThreshold); err != nil {
+			return nil, err
+		}
+	}
+	if sd.options.FileComments {
+		if err := sd.populateFileComments(ctx, cnv.Messages); err != nil {
+			return nil, err
+		}
+	}
+	return cnv, nil
+}
+
+// setPermalinks sets the permalink of the messages msgs and their thread
+// replies in the channel channelID on the workspace baseURL.  Permalinks
+// that are already set are preserved.
+func setPermalinks(baseURL, channelID string, msgs []types.Message) {
+	for i := range msgs {
+		if msgs[i].Permalink == "" {
+			msgs[i].Permalink = structures.Permalink(baseURL, channelID, msgs[i].Timestamp, msgs[i].ThreadTimestamp)
+		}
+		setPermalinks(baseURL, channelID, msgs[i].ThreadReplies)
 	}
 }
 
 // dumpChannel fetches messages from the conversation identified by channelID.
 // processFn will be called on each batch of messages returned from API.
 func (sd *Session) dumpChannel(ctx context.Context, channelID string, oldest, latest time.Time, processFn ...ProcessFunc) (*types.Conversation, error) {
+	messages, err := sd.fetchChannel(ctx, channelID, oldest, latest, processFn...)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := sd.getChannelName(ctx, sd.limiter(network.Tier3), channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Conversation{Name: name, Messages: messages, ID: channelID}, nil
+}
+
+// fetchChannel fetches messages from the conversation identified by channelID
+// and returns them sorted.  processFn will be called on each batch of
+// messages returned from API, after the thread replies are populated.
+func (sd *Session) fetchChannel(ctx context.Context, channelID string, oldest, latest time.Time, processFn ...ProcessFunc) (_ []types.Message, err error) {
 	ctx, task := trace.NewTask(ctx, "dumpMessages")
 	defer task.End()
+	ctx, span := otrace.Start(ctx, "conversation.fetch", otrace.String("channel.id", channelID))
+	defer func() { span.End(err) }()
 
 	if channelID == "" {
 		return nil, errors.New("channelID is empty")
@@ -90,13 +157,19 @@ func (sd *Session) dumpChannel(ctx context.Context, channelID string, oldest, la
 
 	var (
 		// slack rate limits are per method, so we're safe to use different limiters for different mehtods.
-		convLimiter   = sd.limiter(network.Tier3)
-		threadLimiter = sd.limiter(network.Tier3)
+		convLimiter   = sd.pooledLimiter(network.Tier3)
+		threadLimiter = sd.pooledLimiter(network.Tier3)
 	)
 
 	// add thread dumper.  It should go first, because it populates message
-	// chunk with thread messages.
-	pfns := append([]ProcessFunc{sd.newThreadProcessFn(ctx, threadLimiter, oldest, latest)}, processFn...)
+	// chunk with thread messages.  With the deferred schedule, the threads,
+	// and the processors, that may need the replies, run once the history is
+	// complete.
+	threadFn := sd.newThreadProcessFn(ctx, threadLimiter, oldest, latest)
+	var pfns []ProcessFunc
+	if sd.options.ThreadSchedule == TSInterleaved {
+		pfns = append([]ProcessFunc{threadFn}, processFn...)
+	}
 
 	var (
 		messages   []types.Message
@@ -104,6 +177,9 @@ func (sd *Session) dumpChannel(ctx context.Context, channelID string, oldest, la
 		fetchStart = time.Now()
 	)
 	for i := 1; ; i++ {
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
 		var (
 			resp *slack.GetConversationHistoryResponse
 		)
@@ -118,6 +194,8 @@ func (sd *Session) dumpChannel(ctx context.Context, channelID string, oldest, la
 					Oldest:    structures.FormatSlackTS(oldest),
 					Latest:    structures.FormatSlackTS(latest),
 					Inclusive: true,
+
+					IncludeAllMetadata: sd.options.Metadata,
 				})
 			})
 			if err != nil {
@@ -125,11 +203,11 @@ func (sd *Session) dumpChannel(ctx context.Context, channelID string, oldest, la
 			}
 			return nil
 		}); err != nil {
-			return nil, err
+			return nil, network.Classify(channelID, err)
 		}
 		if !resp.Ok {
 			trace.Logf(ctx, "error", "not ok, api error=%s", resp.Error)
-			return nil, fmt.Errorf("response not ok, slack error: %s", resp.Error)
+			return nil, network.Classify(channelID, fmt.Errorf("response not ok, slack error: %w", slack.SlackErrorResponse{Err: resp.Error}))
 		}
 
 		chunk := types.ConvertMsgs(resp.Messages)
@@ -141,39 +219,54 @@ func (sd *Session) dumpChannel(ctx context.Context, channelID string, oldest, la
 
 		messages = append(messages, chunk...)
 
-		sd.l().Printf("messages request #%5d, fetched: %4d (%s), total: %8d (speed: %6.2f/sec, avg: %6.2f/sec)\n",
-			i, len(resp.Messages), results, len(messages),
-			float64(len(resp.Messages))/float64(time.Since(reqStart).Seconds()),
-			float64(len(messages))/float64(time.Since(fetchStart).Seconds()),
-		)
+		sd.pr().MessagesFetched(ctx, progress.Fetch{
+			ChannelID: channelID,
+			Request:   i,
+			Count:     len(resp.Messages),
+			Total:     len(messages),
+			Took:      time.Since(reqStart),
+			Elapsed:   time.Since(fetchStart),
+			Processed: results.String(),
+			Done:      !resp.HasMore,
+		})
 
 		if !resp.HasMore {
-			sd.l().Printf("messages fetch complete, total: %d", len(messages))
 			break
 		}
 
 		cursor = resp.ResponseMetaData.NextCursor
 	}
 
+	if sd.options.ThreadSchedule != TSInterleaved {
+		results, err := runProcessFuncs(messages, channelID, append([]ProcessFunc{threadFn}, processFn...)...)
+		if err != nil {
+			return nil, err
+		}
+		sd.l().Debugf("channel %s: %s", channelID, results)
+	}
+
 	types.SortMessages(messages)
+	span.SetAttr(otrace.Int("messages", len(messages)))
 
-	name, err := sd.getChannelName(ctx, sd.limiter(network.Tier3), channelID)
+	return messages, nil
+}
+
+func (sd *Session) getChannelName(ctx context.Context, l *rate.Limiter, channelID string) (string, error) {
+	ci, err := sd.getChannelInfo(ctx, l, channelID)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	return &types.Conversation{Name: name, Messages: messages, ID: channelID}, nil
+	return ci.Name, nil
 }
 
-func (sd *Session) getChannelName(ctx context.Context, l *rate.Limiter, channelID string) (string, error) {
-	// get channel name
+func (sd *Session) getChannelInfo(ctx context.Context, l *rate.Limiter, channelID string) (*slack.Channel, error) {
 	var ci *slack.Channel
 	if err := network.WithRetry(ctx, l, sd.options.Tier3Retries, func() error {
 		var err error
 		ci, err = sd.client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channelID})
 		return err
 	}); err != nil {
-		return "", err
+		return nil, network.Classify(channelID, err)
 	}
-	return ci.Name, nil
+	return ci, nil
 }
@@ -3,6 +3,7 @@ package slackdump
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/rusq/slackdump/v2/internal/fixtures"
 	"github.com/rusq/slackdump/v2/internal/network"
 	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/progress"
 	"github.com/rusq/slackdump/v2/types"
 )
 
@@ -546,3 +548,83 @@ func TestMessage_IsThreadChild(t *testing.T) {
 		})
 	}
 }
+
+// recReporter records the progress events.
+type recReporter struct {
+	progress.Nop
+	events []string
+	fetch  []progress.Fetch
+}
+
+func (r *recReporter) ChannelStarted(_ context.Context, link string) {
+	r.events = append(r.events, "start "+link)
+}
+
+func (r *recReporter) ChannelFinished(_ context.Context, link string, err error) {
+	r.events = append(r.events, fmt.Sprintf("finish %s %v", link, err))
+}
+
+func (r *recReporter) MessagesFetched(_ context.Context, f progress.Fetch) {
+	r.fetch = append(r.fetch, f)
+}
+
+func TestSession_Dump_progress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mc := newmockClienter(ctrl)
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+		&slack.GetConversationHistoryResponse{
+			Messages:      []slack.Message{testMsg1.Message, testMsg2.Message},
+			SlackResponse: slack.SlackResponse{Ok: true},
+		},
+		nil,
+	)
+	mockConvInfo(mc, "CHM82GF99", "unittest")
+
+	rec := &recReporter{}
+	opts := DefOptions
+	opts.Progress = rec
+	sd := &Session{client: mc, options: opts}
+	_, err := sd.DumpAll(context.Background(), "CHM82GF99")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"start CHM82GF99", "finish CHM82GF99 <nil>"}, rec.events)
+	if assert.Len(t, rec.fetch, 1) {
+		f := rec.fetch[0]
+		assert.Equal(t, "CHM82GF99", f.ChannelID)
+		assert.Equal(t, 1, f.Request)
+		assert.Equal(t, 2, f.Total)
+		assert.True(t, f.Done)
+		assert.False(t, f.IsThread())
+	}
+}
+
+func TestSession_Dump_metadata(t *testing.T) {
+	msg := testMsg1.Message
+	msg.Metadata = slack.SlackMetadata{
+		EventType:    "task_created",
+		EventPayload: map[string]interface{}{"id": "TASK-1"},
+	}
+	mc := newmockClienter(gomock.NewController(t))
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), &slack.GetConversationHistoryParameters{
+		ChannelID:          "CHM82GF99",
+		Limit:              DefOptions.ConversationsPerReq,
+		Inclusive:          true,
+		IncludeAllMetadata: true,
+	}).Return(
+		&slack.GetConversationHistoryResponse{
+			Messages:      []slack.Message{msg},
+			SlackResponse: slack.SlackResponse{Ok: true},
+		},
+		nil,
+	)
+	mockConvInfo(mc, "CHM82GF99", "unittest")
+
+	opts := DefOptions
+	opts.Metadata = true
+	sd := &Session{client: mc, options: opts}
+	conv, err := sd.DumpAll(context.Background(), "CHM82GF99")
+	if !assert.NoError(t, err) || !assert.Len(t, conv.Messages, 1) {
+		return
+	}
+	assert.Equal(t, msg.Metadata, conv.Messages[0].Metadata)
+}
@@ -0,0 +1,174 @@
+// Package mrkdwn parses the Slack message text markup, "mrkdwn", and renders
+// it as HTML, Markdown or plain text.
+//
+// The parser recognises the user, channel and user group mentions, the
+// special mentions, i.e. @here, the links, the inline code and the code
+// blocks and the emoji shortcodes.  The IDs in the mentions are resolved to
+// the names by the Resolver, that is provided by the caller, i.e. from the
+// users of the archive.  The text styles, i.e. *bold*, are not parsed and
+// are output as is.
+//
+// Slack escapes "&", "<" and ">" in the message text, the parser reverses
+// the escaping, so the text of the nodes is the text as the user typed it.
+package mrkdwn
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kind is the kind of the node.
+type Kind uint8
+
+const (
+	Text           Kind = iota // plain text.
+	UserMention                // <@U123>, ID is the user ID.
+	ChannelMention             // <#C123>, ID is the channel ID.
+	GroupMention               // <!subteam^S123>, ID is the user group ID.
+	SpecialMention             // <!here>, ID is the mention, i.e. "here".
+	Link                       // <https://example.com|label>, ID is the URL.
+	Code                       // `code`
+	CodeBlock                  // ```code```
+	Emoji                      // :smile:, ID is the name, i.e. "+1::skin-tone-2".
+)
+
+// Node is the element of the parsed text.
+type Node struct {
+	Kind Kind
+	// Text is the text of the Text, Code and CodeBlock nodes, or the label,
+	// if any, of the mentions and the links.
+	Text string
+	// ID is the ID of the mention, the URL of the link, or the name of the
+	// emoji.
+	ID string
+}
+
+// Parse parses the message text into the list of nodes.  Adjacent text is
+// merged into one Text node.
+func Parse(text string) []Node {
+	const fence = "```"
+	var nodes []Node
+	for text != "" {
+		i := strings.Index(text, fence)
+		if i < 0 {
+			break
+		}
+		j := strings.Index(text[i+len(fence):], fence)
+		if j < 0 {
+			break
+		}
+		nodes = parseInline(nodes, text[:i])
+		code := text[i+len(fence) : i+len(fence)+j]
+		code = strings.TrimSuffix(strings.TrimPrefix(code, "\n"), "\n")
+		nodes = append(nodes, Node{Kind: CodeBlock, Text: unescape(code)})
+		text = text[i+len(fence)+j+len(fence):]
+	}
+	return parseInline(nodes, text)
+}
+
+// parseInline parses the text outside of the code blocks.
+func parseInline(nodes []Node, s string) []Node {
+	for s != "" {
+		i := strings.IndexByte(s, '`')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(s[i+1:], '`')
+		if j <= 0 || strings.Contains(s[i+1:i+1+j], "\n") {
+			// not the inline code, the backtick is the text.
+			nodes = parseText(nodes, s[:i+1])
+			s = s[i+1:]
+			continue
+		}
+		nodes = parseText(nodes, s[:i])
+		nodes = append(nodes, Node{Kind: Code, Text: unescape(s[i+1 : i+1+j])})
+		s = s[i+1+j+1:]
+	}
+	return parseText(nodes, s)
+}
+
+// reToken matches the entities, i.e. <@U123|label>, and the emoji shortcodes.
+var reToken = regexp.MustCompile(`<([@#!]?)([^<>|]+)(?:\|([^<>]+))?>|:([a-z0-9_+'-]+):`)
+
+// parseText parses the entities and the emoji of the text outside of code.
+func parseText(nodes []Node, s string) []Node {
+	last := 0
+	for _, loc := range reToken.FindAllStringSubmatchIndex(s, -1) {
+		if loc[8] >= 0 {
+			name := s[loc[8]:loc[9]]
+			// skip the time and the like, i.e. "12:30:45".
+			if loc[0] > 0 && isAlnum(s[loc[0]-1]) {
+				continue
+			}
+			nodes = appendText(nodes, s[last:loc[0]])
+			last = loc[1]
+			if n := len(nodes); strings.HasPrefix(name, "skin-tone-") && n > 0 && nodes[n-1].Kind == Emoji && !strings.Contains(nodes[n-1].ID, "::") {
+				nodes[n-1].ID += "::" + name
+				continue
+			}
+			nodes = append(nodes, Node{Kind: Emoji, ID: name})
+			continue
+		}
+		nodes = appendText(nodes, s[last:loc[0]])
+		last = loc[1]
+
+		var (
+			sigil = s[loc[2]:loc[3]]
+			ref   = unescape(s[loc[4]:loc[5]])
+			label string
+		)
+		if loc[6] >= 0 {
+			label = unescape(s[loc[6]:loc[7]])
+		}
+		nodes = append(nodes, entity(sigil, ref, label))
+	}
+	return appendText(nodes, s[last:])
+}
+
+// entity returns the node of the entity <sigil ref|label>.
+func entity(sigil, ref, label string) Node {
+	switch sigil {
+	case "@":
+		return Node{Kind: UserMention, ID: ref, Text: label}
+	case "#":
+		return Node{Kind: ChannelMention, ID: ref, Text: label}
+	case "!":
+		// the label of the user group mention has the "@".
+		label = strings.TrimPrefix(label, "@")
+		if id, ok := cutPrefix(ref, "subteam^"); ok {
+			return Node{Kind: GroupMention, ID: id, Text: label}
+		}
+		return Node{Kind: SpecialMention, ID: ref, Text: label}
+	}
+	return Node{Kind: Link, ID: ref, Text: label}
+}
+
+// appendText appends the escaped text s to nodes, merging it with the
+// previous text node.
+func appendText(nodes []Node, s string) []Node {
+	if s == "" {
+		return nodes
+	}
+	s = unescape(s)
+	if n := len(nodes); n > 0 && nodes[n-1].Kind == Text {
+		nodes[n-1].Text += s
+		return nodes
+	}
+	return append(nodes, Node{Kind: Text, Text: s})
+}
+
+// unescape reverses the escaping that Slack applies to the message text.
+func unescape(s string) string {
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(s)
+}
+
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
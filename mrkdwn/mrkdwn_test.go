@@ -0,0 +1,124 @@
+package mrkdwn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Node
+	}{
+		{"empty", "", nil},
+		{"text", "a &lt;b&gt; &amp; c", []Node{{Kind: Text, Text: "a <b> & c"}}},
+		{
+			"mentions",
+			"hi <@U01>, <@U02|bob> in <#C01|general> <!here> <!subteam^S01|@admins>",
+			[]Node{
+				{Kind: Text, Text: "hi "},
+				{Kind: UserMention, ID: "U01"},
+				{Kind: Text, Text: ", "},
+				{Kind: UserMention, ID: "U02", Text: "bob"},
+				{Kind: Text, Text: " in "},
+				{Kind: ChannelMention, ID: "C01", Text: "general"},
+				{Kind: Text, Text: " "},
+				{Kind: SpecialMention, ID: "here"},
+				{Kind: Text, Text: " "},
+				{Kind: GroupMention, ID: "S01", Text: "admins"},
+			},
+		},
+		{
+			"links",
+			"<https://example.com/?a=1&amp;b=2|site> <mailto:a@example.com>",
+			[]Node{
+				{Kind: Link, ID: "https://example.com/?a=1&b=2", Text: "site"},
+				{Kind: Text, Text: " "},
+				{Kind: Link, ID: "mailto:a@example.com"},
+			},
+		},
+		{
+			"code",
+			"run `go test <@U01>` and ```\nfmt.Println(\"&lt;hi&gt;\")\n``` done",
+			[]Node{
+				{Kind: Text, Text: "run "},
+				{Kind: Code, Text: "go test <@U01>"},
+				{Kind: Text, Text: " and "},
+				{Kind: CodeBlock, Text: `fmt.Println("<hi>")`},
+				{Kind: Text, Text: " done"},
+			},
+		},
+		{"unmatched backtick", "it`s ok", []Node{{Kind: Text, Text: "it`s ok"}}},
+		{"backticks across lines", "a `b\nc` d", []Node{{Kind: Text, Text: "a `b\nc` d"}}},
+		{
+			"emoji",
+			":wave: :+1::skin-tone-2: at 12:30:45",
+			[]Node{
+				{Kind: Emoji, ID: "wave"},
+				{Kind: Text, Text: " "},
+				{Kind: Emoji, ID: "+1::skin-tone-2"},
+				{Kind: Text, Text: " at 12:30:45"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Parse(tt.text))
+		})
+	}
+}
+
+var testResolver = Resolver{
+	User: func(id string) string {
+		if id == "U01" {
+			return "Alice"
+		}
+		return ""
+	},
+	Channel:    func(id string) string { return map[string]string{"C01": "general"}[id] },
+	UserGroup:  func(id string) string { return map[string]string{"S01": "everyone"}[id] },
+	ChannelURL: func(id string) string { return "/c/" + id },
+	Emoji: func(name string) string {
+		return map[string]string{"wave": "👋", "party": "https://emoji.example.com/party.png"}[name]
+	},
+}
+
+const testText = "hi <@U01> and <@U99> in <#C01>, <!subteam^S01>: " +
+	"see <https://example.com|the site> or <javascript:alert(1)|this> " +
+	":wave: :party: :unknown:\n`a &lt; b` ```code```"
+
+func TestHTML(t *testing.T) {
+	want := `hi <span class="mention">@Alice</span> and <span class="mention">@U99</span> in ` +
+		`<a class="mention" href="/c/C01">#general</a>, <span class="mention">@everyone</span>: ` +
+		`see <a href="https://example.com" rel="noreferrer" target="_blank">the site</a> or this ` +
+		`👋 <img class="emoji" src="https://emoji.example.com/party.png" alt=":party:"> :unknown:<br>` +
+		`<code>a &lt; b</code> <pre>code</pre>`
+	assert.Equal(t, want, HTML(testText, testResolver))
+
+	// no resolver.
+	assert.Equal(t, `<span class="mention">#C01</span> :wave:`, HTML("<#C01> :wave:", Resolver{}))
+}
+
+func TestMarkdown(t *testing.T) {
+	want := "hi @Alice and @U99 in [#general](/c/C01), @everyone: " +
+		"see [the site](https://example.com) or this " +
+		"👋 ![:party:](https://emoji.example.com/party.png) :unknown:\n" +
+		"`a < b` \n```\ncode\n```\n"
+	assert.Equal(t, want, Markdown(testText, testResolver))
+	assert.Equal(t, "<https://example.com> a &lt;b&gt;", Markdown("<https://example.com> a &lt;b&gt;", Resolver{}))
+}
+
+func TestPlainText(t *testing.T) {
+	want := "hi @Alice and @U99 in #general, @everyone: " +
+		"see the site (https://example.com) or this (javascript:alert(1)) " +
+		"👋 :party: :unknown:\na < b \ncode\n"
+	assert.Equal(t, want, PlainText(testText, testResolver))
+}
+
+func TestNormalize(t *testing.T) {
+	want := "hi <USER> and <USER> in <CHANNEL>, <GROUP>: see the site or this :wave: :party: :unknown: a < b <CODE>"
+	assert.Equal(t, want, Normalize(testText))
+	assert.Equal(t, "@here <URL> :+1:", Normalize("<!here>\n\t<https://example.com> :+1::skin-tone-2:"))
+}
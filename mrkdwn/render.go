@@ -0,0 +1,243 @@
+package mrkdwn
+
+import (
+	"html"
+	"strings"
+)
+
+// Resolver resolves the IDs of the mentions and the emoji names.  Any of the
+// functions can be nil, or return an empty string, if the ID is not known,
+// then the ID is output.
+type Resolver struct {
+	User      func(id string) string // display name of the user.
+	Channel   func(id string) string // channel name, without "#".
+	UserGroup func(id string) string // user group handle, without "@".
+	// ChannelURL returns the URL of the channel, if it's empty, the channel
+	// mention is not a link.
+	ChannelURL func(id string) string
+	// Emoji returns the Unicode character or the image URL of the emoji,
+	// if it's empty, the shortcode is output.
+	Emoji func(name string) string
+}
+
+func resolve(fn func(string) string, id string) string {
+	if fn != nil {
+		if s := fn(id); s != "" {
+			return s
+		}
+	}
+	return id
+}
+
+// name returns the display name of the mention n, without the sigil.
+func (r Resolver) name(n Node) string {
+	if n.Text != "" {
+		return n.Text
+	}
+	switch n.Kind {
+	case UserMention:
+		return resolve(r.User, n.ID)
+	case ChannelMention:
+		return resolve(r.Channel, n.ID)
+	case GroupMention:
+		return resolve(r.UserGroup, n.ID)
+	}
+	return n.ID
+}
+
+func (r Resolver) emoji(name string) string {
+	if r.Emoji == nil {
+		return ""
+	}
+	return r.Emoji(name)
+}
+
+func (r Resolver) channelURL(id string) string {
+	if r.ChannelURL == nil {
+		return ""
+	}
+	return r.ChannelURL(id)
+}
+
+// IsSafeURL returns true if the link can be rendered as a hyperlink, i.e. it
+// is not a "javascript:" URL.
+func IsSafeURL(s string) bool {
+	s = strings.ToLower(s)
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "mailto:")
+}
+
+// isImageURL returns true if the emoji is the image.
+func isImageURL(s string) bool {
+	s = strings.ToLower(s)
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// HTML renders the message text as HTML.  The mentions are the <span
+// class="mention"> elements, or the links, if the channel has an URL, the
+// unsafe links are output as the text, the line breaks are <br>.
+func HTML(text string, r Resolver) string {
+	var buf strings.Builder
+	for _, n := range Parse(text) {
+		switch n.Kind {
+		case Text:
+			buf.WriteString(strings.ReplaceAll(html.EscapeString(n.Text), "\n", "<br>"))
+		case UserMention, GroupMention, SpecialMention:
+			buf.WriteString(`<span class="mention">@` + html.EscapeString(r.name(n)) + `</span>`)
+		case ChannelMention:
+			name := html.EscapeString("#" + r.name(n))
+			if u := r.channelURL(n.ID); u != "" {
+				buf.WriteString(`<a class="mention" href="` + html.EscapeString(u) + `">` + name + `</a>`)
+			} else {
+				buf.WriteString(`<span class="mention">` + name + `</span>`)
+			}
+		case Link:
+			label := html.EscapeString(r.name(n))
+			if !IsSafeURL(n.ID) {
+				buf.WriteString(label)
+				continue
+			}
+			buf.WriteString(`<a href="` + html.EscapeString(n.ID) + `" rel="noreferrer" target="_blank">` + label + `</a>`)
+		case Code:
+			buf.WriteString("<code>" + html.EscapeString(n.Text) + "</code>")
+		case CodeBlock:
+			buf.WriteString("<pre>" + html.EscapeString(n.Text) + "</pre>")
+		case Emoji:
+			code := html.EscapeString(":" + n.ID + ":")
+			switch e := r.emoji(n.ID); {
+			case e == "":
+				buf.WriteString(code)
+			case isImageURL(e):
+				buf.WriteString(`<img class="emoji" src="` + html.EscapeString(e) + `" alt="` + code + `">`)
+			default:
+				buf.WriteString(html.EscapeString(e))
+			}
+		}
+	}
+	return buf.String()
+}
+
+// mdEscaper escapes the characters, that start the HTML in Markdown.
+var mdEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Markdown renders the message text as CommonMark Markdown.  The mentions are
+// the text, i.e. "@alice", or the links, if the channel has an URL.
+func Markdown(text string, r Resolver) string {
+	var buf strings.Builder
+	for _, n := range Parse(text) {
+		switch n.Kind {
+		case Text:
+			buf.WriteString(mdEscaper.Replace(n.Text))
+		case UserMention, GroupMention, SpecialMention:
+			buf.WriteString("@" + mdEscaper.Replace(r.name(n)))
+		case ChannelMention:
+			name := "#" + mdEscaper.Replace(r.name(n))
+			if u := r.channelURL(n.ID); u != "" {
+				buf.WriteString("[" + name + "](" + u + ")")
+			} else {
+				buf.WriteString(name)
+			}
+		case Link:
+			switch {
+			case !IsSafeURL(n.ID):
+				buf.WriteString(mdEscaper.Replace(r.name(n)))
+			case n.Text == "":
+				buf.WriteString("<" + n.ID + ">")
+			default:
+				buf.WriteString("[" + mdEscaper.Replace(n.Text) + "](" + n.ID + ")")
+			}
+		case Code:
+			buf.WriteString("`" + n.Text + "`")
+		case CodeBlock:
+			buf.WriteString("\n```\n" + n.Text + "\n```\n")
+		case Emoji:
+			code := ":" + n.ID + ":"
+			switch e := r.emoji(n.ID); {
+			case e == "":
+				buf.WriteString(code)
+			case isImageURL(e):
+				buf.WriteString("![" + code + "](" + e + ")")
+			default:
+				buf.WriteString(e)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// PlainText renders the message text as the plain text, i.e. for the text
+// files or the search.  The mentions are "@name", the links with the labels
+// are "label (URL)".
+func PlainText(text string, r Resolver) string {
+	var buf strings.Builder
+	for _, n := range Parse(text) {
+		switch n.Kind {
+		case Text, Code:
+			buf.WriteString(n.Text)
+		case CodeBlock:
+			buf.WriteString("\n" + n.Text + "\n")
+		case UserMention, GroupMention, SpecialMention:
+			buf.WriteString("@" + r.name(n))
+		case ChannelMention:
+			buf.WriteString("#" + r.name(n))
+		case Link:
+			if n.Text == "" || n.Text == n.ID {
+				buf.WriteString(n.ID)
+			} else {
+				buf.WriteString(n.Text + " (" + n.ID + ")")
+			}
+		case Emoji:
+			if e := r.emoji(n.ID); e != "" && !isImageURL(e) {
+				buf.WriteString(e)
+			} else {
+				buf.WriteString(":" + n.ID + ":")
+			}
+		}
+	}
+	return buf.String()
+}
+
+// Placeholders of the normalized text, see Normalize.
+const (
+	NormUser      = "<USER>"
+	NormChannel   = "<CHANNEL>"
+	NormUserGroup = "<GROUP>"
+	NormURL       = "<URL>"
+	NormCode      = "<CODE>"
+)
+
+// Normalize renders the message text as the single line for the NLP
+// pipelines, i.e. the tokenizers or the sentiment analysis.  The user,
+// channel and user group mentions, the URLs and the code blocks are replaced
+// with the placeholders, so that the names and the addresses don't leak into
+// the corpus.  The special mentions are "@here", the links with the labels
+// are the labels, the emoji are the shortcodes without the skin tone.  The
+// whitespace is collapsed into single spaces.
+func Normalize(text string) string {
+	var buf strings.Builder
+	for _, n := range Parse(text) {
+		switch n.Kind {
+		case Text, Code:
+			buf.WriteString(n.Text)
+		case CodeBlock:
+			buf.WriteString(" " + NormCode + " ")
+		case UserMention:
+			buf.WriteString(NormUser)
+		case ChannelMention:
+			buf.WriteString(NormChannel)
+		case GroupMention:
+			buf.WriteString(NormUserGroup)
+		case SpecialMention:
+			buf.WriteString("@" + n.ID)
+		case Link:
+			if n.Text == "" || n.Text == n.ID {
+				buf.WriteString(NormURL)
+			} else {
+				buf.WriteString(n.Text)
+			}
+		case Emoji:
+			name, _, _ := strings.Cut(n.ID, "::")
+			buf.WriteString(":" + name + ":")
+		}
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
@@ -0,0 +1,164 @@
+package slackdump
+
+// In this file: channel name to ID resolution.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime/trace"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/encio"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// namedChanTypes are the types of the conversations, that have names, the
+// direct messages don't.
+var namedChanTypes = []string{"public_channel", "private_channel", "mpim"}
+
+// AmbiguousNameError is returned by ResolveChannels, if the name matches
+// several channels, i.e. the archived channel and the new one with the same
+// name, or the channels of the different workspaces of the Enterprise Grid
+// organisation.
+type AmbiguousNameError struct {
+	Name     string
+	Channels []slack.Channel
+}
+
+func (e *AmbiguousNameError) Error() string {
+	var cands []string
+	for _, ch := range e.Channels {
+		var notes []string
+		if ch.IsPrivate {
+			notes = append(notes, "private")
+		}
+		if ch.IsArchived {
+			notes = append(notes, "archived")
+		}
+		c := ch.ID
+		if len(notes) > 0 {
+			c += " (" + strings.Join(notes, ", ") + ")"
+		}
+		cands = append(cands, c)
+	}
+	return fmt.Sprintf("channel name #%s is ambiguous, it matches %s, use the channel ID instead", e.Name, strings.Join(cands, ", "))
+}
+
+// ResolveChannels returns the mapping of the channel names, with or without
+// the "#", to the channel IDs.  The channels are looked up in the channel
+// cache, and, if any of the names is not found or ambiguous, the cache is
+// refreshed from the API, so that the renamed and the new channels are
+// found.  If the name matches several channels, the AmbiguousNameError is
+// returned, instead of picking one of them.
+func (sd *Session) ResolveChannels(ctx context.Context, names ...string) (map[string]string, error) {
+	ctx, task := trace.NewTask(ctx, "ResolveChannels")
+	defer task.End()
+
+	chans, err := sd.loadChannelCache(sd.options.ChanCacheFilename, sd.wspInfo.TeamID, sd.options.MaxChanCacheAge)
+	if err == nil {
+		ids, missing, err := resolveNames(chans, names)
+		if err == nil && len(missing) == 0 {
+			return ids, nil
+		}
+		sd.l().Debugf("channel cache is stale, refreshing")
+	} else if !os.IsNotExist(err) {
+		sd.l().Debugf("%s: it will be recreated", err)
+	}
+
+	if chans, err = sd.GetChannels(ctx, namedChanTypes...); err != nil {
+		return nil, err
+	}
+	if err := sd.saveChannelCache(sd.options.ChanCacheFilename, sd.wspInfo.TeamID, chans); err != nil {
+		trace.Logf(ctx, "error", "saving channel cache to %q, error: %s", sd.options.ChanCacheFilename, err)
+		sd.l().Printf("error saving channel cache to %q: %s, but nevermind, let's continue", sd.options.ChanCacheFilename, err)
+	}
+	ids, missing, err := resolveNames(chans, names)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("channel(s) not found: #%s", strings.Join(missing, ", #"))
+	}
+	return ids, nil
+}
+
+// resolveNames returns the IDs of the channels names, and the names, that
+// don't match any channel.
+func resolveNames(chans types.Channels, names []string) (ids map[string]string, missing []string, err error) {
+	byName := make(map[string][]slack.Channel, len(chans))
+	for _, ch := range chans {
+		if ch.Name == "" {
+			continue
+		}
+		key := strings.ToLower(ch.Name)
+		byName[key] = append(byName[key], ch)
+	}
+	ids = make(map[string]string, len(names))
+	for _, name := range names {
+		cands := byName[strings.ToLower(strings.TrimPrefix(name, "#"))]
+		switch len(cands) {
+		case 0:
+			missing = append(missing, strings.TrimPrefix(name, "#"))
+		case 1:
+			ids[name] = cands[0].ID
+		default:
+			sort.Slice(cands, func(i, j int) bool { return cands[i].ID < cands[j].ID })
+			return nil, nil, &AmbiguousNameError{Name: strings.TrimPrefix(name, "#"), Channels: cands}
+		}
+	}
+	return ids, missing, nil
+}
+
+// loadChannelCache loads the channels from the cache file.
+func (sd *Session) loadChannelCache(filename string, suffix string, maxAge time.Duration) (types.Channels, error) {
+	filename = sd.makeCacheFilename(filename, suffix)
+
+	if err := checkCacheFile(filename, maxAge); err != nil {
+		return nil, err
+	}
+
+	f, err := encio.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	var chans types.Channels
+	for {
+		var ch slack.Channel
+		if err := dec.Decode(&ch); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode channels from %s: %w", filename, err)
+		}
+		chans = append(chans, ch)
+	}
+	return chans, nil
+}
+
+func (sd *Session) saveChannelCache(filename string, suffix string, chans types.Channels) error {
+	filename = sd.makeCacheFilename(filename, suffix)
+
+	f, err := encio.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, ch := range chans {
+		if err := enc.Encode(ch); err != nil {
+			return fmt.Errorf("failed to encode data for %s: %w", filename, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func testChannel(id, name string, archived bool) slack.Channel {
+	var ch slack.Channel
+	ch.ID, ch.Name, ch.IsArchived = id, name, archived
+	return ch
+}
+
+func TestSession_ResolveChannels(t *testing.T) {
+	chans := types.Channels{
+		testChannel("C01", "general", false),
+		testChannel("C02", "random", false),
+		testChannel("C03", "project", true),
+		testChannel("C04", "project", false),
+	}
+	mc := newmockClienter(gomock.NewController(t))
+	sd := &Session{
+		client:  mc,
+		wspInfo: &slack.AuthTestResponse{TeamID: testSuffix},
+		options: Options{
+			CacheDir:          t.TempDir(),
+			ChanCacheFilename: "channels.cache",
+			MaxChanCacheAge:   DefOptions.MaxChanCacheAge,
+			ChannelsPerReq:    DefOptions.ChannelsPerReq,
+			Tier2Burst:        1,
+		},
+	}
+	expectList := func(chans types.Channels) {
+		mc.EXPECT().GetConversationsContext(gomock.Any(), &slack.GetConversationsParameters{
+			Limit: DefOptions.ChannelsPerReq,
+			Types: namedChanTypes,
+		}).Return(chans, "", nil)
+	}
+	ctx := context.Background()
+
+	expectList(chans)
+	ids, err := sd.ResolveChannels(ctx, "#general", "random")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"#general": "C01", "random": "C02"}, ids)
+
+	// from the cache, no API calls.
+	ids, err = sd.ResolveChannels(ctx, "#General")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"#General": "C01"}, ids)
+
+	// the cache miss refreshes the cache.
+	expectList(append(chans, testChannel("C05", "new", false)))
+	ids, err = sd.ResolveChannels(ctx, "#new")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"#new": "C05"}, ids)
+
+	// the ambiguous name is refreshed once and reported.
+	expectList(chans)
+	_, err = sd.ResolveChannels(ctx, "#project")
+	var ambErr *AmbiguousNameError
+	require.True(t, errors.As(err, &ambErr))
+	assert.Equal(t, "channel name #project is ambiguous, it matches C03 (archived), C04, use the channel ID instead", err.Error())
+
+	expectList(chans)
+	_, err = sd.ResolveChannels(ctx, "#missing")
+	assert.EqualError(t, err, "channel(s) not found: #missing")
+}
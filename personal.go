@@ -0,0 +1,257 @@
+package slackdump
+
+// In this file: the personal data of the current user.
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"runtime/trace"
+	"strconv"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// personalPerReq is the page size of the scheduled messages and drafts
+// requests.
+const personalPerReq = 100
+
+// GetScheduledMessages retrieves the scheduled messages of the current user
+// in all conversations.
+func (sd *Session) GetScheduledMessages(ctx context.Context) ([]slack.ScheduledMessage, error) {
+	ctx, task := trace.NewTask(ctx, "GetScheduledMessages")
+	defer task.End()
+
+	var (
+		all    []slack.ScheduledMessage
+		cursor string
+		l      = sd.limiter(network.Tier3)
+	)
+	for {
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
+		var (
+			msgs []slack.ScheduledMessage
+			next string
+		)
+		if err := network.WithRetry(ctx, l, sd.options.Tier3Retries, func() error {
+			var err error
+			msgs, next, err = sd.client.GetScheduledMessagesContext(ctx, &slack.GetScheduledMessagesParameters{
+				Cursor: cursor,
+				Limit:  personalPerReq,
+			})
+			return err
+		}); err != nil {
+			return nil, network.Classify("", err)
+		}
+		all = append(all, msgs...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	return all, nil
+}
+
+// draftsResponse is the response of the drafts.list.
+type draftsResponse struct {
+	Drafts []types.Draft `json:"drafts"`
+	slack.SlackResponse
+}
+
+// GetDrafts retrieves the active drafts of the current user.  The drafts are
+// only available to the web client, so it requires the client token
+// (xoxc-...) and the cookie, and is not supported by the sessions, created
+// with NewWithClient.
+func (sd *Session) GetDrafts(ctx context.Context) ([]types.Draft, error) {
+	ctx, task := trace.NewTask(ctx, "GetDrafts")
+	defer task.End()
+
+	var (
+		all    []types.Draft
+		cursor string
+		l      = sd.limiter(network.Tier3)
+	)
+	for {
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
+		var resp draftsResponse
+		if err := network.WithRetry(ctx, l, sd.options.Tier3Retries, func() error {
+			form := url.Values{
+				"is_active": {"true"},
+				"limit":     {strconv.Itoa(personalPerReq)},
+			}
+			if cursor != "" {
+				form.Set("cursor", cursor)
+			}
+			return sd.api.call(ctx, "drafts.list", form, &resp)
+		}); err != nil {
+			return nil, network.Classify("", err)
+		}
+		all = append(all, resp.Drafts...)
+		if resp.ResponseMetadata.Cursor == "" {
+			break
+		}
+		cursor = resp.ResponseMetadata.Cursor
+	}
+	return all, nil
+}
+
+// GetSaved retrieves the items, saved by the current user.  For the thread
+// replies, the thread parent message is fetched as well.
+func (sd *Session) GetSaved(ctx context.Context) ([]types.SavedItem, error) {
+	ctx, task := trace.NewTask(ctx, "GetSaved")
+	defer task.End()
+
+	items, err := sd.listStars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all := make([]types.SavedItem, 0, len(items))
+	for _, it := range items {
+		all = append(all, types.SavedItem{Item: it})
+	}
+	if err := sd.populateParents(ctx, all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// StarredChannels returns the IDs of the conversations, starred by the
+// current user: the channels, the private channels and the direct messages.
+func (sd *Session) StarredChannels(ctx context.Context) ([]string, error) {
+	ctx, task := trace.NewTask(ctx, "StarredChannels")
+	defer task.End()
+
+	items, err := sd.listStars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, it := range items {
+		switch it.Type {
+		case slack.TYPE_CHANNEL, slack.TYPE_GROUP, slack.TYPE_IM:
+			ids = append(ids, it.Channel)
+		}
+	}
+	return ids, nil
+}
+
+// listStars returns all items, starred (saved) by the current user.
+func (sd *Session) listStars(ctx context.Context) ([]slack.Item, error) {
+	var (
+		all []slack.Item
+		l   = sd.limiter(network.Tier3)
+	)
+	params := slack.NewStarsParameters()
+	params.Count = personalPerReq
+	for {
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
+		var (
+			items  []slack.Item
+			paging *slack.Paging
+		)
+		if err := network.WithRetry(ctx, l, sd.options.Tier3Retries, func() error {
+			var err error
+			items, paging, err = sd.client.ListStarsContext(ctx, params)
+			return err
+		}); err != nil {
+			return nil, network.Classify("", err)
+		}
+		all = append(all, items...)
+		if paging == nil || paging.Page >= paging.Pages {
+			break
+		}
+		params.Page = paging.Page + 1
+	}
+	return all, nil
+}
+
+// populateParents sets the thread parent message of the saved thread
+// replies.  Each thread is requested once.  If the thread is inaccessible,
+// i.e. the user has left the channel, the parent is not set.
+func (sd *Session) populateParents(ctx context.Context, items []types.SavedItem) error {
+	var (
+		parents = make(map[string]*slack.Message)
+		l       = sd.limiter(network.Tier3)
+	)
+	for i := range items {
+		m := items[i].Message
+		if m == nil || m.ThreadTimestamp == "" || m.ThreadTimestamp == m.Timestamp {
+			continue
+		}
+		key := items[i].Channel + ":" + m.ThreadTimestamp
+		parent, seen := parents[key]
+		if !seen {
+			if err := sd.proceed(ctx); err != nil {
+				return err
+			}
+			var msgs []slack.Message
+			if err := network.WithRetry(ctx, l, sd.options.Tier3Retries, func() error {
+				var err error
+				msgs, _, _, err = sd.client.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+					ChannelID: items[i].Channel,
+					Timestamp: m.ThreadTimestamp,
+					Limit:     1,
+				})
+				return err
+			}); err != nil {
+				if IsInterrupted(ctx, err) {
+					return err
+				}
+				sd.l().Debugf("saved: thread %s: %s", key, network.Classify(items[i].Channel, err))
+			}
+			if len(msgs) > 0 {
+				parent = &msgs[0]
+			}
+			parents[key] = parent
+		}
+		items[i].Parent = parent
+	}
+	return nil
+}
+
+// Personal returns the personal data of the current user: the scheduled
+// messages, the drafts and the saved items.  The parts that are not accessible with the token
+// of the session are skipped, and the reason is recorded in the Skipped
+// field.
+func (sd *Session) Personal(ctx context.Context) (*types.Personal, error) {
+	p := &types.Personal{UserID: sd.CurrentUserID()}
+	skip := func(part string, err error) error {
+		var na *ErrNoAccess
+		if !errors.Is(err, errNoAPIClient) && !errors.As(err, &na) {
+			return err
+		}
+		sd.l().Printf("skipping %s: %s", part, err)
+		if p.Skipped == nil {
+			p.Skipped = make(map[string]string)
+		}
+		p.Skipped[part] = err.Error()
+		return nil
+	}
+
+	var err error
+	if p.Scheduled, err = sd.GetScheduledMessages(ctx); err != nil {
+		if err := skip("scheduled", err); err != nil {
+			return nil, err
+		}
+	}
+	if p.Drafts, err = sd.GetDrafts(ctx); err != nil {
+		if err := skip("drafts", err); err != nil {
+			return nil, err
+		}
+	}
+	if p.Saved, err = sd.GetSaved(ctx); err != nil {
+		if err := skip("saved", err); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,143 @@
+package slackdump
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// draftsServer returns the server that serves the drafts.list pages.
+func draftsServer(t *testing.T, pages ...string) *httptest.Server {
+	t.Helper()
+	var n int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/drafts.list", r.URL.Path)
+		assert.Equal(t, "xoxc-test", r.FormValue("token"))
+		if n >= len(pages) {
+			t.Errorf("unexpected request #%d", n+1)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(pages[n]))
+		n++
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSession_GetDrafts(t *testing.T) {
+	t.Run("pagination", func(t *testing.T) {
+		srv := draftsServer(t,
+			`{"ok":true,"drafts":[{"id":"Dr01","destinations":[{"channel_id":"C01"}],"blocks":[]}],"response_metadata":{"next_cursor":"next"}}`,
+			`{"ok":true,"drafts":[{"id":"Dr02","destinations":[{"channel_id":"C02","thread_ts":"1672531200.000100"}],"blocks":[]}]}`,
+		)
+		sd := &Session{api: &apiClient{cl: srv.Client(), token: "xoxc-test", baseURL: srv.URL + "/"}, options: DefOptions}
+		got, err := sd.GetDrafts(context.Background())
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, "Dr01", got[0].ID)
+		assert.Equal(t, "1672531200.000100", got[1].Destinations[0].ThreadTS)
+	})
+	t.Run("token type", func(t *testing.T) {
+		srv := draftsServer(t, `{"ok":false,"error":"not_allowed_token_type"}`)
+		sd := &Session{api: &apiClient{cl: srv.Client(), token: "xoxc-test", baseURL: srv.URL}, options: DefOptions}
+		_, err := sd.GetDrafts(context.Background())
+		var na *ErrNoAccess
+		assert.ErrorAs(t, err, &na)
+	})
+	t.Run("no api client", func(t *testing.T) {
+		sd := &Session{options: DefOptions}
+		_, err := sd.GetDrafts(context.Background())
+		assert.ErrorIs(t, err, errNoAPIClient)
+	})
+}
+
+func TestSession_GetSaved(t *testing.T) {
+	reply := func(ts, threadTS string) *slack.Message {
+		return &slack.Message{Msg: slack.Msg{Timestamp: ts, ThreadTimestamp: threadTS}}
+	}
+	parent := slack.Message{Msg: slack.Msg{Timestamp: "100.000000", ThreadTimestamp: "100.000000", Text: "parent"}}
+
+	ctrl := gomock.NewController(t)
+	mc := newmockClienter(ctrl)
+	gomock.InOrder(
+		mc.EXPECT().ListStarsContext(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params slack.StarsParameters) ([]slack.Item, *slack.Paging, error) {
+				assert.Equal(t, personalPerReq, params.Count)
+				return []slack.Item{
+					slack.NewMessageItem("C01", reply("101.000000", "100.000000")),
+					slack.NewMessageItem("C01", reply("102.000000", "100.000000")),
+				}, &slack.Paging{Page: 1, Pages: 2}, nil
+			}),
+		mc.EXPECT().ListStarsContext(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params slack.StarsParameters) ([]slack.Item, *slack.Paging, error) {
+				assert.Equal(t, 2, params.Page)
+				return []slack.Item{
+					slack.NewMessageItem("C02", reply("201.000000", "200.000000")),
+					slack.NewMessageItem("C03", &parent),
+				}, &slack.Paging{Page: 2, Pages: 2}, nil
+			}),
+	)
+	mc.EXPECT().GetConversationRepliesContext(gomock.Any(), &slack.GetConversationRepliesParameters{ChannelID: "C01", Timestamp: "100.000000", Limit: 1}).
+		Return([]slack.Message{parent}, true, "next", nil).Times(1)
+	mc.EXPECT().GetConversationRepliesContext(gomock.Any(), &slack.GetConversationRepliesParameters{ChannelID: "C02", Timestamp: "200.000000", Limit: 1}).
+		Return(nil, false, "", slack.SlackErrorResponse{Err: "channel_not_found"}).Times(1)
+
+	sd := &Session{client: mc, options: DefOptions}
+	got, err := sd.GetSaved(context.Background())
+	require.NoError(t, err)
+	require.Len(t, got, 4)
+	assert.Equal(t, &parent, got[0].Parent)
+	assert.Equal(t, &parent, got[1].Parent)
+	assert.Nil(t, got[2].Parent, "inaccessible thread")
+	assert.Nil(t, got[3].Parent, "not a reply")
+}
+
+func TestSession_StarredChannels(t *testing.T) {
+	mc := newmockClienter(gomock.NewController(t))
+	mc.EXPECT().ListStarsContext(gomock.Any(), gomock.Any()).Return([]slack.Item{
+		slack.NewChannelItem("C01"),
+		slack.NewMessageItem("C02", &slack.Message{}),
+		{Type: slack.TYPE_IM, Channel: "D01"},
+		{Type: slack.TYPE_GROUP, Channel: "G01"},
+		slack.NewFileItem(&slack.File{ID: "F01"}),
+	}, &slack.Paging{Page: 1, Pages: 1}, nil)
+
+	sd := &Session{client: mc, options: DefOptions}
+	got, err := sd.StarredChannels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C01", "D01", "G01"}, got)
+}
+
+func TestSession_Personal(t *testing.T) {
+	testScheduled := []slack.ScheduledMessage{{ID: "Q01", Channel: "C01", Text: "later"}}
+	t.Run("drafts not supported", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mc.EXPECT().GetScheduledMessagesContext(gomock.Any(), gomock.Any()).Return(testScheduled, "", nil)
+		mc.EXPECT().ListStarsContext(gomock.Any(), gomock.Any()).Return(nil, &slack.Paging{Page: 1, Pages: 1}, nil)
+
+		sd := &Session{client: mc, options: DefOptions, wspInfo: &slack.AuthTestResponse{UserID: "U01"}}
+		p, err := sd.Personal(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "U01", p.UserID)
+		assert.Equal(t, testScheduled, p.Scheduled)
+		assert.Empty(t, p.Drafts)
+		assert.Contains(t, p.Skipped, "drafts")
+	})
+	t.Run("token expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mc.EXPECT().GetScheduledMessagesContext(gomock.Any(), gomock.Any()).Return(nil, "", slack.SlackErrorResponse{Err: "invalid_auth"})
+
+		sd := &Session{client: mc, options: DefOptions, wspInfo: &slack.AuthTestResponse{UserID: "U01"}}
+		_, err := sd.Personal(context.Background())
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+}
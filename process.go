@@ -0,0 +1,118 @@
+package slackdump
+
+// In this file: the processor pipeline.
+
+import (
+	"context"
+	"errors"
+	"runtime/trace"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/processor"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Process fetches the conversation or the thread, identified by link (see
+// Dump), between oldest and latest, and passes the records to the processor
+// p as they are fetched, see processor.Processor for the order of the calls.
+// As with DumpRaw, the options, that post-process the messages, i.e.
+// DumpFiles, are not applied, the processors should be added to p instead.
+func (sd *Session) Process(ctx context.Context, p processor.Processor, link string, oldest, latest time.Time) (err error) {
+	ctx, task := trace.NewTask(ctx, "Process")
+	defer task.End()
+
+	sl, err := structures.ParseLink(link)
+	if err != nil {
+		return err
+	}
+	if !sl.IsValid() {
+		return errors.New("invalid link")
+	}
+
+	sd.pr().ChannelStarted(ctx, sl.String())
+	defer func() { sd.pr().ChannelFinished(ctx, sl.String(), err) }()
+
+	ci, err := sd.getChannelInfo(ctx, sd.limiter(network.Tier3), sl.Channel)
+	if err != nil {
+		return err
+	}
+	if err := p.ChannelInfo(ctx, ci); err != nil {
+		return err
+	}
+
+	if sl.IsThread() {
+		thread, err := sd.dumpThread(ctx, sd.pooledLimiter(network.Tier3), sl.Channel, sl.ThreadTS, oldest, latest)
+		if err != nil {
+			return err
+		}
+		if len(thread) == 0 {
+			return nil
+		}
+		thread[0].ThreadReplies = thread[1:]
+		return processMessages(ctx, p, sl.Channel, thread[:1])
+	}
+
+	// the page is processed after fetchChannel populates the thread replies.
+	_, err = sd.fetchChannel(ctx, sl.Channel, oldest, latest, func(msgs []types.Message, channelID string) (ProcessResult, error) {
+		if err := processMessages(ctx, p, channelID, msgs); err != nil {
+			return ProcessResult{}, err
+		}
+		return ProcessResult{Entity: "processed", Count: len(msgs)}, nil
+	})
+	return err
+}
+
+// ProcessUsers fetches the users of the workspace and passes them to the
+// processor p.
+func (sd *Session) ProcessUsers(ctx context.Context, p processor.Processor) error {
+	users, err := sd.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+	return p.Users(ctx, users)
+}
+
+// processMessages passes the page of the messages msgs of the channel
+// channelID, their files and thread replies to p.
+func processMessages(ctx context.Context, p processor.Processor, channelID string, msgs []types.Message) error {
+	if err := p.Messages(ctx, channelID, rawMessages(msgs)); err != nil {
+		return err
+	}
+	for i := range msgs {
+		m := &msgs[i]
+		if len(m.Files) > 0 {
+			if err := p.Files(ctx, channelID, m.Message, m.Files); err != nil {
+				return err
+			}
+		}
+		if len(m.ThreadReplies) == 0 {
+			continue
+		}
+		if err := p.ThreadMessages(ctx, channelID, m.Message, rawMessages(m.ThreadReplies)); err != nil {
+			return err
+		}
+		for j := range m.ThreadReplies {
+			r := &m.ThreadReplies[j]
+			if len(r.Files) == 0 {
+				continue
+			}
+			if err := p.Files(ctx, channelID, r.Message, r.Files); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rawMessages returns the API messages of msgs.
+func rawMessages(msgs []types.Message) []slack.Message {
+	raw := make([]slack.Message, len(msgs))
+	for i := range msgs {
+		raw[i] = msgs[i].Message
+	}
+	return raw
+}
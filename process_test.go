@@ -0,0 +1,120 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/processor"
+)
+
+// callRecorder records the calls of the processor.
+type callRecorder struct {
+	processor.Nop
+	calls []string
+	err   error
+}
+
+func (r *callRecorder) ChannelInfo(_ context.Context, ch *slack.Channel) error {
+	r.calls = append(r.calls, "info "+ch.Name)
+	return nil
+}
+
+func (r *callRecorder) Messages(_ context.Context, channelID string, msgs []slack.Message) error {
+	r.calls = append(r.calls, fmt.Sprintf("messages %s %d", channelID, len(msgs)))
+	return r.err
+}
+
+func (r *callRecorder) ThreadMessages(_ context.Context, channelID string, parent slack.Message, replies []slack.Message) error {
+	r.calls = append(r.calls, fmt.Sprintf("thread %s %s %d", channelID, parent.Timestamp, len(replies)))
+	return nil
+}
+
+func (r *callRecorder) Files(_ context.Context, channelID string, msg slack.Message, files []slack.File) error {
+	r.calls = append(r.calls, fmt.Sprintf("files %s %s %d", channelID, msg.Timestamp, len(files)))
+	return nil
+}
+
+func (r *callRecorder) Users(_ context.Context, users []slack.User) error {
+	r.calls = append(r.calls, fmt.Sprintf("users %d", len(users)))
+	return nil
+}
+
+func TestSession_Process(t *testing.T) {
+	withFile := testMsg1.Message
+	withFile.Files = []slack.File{{ID: "F01"}}
+	reply := testMsg4t.ThreadReplies[0].Message
+	reply.Files = []slack.File{{ID: "F02"}, {ID: "F03"}}
+
+	t.Run("channel", func(t *testing.T) {
+		mc := newmockClienter(gomock.NewController(t))
+		mockConvInfo(mc, "CHM82GF99", "unittest")
+		mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+			&slack.GetConversationHistoryResponse{
+				Messages:      []slack.Message{withFile, testMsg4t.Message},
+				SlackResponse: slack.SlackResponse{Ok: true},
+			},
+			nil,
+		)
+		mc.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return(
+			[]slack.Message{testMsg4t.Message, reply}, false, "", nil,
+		)
+		sd := &Session{client: mc, options: DefOptions}
+
+		var rec callRecorder
+		err := sd.Process(context.Background(), &rec, "CHM82GF99", time.Time{}, time.Time{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"info unittest",
+			"messages CHM82GF99 2",
+			"files CHM82GF99 1638497751.040300 1",
+			"thread CHM82GF99 1638524854.042000 1",
+			"files CHM82GF99 1638554726.042700 2",
+		}, rec.calls)
+	})
+	t.Run("thread", func(t *testing.T) {
+		mc := newmockClienter(gomock.NewController(t))
+		mockConvInfo(mc, "CHM82GF99", "unittest")
+		mc.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return(
+			[]slack.Message{testMsg4t.Message, reply}, false, "", nil,
+		)
+		sd := &Session{client: mc, options: DefOptions}
+
+		var rec callRecorder
+		err := sd.Process(context.Background(), &rec, "CHM82GF99:1638524854.042000", time.Time{}, time.Time{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"info unittest",
+			"messages CHM82GF99 1",
+			"thread CHM82GF99 1638524854.042000 1",
+			"files CHM82GF99 1638554726.042700 2",
+		}, rec.calls)
+	})
+	t.Run("processor error stops", func(t *testing.T) {
+		mc := newmockClienter(gomock.NewController(t))
+		mockConvInfo(mc, "CHM82GF99", "unittest")
+		mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+			&slack.GetConversationHistoryResponse{
+				Messages:      []slack.Message{testMsg1.Message},
+				HasMore:       true,
+				SlackResponse: slack.SlackResponse{Ok: true},
+			},
+			nil,
+		)
+		sd := &Session{client: mc, options: DefOptions}
+
+		rec := callRecorder{err: errors.New("disk full")}
+		err := sd.Process(context.Background(), &rec, "CHM82GF99", time.Time{}, time.Time{})
+		assert.EqualError(t, err, "disk full")
+	})
+	t.Run("invalid link", func(t *testing.T) {
+		sd := &Session{options: DefOptions}
+		assert.Error(t, sd.Process(context.Background(), processor.Nop{}, "", time.Time{}, time.Time{}))
+	})
+}
@@ -0,0 +1,48 @@
+package processor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/downloader"
+)
+
+// Downloader is the Processor, that downloads the files of the messages into
+// the directories, named after the IDs of the conversations, the same as the
+// dump does.
+type Downloader struct {
+	Nop
+	dl *downloader.Client
+}
+
+// NewDownloader starts the downloader dl, and returns the Downloader, that
+// places the files into its queue.  Close waits for the downloads to finish.
+func NewDownloader(ctx context.Context, dl *downloader.Client) *Downloader {
+	dl.Start(ctx)
+	return &Downloader{dl: dl}
+}
+
+// Files places the files in the download queue, the embedded files are
+// skipped.
+func (d *Downloader) Files(ctx context.Context, channelID string, _ slack.Message, files []slack.File) error {
+	for _, f := range files {
+		if strings.HasPrefix(f.URLPrivateDownload, "data:") {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := d.dl.DownloadFile(channelID, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close waits for all the downloads to finish, and stops the downloader.
+func (d *Downloader) Close() error {
+	d.dl.Stop()
+	return nil
+}
@@ -0,0 +1,115 @@
+// Package processor defines the Processor, that receives the records of the
+// conversations as they are fetched from the API, and the Chain, that
+// composes several processors into one pipeline, i.e. the Recorder, the
+// Downloader, and the custom processors:
+//
+//	rec := processor.NewRecorder(f)
+//	dl := processor.NewDownloader(ctx, downloader.New(sess.Client(), fs))
+//	p := processor.Chain(rec, dl, myProcessor)
+//	defer p.Close()
+//	err := sess.Process(ctx, p, "C01234567", time.Time{}, time.Time{})
+package processor
+
+import (
+	"context"
+	"io"
+
+	"github.com/slack-go/slack"
+)
+
+// Processor receives the records of the conversations.  The conversation
+// records are delivered in the following order: ChannelInfo, then for each
+// page of the messages, Messages, and then for each message of the page, Files
+// of the message, if it has any, ThreadMessages of its thread, if it has one,
+// and Files of each reply.  If the method returns an error, the processing
+// stops.
+//
+// The processor must not retain the slices after the method returns.
+type Processor interface {
+	// ChannelInfo is called with the information of the conversation, before
+	// its messages.
+	ChannelInfo(ctx context.Context, ch *slack.Channel) error
+	// Messages is called with the page of the messages of the conversation,
+	// as returned by the API.
+	Messages(ctx context.Context, channelID string, msgs []slack.Message) error
+	// ThreadMessages is called with the replies of the thread, started by the
+	// message parent.
+	ThreadMessages(ctx context.Context, channelID string, parent slack.Message, replies []slack.Message) error
+	// Files is called with the files of the message msg.
+	Files(ctx context.Context, channelID string, msg slack.Message, files []slack.File) error
+	// Users is called with the users of the workspace.
+	Users(ctx context.Context, users []slack.User) error
+}
+
+// Nop is the Processor, that does nothing.  It can be embedded into the
+// custom processors, so that they only implement the methods they need.
+type Nop struct{}
+
+var _ Processor = Nop{}
+
+func (Nop) ChannelInfo(context.Context, *slack.Channel) error { return nil }
+func (Nop) Messages(context.Context, string, []slack.Message) error {
+	return nil
+}
+func (Nop) ThreadMessages(context.Context, string, slack.Message, []slack.Message) error {
+	return nil
+}
+func (Nop) Files(context.Context, string, slack.Message, []slack.File) error {
+	return nil
+}
+func (Nop) Users(context.Context, []slack.User) error { return nil }
+
+// Chained is the chain of the processors, returned by Chain.
+type Chained []Processor
+
+// Chain returns the processor, that calls the processors pp in order, and
+// stops at the first error.  Close of the chain closes the processors, that
+// implement io.Closer.
+func Chain(pp ...Processor) Chained {
+	return Chained(pp)
+}
+
+func (c Chained) ChannelInfo(ctx context.Context, ch *slack.Channel) error {
+	return c.each(func(p Processor) error { return p.ChannelInfo(ctx, ch) })
+}
+
+func (c Chained) Messages(ctx context.Context, channelID string, msgs []slack.Message) error {
+	return c.each(func(p Processor) error { return p.Messages(ctx, channelID, msgs) })
+}
+
+func (c Chained) ThreadMessages(ctx context.Context, channelID string, parent slack.Message, replies []slack.Message) error {
+	return c.each(func(p Processor) error { return p.ThreadMessages(ctx, channelID, parent, replies) })
+}
+
+func (c Chained) Files(ctx context.Context, channelID string, msg slack.Message, files []slack.File) error {
+	return c.each(func(p Processor) error { return p.Files(ctx, channelID, msg, files) })
+}
+
+func (c Chained) Users(ctx context.Context, users []slack.User) error {
+	return c.each(func(p Processor) error { return p.Users(ctx, users) })
+}
+
+func (c Chained) each(fn func(p Processor) error) error {
+	for _, p := range c {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes all the processors of the chain, that implement io.Closer, in
+// order, and returns the first error.
+func (c Chained) Close() error {
+	var first error
+	for _, p := range c {
+		cl, ok := p.(io.Closer)
+		if !ok {
+			continue
+		}
+		if err := cl.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,125 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/fsadapter"
+)
+
+type named struct {
+	Nop
+	name   string
+	log    *[]string
+	err    error
+	closed error
+}
+
+func (n *named) Messages(context.Context, string, []slack.Message) error {
+	*n.log = append(*n.log, n.name)
+	return n.err
+}
+
+func (n *named) Close() error {
+	*n.log = append(*n.log, "close "+n.name)
+	return n.closed
+}
+
+func TestChain(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		var log []string
+		c := Chain(&named{name: "a", log: &log}, Nop{}, &named{name: "b", log: &log})
+		assert.NoError(t, c.Messages(context.Background(), "C01", nil))
+		assert.NoError(t, c.Close())
+		assert.Equal(t, []string{"a", "b", "close a", "close b"}, log)
+	})
+	t.Run("stops at the first error", func(t *testing.T) {
+		var log []string
+		c := Chain(&named{name: "a", log: &log, err: errors.New("fail")}, &named{name: "b", log: &log})
+		assert.EqualError(t, c.Messages(context.Background(), "C01", nil), "fail")
+		assert.Equal(t, []string{"a"}, log)
+	})
+	t.Run("closes all", func(t *testing.T) {
+		var log []string
+		c := Chain(&named{name: "a", log: &log, closed: errors.New("a")}, &named{name: "b", log: &log, closed: errors.New("b")})
+		assert.EqualError(t, c.Close(), "a")
+		assert.Equal(t, []string{"close a", "close b"}, log)
+	})
+}
+
+type bufCloser struct{ bytes.Buffer }
+
+func (*bufCloser) Close() error { return nil }
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	var buf bufCloser
+	r := NewRecorder(&buf)
+
+	parent := slack.Message{Msg: slack.Msg{Timestamp: "1.000", ThreadTimestamp: "1.000", Files: []slack.File{{ID: "F01"}}}}
+	reply := slack.Message{Msg: slack.Msg{Timestamp: "2.000", ThreadTimestamp: "1.000"}}
+	require.NoError(t, r.Users(ctx, []slack.User{{ID: "U01"}}))
+	require.NoError(t, r.ChannelInfo(ctx, &slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01"}}}))
+	require.NoError(t, r.Messages(ctx, "C01", []slack.Message{parent}))
+	require.NoError(t, r.Files(ctx, "C01", parent, parent.Files))
+	require.NoError(t, r.ThreadMessages(ctx, "C01", parent, []slack.Message{reply}))
+	require.NoError(t, r.Close())
+
+	var types []string
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var ev struct{ Type string }
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &ev))
+		types = append(types, ev.Type)
+	}
+	assert.Equal(t, []string{"user", "channel", "message", "file", "message"}, types, "files are recorded once")
+}
+
+func TestRecorder_options(t *testing.T) {
+	ctx := context.Background()
+	var buf bufCloser
+	r := NewRecorder(&buf, Buffer(4096), FlushInterval(time.Minute))
+	require.NoError(t, r.Users(ctx, []slack.User{{ID: "U01"}}))
+	assert.Zero(t, buf.Len(), "the record is buffered")
+	require.NoError(t, r.Close())
+	assert.NotZero(t, buf.Len(), "the buffer is written on close")
+}
+
+type fakeGetter struct{}
+
+func (fakeGetter) GetFile(downloadURL string, w io.Writer) error {
+	_, err := io.Copy(w, strings.NewReader(downloadURL))
+	return err
+}
+
+func TestDownloader(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	d := NewDownloader(ctx, downloader.New(fakeGetter{}, fsadapter.NewDirectory(dir)))
+
+	msg := slack.Message{Msg: slack.Msg{Files: []slack.File{
+		{ID: "F01", Name: "a.txt", URLPrivateDownload: "contents"},
+		{ID: "F02", Name: "b.txt", URLPrivateDownload: "data:text/plain;base64,"},
+	}}}
+	require.NoError(t, d.Files(ctx, "C01", msg, msg.Files))
+	require.NoError(t, d.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "C01", "F01-a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "C01", "F02-b.txt"), "embedded files are skipped")
+}
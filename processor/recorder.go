@@ -0,0 +1,99 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/stream"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Recorder is the Processor, that writes the records as the NDJSON event
+// stream, the same as the jsonl:// output target does.
+type Recorder struct {
+	w *stream.Writer
+}
+
+var _ Processor = (*Recorder)(nil)
+
+// RecorderOption is the option of the Recorder.
+type RecorderOption func(*stream.Policy)
+
+// Buffer enables the write buffer of size bytes, the records are written,
+// once the buffer is full, see FlushInterval.  It improves the throughput,
+// but the buffered records are lost, if the process crashes.
+func Buffer(size int) RecorderOption {
+	return func(p *stream.Policy) {
+		if size >= 0 {
+			p.BufferSize = size
+		}
+	}
+}
+
+// FlushInterval sets the maximum time, that the buffered records wait before
+// they are written, 0 waits until the buffer is full.
+func FlushInterval(d time.Duration) RecorderOption {
+	return func(p *stream.Policy) {
+		if d >= 0 {
+			p.FlushInterval = d
+		}
+	}
+}
+
+// Fsync enables syncing the file to the disk after each chunk of the
+// records, i.e. each page of the messages, so that the machine crash loses
+// at most the chunk being written, at the cost of the throughput.
+func Fsync(b bool) RecorderOption {
+	return func(p *stream.Policy) {
+		p.Sync = b
+	}
+}
+
+// NewRecorder returns the Recorder, that writes to wc.  wc is closed by
+// Close.  By default, each record is written as it is received, and the
+// syncing is left to the operating system.
+func NewRecorder(wc io.WriteCloser, opts ...RecorderOption) *Recorder {
+	var p stream.Policy
+	for _, opt := range opts {
+		opt(&p)
+	}
+	w := stream.NewWriter(wc)
+	w.SetPolicy(p)
+	return &Recorder{w: w}
+}
+
+func (r *Recorder) ChannelInfo(_ context.Context, ch *slack.Channel) error {
+	r.w.Channel(*ch)
+	return r.w.Err()
+}
+
+// Messages records the messages and their files.
+func (r *Recorder) Messages(_ context.Context, channelID string, msgs []slack.Message) error {
+	r.w.Messages(channelID, types.ConvertMsgs(msgs))
+	return r.w.Err()
+}
+
+// ThreadMessages records the replies and their files.
+func (r *Recorder) ThreadMessages(_ context.Context, channelID string, _ slack.Message, replies []slack.Message) error {
+	r.w.Messages(channelID, types.ConvertMsgs(replies))
+	return r.w.Err()
+}
+
+// Files does nothing, the files are recorded along with their messages.
+func (r *Recorder) Files(context.Context, string, slack.Message, []slack.File) error {
+	return nil
+}
+
+func (r *Recorder) Users(_ context.Context, users []slack.User) error {
+	r.w.Users(users)
+	return r.w.Err()
+}
+
+// Close closes the underlying writer, and returns the first write error, if
+// any.
+func (r *Recorder) Close() error {
+	return r.w.Close()
+}
@@ -1,6 +1,7 @@
 package slackdump
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"path"
@@ -12,7 +13,9 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/internal/network"
 	"github.com/rusq/slackdump/v2/internal/structures/files"
+	"github.com/rusq/slackdump/v2/sanitize"
 	"github.com/rusq/slackdump/v2/types"
 )
 
@@ -81,6 +84,10 @@ func (sd *Session) newFileProcessFn(ctx context.Context, dir string, l *rate.Lim
 		downloader.Retries(sd.options.DownloadRetries),
 		downloader.Workers(sd.options.Workers),
 		downloader.Logger(sd.l()),
+		downloader.Progress(sd.pr()),
+		downloader.Sanitize(sd.options.FilenameProfile),
+		downloader.Schedule(sd.options.DownloadWindow),
+		downloader.Skip(sd.options.SkipList),
 	)
 	var filesC = make(chan *slack.File, filesCbufSz)
 
@@ -90,7 +97,8 @@ func (sd *Session) newFileProcessFn(ctx context.Context, dir string, l *rate.Lim
 	}
 
 	fn := func(msg []types.Message, _ string) (ProcessResult, error) {
-		n := pipeAndUpdateFiles(filesC, msg, dir)
+		n := sd.embedFiles(ctx, l, msg, dir)
+		n += pipeAndUpdateFiles(filesC, msg, dir, sd.options.FilenameProfile)
 		return ProcessResult{Entity: "files", Count: n}, nil
 	}
 
@@ -103,14 +111,51 @@ func (sd *Session) newFileProcessFn(ctx context.Context, dir string, l *rate.Lim
 }
 
 // pipeAndUpdateFiles scans the messages and sends all the files discovered to
-// the filesC.
-func pipeAndUpdateFiles(filesC chan<- *slack.File, msgs []types.Message, dir string) int {
+// the filesC.  The file references are updated to the names, sanitized with
+// the profile p, the same way as the downloader does.
+func pipeAndUpdateFiles(filesC chan<- *slack.File, msgs []types.Message, dir string, p sanitize.Profile) int {
 	// place files in the download queue
 	total := 0
 	_ = files.Extract(msgs, files.Root, func(file slack.File, addr files.Addr) error {
+		if strings.HasPrefix(file.URLPrivateDownload, "data:") {
+			// embedded, see embedFiles.
+			return nil
+		}
 		filesC <- &file
 		total++
-		return files.Update(msgs, addr, files.UpdatePathFn(path.Join(dir, downloader.Filename(&file))))
+		return files.Update(msgs, addr, files.UpdatePathFn(path.Join(dir, p.Name(downloader.Filename(&file)))))
+	})
+	return total
+}
+
+// embedFiles embeds the files of msgs, that are not larger than the
+// EmbedFiles option, as the data URLs, see WithEmbedFiles.  The files, that
+// fail to download, are left for the downloader, that will retry and report
+// them.  It returns the number of embedded files.
+func (sd *Session) embedFiles(ctx context.Context, l *rate.Limiter, msgs []types.Message, dir string) int {
+	if sd.options.EmbedFiles <= 0 {
+		return 0
+	}
+	total := 0
+	_ = files.Extract(msgs, files.Root, func(file slack.File, addr files.Addr) error {
+		if file.Size <= 0 || int64(file.Size) > sd.options.EmbedFiles || file.URLPrivateDownload == "" {
+			return nil
+		}
+		var buf bytes.Buffer
+		err := network.WithRetry(ctx, l, sd.options.DownloadRetries, func() error {
+			buf.Reset()
+			return sd.client.GetFile(file.URLPrivateDownload, &buf)
+		})
+		if err == nil && int64(buf.Len()) > sd.options.EmbedFiles {
+			err = fmt.Errorf("file is larger than reported: %d bytes", buf.Len())
+		}
+		if err != nil {
+			sd.l().Debugf("file %s is not embedded: %s", file.ID, err)
+			return nil
+		}
+		sd.pr().FileDone(ctx, dir, &file, int64(buf.Len()), nil)
+		total++
+		return files.Update(msgs, addr, files.UpdatePathFn(files.DataURL(file.Mimetype, buf.Bytes())))
 	})
 	return total
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/sanitize"
 	"github.com/rusq/slackdump/v2/types"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
@@ -92,7 +93,7 @@ func pipeTestSuite(t *testing.T, msgs []types.Message, dir string) []slack.File
 	}(filesC)
 	wg.Add(1)
 
-	pipeAndUpdateFiles(filesC, msgs, dir)
+	pipeAndUpdateFiles(filesC, msgs, dir, sanitize.Preserve)
 	close(filesC)
 	wg.Wait()
 	return got
@@ -0,0 +1,107 @@
+package slackdump
+
+// In this file: the user profiles with the custom fields.
+
+import (
+	"context"
+	"errors"
+	"runtime/trace"
+	"sort"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// GetUserProfiles returns the users of the workspace with their complete
+// profiles, including the custom profile fields, i.e. the department or the
+// manager, that are not returned by the users list.  The profile of each
+// user is requested separately, so it takes around a minute per hundred
+// users.  Bots and deleted users are returned with the profiles from the
+// users list.  If the workspace has no custom fields, or the token does not
+// have access to their definitions, the Fields of the result is empty.
+func (sd *Session) GetUserProfiles(ctx context.Context) (*types.UserProfiles, error) {
+	ctx, task := trace.NewTask(ctx, "GetUserProfiles")
+	defer task.End()
+
+	users, err := sd.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := sd.teamProfileFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]string, len(fields))
+	for _, f := range fields {
+		labels[f.ID] = f.Label
+	}
+
+	res := &types.UserProfiles{Users: make(types.Users, len(users)), Fields: fields}
+	copy(res.Users, users)
+	l := sd.limiter(network.Tier4)
+	for i := range res.Users {
+		u := &res.Users[i]
+		if u.IsBot || u.Deleted || u.ID == "USLACKBOT" {
+			continue
+		}
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
+		var profile *slack.UserProfile
+		if err := network.WithRetry(ctx, l, sd.options.Tier4Retries, func() error {
+			var err error
+			profile, err = sd.client.GetUserProfileContext(ctx, &slack.GetUserProfileParameters{UserID: u.ID})
+			return err
+		}); err != nil {
+			err = network.Classify("", err)
+			var sce slack.SlackErrorResponse
+			if errors.As(err, &sce) && sce.Err == "user_not_found" {
+				sd.l().Debugf("profiles: skipping %s: %s", u.ID, err)
+				continue
+			}
+			return nil, err
+		}
+		// the user profile fields have no labels, unless requested with the
+		// lower rate limit, so they are set from the team profile.
+		if m := profile.FieldsMap(); len(m) > 0 {
+			for id, f := range m {
+				if f.Label == "" {
+					f.Label = labels[id]
+					m[id] = f
+				}
+			}
+		}
+		u.Profile = *profile
+	}
+	return res, nil
+}
+
+// teamProfileFields returns the definitions of the custom profile fields of
+// the workspace, sorted in the display order, hidden fields are omitted.  If
+// the token does not have access to the team profile, it returns nil.
+func (sd *Session) teamProfileFields(ctx context.Context) ([]slack.TeamProfileField, error) {
+	var tp *slack.TeamProfile
+	if err := network.WithRetry(ctx, sd.limiter(network.Tier3), sd.options.Tier3Retries, func() error {
+		var err error
+		tp, err = sd.client.GetTeamProfileContext(ctx)
+		return err
+	}); err != nil {
+		err = network.Classify("", err)
+		var na *ErrNoAccess
+		if !errors.As(err, &na) {
+			return nil, err
+		}
+		sd.l().Printf("warning: custom profile fields labels are not available: %s", err)
+		return nil, nil
+	}
+	var fields []slack.TeamProfileField
+	for _, f := range tp.Fields {
+		if !f.IsHidden {
+			fields = append(fields, f)
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Ordering < fields[j].Ordering })
+	return fields, nil
+}
@@ -0,0 +1,117 @@
+// Package progress defines the interface for reporting the progress of the
+// archiving, so that the programs that embed slackdump, i.e. GUI wrappers or
+// bots, can render the progress their own way.  By default, the progress is
+// written to the log.
+package progress
+
+import (
+	"context"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// Reporter receives the progress events.  The methods are called from
+// several goroutines, and must be safe for concurrent use.  They should return
+// quickly, as they are called synchronously by the archiving code.
+type Reporter interface {
+	// ChannelStarted is called before the conversation, or the thread,
+	// identified by the link is dumped.
+	ChannelStarted(ctx context.Context, link string)
+	// ChannelFinished is called when the conversation is dumped, err is the
+	// error that the dump failed with, if any.
+	ChannelFinished(ctx context.Context, link string, err error)
+	// MessagesFetched is called after each API request for the messages.
+	MessagesFetched(ctx context.Context, f Fetch)
+	// FileQueued is called when the file is queued for the download into
+	// directory dir.
+	FileQueued(ctx context.Context, dir string, f *slack.File)
+	// FileDone is called when the file download is complete, n is the number
+	// of bytes written, err is the download error, if any.
+	FileDone(ctx context.Context, dir string, f *slack.File, n int64, err error)
+}
+
+// Fetch describes the API request for the messages.
+type Fetch struct {
+	ChannelID string
+	ThreadTS  string        // thread timestamp, set for the thread replies
+	Request   int           // request number, starts with 1
+	Count     int           // number of messages in the response
+	Total     int           // number of messages fetched so far
+	Took      time.Duration // duration of the request
+	Elapsed   time.Duration // time since the first request
+	Processed string        // results of the processors, i.e. "threads: 2"
+	Done      bool          // true if it was the last request
+}
+
+// IsThread returns true if the fetch is for the thread replies.
+func (f Fetch) IsThread() bool {
+	return f.ThreadTS != ""
+}
+
+// Nop is the Reporter that does nothing.  It can be embedded into the
+// reporters that are interested only in some events.
+type Nop struct{}
+
+var _ Reporter = Nop{}
+
+func (Nop) ChannelStarted(context.Context, string)                      {}
+func (Nop) ChannelFinished(context.Context, string, error)              {}
+func (Nop) MessagesFetched(context.Context, Fetch)                      {}
+func (Nop) FileQueued(context.Context, string, *slack.File)             {}
+func (Nop) FileDone(context.Context, string, *slack.File, int64, error) {}
+
+// Log is the Reporter that writes the progress to the logger.
+type Log struct {
+	lg logger.Interface
+}
+
+// NewLog returns the Reporter that writes the progress to lg, if lg is nil,
+// the default logger is used.
+func NewLog(lg logger.Interface) *Log {
+	if lg == nil {
+		lg = logger.Default
+	}
+	return &Log{lg: lg}
+}
+
+func (l *Log) ChannelStarted(_ context.Context, link string) {
+	l.lg.Debugf("dumping %s", link)
+}
+
+func (l *Log) ChannelFinished(_ context.Context, link string, err error) {
+	if err != nil {
+		l.lg.Debugf("dumping %s failed: %s", link, err)
+		return
+	}
+	l.lg.Debugf("dumping %s complete", link)
+}
+
+func (l *Log) MessagesFetched(_ context.Context, f Fetch) {
+	what, indent := "messages", ""
+	if f.IsThread() {
+		what, indent = "thread", "  "
+	}
+	l.lg.Printf("%s%s request #%5d, fetched: %4d (%s), total: %8d (speed: %6.2f/sec, avg: %6.2f/sec)\n",
+		indent, what, f.Request, f.Count, f.Processed, f.Total,
+		float64(f.Count)/f.Took.Seconds(),
+		float64(f.Total)/f.Elapsed.Seconds(),
+	)
+	if f.Done {
+		l.lg.Printf("%s%s fetch complete, total: %d", indent, what, f.Total)
+	}
+}
+
+func (l *Log) FileQueued(_ context.Context, dir string, f *slack.File) {
+	l.lg.Debugf("saving %q to %s, size: %d", f.Name, dir, f.Size)
+}
+
+func (l *Log) FileDone(_ context.Context, dir string, f *slack.File, n int64, err error) {
+	if err != nil {
+		l.lg.Printf("error saving %q to %q: %s", f.Name, dir, err)
+		return
+	}
+	l.lg.Printf("file %q saved to %s: %d bytes written", f.Name, dir, n)
+}
@@ -0,0 +1,32 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rusq/dlog"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLog(dlog.New(&buf, "", 0, false))
+	ctx := context.Background()
+
+	l.MessagesFetched(ctx, Fetch{ChannelID: "C01", Request: 1, Count: 10, Total: 10, Took: time.Second, Elapsed: time.Second, Processed: "threads: 1"})
+	l.MessagesFetched(ctx, Fetch{ChannelID: "C01", ThreadTS: "1.000", Request: 1, Count: 2, Total: 2, Took: time.Second, Elapsed: time.Second, Done: true})
+	l.FileDone(ctx, "C01", &slack.File{Name: "a.png"}, 3, nil)
+	l.FileDone(ctx, "C01", &slack.File{Name: "b.png"}, 0, errors.New("test error"))
+
+	assert.Equal(t, ""+
+		"messages request #    1, fetched:   10 (threads: 1), total:       10 (speed:  10.00/sec, avg:  10.00/sec)\n"+
+		"  thread request #    1, fetched:    2 (), total:        2 (speed:   2.00/sec, avg:   2.00/sec)\n"+
+		"  thread fetch complete, total: 2\n"+
+		"file \"a.png\" saved to C01: 3 bytes written\n"+
+		"error saving \"b.png\" to \"C01\": test error\n",
+		buf.String())
+}
@@ -0,0 +1,66 @@
+package slackdump
+
+// In this file: the reactions enrichment.
+
+import (
+	"context"
+	"runtime/trace"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// truncatedReactions returns true if the message has a reaction with at least
+// threshold reactors, and the list of the reactor users is truncated, as
+// the API does for the popular messages.
+func truncatedReactions(m *types.Message, threshold int) bool {
+	for _, r := range m.Reactions {
+		if threshold <= r.Count && len(r.Users) < r.Count {
+			return true
+		}
+	}
+	return false
+}
+
+// populateReactions replaces the truncated reactions of the messages msgs and
+// their thread replies in the channel channelID with the complete ones,
+// fetched with reactions.get.  Only the messages with at least threshold
+// reactors of some reaction are requested, as it costs one API call per
+// message.  If the reactions can't be fetched, the truncated ones are kept.
+func (sd *Session) populateReactions(ctx context.Context, channelID string, msgs []types.Message, threshold int) error {
+	ctx, task := trace.NewTask(ctx, "populateReactions")
+	defer task.End()
+
+	l := sd.pooledLimiter(network.Tier3)
+	var populate func(msgs []types.Message) error
+	populate = func(msgs []types.Message) error {
+		for i := range msgs {
+			if err := populate(msgs[i].ThreadReplies); err != nil {
+				return err
+			}
+			if !truncatedReactions(&msgs[i], threshold) {
+				continue
+			}
+			if err := sd.proceed(ctx); err != nil {
+				return err
+			}
+			var reactions []slack.ItemReaction
+			if err := network.WithRetry(ctx, l, sd.options.Tier3Retries, func() error {
+				var err error
+				reactions, err = sd.client.GetReactionsContext(ctx, slack.NewRefToMessage(channelID, msgs[i].Timestamp), slack.GetReactionsParameters{Full: true})
+				return err
+			}); err != nil {
+				if IsInterrupted(ctx, err) {
+					return err
+				}
+				sd.l().Printf("warning: failed to get the reactions of %s:%s: %s", channelID, msgs[i].Timestamp, network.Classify(channelID, err))
+				continue
+			}
+			msgs[i].Reactions = reactions
+		}
+		return nil
+	}
+	return populate(msgs)
+}
@@ -0,0 +1,68 @@
+package slackdump
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func reactedMsg(ts string, reactions ...slack.ItemReaction) types.Message {
+	var m types.Message
+	m.Timestamp = ts
+	m.Reactions = reactions
+	return m
+}
+
+func Test_truncatedReactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		reactions []slack.ItemReaction
+		threshold int
+		want      bool
+	}{
+		{"no reactions", nil, 1, false},
+		{"complete", []slack.ItemReaction{{Name: "wave", Count: 2, Users: []string{"U01", "U02"}}}, 1, false},
+		{"truncated", []slack.ItemReaction{{Name: "wave", Count: 3, Users: []string{"U01"}}}, 3, true},
+		{"truncated below threshold", []slack.ItemReaction{{Name: "wave", Count: 3, Users: []string{"U01"}}}, 4, false},
+		{"one of many", []slack.ItemReaction{
+			{Name: "wave", Count: 1, Users: []string{"U01"}},
+			{Name: "tada", Count: 50, Users: []string{"U01", "U02"}},
+		}, 10, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := reactedMsg("1.0", tt.reactions...)
+			assert.Equal(t, tt.want, truncatedReactions(&m, tt.threshold))
+		})
+	}
+}
+
+func TestSession_populateReactions(t *testing.T) {
+	full := []slack.ItemReaction{{Name: "tada", Count: 3, Users: []string{"U01", "U02", "U03"}}}
+	truncated := slack.ItemReaction{Name: "tada", Count: 3, Users: []string{"U01"}}
+
+	parent := reactedMsg("100.0")
+	parent.ThreadReplies = []types.Message{reactedMsg("101.0", truncated)}
+	msgs := []types.Message{
+		reactedMsg("99.0", slack.ItemReaction{Name: "wave", Count: 1, Users: []string{"U01"}}),
+		parent,
+		reactedMsg("102.0", truncated),
+	}
+
+	ctrl := gomock.NewController(t)
+	mc := newmockClienter(ctrl)
+	mc.EXPECT().GetReactionsContext(gomock.Any(), slack.NewRefToMessage("C01", "101.0"), slack.GetReactionsParameters{Full: true}).Return(full, nil)
+	mc.EXPECT().GetReactionsContext(gomock.Any(), slack.NewRefToMessage("C01", "102.0"), slack.GetReactionsParameters{Full: true}).
+		Return(nil, slack.SlackErrorResponse{Err: "message_not_found"})
+
+	sd := &Session{client: mc, options: DefOptions}
+	require.NoError(t, sd.populateReactions(context.Background(), "C01", msgs, 2))
+	assert.Equal(t, full, msgs[1].ThreadReplies[0].Reactions, "thread reply is populated")
+	assert.Equal(t, []slack.ItemReaction{truncated}, msgs[2].Reactions, "truncated reactions are kept on error")
+}
@@ -0,0 +1,158 @@
+package slackdump
+
+// In this file: the result of the Archive and the export.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/progress"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Result is the outcome of the Archive or the export, it is returned together
+// with the error, and is populated with the conversations that were processed
+// before the error occurred.  The methods are safe for concurrent use.
+type Result struct {
+	Started  time.Time       `json:"started"`
+	Duration time.Duration   `json:"duration"`
+	Channels []ChannelResult `json:"channels"`
+	Files    FileStats       `json:"files"`
+
+	mu sync.Mutex
+}
+
+// ChannelResult is the outcome for one conversation.
+type ChannelResult struct {
+	ID       string        `json:"id"` // channel ID, or the link, as requested
+	Name     string        `json:"name,omitempty"`
+	Messages int           `json:"messages"` // including the thread replies
+	Threads  int           `json:"threads"`
+	Files    int           `json:"files"` // number of attached files
+	Duration time.Duration `json:"duration"`
+	// Skipped is the reason why the conversation was skipped, it is empty if
+	// the conversation was processed.
+	Skipped string `json:"skipped,omitempty"`
+	// Interrupted is true if the conversation was skipped, because the run
+	// was cancelled, or the deadline budget was spent.
+	Interrupted bool `json:"interrupted,omitempty"`
+	// Err is the error, if the conversation could not be processed.
+	Err error `json:"-"`
+}
+
+// FileStats is the file download statistics.
+type FileStats struct {
+	Downloaded int   `json:"downloaded"`
+	Failed     int   `json:"failed"`
+	Bytes      int64 `json:"bytes"`
+}
+
+// NewResult returns the new Result, started now.
+func NewResult() *Result {
+	return &Result{Started: time.Now()}
+}
+
+// Add adds the conversation outcome.
+func (r *Result) Add(cr ChannelResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Channels = append(r.Channels, cr)
+}
+
+// Finish sets the duration of the run.
+func (r *Result) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Duration = time.Since(r.Started)
+}
+
+// Messages returns the total number of messages in all conversations.
+func (r *Result) Messages() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int
+	for _, cr := range r.Channels {
+		n += cr.Messages
+	}
+	return n
+}
+
+// Failed returns the conversations that could not be processed.
+func (r *Result) Failed() []ChannelResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []ChannelResult
+	for _, cr := range r.Channels {
+		if cr.Err != nil {
+			res = append(res, cr)
+		}
+	}
+	return res
+}
+
+// Pending returns the IDs of the conversations that were not processed,
+// because the run was interrupted.  It is the checkpoint of the run: passing
+// them to the next run continues the archiving.
+func (r *Result) Pending() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []string
+	for _, cr := range r.Channels {
+		if cr.Interrupted {
+			ids = append(ids, cr.ID)
+		}
+	}
+	return ids
+}
+
+// Interrupt adds the conversations ids, that were not processed, because
+// the run was interrupted by err.
+func (r *Result) Interrupt(err error, ids ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		r.Channels = append(r.Channels, ChannelResult{ID: id, Skipped: err.Error(), Interrupted: true})
+	}
+}
+
+// Reporter returns the progress reporter that counts the downloaded files
+// into the Files statistics, and forwards all events to next.
+func (r *Result) Reporter(next progress.Reporter) progress.Reporter {
+	if next == nil {
+		next = progress.Nop{}
+	}
+	return &fileCounter{Reporter: next, r: r}
+}
+
+type fileCounter struct {
+	progress.Reporter
+	r *Result
+}
+
+func (fc *fileCounter) FileDone(ctx context.Context, dir string, f *slack.File, n int64, err error) {
+	fc.r.mu.Lock()
+	if err != nil {
+		fc.r.Files.Failed++
+	} else {
+		fc.r.Files.Downloaded++
+		fc.r.Files.Bytes += n
+	}
+	fc.r.mu.Unlock()
+	fc.Reporter.FileDone(ctx, dir, f, n, err)
+}
+
+// CountMessages sets the message, thread and file counts of the conversation
+// from the messages msgs.
+func (cr *ChannelResult) CountMessages(msgs []types.Message) {
+	for i := range msgs {
+		cr.Messages++
+		cr.Files += len(msgs[i].Files)
+		if len(msgs[i].ThreadReplies) > 0 {
+			cr.Threads++
+			cr.CountMessages(msgs[i].ThreadReplies)
+		}
+	}
+}
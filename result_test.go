@@ -0,0 +1,53 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/progress"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestChannelResult_CountMessages(t *testing.T) {
+	msgs := []types.Message{
+		{Message: slack.Message{Msg: slack.Msg{Files: []slack.File{{ID: "F01"}}}}},
+		{ThreadReplies: []types.Message{
+			{Message: slack.Message{Msg: slack.Msg{Files: []slack.File{{ID: "F02"}, {ID: "F03"}}}}},
+			{},
+		}},
+	}
+	var cr ChannelResult
+	cr.CountMessages(msgs)
+	assert.Equal(t, ChannelResult{Messages: 4, Threads: 1, Files: 3}, cr)
+}
+
+type fileReporter struct {
+	progress.Nop
+	n int
+}
+
+func (r *fileReporter) FileDone(context.Context, string, *slack.File, int64, error) { r.n++ }
+
+func TestResult(t *testing.T) {
+	res := NewResult()
+	next := &fileReporter{}
+	pr := res.Reporter(next)
+	pr.FileDone(context.Background(), "C01", &slack.File{}, 10, nil)
+	pr.FileDone(context.Background(), "C01", &slack.File{}, 0, errors.New("test error"))
+	assert.Equal(t, FileStats{Downloaded: 1, Failed: 1, Bytes: 10}, res.Files)
+	assert.Equal(t, 2, next.n, "events are forwarded")
+
+	res.Add(ChannelResult{ID: "C01", Messages: 2})
+	res.Add(ChannelResult{ID: "C02", Messages: 3, Err: errors.New("test error")})
+	res.Add(ChannelResult{ID: "C03", Skipped: "excluded"})
+	assert.Equal(t, 5, res.Messages())
+	assert.Len(t, res.Failed(), 1)
+	res.Finish()
+	assert.NotZero(t, res.Duration)
+
+	assert.NotPanics(t, func() { NewResult().Reporter(nil).FileDone(context.Background(), "", nil, 0, nil) })
+}
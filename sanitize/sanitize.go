@@ -0,0 +1,176 @@
+// Package sanitize makes the file and directory names of the archives valid on
+// the target platform.  The names of the downloaded files come from Slack,
+// and may contain the characters, i.e. colons, that are valid on Linux and
+// macOS, but not on Windows, so that the archive, created on one platform,
+// can not be unpacked on the other.
+//
+// The sanitization is done by the profile, that is selected by the user:
+//
+//   - WindowsSafe replaces the characters reserved on Windows, renames the
+//     reserved device names (CON, NUL, COM1, etc.), and removes the trailing
+//     dots and spaces.  The names are valid on any platform.
+//   - POSIX replaces only the slash and the NUL character.
+//   - Preserve leaves the names as they are.
+//
+// All profiles, except Preserve, limit the length of the name to MaxName
+// bytes, keeping the extension.
+package sanitize
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+)
+
+// Profile is the sanitization profile.  It implements flag.Value.
+type Profile uint8
+
+const (
+	// Preserve leaves the names unchanged.
+	Preserve Profile = iota
+	// POSIX replaces the characters, that are invalid on the POSIX systems.
+	POSIX
+	// WindowsSafe makes names valid on Windows, and therefore, on any
+	// platform.
+	WindowsSafe
+)
+
+// Default is the profile of the current platform:  WindowsSafe on Windows,
+// POSIX elsewhere.
+var Default = defaultProfile(runtime.GOOS)
+
+func defaultProfile(goos string) Profile {
+	if goos == "windows" {
+		return WindowsSafe
+	}
+	return POSIX
+}
+
+const (
+	// MaxName is the maximum length of the name in bytes, it is the limit of
+	// the most file systems.
+	MaxName = 255
+	// maxExt is the maximum length of the extension, that is kept when
+	// the name is truncated.
+	maxExt = 16
+	// repl is the replacement of the invalid characters.
+	repl = '_'
+)
+
+var profileNames = map[Profile]string{
+	Preserve:    "preserve",
+	POSIX:       "posix",
+	WindowsSafe: "windows-safe",
+}
+
+func (p Profile) String() string {
+	if s, ok := profileNames[p]; ok {
+		return s
+	}
+	return fmt.Sprintf("Profile(%d)", uint8(p))
+}
+
+// Set sets the profile from its name, it is the flag.Value interface.
+func (p *Profile) Set(s string) error {
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// Parse returns the profile with the name s.
+func Parse(s string) (Profile, error) {
+	for p, name := range profileNames {
+		if strings.EqualFold(s, name) {
+			return p, nil
+		}
+	}
+	return Preserve, fmt.Errorf("unknown filename profile: %q, use one of: windows-safe, posix or preserve", s)
+}
+
+// Name returns the name s, that is valid as a single path element under the
+// profile.  The slashes are replaced, so it should not be used on paths, see
+// Path.
+func (p Profile) Name(s string) string {
+	switch p {
+	case POSIX:
+		s = strings.Map(posixRune, s)
+	case WindowsSafe:
+		s = strings.TrimRight(strings.Map(windowsRune, s), ". ")
+		if isReserved(s) {
+			s = string(repl) + s
+		}
+	default:
+		return s
+	}
+	if s == "" || s == "." || s == ".." {
+		return string(repl)
+	}
+	return truncate(s, MaxName)
+}
+
+// Path returns the slash separated path s with each element sanitized with
+// Name.  The empty, "." and ".." elements are left as they are.
+func (p Profile) Path(s string) string {
+	if p == Preserve {
+		return s
+	}
+	elems := strings.Split(s, "/")
+	for i, e := range elems {
+		if e == "" || e == "." || e == ".." {
+			continue
+		}
+		elems[i] = p.Name(e)
+	}
+	return strings.Join(elems, "/")
+}
+
+func posixRune(r rune) rune {
+	if r == '/' || r == 0 {
+		return repl
+	}
+	return r
+}
+
+func windowsRune(r rune) rune {
+	if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+		return repl
+	}
+	return r
+}
+
+// isReserved returns true if the name s is the reserved device name on
+// Windows.  The device names are reserved with any extension, i.e. "nul.txt".
+func isReserved(s string) bool {
+	base, _, _ := strings.Cut(s, ".")
+	base = strings.ToUpper(strings.TrimRight(base, " "))
+	switch base {
+	case "CON", "PRN", "AUX", "NUL":
+		return true
+	}
+	if len(base) == 4 && (strings.HasPrefix(base, "COM") || strings.HasPrefix(base, "LPT")) {
+		return '1' <= base[3] && base[3] <= '9'
+	}
+	return false
+}
+
+// truncate truncates s to n bytes, keeping the extension, if it's not too
+// long.  It does not split the UTF-8 characters.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	ext := path.Ext(s)
+	if len(ext) > maxExt || len(ext) == len(s) {
+		ext = ""
+	}
+	base := s[:n-len(ext)]
+	for len(base) > 0 && !utf8.RuneStart(s[len(base)]) {
+		base = base[:len(base)-1]
+	}
+	return base + ext
+}
@@ -0,0 +1,67 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile_Name(t *testing.T) {
+	long := strings.Repeat("ж", 200) + ".txt" // 404 bytes
+	tests := []struct {
+		name string
+		p    Profile
+		s    string
+		want string
+	}{
+		{"preserve", Preserve, `F01-a:b/c?.txt`, `F01-a:b/c?.txt`},
+		{"posix", POSIX, "F01-a:b/c?\x00.txt", "F01-a:b_c?_.txt"},
+		{"posix dot", POSIX, "..", "_"},
+		{"windows", WindowsSafe, `F01-a:b/c?"<>|*\.txt`, "F01-a_b_c_______.txt"},
+		{"windows control", WindowsSafe, "a\tb\n", "a_b_"},
+		{"windows trailing", WindowsSafe, "report. . ", "report"},
+		{"windows reserved", WindowsSafe, "nul.txt", "_nul.txt"},
+		{"windows reserved com", WindowsSafe, "COM1", "_COM1"},
+		{"windows not reserved", WindowsSafe, "console.log", "console.log"},
+		{"windows not reserved com", WindowsSafe, "COM10", "COM10"},
+		{"windows empty", WindowsSafe, "...", "_"},
+		{"unicode", WindowsSafe, "日本語: メモ.pdf", "日本語_ メモ.pdf"},
+		{"long", WindowsSafe, long, strings.Repeat("ж", 125) + ".txt"},
+		{"long preserve", Preserve, long, long},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.p.Name(tt.s)
+			assert.Equal(t, tt.want, got)
+			if tt.p != Preserve {
+				assert.LessOrEqual(t, len(got), MaxName)
+			}
+		})
+	}
+}
+
+func TestProfile_Path(t *testing.T) {
+	assert.Equal(t, "C01/attachments/F01-a_b.txt", WindowsSafe.Path("C01/attachments/F01-a:b.txt"))
+	assert.Equal(t, "./a/../b_c", WindowsSafe.Path("./a/../b:c"))
+	assert.Equal(t, "a/b:c", POSIX.Path("a/b:c"))
+}
+
+func TestParse(t *testing.T) {
+	for _, p := range []Profile{Preserve, POSIX, WindowsSafe} {
+		got, err := Parse(p.String())
+		assert.NoError(t, err)
+		assert.Equal(t, p, got)
+	}
+	var p Profile
+	assert.NoError(t, p.Set("Windows-Safe"))
+	assert.Equal(t, WindowsSafe, p)
+	assert.Error(t, p.Set("dos"))
+	assert.Equal(t, WindowsSafe, p, "value should not change on error")
+}
+
+func Test_defaultProfile(t *testing.T) {
+	assert.Equal(t, WindowsSafe, defaultProfile("windows"))
+	assert.Equal(t, POSIX, defaultProfile("linux"))
+	assert.Equal(t, POSIX, defaultProfile("darwin"))
+}
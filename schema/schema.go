@@ -0,0 +1,70 @@
+// Package schema publishes the JSON Schemas of the files, produced by
+// slackdump: the conversation dumps, the messages and the index files of the
+// export, and the user lists.  The schemas are the contract for the
+// downstream consumers: the fields, described by the schema, have the stated
+// types, and the required fields are always present.  The fields, that are
+// not described, may be added or removed by Slack, and are not checked.
+//
+// The schemas are embedded, and the outputs can be checked against them with
+// Get and Schema.Validate.
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+)
+
+// Names of the published schemas.
+const (
+	Conversation   = "conversation.schema.json"    // dump: <channel ID>.json
+	ExportMessages = "export-messages.schema.json" // export: <channel>/<YYYY-MM-DD>.json
+	ExportChannels = "export-channels.schema.json" // export: channels.json, groups.json and mpims.json
+	ExportDMs      = "export-dms.schema.json"      // export: dms.json
+	Users          = "users.schema.json"           // dump and export: users.json
+)
+
+//go:embed *.schema.json
+var schemaFS embed.FS
+
+var (
+	mu       sync.Mutex
+	compiled = make(map[string]*Schema)
+)
+
+// Names returns the names of the published schemas.
+func Names() []string {
+	names, _ := fs.Glob(schemaFS, "*.schema.json")
+	sort.Strings(names)
+	return names
+}
+
+// Source returns the JSON source of the schema name.
+func Source(name string) ([]byte, error) {
+	data, err := schemaFS.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown schema: %q", name)
+	}
+	return data, nil
+}
+
+// Get returns the compiled schema name.
+func Get(name string) (*Schema, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if s, ok := compiled[name]; ok {
+		return s, nil
+	}
+	data, err := Source(name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Compile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	compiled[name] = s
+	return s, nil
+}
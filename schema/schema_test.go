@@ -0,0 +1,71 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/internal/fixtures"
+)
+
+func TestGet(t *testing.T) {
+	names := Names()
+	assert.Equal(t, []string{Conversation, ExportChannels, ExportDMs, ExportMessages, Users}, names)
+	for _, name := range names {
+		s, err := Get(name)
+		require.NoError(t, err, name)
+		assert.NotNil(t, s)
+	}
+	_, err := Get("unknown.schema.json")
+	assert.Error(t, err)
+}
+
+func mustGet(t *testing.T, name string) *Schema {
+	t.Helper()
+	s, err := Get(name)
+	require.NoError(t, err)
+	return s
+}
+
+func TestFixtures(t *testing.T) {
+	t.Run("conversation", func(t *testing.T) {
+		assert.NoError(t, mustGet(t, Conversation).Validate([]byte(fixtures.TestConversationJSON)))
+	})
+	t.Run("export messages", func(t *testing.T) {
+		var days map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal([]byte(fixtures.TestConversationExportJSON), &days))
+		for day, msgs := range days {
+			assert.NoError(t, mustGet(t, ExportMessages).Validate(msgs), day)
+		}
+	})
+	t.Run("channels", func(t *testing.T) {
+		assert.NoError(t, mustGet(t, ExportChannels).Validate([]byte("["+fixtures.TestChannel+"]")))
+	})
+	t.Run("users", func(t *testing.T) {
+		assert.NoError(t, mustGet(t, Users).Validate([]byte(fixtures.UsersJSON)))
+	})
+}
+
+func TestConversation_violations(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"no channel id", `{"name":"x","messages":[]}`, `/: missing required property "channel_id"`},
+		{"no ts", `{"name":"x","channel_id":"C01","messages":[{"text":"hi"}]}`, `/messages/0: missing required property "ts"`},
+		{"invalid ts", `{"name":"x","channel_id":"C01","messages":[{"ts":"yesterday"}]}`, `/messages/0/ts: "yesterday" does not match`},
+		{"invalid reply", `{"name":"x","channel_id":"C01","messages":[{"ts":"1.2","slackdump_thread_replies":[{"ts":1}]}]}`, `/messages/0/slackdump_thread_replies/0/ts: expected string, got number`},
+		{"file without id", `{"name":"x","channel_id":"C01","messages":[{"ts":"1.2","files":[{"name":"a.txt"}]}]}`, `/messages/0/files/0: missing required property "id"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mustGet(t, Conversation).Validate([]byte(tt.doc))
+			var ve *ValidationError
+			require.ErrorAs(t, err, &ve)
+			assert.Contains(t, ve.Errors[0], tt.want)
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Schema is the compiled JSON Schema.  Only the subset of the keywords, that
+// is used by the published schemas, is supported: $ref to the $defs of the
+// same schema, type, enum, pattern, properties, required,
+// additionalProperties, items and minItems.  The annotations, i.e. title,
+// description and format, are ignored.  The schema with any other keyword
+// is rejected by Compile, so that the validation is never weaker than the
+// schema says.
+type Schema struct {
+	root     node
+	defs     map[string]node
+	patterns map[string]*regexp.Regexp // compiled patterns by the source.
+}
+
+type node map[string]any
+
+// annotations are the keywords, that don't affect the validation.
+var annotations = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"$defs":       true,
+	"title":       true,
+	"description": true,
+	"format":      true,
+	"examples":    true,
+}
+
+// keywords are the supported validation keywords.
+var keywords = map[string]bool{
+	"$ref":                 true,
+	"type":                 true,
+	"enum":                 true,
+	"pattern":              true,
+	"properties":           true,
+	"required":             true,
+	"additionalProperties": true,
+	"items":                true,
+	"minItems":             true,
+}
+
+// Compile compiles the JSON Schema data.
+func Compile(data []byte) (*Schema, error) {
+	var root node
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	s := &Schema{root: root, defs: make(map[string]node), patterns: make(map[string]*regexp.Regexp)}
+	if defs, ok := root["$defs"].(map[string]any); ok {
+		for name, d := range defs {
+			n, ok := d.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("$defs/%s: not a schema", name)
+			}
+			s.defs[name] = n
+		}
+	}
+	if err := s.check(root, "#"); err != nil {
+		return nil, err
+	}
+	for name, n := range s.defs {
+		if err := s.check(n, "#/$defs/"+name); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// check checks, that the schema node n uses only the supported keywords, and
+// that the references and patterns are valid.
+func (s *Schema) check(n node, at string) error {
+	for k, v := range n {
+		switch {
+		case annotations[k]:
+			continue
+		case !keywords[k]:
+			return fmt.Errorf("%s: unsupported keyword %q", at, k)
+		}
+		switch k {
+		case "$ref":
+			ref, _ := v.(string)
+			if _, ok := s.defs[strings.TrimPrefix(ref, "#/$defs/")]; !ok || !strings.HasPrefix(ref, "#/$defs/") {
+				return fmt.Errorf("%s: unresolved reference %q", at, ref)
+			}
+		case "pattern":
+			p, _ := v.(string)
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("%s: %w", at, err)
+			}
+			s.patterns[p] = re
+		case "properties":
+			props, _ := v.(map[string]any)
+			for name, p := range props {
+				pn, ok := p.(map[string]any)
+				if !ok {
+					return fmt.Errorf("%s/properties/%s: not a schema", at, name)
+				}
+				if err := s.check(pn, at+"/properties/"+name); err != nil {
+					return err
+				}
+			}
+		case "items", "additionalProperties":
+			if sub, ok := v.(map[string]any); ok {
+				if err := s.check(sub, at+"/"+k); err != nil {
+					return err
+				}
+			} else if _, ok := v.(bool); !ok || k == "items" {
+				return fmt.Errorf("%s/%s: not a schema", at, k)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidationError is returned by Validate, if the document does not match
+// the schema.
+type ValidationError struct {
+	// Errors are the violations, each is prefixed with the JSON Pointer of
+	// the offending value.
+	Errors []string
+}
+
+// maxErrors is the maximum number of the violations, that are reported.
+const maxErrors = 20
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0]
+	}
+	return fmt.Sprintf("%d violation(s), the first: %s", len(e.Errors), e.Errors[0])
+}
+
+// Validate validates the JSON document data against the schema.  It returns
+// the *ValidationError, if the document does not match.
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("extra data after the JSON document")
+	}
+	var ve ValidationError
+	s.validate(s.root, v, "", &ve)
+	if len(ve.Errors) > 0 {
+		return &ve
+	}
+	return nil
+}
+
+func (s *Schema) validate(n node, v any, ptr string, ve *ValidationError) {
+	if len(ve.Errors) >= maxErrors {
+		return
+	}
+	fail := func(format string, a ...any) {
+		if len(ve.Errors) < maxErrors {
+			ve.Errors = append(ve.Errors, pointer(ptr)+": "+fmt.Sprintf(format, a...))
+		}
+	}
+	if ref, ok := n["$ref"].(string); ok {
+		s.validate(s.defs[strings.TrimPrefix(ref, "#/$defs/")], v, ptr, ve)
+	}
+	if t, ok := n["type"]; ok && !matchType(t, v) {
+		fail("expected %s, got %s", typeString(t), typeOf(v))
+		return
+	}
+	if enum, ok := n["enum"].([]any); ok && !inEnum(enum, v) {
+		fail("value %v is not one of %v", v, enum)
+	}
+	if p, ok := n["pattern"].(string); ok {
+		if str, ok := v.(string); ok && !s.patterns[p].MatchString(str) {
+			fail("%q does not match %q", str, p)
+		}
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		props, _ := n["properties"].(map[string]any)
+		if req, ok := n["required"].([]any); ok {
+			for _, r := range req {
+				if name, _ := r.(string); name != "" {
+					if _, ok := val[name]; !ok {
+						fail("missing required property %q", name)
+					}
+				}
+			}
+		}
+		for name, pv := range val {
+			if p, ok := props[name].(map[string]any); ok {
+				s.validate(p, pv, ptr+"/"+escape(name), ve)
+				continue
+			}
+			switch ap := n["additionalProperties"].(type) {
+			case bool:
+				if !ap {
+					fail("unexpected property %q", name)
+				}
+			case map[string]any:
+				s.validate(ap, pv, ptr+"/"+escape(name), ve)
+			}
+		}
+	case []any:
+		if mi, ok := n["minItems"].(float64); ok && len(val) < int(mi) {
+			fail("expected at least %d item(s), got %d", int(mi), len(val))
+		}
+		if items, ok := n["items"].(map[string]any); ok {
+			for i, iv := range val {
+				s.validate(items, iv, ptr+"/"+strconv.Itoa(i), ve)
+			}
+		}
+	}
+}
+
+// matchType returns true, if v matches the type t, that is a string or the
+// list of strings.
+func matchType(t any, v any) bool {
+	switch tt := t.(type) {
+	case string:
+		return isType(tt, v)
+	case []any:
+		for _, t := range tt {
+			if ts, ok := t.(string); ok && isType(ts, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isType(t string, v any) bool {
+	switch t {
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	default:
+		return typeOf(v) == t
+	}
+}
+
+// typeOf returns the JSON type name of the decoded value v.
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func typeString(t any) string {
+	if tt, ok := t.([]any); ok {
+		ss := make([]string, 0, len(tt))
+		for _, t := range tt {
+			ss = append(ss, fmt.Sprint(t))
+		}
+		return strings.Join(ss, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func inEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		if n, ok := v.(json.Number); ok {
+			if f, err := n.Float64(); err == nil && e == f {
+				return true
+			}
+			continue
+		}
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// escape escapes the JSON Pointer token.
+func escape(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+func pointer(ptr string) string {
+	if ptr == "" {
+		return "/"
+	}
+	return ptr
+}
@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{"ok", `{"type":"object","properties":{"a":{"$ref":"#/$defs/a"}},"$defs":{"a":{"type":"string"}}}`, false},
+		{"annotations", `{"$schema":"x","title":"t","description":"d","format":"date"}`, false},
+		{"unsupported keyword", `{"type":"object","oneOf":[]}`, true},
+		{"unsupported nested keyword", `{"properties":{"a":{"minLength":1}}}`, true},
+		{"unresolved reference", `{"$ref":"#/$defs/missing"}`, true},
+		{"external reference", `{"$ref":"other.json"}`, true},
+		{"invalid pattern", `{"pattern":"("}`, true},
+		{"invalid items", `{"items":true}`, true},
+		{"not json", `{`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	const sch = `{
+		"type": "object",
+		"required": ["id"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string", "pattern": "^U"},
+			"n": {"type": "integer"},
+			"f": {"type": "number"},
+			"kind": {"enum": ["a", "b", 1]},
+			"tags": {"type": ["array", "null"], "minItems": 1, "items": {"type": "string"}},
+			"meta": {"type": "object", "additionalProperties": {"type": "boolean"}}
+		}
+	}`
+	s, err := Compile([]byte(sch))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"valid", `{"id":"U01","n":1,"f":1.5,"kind":"a","tags":["x"],"meta":{"x":true}}`, nil},
+		{"null is allowed", `{"id":"U01","tags":null}`, nil},
+		{"numeric enum", `{"id":"U01","kind":1}`, nil},
+		{"not an object", `[]`, []string{"/: expected object, got array"}},
+		{"missing required", `{}`, []string{`/: missing required property "id"`}},
+		{"pattern", `{"id":"C01"}`, []string{`/id: "C01" does not match "^U"`}},
+		{"not an integer", `{"id":"U01","n":1.5}`, []string{"/n: expected integer, got number"}},
+		{"enum", `{"id":"U01","kind":"c"}`, []string{"/kind: value c is not one of [a b 1]"}},
+		{"min items", `{"id":"U01","tags":[]}`, []string{"/tags: expected at least 1 item(s), got 0"}},
+		{"items", `{"id":"U01","tags":["x",2]}`, []string{"/tags/1: expected string, got number"}},
+		{"additional", `{"id":"U01","x":1}`, []string{`/: unexpected property "x"`}},
+		{"additional schema", `{"id":"U01","meta":{"a/b":1}}`, []string{"/meta/a~1b: expected boolean, got number"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate([]byte(tt.doc))
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var ve *ValidationError
+			require.ErrorAs(t, err, &ve)
+			assert.Equal(t, tt.want, ve.Errors)
+		})
+	}
+	t.Run("invalid json", func(t *testing.T) {
+		assert.Error(t, s.Validate([]byte(`{"id":`)))
+		assert.Error(t, s.Validate([]byte(`{"id":"U01"} {}`)))
+	})
+}
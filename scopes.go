@@ -0,0 +1,121 @@
+package slackdump
+
+// In this file: the OAuth scopes of the token.
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/structures/files"
+	"github.com/rusq/slackdump/v2/transport"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// scopeFilesRead is the scope, that is required to download the files.
+const scopeFilesRead = "files:read"
+
+// scopeRecorder records the X-OAuth-Scopes header of the auth.test
+// responses, so that the scopes are known without the extra API call.
+type scopeRecorder struct {
+	mu  sync.Mutex
+	hdr string
+}
+
+// middleware returns the middleware, that records the header.
+func (r *scopeRecorder) middleware() transport.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return transport.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err == nil && strings.HasSuffix(req.URL.Path, "/auth.test") {
+				if hdr := resp.Header.Get("X-OAuth-Scopes"); hdr != "" {
+					r.mu.Lock()
+					r.hdr = hdr
+					r.mu.Unlock()
+				}
+			}
+			return resp, err
+		})
+	}
+}
+
+// scopes returns the recorded scopes, or nil, if the header was not seen,
+// i.e. for the browser tokens.
+func (r *scopeRecorder) scopes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hdr == "" {
+		return nil
+	}
+	scopes := []string{}
+	for _, s := range strings.Split(r.hdr, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// Scopes returns the OAuth scopes of the token, as reported by the API when
+// the session was created.  It returns nil, if the scopes are unknown, i.e.
+// for the browser tokens, that are not restricted by scopes, or for the
+// sessions created with NewWithClient.
+func (sd *Session) Scopes() []string {
+	return sd.scopes
+}
+
+// hasScope reports whether the token has the scope.  If the scopes are
+// unknown, the token is assumed to have it.
+func (sd *Session) hasScope(scope string) bool {
+	if sd.scopes == nil {
+		return true
+	}
+	for _, s := range sd.scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReadFiles reports whether the token is allowed to download the files.
+// If it is not, the files are not downloaded, and are listed in the
+// FilesSkipped of the Manifest instead.
+func (sd *Session) CanReadFiles() bool {
+	return sd.hasScope(scopeFilesRead)
+}
+
+// skipFiles records the files of the messages msgs and their thread replies
+// as skipped, if the token is not allowed to download them.
+func (sd *Session) skipFiles(msgs []types.Message) {
+	if sd.CanReadFiles() {
+		return
+	}
+	sd.skippedMu.Lock()
+	defer sd.skippedMu.Unlock()
+	_ = files.Extract(msgs, files.Root, func(f slack.File, _ files.Addr) error {
+		if sd.skipped == nil {
+			sd.skipped = make(map[string]bool)
+		}
+		sd.skipped[f.ID] = true
+		return nil
+	})
+}
+
+// skippedFiles returns the sorted IDs of the skipped files.
+func (sd *Session) skippedFiles() []string {
+	sd.skippedMu.Lock()
+	defer sd.skippedMu.Unlock()
+	if len(sd.skipped) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(sd.skipped))
+	for id := range sd.skipped {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
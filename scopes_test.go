@@ -0,0 +1,91 @@
+package slackdump
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestNewWithOptions_scopes(t *testing.T) {
+	newSession := func(t *testing.T, hdr string) *Session {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/auth.test":
+				if hdr != "" {
+					w.Header().Set("X-OAuth-Scopes", hdr)
+				}
+				fmt.Fprint(w, `{"ok":true,"url":"https://test.slack.com/","team_id":"T01","user_id":"U01"}`)
+			case "/api/users.list":
+				fmt.Fprint(w, `{"ok":true,"members":[{"id":"U01","name":"alice"}]}`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		prov, err := auth.NewValueAuth("xoxp-test", "")
+		require.NoError(t, err)
+		opts := DefOptions
+		opts.CacheDir = t.TempDir()
+		WithBaseURL(srv.URL+"/", "")(&opts)
+		DownloadFiles(true)(&opts)
+		sd, err := NewWithOptions(context.Background(), prov, opts)
+		require.NoError(t, err)
+		return sd
+	}
+	t.Run("files:read", func(t *testing.T) {
+		sd := newSession(t, "channels:history, files:read,users:read")
+		assert.Equal(t, []string{"channels:history", "files:read", "users:read"}, sd.Scopes())
+		assert.True(t, sd.CanReadFiles())
+		assert.True(t, sd.options.DumpFiles)
+	})
+	t.Run("no files:read", func(t *testing.T) {
+		sd := newSession(t, "channels:history,users:read")
+		assert.False(t, sd.CanReadFiles())
+		assert.False(t, sd.options.DumpFiles, "files are not downloaded")
+	})
+	t.Run("unknown scopes", func(t *testing.T) {
+		sd := newSession(t, "")
+		assert.Nil(t, sd.Scopes())
+		assert.True(t, sd.CanReadFiles())
+		assert.True(t, sd.options.DumpFiles)
+	})
+}
+
+func TestSession_skipFiles(t *testing.T) {
+	msgs := []types.Message{
+		{Message: slack.Message{Msg: slack.Msg{Files: []slack.File{{ID: "F02"}}}}},
+		{
+			Message: slack.Message{Msg: slack.Msg{Files: []slack.File{{ID: "F01"}}}},
+			ThreadReplies: []types.Message{
+				{Message: slack.Message{Msg: slack.Msg{Files: []slack.File{{ID: "F03"}, {ID: "F02"}}}}},
+			},
+		},
+	}
+	t.Run("unknown scopes", func(t *testing.T) {
+		sd := &Session{options: DefOptions}
+		assert.True(t, sd.CanReadFiles())
+		sd.skipFiles(msgs)
+		assert.Empty(t, sd.Manifest().FilesSkipped)
+	})
+	t.Run("files:read", func(t *testing.T) {
+		sd := &Session{options: DefOptions, scopes: []string{"channels:history", "files:read"}}
+		assert.True(t, sd.CanReadFiles())
+		sd.skipFiles(msgs)
+		assert.Empty(t, sd.Manifest().FilesSkipped)
+	})
+	t.Run("no files:read", func(t *testing.T) {
+		sd := &Session{options: DefOptions, scopes: []string{"channels:history"}}
+		assert.False(t, sd.CanReadFiles())
+		sd.skipFiles(msgs)
+		assert.Equal(t, []string{"F01", "F02", "F03"}, sd.Manifest().FilesSkipped)
+	})
+}
@@ -0,0 +1,124 @@
+package slackdump
+
+// In this file: the search API.
+
+import (
+	"context"
+	"runtime/trace"
+	"sort"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// searchPerReq is the page size of the search results, the maximum, allowed
+// by the API.
+const searchPerReq = 100
+
+// SearchMessages returns the messages, that match the query in the Slack
+// search syntax, i.e. "from:@bob in:#general before:2023-01-01", oldest
+// first, grouped into the conversations by the channel.  The messages have no
+// thread replies, the replies, that match the query, are returned as the
+// channel messages.  The search requires the user token, the bot tokens
+// can't search.
+func (sd *Session) SearchMessages(ctx context.Context, query string) ([]types.Conversation, error) {
+	ctx, task := trace.NewTask(ctx, "SearchMessages")
+	defer task.End()
+
+	var matches []slack.SearchMessage
+	if err := sd.search(ctx, func(params slack.SearchParameters) (*slack.Paging, error) {
+		res, err := sd.client.SearchMessagesContext(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, res.Matches...)
+		return &res.Paging, nil
+	}); err != nil {
+		return nil, err
+	}
+	return searchConversations(matches), nil
+}
+
+// SearchFiles returns the files, that match the query in the Slack search
+// syntax, oldest first.
+func (sd *Session) SearchFiles(ctx context.Context, query string) ([]slack.File, error) {
+	ctx, task := trace.NewTask(ctx, "SearchFiles")
+	defer task.End()
+
+	var files []slack.File
+	if err := sd.search(ctx, func(params slack.SearchParameters) (*slack.Paging, error) {
+		res, err := sd.client.SearchFilesContext(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, res.Matches...)
+		return &res.Paging, nil
+	}); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// search calls the search method fn for each page of the results, until the
+// last page.
+func (sd *Session) search(ctx context.Context, fn func(params slack.SearchParameters) (*slack.Paging, error)) error {
+	l := sd.limiter(network.Tier2)
+	params := slack.NewSearchParameters()
+	params.Count = searchPerReq
+	// the stable order, so that the pages don't shift, if the new
+	// messages are posted during the search.
+	params.Sort, params.SortDirection = "timestamp", "asc"
+	for {
+		if err := sd.proceed(ctx); err != nil {
+			return err
+		}
+		var paging *slack.Paging
+		if err := network.WithRetry(ctx, l, sd.options.Tier2Retries, func() error {
+			var err error
+			paging, err = fn(params)
+			return err
+		}); err != nil {
+			return network.Classify("", err)
+		}
+		if paging == nil || paging.Page >= paging.Pages {
+			return nil
+		}
+		params.Page = paging.Page + 1
+	}
+}
+
+// searchConversations groups the search matches into the conversations,
+// sorted by the channel ID, with the messages sorted by the timestamp.
+func searchConversations(matches []slack.SearchMessage) []types.Conversation {
+	var (
+		idx   = make(map[string]int)
+		convs []types.Conversation
+	)
+	for _, m := range matches {
+		i, ok := idx[m.Channel.ID]
+		if !ok {
+			i = len(convs)
+			idx[m.Channel.ID] = i
+			convs = append(convs, types.Conversation{ID: m.Channel.ID, Name: m.Channel.Name})
+		}
+		convs[i].Messages = append(convs[i].Messages, types.Message{Message: slack.Message{Msg: slack.Msg{
+			Type:        m.Type,
+			Channel:     m.Channel.ID,
+			User:        m.User,
+			Username:    m.Username,
+			Timestamp:   m.Timestamp,
+			Text:        m.Text,
+			Blocks:      m.Blocks,
+			Attachments: m.Attachments,
+			Permalink:   m.Permalink,
+		}}})
+	}
+	sort.Slice(convs, func(i, j int) bool { return convs[i].ID < convs[j].ID })
+	for _, c := range convs {
+		msgs := c.Messages
+		sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].Timestamp < msgs[j].Timestamp })
+	}
+	return convs
+}
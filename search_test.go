@@ -0,0 +1,55 @@
+package slackdump
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_SearchMessages(t *testing.T) {
+	match := func(chID, chName, ts, text string) slack.SearchMessage {
+		m := slack.SearchMessage{Timestamp: ts, Text: text}
+		m.Channel.ID, m.Channel.Name = chID, chName
+		return m
+	}
+
+	mc := newmockClienter(gomock.NewController(t))
+	gomock.InOrder(
+		mc.EXPECT().SearchMessagesContext(gomock.Any(), "in:#general foo", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, params slack.SearchParameters) (*slack.SearchMessages, error) {
+				assert.Equal(t, searchPerReq, params.Count)
+				assert.Equal(t, "timestamp", params.Sort)
+				return &slack.SearchMessages{
+					Matches: []slack.SearchMessage{
+						match("C02", "random", "300.000000", "foo"),
+						match("C01", "general", "200.000000", "foo bar"),
+					},
+					Paging: slack.Paging{Page: 1, Pages: 2},
+				}, nil
+			}),
+		mc.EXPECT().SearchMessagesContext(gomock.Any(), "in:#general foo", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, params slack.SearchParameters) (*slack.SearchMessages, error) {
+				assert.Equal(t, 2, params.Page)
+				return &slack.SearchMessages{
+					Matches: []slack.SearchMessage{match("C01", "general", "100.000000", "foo")},
+					Paging:  slack.Paging{Page: 2, Pages: 2},
+				}, nil
+			}),
+	)
+
+	sd := &Session{client: mc, options: DefOptions}
+	got, err := sd.SearchMessages(context.Background(), "in:#general foo")
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "C01", got[0].ID)
+	assert.Equal(t, "general", got[0].Name)
+	require.Len(t, got[0].Messages, 2)
+	assert.Equal(t, "100.000000", got[0].Messages[0].Timestamp)
+	assert.Equal(t, "C01", got[0].Messages[0].Channel)
+	assert.Equal(t, "C02", got[1].ID)
+	assert.Len(t, got[1].Messages, 1)
+}
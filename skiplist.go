@@ -0,0 +1,257 @@
+package slackdump
+
+// In this file: the skip-list of the items, that fail run after run.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/structures"
+)
+
+// SkipListFile is the name of the skip-list file in the cache directory.
+const SkipListFile = "skiplist.json"
+
+// Kinds of the skip-list items.
+const (
+	SkipConversation = "conversation" // the ID is the channel ID.
+	SkipThread       = "thread"       // the ID is "<channel ID>:<thread ts>".
+	SkipFile         = "file"         // the ID is the file ID.
+)
+
+// ErrSkipListed is returned, wrapped, for the conversations and the threads,
+// that are skipped, because they are on the skip-list.
+var ErrSkipListed = errors.New("on the skip-list")
+
+// SkipEntry is the item of the skip-list.
+type SkipEntry struct {
+	Kind     string    `json:"kind"`
+	ID       string    `json:"id"`
+	Reason   string    `json:"reason"`   // the last error.
+	Failures int       `json:"failures"` // number of the runs in a row, the item failed in.
+	First    time.Time `json:"first"`
+	Last     time.Time `json:"last"`
+}
+
+// Key returns the key of the entry, "<kind>:<id>".
+func (e SkipEntry) Key() string {
+	return skipKey(e.Kind, e.ID)
+}
+
+func skipKey(kind, id string) string {
+	return kind + ":" + id
+}
+
+// SkipList is the persistent list of the conversations, the threads and the
+// files, that fail run after run, i.e. the message, that makes the API return
+// the server error.  Once the item fails in After runs in a row, it is
+// skipped, so that one bad item does not fail every run.  The item, that is
+// fetched successfully, is removed.  The skipped items stay on the list
+// until they are removed with Remove.  The list is saved after each change,
+// so that it survives the crash of the run.  The methods are safe for
+// concurrent use, and the nil SkipList skips nothing, see WithSkipList.
+type SkipList struct {
+	After int // number of the failed runs, after which the item is skipped.
+
+	filename string
+	mu       sync.Mutex
+	entries  map[string]*SkipEntry
+	// failed are the items, that failed in this run, so that the retries
+	// within the run are counted once.
+	failed map[string]bool
+}
+
+// OpenSkipList opens the skip-list in the file filename, the file is created
+// with the first failure.  after is the number of the failed runs, after
+// which the item is skipped.
+func OpenSkipList(filename string, after int) (*SkipList, error) {
+	sl := &SkipList{After: after, filename: filename, entries: make(map[string]*SkipEntry), failed: make(map[string]bool)}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return sl, nil
+		}
+		return nil, err
+	}
+	var ee []SkipEntry
+	if err := json.Unmarshal(data, &ee); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	for i := range ee {
+		sl.entries[ee[i].Key()] = &ee[i]
+	}
+	return sl, nil
+}
+
+// Skipped returns the reason, and true, if the item is skipped.
+func (sl *SkipList) Skipped(kind, id string) (string, bool) {
+	if sl == nil {
+		return "", false
+	}
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	e, ok := sl.entries[skipKey(kind, id)]
+	if !ok || e.Failures < sl.After {
+		return "", false
+	}
+	return e.Reason, true
+}
+
+// Fail records the failure of the item with the error err.  The failures are
+// counted once per run, and the errors, that are not caused by the item, i.e.
+// the interruptions, the rate limits or the expired token, are not counted.
+func (sl *SkipList) Fail(kind, id string, err error) error {
+	if sl == nil || !countable(err) {
+		return nil
+	}
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	key := skipKey(kind, id)
+	now := time.Now().UTC()
+	e, ok := sl.entries[key]
+	if !ok {
+		e = &SkipEntry{Kind: kind, ID: id, First: now}
+		sl.entries[key] = e
+	}
+	e.Reason = err.Error()
+	e.Last = now
+	if !sl.failed[key] {
+		sl.failed[key] = true
+		e.Failures++
+	}
+	return sl.save()
+}
+
+// Clear removes the item, that was fetched successfully.
+func (sl *SkipList) Clear(kind, id string) error {
+	if sl == nil {
+		return nil
+	}
+	_, err := sl.Remove(kind, id)
+	return err
+}
+
+// Remove removes the item from the list, and returns true, if it was on the
+// list.
+func (sl *SkipList) Remove(kind, id string) (bool, error) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	key := skipKey(kind, id)
+	if _, ok := sl.entries[key]; !ok {
+		return false, nil
+	}
+	delete(sl.entries, key)
+	return true, sl.save()
+}
+
+// Reset removes all items from the list.
+func (sl *SkipList) Reset() error {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.entries = make(map[string]*SkipEntry)
+	return sl.save()
+}
+
+// Entries returns the items of the list, sorted by the kind and the ID.
+func (sl *SkipList) Entries() []SkipEntry {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	ee := make([]SkipEntry, 0, len(sl.entries))
+	for _, e := range sl.entries {
+		ee = append(ee, *e)
+	}
+	sort.Slice(ee, func(i, j int) bool {
+		if ee[i].Kind != ee[j].Kind {
+			return ee[i].Kind < ee[j].Kind
+		}
+		return ee[i].ID < ee[j].ID
+	})
+	return ee
+}
+
+// save writes the list to the file, it must be called with the mutex held.
+func (sl *SkipList) save() error {
+	ee := make([]*SkipEntry, 0, len(sl.entries))
+	for _, e := range sl.entries {
+		ee = append(ee, e)
+	}
+	sort.Slice(ee, func(i, j int) bool { return ee[i].Key() < ee[j].Key() })
+	data, err := json.MarshalIndent(ee, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(sl.filename), 0700); err != nil {
+		return err
+	}
+	tmp := sl.filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, sl.filename)
+}
+
+// countable returns true, if the error err may be caused by the item itself,
+// and not by the run.
+func countable(err error) bool {
+	var (
+		rl *ErrRateLimited
+		na *ErrNoAccess
+	)
+	switch {
+	case err == nil,
+		errors.Is(err, context.Canceled),
+		errors.Is(err, ErrBudgetExceeded),
+		errors.Is(err, ErrCallBudgetExceeded),
+		errors.Is(err, ErrTokenExpired),
+		errors.Is(err, ErrSkipListed),
+		errors.As(err, &rl),
+		errors.As(err, &na):
+		return false
+	}
+	return true
+}
+
+// skipItem returns the skip-list kind and ID of the conversation or the
+// thread of the link sl.
+func skipItem(sl structures.SlackLink) (string, string) {
+	if sl.IsThread() {
+		return SkipThread, sl.Channel + ":" + sl.ThreadTS
+	}
+	return SkipConversation, sl.Channel
+}
+
+// skipListed returns the error for the item, skipped for the reason.
+func skipListed(kind, id, reason string) error {
+	return fmt.Errorf("%s %s is %w, the last error: %s", kind, id, ErrSkipListed, reason)
+}
+
+// SkipList returns the skip-list of the session, it is nil, if the
+// skip-list is disabled.
+func (sd *Session) SkipList() *SkipList {
+	return sd.options.SkipList
+}
+
+// skipFail records the failure of the item on the skip-list of the session.
+func (sd *Session) skipFail(ctx context.Context, kind, id string, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	if serr := sd.options.SkipList.Fail(kind, id, err); serr != nil {
+		sd.l().Printf("error saving the skip-list: %s", serr)
+	}
+}
+
+// skipClear removes the item, that was fetched successfully, from the
+// skip-list of the session.
+func (sd *Session) skipClear(kind, id string) {
+	if err := sd.options.SkipList.Clear(kind, id); err != nil {
+		sd.l().Printf("error saving the skip-list: %s", err)
+	}
+}
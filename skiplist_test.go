@@ -0,0 +1,137 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+var errServer = errors.New("slack server error: 500 Internal Server Error")
+
+func TestSkipList(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cache", SkipListFile)
+
+	sl, err := OpenSkipList(filename, 2)
+	require.NoError(t, err)
+	assert.Empty(t, sl.Entries())
+
+	// the retries within the run are counted once.
+	require.NoError(t, sl.Fail(SkipThread, "C01:1.000", errServer))
+	require.NoError(t, sl.Fail(SkipThread, "C01:1.000", errServer))
+	require.NoError(t, sl.Fail(SkipFile, "F01", errServer))
+	_, skipped := sl.Skipped(SkipThread, "C01:1.000")
+	assert.False(t, skipped, "the item failed only in one run")
+
+	// the next run.
+	sl, err = OpenSkipList(filename, 2)
+	require.NoError(t, err)
+	require.NoError(t, sl.Fail(SkipThread, "C01:1.000", errServer))
+	reason, skipped := sl.Skipped(SkipThread, "C01:1.000")
+	assert.True(t, skipped)
+	assert.Equal(t, errServer.Error(), reason)
+	_, skipped = sl.Skipped(SkipConversation, "C01")
+	assert.False(t, skipped, "the kinds are distinct")
+
+	require.NoError(t, sl.Clear(SkipFile, "F01"))
+	ee := sl.Entries()
+	require.Len(t, ee, 1)
+	assert.Equal(t, 2, ee[0].Failures)
+
+	// the changes are saved.
+	sl, err = OpenSkipList(filename, 2)
+	require.NoError(t, err)
+	assert.Equal(t, ee, sl.Entries())
+
+	require.NoError(t, sl.Reset())
+	assert.Empty(t, sl.Entries())
+}
+
+func TestSkipList_Fail_notCountable(t *testing.T) {
+	sl, err := OpenSkipList(filepath.Join(t.TempDir(), SkipListFile), 1)
+	require.NoError(t, err)
+	for _, err := range []error{
+		context.Canceled,
+		ErrBudgetExceeded,
+		ErrTokenExpired,
+		&ErrRateLimited{RetryAfter: time.Minute, Err: errServer},
+		&ErrNoAccess{Channel: "C01", Err: ErrNotInChannel},
+	} {
+		require.NoError(t, sl.Fail(SkipConversation, "C01", err))
+	}
+	assert.Empty(t, sl.Entries())
+}
+
+func TestSkipList_nil(t *testing.T) {
+	var sl *SkipList
+	_, skipped := sl.Skipped(SkipConversation, "C01")
+	assert.False(t, skipped)
+	assert.NoError(t, sl.Fail(SkipConversation, "C01", errServer))
+	assert.NoError(t, sl.Clear(SkipConversation, "C01"))
+}
+
+func TestSession_populateThreads_skipList(t *testing.T) {
+	threadID := "x:" + testMsg4t.ThreadTimestamp
+	l := network.NewLimiter(network.NoTier, 1, 0)
+	failFn := func(ctx context.Context, l *rate.Limiter, channelID, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error) {
+		return nil, errServer
+	}
+
+	sl, err := OpenSkipList(filepath.Join(t.TempDir(), SkipListFile), 1)
+	require.NoError(t, err)
+	sd := &Session{options: Options{SkipList: sl}}
+
+	msgs := []types.Message{testMsg1, testMsg4t}
+	_, err = sd.populateThreads(context.Background(), l, msgs, "x", time.Time{}, time.Time{}, failFn)
+	var te *threadError
+	require.ErrorAs(t, err, &te)
+	assert.ErrorIs(t, err, errServer)
+	_, skipped := sl.Skipped(SkipThread, threadID)
+	assert.True(t, skipped)
+
+	msgs = []types.Message{testMsg1, testMsg4t}
+	n, err := sd.populateThreads(context.Background(), l, msgs, "x", time.Time{}, time.Time{}, failFn)
+	require.NoError(t, err, "the thread must be skipped")
+	assert.Zero(t, n)
+}
+
+func TestSession_Dump_skipList(t *testing.T) {
+	sl, err := OpenSkipList(filepath.Join(t.TempDir(), SkipListFile), 1)
+	require.NoError(t, err)
+	require.NoError(t, sl.Fail(SkipConversation, "CHM82GF99", errServer))
+
+	opts := DefOptions
+	opts.SkipList = sl
+	sd := &Session{client: newmockClienter(gomock.NewController(t)), options: opts}
+	_, err = sd.DumpAll(context.Background(), "CHM82GF99")
+	assert.ErrorIs(t, err, ErrSkipListed, "the API must not be called")
+
+	t.Run("the successful dump removes the item", func(t *testing.T) {
+		mc := newmockClienter(gomock.NewController(t))
+		mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+			&slack.GetConversationHistoryResponse{
+				Messages:      []slack.Message{testMsg1.Message},
+				SlackResponse: slack.SlackResponse{Ok: true},
+			},
+			nil,
+		)
+		mockConvInfo(mc, "C01", "unittest")
+		require.NoError(t, sl.Fail(SkipConversation, "C01", errServer))
+		sl.After = 2 // not skipped yet.
+
+		sd := &Session{client: mc, options: opts}
+		_, err := sd.DumpAll(context.Background(), "C01")
+		require.NoError(t, err)
+		assert.Len(t, sl.Entries(), 1, "only CHM82GF99 is left")
+	})
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"runtime/trace"
+	"strings"
+	"sync"
 	"time"
 
 	"errors"
@@ -16,22 +20,42 @@ import (
 	"github.com/rusq/chttp"
 	"github.com/rusq/slackdump/v2/auth"
 	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/metrics"
 	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/otrace"
 	"github.com/rusq/slackdump/v2/internal/structures"
 	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/progress"
+	"github.com/rusq/slackdump/v2/transport"
 	"github.com/rusq/slackdump/v2/types"
 )
 
 //go:generate mockgen -destination internal/mocks/mock_os/mock_os.go os FileInfo
 //go:generate mockgen -destination internal/mocks/mock_downloader/mock_downloader.go github.com/rusq/slackdump/v2/downloader Downloader
-//go:generate sh -c "mockgen -source slackdump.go -destination clienter_mock_test.go -package slackdump -mock_names clienter=mockClienter,Reporter=mockReporter"
+//go:generate sh -c "mockgen -source slackdump.go -destination clienter_mock_test.go -package slackdump -mock_names Slacker=mockClienter,Reporter=mockReporter"
 //go:generate sed -i ~ -e "s/NewmockClienter/newmockClienter/g" -e "s/NewmockReporter/newmockReporter/g" clienter_mock_test.go
 
+// slackURL is the Slack URL, that the cookies are set for.
+const slackURL = "https://slack.com"
+
+// fileHosts are the hosts of the files, that are sent to the Options.FilesURL,
+// if it is set.
+var fileHosts = []string{"files.slack.com", "files.slack-gov.com"}
+
 // Session stores basic session parameters.
 type Session struct {
-	client clienter // Slack client
+	client Slacker // Slack client
+	// api calls the API methods that are not available in the slack
+	// library, it is nil if the session was created with NewWithClient.
+	api *apiClient
 
 	wspInfo *slack.AuthTestResponse // workspace info
+	// scopes are the OAuth scopes of the token, nil if unknown, see Scopes.
+	scopes []string
+	// skipped are the IDs of the files, that were not downloaded, because
+	// the token has no access to them.
+	skipped   map[string]bool
+	skippedMu sync.Mutex
 
 	fs fsadapter.FS // filesystem for saving attachments
 
@@ -40,20 +64,37 @@ type Session struct {
 	UserIndex structures.UserIndex `json:"-"`
 
 	options Options
+	// deadline is the time when the DeadlineBudget is spent, zero if there's
+	// no budget.
+	deadline time.Time
 }
 
-// clienter is the interface with some functions of slack.Client with the sole
-// purpose of mocking in tests (see client_mock.go)
-type clienter interface {
+// Slacker is the subset of the slack.Client methods, that are used by the
+// Session.  *slack.Client satisfies it.  Programs that embed slackdump can
+// use the fake client from the slacktest package, or their own
+// implementation, to test without connecting to Slack (see NewWithClient).
+type Slacker interface {
+	AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error)
 	GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error)
 	GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error)
 	GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error)
 	GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) (channels []slack.Channel, nextCursor string, err error)
 	GetFile(downloadURL string, writer io.Writer) error
+	GetFileInfoContext(ctx context.Context, fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error)
+	GetScheduledMessagesContext(ctx context.Context, params *slack.GetScheduledMessagesParameters) (channels []slack.ScheduledMessage, nextCursor string, err error)
 	GetTeamInfo() (*slack.TeamInfo, error)
+	GetTeamInfoContext(ctx context.Context) (*slack.TeamInfo, error)
+	GetTeamProfileContext(ctx context.Context) (*slack.TeamProfile, error)
+	GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
+	GetUserInfoContext(ctx context.Context, user string) (*slack.User, error)
+	GetUserProfileContext(ctx context.Context, params *slack.GetUserProfileParameters) (*slack.UserProfile, error)
 	GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error)
 	GetEmojiContext(ctx context.Context) (map[string]string, error)
 	GetUsersInConversationContext(ctx context.Context, params *slack.GetUsersInConversationParameters) ([]string, string, error)
+	GetReactionsContext(ctx context.Context, item slack.ItemRef, params slack.GetReactionsParameters) ([]slack.ItemReaction, error)
+	ListStarsContext(ctx context.Context, params slack.StarsParameters) ([]slack.Item, *slack.Paging, error)
+	SearchMessagesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error)
+	SearchFilesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchFiles, error)
 }
 
 var (
@@ -88,16 +129,135 @@ func NewWithOptions(ctx context.Context, authProvider auth.Provider, opts Option
 		return nil, err
 	}
 
-	httpCl, err := chttp.New("https://slack.com", authProvider.Cookies())
+	rt, err := opts.roundTripper()
+	if err != nil {
+		return nil, err
+	}
+	if opts.TLS.Insecure {
+		lg := opts.Logger
+		if lg == nil {
+			lg = logger.Default
+		}
+		lg.Printf("WARNING: the TLS certificate verification is DISABLED, the token, the cookies and the messages can be intercepted by anyone on the network path to Slack")
+	}
+	base, err := url.Parse(opts.baseURL())
+	if err != nil {
+		return nil, fmt.Errorf("invalid API base URL: %w", err)
+	}
+	cookies := authProvider.Cookies()
+	if opts.BaseURL != "" {
+		cookies = rebaseCookies(cookies, base.Hostname())
+	}
+	httpCl, err := chttp.NewWithTransport(base.String(), cookies, chttp.NewTransport(rt))
 	if err != nil {
 		return nil, err
 	}
+	if opts.CookieJar != nil {
+		opts.CookieJar.SetCookies(base, cookies)
+		httpCl.Jar = opts.CookieJar
+	}
+	// metrics and the API call accounting go last, so that the calls served by
+	// the middleware, i.e. from the cache, are not counted.  The headers are
+	// set after the user middleware, so that they override the headers set by
+	// it.
+	mw := opts.Middleware
+	if h := opts.header(); h != nil {
+		mw = append(append([]transport.Middleware{}, mw...), transport.Header(h))
+	}
+	if opts.FilesURL != "" {
+		target, err := url.Parse(opts.FilesURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid files URL: %w", err)
+		}
+		mw = append(append([]transport.Middleware{}, mw...), transport.Rehost(target, fileHosts...))
+	}
+	// the scopes are recorded from the auth.test call of newSession, the
+	// recorder goes first, as the user middleware may not call the next one.
+	var sr scopeRecorder
+	mw = append([]transport.Middleware{sr.middleware()}, mw...)
+	if opts.APIUsage == nil {
+		opts.APIUsage = NewAPIUsage(0)
+	}
+	// the base URL may have the path, i.e. the path-prefixed proxy.
+	apiPrefix := network.APIPrefix(opts.baseURL())
+	httpCl.Transport = transport.Chain(otrace.NewTransport(metrics.NewTransport(opts.APIUsage.transport(httpCl.Transport, apiPrefix), apiPrefix), apiPrefix), mw...)
+
+	cl := slack.New(authProvider.SlackToken(), slack.OptionHTTPClient(httpCl), slack.OptionAPIURL(opts.baseURL()+"/api/"))
+
+	var (
+		sl         Slacker = cl
+		enterprise string  // name of the organisation of the discovery backend.
+	)
+	if opts.Backend == BackendDiscovery {
+		if len(opts.Tokens) > 0 {
+			return nil, errors.New("the token pool is not supported with the discovery backend")
+		}
+		if sl, enterprise, err = newDiscoveryClient(ctx, cl, &apiClient{cl: httpCl, token: authProvider.SlackToken(), baseURL: opts.BaseURL}); err != nil {
+			return nil, err
+		}
+	}
 
-	cl := slack.New(authProvider.SlackToken(), slack.OptionHTTPClient(httpCl))
+	sd, err := newSession(ctx, sl, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Backend == BackendDiscovery {
+		sd.l().Printf("> using the Discovery API of %q", enterprise)
+	}
+	sd.api = &apiClient{cl: httpCl, token: authProvider.SlackToken(), baseURL: sd.wspInfo.URL}
+	if opts.BaseURL != "" {
+		// the workspace URL might not be reachable through the proxy.
+		sd.api.baseURL = opts.baseURL()
+	}
+	sd.scopes = sr.scopes()
+	if len(opts.Tokens) > 0 {
+		pool, err := newClientPool(ctx, cl, sd.wspInfo.TeamID, opts.Tokens, func(token string) Slacker {
+			return slack.New(token, slack.OptionHTTPClient(httpCl), slack.OptionAPIURL(opts.baseURL()+"/api/"))
+		}, sd.limiter)
+		if err != nil {
+			return nil, err
+		}
+		sd.client = pool
+		sd.l().Printf("> the API calls are rotated across %d tokens", pool.size())
+	}
+	if sd.options.DumpFiles && !sd.CanReadFiles() {
+		sd.l().Printf("warning: the token has no %s scope, only the metadata of the files will be saved", scopeFilesRead)
+		sd.options.DumpFiles = false
+	}
+	return sd, nil
+}
 
+// rebaseCookies returns the copies of the Slack cookies, that are set for the
+// domain of the host instead, so that they are sent to the custom API base
+// URL.
+func rebaseCookies(cookies []*http.Cookie, host string) []*http.Cookie {
+	res := make([]*http.Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		cc := *c
+		if cc.Domain == "" || strings.HasSuffix(cc.Domain, "slack.com") {
+			cc.Domain = host
+		}
+		res = append(res, &cc)
+	}
+	return res
+}
+
+// NewWithClient creates new Session that uses the client cl, instead of
+// connecting to Slack with the auth provider, and populates the internal cache
+// of users.  It is intended for testing the programs that embed slackdump,
+// see the slacktest package.
+func NewWithClient(ctx context.Context, cl Slacker, opts ...Option) (*Session, error) {
+	options := DefOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return newSession(ctx, cl, options)
+}
+
+func newSession(ctx context.Context, cl Slacker, opts Options) (*Session, error) {
 	authTestResp, err := cl.AuthTestContext(ctx)
 	if err != nil {
-		return nil, &AuthError{Err: err}
+		return nil, &AuthError{Err: network.Classify("", err)}
 	}
 
 	sd := &Session{
@@ -106,6 +266,12 @@ func NewWithOptions(ctx context.Context, authProvider auth.Provider, opts Option
 		wspInfo: authTestResp,
 		fs:      fsadapter.NewDirectory("."), // default is to save attachments to the current directory.
 	}
+	if opts.DeadlineBudget > 0 {
+		sd.deadline = time.Now().Add(opts.DeadlineBudget)
+	}
+	if sd.options.APIUsage == nil {
+		sd.options.APIUsage = NewAPIUsage(0)
+	}
 
 	network.SetLogger(sd.l())
 
@@ -131,7 +297,7 @@ func TestAuth(ctx context.Context, provider auth.Provider) error {
 	ctx, task := trace.NewTask(ctx, "TestAuth")
 	defer task.End()
 
-	httpCl, err := chttp.New("https://slack.com", provider.Cookies())
+	httpCl, err := chttp.New(slackURL, provider.Cookies())
 	if err != nil {
 		return err
 	}
@@ -141,14 +307,32 @@ func TestAuth(ctx context.Context, provider auth.Provider) error {
 	region := trace.StartRegion(ctx, "AuthTestContext")
 	defer region.End()
 	if _, err := cl.AuthTestContext(ctx); err != nil {
-		return &AuthError{Err: err}
+		return &AuthError{Err: network.Classify("", err)}
 	}
 	return nil
 }
 
-// Client returns the underlying slack.Client.
+// Client returns the underlying slack.Client.  It returns nil, if the
+// Session was created with NewWithClient with some other client, use API in
+// this case.
 func (sd *Session) Client() *slack.Client {
-	return sd.client.(*slack.Client)
+	c := sd.client
+	if p, ok := c.(*clientPool); ok {
+		c = p.clients[0]
+	}
+	cl, _ := c.(*slack.Client)
+	return cl
+}
+
+// API returns the Slack client of the session.
+func (sd *Session) API() Slacker {
+	return sd.client
+}
+
+// UserAgent returns the User-Agent of the API requests, it is empty if the
+// net/http default is used.
+func (sd *Session) UserAgent() string {
+	return sd.options.UserAgent
 }
 
 // Me returns the current authenticated user in a rather dirty manner.
@@ -164,6 +348,15 @@ func (sd *Session) CurrentUserID() string {
 	return sd.wspInfo.UserID
 }
 
+// APIUsage returns the report of the API calls made by the session, see
+// WithAPIUsage.
+func (sd *Session) APIUsage() UsageReport {
+	if sd.options.APIUsage == nil {
+		return UsageReport{}
+	}
+	return sd.options.APIUsage.Report()
+}
+
 // SetFS sets the filesystem to save attachments to (slackdump defaults to the
 // current directory otherwise).
 func (sd *Session) SetFS(fs fsadapter.FS) {
@@ -209,3 +402,11 @@ func (sd *Session) l() logger.Interface {
 	}
 	return sd.options.Logger
 }
+
+// pr returns the progress reporter.
+func (sd *Session) pr() progress.Reporter {
+	if sd.options.Progress == nil {
+		return progress.NewLog(sd.l())
+	}
+	return sd.options.Progress
+}
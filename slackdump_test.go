@@ -2,9 +2,14 @@ package slackdump
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -18,6 +23,7 @@ import (
 	"github.com/rusq/slackdump/v2/internal/network"
 	"github.com/rusq/slackdump/v2/internal/structures"
 	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/transport"
 	"github.com/rusq/slackdump/v2/types"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
@@ -224,7 +230,7 @@ func ExampleNew_browserAuth() {
 
 func TestSession_Me(t *testing.T) {
 	type fields struct {
-		client    clienter
+		client    Slacker
 		fs        fsadapter.FS
 		wspInfo   *slack.AuthTestResponse
 		Users     types.Users
@@ -284,7 +290,7 @@ func TestSession_Me(t *testing.T) {
 func TestSession_l(t *testing.T) {
 	testLg := dlog.New(os.Stderr, "TEST", log.LstdFlags, false)
 	type fields struct {
-		client    clienter
+		client    Slacker
 		wspInfo   *slack.AuthTestResponse
 		fs        fsadapter.FS
 		Users     types.Users
@@ -327,3 +333,101 @@ func TestSession_l(t *testing.T) {
 		})
 	}
 }
+
+func TestNewWithClient(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		mc := newmockClienter(gomock.NewController(t))
+		mc.EXPECT().AuthTestContext(gomock.Any()).Return(&slack.AuthTestResponse{TeamID: "T01", UserID: "U01"}, nil)
+		mc.EXPECT().GetUsersContext(gomock.Any()).Return([]slack.User{{ID: "U01"}}, nil)
+
+		sd, err := NewWithClient(context.Background(), mc, CacheDir(t.TempDir()), WithLogger(logger.Silent))
+		assert.NoError(t, err)
+		assert.Equal(t, "U01", sd.CurrentUserID())
+		assert.Len(t, sd.Users, 1)
+		assert.Nil(t, sd.Client(), "not a slack.Client")
+		assert.Equal(t, mc, sd.API())
+	})
+	t.Run("auth error", func(t *testing.T) {
+		mc := newmockClienter(gomock.NewController(t))
+		mc.EXPECT().AuthTestContext(gomock.Any()).Return(nil, errors.New("invalid_auth"))
+
+		_, err := NewWithClient(context.Background(), mc)
+		var aerr *AuthError
+		assert.ErrorAs(t, err, &aerr)
+	})
+}
+
+func TestOptions_header(t *testing.T) {
+	opts := DefOptions
+	assert.Nil(t, opts.header(), "no headers by default")
+
+	for _, opt := range []Option{WithUserAgent("corp-agent/1.0"), WithHeader("X-Proxy-Token", "abc"), WithHeader("X-Proxy-Token", "def")} {
+		opt(&opts)
+	}
+	h := opts.header()
+	assert.Equal(t, "corp-agent/1.0", h.Get("User-Agent"))
+	assert.Equal(t, []string{"abc", "def"}, h.Values("X-Proxy-Token"))
+	assert.Empty(t, opts.Headers.Get("User-Agent"), "options headers are not modified")
+	assert.Nil(t, DefOptions.Headers, "default options are not modified")
+}
+
+func TestNewWithOptions_baseURL(t *testing.T) {
+	var (
+		paths  []string
+		cookie string
+	)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if c, err := r.Cookie("d"); err == nil {
+			cookie = c.Value
+		}
+		switch r.URL.Path {
+		case "/api/auth.test":
+			fmt.Fprint(w, `{"ok":true,"url":"https://test.slack-gov.com/","team_id":"T01","user_id":"U01"}`)
+		case "/api/users.list":
+			fmt.Fprint(w, `{"ok":true,"members":[{"id":"U01","name":"alice"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	prov, err := auth.NewValueAuth("xoxc-test", "cookie-value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefOptions
+	opts.CacheDir = t.TempDir()
+	WithBaseURL(srv.URL+"/", "")(&opts)
+	// the cookies are secure, the test server certificate is trusted by
+	// its client.
+	WithMiddleware(func(http.RoundTripper) http.RoundTripper { return srv.Client().Transport })(&opts)
+	sd, err := NewWithOptions(context.Background(), prov, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"/api/auth.test", "/api/users.list"}, paths)
+	assert.Equal(t, "cookie-value", cookie, "cookies are sent to the base URL")
+	assert.Equal(t, srv.URL, sd.api.baseURL)
+}
+
+func TestOptions_roundTripper(t *testing.T) {
+	opts := DefOptions
+	rt, err := opts.roundTripper()
+	assert.NoError(t, err)
+	assert.Nil(t, rt, "default transport")
+
+	WithDNS("10.0.0.53")(&opts)
+	WithTLS(transport.TLSConfig{Insecure: true})(&opts)
+	rt, err = opts.roundTripper()
+	if assert.NoError(t, err) {
+		tr := rt.(*http.Transport)
+		assert.NotNil(t, tr.DialContext)
+		assert.True(t, tr.TLSClientConfig.InsecureSkipVerify)
+	}
+	assert.NotSame(t, http.DefaultTransport, rt, "default transport is not modified")
+
+	WithTLS(transport.TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")})(&opts)
+	_, err = opts.roundTripper()
+	assert.Error(t, err)
+}
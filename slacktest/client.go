@@ -0,0 +1,449 @@
+// Package slacktest provides the fake Slack client with the canned workspace
+// data, so that the programs that embed slackdump can be tested without
+// connecting to Slack:
+//
+//	cl := slacktest.New()
+//	sd, err := slackdump.NewWithClient(ctx, cl, slackdump.CacheDir(t.TempDir()))
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	conv, err := sd.DumpAll(ctx, slacktest.ChannelGeneral)
+//
+// The fields of the Client can be modified to set up the workspace for the
+// test.
+package slacktest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2"
+)
+
+var _ slackdump.Slacker = (*Client)(nil)
+
+// defLimit is the page size, if the request doesn't set the limit.
+const defLimit = 100
+
+// Client is the fake Slack client, that serves the workspace data from its
+// fields.  Conversation history and replies are paginated according to the
+// request limit, like the real API does.  It is safe for concurrent use, the
+// fields must not be modified while it is in use.
+type Client struct {
+	Auth     slack.AuthTestResponse
+	Team     slack.TeamInfo
+	Users    []slack.User
+	Channels []slack.Channel
+	// Messages is the channel history, keyed by channel ID, newest first.
+	Messages map[string][]slack.Message
+	// Replies are the thread messages, keyed by "channelID:threadTS", the
+	// first message is the thread parent.
+	Replies map[string][]slack.Message
+	// Members is the channel members, keyed by channel ID.
+	Members map[string][]string
+	// Files is the file contents, keyed by the download URL.
+	Files map[string][]byte
+	Emoji map[string]string
+	// UserGroups and Profile are the workspace metadata.
+	UserGroups []slack.UserGroup
+	Profile    slack.TeamProfile
+	// Scheduled is the scheduled messages of the current user.
+	Scheduled []slack.ScheduledMessage
+	// Reactions is the complete reactions of the messages, keyed by
+	// "channelID:ts", the reactions in Messages and Replies may be truncated.
+	Reactions map[string][]slack.ItemReaction
+	// ProfileFields is the custom profile fields of the users, keyed by
+	// user ID, they are only returned by the users.profile.get.
+	ProfileFields map[string]map[string]slack.UserProfileCustomField
+	// Stars is the items, saved by the current user.
+	Stars []slack.Item
+	// FileComments is the legacy comments of the files, keyed by file ID.
+	FileComments map[string][]slack.Comment
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// errNotFound is returned for the unknown channels, it is the same error as
+// the Slack API returns.
+var errNotFound = slack.SlackErrorResponse{Err: "channel_not_found"}
+
+// Calls returns the number of calls of the API method, i.e.
+// "conversations.history".
+func (c *Client) Calls(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+func (c *Client) called(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.calls == nil {
+		c.calls = make(map[string]int)
+	}
+	c.calls[method]++
+}
+
+func (c *Client) AuthTestContext(context.Context) (*slack.AuthTestResponse, error) {
+	c.called("auth.test")
+	resp := c.Auth
+	return &resp, nil
+}
+
+func (c *Client) GetConversationInfoContext(_ context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	c.called("conversations.info")
+	for i := range c.Channels {
+		if c.Channels[i].ID == input.ChannelID {
+			ch := c.Channels[i]
+			return &ch, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+func (c *Client) GetConversationHistoryContext(_ context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	c.called("conversations.history")
+	msgs, ok := c.Messages[params.ChannelID]
+	if !ok {
+		return nil, errNotFound
+	}
+	msgs = between(msgs, params.Oldest, params.Latest)
+	page, next, err := paginate(len(msgs), params.Cursor, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &slack.GetConversationHistoryResponse{
+		SlackResponse: slack.SlackResponse{Ok: true},
+		HasMore:       next != "",
+		Messages:      msgs[page.from:page.to],
+	}
+	resp.ResponseMetaData.NextCursor = next
+	return resp, nil
+}
+
+func (c *Client) GetConversationRepliesContext(_ context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	c.called("conversations.replies")
+	msgs, ok := c.Replies[params.ChannelID+":"+params.Timestamp]
+	if !ok || len(msgs) == 0 {
+		return nil, false, "", slack.SlackErrorResponse{Err: "thread_not_found"}
+	}
+	parent, replies := msgs[0], between(msgs[1:], params.Oldest, params.Latest)
+	page, next, err := paginate(len(replies), params.Cursor, params.Limit)
+	if err != nil {
+		return nil, false, "", err
+	}
+	// the API returns the parent message with each page.
+	res := append([]slack.Message{parent}, replies[page.from:page.to]...)
+	return res, next != "", next, nil
+}
+
+func (c *Client) GetConversationsContext(_ context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	c.called("conversations.list")
+	var chans []slack.Channel
+	for _, ch := range c.Channels {
+		if len(params.Types) == 0 || contains(params.Types, chanType(ch)) {
+			chans = append(chans, ch)
+		}
+	}
+	page, next, err := paginate(len(chans), params.Cursor, params.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+	return chans[page.from:page.to], next, nil
+}
+
+func (c *Client) GetFile(downloadURL string, w io.Writer) error {
+	c.called("files.download")
+	data, ok := c.Files[downloadURL]
+	if !ok {
+		return fmt.Errorf("file not found: %s", downloadURL)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func (c *Client) GetFileInfoContext(_ context.Context, fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error) {
+	c.called("files.info")
+	comments, ok := c.FileComments[fileID]
+	if !ok {
+		return nil, nil, nil, slack.SlackErrorResponse{Err: "file_not_found"}
+	}
+	if count <= 0 {
+		count = defLimit
+	}
+	if page <= 0 {
+		page = 1
+	}
+	paging := &slack.Paging{Count: count, Total: len(comments), Page: page, Pages: (len(comments) + count - 1) / count}
+	start := (page - 1) * count
+	if start > len(comments) {
+		start = len(comments)
+	}
+	end := start + count
+	if end > len(comments) {
+		end = len(comments)
+	}
+	return &slack.File{ID: fileID, CommentsCount: len(comments)}, append([]slack.Comment(nil), comments[start:end]...), paging, nil
+}
+
+func (c *Client) GetReactionsContext(_ context.Context, item slack.ItemRef, _ slack.GetReactionsParameters) ([]slack.ItemReaction, error) {
+	c.called("reactions.get")
+	reactions, ok := c.Reactions[item.Channel+":"+item.Timestamp]
+	if !ok {
+		return nil, slack.SlackErrorResponse{Err: "message_not_found"}
+	}
+	return append([]slack.ItemReaction(nil), reactions...), nil
+}
+
+func (c *Client) GetScheduledMessagesContext(_ context.Context, params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error) {
+	c.called("chat.scheduledMessages.list")
+	var msgs []slack.ScheduledMessage
+	for _, m := range c.Scheduled {
+		if params.Channel == "" || m.Channel == params.Channel {
+			msgs = append(msgs, m)
+		}
+	}
+	page, next, err := paginate(len(msgs), params.Cursor, params.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+	return msgs[page.from:page.to], next, nil
+}
+
+func (c *Client) GetTeamInfo() (*slack.TeamInfo, error) {
+	c.called("team.info")
+	team := c.Team
+	return &team, nil
+}
+
+func (c *Client) GetTeamInfoContext(context.Context) (*slack.TeamInfo, error) {
+	return c.GetTeamInfo()
+}
+
+func (c *Client) GetTeamProfileContext(context.Context) (*slack.TeamProfile, error) {
+	c.called("team.profile.get")
+	profile := c.Profile
+	return &profile, nil
+}
+
+func (c *Client) GetUserGroupsContext(context.Context, ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	c.called("usergroups.list")
+	return append([]slack.UserGroup(nil), c.UserGroups...), nil
+}
+
+func (c *Client) GetUserInfoContext(_ context.Context, id string) (*slack.User, error) {
+	c.called("users.info")
+	for _, u := range c.Users {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+	return nil, slack.SlackErrorResponse{Err: "user_not_found"}
+}
+
+func (c *Client) GetUserProfileContext(_ context.Context, params *slack.GetUserProfileParameters) (*slack.UserProfile, error) {
+	c.called("users.profile.get")
+	for _, u := range c.Users {
+		if u.ID != params.UserID {
+			continue
+		}
+		profile := u.Profile
+		if fields, ok := c.ProfileFields[u.ID]; ok {
+			m := make(map[string]slack.UserProfileCustomField, len(fields))
+			for id, f := range fields {
+				if !params.IncludeLabels {
+					f.Label = ""
+				}
+				m[id] = f
+			}
+			profile.SetFieldsMap(m)
+		}
+		return &profile, nil
+	}
+	return nil, slack.SlackErrorResponse{Err: "user_not_found"}
+}
+
+func (c *Client) GetUsersContext(context.Context, ...slack.GetUsersOption) ([]slack.User, error) {
+	c.called("users.list")
+	return append([]slack.User(nil), c.Users...), nil
+}
+
+func (c *Client) GetEmojiContext(context.Context) (map[string]string, error) {
+	c.called("emoji.list")
+	emoji := make(map[string]string, len(c.Emoji))
+	for k, v := range c.Emoji {
+		emoji[k] = v
+	}
+	return emoji, nil
+}
+
+func (c *Client) GetUsersInConversationContext(_ context.Context, params *slack.GetUsersInConversationParameters) ([]string, string, error) {
+	c.called("conversations.members")
+	members, ok := c.Members[params.ChannelID]
+	if !ok {
+		return nil, "", errNotFound
+	}
+	page, next, err := paginate(len(members), params.Cursor, params.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+	return members[page.from:page.to], next, nil
+}
+
+type span struct{ from, to int }
+
+func (c *Client) ListStarsContext(_ context.Context, params slack.StarsParameters) ([]slack.Item, *slack.Paging, error) {
+	c.called("stars.list")
+	page, paging := pageOf(len(c.Stars), params.Count, params.Page)
+	items := append([]slack.Item(nil), c.Stars[page.from:page.to]...)
+	return items, &paging, nil
+}
+
+// SearchMessagesContext returns the channel messages and the thread replies,
+// that contain the query text, the search modifiers are not supported.
+func (c *Client) SearchMessagesContext(_ context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error) {
+	c.called("search.messages")
+	var matches []slack.SearchMessage
+	match := func(chID string, msgs []slack.Message) {
+		for _, m := range msgs {
+			if !strings.Contains(strings.ToLower(m.Text), strings.ToLower(query)) {
+				continue
+			}
+			sm := slack.SearchMessage{Type: m.Type, User: m.User, Username: m.Username, Timestamp: m.Timestamp, Text: m.Text}
+			sm.Channel.ID = chID
+			for _, ch := range c.Channels {
+				if ch.ID == chID {
+					sm.Channel.Name = ch.Name
+				}
+			}
+			matches = append(matches, sm)
+		}
+	}
+	for chID, msgs := range c.Messages {
+		match(chID, msgs)
+	}
+	for key, msgs := range c.Replies {
+		chID, _, _ := strings.Cut(key, ":")
+		// the parent is in the channel messages.
+		match(chID, msgs[1:])
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Timestamp < matches[j].Timestamp })
+	page, paging := pageOf(len(matches), params.Count, params.Page)
+	return &slack.SearchMessages{Matches: matches[page.from:page.to], Paging: paging, Total: len(matches)}, nil
+}
+
+// SearchFilesContext returns the files of the messages, which names or
+// titles contain the query text.
+func (c *Client) SearchFilesContext(_ context.Context, query string, params slack.SearchParameters) (*slack.SearchFiles, error) {
+	c.called("search.files")
+	var matches []slack.File
+	for _, msgs := range c.Messages {
+		for _, m := range msgs {
+			for _, f := range m.Files {
+				if strings.Contains(strings.ToLower(f.Name+" "+f.Title), strings.ToLower(query)) {
+					matches = append(matches, f)
+				}
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	page, paging := pageOf(len(matches), params.Count, params.Page)
+	return &slack.SearchFiles{Matches: matches[page.from:page.to], Paging: paging, Total: len(matches)}, nil
+}
+
+// pageOf returns the page of n items for the page number and the page size
+// count, that are paginated by the page numbers, and the paging of the
+// response.
+func pageOf(n int, count int, page int) (span, slack.Paging) {
+	if count <= 0 {
+		count = defLimit
+	}
+	if page <= 0 {
+		page = 1
+	}
+	pages := (n + count - 1) / count
+	if pages == 0 {
+		pages = 1
+	}
+	from, to := (page-1)*count, page*count
+	if from > n {
+		from = n
+	}
+	if to > n {
+		to = n
+	}
+	return span{from, to}, slack.Paging{Count: count, Total: n, Page: page, Pages: pages}
+}
+
+// paginate returns the page of n items for the cursor, which is the offset
+// of the page, and the cursor of the next page, or an empty string for the
+// last page.
+func paginate(n int, cursor string, limit int) (span, string, error) {
+	if limit <= 0 {
+		limit = defLimit
+	}
+	var from int
+	if cursor != "" {
+		var err error
+		if from, err = strconv.Atoi(cursor); err != nil || from < 0 || from > n {
+			return span{}, "", slack.SlackErrorResponse{Err: "invalid_cursor"}
+		}
+	}
+	to := from + limit
+	if to >= n {
+		return span{from, n}, "", nil
+	}
+	return span{from, to}, strconv.Itoa(to), nil
+}
+
+// between returns the messages within the oldest and latest timestamps,
+// inclusive.  Empty timestamp means no limit.
+func between(msgs []slack.Message, oldest, latest string) []slack.Message {
+	if oldest == "" && latest == "" {
+		return msgs
+	}
+	lo, hi := parseTS(oldest), parseTS(latest)
+	var res []slack.Message
+	for _, m := range msgs {
+		ts := parseTS(m.Timestamp)
+		if (oldest == "" || lo <= ts) && (latest == "" || ts <= hi) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+func parseTS(ts string) float64 {
+	f, _ := strconv.ParseFloat(ts, 64)
+	return f
+}
+
+func chanType(ch slack.Channel) string {
+	switch {
+	case ch.IsIM:
+		return "im"
+	case ch.IsMpIM:
+		return "mpim"
+	case ch.IsPrivate:
+		return "private_channel"
+	default:
+		return "public_channel"
+	}
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
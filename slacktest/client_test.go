@@ -0,0 +1,230 @@
+package slacktest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/slacktest"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func newSession(t *testing.T, cl *slacktest.Client, opts ...slackdump.Option) *slackdump.Session {
+	t.Helper()
+	opts = append([]slackdump.Option{slackdump.CacheDir(t.TempDir()), slackdump.WithLogger(logger.Silent)}, opts...)
+	sd, err := slackdump.NewWithClient(context.Background(), cl, opts...)
+	require.NoError(t, err)
+	return sd
+}
+
+func TestClient_session(t *testing.T) {
+	t.Run("dump with pagination", func(t *testing.T) {
+		cl := slacktest.New()
+		sd := newSession(t, cl, func(o *slackdump.Options) {
+			o.ConversationsPerReq = 1
+			o.RepliesPerReq = 1
+		})
+		assert.Equal(t, slacktest.UserAlice, sd.CurrentUserID())
+		assert.Len(t, sd.Users, 2)
+
+		conv, err := sd.DumpAll(context.Background(), slacktest.ChannelGeneral)
+		require.NoError(t, err)
+		assert.Equal(t, "general", conv.Name)
+		require.Len(t, conv.Messages, 3)
+		assert.Equal(t, []string{"hello, world", "who's up for lunch?", "see the attached file"},
+			[]string{conv.Messages[0].Text, conv.Messages[1].Text, conv.Messages[2].Text}, "messages are sorted")
+		require.Len(t, conv.Messages[1].ThreadReplies, 2)
+		assert.Equal(t, "great, in 5 minutes", conv.Messages[1].ThreadReplies[1].Text)
+		assert.Equal(t, 3, cl.Calls("conversations.history"))
+		assert.Equal(t, 2, cl.Calls("conversations.replies"))
+	})
+	t.Run("time window", func(t *testing.T) {
+		sd := newSession(t, slacktest.New())
+		conv, err := sd.Dump(context.Background(), slacktest.ChannelGeneral, time.Unix(1672531300, 0), time.Time{})
+		require.NoError(t, err)
+		require.Len(t, conv.Messages, 1)
+		assert.Equal(t, "see the attached file", conv.Messages[0].Text)
+	})
+	t.Run("reactions", func(t *testing.T) {
+		cl := slacktest.New()
+		sd := newSession(t, cl, slackdump.WithFullReactions(2))
+		conv, err := sd.DumpAll(context.Background(), slacktest.ChannelGeneral)
+		require.NoError(t, err)
+		require.NotEmpty(t, conv.Messages)
+		first := conv.Messages[0]
+		require.Len(t, first.Reactions, 1)
+		assert.ElementsMatch(t, []string{slacktest.UserAlice, slacktest.UserBob}, first.Reactions[0].Users)
+		assert.Equal(t, 1, cl.Calls("reactions.get"))
+	})
+	t.Run("permalinks", func(t *testing.T) {
+		sd := newSession(t, slacktest.New(), slackdump.WithPermalinks(true))
+		conv, err := sd.DumpAll(context.Background(), slacktest.ChannelGeneral)
+		require.NoError(t, err)
+		require.Len(t, conv.Messages, 3)
+		parent := conv.Messages[1]
+		assert.Equal(t, "https://test.slack.com/archives/C0GENERAL/p1672531260000100", parent.Permalink)
+		require.Len(t, parent.ThreadReplies, 2)
+		assert.Equal(t, "https://test.slack.com/archives/C0GENERAL/p"+strings.Replace(parent.ThreadReplies[1].Timestamp, ".", "", 1)+"?thread_ts=1672531260.000100&cid=C0GENERAL", parent.ThreadReplies[1].Permalink)
+
+		sd = newSession(t, slacktest.New())
+		conv, err = sd.DumpAll(context.Background(), slacktest.ChannelGeneral)
+		require.NoError(t, err)
+		assert.Empty(t, conv.Messages[0].Permalink, "disabled by default")
+	})
+	t.Run("user profiles", func(t *testing.T) {
+		cl := slacktest.New()
+		sd := newSession(t, cl)
+		up, err := sd.GetUserProfiles(context.Background())
+		require.NoError(t, err)
+		require.Len(t, up.Fields, 1)
+		byID := up.Users.IndexByID()
+		require.Contains(t, byID, slacktest.UserAlice)
+		fields := byID[slacktest.UserAlice].Profile.FieldsMap()
+		assert.Equal(t, slack.UserProfileCustomField{Value: "Engineer", Label: "Title"}, fields["Xf0TITLE"], "label is set from the team profile")
+		assert.Equal(t, len(cl.Users), cl.Calls("users.profile.get"))
+	})
+	t.Run("personal", func(t *testing.T) {
+		sd := newSession(t, slacktest.New())
+		p, err := sd.Personal(context.Background())
+		require.NoError(t, err)
+		require.Len(t, p.Scheduled, 1)
+		assert.Equal(t, slacktest.ChannelGeneral, p.Scheduled[0].Channel)
+		assert.Contains(t, p.Skipped, "drafts", "drafts are not supported by the fake client")
+		require.Len(t, p.Saved, 2)
+		require.NotNil(t, p.Saved[0].Parent, "thread reply must have the parent")
+		assert.Equal(t, slacktest.ThreadTS, p.Saved[0].Parent.Timestamp)
+		assert.Nil(t, p.Saved[1].Parent)
+	})
+	t.Run("channels", func(t *testing.T) {
+		sd := newSession(t, slacktest.New())
+		chans, err := sd.GetChannels(context.Background(), "public_channel")
+		require.NoError(t, err)
+		assert.Len(t, chans, 2)
+		chans, err = sd.GetChannels(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, chans, 4)
+	})
+	t.Run("unknown channel", func(t *testing.T) {
+		sd := newSession(t, slacktest.New())
+		_, err := sd.DumpAll(context.Background(), "C0UNKNOWN")
+		var serr slack.SlackErrorResponse
+		require.ErrorAs(t, err, &serr)
+		assert.Equal(t, "channel_not_found", serr.Err)
+	})
+	t.Run("archive with files", func(t *testing.T) {
+		sd := newSession(t, slacktest.New(), slackdump.DownloadFiles(true))
+		dir := t.TempDir()
+		res, err := sd.Archive(context.Background(), dir, time.Time{}, time.Time{}, slacktest.ChannelGeneral, "C0UNKNOWN")
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(dir, slacktest.ChannelGeneral+".json"))
+		data, err := os.ReadFile(filepath.Join(dir, slacktest.ChannelGeneral, slacktest.FileID+"-hello.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, slacktest.FileData, data)
+
+		require.Len(t, res.Channels, 2)
+		assert.Equal(t, "general", res.Channels[0].Name)
+		assert.Equal(t, 1, res.Channels[0].Threads)
+		assert.Equal(t, 1, res.Channels[0].Files)
+		assert.Empty(t, res.Channels[0].Skipped)
+		assert.Contains(t, res.Channels[1].Skipped, "channel_not_found", "inaccessible channel is skipped")
+		assert.Equal(t, slackdump.FileStats{Downloaded: 1, Bytes: int64(len(slacktest.FileData))}, res.Files)
+
+		data, err = os.ReadFile(filepath.Join(dir, slackdump.WorkspaceFile))
+		require.NoError(t, err)
+		var ws types.Workspace
+		require.NoError(t, json.Unmarshal(data, &ws))
+		assert.Equal(t, slacktest.TeamID, ws.Team.ID)
+		assert.Len(t, ws.Emoji, 2)
+		if assert.Len(t, ws.UserGroups, 1) {
+			assert.Equal(t, slacktest.UserGroupEveryone, ws.UserGroups[0].ID)
+		}
+	})
+	t.Run("archive with embedded files", func(t *testing.T) {
+		sd := newSession(t, slacktest.New(), slackdump.DownloadFiles(true), slackdump.WithEmbedFiles(1024))
+		dir := t.TempDir()
+		res, err := sd.Archive(context.Background(), dir, time.Time{}, time.Time{}, slacktest.ChannelGeneral)
+		require.NoError(t, err)
+		assert.NoDirExists(t, filepath.Join(dir, slacktest.ChannelGeneral), "embedded file must not be saved")
+		assert.Equal(t, 1, res.Channels[0].Files)
+		assert.Equal(t, slackdump.FileStats{Downloaded: 1, Bytes: int64(len(slacktest.FileData))}, res.Files)
+
+		data, err := os.ReadFile(filepath.Join(dir, slacktest.ChannelGeneral+".json"))
+		require.NoError(t, err)
+		var conv types.Conversation
+		require.NoError(t, json.Unmarshal(data, &conv))
+		var got []string
+		for _, m := range conv.Messages {
+			for _, f := range m.Files {
+				got = append(got, f.URLPrivateDownload)
+			}
+		}
+		assert.Equal(t, []string{"data:text/plain;base64," + base64.StdEncoding.EncodeToString(slacktest.FileData)}, got)
+	})
+}
+
+func TestClient_export(t *testing.T) {
+	sd := newSession(t, slacktest.New())
+	dir := t.TempDir()
+	list, err := slackdump.NewEntityList(slacktest.ChannelGeneral)
+	require.NoError(t, err)
+	res, err := export.Create(context.Background(), sd, dir, export.Options{
+		List:   list,
+		Type:   export.TStandard,
+		Logger: logger.Silent,
+	})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "users.json"))
+	assert.FileExists(t, filepath.Join(dir, slackdump.ManifestFile))
+	assert.FileExists(t, filepath.Join(dir, "usergroups.json"))
+	assert.FileExists(t, filepath.Join(dir, "general", "2023-01-01.json"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "channel_info.json"))
+	require.NoError(t, err)
+	var info []export.ChannelInfo
+	require.NoError(t, json.Unmarshal(data, &info))
+	require.Len(t, info, 1)
+	assert.Equal(t, "Company-wide announcements", info[0].Purpose.Value)
+	assert.Equal(t, []string{slacktest.UserAlice, slacktest.UserBob}, info[0].Members)
+	assert.FileExists(t, filepath.Join(dir, "general", "attachments", slacktest.FileID+"-hello.txt"))
+
+	require.Len(t, res.Channels, 1)
+	assert.Equal(t, slacktest.ChannelGeneral, res.Channels[0].ID)
+	assert.NotZero(t, res.Channels[0].Messages)
+	assert.Equal(t, 1, res.Files.Downloaded)
+	assert.NotZero(t, res.Duration)
+}
+
+func TestClient_exportBudget(t *testing.T) {
+	sd := newSession(t, slacktest.New(), slackdump.WithDeadlineBudget(time.Nanosecond))
+	dir := t.TempDir()
+	list, err := slackdump.NewEntityList(slacktest.ChannelGeneral, slacktest.ChannelRandom)
+	require.NoError(t, err)
+	res, err := export.Create(context.Background(), sd, dir, export.Options{
+		List:   list,
+		Logger: logger.Silent,
+	})
+	assert.ErrorIs(t, err, slackdump.ErrBudgetExceeded)
+	assert.Equal(t, []string{slacktest.ChannelGeneral, slacktest.ChannelRandom}, res.Pending())
+}
+
+func TestClient_GetFile(t *testing.T) {
+	cl := slacktest.New()
+	var buf bytes.Buffer
+	require.NoError(t, cl.GetFile(slacktest.FileURL, &buf))
+	assert.Equal(t, slacktest.FileData, buf.Bytes())
+	assert.Error(t, cl.GetFile("https://example.com/none", &buf))
+	assert.Equal(t, 2, cl.Calls("files.download"))
+}
@@ -0,0 +1,211 @@
+package slacktest
+
+// In this file: the canned workspace data.
+
+import "github.com/slack-go/slack"
+
+// IDs of the canned workspace objects.
+const (
+	TeamID = "T0TEST"
+
+	UserAlice = "U0ALICE" // the current user
+	UserBob   = "U0BOB"
+
+	ChannelGeneral = "C0GENERAL" // public channel with a thread and a file
+	ChannelRandom  = "C0RANDOM"  // public channel
+	ChannelSecret  = "G0SECRET"  // private channel
+	DMAliceBob     = "D0ALICEBOB"
+
+	// ThreadTS is the timestamp of the thread in the #general channel.
+	ThreadTS = "1672531260.000100"
+
+	UserGroupEveryone = "S0EVERYONE" // user group with Alice and Bob
+
+	FileID = "F0HELLO"
+	// FileURL is the download URL of the file in the #general channel.
+	FileURL = "https://files.slack.com/files-pri/T0TEST-F0HELLO/hello.txt"
+)
+
+// FileData is the content of the file in the #general channel.
+var FileData = []byte("hello, world\n")
+
+// New returns the Client with the canned workspace: users Alice and Bob, the
+// #general channel with three messages, one of which starts a thread with two
+// replies, and another has a file attached, #random and #secret channels
+// with one message each, and one DM between Alice and Bob.  Alice has saved
+// one thread reply in #general and the message in #random.  The first message
+// in #general has the reaction with the truncated list of users.  Each call returns
+// the new copy of the data.
+func New() *Client {
+	return &Client{
+		Auth: slack.AuthTestResponse{
+			URL:    "https://test.slack.com/",
+			Team:   "Test Team",
+			User:   "alice",
+			TeamID: TeamID,
+			UserID: UserAlice,
+		},
+		Team: slack.TeamInfo{ID: TeamID, Name: "Test Team", Domain: "test"},
+		Users: []slack.User{
+			user(UserAlice, "alice", "Alice Liddell"),
+			user(UserBob, "bob", "Bob Builder"),
+		},
+		Channels: []slack.Channel{
+			channel(ChannelGeneral, "general", "Company-wide announcements", false, UserAlice),
+			channel(ChannelRandom, "random", "Non-work banter", false, UserBob),
+			channel(ChannelSecret, "secret", "Private matters", true, UserAlice),
+			dm(DMAliceBob, UserBob),
+		},
+		Messages: map[string][]slack.Message{
+			ChannelGeneral: {
+				withFile(message(UserAlice, "1672531320.000100", "see the attached file"), slack.File{
+					ID:                 FileID,
+					Name:               "hello.txt",
+					Title:              "hello.txt",
+					Mimetype:           "text/plain",
+					Filetype:           "text",
+					Size:               len(FileData),
+					URLPrivate:         FileURL,
+					URLPrivateDownload: FileURL,
+				}),
+				thread(message(UserBob, ThreadTS, "who's up for lunch?"), 2, "1672531290.000100"),
+				withReactions(message(UserAlice, "1672531200.000100", "hello, world"), slack.ItemReaction{Name: "wave", Count: 2, Users: []string{UserBob}}),
+			},
+			ChannelRandom: {
+				message(UserBob, "1672617600.000100", "random thought"),
+			},
+			ChannelSecret: {
+				message(UserAlice, "1672704000.000100", "don't tell anyone"),
+			},
+			DMAliceBob: {
+				message(UserBob, "1672790400.000100", "hi Alice"),
+			},
+		},
+		Replies: map[string][]slack.Message{
+			ChannelGeneral + ":" + ThreadTS: {
+				thread(message(UserBob, ThreadTS, "who's up for lunch?"), 2, "1672531290.000100"),
+				reply(message(UserAlice, "1672531280.000100", "me!"), ThreadTS),
+				reply(message(UserBob, "1672531290.000100", "great, in 5 minutes"), ThreadTS),
+			},
+		},
+		Members: map[string][]string{
+			ChannelGeneral: {UserAlice, UserBob},
+			ChannelRandom:  {UserAlice, UserBob},
+			ChannelSecret:  {UserAlice},
+			DMAliceBob:     {UserAlice, UserBob},
+		},
+		Files: map[string][]byte{
+			FileURL: FileData,
+		},
+		Emoji: map[string]string{
+			"party_parrot": "https://emoji.slack-edge.com/T0TEST/party_parrot/1.gif",
+			"parrot":       "alias:party_parrot",
+		},
+		UserGroups: []slack.UserGroup{
+			{
+				ID:          UserGroupEveryone,
+				TeamID:      TeamID,
+				IsUserGroup: true,
+				Name:        "Everyone",
+				Handle:      "everyone",
+				UserCount:   2,
+				Users:       []string{UserAlice, UserBob},
+			},
+		},
+		Scheduled: []slack.ScheduledMessage{
+			{ID: "Q0LUNCH", Channel: ChannelGeneral, PostAt: 1672617600, DateCreated: 1672531400, Text: "lunch reminder"},
+		},
+		Reactions: map[string][]slack.ItemReaction{
+			ChannelGeneral + ":" + "1672531200.000100": {
+				{Name: "wave", Count: 2, Users: []string{UserBob, UserAlice}},
+			},
+		},
+		ProfileFields: map[string]map[string]slack.UserProfileCustomField{
+			UserAlice: {"Xf0TITLE": {Value: "Engineer", Label: "Title"}},
+		},
+		Stars: []slack.Item{
+			saved(ChannelGeneral, reply(message(UserAlice, "1672531280.000100", "me!"), ThreadTS)),
+			saved(ChannelRandom, message(UserBob, "1672617600.000100", "random thought")),
+		},
+		Profile: slack.TeamProfile{
+			Fields: []slack.TeamProfileField{
+				{ID: "Xf0TITLE", Label: "Title", Type: "text"},
+			},
+		},
+	}
+}
+
+func user(id, name, realName string) slack.User {
+	return slack.User{
+		ID:       id,
+		TeamID:   TeamID,
+		Name:     name,
+		RealName: realName,
+		TZ:       "Europe/London",
+		Profile: slack.UserProfile{
+			RealName:    realName,
+			DisplayName: name,
+			Email:       name + "@example.com",
+		},
+	}
+}
+
+func channel(id, name, purpose string, private bool, creator string) slack.Channel {
+	var ch slack.Channel
+	ch.ID = id
+	ch.Name = name
+	ch.NameNormalized = name
+	ch.IsChannel = !private
+	ch.IsGroup = private
+	ch.IsPrivate = private
+	ch.IsMember = true
+	ch.Creator = creator
+	ch.Created = 1672531200
+	ch.Purpose = slack.Purpose{Value: purpose, Creator: creator}
+	return ch
+}
+
+func dm(id, user string) slack.Channel {
+	var ch slack.Channel
+	ch.ID = id
+	ch.IsIM = true
+	ch.User = user
+	ch.Created = 1672531200
+	return ch
+}
+
+func message(user, ts, text string) slack.Message {
+	return slack.Message{Msg: slack.Msg{
+		Type:      slack.TYPE_MESSAGE,
+		User:      user,
+		Timestamp: ts,
+		Text:      text,
+	}}
+}
+
+func thread(m slack.Message, replies int, latestReply string) slack.Message {
+	m.ThreadTimestamp = m.Timestamp
+	m.ReplyCount = replies
+	m.LatestReply = latestReply
+	return m
+}
+
+func reply(m slack.Message, threadTS string) slack.Message {
+	m.ThreadTimestamp = threadTS
+	return m
+}
+
+func saved(channelID string, m slack.Message) slack.Item {
+	return slack.NewMessageItem(channelID, &m)
+}
+
+// withReactions adds the reactions to the message, as returned in the history.
+func withReactions(m slack.Message, r ...slack.ItemReaction) slack.Message {
+	m.Reactions = r
+	return m
+}
+
+func withFile(m slack.Message, f slack.File) slack.Message {
+	m.Files = []slack.File{f}
+	return m
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"runtime/trace"
+	"sort"
+	"strings"
 	"time"
 
 	"errors"
@@ -12,10 +14,52 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/otrace"
 	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/progress"
 	"github.com/rusq/slackdump/v2/types"
 )
 
+// ThreadSchedule is the schedule of fetching the thread replies of the
+// conversation.
+type ThreadSchedule uint8
+
+const (
+	// TSInterleaved fetches the replies of the threads on each page of the
+	// conversation history, as the page is fetched.
+	TSInterleaved ThreadSchedule = iota
+	// TSDeferred fetches the complete history of the conversation first,
+	// and then the replies of its threads, the newest threads first.
+	TSDeferred
+	// TSRecent is TSDeferred, where the threads with the most recent
+	// replies are fetched first.
+	TSRecent
+)
+
+var threadScheduleNames = map[ThreadSchedule]string{
+	TSInterleaved: "interleaved",
+	TSDeferred:    "deferred",
+	TSRecent:      "recent",
+}
+
+func (s ThreadSchedule) String() string {
+	if name, ok := threadScheduleNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("ThreadSchedule(%d)", uint8(s))
+}
+
+// Set sets the schedule from its name, it is the flag.Value interface.
+func (s *ThreadSchedule) Set(v string) error {
+	for ts, name := range threadScheduleNames {
+		if strings.EqualFold(v, name) {
+			*s = ts
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown thread schedule: %q, use one of: interleaved, deferred or recent", v)
+}
+
 type threadFunc func(ctx context.Context, l *rate.Limiter, channelID string, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error)
 
 // dumpThreadAsConversation dumps a single thread identified by (channelID,
@@ -36,7 +80,7 @@ func (sd *Session) dumpThreadAsConversation(
 
 	trace.Logf(ctx, "info", "channelID: %q, threadTS: %q", sl.Channel, sl.ThreadTS)
 
-	threadMsgs, err := sd.dumpThread(ctx, sd.limiter(network.Tier3), sl.Channel, sl.ThreadTS, oldest, latest, processFn...)
+	threadMsgs, err := sd.dumpThread(ctx, sd.pooledLimiter(network.Tier3), sl.Channel, sl.ThreadTS, oldest, latest, processFn...)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +106,7 @@ func (sd *Session) dumpThreadAsConversation(
 // threads.  msgs is being updated with discovered messages.
 //
 // ref: https://api.slack.com/messaging/retrieving
-func (*Session) populateThreads(
+func (sd *Session) populateThreads(
 	ctx context.Context,
 	l *rate.Limiter,
 	msgs []types.Message,
@@ -71,14 +115,21 @@ func (*Session) populateThreads(
 	dumpFn threadFunc,
 ) (int, error) {
 	total := 0
-	for i := range msgs {
+	for _, i := range sd.threadOrder(msgs) {
 		if msgs[i].ThreadTimestamp == "" || msgs[i].SubType == "thread_broadcast" {
 			continue
 		}
+		id := channelID + ":" + msgs[i].ThreadTimestamp
+		if reason, ok := sd.options.SkipList.Skipped(SkipThread, id); ok {
+			sd.l().Printf("skipping the replies: %s", skipListed(SkipThread, id, reason))
+			continue
+		}
 		threadMsgs, err := dumpFn(ctx, l, channelID, msgs[i].ThreadTimestamp, oldest, latest)
 		if err != nil {
-			return total, err
+			sd.skipFail(ctx, SkipThread, id, err)
+			return total, &threadError{err: err}
 		}
+		sd.skipClear(SkipThread, id)
 		if len(threadMsgs) == 0 {
 			trace.Log(ctx, "warn", "a very strange situation right here, no error, and no messages. testing?")
 			continue
@@ -89,6 +140,43 @@ func (*Session) populateThreads(
 	return total, nil
 }
 
+// threadOrder returns the indexes of msgs in the order, in which their
+// threads are fetched: as they are, or, with TSRecent, the threads with the
+// most recent replies first.
+func (sd *Session) threadOrder(msgs []types.Message) []int {
+	idx := make([]int, len(msgs))
+	for i := range idx {
+		idx[i] = i
+	}
+	if sd.options.ThreadSchedule == TSRecent {
+		latest := func(m types.Message) string {
+			if m.LatestReply != "" {
+				return m.LatestReply
+			}
+			return m.ThreadTimestamp
+		}
+		sort.SliceStable(idx, func(i, j int) bool {
+			return latest(msgs[idx[i]]) > latest(msgs[idx[j]])
+		})
+	}
+	return idx
+}
+
+// threadError is the error of the thread of the conversation, so that it is
+// recorded on the skip-list as the failure of the thread, and not of the
+// conversation.
+type threadError struct {
+	err error
+}
+
+func (e *threadError) Error() string {
+	return e.err.Error()
+}
+
+func (e *threadError) Unwrap() error {
+	return e.err
+}
+
 // dumpThread retrieves all messages in the thread and returns them as a slice
 // of messages.
 func (sd *Session) dumpThread(
@@ -98,13 +186,19 @@ func (sd *Session) dumpThread(
 	threadTS string,
 	oldest, latest time.Time,
 	processFn ...ProcessFunc,
-) ([]types.Message, error) {
+) (_ []types.Message, err error) {
+	ctx, span := otrace.Start(ctx, "thread.fetch", otrace.String("channel.id", channelID), otrace.String("thread.ts", threadTS))
+	defer func() { span.End(err) }()
+
 	var (
 		thread     []types.Message
 		cursor     string
 		fetchStart = time.Now()
 	)
 	for i := 0; ; i++ {
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
 		var (
 			msgs       []slack.Message
 			hasmore    bool
@@ -124,6 +218,8 @@ func (sd *Session) dumpThread(
 						Oldest:    structures.FormatSlackTS(oldest),
 						Latest:    structures.FormatSlackTS(latest),
 						Inclusive: true,
+
+						IncludeAllMetadata: sd.options.Metadata,
 					},
 				)
 			})
@@ -132,7 +228,7 @@ func (sd *Session) dumpThread(
 			}
 			return nil
 		}); err != nil {
-			return nil, err
+			return nil, network.Classify(channelID, err)
 		}
 		// slack api returns the first message of a thread with every api call:
 		// strip the first message if i > 0 to avoid dupes
@@ -146,18 +242,23 @@ func (sd *Session) dumpThread(
 			return nil, err
 		}
 
-		sd.l().Printf("  thread request #%5d, fetched: %4d, total: %8d, process results: %s (speed: %6.2f/sec, avg: %6.2f/sec)\n",
-			i+1, len(msgs), len(thread),
-			prs,
-			float64(len(msgs))/time.Since(reqStart).Seconds(),
-			float64(len(thread))/time.Since(fetchStart).Seconds(),
-		)
+		sd.pr().MessagesFetched(ctx, progress.Fetch{
+			ChannelID: channelID,
+			ThreadTS:  threadTS,
+			Request:   i + 1,
+			Count:     len(msgs),
+			Total:     len(thread),
+			Took:      time.Since(reqStart),
+			Elapsed:   time.Since(fetchStart),
+			Processed: prs.String(),
+			Done:      !hasmore,
+		})
 
 		if !hasmore {
-			sd.l().Printf("  thread fetch complete, total: %d", len(thread))
 			break
 		}
 		cursor = nextCursor
 	}
+	span.SetAttr(otrace.Int("messages", len(thread)))
 	return thread, nil
 }
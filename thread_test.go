@@ -398,3 +398,77 @@ func TestSession_dumpThread(t *testing.T) {
 		})
 	}
 }
+
+func TestThreadSchedule_Set(t *testing.T) {
+	var s ThreadSchedule
+	if err := s.Set("Recent"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assert.Equal(t, TSRecent, s)
+	assert.Error(t, s.Set("whenever"))
+}
+
+func TestSession_Dump_threadSchedule(t *testing.T) {
+	// two pages of the history, each has a thread, the thread of the
+	// second, older, page has the most recent reply.
+	parentA := slack.Message{Msg: slack.Msg{Timestamp: "1638500000.000200", ThreadTimestamp: "1638500000.000200", ReplyCount: 1, LatestReply: "1638500010.000000", Text: "A"}}
+	parentB := slack.Message{Msg: slack.Msg{Timestamp: "1638400000.000100", ThreadTimestamp: "1638400000.000100", ReplyCount: 1, LatestReply: "1638600000.000000", Text: "B"}}
+	reply := func(parent slack.Message, ts string) slack.Message {
+		return slack.Message{Msg: slack.Msg{Timestamp: ts, ThreadTimestamp: parent.Timestamp, Text: "reply to " + parent.Text}}
+	}
+
+	tests := []struct {
+		name     string
+		schedule ThreadSchedule
+		want     []string
+	}{
+		{"interleaved", TSInterleaved, []string{"history", "replies A", "history", "replies B"}},
+		{"deferred", TSDeferred, []string{"history", "history", "replies A", "replies B"}},
+		{"recent", TSRecent, []string{"history", "history", "replies B", "replies A"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			mc := newmockClienter(gomock.NewController(t))
+			mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, p *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+					calls = append(calls, "history")
+					resp := &slack.GetConversationHistoryResponse{SlackResponse: slack.SlackResponse{Ok: true}}
+					if p.Cursor == "" {
+						resp.HasMore = true
+						resp.ResponseMetaData.NextCursor = "cur"
+						resp.Messages = []slack.Message{parentA}
+					} else {
+						resp.Messages = []slack.Message{parentB}
+					}
+					return resp, nil
+				}).Times(2)
+			mc.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, p *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+					parent := parentA
+					if p.Timestamp == parentB.Timestamp {
+						parent = parentB
+					}
+					calls = append(calls, "replies "+parent.Text)
+					return []slack.Message{parent, reply(parent, parent.LatestReply)}, false, "", nil
+				}).Times(2)
+			mockConvInfo(mc, "CHM82GF99", "unittest")
+
+			opts := DefOptions
+			opts.ThreadSchedule = tt.schedule
+			sd := &Session{client: mc, options: opts}
+			conv, err := sd.DumpAll(context.Background(), "CHM82GF99")
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, calls)
+			if assert.Len(t, conv.Messages, 2) {
+				for _, m := range conv.Messages {
+					if assert.Len(t, m.ThreadReplies, 1) {
+						assert.Equal(t, "reply to "+m.Text, m.ThreadReplies[0].Text)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package slackdump
+
+// In this file: the pool of the tokens of the same workspace.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/slack-go/slack"
+	"golang.org/x/time/rate"
+
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+// clientPool is the Slacker, that rotates the API calls across the clients
+// of the different tokens of the same workspace.  Slack rate limits are per
+// token, so with n tokens, the session can make n times more calls: each
+// client has its own limiters, see clientPool.wait.  The first client is the
+// client of the session token, the calls, that depend on the user of the
+// token, i.e. the conversation lists, the search and the saved items, always
+// go through it, and are throttled by the limiters of the session.
+type clientPool struct {
+	clients []Slacker
+	next    uint32
+
+	// newLimiter returns the limiter of the tier, nil - the calls are not
+	// throttled by the pool.
+	newLimiter func(t network.Tier) *rate.Limiter
+	mu         sync.Mutex
+	limiters   []map[network.Tier]*rate.Limiter // by the client index
+}
+
+// newClientPool returns the pool of the client cl of the session token, and
+// the clients of the tokens, created with newFn.  The tokens must belong to
+// the workspace teamID, the browser tokens are not supported, as they
+// require the cookies of their users.  The rotated calls of each client are
+// throttled by the limiters, created with newLimiter.
+func newClientPool(ctx context.Context, cl Slacker, teamID string, tokens []string, newFn func(token string) Slacker, newLimiter func(t network.Tier) *rate.Limiter) (*clientPool, error) {
+	p := &clientPool{clients: []Slacker{cl}, newLimiter: newLimiter}
+	for i, token := range tokens {
+		if auth.IsClientToken(token) {
+			return nil, fmt.Errorf("pool token #%d: browser tokens can't be pooled", i+1)
+		}
+		c := newFn(token)
+		resp, err := c.AuthTestContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("pool token #%d: %w", i+1, network.Classify("", err))
+		}
+		if resp.TeamID != teamID {
+			return nil, fmt.Errorf("pool token #%d: belongs to the workspace %s, not %s", i+1, resp.TeamID, teamID)
+		}
+		p.clients = append(p.clients, c)
+	}
+	return p, nil
+}
+
+// noAccess are the API errors, that mean that the pooled token has no access
+// to the conversation or the file, that the session token may have, i.e. to
+// the private channel, that its user is not a member of.
+var noAccess = map[string]bool{
+	"channel_not_found": true,
+	"not_in_channel":    true,
+	"thread_not_found":  true,
+	"file_not_found":    true,
+	"access_denied":     true,
+	"missing_scope":     true,
+}
+
+// do calls fn with the next client, once its limiter of the API method
+// allows.  If the pooled token has no access to the object, fn is called
+// again with the client of the session token.
+func (p *clientPool) do(ctx context.Context, method string, fn func(c Slacker) error) error {
+	c, err := p.acquire(ctx, method)
+	if err != nil {
+		return err
+	}
+	err = fn(c)
+	var ser slack.SlackErrorResponse
+	if err != nil && c != p.clients[0] && errors.As(err, &ser) && noAccess[ser.Err] {
+		if err := p.wait(ctx, 0, method); err != nil {
+			return err
+		}
+		return fn(p.clients[0])
+	}
+	return err
+}
+
+// acquire returns the next client, once its limiter of the API method allows.
+func (p *clientPool) acquire(ctx context.Context, method string) (Slacker, error) {
+	i := p.pick()
+	if err := p.wait(ctx, i, method); err != nil {
+		return nil, err
+	}
+	return p.clients[i], nil
+}
+
+// pick returns the index of the next client.
+func (p *clientPool) pick() int {
+	n := atomic.AddUint32(&p.next, 1)
+	return int(n-1) % len(p.clients)
+}
+
+// wait waits for the limiter of the client i for the tier of the API method.
+// The limiters are independent, so that each token is throttled at the rate
+// of the tier, regardless of the others.
+func (p *clientPool) wait(ctx context.Context, i int, method string) error {
+	if p.newLimiter == nil {
+		return nil
+	}
+	t := network.MethodTier(method)
+	p.mu.Lock()
+	if p.limiters == nil {
+		p.limiters = make([]map[network.Tier]*rate.Limiter, len(p.clients))
+	}
+	if p.limiters[i] == nil {
+		p.limiters[i] = make(map[network.Tier]*rate.Limiter)
+	}
+	l, ok := p.limiters[i][t]
+	if !ok {
+		l = p.newLimiter(t)
+		p.limiters[i][t] = l
+	}
+	p.mu.Unlock()
+	return l.Wait(ctx)
+}
+
+// size returns the number of the clients in the pool.
+func (p *clientPool) size() int {
+	return len(p.clients)
+}
+
+// pooledLimiter returns the limiter of the tier t for the API calls, that are
+// rotated across the tokens of the session.  With the pool, these calls are
+// throttled by the limiters of the pooled clients instead, see
+// clientPool.wait, and the returned limiter does not limit them.
+func (sd *Session) pooledLimiter(t network.Tier) *rate.Limiter {
+	if p, ok := sd.client.(*clientPool); ok && p.size() > 1 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return sd.limiter(t)
+}
+
+func (p *clientPool) AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error) {
+	return p.clients[0].AuthTestContext(ctx)
+}
+
+func (p *clientPool) GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (ch *slack.Channel, err error) {
+	err = p.do(ctx, "conversations.info", func(c Slacker) error {
+		var err error
+		ch, err = c.GetConversationInfoContext(ctx, input)
+		return err
+	})
+	return
+}
+
+func (p *clientPool) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (resp *slack.GetConversationHistoryResponse, err error) {
+	err = p.do(ctx, "conversations.history", func(c Slacker) error {
+		var err error
+		resp, err = c.GetConversationHistoryContext(ctx, params)
+		return err
+	})
+	return
+}
+
+func (p *clientPool) GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error) {
+	err = p.do(ctx, "conversations.replies", func(c Slacker) error {
+		var err error
+		msgs, hasMore, nextCursor, err = c.GetConversationRepliesContext(ctx, params)
+		return err
+	})
+	return
+}
+
+func (p *clientPool) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	return p.clients[0].GetConversationsContext(ctx, params)
+}
+
+func (p *clientPool) GetFile(downloadURL string, writer io.Writer) error {
+	// the file downloads are not rate limited by the tier.
+	return p.clients[p.pick()].GetFile(downloadURL, writer)
+}
+
+func (p *clientPool) GetFileInfoContext(ctx context.Context, fileID string, count, page int) (f *slack.File, comments []slack.Comment, paging *slack.Paging, err error) {
+	err = p.do(ctx, "files.info", func(c Slacker) error {
+		var err error
+		f, comments, paging, err = c.GetFileInfoContext(ctx, fileID, count, page)
+		return err
+	})
+	return
+}
+
+func (p *clientPool) GetScheduledMessagesContext(ctx context.Context, params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error) {
+	return p.clients[0].GetScheduledMessagesContext(ctx, params)
+}
+
+func (p *clientPool) GetTeamInfo() (*slack.TeamInfo, error) {
+	c, err := p.acquire(context.Background(), "team.info")
+	if err != nil {
+		return nil, err
+	}
+	return c.GetTeamInfo()
+}
+
+func (p *clientPool) GetTeamInfoContext(ctx context.Context) (*slack.TeamInfo, error) {
+	c, err := p.acquire(ctx, "team.info")
+	if err != nil {
+		return nil, err
+	}
+	return c.GetTeamInfoContext(ctx)
+}
+
+func (p *clientPool) GetTeamProfileContext(ctx context.Context) (*slack.TeamProfile, error) {
+	c, err := p.acquire(ctx, "team.profile.get")
+	if err != nil {
+		return nil, err
+	}
+	return c.GetTeamProfileContext(ctx)
+}
+
+func (p *clientPool) GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	c, err := p.acquire(ctx, "usergroups.list")
+	if err != nil {
+		return nil, err
+	}
+	return c.GetUserGroupsContext(ctx, options...)
+}
+
+func (p *clientPool) GetUserInfoContext(ctx context.Context, user string) (*slack.User, error) {
+	c, err := p.acquire(ctx, "users.info")
+	if err != nil {
+		return nil, err
+	}
+	return c.GetUserInfoContext(ctx, user)
+}
+
+func (p *clientPool) GetUserProfileContext(ctx context.Context, params *slack.GetUserProfileParameters) (*slack.UserProfile, error) {
+	return p.clients[0].GetUserProfileContext(ctx, params)
+}
+
+func (p *clientPool) GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error) {
+	c, err := p.acquire(ctx, "users.list")
+	if err != nil {
+		return nil, err
+	}
+	return c.GetUsersContext(ctx, options...)
+}
+
+func (p *clientPool) GetEmojiContext(ctx context.Context) (map[string]string, error) {
+	c, err := p.acquire(ctx, "emoji.list")
+	if err != nil {
+		return nil, err
+	}
+	return c.GetEmojiContext(ctx)
+}
+
+func (p *clientPool) GetUsersInConversationContext(ctx context.Context, params *slack.GetUsersInConversationParameters) (members []string, nextCursor string, err error) {
+	err = p.do(ctx, "conversations.members", func(c Slacker) error {
+		var err error
+		members, nextCursor, err = c.GetUsersInConversationContext(ctx, params)
+		return err
+	})
+	return
+}
+
+func (p *clientPool) GetReactionsContext(ctx context.Context, item slack.ItemRef, params slack.GetReactionsParameters) (reactions []slack.ItemReaction, err error) {
+	err = p.do(ctx, "reactions.get", func(c Slacker) error {
+		var err error
+		reactions, err = c.GetReactionsContext(ctx, item, params)
+		return err
+	})
+	return
+}
+
+func (p *clientPool) ListStarsContext(ctx context.Context, params slack.StarsParameters) ([]slack.Item, *slack.Paging, error) {
+	return p.clients[0].ListStarsContext(ctx, params)
+}
+
+func (p *clientPool) SearchMessagesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error) {
+	return p.clients[0].SearchMessagesContext(ctx, query, params)
+}
+
+func (p *clientPool) SearchFilesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchFiles, error) {
+	return p.clients[0].SearchFilesContext(ctx, query, params)
+}
@@ -0,0 +1,116 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+func Test_newClientPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	main, other := newmockClienter(ctrl), newmockClienter(ctrl)
+	newFn := func(string) Slacker { return other }
+
+	t.Run("ok", func(t *testing.T) {
+		other.EXPECT().AuthTestContext(gomock.Any()).Return(&slack.AuthTestResponse{TeamID: "T01"}, nil)
+		p, err := newClientPool(context.Background(), main, "T01", []string{"xoxp-2"}, newFn, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, p.size())
+	})
+	t.Run("other workspace", func(t *testing.T) {
+		other.EXPECT().AuthTestContext(gomock.Any()).Return(&slack.AuthTestResponse{TeamID: "T02"}, nil)
+		_, err := newClientPool(context.Background(), main, "T01", []string{"xoxp-2"}, newFn, nil)
+		assert.ErrorContains(t, err, "belongs to the workspace T02")
+	})
+	t.Run("invalid token", func(t *testing.T) {
+		other.EXPECT().AuthTestContext(gomock.Any()).Return(nil, slack.SlackErrorResponse{Err: "invalid_auth"})
+		_, err := newClientPool(context.Background(), main, "T01", []string{"xoxp-2"}, newFn, nil)
+		assert.Error(t, err)
+	})
+	t.Run("browser token", func(t *testing.T) {
+		_, err := newClientPool(context.Background(), main, "T01", []string{"xoxc-2"}, newFn, nil)
+		assert.ErrorContains(t, err, "browser tokens can't be pooled")
+	})
+}
+
+func Test_clientPool(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	main, other := newmockClienter(ctrl), newmockClienter(ctrl)
+	p := &clientPool{clients: []Slacker{main, other}}
+
+	t.Run("rotation", func(t *testing.T) {
+		main.EXPECT().GetUserInfoContext(ctx, "U01").Return(&slack.User{ID: "U01"}, nil)
+		other.EXPECT().GetUserInfoContext(ctx, "U02").Return(&slack.User{ID: "U02"}, nil)
+		main.EXPECT().GetUserInfoContext(ctx, "U03").Return(&slack.User{ID: "U03"}, nil)
+		for _, id := range []string{"U01", "U02", "U03"} {
+			u, err := p.GetUserInfoContext(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, id, u.ID)
+		}
+	})
+	t.Run("no access falls back to the session token", func(t *testing.T) {
+		params := &slack.GetConversationHistoryParameters{ChannelID: "G01"}
+		other.EXPECT().GetConversationHistoryContext(ctx, params).Return(nil, slack.SlackErrorResponse{Err: "channel_not_found"})
+		main.EXPECT().GetConversationHistoryContext(ctx, params).Return(&slack.GetConversationHistoryResponse{HasMore: true}, nil)
+		resp, err := p.GetConversationHistoryContext(ctx, params)
+		require.NoError(t, err)
+		assert.True(t, resp.HasMore)
+	})
+	t.Run("other errors are returned", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		main.EXPECT().GetConversationRepliesContext(ctx, gomock.Any()).Return(nil, false, "", errBoom)
+		_, _, _, err := p.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{})
+		assert.ErrorIs(t, err, errBoom)
+	})
+	t.Run("user dependent calls use the session token", func(t *testing.T) {
+		main.EXPECT().GetConversationsContext(ctx, gomock.Any()).Return(nil, "", nil).Times(2)
+		main.EXPECT().SearchMessagesContext(ctx, "q", gomock.Any()).Return(&slack.SearchMessages{}, nil)
+		for i := 0; i < 2; i++ {
+			_, _, err := p.GetConversationsContext(ctx, &slack.GetConversationsParameters{})
+			require.NoError(t, err)
+		}
+		_, err := p.SearchMessagesContext(ctx, "q", slack.SearchParameters{})
+		require.NoError(t, err)
+	})
+}
+
+func TestSession_pooledLimiter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	single := &Session{client: newmockClienter(ctrl), options: DefOptions}
+	pooled := &Session{client: &clientPool{clients: []Slacker{newmockClienter(ctrl), newmockClienter(ctrl), newmockClienter(ctrl)}}, options: DefOptions}
+
+	assert.Equal(t, single.limiter(network.Tier3).Limit(), single.pooledLimiter(network.Tier3).Limit())
+	assert.Equal(t, rate.Inf, pooled.pooledLimiter(network.Tier3).Limit(), "pooled calls are throttled by the pool")
+}
+
+func Test_clientPool_wait(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	main, other := newmockClienter(ctrl), newmockClienter(ctrl)
+	var created []network.Tier
+	p := &clientPool{clients: []Slacker{main, other}, newLimiter: func(t network.Tier) *rate.Limiter {
+		created = append(created, t)
+		return rate.NewLimiter(rate.Every(time.Hour), 1) // one call per client
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	main.EXPECT().GetUserInfoContext(ctx, "U01").Return(&slack.User{ID: "U01"}, nil)
+	other.EXPECT().GetUserInfoContext(ctx, "U02").Return(&slack.User{ID: "U02"}, nil)
+	for _, id := range []string{"U01", "U02"} {
+		_, err := p.GetUserInfoContext(ctx, id)
+		require.NoError(t, err, "each client has its own limiter")
+	}
+	_, err := p.GetUserInfoContext(ctx, "U03")
+	assert.Error(t, err, "the limiter of the first client is exhausted")
+	assert.Equal(t, []network.Tier{network.Tier4, network.Tier4}, created)
+}
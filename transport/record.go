@@ -0,0 +1,254 @@
+package transport
+
+// In this file: recording of the API calls to the cassette.
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// redacted replaces the values of the secrets in the cassette.
+const redacted = "REDACTED"
+
+// Cassette is the recording of the API calls.
+type Cassette struct {
+	Recorded     time.Time     `json:"recorded"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is the recorded request and its response.
+type Interaction struct {
+	Request  Request       `json:"request"`
+	Response Response      `json:"response"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"` // transport error, if any.
+}
+
+// Request is the recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Response is the recorded response.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Body is the recorded body, it's marshaled as a string, if it is a valid
+// UTF-8, i.e. JSON or a form, otherwise, as a base64 encoded object.
+type Body []byte
+
+// binaryBody is the binary body, encoding/json encodes []byte as base64.
+type binaryBody struct {
+	Base64 []byte `json:"base64"`
+}
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(binaryBody{Base64: b})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+	var bin binaryBody
+	if err := json.Unmarshal(data, &bin); err != nil {
+		return err
+	}
+	*b = bin.Base64
+	return nil
+}
+
+// Recorder records the API calls.  The tokens and cookies are redacted from
+// the recorded requests.  Bodies are kept in memory, until the cassette is
+// saved, so it is intended for debugging and tests.
+type Recorder struct {
+	mu sync.Mutex
+	c  Cassette
+}
+
+// NewRecorder creates the Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{c: Cassette{Recorded: time.Now().UTC()}}
+}
+
+// Middleware returns the middleware that records the calls.
+func (r *Recorder) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var reqBody []byte
+			if req.Body != nil && req.Body != http.NoBody {
+				var err error
+				if reqBody, err = io.ReadAll(req.Body); err != nil {
+					return nil, err
+				}
+				req.Body.Close()
+				req = req.Clone(req.Context())
+				req.Body = io.NopCloser(bytes.NewReader(reqBody))
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			in := Interaction{
+				Request:  recordRequest(req, reqBody),
+				Duration: time.Since(start),
+			}
+			if err != nil {
+				in.Error = err.Error()
+				r.add(in)
+				return resp, err
+			}
+			respBody, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(respBody))
+			in.Response = Response{
+				Status: resp.StatusCode,
+				Header: redactHeader(resp.Header),
+				Body:   respBody,
+			}
+			r.add(in)
+			return resp, nil
+		})
+	}
+}
+
+func (r *Recorder) add(in Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.c.Interactions = append(r.c.Interactions, in)
+}
+
+// Cassette returns the copy of the recorded cassette.
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.c
+	c.Interactions = append([]Interaction(nil), r.c.Interactions...)
+	return c
+}
+
+// Save writes the cassette to w as JSON.
+func (r *Recorder) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Cassette())
+}
+
+// SaveFile writes the cassette to the file.  If compress is true, the file
+// is gzipped, otherwise it is the plain JSON, that can be inspected and
+// edited by hand.
+func (r *Recorder) SaveFile(filename string, compress bool) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := r.saveTo(f, compress); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (r *Recorder) saveTo(w io.Writer, compress bool) error {
+	if !compress {
+		return r.Save(w)
+	}
+	gz := gzip.NewWriter(w)
+	if err := r.Save(gz); err != nil {
+		gz.Close()
+		return err
+	}
+	return gz.Close()
+}
+
+// gzipMagic is the header of the gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// LoadCassette reads the cassette from r.  The gzipped cassette is detected
+// by its header, so both the compressed and the plain JSON cassettes are
+// accepted, regardless of the file extension.
+func LoadCassette(r io.Reader) (*Cassette, error) {
+	br := bufio.NewReader(r)
+	if hdr, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(hdr, gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+	var c Cassette
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func recordRequest(req *http.Request, body []byte) Request {
+	u := *req.URL
+	u.RawQuery = redactValues(u.RawQuery)
+	rr := Request{
+		Method: req.Method,
+		URL:    u.String(),
+		Header: redactHeader(req.Header),
+		Body:   body,
+	}
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		rr.Body = Body(redactValues(string(body)))
+	}
+	return rr
+}
+
+// secretHeaders are the headers, values of which are redacted.
+var secretHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+func redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	h = h.Clone()
+	for _, k := range secretHeaders {
+		if _, ok := h[k]; ok {
+			h[k] = []string{redacted}
+		}
+	}
+	return h
+}
+
+// redactValues redacts the token in the URL encoded values s.
+func redactValues(s string) string {
+	if s == "" {
+		return s
+	}
+	v, err := url.ParseQuery(s)
+	if err != nil || !v.Has("token") {
+		return s
+	}
+	v.Set("token", redacted)
+	return v.Encode()
+}
@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/conversations.history":
+			r.ParseForm()
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Set-Cookie", "d=secret")
+			io.WriteString(w, `{"ok":true,"channel":"`+r.Form.Get("channel")+`"}`)
+		case "/files/a.png":
+			w.Write([]byte{0x89, 'P', 'N', 'G', 0xff})
+		}
+	}))
+	defer srv.Close()
+
+	rec := NewRecorder()
+	cl := &http.Client{Transport: Chain(nil, rec.Middleware())}
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/conversations.history",
+		strings.NewReader(url.Values{"token": {"xoxc-secret"}, "channel": {"C01"}}.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Cookie", "d=xoxd-secret")
+	resp, err := cl.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, `{"ok":true,"channel":"C01"}`, string(body), "server receives the original body")
+
+	resp, err = cl.Get(srv.URL + "/files/a.png?token=xoxc-secret")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	var buf bytes.Buffer
+	require.NoError(t, rec.Save(&buf))
+	assert.NotContains(t, buf.String(), "secret", "secrets are redacted")
+
+	c, err := LoadCassette(&buf)
+	require.NoError(t, err)
+	require.Len(t, c.Interactions, 2)
+	api := c.Interactions[0]
+	assert.Equal(t, http.MethodPost, api.Request.Method)
+	assert.Equal(t, "channel=C01&token=REDACTED", string(api.Request.Body))
+	assert.Equal(t, []string{redacted}, api.Request.Header["Cookie"])
+	assert.Equal(t, http.StatusOK, api.Response.Status)
+	assert.Equal(t, `{"ok":true,"channel":"C01"}`, string(api.Response.Body))
+
+	file := c.Interactions[1]
+	assert.Equal(t, srv.URL+"/files/a.png?token=REDACTED", file.Request.URL)
+	assert.Equal(t, []byte{0x89, 'P', 'N', 'G', 0xff}, []byte(file.Response.Body), "binary body")
+}
+
+func TestRecorder_error(t *testing.T) {
+	rec := NewRecorder()
+	rt := Chain(RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, io.ErrUnexpectedEOF
+	}), rec.Middleware())
+	_, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://slack.com/api/test", nil))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	name := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, rec.SaveFile(name, false))
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+	c, err := LoadCassette(f)
+	require.NoError(t, err)
+	require.Len(t, c.Interactions, 1)
+	assert.Equal(t, io.ErrUnexpectedEOF.Error(), c.Interactions[0].Error)
+}
+
+func TestRecorder_SaveFile(t *testing.T) {
+	rec := NewRecorder()
+	rt := Chain(RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"ok":true}`))}, nil
+	}), rec.Middleware())
+	resp, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://slack.com/api/test", nil))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	for _, compress := range []bool{true, false} {
+		name := filepath.Join(t.TempDir(), "cassette.json")
+		require.NoError(t, rec.SaveFile(name, compress))
+		data, err := os.ReadFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, compress, bytes.HasPrefix(data, gzipMagic), "compress=%v", compress)
+
+		c, err := LoadCassette(bytes.NewReader(data))
+		require.NoError(t, err, "compress=%v", compress)
+		require.Len(t, c.Interactions, 1)
+		assert.Equal(t, `{"ok":true}`, string(c.Interactions[0].Response.Body))
+	}
+}
@@ -0,0 +1,105 @@
+package transport
+
+// In this file: replaying of the recorded cassette.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrNotRecorded is returned by the Player, if the request is not in the
+// cassette.
+var ErrNotRecorded = errors.New("request is not recorded")
+
+// Player serves the recorded responses to the requests that match the
+// recorded ones, without the network access.  The request matches, if it has
+// the same method, URL and body, with tokens redacted, as the recorded one.
+// Matching interactions are served in the recorded order, if they are
+// exhausted, the last one is served again, so that the retries work.
+type Player struct {
+	mu   sync.Mutex
+	c    *Cassette
+	used []bool
+}
+
+// NewPlayer creates the Player for the cassette c.
+func NewPlayer(c *Cassette) *Player {
+	return &Player{c: c, used: make([]bool, len(c.Interactions))}
+}
+
+// Middleware returns the middleware that serves the recorded responses, it
+// doesn't call the next round tripper.
+func (p *Player) Middleware() Middleware {
+	return func(http.RoundTripper) http.RoundTripper {
+		return p
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	in, ok := p.match(recordRequest(req, body))
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, req.URL.Path)
+	}
+	if in.Error != "" {
+		return nil, errors.New(in.Error)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+		StatusCode:    in.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        in.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(in.Response.Body)),
+		ContentLength: int64(len(in.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// match returns the first unused interaction that matches the request, or
+// the last used one.
+func (p *Player) match(rr Request) (Interaction, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	last := -1
+	for i, in := range p.c.Interactions {
+		if in.Request.Method != rr.Method || in.Request.URL != rr.URL || !bytes.Equal(in.Request.Body, rr.Body) {
+			continue
+		}
+		if !p.used[i] {
+			p.used[i] = true
+			return in, true
+		}
+		last = i
+	}
+	if last < 0 {
+		return Interaction{}, false
+	}
+	return p.c.Interactions[last], true
+}
+
+// Unused returns the number of the interactions that were not replayed.
+func (p *Player) Unused() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var n int
+	for _, used := range p.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
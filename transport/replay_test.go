@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func formRequest(t *testing.T, method string, v url.Values) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "https://slack.com/api/"+method, strings.NewReader(v.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestPlayer(t *testing.T) {
+	page := func(cursor, body string) Interaction {
+		return Interaction{
+			Request:  Request{Method: http.MethodPost, URL: "https://slack.com/api/conversations.history", Body: Body("channel=C01&cursor=" + cursor + "&token=REDACTED")},
+			Response: Response{Status: http.StatusOK, Body: Body(body)},
+		}
+	}
+	c := &Cassette{Interactions: []Interaction{
+		page("", `{"page":1}`),
+		page("", `{"page":1,"retry":true}`),
+		page("2", `{"page":2}`),
+		{Request: Request{Method: http.MethodGet, URL: "https://files.slack.com/a.png"}, Error: "connection reset"},
+	}}
+	p := NewPlayer(c)
+	cl := &http.Client{Transport: Chain(nil, p.Middleware())}
+
+	get := func(cursor string) string {
+		resp, err := cl.Do(formRequest(t, "conversations.history", url.Values{"token": {"xoxc-live"}, "channel": {"C01"}, "cursor": {cursor}}))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, 4, p.Unused())
+	assert.Equal(t, `{"page":1}`, get(""), "token is ignored")
+	assert.Equal(t, `{"page":1,"retry":true}`, get(""), "recorded order")
+	assert.Equal(t, `{"page":1,"retry":true}`, get(""), "last one is repeated")
+	assert.Equal(t, `{"page":2}`, get("2"))
+	assert.Equal(t, 1, p.Unused())
+
+	_, err := cl.Get("https://files.slack.com/a.png")
+	assert.ErrorContains(t, err, "connection reset")
+
+	_, err = p.RoundTrip(formRequest(t, "conversations.info", url.Values{"channel": {"C01"}}))
+	assert.ErrorIs(t, err, ErrNotRecorded)
+}
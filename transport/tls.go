@@ -0,0 +1,78 @@
+package transport
+
+// In this file: the TLS and DNS configuration of the transport.
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// TLSConfig is the TLS configuration of the connections to Slack, i.e. for
+// the corporate proxies, that terminate TLS with their own certificates.
+type TLSConfig struct {
+	CAFile   string // PEM file with the root certificates, trusted in addition to the system ones
+	CertFile string // PEM file with the client certificate, for the proxies, that require mTLS
+	KeyFile  string // PEM file with the key of the client certificate
+	Insecure bool   // skip the verification of the server certificates, DANGEROUS
+}
+
+// IsZero returns true, if the configuration is the default one.
+func (c TLSConfig) IsZero() bool {
+	return c == TLSConfig{}
+}
+
+// Config returns the tls.Config for the configuration.
+func (c TLSConfig) Config() (*tls.Config, error) {
+	cfg := &tls.Config{InsecureSkipVerify: c.Insecure}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			// i.e. on the systems without the certificate store.
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, errors.New("client certificate requires both the certificate and the key files")
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// Dialer returns the dial function, that resolves the host names with the DNS
+// server at addr, "host[:port]", instead of the system resolver, i.e. to
+// reach Slack through the split-horizon DNS.
+func Dialer(addr string) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "53")
+	}
+	var d net.Dialer
+	d.Timeout, d.KeepAlive = 30*time.Second, 30*time.Second
+	d.Resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var rd net.Dialer
+			return rd.DialContext(ctx, network, addr)
+		},
+	}
+	return d.DialContext
+}
@@ -0,0 +1,43 @@
+package transport
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSConfig_Config(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o644))
+
+	get := func(c TLSConfig) error {
+		cfg, err := c.Config()
+		if err != nil {
+			return err
+		}
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = cfg
+		resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	assert.Error(t, get(TLSConfig{}), "unknown authority")
+	assert.NoError(t, get(TLSConfig{CAFile: caFile}))
+	assert.NoError(t, get(TLSConfig{Insecure: true}))
+
+	_, err := TLSConfig{CertFile: caFile}.Config()
+	assert.Error(t, err, "certificate without the key")
+	_, err = TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}.Config()
+	assert.Error(t, err)
+}
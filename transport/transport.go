@@ -0,0 +1,99 @@
+// Package transport provides the HTTP middleware for the Slack API client of
+// the Session, i.e. for logging or recording the API calls, see
+// slackdump.WithMiddleware.  The middleware applies to all requests of the
+// session, including the file downloads.
+package transport
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// Middleware wraps the round tripper next, it's called once, when the
+// session is created.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is the function that satisfies http.RoundTripper.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (fn RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// Chain wraps rt with the middleware mw, the first middleware is the
+// outermost, it receives the request first.  If rt is nil,
+// http.DefaultTransport is used.
+func Chain(rt http.RoundTripper, mw ...Middleware) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(mw) - 1; i >= 0; i-- {
+		rt = mw[i](rt)
+	}
+	return rt
+}
+
+// Log logs the method and the path of each request, the response status and
+// the time it took.  Query and body are not logged, as they might contain
+// the token.
+func Log(lg logger.Interface) Middleware {
+	if lg == nil {
+		lg = logger.Default
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				lg.Printf("http: %s %s: error: %s (%s)", req.Method, req.URL.Path, err, time.Since(start))
+				return resp, err
+			}
+			lg.Printf("http: %s %s: %s (%s)", req.Method, req.URL.Path, resp.Status, time.Since(start))
+			return resp, nil
+		})
+	}
+}
+
+// Header sets the headers h on each request, replacing the existing values.
+func Header(h http.Header) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// the request must not be modified by the round tripper.
+			req = req.Clone(req.Context())
+			for k, v := range h {
+				req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// Rehost sends the requests to the hosts to the scheme and the host of the
+// target URL instead, i.e. the file downloads to the TLS-terminating proxy.
+// The path and the query are kept.
+func Rehost(target *url.URL, hosts ...string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !containsHost(hosts, req.URL.Hostname()) {
+				return next.RoundTrip(req)
+			}
+			req = req.Clone(req.Context())
+			req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+			req.Host = ""
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func containsHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
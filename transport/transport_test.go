@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rusq/dlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tag returns the middleware that appends name to the X-Trace header.
+func tag(name string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Add("X-Trace", name)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	var got []string
+	rt := Chain(RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header.Values("X-Trace")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), tag("first"), tag("second"))
+
+	req := httptest.NewRequest(http.MethodGet, "https://slack.com/api/test", nil)
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, got)
+
+	assert.Equal(t, http.DefaultTransport, Chain(nil))
+}
+
+func TestHeader(t *testing.T) {
+	var got http.Header
+	rt := Chain(RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), Header(http.Header{"x-corp-id": {"42"}}))
+
+	req := httptest.NewRequest(http.MethodGet, "https://slack.com/api/test", nil)
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, "42", got.Get("X-Corp-Id"))
+	assert.Empty(t, req.Header.Get("X-Corp-Id"), "original request is not modified")
+}
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	rt := Chain(RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests", Body: http.NoBody}, nil
+	}), Log(dlog.New(&buf, "", 0, false)))
+
+	req := httptest.NewRequest(http.MethodPost, "https://slack.com/api/conversations.history?token=xoxc-secret", nil)
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "http: POST /api/conversations.history: 429 Too Many Requests")
+	assert.NotContains(t, buf.String(), "xoxc")
+}
+
+func TestRehost(t *testing.T) {
+	var got *http.Request
+	target, err := url.Parse("https://proxy.corp:8443")
+	require.NoError(t, err)
+	rt := Chain(RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		got = req
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), Rehost(target, "files.slack.com"))
+
+	req := httptest.NewRequest(http.MethodGet, "https://files.slack.com/files-pri/T01-F01/a.txt?t=1", nil)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, "https://proxy.corp:8443/files-pri/T01-F01/a.txt?t=1", got.URL.String())
+	assert.Equal(t, "files.slack.com", req.URL.Host, "original request is not modified")
+
+	req = httptest.NewRequest(http.MethodGet, "https://slack.com/api/test", nil)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, "slack.com", got.URL.Host)
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Admin is the snapshot of the admin settings of the archived conversations,
+// that are only available to the organisation admins and owners, so that the
+// compliance settings are documented alongside the content.
+type Admin struct {
+	Created       time.Time           `json:"created"`
+	Conversations []AdminConversation `json:"conversations,omitempty"`
+	// Skipped is the reason, keyed by the part name, i.e. "retention", why
+	// the part was not fetched, usually, the token is not an admin token.
+	Skipped map[string]string `json:"skipped,omitempty"`
+}
+
+// AdminConversation is the admin settings of the conversation.
+type AdminConversation struct {
+	ChannelID string     `json:"channel_id"`
+	Retention *Retention `json:"retention,omitempty"`
+	// Prefs is the conversation preferences, i.e. who can post, as returned
+	// by the API.
+	Prefs   json.RawMessage `json:"prefs,omitempty"`
+	TeamIDs []string        `json:"team_ids,omitempty"` // workspaces the conversation is shared with.
+	// Err is the error, if some settings of the conversation could not be
+	// fetched.
+	Err string `json:"error,omitempty"`
+}
+
+// Retention is the custom message retention policy of the conversation.  If
+// the policy is not enabled, the workspace policy applies.
+type Retention struct {
+	IsPolicyEnabled bool `json:"is_policy_enabled"`
+	DurationDays    int  `json:"duration_days,omitempty"`
+}
@@ -0,0 +1,65 @@
+package types
+
+import "encoding/json"
+
+// AuditEntry is the entry of the Enterprise Grid audit log.  The entry is
+// kept as it was returned by the API, the fields that slackdump uses are
+// decoded for convenience.
+type AuditEntry struct {
+	ID         string      `json:"id"`
+	DateCreate int64       `json:"date_create"` // unix time, seconds.
+	Action     string      `json:"action"`
+	Actor      AuditActor  `json:"actor"`
+	Entity     AuditEntity `json:"entity"`
+	// Raw is the entry as returned by the API, it is output on marshalling.
+	Raw json.RawMessage `json:"-"`
+}
+
+// AuditActor is the actor of the audit log entry, i.e. the user who joined
+// the channel.
+type AuditActor struct {
+	Type string    `json:"type"` // i.e. "user"
+	User AuditUser `json:"user"`
+}
+
+// AuditUser is the user in the audit log entry.
+type AuditUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// AuditEntity is the entity of the audit log entry, i.e. the channel that
+// the user joined.  Only the fields of the entity type are set.
+type AuditEntity struct {
+	Type    string        `json:"type"` // i.e. "channel", "user" or "file"
+	Channel *AuditChannel `json:"channel,omitempty"`
+	User    *AuditUser    `json:"user,omitempty"`
+}
+
+// AuditChannel is the channel in the audit log entry.
+type AuditChannel struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// UnmarshalJSON decodes the entry and keeps the raw JSON.
+func (e *AuditEntry) UnmarshalJSON(b []byte) error {
+	type entry AuditEntry
+	var ent entry
+	if err := json.Unmarshal(b, &ent); err != nil {
+		return err
+	}
+	*e = AuditEntry(ent)
+	e.Raw = append(json.RawMessage(nil), b...)
+	return nil
+}
+
+// MarshalJSON outputs the raw JSON of the entry, so that no details are
+// lost.
+func (e AuditEntry) MarshalJSON() ([]byte, error) {
+	if len(e.Raw) > 0 {
+		return e.Raw, nil
+	}
+	type entry AuditEntry
+	return json.Marshal(entry(e))
+}
@@ -5,9 +5,15 @@ import (
 	"fmt"
 	"html"
 	"io"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/blockkit"
 	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/mrkdwn"
 )
 
 // time format for text output.
@@ -42,15 +48,83 @@ func (c Conversation) IsThread() bool {
 	return c.ThreadTS != ""
 }
 
-// ToText outputs Messages m to io.Writer w in text format.
+// UserIDs returns the sorted IDs of the users, that are involved in the
+// conversation: the authors of the messages and the replies, the users, who
+// reacted to them, uploaded the files, or are mentioned in the text.
+func (c Conversation) UserIDs() []string {
+	seen := make(map[string]bool)
+	addUserIDs(seen, c.Messages)
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func addUserIDs(seen map[string]bool, msgs []Message) {
+	add := func(id string) {
+		if id != "" {
+			seen[id] = true
+		}
+	}
+	for _, m := range msgs {
+		add(m.User)
+		add(m.Inviter)
+		for _, r := range m.Reactions {
+			for _, id := range r.Users {
+				add(id)
+			}
+		}
+		for _, f := range m.Files {
+			add(f.User)
+		}
+		for _, n := range mrkdwn.Parse(m.Text) {
+			if n.Kind == mrkdwn.UserMention {
+				add(n.ID)
+			}
+		}
+		addUserIDs(seen, m.ThreadReplies)
+	}
+}
+
+// TextFormat is the format of the text output of the conversation.  The zero
+// value is the default format.
+type TextFormat struct {
+	Location   *time.Location // time zone of the message times, local, if nil.
+	TimeLayout string         // layout of the message times, see time.Layout.
+	// Text returns the text of the message, i.e. translated, if nil, the
+	// text with the Block Kit layouts rendered is output, see blockkit.Text.
+	Text func(m *slack.Message) string
+}
+
+func (f TextFormat) withDefaults() TextFormat {
+	if f.Location == nil {
+		f.Location = time.Local
+	}
+	if f.TimeLayout == "" {
+		f.TimeLayout = textTimeFmt
+	}
+	if f.Text == nil {
+		f.Text = blockkit.Text
+	}
+	return f
+}
+
+// ToText outputs Messages m to io.Writer w in the default text format.
 func (c Conversation) ToText(w io.Writer, userIdx structures.UserIndex) (err error) {
+	return c.ToTextFormat(w, userIdx, TextFormat{})
+}
+
+// ToTextFormat outputs Messages m to io.Writer w in the text format f.
+func (c Conversation) ToTextFormat(w io.Writer, userIdx structures.UserIndex, f TextFormat) error {
 	buf := bufio.NewWriter(w)
 	defer buf.Flush()
 
-	return generateText(w, c.Messages, "", userIdx)
+	return generateText(w, c.Messages, "", userIdx, f.withDefaults())
 }
 
-func generateText(w io.Writer, m []Message, prefix string, userIdx structures.UserIndex) error {
+func generateText(w io.Writer, m []Message, prefix string, userIdx structures.UserIndex, f TextFormat) error {
 	var (
 		prevMsg  Message
 		prevTime time.Time
@@ -62,16 +136,21 @@ func generateText(w io.Writer, m []Message, prefix string, userIdx structures.Us
 		}
 		diff := t.Sub(prevTime)
 		if prevMsg.User == message.User && diff < minMsgTimeApart {
-			fmt.Fprintf(w, prefix+"%s\n", message.Text)
+			fmt.Fprintf(w, prefix+"%s\n", f.Text(&message.Message))
 		} else {
 			fmt.Fprintf(w, prefix+"\n"+prefix+"> %s [%s] @ %s:\n%s\n",
 				userIdx.Sender(&message.Message), message.User,
-				t.Format(textTimeFmt),
-				prefix+html.UnescapeString(message.Text),
+				t.In(f.Location).Format(f.TimeLayout),
+				prefix+html.UnescapeString(f.Text(&message.Message)),
 			)
 		}
+		if att := blockkit.Attachments(message.Attachments); att != "" {
+			for _, line := range strings.Split(html.UnescapeString(att), "\n") {
+				fmt.Fprintf(w, prefix+"%s\n", line)
+			}
+		}
 		if len(message.ThreadReplies) > 0 {
-			if err := generateText(w, message.ThreadReplies, "|   ", userIdx); err != nil {
+			if err := generateText(w, message.ThreadReplies, "|   ", userIdx, f); err != nil {
 				return err
 			}
 		}
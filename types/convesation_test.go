@@ -3,6 +3,7 @@ package types
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/rusq/slackdump/v2/internal/structures"
 	"github.com/slack-go/slack"
@@ -59,7 +60,13 @@ func Test_generateText(t *testing.T) {
 		m       []Message
 		prefix  string
 		userIdx structures.UserIndex
+		loc     *time.Location
 	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summerMsg := Message{Message: slack.Message{Msg: slack.Msg{User: "U01", Timestamp: "1625140800.000100", Text: "summer"}}}
 	tests := []struct {
 		name    string
 		args    args
@@ -68,21 +75,34 @@ func Test_generateText(t *testing.T) {
 	}{
 		{
 			"two messages from the same person, not very far apart, with html escaped char",
-			args{[]Message{testMsg1, testMsg2}, "", nil},
+			args{[]Message{testMsg1, testMsg2}, "", nil, time.UTC},
 			"\n> U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nTest message < > < >\nmessage 2\n",
 			false,
 		},
 		{
 			"two messages from the same person, far apart",
-			args{[]Message{testMsg1, testMsg4t}, "", nil},
+			args{[]Message{testMsg1, testMsg4t}, "", nil, time.UTC},
 			"\n> U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nTest message < > < >\n\n> UP58RAHCJ [UP58RAHCJ] @ 03/12/2021 09:47:34 Z:\nmessage 4\n|   \n|   > U01HPAR0YFN [U01HPAR0YFN] @ 03/12/2021 18:05:26 Z:\n|   blah blah, reply 1\n",
 			false,
 		},
+		{
+			"attachments",
+			args{[]Message{{Message: slack.Message{Msg: slack.Msg{User: "U01", Timestamp: "1625140800.000100", Text: "see <https://example.com>",
+				Attachments: []slack.Attachment{{Title: "Example & Co", TitleLink: "https://example.com", Text: "An example site"}}}}}}, "", nil, time.UTC},
+			"\n> U01 [U01] @ 01/07/2021 12:00:00 Z:\nsee <https://example.com>\n> *<https://example.com|Example & Co>*\n> An example site\n",
+			false,
+		},
+		{
+			"time zone with daylight saving",
+			args{[]Message{testMsg1, summerMsg}, "", nil, berlin},
+			"\n> U10H7D9RR [U10H7D9RR] @ 03/12/2021 03:15:51 +0100:\nTest message < > < >\n\n> U01 [U01] @ 01/07/2021 14:00:00 +0200:\nsummer\n",
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			w := &bytes.Buffer{}
-			if err := generateText(w, tt.args.m, tt.args.prefix, tt.args.userIdx); (err != nil) != tt.wantErr {
+			if err := generateText(w, tt.args.m, tt.args.prefix, tt.args.userIdx, TextFormat{Location: tt.args.loc}.withDefaults()); (err != nil) != tt.wantErr {
 				t.Errorf("Session.generateText() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
@@ -92,3 +112,18 @@ func Test_generateText(t *testing.T) {
 	}
 
 }
+
+func TestConversation_UserIDs(t *testing.T) {
+	reacted := Message{Message: slack.Message{Msg: slack.Msg{
+		User:      "U10H7D9RR",
+		Timestamp: "1638497790.040300",
+		Text:      "ping <@U02MENTION> and <@U02LABEL|label>, not <#C01CHAN>",
+		Reactions: []slack.ItemReaction{{Name: "+1", Users: []string{"U03REACT", "U10H7D9RR"}}},
+		Files:     []slack.File{{ID: "F01", User: "U04FILE"}},
+	}}}
+	c := Conversation{Messages: []Message{testMsg1, reacted, testMsg4t}}
+	assert.Equal(t,
+		[]string{"U01HPAR0YFN", "U02LABEL", "U02MENTION", "U03REACT", "U04FILE", "U10H7D9RR", "UP58RAHCJ"},
+		c.UserIDs())
+	assert.Empty(t, Conversation{}.UserIDs())
+}
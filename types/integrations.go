@@ -0,0 +1,67 @@
+package types
+
+import "time"
+
+// Integrations is the inventory of the apps, the bots and the legacy
+// integrations of the workspace, that is needed, when the workspace is
+// decommissioned, to know what was connected to it.
+type Integrations struct {
+	Created time.Time `json:"created"`
+	Apps    []App     `json:"apps,omitempty"`
+	Bots    []Bot     `json:"bots,omitempty"`
+	// Logs is the history of the installations and the removals of the
+	// apps and the legacy integrations, i.e. the incoming webhooks, newest
+	// first.
+	Logs []IntegrationLog `json:"logs,omitempty"`
+	// Skipped is the reason, keyed by the part name, i.e. "apps", why the
+	// part was not fetched, usually, the token is not an admin token.
+	Skipped map[string]string `json:"skipped,omitempty"`
+}
+
+// App is the app, that is approved or restricted by the admins.
+type App struct {
+	ID                     string `json:"id"`
+	Name                   string `json:"name"`
+	Description            string `json:"description,omitempty"`
+	IsInternal             bool   `json:"is_internal,omitempty"`
+	IsAppDirectoryApproved bool   `json:"is_app_directory_approved,omitempty"`
+	AppDirectoryURL        string `json:"app_directory_url,omitempty"`
+	// Status is "approved" or "restricted".
+	Status string `json:"status"`
+	// Scopes are the OAuth scopes, that the app is approved or restricted
+	// with.
+	Scopes      []AppScope `json:"scopes,omitempty"`
+	DateUpdated int64      `json:"date_updated,omitempty"`
+}
+
+// AppScope is the OAuth scope of the app.
+type AppScope struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	IsSensitive bool   `json:"is_sensitive,omitempty"`
+	TokenType   string `json:"token_type,omitempty"` // "user" or "bot".
+}
+
+// Bot is the bot user of the workspace.
+type Bot struct {
+	UserID  string `json:"user_id"`
+	BotID   string `json:"bot_id,omitempty"`
+	AppID   string `json:"app_id,omitempty"`
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// IntegrationLog is the entry of the integration log of the workspace.
+type IntegrationLog struct {
+	AppID       string `json:"app_id,omitempty"`
+	AppType     string `json:"app_type,omitempty"`
+	ServiceID   string `json:"service_id,omitempty"`
+	ServiceType string `json:"service_type,omitempty"`
+	UserID      string `json:"user_id"`
+	UserName    string `json:"user_name,omitempty"`
+	Channel     string `json:"channel,omitempty"`
+	Date        string `json:"date"`
+	ChangeType  string `json:"change_type"` // i.e. "added", "removed", "expanded".
+	Scope       string `json:"scope,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
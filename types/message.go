@@ -8,10 +8,20 @@ import (
 	"github.com/slack-go/slack"
 )
 
+// SubTypeTombstone is the subtype of the deleted message, that Slack keeps in
+// the history, because it has the thread replies, its text is TombstoneText.
+const SubTypeTombstone = "tombstone"
+
+// TombstoneText is the text, that Slack sets on the tombstone.
+const TombstoneText = "This message was deleted."
+
 // Message is the internal representation of message with thread.
 type Message struct {
 	slack.Message
 	ThreadReplies []Message `json:"slackdump_thread_replies,omitempty"`
+	// FileComments are the legacy comments of the files of the message, by
+	// the file ID, see slackdump.WithFileComments.
+	FileComments map[string][]slack.Comment `json:"slackdump_file_comments,omitempty"`
 }
 
 func (m Message) Datetime() (time.Time, error) {
@@ -23,6 +33,12 @@ func (m Message) IsBotMessage() bool {
 	return m.Msg.BotID != ""
 }
 
+// IsTombstone returns true if the message is the tombstone of the deleted
+// message.
+func (m Message) IsTombstone() bool {
+	return m.Msg.SubType == SubTypeTombstone
+}
+
 func (m Message) IsThread() bool {
 	return m.Msg.ThreadTimestamp != ""
 }
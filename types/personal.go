@@ -0,0 +1,46 @@
+package types
+
+import "github.com/slack-go/slack"
+
+// Personal is the personal data of the current user, that is lost when the
+// account is deactivated.
+type Personal struct {
+	UserID    string                   `json:"user_id"`
+	Scheduled []slack.ScheduledMessage `json:"scheduled,omitempty"`
+	Drafts    []Draft                  `json:"drafts,omitempty"`
+	Saved     []SavedItem              `json:"saved,omitempty"`
+	// Skipped is the reason, keyed by the part name, i.e. "drafts", why the
+	// part was not fetched.
+	Skipped map[string]string `json:"skipped,omitempty"`
+}
+
+// SavedItem is the item, saved (starred) by the user: a message, a file or a
+// conversation.  Parent is the thread parent message, if the saved message is
+// a thread reply, so that the reply can be read in context.
+type SavedItem struct {
+	slack.Item
+	Parent *slack.Message `json:"parent,omitempty"`
+}
+
+// Draft is the unsent message draft, as returned by the drafts.list method
+// of the Slack web client.
+type Draft struct {
+	ID            string             `json:"id"`
+	ClientMsgID   string             `json:"client_msg_id,omitempty"`
+	UserID        string             `json:"user_id,omitempty"`
+	DateCreated   int64              `json:"date_created,omitempty"`
+	DateScheduled int64              `json:"date_scheduled,omitempty"`
+	LastUpdatedTS string             `json:"last_updated_ts,omitempty"`
+	Blocks        slack.Blocks       `json:"blocks"`
+	FileIDs       []string           `json:"file_ids,omitempty"`
+	Destinations  []DraftDestination `json:"destinations,omitempty"`
+	IsDeleted     bool               `json:"is_deleted"`
+	IsSent        bool               `json:"is_sent"`
+}
+
+// DraftDestination is the conversation or the thread the draft is for.
+type DraftDestination struct {
+	ChannelID string `json:"channel_id"`
+	ThreadTS  string `json:"thread_ts,omitempty"`
+	Broadcast bool   `json:"broadcast,omitempty"`
+}
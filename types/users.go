@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/slack-go/slack"
@@ -69,3 +70,100 @@ func (us Users) ToText(w io.Writer, _ structures.UserIndex) error {
 func (us Users) IndexByID() structures.UserIndex {
 	return structures.NewUserIndex(us)
 }
+
+// ByTeam groups the users by their team ID.  The users of the Enterprise
+// Grid organisation belong to the different teams (workspaces).
+func (us Users) ByTeam() map[string]Users {
+	teams := make(map[string]Users)
+	for _, u := range us {
+		teams[u.TeamID] = append(teams[u.TeamID], u)
+	}
+	return teams
+}
+
+// Tombstone returns the placeholder of the user id, that is referenced in
+// the messages, but can't be fetched from the API anymore, i.e. the deleted
+// user.  It is marked as deleted and is named by its ID.
+func Tombstone(id string) slack.User {
+	return slack.User{
+		ID:       id,
+		Name:     id,
+		RealName: id,
+		Deleted:  true,
+		Profile:  slack.UserProfile{RealName: id},
+	}
+}
+
+// Match returns the users, that have the ID, the username, the display name,
+// the real name or the email equal to s, ignoring the case.
+func (us Users) Match(s string) Users {
+	var found Users
+	for _, u := range us {
+		for _, v := range []string{u.ID, u.Name, u.Profile.DisplayName, u.RealName, u.Profile.Email} {
+			if v != "" && strings.EqualFold(v, s) {
+				found = append(found, u)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// UserProfiles is the list of users with the complete profiles, including the
+// custom profile fields.
+type UserProfiles struct {
+	Users Users `json:"users"`
+	// Fields is the custom profile field definitions of the workspace, in the
+	// display order.
+	Fields []slack.TeamProfileField `json:"fields,omitempty"`
+}
+
+// ToText outputs the users and their profile fields to w in text format, one
+// column per custom field.
+func (up UserProfiles) ToText(w io.Writer, _ structures.UserIndex) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer writer.Flush()
+
+	header := []string{"Name", "ID", "Real Name", "Title", "Email", "Deleted?"}
+	for _, f := range up.Fields {
+		header = append(header, f.Label)
+	}
+	if err := writeRow(writer, header); err != nil {
+		return err
+	}
+	if err := writeRow(writer, make([]string, len(header))); err != nil {
+		return err
+	}
+
+	users := make(Users, len(up.Users))
+	copy(users, up.Users)
+	sort.SliceStable(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	for _, u := range users {
+		var deleted string
+		if u.Deleted {
+			deleted = "deleted"
+		}
+		row := []string{u.Name, u.ID, u.Profile.RealName, u.Profile.Title, u.Profile.Email, deleted}
+		fields := u.Profile.FieldsMap()
+		for _, f := range up.Fields {
+			v := fields[f.ID]
+			if v.Alt != "" {
+				row = append(row, v.Alt)
+			} else {
+				row = append(row, v.Value)
+			}
+		}
+		if err := writeRow(writer, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRow writes the tab-separated row to w.
+func writeRow(w io.Writer, cols []string) error {
+	if _, err := fmt.Fprintln(w, strings.Join(cols, "\t")); err != nil {
+		return fmt.Errorf("writer error: %w", err)
+	}
+	return nil
+}
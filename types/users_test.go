@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/rusq/slackdump/v2/internal/fixtures"
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,3 +36,48 @@ func TestUsers_ToText(t *testing.T) {
 		})
 	}
 }
+
+func TestUserProfiles_ToText(t *testing.T) {
+	alice := slack.User{ID: "U01", Name: "alice", Profile: slack.UserProfile{RealName: "Alice", Title: "Engineer", Email: "alice@example.com"}}
+	alice.Profile.SetFieldsMap(map[string]slack.UserProfileCustomField{
+		"Xf01": {Value: "R&D"},
+		"Xf02": {Value: "U02", Alt: "bob"},
+	})
+	bob := slack.User{ID: "U02", Name: "bob", Deleted: true}
+	up := UserProfiles{
+		Users:  Users{bob, alice},
+		Fields: []slack.TeamProfileField{{ID: "Xf01", Label: "Department"}, {ID: "Xf02", Label: "Manager"}},
+	}
+	w := &bytes.Buffer{}
+	if err := up.ToText(w, nil); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ""+
+		"Name   ID   Real Name  Title     Email              Deleted?  Department  Manager\n"+
+		"                                                                          \n"+
+		"alice  U01  Alice      Engineer  alice@example.com            R&D         bob\n"+
+		"bob    U02                                          deleted               \n",
+		w.String())
+}
+
+func TestUsers_Match(t *testing.T) {
+	us := Users{
+		{ID: "U01", Name: "alice", RealName: "Alice Liddell", Profile: slack.UserProfile{DisplayName: "Ally", Email: "alice@example.com"}},
+		{ID: "U02", Name: "bob", RealName: "Bob", Profile: slack.UserProfile{Email: "bob@example.com"}},
+		{ID: "U03", Name: "bob2", RealName: "bob"},
+	}
+	ids := func(us Users) []string {
+		var ids []string
+		for _, u := range us {
+			ids = append(ids, u.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"U01"}, ids(us.Match("alice")))
+	assert.Equal(t, []string{"U01"}, ids(us.Match("ally")))
+	assert.Equal(t, []string{"U01"}, ids(us.Match("ALICE@example.com")))
+	assert.Equal(t, []string{"U02"}, ids(us.Match("u02")))
+	assert.Equal(t, []string{"U02", "U03"}, ids(us.Match("Bob")))
+	assert.Empty(t, us.Match("carol"))
+	assert.Empty(t, us.Match(""))
+}
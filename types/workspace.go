@@ -0,0 +1,23 @@
+package types
+
+import (
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// Workspace is the snapshot of the workspace metadata, taken at the start of
+// the run, so that the archive describes the workspace it was taken from.
+type Workspace struct {
+	Created time.Time       `json:"created"`
+	Team    *slack.TeamInfo `json:"team,omitempty"`
+	// EnterpriseID is the Enterprise Grid organisation of the workspace, it
+	// is empty for the standalone workspaces.
+	EnterpriseID string             `json:"enterprise_id,omitempty"`
+	Emoji        map[string]string  `json:"emoji,omitempty"` // custom emoji name to URL or "alias:name"
+	UserGroups   []slack.UserGroup  `json:"usergroups,omitempty"`
+	Profile      *slack.TeamProfile `json:"profile,omitempty"` // custom user profile fields.
+	// Skipped is the reason, keyed by the part name, i.e. "usergroups", why
+	// the part was not fetched, usually, the missing token scope.
+	Skipped map[string]string `json:"skipped,omitempty"`
+}
@@ -0,0 +1,184 @@
+package slackdump
+
+// In this file: the accounting of the API calls.
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+// APIUsage counts the Slack API calls of the run by the method, and limits
+// their total number.  The calls are counted by the HTTP transport of the
+// session, so the sessions created with NewWithClient don't count them.  It
+// is safe for concurrent use, and can be shared by the sessions, see
+// WithAPIUsage.
+type APIUsage struct {
+	start time.Time
+	max   int64
+	total int64 // atomic
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// NewAPIUsage returns the new APIUsage, started now.  maxCalls is the
+// maximum number of the API calls, 0 is unlimited.
+func NewAPIUsage(maxCalls int) *APIUsage {
+	if maxCalls < 0 {
+		maxCalls = 0
+	}
+	return &APIUsage{start: time.Now(), max: int64(maxCalls), calls: make(map[string]int)}
+}
+
+// Total returns the number of API calls made.
+func (u *APIUsage) Total() int {
+	return int(atomic.LoadInt64(&u.total))
+}
+
+// Exceeded returns true, if the maximum number of calls is reached.
+func (u *APIUsage) Exceeded() bool {
+	return u.max > 0 && atomic.LoadInt64(&u.total) >= u.max
+}
+
+func (u *APIUsage) add(method string) {
+	atomic.AddInt64(&u.total, 1)
+	u.mu.Lock()
+	u.calls[method]++
+	u.mu.Unlock()
+}
+
+// transport returns the transport, that counts the API calls made with rt.
+// The apiPrefix is the URL path prefix of the API methods, see
+// network.APIPrefix.
+func (u *APIUsage) transport(rt http.RoundTripper, apiPrefix string) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return usageTransport{u: u, rt: rt, prefix: apiPrefix}
+}
+
+type usageTransport struct {
+	u      *APIUsage
+	rt     http.RoundTripper
+	prefix string
+}
+
+func (t usageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if method := network.APIMethod(req, t.prefix); method != "" {
+		t.u.add(method)
+	}
+	return t.rt.RoundTrip(req)
+}
+
+// MethodUsage is the usage of one API method.
+type MethodUsage struct {
+	Method string `json:"method"`
+	Tier   string `json:"tier"`
+	Calls  int    `json:"calls"`
+	// Budget is the theoretical maximum number of calls in the elapsed
+	// time, according to the method rate limit tier, 0 if the method has the
+	// special rate limit.
+	Budget int `json:"budget,omitempty"`
+}
+
+// Remaining returns the remaining theoretical budget of the method.
+func (m MethodUsage) Remaining() int {
+	if m.Budget < m.Calls {
+		return 0
+	}
+	return m.Budget - m.Calls
+}
+
+// UsageReport is the snapshot of the API usage.
+type UsageReport struct {
+	Elapsed  time.Duration `json:"elapsed"`
+	Calls    int           `json:"calls"`
+	MaxCalls int           `json:"max_calls,omitempty"` // 0 is unlimited
+	// Methods are ordered by the number of calls, descending.
+	Methods []MethodUsage `json:"methods"`
+}
+
+// Report returns the snapshot of the usage.
+func (u *APIUsage) Report() UsageReport {
+	elapsed := time.Since(u.start)
+	r := UsageReport{
+		Elapsed:  elapsed,
+		Calls:    u.Total(),
+		MaxCalls: int(u.max),
+	}
+	// the budget of the started minute is available in full.
+	minutes := int(elapsed/time.Minute) + 1
+	u.mu.Lock()
+	for method, n := range u.calls {
+		t := network.MethodTier(method)
+		m := MethodUsage{Method: method, Tier: t.String(), Calls: n}
+		if t != network.NoTier {
+			m.Budget = int(t) * minutes
+		}
+		r.Methods = append(r.Methods, m)
+	}
+	u.mu.Unlock()
+	sort.Slice(r.Methods, func(i, j int) bool {
+		if r.Methods[i].Calls != r.Methods[j].Calls {
+			return r.Methods[i].Calls > r.Methods[j].Calls
+		}
+		return r.Methods[i].Method < r.Methods[j].Method
+	})
+	return r
+}
+
+// Tiers returns the number of calls by the rate limit tier.
+func (r UsageReport) Tiers() map[string]int {
+	tiers := make(map[string]int)
+	for _, m := range r.Methods {
+		tiers[m.Tier] += m.Calls
+	}
+	return tiers
+}
+
+// Top returns at most n methods with the most calls.
+func (r UsageReport) Top(n int) []MethodUsage {
+	if n > len(r.Methods) {
+		n = len(r.Methods)
+	}
+	return r.Methods[:n]
+}
+
+// reportTop is the number of methods in the text report.
+const reportTop = 5
+
+// String returns the text report with the top consumers.
+func (r UsageReport) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "API calls: %d in %s", r.Calls, r.Elapsed.Round(time.Second))
+	if r.MaxCalls > 0 {
+		fmt.Fprintf(&buf, " (maximum: %d)", r.MaxCalls)
+	}
+	if len(r.Methods) == 0 {
+		return buf.String()
+	}
+	tiers := r.Tiers()
+	names := make([]string, 0, len(tiers))
+	for t := range tiers {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	for i, t := range names {
+		names[i] = fmt.Sprintf("%s: %d", t, tiers[t])
+	}
+	fmt.Fprintf(&buf, "\nby tier: %s\ntop methods:", strings.Join(names, ", "))
+	for _, m := range r.Top(reportTop) {
+		fmt.Fprintf(&buf, "\n  %-28s %6d", m.Method, m.Calls)
+		if m.Budget > 0 {
+			fmt.Fprintf(&buf, " (%s, %d of %d remaining)", m.Tier, m.Remaining(), m.Budget)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,110 @@
+package slackdump
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+func TestAPIUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	u := NewAPIUsage(5)
+	cl := &http.Client{Transport: u.transport(nil, "")}
+	for _, p := range []string{
+		"/api/conversations.history",
+		"/api/conversations.history",
+		"/api/conversations.replies",
+		"/api/users.list",
+		"/api/auth.test",
+		"/files-pri/T01-F01/file.txt", // not an API call
+	} {
+		resp, err := cl.Get(srv.URL + p)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, 5, u.Total())
+	assert.True(t, u.Exceeded())
+
+	rep := u.Report()
+	assert.Equal(t, 5, rep.Calls)
+	assert.Equal(t, 5, rep.MaxCalls)
+	assert.Equal(t, []MethodUsage{
+		{Method: "conversations.history", Tier: "tier3", Calls: 2, Budget: 50},
+		{Method: "auth.test", Tier: "special", Calls: 1},
+		{Method: "conversations.replies", Tier: "tier3", Calls: 1, Budget: 50},
+		{Method: "users.list", Tier: "tier2", Calls: 1, Budget: 20},
+	}, rep.Methods)
+	assert.Equal(t, map[string]int{"tier2": 1, "tier3": 3, "special": 1}, rep.Tiers())
+	assert.Equal(t, 48, rep.Methods[0].Remaining())
+	assert.Len(t, rep.Top(2), 2)
+	assert.Len(t, rep.Top(10), 4)
+}
+
+func TestAPIUsage_prefixedBaseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	u := NewAPIUsage(0)
+	cl := &http.Client{Transport: u.transport(nil, network.APIPrefix(srv.URL+"/slack"))}
+	for _, p := range []string{"/slack/api/users.list", "/api/users.list"} {
+		resp, err := cl.Get(srv.URL + p)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, 1, u.Total(), "only the calls under the base URL path are counted")
+}
+
+func TestAPIUsage_unlimited(t *testing.T) {
+	u := NewAPIUsage(0)
+	for i := 0; i < 100; i++ {
+		u.add("conversations.history")
+	}
+	assert.False(t, u.Exceeded())
+}
+
+func TestMethodUsage_Remaining(t *testing.T) {
+	assert.Equal(t, 0, MethodUsage{Calls: 60, Budget: 50}.Remaining())
+	assert.Equal(t, 0, MethodUsage{Calls: 1}.Remaining())
+}
+
+func TestUsageReport_String(t *testing.T) {
+	r := UsageReport{
+		Elapsed:  90 * time.Second,
+		Calls:    13,
+		MaxCalls: 100,
+		Methods: []MethodUsage{
+			{Method: "conversations.history", Tier: "tier3", Calls: 12, Budget: 100},
+			{Method: "auth.test", Tier: "special", Calls: 1},
+		},
+	}
+	want := "API calls: 13 in 1m30s (maximum: 100)\n" +
+		"by tier: special: 1, tier3: 12\n" +
+		"top methods:\n" +
+		"  conversations.history            12 (tier3, 88 of 100 remaining)\n" +
+		"  auth.test                         1"
+	assert.Equal(t, want, r.String())
+	assert.Equal(t, "API calls: 0 in 0s", UsageReport{}.String())
+}
+
+func TestSession_proceed_callBudget(t *testing.T) {
+	u := NewAPIUsage(2)
+	sd := &Session{options: DefOptions}
+	sd.options.APIUsage = u
+	ctx := context.Background()
+	assert.NoError(t, sd.proceed(ctx))
+	u.add("conversations.history")
+	u.add("conversations.history")
+	err := sd.proceed(ctx)
+	assert.ErrorIs(t, err, ErrCallBudgetExceeded)
+	assert.True(t, IsInterrupted(ctx, err))
+	assert.Equal(t, 2, sd.APIUsage().Calls)
+}
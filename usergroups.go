@@ -0,0 +1,42 @@
+package slackdump
+
+// In this file: user group related code.
+
+import (
+	"context"
+	"runtime/trace"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+// userGroupsOptions are the options of the usergroups.list call, the
+// disabled groups are included, as they can still be mentioned in the old
+// messages.
+var userGroupsOptions = []slack.GetUserGroupsOption{
+	slack.GetUserGroupsOptionIncludeUsers(true),
+	slack.GetUserGroupsOptionIncludeDisabled(true),
+}
+
+// GetUserGroups retrieves the user groups (@-groups) of the workspace, with
+// their members, from the API.  It requires the usergroups:read scope, if the
+// token does not have it, ErrNoAccess is returned.
+func (sd *Session) GetUserGroups(ctx context.Context) ([]slack.UserGroup, error) {
+	ctx, task := trace.NewTask(ctx, "GetUserGroups")
+	defer task.End()
+
+	if err := sd.proceed(ctx); err != nil {
+		return nil, err
+	}
+	var ugs []slack.UserGroup
+	if err := network.WithRetry(ctx, sd.limiter(network.Tier2), sd.options.Tier2Retries, func() error {
+		var err error
+		ugs, err = sd.client.GetUserGroupsContext(ctx, userGroupsOptions...)
+		return err
+	}); err != nil {
+		trace.Logf(ctx, "error", "GetUserGroups error=%s", err)
+		return nil, network.Classify("", err)
+	}
+	return ugs, nil
+}
@@ -0,0 +1,34 @@
+package slackdump
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_GetUserGroups(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mc.EXPECT().GetUserGroupsContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(testUserGroups, nil)
+
+		sd := &Session{client: mc, options: DefOptions}
+		got, err := sd.GetUserGroups(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, testUserGroups, got)
+	})
+	t.Run("missing scope", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mc.EXPECT().GetUserGroupsContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, slack.SlackErrorResponse{Err: "missing_scope"})
+
+		sd := &Session{client: mc, options: DefOptions}
+		_, err := sd.GetUserGroups(context.Background())
+		var na *ErrNoAccess
+		assert.ErrorAs(t, err, &na)
+	})
+}
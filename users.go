@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/trace"
+	"strings"
 	"time"
 
 	"errors"
@@ -21,6 +22,57 @@ import (
 	"github.com/rusq/slackdump/v2/types"
 )
 
+// CachePolicy is the policy of refreshing the cache.
+type CachePolicy uint8
+
+const (
+	// CPIfOlder refreshes the cache, if it is older than its maximum age.
+	CPIfOlder CachePolicy = iota
+	// CPAlways fetches the data from the API on each run, and updates the
+	// cache.
+	CPAlways
+	// CPNever uses the cache regardless of its age, the data is only
+	// fetched, if there's no cache.
+	CPNever
+)
+
+var cachePolicyNames = map[CachePolicy]string{
+	CPIfOlder: "if-older-than",
+	CPAlways:  "always",
+	CPNever:   "never",
+}
+
+func (p CachePolicy) String() string {
+	if s, ok := cachePolicyNames[p]; ok {
+		return s
+	}
+	return fmt.Sprintf("CachePolicy(%d)", uint8(p))
+}
+
+// Set sets the policy from its name, it is the flag.Value interface.
+func (p *CachePolicy) Set(s string) error {
+	for v, name := range cachePolicyNames {
+		if strings.EqualFold(s, name) {
+			*p = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown cache policy: %q, use one of: always, if-older-than or never", s)
+}
+
+// maxAge returns the maximum age of the cache under the policy, maxAge is
+// the age for CPIfOlder.
+func (p CachePolicy) maxAge(maxAge time.Duration) time.Duration {
+	switch p {
+	case CPAlways:
+		return 0
+	case CPNever:
+		return time.Duration(1<<63 - 1)
+	default:
+		return maxAge
+	}
+}
+
 // GetUsers retrieves all users either from cache or from the API.
 func (sd *Session) GetUsers(ctx context.Context) (types.Users, error) {
 	// TODO: validate that the cache is from the same workspace, it can be done by team ID.
@@ -31,8 +83,10 @@ func (sd *Session) GetUsers(ctx context.Context) (types.Users, error) {
 		return types.Users{}, nil
 	}
 
-	users, err := sd.loadUserCache(sd.options.UserCacheFilename, sd.wspInfo.TeamID, sd.options.MaxUserCacheAge)
-	if err != nil {
+	users, err := sd.loadUserCache(sd.options.UserCacheFilename, sd.wspInfo.TeamID, sd.options.UserCachePolicy.maxAge(sd.options.MaxUserCacheAge))
+	if err == nil {
+		sd.warnStaleUsers()
+	} else {
 		if os.IsNotExist(err) {
 			sd.l().Println("  caching users for the first time")
 		} else {
@@ -51,6 +105,54 @@ func (sd *Session) GetUsers(ctx context.Context) (types.Users, error) {
 	return users, err
 }
 
+// GetUsersInfo returns the users with the ids, each of them is requested
+// separately with users.info.  It is cheaper than fetching all users of the
+// workspace, if only a few users are needed, i.e. the participants of a
+// thread.  The unknown users are skipped.
+func (sd *Session) GetUsersInfo(ctx context.Context, ids ...string) (types.Users, error) {
+	ctx, task := trace.NewTask(ctx, "GetUsersInfo")
+	defer task.End()
+
+	users := make(types.Users, 0, len(ids))
+	l := sd.pooledLimiter(network.Tier4)
+	for _, id := range ids {
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
+		var u *slack.User
+		if err := network.WithRetry(ctx, l, sd.options.Tier4Retries, func() error {
+			var err error
+			u, err = sd.client.GetUserInfoContext(ctx, id)
+			return err
+		}); err != nil {
+			err = network.Classify("", err)
+			var sce slack.SlackErrorResponse
+			if errors.As(err, &sce) && sce.Err == "user_not_found" {
+				sd.l().Debugf("users: skipping %s: %s", id, err)
+				continue
+			}
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, nil
+}
+
+// warnStaleUsers logs the warning, if the user cache is older than
+// UserCacheWarnAge, as the user names, resolved from it, may be outdated.
+func (sd *Session) warnStaleUsers() {
+	if sd.options.UserCacheWarnAge <= 0 {
+		return
+	}
+	fi, err := os.Stat(sd.makeCacheFilename(sd.options.UserCacheFilename, sd.wspInfo.TeamID))
+	if err != nil {
+		return
+	}
+	if age := time.Since(fi.ModTime()); age > sd.options.UserCacheWarnAge {
+		sd.l().Printf("warning: the user cache is %s old, the user names may be outdated, refresh it with -user-cache-policy always", age.Round(time.Hour))
+	}
+}
+
 // fetchUsers fetches users from the API.
 func (sd *Session) fetchUsers(ctx context.Context) (types.Users, error) {
 	var (
@@ -62,7 +164,7 @@ func (sd *Session) fetchUsers(ctx context.Context) (types.Users, error) {
 		return err
 	}); err != nil {
 		trace.Logf(ctx, "error", "GetUsers error=%s", err)
-		return nil, err
+		return nil, network.Classify("", err)
 	}
 	// BUG: as of 201902 there's a bug in slack module, the invalid_auth error
 	// is not propagated properly, so we'll check for number of users.  There
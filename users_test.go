@@ -1,6 +1,7 @@
 package slackdump
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"errors"
 
 	"github.com/golang/mock/gomock"
+	"github.com/rusq/dlog"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 
@@ -241,6 +243,37 @@ func TestSession_GetUsers(t *testing.T) {
 			testUsers,
 			false,
 		},
+		{
+			"policy never ignores the cache age",
+			fields{options: Options{
+				UserCacheFilename: gimmeTempFileWithUsers(t, dir),
+				UserCachePolicy:   CPNever,
+				Tier2Burst:        1,
+				Tier3Burst:        1,
+			}},
+			args{context.Background()},
+			func(mc *mockClienter) {
+				// we don't expect any API calls
+			},
+			testUsers,
+			false,
+		},
+		{
+			"policy always refreshes the cache",
+			fields{options: Options{
+				UserCacheFilename: gimmeTempFileWithUsers(t, dir),
+				MaxUserCacheAge:   5 * time.Hour,
+				UserCachePolicy:   CPAlways,
+				Tier2Burst:        1,
+				Tier3Burst:        1,
+			}},
+			args{context.Background()},
+			func(mc *mockClienter) {
+				mc.EXPECT().GetUsersContext(gomock.Any()).Return([]slack.User(testUsers), nil)
+			},
+			testUsers,
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -267,6 +300,38 @@ func TestSession_GetUsers(t *testing.T) {
 	}
 }
 
+func TestSession_warnStaleUsers(t *testing.T) {
+	filename := gimmeTempFileWithUsers(t, t.TempDir())
+	var buf bytes.Buffer
+	sd := &Session{
+		wspInfo: &slack.AuthTestResponse{TeamID: testSuffix},
+		options: Options{UserCacheFilename: filename, UserCacheWarnAge: 24 * time.Hour, Logger: dlog.New(&buf, "", 0, false)},
+	}
+	sd.warnStaleUsers()
+	assert.Empty(t, buf.String(), "the cache is fresh")
+
+	old := time.Now().Add(-72 * time.Hour)
+	if err := os.Chtimes(filename+"-"+testSuffix, old, old); err != nil {
+		t.Fatal(err)
+	}
+	sd.warnStaleUsers()
+	assert.Contains(t, buf.String(), "the user cache is 72h0m0s old")
+
+	buf.Reset()
+	sd.options.UserCacheWarnAge = 0
+	sd.warnStaleUsers()
+	assert.Empty(t, buf.String(), "the warning is disabled")
+}
+
+func TestCachePolicy_Set(t *testing.T) {
+	var p CachePolicy
+	if err := p.Set("Never"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assert.Equal(t, CPNever, p)
+	assert.Error(t, p.Set("sometimes"))
+}
+
 func gimmeTempFile(t *testing.T, dir string) string {
 	f, err := os.CreateTemp(dir, "")
 	if err != nil {
@@ -301,3 +366,63 @@ func FuzzFilenameSplit(f *testing.F) {
 		assert.Equal(t, input, joined)
 	})
 }
+
+func TestSession_GetUsersInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []string
+		expectFn func(*mockClienter)
+		want     types.Users
+		wantErr  bool
+	}{
+		{
+			"ok",
+			[]string{testUsers[0].ID, testUsers[1].ID},
+			func(mc *mockClienter) {
+				mc.EXPECT().GetUserInfoContext(gomock.Any(), testUsers[0].ID).Return(&testUsers[0], nil)
+				mc.EXPECT().GetUserInfoContext(gomock.Any(), testUsers[1].ID).Return(&testUsers[1], nil)
+			},
+			testUsers[:2],
+			false,
+		},
+		{
+			"unknown user is skipped",
+			[]string{"UNKNOWN", testUsers[0].ID},
+			func(mc *mockClienter) {
+				mc.EXPECT().GetUserInfoContext(gomock.Any(), "UNKNOWN").Return(nil, slack.SlackErrorResponse{Err: "user_not_found"})
+				mc.EXPECT().GetUserInfoContext(gomock.Any(), testUsers[0].ID).Return(&testUsers[0], nil)
+			},
+			testUsers[:1],
+			false,
+		},
+		{
+			"api error",
+			[]string{testUsers[0].ID},
+			func(mc *mockClienter) {
+				mc.EXPECT().GetUserInfoContext(gomock.Any(), testUsers[0].ID).Return(nil, errors.New("i don't think so"))
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := newmockClienter(gomock.NewController(t))
+
+			tt.expectFn(mc)
+
+			sd := &Session{
+				client:  mc,
+				options: DefOptions,
+			}
+			got, err := sd.GetUsersInfo(context.Background(), tt.ids...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Session.GetUsersInfo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Session.GetUsersInfo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
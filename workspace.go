@@ -0,0 +1,91 @@
+package slackdump
+
+// In this file: the workspace metadata snapshot.
+
+import (
+	"context"
+	"errors"
+	"runtime/trace"
+	"time"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// WorkspaceFile is the name of the workspace metadata file in the root of the
+// archive.
+const WorkspaceFile = "workspace.json"
+
+// Workspace returns the snapshot of the workspace metadata: team info, custom
+// emoji, user groups and custom profile fields.  The parts that the token has
+// no access to, i.e. because of the missing scope, are skipped, and the
+// reason is recorded in the Skipped field.  Workspace preferences are not
+// available through the public API, and are not included.
+func (sd *Session) Workspace(ctx context.Context) (*types.Workspace, error) {
+	ctx, task := trace.NewTask(ctx, "Workspace")
+	defer task.End()
+
+	ws := &types.Workspace{Created: time.Now().UTC()}
+	if sd.wspInfo != nil {
+		ws.EnterpriseID = sd.wspInfo.EnterpriseID
+	}
+	parts := []struct {
+		name string
+		tier network.Tier
+		fn   func() error
+	}{
+		{"team", network.Tier3, func() (err error) {
+			ws.Team, err = sd.client.GetTeamInfoContext(ctx)
+			return
+		}},
+		{"emoji", network.Tier2, func() (err error) {
+			ws.Emoji, err = sd.client.GetEmojiContext(ctx)
+			return
+		}},
+		{"usergroups", network.Tier2, func() (err error) {
+			ws.UserGroups, err = sd.client.GetUserGroupsContext(ctx, userGroupsOptions...)
+			return
+		}},
+		{"profile", network.Tier3, func() (err error) {
+			ws.Profile, err = sd.client.GetTeamProfileContext(ctx)
+			return
+		}},
+	}
+	for _, p := range parts {
+		if err := sd.proceed(ctx); err != nil {
+			return nil, err
+		}
+		if err := network.WithRetry(ctx, sd.limiter(p.tier), sd.options.Tier3Retries, p.fn); err != nil {
+			err = network.Classify("", err)
+			var na *ErrNoAccess
+			if !errors.As(err, &na) {
+				return nil, err
+			}
+			trace.Logf(ctx, "warn", "%s: %s", p.name, err)
+			sd.l().Debugf("workspace: skipping %s: %s", p.name, err)
+			if ws.Skipped == nil {
+				ws.Skipped = make(map[string]string)
+			}
+			ws.Skipped[p.name] = err.Error()
+		}
+	}
+	return ws, nil
+}
+
+// SaveWorkspace takes the workspace snapshot and writes it into the root of
+// the filesystem fs as WorkspaceFile.  The snapshot is auxiliary, so the
+// errors, other than the interruption, are logged and do not stop the run.
+func (sd *Session) SaveWorkspace(ctx context.Context, fs fsadapter.FS) error {
+	ws, err := sd.Workspace(ctx)
+	if err == nil {
+		err = writeJSON(fs, WorkspaceFile, ws)
+	}
+	if err != nil {
+		if IsInterrupted(ctx, err) {
+			return err
+		}
+		sd.l().Printf("warning: failed to save the workspace metadata: %s", err)
+	}
+	return nil
+}
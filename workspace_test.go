@@ -0,0 +1,93 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+)
+
+var (
+	testTeam       = slack.TeamInfo{ID: "T01", Name: "Test", Domain: "test"}
+	testEmoji      = map[string]string{"parrot": "https://emoji.slack-edge.com/T01/parrot/1.gif"}
+	testUserGroups = []slack.UserGroup{{ID: "S01", Name: "Everyone", Handle: "everyone", Users: []string{"U01"}}}
+	testProfile    = slack.TeamProfile{Fields: []slack.TeamProfileField{{ID: "Xf01", Label: "Title"}}}
+)
+
+// mockWorkspace sets up the workspace metadata calls.
+func mockWorkspace(mc *mockClienter) {
+	mc.EXPECT().GetTeamInfoContext(gomock.Any()).Return(&testTeam, nil)
+	mc.EXPECT().GetEmojiContext(gomock.Any()).Return(testEmoji, nil)
+	mc.EXPECT().GetUserGroupsContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(testUserGroups, nil)
+	mc.EXPECT().GetTeamProfileContext(gomock.Any()).Return(&testProfile, nil)
+}
+
+func TestSession_Workspace(t *testing.T) {
+	t.Run("all parts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mockWorkspace(mc)
+
+		sd := &Session{client: mc, options: DefOptions, wspInfo: &slack.AuthTestResponse{EnterpriseID: "E01"}}
+		ws, err := sd.Workspace(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, &testTeam, ws.Team)
+		assert.Equal(t, "E01", ws.EnterpriseID)
+		assert.Equal(t, testEmoji, ws.Emoji)
+		assert.Equal(t, testUserGroups, ws.UserGroups)
+		assert.Equal(t, &testProfile, ws.Profile)
+		assert.Empty(t, ws.Skipped)
+		assert.False(t, ws.Created.IsZero())
+	})
+	t.Run("missing scope", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mc.EXPECT().GetTeamInfoContext(gomock.Any()).Return(&testTeam, nil)
+		mc.EXPECT().GetEmojiContext(gomock.Any()).Return(testEmoji, nil)
+		mc.EXPECT().GetUserGroupsContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, slack.SlackErrorResponse{Err: "missing_scope"})
+		mc.EXPECT().GetTeamProfileContext(gomock.Any()).Return(&testProfile, nil)
+
+		sd := &Session{client: mc, options: DefOptions}
+		ws, err := sd.Workspace(context.Background())
+		require.NoError(t, err)
+		assert.Nil(t, ws.UserGroups)
+		assert.Contains(t, ws.Skipped, "usergroups")
+		assert.Equal(t, &testProfile, ws.Profile)
+	})
+	t.Run("token expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mc.EXPECT().GetTeamInfoContext(gomock.Any()).Return(nil, slack.SlackErrorResponse{Err: "token_expired"})
+
+		sd := &Session{client: mc, options: DefOptions}
+		_, err := sd.Workspace(context.Background())
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+}
+
+func TestSession_SaveWorkspace(t *testing.T) {
+	t.Run("error is not fatal", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+		mc.EXPECT().GetTeamInfoContext(gomock.Any()).Return(nil, errors.New("boom"))
+
+		sd := &Session{client: mc, options: DefOptions}
+		sd.options.Tier3Retries = 0
+		assert.NoError(t, sd.SaveWorkspace(context.Background(), fsadapter.NewDirectory(t.TempDir())))
+	})
+	t.Run("interrupted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := newmockClienter(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		sd := &Session{client: mc, options: DefOptions}
+		assert.ErrorIs(t, sd.SaveWorkspace(ctx, fsadapter.NewDirectory(t.TempDir())), context.Canceled)
+	})
+}